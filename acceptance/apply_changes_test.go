@@ -172,6 +172,47 @@ var _ = Describe("apply-changes command", func() {
 		})
 	})
 
+	When("--timeout is exceeded", func() {
+		It("stops waiting and exits with status 3", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/api/v0/installations"),
+					ghttp.RespondWith(http.StatusOK, `{
+						"installations": [{
+							"id": 42,
+							"status": "running",
+							"started_at": "2017-03-02T06:50:32.370Z"
+						}]
+					}`),
+				),
+			)
+			server.RouteToHandler("GET", "/api/v0/installations/42",
+				ghttp.RespondWith(http.StatusOK, `{"status": "running"}`),
+			)
+			server.RouteToHandler("GET", "/api/v0/installations/42/logs",
+				ghttp.RespondWith(http.StatusOK, `{ "logs": "call #0\n"}`),
+			)
+
+			command := exec.Command(pathToMain,
+				"--target", server.URL(),
+				"--username", "some-username",
+				"--password", "some-password",
+				"--skip-ssl-validation",
+				"apply-changes",
+				"--reattach",
+				"--timeout", "100ms",
+			)
+
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session, "5s").Should(gexec.Exit(3))
+
+			Expect(session.Out).To(gbytes.Say("stopped waiting for installation 42, which is still running on the Ops Manager"))
+			Expect(session.Err).To(gbytes.Say(`timed out waiting for the installation to finish \(Installation ID: 42\)`))
+		})
+	})
+
 	It("successfully re-attaches to an existing deployment", func() {
 		server.AppendHandlers(
 			ghttp.CombineHandlers(
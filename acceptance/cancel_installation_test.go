@@ -0,0 +1,63 @@
+package acceptance
+
+import (
+	"net/http"
+	"os/exec"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/ghttp"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cancel-installation command", func() {
+	var (
+		server *ghttp.Server
+	)
+
+	BeforeEach(func() {
+		server = createTLSServer()
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v0/installations"),
+				ghttp.RespondWith(http.StatusOK, `{"installations": [{"id": 42, "status": "running"}]}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v0/installations/42/cancel"),
+				ghttp.RespondWith(http.StatusOK, `{}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v0/installations/42"),
+				ghttp.RespondWith(http.StatusOK, `{ "status": "running" }`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v0/installations/42"),
+				ghttp.RespondWith(http.StatusOK, `{ "status": "failed" }`),
+			),
+		)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("cancels the running installation on the Ops Manager", func() {
+		command := exec.Command(pathToMain,
+			"--target", server.URL(),
+			"--username", "some-username",
+			"--password", "some-password",
+			"--skip-ssl-validation",
+			"cancel-installation",
+			"--force")
+
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session, "5s").Should(gexec.Exit(0))
+
+		Expect(session.Out).To(gbytes.Say("cancelling installation 42"))
+		Expect(session.Out).To(gbytes.Say("installation 42 has stopped with status: failed"))
+	})
+})
@@ -6,9 +6,12 @@ import (
 	"fmt"
 	"github.com/onsi/gomega/gbytes"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"regexp"
+	"sync/atomic"
 
 	"github.com/onsi/gomega/gexec"
 
@@ -117,6 +120,42 @@ bad-key: bad-value
 			Expect(string(session.Err.Contents())).To(ContainSubstring("field bad-key not found"))
 		})
 
+		It("disables the retries with a retry-attempts of 0", func() {
+			var requests int32
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				if req.URL.Path == "/uaa/oauth/token" {
+					w.Header().Set("Content-Type", "application/json")
+					_, _ = w.Write([]byte(`{"access_token": "some-opsman-token", "token_type": "bearer", "expires_in": 3600}`))
+					return
+				}
+
+				atomic.AddInt32(&requests, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}))
+			defer server.Close()
+
+			createConfigFile(fmt.Sprintf(`
+---
+password: some-env-provided-password
+username: some-env-provided-username
+target: %s
+skip-ssl-validation: true
+retry-attempts: 0
+`, server.URL))
+			command := exec.Command(pathToMain,
+				"--env", configFile.Name(),
+				"curl",
+				"-p", "/api/v0/available_products",
+			)
+
+			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Eventually(session, "10s").Should(gexec.Exit())
+			Expect(atomic.LoadInt32(&requests)).To(Equal(int32(1)))
+			Expect(session.Err).ToNot(gbytes.Say("retrying"))
+		})
+
 		When("the env file contains variables", func() {
 			BeforeEach(func() {
 				createConfigFile(fmt.Sprintf(validConfigFile, "((target_url))"))
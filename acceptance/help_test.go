@@ -83,21 +83,42 @@ Commands:
 
 const GLOBAL_USAGE_FLAGS = `
 Global Flags:
-  --ca-cert, OM_CA_CERT                                  string  OpsManager CA certificate path or value
-  --client-id, -c, OM_CLIENT_ID                          string  Client ID for the Ops Manager VM (not required for unauthenticated commands)
-  --client-secret, -s, OM_CLIENT_SECRET                  string  Client Secret for the Ops Manager VM (not required for unauthenticated commands)
-  --connect-timeout, -o, OM_CONNECT_TIMEOUT              int     timeout in seconds to make TCP connections (default: 10)
-  --decryption-passphrase, -d, OM_DECRYPTION_PASSPHRASE  string  Passphrase to decrypt the installation if the Ops Manager VM has been rebooted (optional for most commands)
-  --env, -e                                              string  env file with login credentials
-  --help, -h                                             bool    prints this usage information (default: false)
-  --password, -p, OM_PASSWORD                            string  admin password for the Ops Manager VM (not required for unauthenticated commands)
-  --request-timeout, -r, OM_REQUEST_TIMEOUT              int     timeout in seconds for HTTP requests to Ops Manager (default: 1800)
-  --skip-ssl-validation, -k, OM_SKIP_SSL_VALIDATION      bool    skip ssl certificate validation during http requests (default: false)
-  --target, -t, OM_TARGET                                string  location of the Ops Manager VM
-  --trace, -tr, OM_TRACE                                 bool    prints HTTP requests and response payloads
-  --username, -u, OM_USERNAME                            string  admin username for the Ops Manager VM (not required for unauthenticated commands)
-  --version, -v                                          bool    prints the om release version (default: false)
-  OM_VARS_ENV                                            string  load vars from environment variables by specifying a prefix (e.g.: 'MY' to load MY_var=value)
+  --ca-cert, OM_CA_CERT                                  string             OpsManager CA certificate path or value
+  --client-cert, OM_CLIENT_CERT                          string             client certificate path or value, presented to Ops Manager and UAA for mutual TLS
+  --client-id, -c, OM_CLIENT_ID                          string             Client ID for the Ops Manager VM (not required for unauthenticated commands)
+  --client-key, OM_CLIENT_KEY                            string             private key path or value of the client certificate
+  --client-secret, -s, OM_CLIENT_SECRET                  string             Client Secret for the Ops Manager VM (not required for unauthenticated commands)
+  --connect-timeout, -o, OM_CONNECT_TIMEOUT              int                timeout in seconds to make TCP connections (default: 10)
+  --credhub-ca-cert, CREDHUB_CA_CERT                     string             CredHub CA certificate path or value
+  --credhub-client, CREDHUB_CLIENT                       string             UAA client to read the credentials of credhub:// vars sources with
+  --credhub-client-cert, CREDHUB_CLIENT_CERT             string             client certificate path or value to authenticate with CredHub, instead of a UAA client
+  --credhub-client-key, CREDHUB_CLIENT_KEY               string             private key path or value of the CredHub client certificate
+  --credhub-secret, CREDHUB_SECRET                       string             secret of the UAA client of credhub:// vars sources
+  --credhub-server, CREDHUB_SERVER                       string             address of the CredHub server of credhub:// vars sources
+  --decryption-passphrase, -d, OM_DECRYPTION_PASSPHRASE  string             Passphrase to decrypt the installation if the Ops Manager VM has been rebooted (optional for most commands)
+  --env, -e                                              string             env file with login credentials
+  --help, -h                                             bool               prints this usage information (default: false)
+  --password, -p, OM_PASSWORD                            string             admin password for the Ops Manager VM (not required for unauthenticated commands)
+  --profile, OM_PROFILE                                  string             profile of ~/.om/config.yml to take the target, credentials and timeouts not otherwise set from (defaults to the current profile, when no target is set)
+  --request-timeout, -r, OM_REQUEST_TIMEOUT              int                timeout in seconds for HTTP requests to Ops Manager (default: 1800)
+  --retry-attempts, OM_RETRY_ATTEMPTS                    int                times to retry the requests of idempotent methods when the connection fails or Ops Manager answers 502, 503 or 504 (0 disables retries) (default: 3)
+  --retry-max-backoff, OM_RETRY_MAX_BACKOFF              int                maximum time in seconds to wait between retries, which doubles from 1 second with jitter (default: 30)
+  --skip-ssl-validation, -k, OM_SKIP_SSL_VALIDATION      bool               skip ssl certificate validation during http requests (default: false)
+  --target, -t, OM_TARGET                                string             location of the Ops Manager VM
+  --token-cache, OM_TOKEN_CACHE                          string             directory to cache UAA tokens in, so they can be reused by subsequent om invocations (disabled when not set, except for the tokens of om login, stored in ~/.om/tokens)
+  --trace, -tr, OM_TRACE                                 bool               prints HTTP requests and response payloads
+  --trace-file, OM_TRACE_FILE                            string             records HTTP requests and responses, with their secrets redacted, to this HTTP Archive (HAR) file
+  --username, -u, OM_USERNAME                            string             admin username for the Ops Manager VM (not required for unauthenticated commands)
+  --vars-source                                          string (variadic)  secret store to look up the variables missing from the vars files, vars and vars envs of the commands interpolating their config (e.g.: 'vault://secret/foundation' or 'credhub://concourse/main')
+  --vault-addr, VAULT_ADDR                               string             address of the Vault server of vault:// vars sources
+  --vault-ca-cert, VAULT_CACERT                          string             Vault CA certificate path or value
+  --vault-namespace, VAULT_NAMESPACE                     string             Vault Enterprise namespace of vault:// vars sources
+  --vault-role-id, VAULT_ROLE_ID                         string             role ID of the AppRole to log in to Vault with, instead of a token
+  --vault-secret-id, VAULT_SECRET_ID                     string             secret ID of the AppRole to log in to Vault with, instead of a token
+  --vault-skip-ssl-validation, VAULT_SKIP_VERIFY         bool               skip ssl certificate validation of the requests to Vault
+  --vault-token, VAULT_TOKEN                             string             token to read the secrets of vault:// vars sources with
+  --version, -v                                          bool               prints the om release version (default: false)
+  OM_VARS_ENV                                            string             load vars from environment variables by specifying a prefix (e.g.: 'MY' to load MY_var=value)
 `
 
 const CONFIGURE_AUTHENTICATION_USAGE = `
@@ -46,4 +46,23 @@ var _ = Describe("installation-log command", func() {
 
 		Expect(string(session.Out.Contents())).To(Equal("log output\n"))
 	})
+
+	It("fails when the format template does not fit the output", func() {
+		command := exec.Command(pathToMain,
+			"--target", server.URL(),
+			"--username", "some-username",
+			"--password", "some-password",
+			"--skip-ssl-validation",
+			"installation-log",
+			"--id", "999",
+			"--format", "template={{.missing}}")
+
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session, "40s").Should(gexec.Exit(1))
+
+		Expect(string(session.Err.Contents())).To(ContainSubstring(`could not execute format template: `))
+		Expect(string(session.Err.Contents())).To(ContainSubstring(`map has no entry for key "missing"`))
+	})
 })
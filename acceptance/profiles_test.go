@@ -0,0 +1,117 @@
+package acceptance
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
+)
+
+const profilesConfigFile = `
+current: a
+profiles:
+  a:
+    target: %s
+    skip-ssl-validation: true
+    username: some-env-provided-username
+    password:
+      env: OM_PROFILE_A_PASSWORD
+  b:
+    target: https://b.example.com
+`
+
+var _ = Describe("profiles", func() {
+	var (
+		home   string
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		var err error
+		home, err = ioutil.TempDir("", "home")
+		Expect(err).ToNot(HaveOccurred())
+
+		server = testServer(true)
+
+		Expect(os.MkdirAll(filepath.Join(home, ".om"), 0700)).To(Succeed())
+		config := fmt.Sprintf(profilesConfigFile, server.URL)
+		Expect(ioutil.WriteFile(filepath.Join(home, ".om", "config.yml"), []byte(config), 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(home)).To(Succeed())
+	})
+
+	run := func(env []string, args ...string) *gexec.Session {
+		command := exec.Command(pathToMain, args...)
+		command.Env = append([]string{"HOME=" + home, "PATH=" + os.Getenv("PATH")}, env...)
+
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+		Eventually(session, "10s").Should(gexec.Exit())
+		return session
+	}
+
+	It("reads the secrets of the current profile for the requests that need them", func() {
+		session := run([]string{"OM_PROFILE_A_PASSWORD=some-env-provided-password"}, "curl", "-p", "/api/v0/available_products")
+		Expect(session.ExitCode()).To(Equal(0))
+		Expect(string(session.Out.Contents())).To(MatchJSON(`[ { "name": "p-bosh", "product_version": "999.99" } ]`))
+
+		session = run(nil, "curl", "-p", "/api/v0/available_products")
+		Expect(session.ExitCode()).To(Equal(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring("could not read the password of profile a: the environment variable OM_PROFILE_A_PASSWORD of the secret is not set"))
+	})
+
+	It("does not read the secrets of the profile for the commands that do not need them", func() {
+		Expect(run(nil, "version").ExitCode()).To(Equal(0))
+		Expect(run(nil, "interpolate", "-c", writeFile("name: value")).ExitCode()).To(Equal(0))
+		Expect(run(nil, "profiles", "use", "b").ExitCode()).To(Equal(0))
+		Expect(run(nil, "--profile", "a", "profiles", "list").ExitCode()).To(Equal(0))
+	})
+
+	It("prefers the timeouts of the flags, even when they are the defaults", func() {
+		slowServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/uaa/oauth/token" {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"access_token": "some-opsman-token", "token_type": "bearer", "expires_in": 3600}`))
+				return
+			}
+
+			time.Sleep(2 * time.Second)
+			_, _ = w.Write([]byte(`[]`))
+		}))
+		defer slowServer.Close()
+
+		config := fmt.Sprintf(`
+current: slow
+profiles:
+  slow:
+    target: %s
+    skip-ssl-validation: true
+    username: some-username
+    password:
+      env: OM_PROFILE_SLOW_PASSWORD
+    request-timeout: 1
+`, slowServer.URL)
+		Expect(ioutil.WriteFile(filepath.Join(home, ".om", "config.yml"), []byte(config), 0600)).To(Succeed())
+
+		session := run([]string{"OM_PROFILE_SLOW_PASSWORD=some-password"}, "--retry-attempts", "0", "curl", "-p", "/api/v0/available_products")
+		Expect(session.ExitCode()).To(Equal(1))
+		Expect(string(session.Err.Contents())).To(ContainSubstring("Client.Timeout exceeded"))
+
+		session = run([]string{"OM_PROFILE_SLOW_PASSWORD=some-password"}, "--retry-attempts", "0", "--request-timeout", "1800", "curl", "-p", "/api/v0/available_products")
+		Expect(session.ExitCode()).To(Equal(0))
+
+		session = run([]string{"OM_PROFILE_SLOW_PASSWORD=some-password", "OM_REQUEST_TIMEOUT=1800"}, "--retry-attempts", "0", "curl", "-p", "/api/v0/available_products")
+		Expect(session.ExitCode()).To(Equal(0))
+	})
+})
@@ -2,12 +2,14 @@ package acceptance
 
 import (
 	"archive/zip"
+	"encoding/json"
 	"github.com/onsi/gomega/ghttp"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/onsi/gomega/gexec"
 
@@ -65,7 +67,7 @@ name: some-product`)
 				}, {
 					"name": "p-redis",
 					"product_version": "1.7.2"
-				}]`),
+				}]`, http.Header{"Content-Type": {"application/json"}}),
 			),
 			ghttp.CombineHandlers(
 				ghttp.VerifyRequest("POST", "/api/v0/available_products"),
@@ -117,4 +119,63 @@ name: some-product`)
 		Expect(string(session.Err.Contents())).To(ContainSubstring("POST /api/v0/available_products"))
 		Expect(string(session.Err.Contents())).To(ContainSubstring("200 OK"))
 	})
+
+	It("records the requests and responses to an HTTP Archive", func() {
+		traceDir, err := ioutil.TempDir("", "trace-file")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(traceDir)
+		traceFile := filepath.Join(traceDir, "out.har")
+
+		command := exec.Command(pathToMain,
+			"--target", server.URL(),
+			"--username", "some-username",
+			"--password", "some-password",
+			"--skip-ssl-validation",
+			"--trace-file", traceFile,
+			"available-products")
+
+		session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+		Expect(err).ToNot(HaveOccurred())
+
+		Eventually(session, "40s").Should(gexec.Exit(0))
+		Expect(string(session.Out.Contents())).To(ContainSubstring(tableOutput))
+
+		info, err := os.Stat(traceFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		contents, err := ioutil.ReadFile(traceFile)
+		Expect(err).ToNot(HaveOccurred())
+
+		var archive struct {
+			Log struct {
+				Entries []struct {
+					Request struct {
+						Method string
+						URL    string
+					}
+					Response struct {
+						Status  int
+						Content struct{ Text string }
+					}
+				}
+			}
+		}
+		Expect(string(contents)).ToNot(ContainSubstring("Bearer"))
+		Expect(string(contents)).ToNot(ContainSubstring("some-password"))
+		Expect(string(contents)).ToNot(ContainSubstring("some-opsman-token"))
+		Expect(json.Unmarshal(contents, &archive)).To(Succeed())
+		Expect(archive.Log.Entries).To(HaveLen(2))
+
+		entry := archive.Log.Entries[0]
+		Expect(entry.Request.Method).To(Equal("POST"))
+		Expect(entry.Request.URL).To(Equal(server.URL() + "/uaa/oauth/token"))
+		Expect(entry.Response.Status).To(Equal(http.StatusOK))
+
+		entry = archive.Log.Entries[1]
+		Expect(entry.Request.Method).To(Equal("GET"))
+		Expect(entry.Request.URL).To(Equal(server.URL() + "/api/v0/available_products"))
+		Expect(entry.Response.Status).To(Equal(http.StatusOK))
+		Expect(entry.Response.Content.Text).To(ContainSubstring("p-redis"))
+	})
 })
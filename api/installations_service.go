@@ -193,6 +193,16 @@ func (a Api) GetInstallationLogs(id int) (InstallationsServiceOutput, error) {
 	return InstallationsServiceOutput{Logs: output.Logs}, nil
 }
 
+func (a Api) CancelInstallation(id int) error {
+	resp, err := a.sendAPIRequest("POST", fmt.Sprintf("/api/v0/installations/%d/cancel", id), nil)
+	if err != nil {
+		return fmt.Errorf("could not make api request to installations cancel endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return validateStatusOK(resp)
+}
+
 func (a *Api) removeErrandsWithoutProductNameFlag(errands map[string]ProductErrand, productFlags []string) map[string]ProductErrand {
 	for productName := range errands {
 		shouldDelete := true
@@ -461,6 +461,43 @@ var _ = Describe("InstallationsService", func() {
 		})
 	})
 
+	Describe("CancelInstallation", func() {
+		It("cancels the installation", func() {
+			client.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/api/v0/installations/3232/cancel"),
+					ghttp.RespondWith(http.StatusOK, `{}`),
+				),
+			)
+
+			err := service.CancelInstallation(3232)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		When("the client returns a non-2XX", func() {
+			It("returns an error", func() {
+				client.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/api/v0/installations/3232/cancel"),
+						ghttp.RespondWith(http.StatusTeapot, `{}`),
+					),
+				)
+
+				err := service.CancelInstallation(3232)
+				Expect(err).To(MatchError(ContainSubstring("request failed: unexpected response")))
+			})
+		})
+
+		When("the client has an error during the request", func() {
+			It("returns an error", func() {
+				client.Close()
+
+				err := service.CancelInstallation(3232)
+				Expect(err).To(MatchError(ContainSubstring("could not make api request to installations cancel endpoint: could not send api request to POST /api/v0/installations/3232/cancel")))
+			})
+		})
+	})
+
 	Describe("GetInstallationLogs", func() {
 		It("grabs the logs from the currently running installation", func() {
 			client.AppendHandlers(
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"net/http"
+	"sync"
+)
+
+// lazyClient builds its client for the first request,
+// so that the commands making none do not need what it is built from.
+type lazyClient struct {
+	build func() (httpClient, error)
+
+	once   sync.Once
+	client httpClient
+	err    error
+}
+
+func newLazyClient(build func() (httpClient, error)) *lazyClient {
+	return &lazyClient{build: build}
+}
+
+func (c *lazyClient) Do(request *http.Request) (*http.Response, error) {
+	c.once.Do(func() {
+		c.client, c.err = c.build()
+	})
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return c.client.Do(request)
+}
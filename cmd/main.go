@@ -1,22 +1,31 @@
 package cmd
 
 import (
+	"flag"
 	"fmt"
 	"github.com/olekukonko/tablewriter"
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/configtemplate/metadata"
+	"github.com/pivotal-cf/om/credhub"
 	"github.com/pivotal-cf/om/extractor"
 	"github.com/pivotal-cf/om/formcontent"
 	"github.com/pivotal-cf/om/interpolate"
 	"github.com/pivotal-cf/om/network"
 	"github.com/pivotal-cf/om/presenters"
+	"github.com/pivotal-cf/om/profiles"
 	"github.com/pivotal-cf/om/renderers"
+	"github.com/pivotal-cf/om/vault"
 	"gopkg.in/yaml.v2"
 	"io"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
 	"regexp"
 	"strings"
 	"time"
@@ -28,20 +37,42 @@ type httpClient interface {
 
 type options struct {
 	CACert               string `yaml:"ca-cert" long:"ca-cert" env:"OM_CA_CERT" description:"OpsManager CA certificate path or value"`
+	ClientCert           string `yaml:"client-cert"                      long:"client-cert"           env:"OM_CLIENT_CERT"                         description:"client certificate path or value, presented to Ops Manager and UAA for mutual TLS"`
 	ClientID             string `yaml:"client-id"             short:"c"  long:"client-id"             env:"OM_CLIENT_ID"                           description:"Client ID for the Ops Manager VM (not required for unauthenticated commands)"`
+	ClientKey            string `yaml:"client-key"                       long:"client-key"            env:"OM_CLIENT_KEY"                          description:"private key path or value of the client certificate"`
 	ClientSecret         string `yaml:"client-secret"         short:"s"  long:"client-secret"         env:"OM_CLIENT_SECRET"                       description:"Client Secret for the Ops Manager VM (not required for unauthenticated commands)"`
 	ConnectTimeout       int    `yaml:"connect-timeout"       short:"o"  long:"connect-timeout"       env:"OM_CONNECT_TIMEOUT"     default:"10"    description:"timeout in seconds to make TCP connections"`
 	DecryptionPassphrase string `yaml:"decryption-passphrase" short:"d"  long:"decryption-passphrase" env:"OM_DECRYPTION_PASSPHRASE"             description:"Passphrase to decrypt the installation if the Ops Manager VM has been rebooted (optional for most commands)"`
 	Env                  string `                             short:"e"  long:"env"                                                              description:"env file with login credentials"`
 	Help                 bool   `                             short:"h"  long:"help"                                             default:"false" description:"prints this usage information"`
 	Password             string `yaml:"password"              short:"p"  long:"password"              env:"OM_PASSWORD"                            description:"admin password for the Ops Manager VM (not required for unauthenticated commands)"`
+	Profile              string `                                        long:"profile"               env:"OM_PROFILE"                             description:"profile of ~/.om/config.yml to take the target, credentials and timeouts not otherwise set from (defaults to the current profile, when no target is set)"`
 	RequestTimeout       int    `yaml:"request-timeout"       short:"r"  long:"request-timeout"       env:"OM_REQUEST_TIMEOUT"     default:"1800"  description:"timeout in seconds for HTTP requests to Ops Manager"`
+	RetryAttempts        int    `yaml:"retry-attempts"                   long:"retry-attempts"        env:"OM_RETRY_ATTEMPTS"      default:"3"     description:"times to retry the requests of idempotent methods when the connection fails or Ops Manager answers 502, 503 or 504 (0 disables retries)"`
+	RetryMaxBackoff      int    `yaml:"retry-max-backoff"                long:"retry-max-backoff"     env:"OM_RETRY_MAX_BACKOFF"   default:"30"    description:"maximum time in seconds to wait between retries, which doubles from 1 second with jitter"`
 	SkipSSLValidation    bool   `yaml:"skip-ssl-validation"   short:"k"  long:"skip-ssl-validation"   env:"OM_SKIP_SSL_VALIDATION" default:"false" description:"skip ssl certificate validation during http requests"`
 	Target               string `yaml:"target"                short:"t"  long:"target"                env:"OM_TARGET"                              description:"location of the Ops Manager VM"`
+	TokenCache           string `yaml:"token-cache"                      long:"token-cache"           env:"OM_TOKEN_CACHE"                         description:"directory to cache UAA tokens in, so they can be reused by subsequent om invocations (disabled when not set, except for the tokens of om login, stored in ~/.om/tokens)"`
 	Trace                bool   `yaml:"trace"                 short:"tr" long:"trace"                 env:"OM_TRACE"                               description:"prints HTTP requests and response payloads"`
+	TraceFile            string `yaml:"trace-file"                       long:"trace-file"            env:"OM_TRACE_FILE"                          description:"records HTTP requests and responses, with their secrets redacted, to this HTTP Archive (HAR) file"`
 	Username             string `yaml:"username"              short:"u"  long:"username"              env:"OM_USERNAME"                            description:"admin username for the Ops Manager VM (not required for unauthenticated commands)"`
 	VarsEnv              string `                                                                     env:"OM_VARS_ENV"                            description:"load vars from environment variables by specifying a prefix (e.g.: 'MY' to load MY_var=value)"`
 	Version              bool   `                             short:"v"  long:"version"                                          default:"false" description:"prints the om release version"`
+
+	VarsSource             []string `yaml:"vars-source"               long:"vars-source"                                         description:"secret store to look up the variables missing from the vars files, vars and vars envs of the commands interpolating their config (e.g.: 'vault://secret/foundation' or 'credhub://concourse/main')"`
+	CredHubCACert          string   `yaml:"credhub-ca-cert"           long:"credhub-ca-cert"           env:"CREDHUB_CA_CERT"     description:"CredHub CA certificate path or value"`
+	CredHubClient          string   `yaml:"credhub-client"            long:"credhub-client"            env:"CREDHUB_CLIENT"      description:"UAA client to read the credentials of credhub:// vars sources with"`
+	CredHubClientCert      string   `yaml:"credhub-client-cert"       long:"credhub-client-cert"       env:"CREDHUB_CLIENT_CERT" description:"client certificate path or value to authenticate with CredHub, instead of a UAA client"`
+	CredHubClientKey       string   `yaml:"credhub-client-key"        long:"credhub-client-key"        env:"CREDHUB_CLIENT_KEY"  description:"private key path or value of the CredHub client certificate"`
+	CredHubSecret          string   `yaml:"credhub-secret"            long:"credhub-secret"            env:"CREDHUB_SECRET"      description:"secret of the UAA client of credhub:// vars sources"`
+	CredHubServer          string   `yaml:"credhub-server"            long:"credhub-server"            env:"CREDHUB_SERVER"      description:"address of the CredHub server of credhub:// vars sources"`
+	VaultAddr              string   `yaml:"vault-addr"                long:"vault-addr"                env:"VAULT_ADDR"          description:"address of the Vault server of vault:// vars sources"`
+	VaultCACert            string   `yaml:"vault-ca-cert"             long:"vault-ca-cert"             env:"VAULT_CACERT"        description:"Vault CA certificate path or value"`
+	VaultNamespace         string   `yaml:"vault-namespace"           long:"vault-namespace"           env:"VAULT_NAMESPACE"     description:"Vault Enterprise namespace of vault:// vars sources"`
+	VaultRoleID            string   `yaml:"vault-role-id"             long:"vault-role-id"             env:"VAULT_ROLE_ID"       description:"role ID of the AppRole to log in to Vault with, instead of a token"`
+	VaultSecretID          string   `yaml:"vault-secret-id"           long:"vault-secret-id"           env:"VAULT_SECRET_ID"     description:"secret ID of the AppRole to log in to Vault with, instead of a token"`
+	VaultSkipSSLValidation bool     `yaml:"vault-skip-ssl-validation" long:"vault-skip-ssl-validation" env:"VAULT_SKIP_VERIFY"   description:"skip ssl certificate validation of the requests to Vault"`
+	VaultToken             string   `yaml:"vault-token"               long:"vault-token"               env:"VAULT_TOKEN"         description:"token to read the secrets of vault:// vars sources with"`
 }
 
 func Main(sout io.Writer, serr io.Writer, version string, applySleepDurationString string, args []string) error {
@@ -52,21 +83,12 @@ func Main(sout io.Writer, serr io.Writer, version string, applySleepDurationStri
 
 	var global options
 
+	explicit := explicitOptions(args[1:])
 	args, err := jhanda.Parse(&global, args[1:])
 	if err != nil {
 		return err
 	}
 
-	err = setEnvFileProperties(&global)
-	if err != nil {
-		return err
-	}
-
-	globalFlagsUsage, err := jhanda.PrintUsage(global)
-	if err != nil {
-		return err
-	}
-
 	var command string
 	if len(args) > 0 {
 		command, args = args[0], args[1:]
@@ -84,24 +106,91 @@ func Main(sout io.Writer, serr io.Writer, version string, applySleepDurationStri
 		command = "help"
 	}
 
+	err = setEnvFileProperties(&global, explicit)
+	if err != nil {
+		return err
+	}
+
+	var secrets profileSecrets
+	if !commandsWithoutProfile[command] {
+		secrets, err = setProfileProperties(&global, explicit)
+		if err != nil {
+			return err
+		}
+	}
+
+	globalFlagsUsage, err := jhanda.PrintUsage(global)
+	if err != nil {
+		return err
+	}
+
 	requestTimeout := time.Duration(global.RequestTimeout) * time.Second
 	connectTimeout := time.Duration(global.ConnectTimeout) * time.Second
 
+	// the requests are recorded as they are sent, so that the trace file has those for tokens and the retries too
+	var harRecorder *network.HARRecorder
+	var traceFile *os.File
+	if global.TraceFile != "" {
+		// the trace file has the bodies of the requests and responses, so only the user can read it
+		traceFile, err = os.OpenFile(global.TraceFile, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return fmt.Errorf("could not create the trace file: %s", err)
+		}
+		defer traceFile.Close()
+
+		harRecorder = network.NewHARRecorder(version)
+	}
+
 	var unauthenticatedClient, authedClient, unauthenticatedProgressClient, authedProgressClient httpClient
-	unauthenticatedClient, err = network.NewUnauthenticatedClient(global.Target, global.SkipSSLValidation, global.CACert, connectTimeout, requestTimeout)
+	unauthenticatedClient, err = network.NewUnauthenticatedClient(global.Target, global.SkipSSLValidation, global.CACert, global.ClientCert, global.ClientKey, connectTimeout, requestTimeout, harRecorder)
 	if err != nil {
 		return err
 	}
 
-	authedClient, err = network.NewOAuthClient(global.Target, global.Username, global.Password, global.ClientID, global.ClientSecret, global.SkipSSLValidation, global.CACert, connectTimeout, requestTimeout)
+	var tokenCache *network.TokenCache
+	if global.TokenCache != "" {
+		tokenCache = network.NewTokenCache(global.TokenCache)
+	} else if global.Username == "" && global.ClientID == "" {
+		// without credentials, the tokens are those of `om login`
+		if home, err := os.UserHomeDir(); err == nil {
+			tokenCache = network.NewTokenCache(filepath.Join(home, ".om", "tokens"))
+		}
+	}
 
+	// logging in only needs the target and the token cache, not the secrets of the other credentials
+	loginClient, err := network.NewOAuthClient(global.Target, global.Username, "", global.ClientID, "", global.SkipSSLValidation, global.CACert, global.ClientCert, global.ClientKey, connectTimeout, requestTimeout, tokenCache, harRecorder)
 	if err != nil {
 		return err
 	}
 
-	if global.DecryptionPassphrase != "" {
-		authedClient = network.NewDecryptClient(authedClient, unauthenticatedClient, global.DecryptionPassphrase, os.Stderr)
-	}
+	retryMaxBackoff := time.Duration(global.RetryMaxBackoff) * time.Second
+	unauthenticatedClient = network.NewRetryClient(unauthenticatedClient, global.RetryAttempts, retryMaxBackoff, os.Stderr)
+
+	// the secrets of the profile are only read by the commands making authenticated requests
+	retryingUnauthenticatedClient := unauthenticatedClient
+	authedClient = newLazyClient(func() (httpClient, error) {
+		err := secrets.resolveCredentials(&global)
+		if err != nil {
+			return nil, err
+		}
+
+		oauthClient, err := network.NewOAuthClient(global.Target, global.Username, global.Password, global.ClientID, global.ClientSecret, global.SkipSSLValidation, global.CACert, global.ClientCert, global.ClientKey, connectTimeout, requestTimeout, tokenCache, harRecorder)
+		if err != nil {
+			return nil, err
+		}
+
+		var client httpClient = network.NewRetryClient(oauthClient, global.RetryAttempts, retryMaxBackoff, os.Stderr)
+
+		err = secrets.resolveDecryptionPassphrase(&global)
+		if err != nil {
+			return nil, err
+		}
+		if global.DecryptionPassphrase != "" {
+			client = network.NewDecryptClient(client, retryingUnauthenticatedClient, global.DecryptionPassphrase, os.Stderr)
+		}
+
+		return client, nil
+	})
 
 	unauthenticatedProgressClient = network.NewProgressClient(unauthenticatedClient, os.Stderr)
 	authedProgressClient = network.NewProgressClient(authedClient, os.Stderr)
@@ -128,28 +217,56 @@ func Main(sout io.Writer, serr io.Writer, version string, applySleepDurationStri
 
 	metadataExtractor := extractor.NewMetadataExtractor()
 
-	presenter := presenters.NewPresenter(presenters.NewTablePresenter(tableWriter), presenters.NewJSONPresenter(os.Stdout))
+	presenter := presenters.NewPresenter(presenters.NewTablePresenter(tableWriter, os.Stdout), presenters.NewJSONPresenter(os.Stdout))
+	presenter.Register("yaml", presenters.NewYAMLPresenter(os.Stdout))
+	presenter.Register("csv", presenters.NewCSVPresenter(os.Stdout))
+	presenter.Register("template", presenters.NewTemplatePresenter(os.Stdout))
 	envRendererFactory := renderers.NewFactory(renderers.NewEnvGetter())
 
+	varsSourceConfig := interpolate.VarsSourceConfig{
+		Sources: global.VarsSource,
+		Vault: vault.Config{
+			Address:           global.VaultAddr,
+			Token:             global.VaultToken,
+			RoleID:            global.VaultRoleID,
+			SecretID:          global.VaultSecretID,
+			Namespace:         global.VaultNamespace,
+			CACert:            global.VaultCACert,
+			SkipSSLValidation: global.VaultSkipSSLValidation,
+		},
+		CredHub: credhub.Config{
+			Server:     global.CredHubServer,
+			Client:     global.CredHubClient,
+			Secret:     global.CredHubSecret,
+			CACert:     global.CredHubCACert,
+			ClientCert: global.CredHubClientCert,
+			ClientKey:  global.CredHubClientKey,
+		},
+	}
+
+	profilesPath, _ := profiles.DefaultPath()
+
 	commandSet := jhanda.CommandSet{}
 	commandSet["activate-certificate-authority"] = commands.NewActivateCertificateAuthority(api, stdout)
-	commandSet["apply-changes"] = commands.NewApplyChanges(api, api, logWriter, stdout, applySleepDuration)
-	commandSet["assign-multi-stemcell"] = commands.NewAssignMultiStemcell(api, stdout)
-	commandSet["assign-stemcell"] = commands.NewAssignStemcell(api, stdout)
+	commandSet["apply-changes"] = commands.NewApplyChanges(api, api, logWriter, stdout, applySleepDuration, signal.Notify)
+	commandSet["assign-multi-stemcell"] = commands.NewAssignMultiStemcell(varsSourceConfig, api, stdout)
+	commandSet["assign-stemcell"] = commands.NewAssignStemcell(varsSourceConfig, api, stdout)
 	commandSet["available-products"] = commands.NewAvailableProducts(api, presenter, stdout)
-	commandSet["bosh-diff"] = commands.NewBoshDiff(api, stdout)
+	commandSet["bosh-diff"] = commands.NewBoshDiff(api, presenter)
 	commandSet["bosh-env"] = commands.NewBoshEnvironment(api, stdout, global.Target, envRendererFactory)
+	commandSet["cancel-installation"] = commands.NewCancelInstallation(api, stdout, os.Stdin, applySleepDuration)
 	commandSet["certificate-authorities"] = commands.NewCertificateAuthorities(api, presenter)
 	commandSet["certificate-authority"] = commands.NewCertificateAuthority(api, presenter, stdout)
-	commandSet["config-template"] = commands.NewConfigTemplate(commands.DefaultProvider())
-	commandSet["configure-authentication"] = commands.NewConfigureAuthentication(os.Environ, api, stdout)
-	commandSet["configure-director"] = commands.NewConfigureDirector(os.Environ, api, stdout)
-	commandSet["configure-ldap-authentication"] = commands.NewConfigureLDAPAuthentication(os.Environ, api, stdout)
-	commandSet["configure-opsman"] = commands.NewConfigureOpsman(os.Environ, api, stderr)
-	commandSet["configure-product"] = commands.NewConfigureProduct(os.Environ, api, global.Target, stdout)
-	commandSet["configure-saml-authentication"] = commands.NewConfigureSAMLAuthentication(os.Environ, api, stdout)
+	commandSet["config-template"] = commands.NewConfigTemplate(commands.DefaultProvider(), varsSourceConfig)
+	commandSet["configure-authentication"] = commands.NewConfigureAuthentication(os.Environ, varsSourceConfig, api, stdout)
+	commandSet["configure-director"] = commands.NewConfigureDirector(os.Environ, varsSourceConfig, api, stdout)
+	commandSet["configure-ldap-authentication"] = commands.NewConfigureLDAPAuthentication(os.Environ, varsSourceConfig, api, stdout)
+	commandSet["configure-opsman"] = commands.NewConfigureOpsman(os.Environ, varsSourceConfig, api, stderr)
+	commandSet["configure-product"] = commands.NewConfigureProduct(os.Environ, varsSourceConfig, api, global.Target, stdout)
+	commandSet["configure-saml-authentication"] = commands.NewConfigureSAMLAuthentication(os.Environ, varsSourceConfig, api, stdout)
+	commandSet["converge"] = commands.NewConverge(os.Environ, varsSourceConfig, api, commandSet, stdout)
 	commandSet["create-certificate-authority"] = commands.NewCreateCertificateAuthority(api, presenter)
-	commandSet["create-vm-extension"] = commands.NewCreateVMExtension(os.Environ, api, stdout)
+	commandSet["create-vm-extension"] = commands.NewCreateVMExtension(os.Environ, varsSourceConfig, api, stdout)
 	commandSet["credential-references"] = commands.NewCredentialReferences(api, presenter, stdout)
 	commandSet["credentials"] = commands.NewCredentials(api, presenter, stdout)
 	commandSet["curl"] = commands.NewCurl(api, stdout, stderr)
@@ -161,36 +278,68 @@ func Main(sout io.Writer, serr io.Writer, version string, applySleepDurationStri
 	commandSet["deployed-manifest"] = commands.NewDeployedManifest(api, stdout)
 	commandSet["deployed-products"] = commands.NewDeployedProducts(presenter, api)
 	commandSet["diagnostic-report"] = commands.NewDiagnosticReport(presenter, api)
-	commandSet["disable-director-verifiers"] = commands.NewDisableDirectorVerifiers(presenter, api, stdout)
+	commandSet["disable-director-verifiers"] = commands.NewDisableDirectorVerifiers(varsSourceConfig, presenter, api, stdout)
 	commandSet["disable-product-verifiers"] = commands.NewDisableProductVerifiers(presenter, api, stdout)
-	commandSet["download-product"] = commands.NewDownloadProduct(os.Environ, stdout, stderr, os.Stderr, api)
+	commandSet["download-product"] = commands.NewDownloadProduct(os.Environ, varsSourceConfig, stdout, stderr, os.Stderr, api)
 	commandSet["errands"] = commands.NewErrands(presenter, api)
-	commandSet["expiring-certificates"] = commands.NewExpiringCertificates(api, stdout)
+	commandSet["expiring-certificates"] = commands.NewExpiringCertificates(api, presenter, stdout)
+	commandSet["export-foundation-config"] = commands.NewExportFoundationConfig(api, stdout)
 	commandSet["export-installation"] = commands.NewExportInstallation(api, stderr)
+	commandSet["exporter"] = commands.NewExporter(api, stdout, http.ListenAndServe)
+	commandSet["fake-opsman"] = commands.NewFakeOpsman(stdout, http.ListenAndServe)
 	commandSet["generate-certificate"] = commands.NewGenerateCertificate(api, stdout)
 	commandSet["generate-certificate-authority"] = commands.NewGenerateCertificateAuthority(api, presenter)
 	commandSet["help"] = commands.NewHelp(os.Stdout, globalFlagsUsage, commandSet)
-	commandSet["import-installation"] = commands.NewImportInstallation(form, api, global.DecryptionPassphrase, stdout)
-	commandSet["installation-log"] = commands.NewInstallationLog(api, stdout)
+	if command == "import-installation" {
+		// the passphrase is sent by the command itself, rather than by the decrypt client
+		err = secrets.resolveDecryptionPassphrase(&global)
+		if err != nil {
+			return err
+		}
+	}
+	commandSet["import-installation"] = commands.NewImportInstallation(varsSourceConfig, form, api, global.DecryptionPassphrase, stdout)
+	commandSet["installation-log"] = commands.NewInstallationLog(api, presenter)
+	commandSet["installation-stats"] = commands.NewInstallationStats(api, presenter, time.Now)
+	commandSet["installation-summary"] = commands.NewInstallationSummary(api, presenter)
 	commandSet["installations"] = commands.NewInstallations(api, presenter)
-	commandSet["interpolate"] = commands.NewInterpolate(os.Environ, stdout, os.Stdin)
+	commandSet["interpolate"] = commands.NewInterpolate(os.Environ, varsSourceConfig, stdout, os.Stdin)
+	commandSet["login"] = commands.NewLogin(loginClient, stdout, os.Stdin)
 	commandSet["pending-changes"] = commands.NewPendingChanges(presenter, api)
 	commandSet["pre-deploy-check"] = commands.NewPreDeployCheck(presenter, api, stdout)
 	commandSet["product-metadata"] = commands.NewProductMetadata(stdout)
+	commandSet["profiles"] = commands.NewProfiles(profiles.NewStore(profilesPath), stdout)
 	commandSet["regenerate-certificates"] = commands.NewRegenerateCertificates(api, stdout)
 	commandSet["revert-staged-changes"] = commands.NewRevertStagedChanges(api, stdout)
+	commandSet["rotate-certificate-authority"] = commands.NewRotateCertificateAuthority(api, logWriter, stdout, applySleepDuration)
+	commandSet["run-errand"] = commands.NewRunErrand(api, logWriter, stdout, applySleepDuration, signal.Notify)
 	commandSet["ssl-certificate"] = commands.NewSSLCertificate(api, presenter)
-	commandSet["stage-product"] = commands.NewStageProduct(api, stdout)
+	commandSet["stage-product"] = commands.NewStageProduct(varsSourceConfig, api, stdout)
 	commandSet["staged-config"] = commands.NewStagedConfig(api, stdout)
 	commandSet["staged-director-config"] = commands.NewStagedDirectorConfig(api, stdout, stderr)
 	commandSet["staged-manifest"] = commands.NewStagedManifest(api, stdout)
 	commandSet["staged-products"] = commands.NewStagedProducts(presenter, api)
 	commandSet["unstage-product"] = commands.NewUnstageProduct(api, stdout)
-	commandSet["upload-product"] = commands.NewUploadProduct(form, metadataExtractor, api, stdout)
-	commandSet["upload-stemcell"] = commands.NewUploadStemcell(form, api, stdout)
+	commandSet["upload-product"] = commands.NewUploadProduct(varsSourceConfig, form, metadataExtractor, api, stdout)
+	commandSet["upload-stemcell"] = commands.NewUploadStemcell(varsSourceConfig, form, api, stdout)
+	commandSet["validate-product-config"] = commands.NewValidateProductConfig(os.Environ, varsSourceConfig, func(productPath string) commands.MetadataProvider {
+		return metadata.NewFileProvider(productPath)
+	}, stdout)
 	commandSet["version"] = commands.NewVersion(version, sout)
 
-	err = commandSet.Execute(command, args)
+	err = executeCommand(commandSet, presenter, command, args)
+
+	if harRecorder != nil {
+		// the trace file is written even when the command fails, as that is when it is most useful
+		writeErr := harRecorder.Write(traceFile)
+		if writeErr != nil {
+			writeErr = fmt.Errorf("could not write the trace file: %s", writeErr)
+			if err == nil {
+				return writeErr
+			}
+			stderr.Println(writeErr)
+		}
+	}
+
 	if err != nil {
 		return err
 	}
@@ -198,7 +347,83 @@ func Main(sout io.Writer, serr io.Writer, version string, applySleepDurationStri
 	return nil
 }
 
-func setEnvFileProperties(global *options) error {
+// executeCommand behaves like jhanda.CommandSet.Execute,
+// but wraps the command's error so callers can still match it with errors.Is.
+// A command presenting its output with a failing format template fails too.
+func executeCommand(commandSet jhanda.CommandSet, presenter *presenters.MultiPresenter, command string, args []string) error {
+	cmd, ok := commandSet[command]
+	if !ok {
+		return commandSet.Execute(command, args)
+	}
+
+	for _, arg := range args {
+		if arg == "--help" || arg == "-h" || arg == "-help" {
+			return commandSet.Execute(command, args)
+		}
+	}
+
+	err := cmd.Execute(args)
+	if err == nil {
+		err = presenter.Err()
+	}
+	if err != nil {
+		return fmt.Errorf("could not execute %q: %w", command, err)
+	}
+
+	return nil
+}
+
+// explicitOptions returns the long names of the global options
+// that the args or the environment set.
+// The defaults of the options cannot be told apart from the same values set explicitly,
+// so it parses the args again, without the defaults.
+func explicitOptions(args []string) map[string]bool {
+	explicit := map[string]bool{}
+	longNames := map[string]string{}
+
+	set := flag.NewFlagSet("om", flag.ContinueOnError)
+	set.SetOutput(ioutil.Discard)
+
+	fields := reflect.TypeOf(options{})
+	for i := 0; i < fields.NumField(); i++ {
+		field := fields.Field(i)
+
+		long := field.Tag.Get("long")
+		if long == "" {
+			continue
+		}
+
+		for _, env := range strings.Split(field.Tag.Get("env"), ",") {
+			if env != "" && os.Getenv(env) != "" {
+				explicit[long] = true
+			}
+		}
+
+		for _, name := range []string{long, field.Tag.Get("short")} {
+			if name == "" {
+				continue
+			}
+
+			longNames[name] = long
+			if field.Type.Kind() == reflect.Bool {
+				set.Bool(name, false, "")
+			} else {
+				set.String(name, "", "")
+			}
+		}
+	}
+
+	// parsing stops at the command, and the args have already been parsed by jhanda
+	_ = set.Parse(args)
+	set.Visit(func(f *flag.Flag) {
+		explicit[longNames[f.Name]] = true
+	})
+
+	return explicit
+}
+
+// setEnvFileProperties sets what the flags and environment leave unset from the env file.
+func setEnvFileProperties(global *options, explicit map[string]bool) error {
 	if global.Env == "" {
 		return nil
 	}
@@ -228,6 +453,18 @@ func setEnvFileProperties(global *options) error {
 		return fmt.Errorf("could not parse env file: %s", err)
 	}
 
+	// 0 disables the retries and timeouts, so these keys count when they are set at all
+	var defaulted struct {
+		ConnectTimeout  *int `yaml:"connect-timeout"`
+		RequestTimeout  *int `yaml:"request-timeout"`
+		RetryAttempts   *int `yaml:"retry-attempts"`
+		RetryMaxBackoff *int `yaml:"retry-max-backoff"`
+	}
+	err = yaml.Unmarshal(contents, &defaulted)
+	if err != nil {
+		return fmt.Errorf("could not parse env file: %s", err)
+	}
+
 	if global.ClientID == "" {
 		global.ClientID = opts.ClientID
 	}
@@ -237,11 +474,21 @@ func setEnvFileProperties(global *options) error {
 	if global.Password == "" {
 		global.Password = opts.Password
 	}
-	if global.ConnectTimeout == 10 && opts.ConnectTimeout != 0 {
-		global.ConnectTimeout = opts.ConnectTimeout
+	if !explicit["connect-timeout"] && defaulted.ConnectTimeout != nil {
+		global.ConnectTimeout = *defaulted.ConnectTimeout
+		explicit["connect-timeout"] = true
+	}
+	if !explicit["request-timeout"] && defaulted.RequestTimeout != nil {
+		global.RequestTimeout = *defaulted.RequestTimeout
+		explicit["request-timeout"] = true
 	}
-	if global.RequestTimeout == 1800 && opts.RequestTimeout != 0 {
-		global.RequestTimeout = opts.RequestTimeout
+	if !explicit["retry-attempts"] && defaulted.RetryAttempts != nil {
+		global.RetryAttempts = *defaulted.RetryAttempts
+		explicit["retry-attempts"] = true
+	}
+	if !explicit["retry-max-backoff"] && defaulted.RetryMaxBackoff != nil {
+		global.RetryMaxBackoff = *defaulted.RetryMaxBackoff
+		explicit["retry-max-backoff"] = true
 	}
 	if !global.SkipSSLValidation {
 		global.SkipSSLValidation = opts.SkipSSLValidation
@@ -252,6 +499,12 @@ func setEnvFileProperties(global *options) error {
 	if !global.Trace {
 		global.Trace = opts.Trace
 	}
+	if global.TraceFile == "" {
+		global.TraceFile = opts.TraceFile
+	}
+	if global.TokenCache == "" {
+		global.TokenCache = opts.TokenCache
+	}
 	if global.Username == "" {
 		global.Username = opts.Username
 	}
@@ -261,6 +514,54 @@ func setEnvFileProperties(global *options) error {
 	if global.CACert == "" {
 		global.CACert = opts.CACert
 	}
+	if global.ClientCert == "" {
+		global.ClientCert = opts.ClientCert
+	}
+	if global.ClientKey == "" {
+		global.ClientKey = opts.ClientKey
+	}
+	if len(global.VarsSource) == 0 {
+		global.VarsSource = opts.VarsSource
+	}
+	if global.CredHubCACert == "" {
+		global.CredHubCACert = opts.CredHubCACert
+	}
+	if global.CredHubClient == "" {
+		global.CredHubClient = opts.CredHubClient
+	}
+	if global.CredHubClientCert == "" {
+		global.CredHubClientCert = opts.CredHubClientCert
+	}
+	if global.CredHubClientKey == "" {
+		global.CredHubClientKey = opts.CredHubClientKey
+	}
+	if global.CredHubSecret == "" {
+		global.CredHubSecret = opts.CredHubSecret
+	}
+	if global.CredHubServer == "" {
+		global.CredHubServer = opts.CredHubServer
+	}
+	if global.VaultAddr == "" {
+		global.VaultAddr = opts.VaultAddr
+	}
+	if global.VaultCACert == "" {
+		global.VaultCACert = opts.VaultCACert
+	}
+	if global.VaultNamespace == "" {
+		global.VaultNamespace = opts.VaultNamespace
+	}
+	if global.VaultRoleID == "" {
+		global.VaultRoleID = opts.VaultRoleID
+	}
+	if global.VaultSecretID == "" {
+		global.VaultSecretID = opts.VaultSecretID
+	}
+	if !global.VaultSkipSSLValidation {
+		global.VaultSkipSSLValidation = opts.VaultSkipSSLValidation
+	}
+	if global.VaultToken == "" {
+		global.VaultToken = opts.VaultToken
+	}
 
 	err = checkForVars(global)
 	if err != nil {
@@ -270,6 +571,128 @@ func setEnvFileProperties(global *options) error {
 	return nil
 }
 
+// commandsWithoutProfile do not target an Ops Manager,
+// so a profile whose secrets cannot be read does not break them.
+var commandsWithoutProfile = map[string]bool{
+	"help":        true,
+	"interpolate": true,
+	"profiles":    true,
+	"version":     true,
+}
+
+// setProfileProperties sets what the flags, environment and env file leave unset
+// from the profile, or from the current profile when no target is set either.
+// The credentials of the profile are only used when none are set,
+// and its secrets are returned to be read once they are needed.
+func setProfileProperties(global *options, explicit map[string]bool) (profileSecrets, error) {
+	path, err := profiles.DefaultPath()
+	if err != nil {
+		if global.Profile != "" {
+			return profileSecrets{}, err
+		}
+		return profileSecrets{}, nil
+	}
+
+	config, err := profiles.NewStore(path).Load()
+	if err != nil {
+		return profileSecrets{}, err
+	}
+
+	name := global.Profile
+	if name == "" {
+		if config.Current == "" || global.Target != "" {
+			return profileSecrets{}, nil
+		}
+		name = config.Current
+	}
+
+	profile, ok := config.Profiles[name]
+	if !ok {
+		return profileSecrets{}, fmt.Errorf("could not find profile %s in %s", name, path)
+	}
+
+	if global.Target == "" {
+		global.Target = profile.Target
+	}
+	if global.CACert == "" {
+		global.CACert = profile.CACert
+	}
+	if !global.SkipSSLValidation {
+		global.SkipSSLValidation = profile.SkipSSLValidation
+	}
+	if global.ClientCert == "" {
+		global.ClientCert = profile.ClientCert
+	}
+	if global.ClientKey == "" {
+		global.ClientKey = profile.ClientKey
+	}
+	if !explicit["connect-timeout"] && profile.ConnectTimeout != 0 {
+		global.ConnectTimeout = profile.ConnectTimeout
+	}
+	if !explicit["request-timeout"] && profile.RequestTimeout != 0 {
+		global.RequestTimeout = profile.RequestTimeout
+	}
+
+	if global.Username == "" && global.ClientID == "" {
+		global.Username = profile.Username
+		global.ClientID = profile.ClientID
+	}
+
+	secrets := profileSecrets{profile: name}
+	if global.Username == profile.Username && global.Username != "" && global.Password == "" {
+		secrets.password = profile.Password
+	}
+	if global.ClientID == profile.ClientID && global.ClientID != "" && global.ClientSecret == "" {
+		secrets.clientSecret = profile.ClientSecret
+	}
+	if global.DecryptionPassphrase == "" {
+		secrets.decryptionPassphrase = profile.DecryptionPassphrase
+	}
+
+	return secrets, nil
+}
+
+// profileSecrets are the secrets of a profile that the flags, environment and env file leave unset.
+type profileSecrets struct {
+	profile              string
+	password             *profiles.Secret
+	clientSecret         *profiles.Secret
+	decryptionPassphrase *profiles.Secret
+}
+
+func (s *profileSecrets) resolveCredentials(global *options) error {
+	var err error
+	if s.password != nil {
+		global.Password, err = s.password.Resolve()
+		if err != nil {
+			return fmt.Errorf("could not read the password of profile %s: %s", s.profile, err)
+		}
+		s.password = nil
+	}
+	if s.clientSecret != nil {
+		global.ClientSecret, err = s.clientSecret.Resolve()
+		if err != nil {
+			return fmt.Errorf("could not read the client secret of profile %s: %s", s.profile, err)
+		}
+		s.clientSecret = nil
+	}
+
+	return nil
+}
+
+func (s *profileSecrets) resolveDecryptionPassphrase(global *options) error {
+	if s.decryptionPassphrase != nil {
+		passphrase, err := s.decryptionPassphrase.Resolve()
+		if err != nil {
+			return fmt.Errorf("could not read the decryption passphrase of profile %s: %s", s.profile, err)
+		}
+		global.DecryptionPassphrase = passphrase
+		s.decryptionPassphrase = nil
+	}
+
+	return nil
+}
+
 func checkForVars(opts *options) error {
 	var errBuffer []string
 
@@ -283,6 +706,10 @@ func checkForVars(opts *options) error {
 		errBuffer = append(errBuffer, "* use OM_CLIENT_ID environment variable for the client-id value")
 	}
 
+	if interpolateRegex.MatchString(opts.ClientKey) {
+		errBuffer = append(errBuffer, "* use OM_CLIENT_KEY environment variable for the client-key value")
+	}
+
 	if interpolateRegex.MatchString(opts.ClientSecret) {
 		errBuffer = append(errBuffer, "* use OM_CLIENT_SECRET environment variable for the client-secret value")
 	}
@@ -299,6 +726,18 @@ func checkForVars(opts *options) error {
 		errBuffer = append(errBuffer, "* use OM_USERNAME environment variable for the username value")
 	}
 
+	if interpolateRegex.MatchString(opts.CredHubSecret) {
+		errBuffer = append(errBuffer, "* use CREDHUB_SECRET environment variable for the credhub-secret value")
+	}
+
+	if interpolateRegex.MatchString(opts.VaultSecretID) {
+		errBuffer = append(errBuffer, "* use VAULT_SECRET_ID environment variable for the vault-secret-id value")
+	}
+
+	if interpolateRegex.MatchString(opts.VaultToken) {
+		errBuffer = append(errBuffer, "* use VAULT_TOKEN environment variable for the vault-token value")
+	}
+
 	if len(errBuffer) > 0 {
 		errBuffer = append([]string{"env file contains YAML placeholders. Pleases provide them via interpolation or environment variables."}, errBuffer...)
 		errBuffer = append(errBuffer, "Or, to enable interpolation of env.yml with variables from env-vars,")
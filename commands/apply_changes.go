@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"gopkg.in/yaml.v2"
 	"os"
+	"os/signal"
 	"sort"
+	"syscall"
 	"time"
 
 	"github.com/pivotal-cf/jhanda"
@@ -19,13 +21,16 @@ type ApplyChanges struct {
 	logger         logger
 	logWriter      logWriter
 	waitDuration   time.Duration
+	notifySignals  func(chan<- os.Signal, ...os.Signal)
 	Options        struct {
-		Config             string   `short:"c"   long:"config"               description:"path to yml file containing errand configuration (see docs/apply-changes/README.md for format)"`
-		IgnoreWarnings     bool     `short:"i"   long:"ignore-warnings"      description:"For convenience. Use other commands to disable particular verifiers if they are inappropriate."`
-		Reattach           bool     `long:"reattach" description:"reattach to an already running apply changes (if available)"`
-		RecreateVMs        bool     `long:"recreate-vms" description:"recreate all vms"`
-		SkipDeployProducts bool     `short:"sdp" long:"skip-deploy-products" description:"skip deploying products when applying changes - just update the director"`
-		ProductNames       []string `short:"n"   long:"product-name"         description:"name of the product(s) to deploy, cannot be used in conjunction with --skip-deploy-products (OM 2.2+)"`
+		Config             string        `short:"c"   long:"config"               description:"path to yml file containing errand configuration (see docs/apply-changes/README.md for format)"`
+		IgnoreWarnings     bool          `short:"i"   long:"ignore-warnings"      description:"For convenience. Use other commands to disable particular verifiers if they are inappropriate."`
+		Output             string        `long:"output" default:"text" description:"format of the installation progress: text (raw installation log) or json (newline-delimited events)"`
+		Timeout            time.Duration `long:"timeout" description:"stop waiting for the installation after this long (e.g. 90m, 4h) and exit with status 3; the installation keeps running on the Ops Manager"`
+		Reattach           bool          `long:"reattach" description:"reattach to an already running apply changes (if available)"`
+		RecreateVMs        bool          `long:"recreate-vms" description:"recreate all vms"`
+		SkipDeployProducts bool          `short:"sdp" long:"skip-deploy-products" description:"skip deploying products when applying changes - just update the director"`
+		ProductNames       []string      `short:"n"   long:"product-name"         description:"name of the product(s) to deploy, cannot be used in conjunction with --skip-deploy-products (OM 2.2+)"`
 	}
 }
 
@@ -45,13 +50,19 @@ type logWriter interface {
 	Flush(logs string) error
 }
 
-func NewApplyChanges(service applyChangesService, pendingService pendingChangesService, logWriter logWriter, logger logger, waitDuration time.Duration) ApplyChanges {
+var (
+	ErrApplyChangesTimedOut    = errors.New("timed out waiting for the installation to finish")
+	ErrApplyChangesInterrupted = errors.New("interrupted while waiting for the installation to finish")
+)
+
+func NewApplyChanges(service applyChangesService, pendingService pendingChangesService, logWriter logWriter, logger logger, waitDuration time.Duration, notifySignals func(chan<- os.Signal, ...os.Signal)) ApplyChanges {
 	return ApplyChanges{
 		service:        service,
 		pendingService: pendingService,
 		logger:         logger,
 		logWriter:      logWriter,
 		waitDuration:   waitDuration,
+		notifySignals:  notifySignals,
 	}
 }
 
@@ -60,6 +71,10 @@ func (ac ApplyChanges) Execute(args []string) error {
 		return fmt.Errorf("could not parse apply-changes flags: %s", err)
 	}
 
+	if ac.Options.Output != "text" && ac.Options.Output != "json" {
+		return fmt.Errorf("--output must be one of: text, json")
+	}
+
 	if ac.Options.RecreateVMs && ac.Options.Reattach {
 		return fmt.Errorf("--recreate-vms cannot be used with --reattach because it requires the ability to update a director property")
 	}
@@ -102,13 +117,13 @@ func (ac ApplyChanges) Execute(args []string) error {
 		startedAtFormatted := installation.StartedAt.Format(time.UnixDate)
 
 		if ac.Options.Reattach {
-			ac.logger.Printf("found already running installation... re-attaching (Installation ID: %d, Started: %s)", installation.ID, startedAtFormatted)
-			err = ac.waitForApplyChangesCompletion(installation)
-			ac.logger.Printf("found already running installation... re-attaching (Installation ID: %d, Started: %s)", installation.ID, startedAtFormatted)
+			ac.say("found already running installation... re-attaching (Installation ID: %d, Started: %s)", installation.ID, startedAtFormatted)
+			err = ac.waitForApplyChangesCompletion(installation, true)
+			ac.say("found already running installation... re-attaching (Installation ID: %d, Started: %s)", installation.ID, startedAtFormatted)
 
 			return err
 		} else {
-			ac.logger.Printf("found already running installation... not re-attaching (Installation ID: %d, Started: %s)", installation.ID, startedAtFormatted)
+			ac.say("found already running installation... not re-attaching (Installation ID: %d, Started: %s)", installation.ID, startedAtFormatted)
 			return fmt.Errorf("apply changes is already running, use \"--reattach\" to enable reattaching")
 		}
 	}
@@ -122,19 +137,19 @@ func (ac ApplyChanges) Execute(args []string) error {
 		}
 
 		if len(ac.Options.ProductNames) > 0 {
-			ac.logger.Println("setting director to recreate all VMs for the following products:")
+			ac.say("setting director to recreate all VMs for the following products:")
 			sort.Strings(ac.Options.ProductNames)
 
 			for _, product := range ac.Options.ProductNames {
-				ac.logger.Printf("- %s", product)
+				ac.say("- %s", product)
 			}
-			ac.logger.Println("this will also recreate the director vm if there are changes")
+			ac.say("this will also recreate the director vm if there are changes")
 			config.DirectorConfiguration.ProductRecreate = true
 		} else if ac.Options.SkipDeployProducts {
-			ac.logger.Println("setting director to recreate director vm (available in Ops Manager 2.9+)")
+			ac.say("setting director to recreate director vm (available in Ops Manager 2.9+)")
 			config.DirectorConfiguration.DirectorRecreate = true
 		} else {
-			ac.logger.Println("setting director to recreate all vms (available in Ops Manager 2.9+)")
+			ac.say("setting director to recreate all vms (available in Ops Manager 2.9+)")
 			config.DirectorConfiguration.ProductRecreate = true
 			config.DirectorConfiguration.DirectorRecreate = true
 		}
@@ -161,42 +176,117 @@ func (ac ApplyChanges) Execute(args []string) error {
 		}
 	}
 
-	ac.logger.Printf("attempting to apply changes to the targeted Ops Manager")
+	ac.say("attempting to apply changes to the targeted Ops Manager")
 	installation, err = ac.service.CreateInstallation(ac.Options.IgnoreWarnings, !ac.Options.SkipDeployProducts, changedProducts, errands)
 	if err != nil {
 		return fmt.Errorf("installation failed to trigger: %s", err)
 	}
 
-	return ac.waitForApplyChangesCompletion(installation)
+	return ac.waitForApplyChangesCompletion(installation, false)
+}
+
+func (ac ApplyChanges) say(format string, v ...interface{}) {
+	if ac.Options.Output == "json" {
+		_ = newInstallationEventStream(ac.logger, 0, time.Time{}).message(format, v...)
+		return
+	}
+
+	ac.logger.Printf(format, v...)
 }
 
-func (ac ApplyChanges) waitForApplyChangesCompletion(installation api.InstallationsServiceOutput) error {
+func (ac ApplyChanges) waitForApplyChangesCompletion(installation api.InstallationsServiceOutput, reattached bool) error {
+	var events *installationEventStream
+	if ac.Options.Output == "json" {
+		startedAt := time.Now()
+		if installation.StartedAt != nil {
+			startedAt = *installation.StartedAt
+		}
+
+		events = newInstallationEventStream(ac.logger, installation.ID, startedAt)
+
+		err := events.started(reattached)
+		if err != nil {
+			return err
+		}
+	}
+
+	interrupts := make(chan os.Signal, 1)
+	ac.notifySignals(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+
+	var timeout <-chan time.Time
+	if ac.Options.Timeout > 0 {
+		timeout = time.After(ac.Options.Timeout)
+	}
+
 	for {
 		current, err := ac.service.GetInstallation(installation.ID)
 		if err != nil {
 			return fmt.Errorf("installation failed to get status: %s", err)
 		}
 
+		if events != nil {
+			err = events.statusChanged(current.Status)
+			if err != nil {
+				return err
+			}
+		}
+
 		install, err := ac.service.GetInstallationLogs(installation.ID)
 		if err != nil {
 			return fmt.Errorf("installation failed to get logs: %s", err)
 		}
 
-		err = ac.logWriter.Flush(install.Logs)
+		if events != nil {
+			err = events.logs(install.Logs)
+		} else {
+			err = ac.logWriter.Flush(install.Logs)
+		}
 		if err != nil {
 			return fmt.Errorf("installation failed to flush logs: %s", err)
 		}
 
-		if current.Status == api.StatusSucceeded {
+		if current.Status == api.StatusSucceeded || current.Status == api.StatusFailed {
+			if events != nil {
+				err = events.finished(current.Status)
+				if err != nil {
+					return err
+				}
+			}
+
+			if current.Status == api.StatusFailed {
+				return errors.New("installation was unsuccessful")
+			}
+
 			return nil
-		} else if current.Status == api.StatusFailed {
-			return errors.New("installation was unsuccessful")
 		}
 
-		time.Sleep(ac.waitDuration)
+		select {
+		case <-time.After(ac.waitDuration):
+		case <-timeout:
+			return ac.detach(events, installation.ID, ErrApplyChangesTimedOut)
+		case sig := <-interrupts:
+			return ac.detach(events, installation.ID, fmt.Errorf("%w by %s", ErrApplyChangesInterrupted, sig))
+		}
 	}
 }
 
+// detach stops waiting on an installation, leaving it running on the Ops Manager.
+func (ac ApplyChanges) detach(events *installationEventStream, installationID int, reason error) error {
+	message := fmt.Sprintf("stopped waiting for installation %d, which is still running on the Ops Manager: %s", installationID, reason)
+
+	if events != nil {
+		err := events.detached(message)
+		if err != nil {
+			return err
+		}
+	} else {
+		ac.logger.Println(message)
+	}
+
+	return fmt.Errorf("%w (Installation ID: %d). Use \"om apply-changes --reattach\" to continue watching it or \"om cancel-installation\" to abort it", reason, installationID)
+}
+
 func (ac ApplyChanges) Usage() jhanda.Usage {
 	return jhanda.Usage{
 		Description:      "This authenticated command kicks off an install of any staged changes on the Ops Manager.",
@@ -1,6 +1,8 @@
 package commands_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/onsi/gomega/gbytes"
@@ -9,6 +11,7 @@ import (
 	"log"
 	"os"
 	"regexp"
+	"syscall"
 	"time"
 
 	"github.com/pivotal-cf/om/api"
@@ -26,9 +29,15 @@ var _ = Describe("ApplyChanges", func() {
 		logger         *log.Logger
 		stderr         *gbytes.Buffer
 		writer         *fakes.LogWriter
+		signals        chan<- os.Signal
+		notifySignals  func(chan<- os.Signal, ...os.Signal)
 	)
 
 	BeforeEach(func() {
+		signals = nil
+		notifySignals = func(c chan<- os.Signal, _ ...os.Signal) {
+			signals = c
+		}
 		service = &fakes.ApplyChangesService{}
 		pendingService = &fakes.PendingChangesService{}
 		stderr = gbytes.NewBuffer()
@@ -53,7 +62,7 @@ var _ = Describe("ApplyChanges", func() {
 		})
 
 		It("applies changes to the Ops Manager", func() {
-			command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+			command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 			err := command.Execute([]string{})
 			Expect(err).ToNot(HaveOccurred())
@@ -82,7 +91,7 @@ var _ = Describe("ApplyChanges", func() {
 			It("applies changes while ignoring warnings", func() {
 				service.InfoReturns(api.Info{Version: "2.3-build43"}, nil)
 
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 				err := command.Execute([]string{"--ignore-warnings"})
 				Expect(err).ToNot(HaveOccurred())
@@ -94,7 +103,7 @@ var _ = Describe("ApplyChanges", func() {
 
 		When("passed the skip-deploy-products flag", func() {
 			It("applies changes while not deploying products", func() {
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 				err := command.Execute([]string{"--skip-deploy-products"})
 				Expect(err).ToNot(HaveOccurred())
@@ -104,7 +113,7 @@ var _ = Describe("ApplyChanges", func() {
 			})
 
 			It("fails if product names were specified", func() {
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 				err := command.Execute([]string{"--skip-deploy-products", "--product-name", "product1"})
 				Expect(err).To(HaveOccurred())
 			})
@@ -116,7 +125,7 @@ var _ = Describe("ApplyChanges", func() {
 				service.CreateInstallationReturns(api.InstallationsServiceOutput{}, errors.New("error"))
 				service.RunningInstallationReturns(api.InstallationsServiceOutput{}, nil)
 
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 				err := command.Execute([]string{"--product-name", "product1", "--product-name", "product2"})
 				Expect(err).To(HaveOccurred())
 
@@ -135,7 +144,7 @@ var _ = Describe("ApplyChanges", func() {
 					StartedAt: &installationStartedAt,
 				}, nil)
 
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 				err := command.Execute([]string{"--reattach"})
 				Expect(err).ToNot(HaveOccurred())
@@ -151,7 +160,7 @@ var _ = Describe("ApplyChanges", func() {
 
 			When("the recreate-vms flag is also passed", func() {
 				It("errors because this is a conflict", func() {
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{"--reattach", "--recreate-vms"})
 					Expect(err).To(MatchError(ContainSubstring("--recreate-vms cannot be used with --reattach because it requires the ability to update a director property")))
@@ -159,6 +168,116 @@ var _ = Describe("ApplyChanges", func() {
 			})
 		})
 
+		When("passed --output json", func() {
+			decodeEvents := func() []commands.InstallationEvent {
+				var events []commands.InstallationEvent
+				decoder := json.NewDecoder(bytes.NewReader(stderr.Contents()))
+				for decoder.More() {
+					var event commands.InstallationEvent
+					Expect(decoder.Decode(&event)).To(Succeed())
+					events = append(events, event)
+				}
+				return events
+			}
+
+			It("emits newline-delimited json events instead of the raw log", func() {
+				service.GetInstallationLogsReturnsOnCall(0, api.InstallationsServiceOutput{Logs: "start of logs\n===== 2020-07-01 17:44:55 UTC Running \"/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-1234 run-errand smoke_tests\"\n"}, nil)
+				service.GetInstallationLogsReturnsOnCall(1, api.InstallationsServiceOutput{Logs: "start of logs\n===== 2020-07-01 17:44:55 UTC Running \"/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-1234 run-errand smoke_tests\"\n===== 2020-07-01 17:49:55 UTC Finished \"/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-1234 run-errand smoke_tests\"; Duration: 300s; Exit Status: 0\n"}, nil)
+				service.GetInstallationLogsReturnsOnCall(2, api.InstallationsServiceOutput{Logs: "start of logs\n===== 2020-07-01 17:44:55 UTC Running \"/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-1234 run-errand smoke_tests\"\n===== 2020-07-01 17:49:55 UTC Finished \"/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-1234 run-errand smoke_tests\"; Duration: 300s; Exit Status: 0\n"}, nil)
+
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
+
+				err := command.Execute([]string{"--output", "json"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(writer.FlushCallCount()).To(Equal(0))
+
+				events := decodeEvents()
+
+				var types []string
+				for _, event := range events {
+					types = append(types, event.Type)
+				}
+				Expect(types).To(Equal([]string{
+					"message",
+					"installation_started",
+					"status_changed",
+					"log",
+					"errand_started",
+					"log",
+					"errand_finished",
+					"status_changed",
+					"installation_finished",
+				}))
+
+				Expect(events[0].Message).To(Equal("attempting to apply changes to the targeted Ops Manager"))
+				Expect(events[1].InstallationID).To(Equal(311))
+				Expect(events[1].Reattached).To(BeFalse())
+				Expect(events[2].Status).To(Equal("running"))
+				Expect(events[3].Log).To(HavePrefix("start of logs\n"))
+
+				Expect(events[4].Errand).To(Equal("smoke_tests"))
+				Expect(events[4].Deployment).To(Equal("cf-1234"))
+
+				Expect(events[5].Log).To(HavePrefix("===== 2020-07-01 17:49:55 UTC Finished"))
+
+				Expect(events[6].Errand).To(Equal("smoke_tests"))
+				Expect(*events[6].ExitStatus).To(Equal(0))
+				Expect(*events[6].DurationSeconds).To(Equal(300.0))
+
+				Expect(events[7].Status).To(Equal("succeeded"))
+				Expect(events[7].PreviousStatus).To(Equal("running"))
+
+				Expect(events[8].InstallationID).To(Equal(311))
+				Expect(events[8].Status).To(Equal("succeeded"))
+				Expect(events[8].DurationSeconds).ToNot(BeNil())
+			})
+
+			It("emits a finished event before failing", func() {
+				service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{Status: "failed"}, nil)
+
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
+
+				err := command.Execute([]string{"--output", "json"})
+				Expect(err).To(MatchError("installation was unsuccessful"))
+
+				events := decodeEvents()
+				Expect(events[len(events)-1].Type).To(Equal("installation_finished"))
+				Expect(events[len(events)-1].Status).To(Equal("failed"))
+			})
+
+			It("marks a reattached installation and measures its duration from when it started", func() {
+				installationStartedAt := time.Now().Add(-time.Hour)
+
+				service.RunningInstallationReturns(api.InstallationsServiceOutput{
+					ID:        200,
+					Status:    "running",
+					StartedAt: &installationStartedAt,
+				}, nil)
+
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
+
+				err := command.Execute([]string{"--reattach", "--output", "json"})
+				Expect(err).ToNot(HaveOccurred())
+
+				events := decodeEvents()
+				Expect(events[1].Type).To(Equal("installation_started"))
+				Expect(events[1].InstallationID).To(Equal(200))
+				Expect(events[1].Reattached).To(BeTrue())
+
+				finished := events[len(events)-2]
+				Expect(finished.Type).To(Equal("installation_finished"))
+				Expect(*finished.DurationSeconds).To(BeNumerically(">=", time.Hour.Seconds()))
+			})
+
+			It("errors for an unknown output format", func() {
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
+
+				err := command.Execute([]string{"--output", "xml"})
+				Expect(err).To(MatchError("--output must be one of: text, json"))
+			})
+		})
+
 		When("not passed reattach", func() {
 			It("errors of an already running installation", func() {
 				installationStartedAt := time.Date(2017, time.February, 25, 02, 31, 1, 0, time.UTC)
@@ -169,7 +288,7 @@ var _ = Describe("ApplyChanges", func() {
 					StartedAt: &installationStartedAt,
 				}, nil)
 
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 				err := command.Execute([]string{})
 				Expect(err).To(HaveOccurred())
@@ -182,7 +301,7 @@ var _ = Describe("ApplyChanges", func() {
 
 		When("passed the recreate-vms", func() {
 			It("ensures all vms are recreated", func() {
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 				err := command.Execute([]string{"--recreate-vms"})
 				Expect(err).ToNot(HaveOccurred())
@@ -201,7 +320,7 @@ var _ = Describe("ApplyChanges", func() {
 			})
 
 			It("ensures only the director is recreated", func() {
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 				err := command.Execute([]string{
 					"--recreate-vms",
@@ -222,7 +341,7 @@ var _ = Describe("ApplyChanges", func() {
 			})
 
 			It("ensures only products are updated", func() {
-				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 				err := command.Execute([]string{
 					"--recreate-vms",
@@ -250,7 +369,7 @@ var _ = Describe("ApplyChanges", func() {
 				It("ensures only products are updated", func() {
 					service.InfoReturns(api.Info{Version: "2.6.0"}, nil)
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{
 						"--recreate-vms",
@@ -278,7 +397,7 @@ var _ = Describe("ApplyChanges", func() {
 			When("the service returns an error", func() {
 				It("displays that error message", func() {
 					service.UpdateStagedDirectorPropertiesReturns(errors.New("testing"))
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{"--recreate-vms"})
 					Expect(err).To(MatchError(ContainSubstring("testing")))
@@ -346,7 +465,7 @@ errands:
 				})
 
 				It("calls the api with correct arguments", func() {
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{"--config", fileName})
 					Expect(err).ToNot(HaveOccurred())
@@ -394,7 +513,7 @@ errands:
 
 			Context("given a file that does not exist", func() {
 				It("returns an error", func() {
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{"--config", "filedoesnotexist"})
 					Expect(err).To(MatchError("could not load config: open filedoesnotexist: no such file or directory"))
@@ -416,7 +535,7 @@ errands: lolololol
 				})
 
 				It("returns an error", func() {
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{"--config", fileName})
 					Expect(err).To(MatchError(ContainSubstring("line 3: cannot unmarshal !!str `lolololol`")))
@@ -424,12 +543,65 @@ errands: lolololol
 			})
 		})
 
+		When("passed a timeout", func() {
+			It("stops waiting once the timeout has passed and leaves the installation running", func() {
+				service.GetInstallationReturns(api.InstallationsServiceOutput{Status: "running"}, nil)
+				service.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: "some logs"}, nil)
+				service.GetInstallationReturnsOnCall(2, api.InstallationsServiceOutput{Status: "running"}, nil)
+
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 10*time.Millisecond, notifySignals)
+
+				err := command.Execute([]string{"--timeout", "50ms"})
+				Expect(errors.Is(err, commands.ErrApplyChangesTimedOut)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring("(Installation ID: 311). Use \"om apply-changes --reattach\"")))
+
+				Expect(stderr).To(gbytes.Say(regexp.QuoteMeta("stopped waiting for installation 311, which is still running on the Ops Manager: timed out waiting for the installation to finish")))
+				Expect(service.GetInstallationCallCount()).To(BeNumerically(">", 1))
+			})
+
+			It("emits a detached event in json mode", func() {
+				service.GetInstallationReturns(api.InstallationsServiceOutput{Status: "running"}, nil)
+				service.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: "some logs"}, nil)
+				service.GetInstallationReturnsOnCall(2, api.InstallationsServiceOutput{Status: "running"}, nil)
+
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, 10*time.Millisecond, notifySignals)
+
+				err := command.Execute([]string{"--timeout", "50ms", "--output", "json"})
+				Expect(errors.Is(err, commands.ErrApplyChangesTimedOut)).To(BeTrue())
+
+				lines := bytes.Split(bytes.TrimSpace(stderr.Contents()), []byte("\n"))
+				var event commands.InstallationEvent
+				Expect(json.Unmarshal(lines[len(lines)-1], &event)).To(Succeed())
+				Expect(event.Type).To(Equal("installation_detached"))
+				Expect(event.InstallationID).To(Equal(311))
+				Expect(event.Status).To(Equal("running"))
+			})
+		})
+
+		When("interrupted while waiting", func() {
+			It("stops polling and prints the installation id so it can be re-attached", func() {
+				service.GetInstallationStub = func(int) (api.InstallationsServiceOutput, error) {
+					signals <- syscall.SIGTERM
+					return api.InstallationsServiceOutput{Status: "running"}, nil
+				}
+
+				command := commands.NewApplyChanges(service, pendingService, writer, logger, time.Minute, notifySignals)
+
+				err := command.Execute([]string{})
+				Expect(errors.Is(err, commands.ErrApplyChangesInterrupted)).To(BeTrue())
+				Expect(err).To(MatchError(ContainSubstring("interrupted while waiting for the installation to finish by terminated (Installation ID: 311)")))
+
+				Expect(service.GetInstallationCallCount()).To(Equal(1))
+				Expect(stderr).To(gbytes.Say(regexp.QuoteMeta("stopped waiting for installation 311, which is still running on the Ops Manager")))
+			})
+		})
+
 		It("handles a failed installation", func() {
 			service.CreateInstallationReturns(api.InstallationsServiceOutput{ID: 311}, nil)
 			service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{Status: "failed"}, nil)
 			service.GetInstallationLogsReturnsOnCall(0, api.InstallationsServiceOutput{Logs: "start of logs"}, nil)
 
-			command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+			command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 			err := command.Execute([]string{})
 			Expect(err).To(MatchError("installation was unsuccessful"))
@@ -440,7 +612,7 @@ errands: lolololol
 				It("returns an error", func() {
 					service.RunningInstallationReturns(api.InstallationsServiceOutput{}, errors.New("some error"))
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("could not check for any already running installation: some error"))
@@ -453,7 +625,7 @@ errands: lolololol
 					for _, version := range versions {
 						service.InfoReturns(api.Info{Version: version}, nil)
 
-						command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+						command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 						err := command.Execute([]string{"--product-name", "p-mysql"})
 						Expect(err).To(MatchError(fmt.Sprintf("--product-name is only available with Ops Manager 2.2 or later: you are running %s", version)))
 					}
@@ -464,7 +636,7 @@ errands: lolololol
 				It("returns an error", func() {
 					service.CreateInstallationReturns(api.InstallationsServiceOutput{}, errors.New("some error"))
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("installation failed to trigger: some error"))
@@ -476,7 +648,7 @@ errands: lolololol
 					service.CreateInstallationReturns(api.InstallationsServiceOutput{ID: 311}, nil)
 					service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{}, errors.New("another error"))
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("installation failed to get status: another error"))
@@ -489,7 +661,7 @@ errands: lolololol
 					service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{Status: "running"}, nil)
 					service.GetInstallationLogsReturnsOnCall(0, api.InstallationsServiceOutput{}, errors.New("no"))
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("installation failed to get logs: no"))
@@ -504,7 +676,7 @@ errands: lolololol
 
 					writer.FlushReturns(errors.New("yes"))
 
-					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1)
+					command := commands.NewApplyChanges(service, pendingService, writer, logger, 1, notifySignals)
 
 					err := command.Execute([]string{})
 					Expect(err).To(MatchError("installation failed to flush logs: yes"))
@@ -4,14 +4,16 @@ import (
 	"fmt"
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/interpolate"
 	"os"
 	"strings"
 )
 
 type AssignMultiStemcell struct {
-	logger  logger
-	service assignMultiStemcellService
-	Options struct {
+	varsSourceConfig interpolate.VarsSourceConfig
+	logger           logger
+	service          assignMultiStemcellService
+	Options          struct {
 		interpolateConfigFileOptions
 		ProductName string   `long:"product"  short:"p"  description:"name of Ops Manager tile to associate a stemcell to" required:"true"`
 		Stemcells   []string `long:"stemcell" short:"s"  description:"associate a particular stemcell version to a tile (ie 'ubuntu-trusty:123.4')" required:"true"`
@@ -25,10 +27,11 @@ type assignMultiStemcellService interface {
 	Info() (api.Info, error)
 }
 
-func NewAssignMultiStemcell(service assignMultiStemcellService, logger logger) AssignMultiStemcell {
+func NewAssignMultiStemcell(varsSourceConfig interpolate.VarsSourceConfig, service assignMultiStemcellService, logger logger) AssignMultiStemcell {
 	return AssignMultiStemcell{
-		service: service,
-		logger:  logger,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		logger:           logger,
 	}
 }
 
@@ -42,7 +45,7 @@ func (as AssignMultiStemcell) Usage() jhanda.Usage {
 }
 
 func (as AssignMultiStemcell) Execute(args []string) error {
-	err := loadConfigFile(args, &as.Options, os.Environ)
+	err := loadConfigFile(args, &as.Options, os.Environ, as.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse assign-stemcell flags: %s", err)
 	}
@@ -6,6 +6,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 	"io/ioutil"
 	"os"
 )
@@ -21,7 +22,7 @@ var _ = Describe("AssignMutliStemcell", func() {
 		fakeService = &fakes.AssignMultiStemcellService{}
 		fakeService.InfoReturns(api.Info{Version: "2.6.0"}, nil)
 		logger = &fakes.Logger{}
-		command = commands.NewAssignMultiStemcell(fakeService, logger)
+		command = commands.NewAssignMultiStemcell(interpolate.VarsSourceConfig{}, fakeService, logger)
 	})
 
 	When("--stemcell exists for the specified product", func() {
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"github.com/pivotal-cf/om/interpolate"
 	"os"
 	"strings"
 
@@ -10,9 +11,10 @@ import (
 )
 
 type AssignStemcell struct {
-	logger  logger
-	service assignStemcellService
-	Options struct {
+	varsSourceConfig interpolate.VarsSourceConfig
+	logger           logger
+	service          assignStemcellService
+	Options          struct {
 		interpolateConfigFileOptions
 		ProductName     string `long:"product"  short:"p"  description:"name of Ops Manager tile to associate a stemcell to" required:"true"`
 		StemcellVersion string `long:"stemcell" short:"s"  description:"associate a particular stemcell version to a tile." default:"latest"`
@@ -25,10 +27,11 @@ type assignStemcellService interface {
 	AssignStemcell(input api.ProductStemcells) error
 }
 
-func NewAssignStemcell(service assignStemcellService, logger logger) AssignStemcell {
+func NewAssignStemcell(varsSourceConfig interpolate.VarsSourceConfig, service assignStemcellService, logger logger) AssignStemcell {
 	return AssignStemcell{
-		service: service,
-		logger:  logger,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		logger:           logger,
 	}
 }
 
@@ -42,7 +45,7 @@ func (as AssignStemcell) Usage() jhanda.Usage {
 }
 
 func (as AssignStemcell) Execute(args []string) error {
-	err := loadConfigFile(args, &as.Options, os.Environ)
+	err := loadConfigFile(args, &as.Options, os.Environ, as.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse assign-stemcell flags: %s", err)
 	}
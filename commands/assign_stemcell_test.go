@@ -6,6 +6,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 	"io/ioutil"
 	"os"
 )
@@ -20,7 +21,7 @@ var _ = Describe("AssignStemcell", func() {
 	BeforeEach(func() {
 		fakeService = &fakes.AssignStemcellService{}
 		logger = &fakes.Logger{}
-		command = commands.NewAssignStemcell(fakeService, logger)
+		command = commands.NewAssignStemcell(interpolate.VarsSourceConfig{}, fakeService, logger)
 	})
 
 	When("--stemcell exists for the specified product", func() {
@@ -14,7 +14,7 @@ type AvailableProducts struct {
 	presenter presenters.FormattedPresenter
 	logger    logger
 	Options   struct {
-		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -51,7 +51,10 @@ func (ap AvailableProducts) Execute(args []string) error {
 		})
 	}
 
-	ap.presenter.SetFormat(ap.Options.Format)
+	err = ap.presenter.SetFormat(ap.Options.Format)
+	if err != nil {
+		return err
+	}
 	ap.presenter.PresentAvailableProducts(products)
 
 	return nil
@@ -4,21 +4,22 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-	"strings"
 
-	"github.com/fatih/color"
 	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+	"github.com/pivotal-cf/om/presenters"
 
 	"github.com/pivotal-cf/jhanda"
 )
 
 type BoshDiff struct {
-	service boshDiffService
-	logger  logger
-	Options struct {
+	service   boshDiffService
+	presenter presenters.FormattedPresenter
+	Options   struct {
 		Product  []string `long:"product-name" short:"p" description:"Product to get diff for. Pass repeatedly for multiple products. If excluded, all staged non-director products will be shown."`
 		Director bool     `long:"director" short:"d" description:"Include director diffs. Can be combined with --product-name."`
 		Check    bool     `long:"check" description:"Exit 2 if there are any differences. Useful for validating that Ops Manager is in a clean state."`
+		Format   string   `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -31,10 +32,10 @@ type boshDiffService interface {
 	ListStagedProducts() (api.StagedProductsOutput, error)
 }
 
-func NewBoshDiff(service boshDiffService, logger logger) BoshDiff {
+func NewBoshDiff(service boshDiffService, presenter presenters.FormattedPresenter) BoshDiff {
 	return BoshDiff{
-		service: service,
-		logger:  logger,
+		service:   service,
+		presenter: presenter,
 	}
 }
 
@@ -43,27 +44,24 @@ func (c BoshDiff) Execute(args []string) error {
 		return fmt.Errorf("could not parse bosh-diff flags: %s", err)
 	}
 
+	err := c.presenter.SetFormat(c.Options.Format)
+	if err != nil {
+		return err
+	}
+
+	var diff models.BoshDiff
 	var diffableProducts []string
 	var thereAreDiffs bool
 
 	showDirectorAndProducts := !c.Options.Director && len(c.Options.Product) == 0
 
 	if c.Options.Director || showDirectorAndProducts {
-		diff, err := c.service.DirectorDiff()
+		directorDiff, err := c.service.DirectorDiff()
 		if err != nil {
 			return fmt.Errorf("could not discover the director diff: %s", err)
 		}
-		thereAreDiffs = diff.Manifest.Status != "same"
-		c.logger.Println("## Director Manifest\n")
-		notInstalled := c.printManifestDiff(diff.Manifest)
-		if !notInstalled {
-			c.logger.Println("## Director Cloud Config\n")
-			c.printManifestDiff(diff.CloudConfig)
-			c.logger.Println("## Director Runtime Configs\n")
-			c.printRuntimeConfigs(diff.RuntimeConfigs)
-			c.logger.Println("## Director CPI Configs\n")
-			c.printCPIConfigs(diff.CPIConfigs)
-		}
+		thereAreDiffs = directorDiff.Manifest.Status != "same"
+		diff.Director = &directorDiff
 	}
 
 	if showDirectorAndProducts {
@@ -82,23 +80,19 @@ func (c BoshDiff) Execute(args []string) error {
 		diffableProducts = c.Options.Product
 	}
 
+	diff.Products = []models.ProductDiff{}
 	for _, product := range diffableProducts {
-		diff, err := c.service.ProductDiff(product)
+		productDiff, err := c.service.ProductDiff(product)
 		if err != nil {
 			return err
 		}
 
-		thereAreDiffs = thereAreDiffs || (diff.Manifest.Status != "same")
-		c.logger.Printf("## Product Manifest for %s\n\n", product)
-
-		notInstalled := c.printManifestDiff(diff.Manifest)
-		if notInstalled {
-			continue
-		}
-		c.logger.Printf("## Runtime Configs for %s\n\n", product)
-		c.printRuntimeConfigs(diff.RuntimeConfigs)
+		thereAreDiffs = thereAreDiffs || (productDiff.Manifest.Status != "same")
+		diff.Products = append(diff.Products, models.ProductDiff{Name: product, ProductDiff: productDiff})
 	}
 
+	c.presenter.PresentBoshDiff(diff)
+
 	if c.Options.Check && thereAreDiffs {
 		return ErrBoshDiffChangesExist
 	}
@@ -106,74 +100,6 @@ func (c BoshDiff) Execute(args []string) error {
 	return nil
 }
 
-func (c BoshDiff) printManifestDiff(diff api.ManifestDiff) bool {
-	switch diff.Status {
-	case "same":
-		c.logger.Println("no changes\n")
-	case "does_not_exist":
-		c.logger.Println("no manifest for this product\n")
-	case "different":
-		c.logger.Printf("%s\n\n", c.colorizeDiff(diff.Diff))
-	case "to_be_installed":
-		c.logger.Println("This product is not yet deployed, so the product and runtime diffs are not available.")
-		return true
-	default:
-		c.logger.Printf("unrecognized product status: %s\n\n%s\n\n", diff.Status, diff.Diff)
-	}
-	return false
-}
-
-func (c BoshDiff) printRuntimeConfigs(configs []api.RuntimeConfigsDiff) {
-	noneChanged := true
-
-	for _, config := range configs {
-		if config.Status == "same" {
-			continue
-		}
-
-		noneChanged = false
-
-		c.logger.Printf("### %s\n\n", config.Name)
-		c.logger.Printf("%s\n\n", c.colorizeDiff(config.Diff))
-	}
-
-	if noneChanged {
-		c.logger.Println("no changes\n")
-	}
-}
-
-func (c BoshDiff) printCPIConfigs(configs []api.CPIConfigsDiff) {
-	noneChanged := true
-
-	for _, config := range configs {
-		if config.Status == "same" {
-			continue
-		}
-
-		noneChanged = false
-
-		c.logger.Printf("### %s\n\n", config.IAASConfigurationName)
-		c.logger.Printf("%s\n\n", c.colorizeDiff(config.Diff))
-	}
-
-	if noneChanged {
-		c.logger.Println("no changes\n")
-	}
-}
-
-func (c BoshDiff) colorizeDiff(diff string) string {
-	lines := strings.Split(diff, "\n")
-	for index, line := range lines {
-		if strings.HasPrefix(line, "-") {
-			lines[index] = color.RedString(line)
-		}
-		if strings.HasPrefix(line, "+") {
-			lines[index] = color.GreenString(line)
-		}
-	}
-	return strings.Join(lines, "\n")
-}
-
 func (c BoshDiff) Usage() jhanda.Usage {
 	return jhanda.Usage{
 		Description:      "This command displays the bosh manifest diff for the director and products (Note: secret values are replaced with double-paren variable names)",
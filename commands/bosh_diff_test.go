@@ -2,21 +2,22 @@ package commands_test
 
 import (
 	"fmt"
-	"log"
 
 	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/onsi/gomega/gbytes"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/presenters"
 )
 
 var _ = Describe("BoshDiff", func() {
 	var (
 		logBuffer *gbytes.Buffer
-		logger    *log.Logger
+		presenter *presenters.MultiPresenter
 		service   *fakes.BoshDiffService
 		err       error
 	)
@@ -24,7 +25,7 @@ var _ = Describe("BoshDiff", func() {
 	BeforeEach(func() {
 		service = &fakes.BoshDiffService{}
 		logBuffer = gbytes.NewBuffer()
-		logger = log.New(logBuffer, "", 0)
+		presenter = presenters.NewPresenter(presenters.NewTablePresenter(tablewriter.NewWriter(logBuffer), logBuffer), presenters.NewJSONPresenter(logBuffer))
 	})
 
 	When("the --director flag is provided", func() {
@@ -58,7 +59,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("prints the diff with colors", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--director"})
 				Expect(err).NotTo(HaveOccurred())
 
@@ -88,7 +89,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("Errors if --check is enabled", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--director", "--check"})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("Differences exist between the staged and deployed versions of the requested products"))
@@ -131,7 +132,7 @@ var _ = Describe("BoshDiff", func() {
 				color.NoColor = true
 				defer func() { color.NoColor = false }()
 
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "example-product"})
 				Expect(err).NotTo(HaveOccurred())
 				expectedOutput := `## Product Manifest for example-product
@@ -166,7 +167,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("has colors on the diff", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "example-product"})
 				Expect(err).NotTo(HaveOccurred())
 
@@ -209,7 +210,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("says there are no runtime config differences and prints manifest diffs", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "example-product"})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(logBuffer).To(gbytes.Say("## Product Manifest"))
@@ -238,7 +239,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("says there are no product manifest differences and prints runtime config diffs", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "example-product"})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(logBuffer).To(gbytes.Say("## Product Manifest"))
@@ -268,7 +269,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("says there are no manifest differences and no runtime config diffs", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "example-product"})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(logBuffer).To(gbytes.Say("## Product Manifest"))
@@ -278,7 +279,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("does not error if --check is passed", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "example-product", "--check"})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(logBuffer).To(gbytes.Say("## Product Manifest"))
@@ -307,7 +308,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("says there is no manifest for the product and prints runtime config diffs", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "example-product"})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(logBuffer).To(gbytes.Say("## Product Manifest"))
@@ -330,7 +331,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("says the product will be installed for the first time", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "example-product"})
 				Expect(err).NotTo(HaveOccurred())
 				Expect(logBuffer).To(gbytes.Say("## Product Manifest"))
@@ -346,7 +347,7 @@ var _ = Describe("BoshDiff", func() {
 					api.ProductDiff{}, fmt.Errorf("too many cooks"))
 
 				// execute
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--product-name", "err-product"})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("too many cooks"))
@@ -399,7 +400,7 @@ var _ = Describe("BoshDiff", func() {
 		})
 
 		It("prints both product statuses", func() {
-			diff := commands.NewBoshDiff(service, logger)
+			diff := commands.NewBoshDiff(service, presenter)
 			err = diff.Execute([]string{"--product-name", "example-product", "--product-name", "another-product"})
 			Expect(err).NotTo(HaveOccurred())
 
@@ -413,6 +414,31 @@ var _ = Describe("BoshDiff", func() {
 			Expect(logBuffer).To(gbytes.Say("## Runtime Configs for another-product"))
 			Expect(logBuffer).To(gbytes.Say("no changes"))
 		})
+
+		It("prints the diffs of both products as json", func() {
+			diff := commands.NewBoshDiff(service, presenter)
+			err = diff.Execute([]string{"--product-name", "example-product", "--product-name", "another-product", "--format", "json"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logBuffer.Contents()).To(MatchJSON(`{
+				"products": [
+					{
+						"name": "example-product",
+						"manifest": {"status": "different", "diff": " properties:\n+  host: example.com\n-  host: localhost"},
+						"runtime_configs": [
+							{"name": "example-different-runtime-config", "status": "different", "diff": " addons:\n - name: a-runtime-config\n   jobs:\n   - name: a-job\n     properties:\n+      timeout: 100\n-      timeout: 30"},
+							{"name": "example-same-runtime-config", "status": "same", "diff": ""},
+							{"name": "example-also-different-runtime-config", "status": "different", "diff": " addons:\n - name: another-runtime-config\n   jobs:\n   - name: another-job\n     properties:\n+      timeout: 110\n-      timeout: 31"}
+						]
+					},
+					{
+						"name": "another-product",
+						"manifest": {"status": "same", "diff": ""},
+						"runtime_configs": [{"name": "example-different-runtime-config", "status": "same", "diff": ""}]
+					}
+				]
+			}`))
+		})
 	})
 
 	When("specific --product and --director are not provided", func() {
@@ -459,7 +485,7 @@ var _ = Describe("BoshDiff", func() {
 			})
 
 			It("lists all staged products (alphabetically by name) as well as the director", func() {
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{})
 				Expect(err).NotTo(HaveOccurred())
 
@@ -533,7 +559,7 @@ var _ = Describe("BoshDiff", func() {
 				color.NoColor = true
 				defer func() { color.NoColor = false }()
 
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{"--check"})
 
 				Expect(err).To(HaveOccurred())
@@ -589,7 +615,7 @@ var _ = Describe("BoshDiff", func() {
 				}}}, nil)
 				service.DirectorDiffReturns(api.DirectorDiff{}, fmt.Errorf("insufficient cooks"))
 
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{""})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("could not discover the director diff: insufficient cooks"))
@@ -601,7 +627,7 @@ var _ = Describe("BoshDiff", func() {
 				service.ListStagedProductsReturns(
 					api.StagedProductsOutput{}, fmt.Errorf("insufficient cooks"))
 
-				diff := commands.NewBoshDiff(service, logger)
+				diff := commands.NewBoshDiff(service, presenter)
 				err = diff.Execute([]string{""})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(Equal("could not discover staged products to diff: insufficient cooks"))
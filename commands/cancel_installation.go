@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+)
+
+type CancelInstallation struct {
+	service      cancelInstallationService
+	logger       logger
+	stdin        io.Reader
+	waitDuration time.Duration
+	Options      struct {
+		ID      int           `long:"id"              description:"id of the installation to cancel (defaults to the currently running installation)"`
+		Force   bool          `long:"force" short:"f" description:"used to avoid interactive prompt acknowledging cancellation"`
+		Timeout time.Duration `long:"timeout" default:"30m" description:"stop waiting for the installation to stop after this long (e.g. 10m, 1h)"`
+	}
+}
+
+//counterfeiter:generate -o ./fakes/cancel_installation_service.go --fake-name CancelInstallationService . cancelInstallationService
+type cancelInstallationService interface {
+	CancelInstallation(id int) error
+	GetInstallation(id int) (api.InstallationsServiceOutput, error)
+	RunningInstallation() (api.InstallationsServiceOutput, error)
+}
+
+func NewCancelInstallation(service cancelInstallationService, logger logger, stdin io.Reader, waitDuration time.Duration) CancelInstallation {
+	return CancelInstallation{
+		service:      service,
+		logger:       logger,
+		stdin:        stdin,
+		waitDuration: waitDuration,
+	}
+}
+
+func (ci CancelInstallation) Execute(args []string) error {
+	if _, err := jhanda.Parse(&ci.Options, args); err != nil {
+		return fmt.Errorf("could not parse cancel-installation flags: %s", err)
+	}
+
+	id := ci.Options.ID
+	if id == 0 {
+		installation, err := ci.service.RunningInstallation()
+		if err != nil {
+			return fmt.Errorf("could not check for a running installation: %s", err)
+		}
+
+		if installation == (api.InstallationsServiceOutput{}) {
+			ci.logger.Println("no running installation to cancel")
+			return nil
+		}
+
+		id = installation.ID
+	}
+
+	if !ci.Options.Force {
+		ci.logger.Printf("Do you really want to cancel installation %d? [yes/no]: ", id)
+
+		scanner := bufio.NewScanner(ci.stdin)
+		scanner.Scan()
+		if scanner.Text() != "yes" {
+			ci.logger.Println("Ok, the installation was not cancelled.")
+			return nil
+		}
+	}
+
+	ci.logger.Printf("cancelling installation %d", id)
+
+	err := ci.service.CancelInstallation(id)
+	if err != nil {
+		return fmt.Errorf("could not cancel installation %d: %s", id, err)
+	}
+
+	timeout := time.After(ci.Options.Timeout)
+	for {
+		current, err := ci.service.GetInstallation(id)
+		if err != nil {
+			return fmt.Errorf("could not get status of installation %d: %s", id, err)
+		}
+
+		if current.Status != api.StatusRunning {
+			ci.logger.Printf("installation %d has stopped with status: %s", id, current.Status)
+			return nil
+		}
+
+		select {
+		case <-time.After(ci.waitDuration):
+		case <-timeout:
+			return fmt.Errorf("installation %d was still running %s after it was cancelled", id, ci.Options.Timeout)
+		}
+	}
+}
+
+func (ci CancelInstallation) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command aborts a running installation on the Ops Manager and waits for it to stop.",
+		ShortDescription: "cancels a running installation on the Ops Manager targeted",
+		Flags:            ci.Options,
+	}
+}
@@ -0,0 +1,156 @@
+package commands_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CancelInstallation", func() {
+	var (
+		service *fakes.CancelInstallationService
+		stdout  *gbytes.Buffer
+		logger  *log.Logger
+		stdin   *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		service = &fakes.CancelInstallationService{}
+		stdout = gbytes.NewBuffer()
+		logger = log.New(stdout, "", 0)
+		stdin = bytes.NewBuffer([]byte{})
+
+		service.RunningInstallationReturns(api.InstallationsServiceOutput{ID: 42, Status: "running"}, nil)
+		service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{Status: "running"}, nil)
+		service.GetInstallationReturnsOnCall(1, api.InstallationsServiceOutput{Status: "failed"}, nil)
+	})
+
+	It("cancels the running installation and waits for it to stop", func() {
+		command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+		err := command.Execute([]string{"--force"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(service.CancelInstallationCallCount()).To(Equal(1))
+		Expect(service.CancelInstallationArgsForCall(0)).To(Equal(42))
+
+		Expect(service.GetInstallationCallCount()).To(Equal(2))
+		Expect(service.GetInstallationArgsForCall(1)).To(Equal(42))
+
+		Expect(stdout).To(gbytes.Say("cancelling installation 42"))
+		Expect(stdout).To(gbytes.Say("installation 42 has stopped with status: failed"))
+	})
+
+	It("cancels the installation given by id", func() {
+		command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+		err := command.Execute([]string{"--force", "--id", "7"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(service.RunningInstallationCallCount()).To(Equal(0))
+		Expect(service.CancelInstallationArgsForCall(0)).To(Equal(7))
+	})
+
+	It("does nothing when no installation is running", func() {
+		service.RunningInstallationReturns(api.InstallationsServiceOutput{}, nil)
+
+		command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+		err := command.Execute([]string{"--force"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(service.CancelInstallationCallCount()).To(Equal(0))
+		Expect(stdout).To(gbytes.Say("no running installation to cancel"))
+	})
+
+	When("not forced", func() {
+		It("cancels when the user confirms", func() {
+			stdin.WriteString("yes\n")
+
+			command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+			err := command.Execute([]string{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(stdout).To(gbytes.Say(`Do you really want to cancel installation 42\? \[yes/no\]: `))
+			Expect(service.CancelInstallationCallCount()).To(Equal(1))
+		})
+
+		It("does not cancel when the user declines", func() {
+			stdin.WriteString("no\n")
+
+			command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+			err := command.Execute([]string{})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(stdout).To(gbytes.Say("Ok, the installation was not cancelled."))
+			Expect(service.CancelInstallationCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("failure cases", func() {
+		When("checking for a running installation fails", func() {
+			It("returns an error", func() {
+				service.RunningInstallationReturns(api.InstallationsServiceOutput{}, errors.New("some error"))
+
+				command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+				err := command.Execute([]string{"--force"})
+				Expect(err).To(MatchError("could not check for a running installation: some error"))
+			})
+		})
+
+		When("cancelling fails", func() {
+			It("returns an error", func() {
+				service.CancelInstallationReturns(errors.New("some error"))
+
+				command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+				err := command.Execute([]string{"--force"})
+				Expect(err).To(MatchError("could not cancel installation 42: some error"))
+			})
+		})
+
+		When("getting the status fails", func() {
+			It("returns an error", func() {
+				service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{}, errors.New("some error"))
+
+				command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+				err := command.Execute([]string{"--force"})
+				Expect(err).To(MatchError("could not get status of installation 42: some error"))
+			})
+		})
+
+		When("the installation is still running once the timeout expires", func() {
+			It("returns an error", func() {
+				service.GetInstallationReturnsOnCall(1, api.InstallationsServiceOutput{Status: "running"}, nil)
+				service.GetInstallationReturns(api.InstallationsServiceOutput{Status: "running"}, nil)
+
+				command := commands.NewCancelInstallation(service, logger, stdin, 10*time.Millisecond)
+
+				err := command.Execute([]string{"--force", "--timeout", "50ms"})
+				Expect(err).To(MatchError("installation 42 was still running 50ms after it was cancelled"))
+			})
+		})
+
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				command := commands.NewCancelInstallation(service, logger, stdin, 0)
+
+				err := command.Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse cancel-installation flags: flag provided but not defined: -badflag"))
+			})
+		})
+	})
+})
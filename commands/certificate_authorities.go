@@ -12,7 +12,7 @@ type CertificateAuthorities struct {
 	service   certificateAuthoritiesService
 	presenter presenters.FormattedPresenter
 	Options   struct {
-		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -39,7 +39,10 @@ func (c CertificateAuthorities) Execute(args []string) error {
 		return err
 	}
 
-	c.presenter.SetFormat(c.Options.Format)
+	err = c.presenter.SetFormat(c.Options.Format)
+	if err != nil {
+		return err
+	}
 	c.presenter.PresentCertificateAuthorities(casOutput.CAs)
 
 	return nil
@@ -17,7 +17,7 @@ type CertificateAuthority struct {
 	Options   struct {
 		ID      string `long:"id" description:"ID of certificate to display. Required if there is more than one certificate authority"`
 		CertPEM bool   `long:"cert-pem" description:"Display the cert pem"`
-		Format  string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format  string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -58,7 +58,10 @@ func (c CertificateAuthority) Execute(args []string) error {
 		if c.Options.CertPEM {
 			c.logger.Println(displayCA.CertPEM)
 		} else {
-			c.presenter.SetFormat(c.Options.Format)
+			err = c.presenter.SetFormat(c.Options.Format)
+			if err != nil {
+				return err
+			}
 			c.presenter.PresentCertificateAuthority(displayCA)
 		}
 		return nil
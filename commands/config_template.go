@@ -8,12 +8,14 @@ import (
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/configtemplate/generator"
 	"github.com/pivotal-cf/om/configtemplate/metadata"
+	"github.com/pivotal-cf/om/interpolate"
 )
 
 type ConfigTemplate struct {
-	environFunc   envProvider
-	buildProvider buildProvider
-	Options       struct {
+	environFunc      envProvider
+	varsSourceConfig interpolate.VarsSourceConfig
+	buildProvider    buildProvider
+	Options          struct {
 		interpolateConfigFileOptions
 
 		PivnetApiToken    string `long:"pivnet-api-token"`
@@ -49,20 +51,21 @@ var DefaultProvider = func() func(c *ConfigTemplate) MetadataProvider {
 type buildProvider func(*ConfigTemplate) MetadataProvider
 type envProvider func() []string
 
-func NewConfigTemplate(bp buildProvider) *ConfigTemplate {
-	return NewConfigTemplateWithEnvironment(bp, os.Environ)
+func NewConfigTemplate(bp buildProvider, varsSourceConfig interpolate.VarsSourceConfig) *ConfigTemplate {
+	return NewConfigTemplateWithEnvironment(bp, os.Environ, varsSourceConfig)
 }
 
-func NewConfigTemplateWithEnvironment(bp buildProvider, environFunc envProvider) *ConfigTemplate {
+func NewConfigTemplateWithEnvironment(bp buildProvider, environFunc envProvider, varsSourceConfig interpolate.VarsSourceConfig) *ConfigTemplate {
 	return &ConfigTemplate{
-		environFunc:   environFunc,
-		buildProvider: bp,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		buildProvider:    bp,
 	}
 }
 
 //Execute - generates config template and ops files
 func (c *ConfigTemplate) Execute(args []string) error {
-	err := loadConfigFile(args, &c.Options, c.environFunc)
+	err := loadConfigFile(args, &c.Options, c.environFunc, c.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse config-template flags: %s", err.Error())
 	}
@@ -12,6 +12,7 @@ import (
 	. "github.com/onsi/gomega"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 )
 
 var _ = Describe("ConfigTemplate", func() {
@@ -37,7 +38,7 @@ var _ = Describe("ConfigTemplate", func() {
 				f := &fakes.MetadataProvider{}
 				f.MetadataBytesReturns([]byte(`{name: example-product, product_version: "1.1.1"}`), nil)
 				return f
-			})
+			}, interpolate.VarsSourceConfig{})
 		})
 
 		Describe("upserting an entry in the output directory with template files", func() {
@@ -193,7 +194,7 @@ property_blueprints:
     type: string
 `), nil)
 					return f
-				})
+				}, interpolate.VarsSourceConfig{})
 			})
 
 			It("outputs 10 ops-files by default", func() {
@@ -250,7 +251,7 @@ property_blueprints:
 					f := &fakes.MetadataProvider{}
 					f.MetadataBytesReturns([]byte(`{name: example-product, product_version: "1.1.1"}`), nil)
 					return f
-				})
+				}, interpolate.VarsSourceConfig{})
 			})
 			It("returns an error", func() {
 				err := command.Execute([]string{"--invalid"})
@@ -266,7 +267,7 @@ property_blueprints:
 					f := &fakes.MetadataProvider{}
 					f.MetadataBytesReturns([]byte(`{name: example-product, product_version: "1.1.1"}`), nil)
 					return f
-				})
+				}, interpolate.VarsSourceConfig{})
 			})
 			It("returns an error", func() {
 				err := command.Execute([]string{
@@ -284,7 +285,7 @@ property_blueprints:
 					f := &fakes.MetadataProvider{}
 					f.MetadataBytesReturns([]byte(`{name: example-product, product_version: "1.1.1"}`), nil)
 					return f
-				})
+				}, interpolate.VarsSourceConfig{})
 			})
 			DescribeTable("returns an error", func(required, message string) {
 				args := []string{
@@ -316,7 +317,7 @@ property_blueprints:
 					f := &fakes.MetadataProvider{}
 					f.MetadataBytesReturns([]byte(`{name: example-product, product_version: "1.1.1"}`), nil)
 					return f
-				})
+				}, interpolate.VarsSourceConfig{})
 			})
 			var (
 				configFile *os.File
@@ -406,7 +407,7 @@ output-directory: %s
 							f := &fakes.MetadataProvider{}
 							f.MetadataBytesReturns([]byte(`{name: example-product, product_version: "1.1.1"}`), nil)
 							return f
-						}, environFunc)
+						}, environFunc, interpolate.VarsSourceConfig{})
 					})
 
 					It("can interpolate variables into the configuration", func() {
@@ -427,7 +428,7 @@ output-directory: %s
 						f := &fakes.MetadataProvider{}
 						f.MetadataBytesReturns(nil, errors.New("cannot get metadata"))
 						return f
-					})
+					}, interpolate.VarsSourceConfig{})
 				})
 
 				It("returns an error", func() {
@@ -450,7 +451,7 @@ output-directory: %s
 						f := &fakes.MetadataProvider{}
 						f.MetadataBytesReturns([]byte(`{name: example-product, product_version: ""}`), nil)
 						return f
-					})
+					}, interpolate.VarsSourceConfig{})
 				})
 				It("errors", func() {
 					tempDir := createOutputDirectory()
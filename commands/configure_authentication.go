@@ -3,6 +3,7 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"github.com/pivotal-cf/om/interpolate"
 
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
@@ -16,10 +17,11 @@ type configureAuthenticationService interface {
 }
 
 type ConfigureAuthentication struct {
-	service     configureAuthenticationService
-	logger      logger
-	environFunc func() []string
-	Options     struct {
+	service          configureAuthenticationService
+	logger           logger
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	Options          struct {
 		interpolateConfigFileOptions
 
 		Username               string `long:"username"              short:"u"  env:"OM_USERNAME"              description:"admin username" required:"true"`
@@ -32,18 +34,19 @@ type ConfigureAuthentication struct {
 	}
 }
 
-func NewConfigureAuthentication(environFunc func() []string, service configureAuthenticationService, logger logger) ConfigureAuthentication {
+func NewConfigureAuthentication(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, service configureAuthenticationService, logger logger) ConfigureAuthentication {
 	return ConfigureAuthentication{
-		environFunc: environFunc,
-		service:     service,
-		logger:      logger,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		logger:           logger,
 	}
 }
 
 func (ca ConfigureAuthentication) Execute(args []string) error {
 	var opsManUaaClientMsg string
 
-	err := loadConfigFile(args, &ca.Options, ca.environFunc)
+	err := loadConfigFile(args, &ca.Options, ca.environFunc, ca.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse configure-authentication flags: %s", err)
 	}
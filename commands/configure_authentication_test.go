@@ -9,6 +9,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -43,7 +44,7 @@ var _ = Describe("ConfigureAuthentication.Execute", func() {
 			return eaOutputs[service.EnsureAvailabilityCallCount()-1], nil
 		}
 
-		command := commands.NewConfigureAuthentication(nil, service, logger)
+		command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 		err := command.Execute([]string{
 			"--username", "some-username",
 			"--password", "some-password",
@@ -80,7 +81,7 @@ It will have the username 'precreated-client' and the client secret you provided
 				Status: api.EnsureAvailabilityStatusComplete,
 			}, nil)
 
-			command := commands.NewConfigureAuthentication(nil, service, logger)
+			command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 			err := command.Execute([]string{
 				"--username", "some-username",
 				"--password", "some-password",
@@ -123,7 +124,7 @@ decryption-passphrase: some-passphrase
 		})
 
 		It("reads configuration from config file", func() {
-			command := commands.NewConfigureAuthentication(nil, service, logger)
+			command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 			err := command.Execute([]string{
 				"--config", configFile,
 			})
@@ -147,7 +148,7 @@ decryption-passphrase: some-passphrase
 		})
 
 		It("respects vars from flags over those in the config file", func() {
-			command := commands.NewConfigureAuthentication(nil, service, logger)
+			command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 			err := command.Execute([]string{
 				"--config", configFile,
 				"--password", "some-password-1",
@@ -190,7 +191,7 @@ decryption-passphrase: ((vars-passphrase))
 
 		Context("variables are not provided", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 				})
@@ -216,7 +217,7 @@ vars-passphrase: a-vars-file-passphrase
 			})
 
 			It("uses values from the vars file", func() {
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 					"--vars-file", varsFile,
@@ -237,7 +238,7 @@ vars-passphrase: a-vars-file-passphrase
 
 		Context("passed in a var (--var)", func() {
 			It("uses values from the command line", func() {
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 					"--var", "vars-password=a-command-line-password",
@@ -263,6 +264,7 @@ vars-passphrase: a-vars-file-passphrase
 					func() []string {
 						return []string{"OM_VAR_vars-password=an-env-var-password", "OM_VAR_vars-passphrase=an-env-var-passphrase"}
 					},
+					interpolate.VarsSourceConfig{},
 					service,
 					logger,
 				)
@@ -293,6 +295,7 @@ vars-passphrase: a-vars-file-passphrase
 					func() []string {
 						return []string{"OM_VAR_vars-password=an-env-var-password", "OM_VAR_vars-passphrase=an-env-var-passphrase"}
 					},
+					interpolate.VarsSourceConfig{},
 					service,
 					logger,
 				)
@@ -332,7 +335,7 @@ vars-passphrase: a-vars-file-passphrase
 				Version: "2.4-build.1",
 			}, nil)
 
-			command := commands.NewConfigureAuthentication(nil, service, logger)
+			command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 			err := command.Execute([]string{
 				"--username", "some-username",
 				"--password", "some-password",
@@ -349,7 +352,7 @@ This is only supported in OpsManager 2.5 and up.
 	Context("failure cases", func() {
 		When("an unknown flag is provided", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{"--banana"})
 				Expect(err).To(MatchError("could not parse configure-authentication flags: flag provided but not defined: -banana"))
 			})
@@ -357,7 +360,7 @@ This is only supported in OpsManager 2.5 and up.
 
 		When("config file cannot be opened", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{"--config", "something"})
 				Expect(err).To(MatchError("could not parse configure-authentication flags: could not load the config file: could not read file (something): open something: no such file or directory"))
 			})
@@ -367,7 +370,7 @@ This is only supported in OpsManager 2.5 and up.
 			It("returns an error", func() {
 				service.EnsureAvailabilityReturns(api.EnsureAvailabilityOutput{}, errors.New("failed to fetch status"))
 
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--username", "some-username",
 					"--password", "some-password",
@@ -383,7 +386,7 @@ This is only supported in OpsManager 2.5 and up.
 					Status: api.EnsureAvailabilityStatusUnknown,
 				}, nil)
 
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--username", "some-username",
 					"--password", "some-password",
@@ -401,7 +404,7 @@ This is only supported in OpsManager 2.5 and up.
 
 				service.SetupReturns(api.SetupOutput{}, errors.New("could not setup"))
 
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--username", "some-username",
 					"--password", "some-password",
@@ -426,7 +429,7 @@ This is only supported in OpsManager 2.5 and up.
 					return eaOutputs[service.EnsureAvailabilityCallCount()-1], eaErrors[service.EnsureAvailabilityCallCount()-1]
 				}
 
-				command := commands.NewConfigureAuthentication(nil, service, logger)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--username", "some-username",
 					"--password", "some-password",
@@ -438,7 +441,7 @@ This is only supported in OpsManager 2.5 and up.
 
 		When("the --username flag is missing", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureAuthentication(nil, nil, nil)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute([]string{
 					"--password", "some-password",
 					"--decryption-passphrase", "some-passphrase",
@@ -449,7 +452,7 @@ This is only supported in OpsManager 2.5 and up.
 
 		When("the --password flag is missing", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureAuthentication(nil, nil, nil)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute([]string{
 					"--username", "some-username",
 					"--decryption-passphrase", "some-passphrase",
@@ -460,7 +463,7 @@ This is only supported in OpsManager 2.5 and up.
 
 		When("the --decryption-passphrase flag is missing", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureAuthentication(nil, nil, nil)
+				command := commands.NewConfigureAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute([]string{
 					"--username", "some-username",
 					"--password", "some-password",
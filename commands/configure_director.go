@@ -13,14 +13,16 @@ import (
 )
 
 type ConfigureDirector struct {
-	environFunc func() []string
-	service     configureDirectorService
-	logger      logger
-	Options     struct {
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	service          configureDirectorService
+	logger           logger
+	Options          struct {
 		IgnoreVerifierWarnings bool     `long:"ignore-verifier-warnings"    description:"option to ignore verifier warnings. NOT RECOMMENDED UNLESS DISABLED IN OPS MANAGER"`
 		ConfigFile             string   `long:"config"    short:"c"         description:"path to yml file containing all config fields (see docs/configure-director/README.md for format)" required:"true"`
 		VarsFile               []string `long:"vars-file" short:"l"         description:"load variables from a YAML file"`
 		VarsEnv                []string `long:"vars-env"  env:"OM_VARS_ENV" description:"load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
+		VarsSource             []string `long:"vars-source"                 description:"load the variables missing from the other options from a secret store (e.g.: 'vault://secret/foundation' for the secrets under that path of Vault, or 'credhub://concourse/main' for the credentials under that prefix of CredHub)"`
 		Vars                   []string `long:"var"       short:"v"         description:"load variable from the command line. Format: VAR=VAL"`
 		OpsFile                []string `long:"ops-file"                    description:"YAML operations file"`
 	}
@@ -63,11 +65,12 @@ type configureDirectorService interface {
 	UpdateStagedDirectorProperties(api.DirectorProperties) error
 }
 
-func NewConfigureDirector(environFunc func() []string, service configureDirectorService, logger logger) ConfigureDirector {
+func NewConfigureDirector(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, service configureDirectorService, logger logger) ConfigureDirector {
 	return ConfigureDirector{
-		environFunc: environFunc,
-		service:     service,
-		logger:      logger,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		logger:           logger,
 	}
 }
 
@@ -149,13 +152,15 @@ func (c ConfigureDirector) Execute(args []string) error {
 
 func (c ConfigureDirector) interpolateConfig() (*directorConfig, error) {
 	configContents, err := interpolate.Execute(interpolate.Options{
-		TemplateFile:  c.Options.ConfigFile,
-		VarsFiles:     c.Options.VarsFile,
-		EnvironFunc:   c.environFunc,
-		Vars:          c.Options.Vars,
-		VarsEnvs:      c.Options.VarsEnv,
-		OpsFiles:      c.Options.OpsFile,
-		ExpectAllKeys: true,
+		TemplateFile:     c.Options.ConfigFile,
+		VarsFiles:        c.Options.VarsFile,
+		EnvironFunc:      c.environFunc,
+		Vars:             c.Options.Vars,
+		VarsEnvs:         c.Options.VarsEnv,
+		VarsSources:      c.Options.VarsSource,
+		VarsSourceConfig: c.varsSourceConfig,
+		OpsFiles:         c.Options.OpsFile,
+		ExpectAllKeys:    true,
 	})
 	if err != nil {
 		return nil, err
@@ -15,6 +15,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 )
 
 var _ = Describe("ConfigureDirector", func() {
@@ -55,6 +56,7 @@ var _ = Describe("ConfigureDirector", func() {
 
 		command = commands.NewConfigureDirector(
 			func() []string { return []string{} },
+			interpolate.VarsSourceConfig{},
 			service,
 			logger)
 	})
@@ -402,6 +404,7 @@ vmtypes-configuration:
 							logger := log.New(stdout, "", 0)
 							command = commands.NewConfigureDirector(
 								func() []string { return []string{"OM_VAR_name=network"} },
+								interpolate.VarsSourceConfig{},
 								service,
 								logger)
 
@@ -425,6 +428,7 @@ vmtypes-configuration:
 
 							command = commands.NewConfigureDirector(
 								func() []string { return []string{"OM_VAR_name=network"} },
+								interpolate.VarsSourceConfig{},
 								service,
 								logger)
 
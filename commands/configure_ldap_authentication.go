@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/interpolate"
 )
 
 type ConfigureLDAPAuthentication struct {
-	service     configureAuthenticationService
-	logger      logger
-	environFunc func() []string
-	Options     struct {
+	service          configureAuthenticationService
+	logger           logger
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	Options          struct {
 		interpolateConfigFileOptions
 
 		DecryptionPassphrase      string `long:"decryption-passphrase" short:"dp" required:"true" description:"passphrase used to encrypt the installation"`
@@ -34,11 +36,12 @@ type ConfigureLDAPAuthentication struct {
 	}
 }
 
-func NewConfigureLDAPAuthentication(environFunc func() []string, service configureAuthenticationService, logger logger) ConfigureLDAPAuthentication {
+func NewConfigureLDAPAuthentication(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, service configureAuthenticationService, logger logger) ConfigureLDAPAuthentication {
 	return ConfigureLDAPAuthentication{
-		environFunc: environFunc,
-		service:     service,
-		logger:      logger,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		logger:           logger,
 	}
 }
 
@@ -48,7 +51,7 @@ func (ca ConfigureLDAPAuthentication) Execute(args []string) error {
 		opsManUaaClientMsg string
 	)
 
-	err := loadConfigFile(args, &ca.Options, ca.environFunc)
+	err := loadConfigFile(args, &ca.Options, ca.environFunc, ca.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse configure-ldap-authentication flags: %s", err)
 	}
@@ -8,6 +8,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 
 	"io/ioutil"
 	"os"
@@ -45,7 +46,7 @@ var _ = Describe("ConfigureLDAPAuthentication.Execute", func() {
 			return eaOutputs[service.EnsureAvailabilityCallCount()-1], nil
 		}
 
-		command = commands.NewConfigureLDAPAuthentication(nil, service, logger)
+		command = commands.NewConfigureLDAPAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 
 		service.InfoReturns(api.Info{
 			Version: "2.5-build.1",
@@ -303,7 +304,7 @@ ldap-referrals: follow
 
 		Context("variables are not provided", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureLDAPAuthentication(nil, service, logger)
+				command := commands.NewConfigureLDAPAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 				})
@@ -329,7 +330,7 @@ password: a-vars-file-password
 			})
 
 			It("uses values from the vars file", func() {
-				command := commands.NewConfigureLDAPAuthentication(nil, service, logger)
+				command := commands.NewConfigureLDAPAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 					"--vars-file", varsFile,
@@ -360,7 +361,7 @@ password: a-vars-file-password
 
 		Context("passed in a var (--var)", func() {
 			It("uses values from the command line", func() {
-				command := commands.NewConfigureLDAPAuthentication(nil, service, logger)
+				command := commands.NewConfigureLDAPAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 					"--var", "password=a-command-line-password",
@@ -396,6 +397,7 @@ password: a-vars-file-password
 					func() []string {
 						return []string{"OM_VAR_password=an-env-var-password", "OM_VAR_passphrase=an-env-var-passphrase"}
 					},
+					interpolate.VarsSourceConfig{},
 					service,
 					logger,
 				)
@@ -436,6 +438,7 @@ password: a-vars-file-password
 					func() []string {
 						return []string{"OM_VAR_password=an-env-var-password", "OM_VAR_passphrase=an-env-var-passphrase"}
 					},
+					interpolate.VarsSourceConfig{},
 					service,
 					logger,
 				)
@@ -530,7 +533,7 @@ password: a-vars-file-password
 
 		When("missing required fields", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureLDAPAuthentication(nil, nil, nil)
+				command := commands.NewConfigureLDAPAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute(nil)
 				Expect(err).To(MatchError("could not parse configure-ldap-authentication flags: missing required flag \"--decryption-passphrase\""))
 			})
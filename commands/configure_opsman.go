@@ -12,10 +12,11 @@ import (
 )
 
 type ConfigureOpsman struct {
-	service     configureOpsmanService
-	logger      logger
-	environFunc func() []string
-	Options     struct {
+	service          configureOpsmanService
+	logger           logger
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	Options          struct {
 		ConfigFile string   `long:"config"    short:"c"         description:"path to yml file containing all config fields (see docs/configure-director/README.md for format)" required:"true"`
 		VarsFile   []string `long:"vars-file" short:"l"         description:"load variables from a YAML file"`
 		VarsEnv    []string `long:"vars-env"  env:"OM_VARS_ENV" description:"load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
@@ -52,11 +53,12 @@ type configureOpsmanService interface {
 	UpdateSyslogSettings(syslogSettings api.SyslogSettings) error
 }
 
-func NewConfigureOpsman(environFunc func() []string, service configureOpsmanService, logger logger) ConfigureOpsman {
+func NewConfigureOpsman(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, service configureOpsmanService, logger logger) ConfigureOpsman {
 	return ConfigureOpsman{
-		environFunc: environFunc,
-		service:     service,
-		logger:      logger,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		logger:           logger,
 	}
 }
 
@@ -130,13 +132,14 @@ func (c ConfigureOpsman) updateSSLCertificate(config *opsmanConfig) error {
 
 func (c ConfigureOpsman) interpolateConfig() (*opsmanConfig, error) {
 	configContents, err := interpolate.Execute(interpolate.Options{
-		TemplateFile:  c.Options.ConfigFile,
-		VarsFiles:     c.Options.VarsFile,
-		EnvironFunc:   c.environFunc,
-		Vars:          c.Options.Vars,
-		VarsEnvs:      c.Options.VarsEnv,
-		OpsFiles:      c.Options.OpsFile,
-		ExpectAllKeys: true,
+		TemplateFile:     c.Options.ConfigFile,
+		VarsFiles:        c.Options.VarsFile,
+		EnvironFunc:      c.environFunc,
+		Vars:             c.Options.Vars,
+		VarsEnvs:         c.Options.VarsEnv,
+		OpsFiles:         c.Options.OpsFile,
+		VarsSourceConfig: c.varsSourceConfig,
+		ExpectAllKeys:    true,
 	})
 	if err != nil {
 		return nil, err
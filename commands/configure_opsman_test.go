@@ -7,6 +7,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 	"io/ioutil"
 	"log"
 )
@@ -20,7 +21,7 @@ var _ = Describe("ConfigureOpsman", func() {
 	BeforeEach(func() {
 		fakeService = &fakes.ConfigureOpsmanService{}
 		logger := log.New(ioutil.Discard, "", 0)
-		command = commands.NewConfigureOpsman(func() []string { return []string{} }, fakeService, logger)
+		command = commands.NewConfigureOpsman(func() []string { return []string{} }, interpolate.VarsSourceConfig{}, fakeService, logger)
 	})
 
 	Describe("Execute", func() {
@@ -16,16 +16,18 @@ import (
 )
 
 type ConfigureProduct struct {
-	environFunc func() []string
-	service     configureProductService
-	logger      logger
-	target      string
-	Options     struct {
-		ConfigFile string   `long:"config"    short:"c"         description:"path to yml file containing all config fields (see docs/configure-product/README.md for format)" required:"true"`
-		VarsFile   []string `long:"vars-file" short:"l"         description:"load variables from a YAML file"`
-		Vars       []string `long:"var"       short:"v"         description:"load variable from the command line. Format: VAR=VAL"`
-		VarsEnv    []string `long:"vars-env"  env:"OM_VARS_ENV" description:"load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
-		OpsFile    []string `long:"ops-file"  short:"o"         description:"YAML operations file"`
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	service          configureProductService
+	logger           logger
+	target           string
+	Options          struct {
+		ConfigFile string   `long:"config"      short:"c"         description:"path to yml file containing all config fields (see docs/configure-product/README.md for format)" required:"true"`
+		VarsFile   []string `long:"vars-file"   short:"l"         description:"load variables from a YAML file"`
+		Vars       []string `long:"var"         short:"v"         description:"load variable from the command line. Format: VAR=VAL"`
+		VarsEnv    []string `long:"vars-env"    env:"OM_VARS_ENV" description:"load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
+		VarsSource []string `long:"vars-source"                   description:"load the variables missing from the other options from a secret store (e.g.: 'vault://secret/foundation' for the secrets under that path of Vault, or 'credhub://concourse/main' for the credentials under that prefix of CredHub)"`
+		OpsFile    []string `long:"ops-file"    short:"o"         description:"YAML operations file"`
 	}
 }
 
@@ -49,12 +51,13 @@ type configureProduct struct {
 	Field                       map[string]interface{} `yaml:",inline"`
 }
 
-func NewConfigureProduct(environFunc func() []string, service configureProductService, target string, logger logger) ConfigureProduct {
+func NewConfigureProduct(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, service configureProductService, target string, logger logger) ConfigureProduct {
 	return ConfigureProduct{
-		environFunc: environFunc,
-		service:     service,
-		target:      target,
-		logger:      logger,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		target:           target,
+		logger:           logger,
 	}
 }
 
@@ -325,13 +328,15 @@ func (cp *ConfigureProduct) configureErrands(cfg configureProduct, productGUID s
 
 func (cp *ConfigureProduct) interpolateConfig(cfg configureProduct) (configureProduct, error) {
 	configContents, err := interpolate.Execute(interpolate.Options{
-		TemplateFile:  cp.Options.ConfigFile,
-		VarsFiles:     cp.Options.VarsFile,
-		Vars:          cp.Options.Vars,
-		EnvironFunc:   cp.environFunc,
-		VarsEnvs:      cp.Options.VarsEnv,
-		OpsFiles:      cp.Options.OpsFile,
-		ExpectAllKeys: true,
+		TemplateFile:     cp.Options.ConfigFile,
+		VarsFiles:        cp.Options.VarsFile,
+		Vars:             cp.Options.Vars,
+		EnvironFunc:      cp.environFunc,
+		VarsEnvs:         cp.Options.VarsEnv,
+		VarsSources:      cp.Options.VarsSource,
+		VarsSourceConfig: cp.varsSourceConfig,
+		OpsFiles:         cp.Options.OpsFile,
+		ExpectAllKeys:    true,
 	})
 	if err != nil {
 		return configureProduct{}, err
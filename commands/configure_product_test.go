@@ -10,6 +10,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -45,7 +46,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("configures the given product's properties", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
@@ -78,7 +79,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("check configuration is complete after configuring", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "example.com", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "example.com", logger)
 
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
@@ -117,7 +118,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("returns a helpful error message if configuration completeness cannot be validated", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "example.com", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "example.com", logger)
 
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
@@ -158,7 +159,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("configures a product's network", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
@@ -197,7 +198,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("configures a product's syslog", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
@@ -236,7 +237,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("configures the resource that is provided", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
 						{GUID: "some-product-guid", Type: "cf"},
@@ -289,7 +290,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("sets the max in flight for all jobs", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
 						{GUID: "some-product-guid", Type: "cf"},
@@ -348,7 +349,7 @@ var _ = Describe("ConfigureProduct", func() {
 			When("the config file contains variables", func() {
 				Context("passed in a vars-file", func() {
 					It("can interpolate variables into the configuration", func() {
-						client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+						client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 						configFile, err = ioutil.TempFile("", "")
 						Expect(err).ToNot(HaveOccurred())
@@ -372,7 +373,7 @@ var _ = Describe("ConfigureProduct", func() {
 
 				Context("given vars", func() {
 					It("can interpolate variables into the configuration", func() {
-						client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+						client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 						configFile, err = ioutil.TempFile("", "")
 						Expect(err).ToNot(HaveOccurred())
@@ -390,7 +391,7 @@ var _ = Describe("ConfigureProduct", func() {
 
 				Context("passed as environment variables", func() {
 					It("can interpolate variables into the configuration", func() {
-						client := commands.NewConfigureProduct(func() []string { return []string{"OM_VAR_password=something-secure"} }, service, "", logger)
+						client := commands.NewConfigureProduct(func() []string { return []string{"OM_VAR_password=something-secure"} }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 						configFile, err = ioutil.TempFile("", "")
 						Expect(err).ToNot(HaveOccurred())
@@ -409,7 +410,7 @@ var _ = Describe("ConfigureProduct", func() {
 						os.Setenv("OM_VARS_ENV", "OM_VAR")
 						defer os.Unsetenv("OM_VARS_ENV")
 
-						client := commands.NewConfigureProduct(func() []string { return []string{"OM_VAR_password=something-secure"} }, service, "", logger)
+						client := commands.NewConfigureProduct(func() []string { return []string{"OM_VAR_password=something-secure"} }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 						configFile, err = ioutil.TempFile("", "")
 						Expect(err).ToNot(HaveOccurred())
@@ -425,7 +426,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error if missing variables", func() {
-					client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 					configFile, err = ioutil.TempFile("", "")
 					Expect(err).ToNot(HaveOccurred())
@@ -442,7 +443,7 @@ var _ = Describe("ConfigureProduct", func() {
 
 			When("an ops-file is provided", func() {
 				It("can interpolate ops-files into the configuration", func() {
-					client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 					configFile, err = ioutil.TempFile("", "")
 					Expect(err).ToNot(HaveOccurred())
@@ -469,7 +470,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error if the ops file is invalid", func() {
-					client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 					configFile, err = ioutil.TempFile("", "")
 					Expect(err).ToNot(HaveOccurred())
@@ -497,7 +498,7 @@ var _ = Describe("ConfigureProduct", func() {
 				config = fmt.Sprintf(`{"product-name": "cf", "resource-config": %s}`, resourceConfig)
 			})
 			It("returns an error", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
 						{GUID: "some-product-guid", Type: "cf"},
@@ -531,7 +532,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("logs and then does nothing if they are empty", func() {
-				command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 				err := command.Execute([]string{
 					"--config", configFile.Name(),
@@ -575,7 +576,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("returns an error", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 				err := client.Execute([]string{"--config", configFile.Name()})
 				Expect(err).To(MatchError("OpsManager does not allow configuration or staging changes while apply changes are running to prevent data loss for configuration and/or staging changes"))
 				Expect(service.ListInstallationsCallCount()).To(Equal(1))
@@ -592,7 +593,7 @@ var _ = Describe("ConfigureProduct", func() {
 			})
 
 			It("does not return an error", func() {
-				client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+				client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 				service.ListStagedProductsReturns(api.StagedProductsOutput{
 					Products: []api.StagedProduct{
@@ -615,7 +616,7 @@ var _ = Describe("ConfigureProduct", func() {
 
 			When("the product does not exist", func() {
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 					service.ListStagedProductsReturns(api.StagedProductsOutput{
 						Products: []api.StagedProduct{
@@ -636,7 +637,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					service.ListStagedProductsReturns(api.StagedProductsOutput{
 						Products: []api.StagedProduct{
 							{GUID: "some-product-guid", Type: "cf"},
@@ -654,7 +655,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					service.ListStagedProductsReturns(api.StagedProductsOutput{
 						Products: []api.StagedProduct{
 							{GUID: "some-product-guid", Type: "cf"},
@@ -673,7 +674,7 @@ var _ = Describe("ConfigureProduct", func() {
 
 			When("an unknown flag is provided", func() {
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					err := command.Execute([]string{"--badflag"})
 					Expect(err).To(MatchError("could not parse configure-product flags: flag provided but not defined: -badflag"))
 				})
@@ -685,7 +686,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					err := command.Execute([]string{"--config", configFile.Name()})
 					Expect(err).To(MatchError("could not parse configure-product config: \"product-name\" is required"))
 				})
@@ -694,7 +695,7 @@ var _ = Describe("ConfigureProduct", func() {
 			When("the --config flag is passed", func() {
 				When("the provided config path does not exist", func() {
 					It("returns an error", func() {
-						command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+						command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 						service.ListStagedProductsReturns(api.StagedProductsOutput{
 							Products: []api.StagedProduct{
 								{GUID: "some-product-guid", Type: "cf"},
@@ -717,7 +718,7 @@ var _ = Describe("ConfigureProduct", func() {
 
 					It("returns an error", func() {
 						invalidConfig := "this is not a valid config"
-						client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+						client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 						service.ListStagedProductsReturns(api.StagedProductsOutput{
 							Products: []api.StagedProduct{
 								{GUID: "some-product-guid", Type: "cf"},
@@ -744,7 +745,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					service.UpdateStagedProductPropertiesReturns(errors.New("some product error"))
 
 					service.ListStagedProductsReturns(api.StagedProductsOutput{
@@ -764,7 +765,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					service.UpdateStagedProductNetworksAndAZsReturns(errors.New("some product error"))
 
 					service.ListStagedProductsReturns(api.StagedProductsOutput{
@@ -784,7 +785,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					service.UpdateSyslogConfigurationReturns(errors.New("some product error"))
 
 					service.ListStagedProductsReturns(api.StagedProductsOutput{
@@ -804,7 +805,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 
 				It("returns an error", func() {
-					command := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					command := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					service.UpdateSyslogConfigurationReturns(errors.New("some product error"))
 
 					service.ListStagedProductsReturns(api.StagedProductsOutput{
@@ -836,7 +837,7 @@ var _ = Describe("ConfigureProduct", func() {
 				})
 				It("errors when calling api", func() {
 					service.UpdateStagedProductErrandsReturns(errors.New("error configuring errand"))
-					client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 
 					configFile, err = ioutil.TempFile("", "")
 					Expect(err).ToNot(HaveOccurred())
@@ -860,7 +861,7 @@ var _ = Describe("ConfigureProduct", func() {
 					Expect(err).ToNot(HaveOccurred())
 					Expect(configFile.Close()).ToNot(HaveOccurred())
 
-					client := commands.NewConfigureProduct(func() []string { return nil }, service, "", logger)
+					client := commands.NewConfigureProduct(func() []string { return nil }, interpolate.VarsSourceConfig{}, service, "", logger)
 					err = client.Execute([]string{
 						"--config", configFile.Name(),
 					})
@@ -3,16 +3,18 @@ package commands
 import (
 	"errors"
 	"fmt"
+	"github.com/pivotal-cf/om/interpolate"
 
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
 )
 
 type ConfigureSAMLAuthentication struct {
-	service     configureAuthenticationService
-	logger      logger
-	environFunc func() []string
-	Options     struct {
+	service          configureAuthenticationService
+	logger           logger
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	Options          struct {
 		interpolateConfigFileOptions
 
 		DecryptionPassphrase      string `long:"decryption-passphrase" short:"dp" required:"true" description:"passphrase used to encrypt the installation"`
@@ -28,11 +30,12 @@ type ConfigureSAMLAuthentication struct {
 	}
 }
 
-func NewConfigureSAMLAuthentication(environFunc func() []string, service configureAuthenticationService, logger logger) ConfigureSAMLAuthentication {
+func NewConfigureSAMLAuthentication(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, service configureAuthenticationService, logger logger) ConfigureSAMLAuthentication {
 	return ConfigureSAMLAuthentication{
-		environFunc: environFunc,
-		service:     service,
-		logger:      logger,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		logger:           logger,
 	}
 }
 
@@ -42,7 +45,7 @@ func (ca ConfigureSAMLAuthentication) Execute(args []string) error {
 		opsManUaaClientMsg string
 	)
 
-	err := loadConfigFile(args, &ca.Options, ca.environFunc)
+	err := loadConfigFile(args, &ca.Options, ca.environFunc, ca.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse configure-saml-authentication flags: %s", err)
 	}
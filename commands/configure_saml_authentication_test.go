@@ -7,6 +7,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -42,7 +43,7 @@ var _ = Describe("ConfigureSAMLAuthentication.Execute", func() {
 			Version: "2.5-build.1",
 		}, nil)
 
-		command = commands.NewConfigureSAMLAuthentication(nil, service, logger)
+		command = commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 
 		commandLineArgs = []string{
 			"--decryption-passphrase", "some-passphrase",
@@ -225,7 +226,7 @@ This was skipped due to the 'skip-create-bosh-admin-client' flag.
 				Status: api.EnsureAvailabilityStatusComplete,
 			}, nil)
 
-			command := commands.NewConfigureSAMLAuthentication(nil, service, logger)
+			command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 			err := command.Execute(commandLineArgs)
 			Expect(err).ToNot(HaveOccurred())
 
@@ -332,7 +333,7 @@ saml-rbac-groups-attribute: myenterprise
 
 		Context("variables are not provided", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureSAMLAuthentication(nil, service, logger)
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 				})
@@ -357,7 +358,7 @@ passphrase: a-vars-file-passphrase
 			})
 
 			It("uses values from the vars file", func() {
-				command := commands.NewConfigureSAMLAuthentication(nil, service, logger)
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 					"--vars-file", varsFile,
@@ -380,7 +381,7 @@ passphrase: a-vars-file-passphrase
 
 		Context("passed in a var (--var)", func() {
 			It("uses values from the command line", func() {
-				command := commands.NewConfigureSAMLAuthentication(nil, service, logger)
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, logger)
 				err := command.Execute([]string{
 					"--config", configFile,
 					"--var", "passphrase=a-command-line-passphrase",
@@ -405,6 +406,7 @@ passphrase: a-vars-file-passphrase
 			It("interpolates variables into the configuration", func() {
 				command := commands.NewConfigureSAMLAuthentication(
 					func() []string { return []string{"OM_VAR_passphrase=an-env-var-passphrase"} },
+					interpolate.VarsSourceConfig{},
 					service,
 					logger,
 				)
@@ -435,6 +437,7 @@ passphrase: a-vars-file-passphrase
 
 				command := commands.NewConfigureSAMLAuthentication(
 					func() []string { return []string{"OM_VAR_passphrase=an-env-var-passphrase"} },
+					interpolate.VarsSourceConfig{},
 					service,
 					logger,
 				)
@@ -479,7 +482,7 @@ passphrase: a-vars-file-passphrase
 			It("returns an error", func() {
 				service.EnsureAvailabilityReturns(api.EnsureAvailabilityOutput{}, errors.New("failed to fetch status"))
 
-				command := commands.NewConfigureSAMLAuthentication(nil, service, &fakes.Logger{})
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, &fakes.Logger{})
 				err := command.Execute(commandLineArgs)
 				Expect(err).To(MatchError("could not determine initial configuration status: failed to fetch status"))
 			})
@@ -491,7 +494,7 @@ passphrase: a-vars-file-passphrase
 					Status: api.EnsureAvailabilityStatusUnknown,
 				}, nil)
 
-				command := commands.NewConfigureSAMLAuthentication(nil, service, &fakes.Logger{})
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, &fakes.Logger{})
 				err := command.Execute(commandLineArgs)
 				Expect(err).To(MatchError("could not determine initial configuration status: received unexpected status"))
 			})
@@ -505,7 +508,7 @@ passphrase: a-vars-file-passphrase
 
 				service.SetupReturns(api.SetupOutput{}, errors.New("could not setup"))
 
-				command := commands.NewConfigureSAMLAuthentication(nil, service, &fakes.Logger{})
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, &fakes.Logger{})
 				err := command.Execute(commandLineArgs)
 				Expect(err).To(MatchError("could not configure authentication: could not setup"))
 			})
@@ -526,7 +529,7 @@ passphrase: a-vars-file-passphrase
 					return eaOutputs[service.EnsureAvailabilityCallCount()-1], eaErrors[service.EnsureAvailabilityCallCount()-1]
 				}
 
-				command := commands.NewConfigureSAMLAuthentication(nil, service, &fakes.Logger{})
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, service, &fakes.Logger{})
 				err := command.Execute(commandLineArgs)
 				Expect(err).To(MatchError("could not determine final configuration status: failed to fetch status"))
 			})
@@ -534,7 +537,7 @@ passphrase: a-vars-file-passphrase
 
 		When("the --saml-idp-metadata field is not configured with others", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureSAMLAuthentication(nil, nil, nil)
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute([]string{
 					"--decryption-passphrase", "some-passphrase",
 					"--saml-bosh-idp-metadata", "https://bosh-saml.example.com:8080",
@@ -549,7 +552,7 @@ passphrase: a-vars-file-passphrase
 
 		When("the --saml-bosh-idp-metadata field is not configured with others", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureSAMLAuthentication(nil, nil, nil)
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute([]string{
 					"--decryption-passphrase", "some-passphrase",
 					"--saml-idp-metadata", "https://saml.example.com:8080",
@@ -564,7 +567,7 @@ passphrase: a-vars-file-passphrase
 
 		When("the --saml-rbac-admin-group field is not configured with others", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureSAMLAuthentication(nil, nil, nil)
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute([]string{
 					"--decryption-passphrase", "some-passphrase",
 					"--saml-idp-metadata", "https://saml.example.com:8080",
@@ -579,7 +582,7 @@ passphrase: a-vars-file-passphrase
 
 		When("the --saml-rbac-groups-attribute field is not configured with others", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureSAMLAuthentication(nil, nil, nil)
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute([]string{
 					"--decryption-passphrase", "some-passphrase",
 					"--saml-idp-metadata", "https://saml.example.com:8080",
@@ -594,7 +597,7 @@ passphrase: a-vars-file-passphrase
 
 		When("the --decryption-passphrase flag is missing", func() {
 			It("returns an error", func() {
-				command := commands.NewConfigureSAMLAuthentication(nil, nil, nil)
+				command := commands.NewConfigureSAMLAuthentication(nil, interpolate.VarsSourceConfig{}, nil, nil)
 				err := command.Execute([]string{
 					"--saml-idp-metadata", "https://saml.example.com:8080",
 					"--saml-bosh-idp-metadata", "https://bosh-saml.example.com:8080",
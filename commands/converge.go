@@ -0,0 +1,505 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/interpolate"
+	"gopkg.in/yaml.v2"
+)
+
+type Converge struct {
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	service          convergeService
+	commands         jhanda.CommandSet
+	logger           logger
+	Options          struct {
+		ConfigFile        string   `long:"config"             short:"c"         description:"path to the foundation manifest (see docs/converge/README.md for format)" required:"true"`
+		VarsFile          []string `long:"vars-file"          short:"l"         description:"load variables from a YAML file, for the foundation manifest and every config file it references"`
+		VarsEnv           []string `long:"vars-env"           env:"OM_VARS_ENV" description:"load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
+		Vars              []string `long:"var"                short:"v"         description:"load variable from the command line. Format: VAR=VAL"`
+		DownloadDirectory string   `long:"download-directory"                   description:"directory to download products and stemcells to (defaults to a temporary directory that is removed afterwards)"`
+		ApplyChanges      bool     `long:"apply-changes"                        description:"finish by applying changes to the products of the manifest that have pending changes"`
+		DryRun            bool     `long:"dry-run"                              description:"print the steps that are needed, without taking them"`
+	}
+}
+
+//counterfeiter:generate -o ./fakes/converge_service.go --fake-name ConvergeService . convergeService
+type convergeService interface {
+	CheckProductAvailability(productName string, productVersion string) (bool, error)
+	GetDiagnosticReport() (api.DiagnosticReport, error)
+	ListStagedPendingChanges() (api.PendingChangesOutput, error)
+	ListStagedProducts() (api.StagedProductsOutput, error)
+	ListStemcells() (api.ProductStemcells, error)
+
+	GetStagedDirectorAvailabilityZones() (api.AvailabilityZonesOutput, error)
+	GetStagedDirectorIaasConfigurations(redact bool) (map[string][]map[string]interface{}, error)
+	GetStagedDirectorNetworks() (api.NetworksConfigurationOutput, error)
+	GetStagedDirectorProperties(redact bool) (map[string]interface{}, error)
+	GetStagedProductByName(productName string) (api.StagedProductsFindOutput, error)
+	GetStagedProductJobMaxInFlight(productGUID string) (map[string]interface{}, error)
+	GetStagedProductJobResourceConfig(productGUID, jobGUID string) (api.JobProperties, error)
+	GetStagedProductNetworksAndAZs(productGUID string) (map[string]interface{}, error)
+	GetStagedProductProperties(productGUID string, redact bool) (map[string]api.ResponseProperty, error)
+	GetStagedProductSyslogConfiguration(productGUID string) (map[string]interface{}, error)
+	ListStagedProductErrands(productGUID string) (api.ErrandsListOutput, error)
+	ListStagedProductJobs(productGUID string) (map[string]string, error)
+	ListStagedVMExtensions() ([]api.VMExtension, error)
+	ListVMTypes() ([]api.VMType, error)
+}
+
+type foundationManifest struct {
+	Director *foundationDirector `yaml:"director,omitempty"`
+	Products []foundationProduct `yaml:"products"`
+}
+
+type foundationDirector struct {
+	Config   string `yaml:"config"`
+	VarsFile string `yaml:"vars-file,omitempty"`
+}
+
+type foundationProduct struct {
+	Name     string              `yaml:"name"`
+	Version  string              `yaml:"version"`
+	File     string              `yaml:"file,omitempty"`
+	Download string              `yaml:"download,omitempty"`
+	Config   string              `yaml:"config,omitempty"`
+	VarsFile string              `yaml:"vars-file,omitempty"`
+	Stemcell *foundationStemcell `yaml:"stemcell,omitempty"`
+}
+
+type foundationStemcell struct {
+	Version string `yaml:"version"`
+	File    string `yaml:"file,omitempty"`
+}
+
+func NewConverge(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, service convergeService, commands jhanda.CommandSet, logger logger) Converge {
+	return Converge{
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		commands:         commands,
+		logger:           logger,
+	}
+}
+
+func (c Converge) Execute(args []string) error {
+	if _, err := jhanda.Parse(&c.Options, args); err != nil {
+		return fmt.Errorf("could not parse converge flags: %s", err)
+	}
+
+	manifest, err := c.loadManifest()
+	if err != nil {
+		return err
+	}
+
+	if manifest.Director != nil && manifest.Director.Config != "" {
+		err = c.convergeDirector(*manifest.Director)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.Options.DownloadDirectory == "" && !c.Options.DryRun {
+		c.Options.DownloadDirectory, err = ioutil.TempDir("", "om-converge")
+		if err != nil {
+			return fmt.Errorf("could not create download directory: %s", err)
+		}
+		defer os.RemoveAll(c.Options.DownloadDirectory)
+	}
+
+	report, err := c.service.GetDiagnosticReport()
+	if err != nil {
+		return fmt.Errorf("could not determine the staged products: %s", err)
+	}
+
+	stagedVersions := map[string]string{}
+	for _, product := range report.StagedProducts {
+		stagedVersions[product.Name] = product.Version
+	}
+
+	for _, product := range manifest.Products {
+		err = c.convergeProduct(product, stagedVersions[product.Name])
+		if err != nil {
+			return err
+		}
+	}
+
+	if !c.Options.ApplyChanges {
+		return nil
+	}
+
+	return c.applyChanges(manifest)
+}
+
+func (c Converge) loadManifest() (foundationManifest, error) {
+	contents, err := interpolate.Execute(interpolate.Options{
+		TemplateFile:     c.Options.ConfigFile,
+		VarsFiles:        c.Options.VarsFile,
+		VarsEnvs:         c.Options.VarsEnv,
+		Vars:             c.Options.Vars,
+		EnvironFunc:      c.environFunc,
+		VarsSourceConfig: c.varsSourceConfig,
+		ExpectAllKeys:    true,
+	})
+	if err != nil {
+		return foundationManifest{}, fmt.Errorf("could not load the foundation manifest: %s", err)
+	}
+
+	var manifest foundationManifest
+	err = yaml.UnmarshalStrict(contents, &manifest)
+	if err != nil {
+		return foundationManifest{}, fmt.Errorf("could not parse the foundation manifest: %s", err)
+	}
+
+	// paths in the manifest are relative to the manifest itself
+	dir := filepath.Dir(c.Options.ConfigFile)
+	relativeTo := func(path string) string {
+		if path == "" || filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(dir, path)
+	}
+
+	if manifest.Director != nil {
+		manifest.Director.Config = relativeTo(manifest.Director.Config)
+		manifest.Director.VarsFile = relativeTo(manifest.Director.VarsFile)
+	}
+
+	names := map[string]bool{}
+	for i, product := range manifest.Products {
+		switch {
+		case product.Name == "" || product.Version == "":
+			return foundationManifest{}, fmt.Errorf("every product in the foundation manifest requires a name and a version")
+		case names[product.Name]:
+			return foundationManifest{}, fmt.Errorf("product %q is listed more than once in the foundation manifest", product.Name)
+		case product.File != "" && product.Download != "":
+			return foundationManifest{}, fmt.Errorf("product %q can have either a file or a download, not both", product.Name)
+		case product.Stemcell != nil && product.Stemcell.Version == "":
+			return foundationManifest{}, fmt.Errorf("the stemcell of product %q requires a version", product.Name)
+		}
+		names[product.Name] = true
+
+		manifest.Products[i].File = relativeTo(product.File)
+		manifest.Products[i].Download = relativeTo(product.Download)
+		manifest.Products[i].Config = relativeTo(product.Config)
+		manifest.Products[i].VarsFile = relativeTo(product.VarsFile)
+		if product.Stemcell != nil {
+			manifest.Products[i].Stemcell.File = relativeTo(product.Stemcell.File)
+		}
+	}
+
+	return manifest, nil
+}
+
+func (c Converge) convergeProduct(product foundationProduct, stagedVersion string) error {
+	available, err := c.service.CheckProductAvailability(product.Name, product.Version)
+	if err != nil {
+		return fmt.Errorf("could not check whether %s %s is uploaded: %s", product.Name, product.Version, err)
+	}
+
+	if available {
+		c.logger.Printf("%s %s is already uploaded", product.Name, product.Version)
+	} else {
+		product, err = c.uploadProduct(product)
+		if err != nil {
+			return err
+		}
+	}
+
+	if stagedVersion == product.Version {
+		c.logger.Printf("%s %s is already staged", product.Name, product.Version)
+	} else {
+		err = c.run(fmt.Sprintf("stage %s %s", product.Name, product.Version), "stage-product",
+			"--product-name", product.Name,
+			"--product-version", product.Version,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if product.Stemcell != nil {
+		err = c.convergeStemcell(product)
+		if err != nil {
+			return err
+		}
+	}
+
+	if product.Config == "" {
+		return nil
+	}
+
+	// a product staged at another version can have other properties,
+	// so it is configured once staged without comparing
+	if stagedVersion == product.Version || !c.Options.DryRun {
+		differences, err := c.productDifferences(product.Config, product.VarsFile, product.Name)
+		if err != nil {
+			return fmt.Errorf("could not compare the config of %s with its staged config: %s", product.Name, err)
+		}
+
+		if len(differences) == 0 {
+			c.logger.Printf("%s is already configured", product.Name)
+			return nil
+		}
+		c.logger.Printf("the config of %s differs from its staged config at %s", product.Name, summarizeDifferences(differences))
+	}
+
+	return c.run(fmt.Sprintf("configure %s", product.Name), "configure-product", c.withConfigVars(product.VarsFile, "--config", product.Config)...)
+}
+
+func (c Converge) convergeDirector(director foundationDirector) error {
+	differences, err := c.directorDifferences(director.Config, director.VarsFile)
+	if err != nil {
+		return fmt.Errorf("could not compare the config of the director with its staged config: %s", err)
+	}
+
+	if len(differences) == 0 {
+		c.logger.Printf("the director is already configured")
+		return nil
+	}
+	c.logger.Printf("the config of the director differs from its staged config at %s", summarizeDifferences(differences))
+
+	return c.run("configure the director", "configure-director", c.withConfigVars(director.VarsFile, "--config", director.Config)...)
+}
+
+// uploadProduct returns the product with the file (and stemcell)
+// filled in when they had to be downloaded.
+func (c Converge) uploadProduct(product foundationProduct) (foundationProduct, error) {
+	if product.File == "" && product.Download == "" {
+		return product, fmt.Errorf("%s %s is not uploaded, and the foundation manifest has neither a file nor a download for it", product.Name, product.Version)
+	}
+
+	if product.Download != "" {
+		err := c.run(fmt.Sprintf("download %s %s", product.Name, product.Version), "download-product",
+			c.withVars("--config", product.Download, "--output-directory", c.Options.DownloadDirectory)...,
+		)
+		if err != nil {
+			return product, err
+		}
+
+		if c.Options.DryRun {
+			product.File = filepath.Join(c.Options.DownloadDirectory, product.Name+".pivotal")
+		} else {
+			product, err = c.downloadedProduct(product)
+			if err != nil {
+				return product, err
+			}
+		}
+	}
+
+	return product, c.run(fmt.Sprintf("upload %s %s", product.Name, product.Version), "upload-product",
+		"--product", product.File,
+		"--product-version", product.Version,
+	)
+}
+
+func (c Converge) downloadedProduct(product foundationProduct) (foundationProduct, error) {
+	contents, err := ioutil.ReadFile(filepath.Join(c.Options.DownloadDirectory, "download-file.json"))
+	if err != nil {
+		return product, fmt.Errorf("could not find what was downloaded for %s: %s", product.Name, err)
+	}
+
+	var downloaded struct {
+		ProductPath     string `json:"product_path"`
+		StemcellPath    string `json:"stemcell_path"`
+		StemcellVersion string `json:"stemcell_version"`
+	}
+	err = json.Unmarshal(contents, &downloaded)
+	if err != nil {
+		return product, fmt.Errorf("could not find what was downloaded for %s: %s", product.Name, err)
+	}
+
+	product.File = downloaded.ProductPath
+
+	if downloaded.StemcellPath != "" {
+		if product.Stemcell == nil {
+			product.Stemcell = &foundationStemcell{Version: downloaded.StemcellVersion}
+		}
+
+		if product.Stemcell.File == "" && product.Stemcell.Version == downloaded.StemcellVersion {
+			product.Stemcell.File = downloaded.StemcellPath
+		}
+	}
+
+	return product, nil
+}
+
+func (c Converge) convergeStemcell(product foundationProduct) error {
+	stemcells, err := c.service.ListStemcells()
+	if err != nil {
+		return fmt.Errorf("could not list the stemcells of %s: %s", product.Name, err)
+	}
+
+	var assignment api.ProductStemcell
+	for _, p := range stemcells.Products {
+		if p.ProductName == product.Name {
+			assignment = p
+			break
+		}
+	}
+
+	if assignment.StagedStemcellVersion == product.Stemcell.Version {
+		c.logger.Printf("stemcell %s is already assigned to %s", product.Stemcell.Version, product.Name)
+		return nil
+	}
+
+	var uploaded bool
+	for _, version := range assignment.AvailableVersions {
+		if version == product.Stemcell.Version {
+			uploaded = true
+			break
+		}
+	}
+
+	if !uploaded {
+		if product.Stemcell.File == "" {
+			// a freshly staged product is not listed yet when dry-running
+			if !c.Options.DryRun || assignment.ProductName != "" {
+				return fmt.Errorf("stemcell %s for %s is not uploaded, and the foundation manifest has no file for it", product.Stemcell.Version, product.Name)
+			}
+		} else {
+			err = c.run(fmt.Sprintf("upload stemcell %s", product.Stemcell.Version), "upload-stemcell",
+				"--stemcell", product.Stemcell.File,
+				"--floating", "false",
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return c.run(fmt.Sprintf("assign stemcell %s to %s", product.Stemcell.Version, product.Name), "assign-stemcell",
+		"--product", product.Name,
+		"--stemcell", product.Stemcell.Version,
+	)
+}
+
+func (c Converge) applyChanges(manifest foundationManifest) error {
+	if c.Options.DryRun {
+		c.logger.Printf("would apply changes to the products with pending changes")
+		return nil
+	}
+
+	stagedProducts, err := c.service.ListStagedProducts()
+	if err != nil {
+		return fmt.Errorf("could not list the staged products: %s", err)
+	}
+
+	names := map[string]string{}
+	for _, product := range stagedProducts.Products {
+		names[product.GUID] = product.Type
+	}
+
+	inManifest := map[string]bool{}
+	for _, product := range manifest.Products {
+		inManifest[product.Name] = true
+	}
+
+	pendingChanges, err := c.service.ListStagedPendingChanges()
+	if err != nil {
+		return fmt.Errorf("could not list the pending changes: %s", err)
+	}
+
+	var (
+		changedProducts []string
+		directorChanged bool
+	)
+	for _, change := range pendingChanges.ChangeList {
+		if change.Action == "unchanged" {
+			continue
+		}
+
+		name := names[change.GUID]
+		switch {
+		case name == "p-bosh" || strings.HasPrefix(change.GUID, "p-bosh-"):
+			directorChanged = true
+		case inManifest[name]:
+			changedProducts = append(changedProducts, name)
+		default:
+			c.logger.Printf("leaving the pending changes of %s alone, as it is not in the foundation manifest", change.GUID)
+		}
+	}
+
+	sort.Strings(changedProducts)
+
+	switch {
+	case len(changedProducts) > 0:
+		var args []string
+		for _, name := range changedProducts {
+			args = append(args, "--product-name", name)
+		}
+
+		return c.run(fmt.Sprintf("apply changes to %s", strings.Join(changedProducts, ", ")), "apply-changes", args...)
+	case directorChanged:
+		return c.run("apply changes to the director", "apply-changes", "--skip-deploy-products")
+	default:
+		c.logger.Printf("there are no changes to apply")
+		return nil
+	}
+}
+
+// withConfigVars passes on the vars file of a config before the vars flags,
+// so that the vars flags take precedence.
+func (c Converge) withConfigVars(varsFile string, args ...string) []string {
+	if varsFile != "" {
+		args = append(args, "--vars-file", varsFile)
+	}
+
+	return c.withVars(args...)
+}
+
+// configVarsFiles are the vars files of a config, as withConfigVars passes them on.
+func (c Converge) configVarsFiles(varsFile string) []string {
+	if varsFile == "" {
+		return c.Options.VarsFile
+	}
+
+	return append([]string{varsFile}, c.Options.VarsFile...)
+}
+
+func (c Converge) withVars(args ...string) []string {
+	for _, varsFile := range c.Options.VarsFile {
+		args = append(args, "--vars-file", varsFile)
+	}
+	for _, varsEnv := range c.Options.VarsEnv {
+		args = append(args, "--vars-env", varsEnv)
+	}
+	for _, v := range c.Options.Vars {
+		args = append(args, "--var", v)
+	}
+
+	return args
+}
+
+func (c Converge) run(description string, command string, args ...string) error {
+	if c.Options.DryRun {
+		c.logger.Printf("would %s: om %s %s", description, command, strings.Join(args, " "))
+		return nil
+	}
+
+	c.logger.Printf("%s...", description)
+
+	err := c.commands.Execute(command, args)
+	if err != nil {
+		return fmt.Errorf("could not %s: %w", description, err)
+	}
+
+	return nil
+}
+
+func (c Converge) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command brings the targeted Ops Manager in line with a foundation manifest. It configures the director, and uploads, stages, assigns stemcells to and configures each product, skipping what is already in place, including the configs that are already staged. With --apply-changes, it finishes by applying changes to the products that have pending changes.",
+		ShortDescription: "converges the Ops Manager to a foundation manifest",
+		Flags:            c.Options,
+	}
+}
@@ -0,0 +1,369 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	yamlConverter "github.com/ghodss/yaml"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/configparser"
+	"github.com/pivotal-cf/om/interpolate"
+)
+
+// maxListedDifferences is how many differences are logged before summarizing the others.
+const maxListedDifferences = 5
+
+// directorDifferences lists where the director config differs from the staged director,
+// with the same keys as `om staged-director-config --no-redact`.
+func (c Converge) directorDifferences(configFile string, varsFile string) ([]string, error) {
+	want, err := c.loadConfig(configFile, varsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	stagedDirector, err := c.service.GetStagedProductByName("p-bosh")
+	if err != nil {
+		return nil, fmt.Errorf("could not find the staged director: %s", err)
+	}
+	directorGUID := stagedDirector.Product.GUID
+
+	var differences []string
+	for _, key := range sortedConfigKeys(want) {
+		// like the configure commands, empty keys are left alone
+		if want[key] == nil {
+			continue
+		}
+
+		var have interface{}
+
+		switch key {
+		case "az-configuration":
+			azs, err := c.service.GetStagedDirectorAvailabilityZones()
+			if err != nil {
+				return nil, err
+			}
+			have = azs.AvailabilityZones
+		case "networks-configuration":
+			have, err = c.service.GetStagedDirectorNetworks()
+		case "network-assignment":
+			have, err = c.service.GetStagedProductNetworksAndAZs(directorGUID)
+		case "properties-configuration":
+			have, err = c.service.GetStagedDirectorProperties(false)
+		case "iaas-configurations":
+			var iaasConfigurations map[string][]map[string]interface{}
+			iaasConfigurations, err = c.service.GetStagedDirectorIaasConfigurations(false)
+			have = iaasConfigurations["iaas_configurations"]
+		case "resource-configuration":
+			have, err = c.stagedResourceConfig(directorGUID, false)
+		case "vmextensions-configuration":
+			have, err = c.service.ListStagedVMExtensions()
+		case "vmtypes-configuration":
+			have, err = c.stagedVMTypes()
+		default:
+			differences = append(differences, key)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get the staged %s of the director: %s", key, err)
+		}
+
+		keyDifferences, err := configDifferences(key, want[key], have)
+		if err != nil {
+			return nil, err
+		}
+		differences = append(differences, keyDifferences...)
+	}
+
+	return differences, nil
+}
+
+// productDifferences lists where the product config differs from the staged product,
+// with the same keys as `om staged-config --include-credentials`.
+func (c Converge) productDifferences(configFile string, varsFile string, productName string) ([]string, error) {
+	want, err := c.loadConfig(configFile, varsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if name, ok := want["product-name"].(string); ok {
+		productName = name
+	}
+
+	stagedProducts, err := c.service.ListStagedProducts()
+	if err != nil {
+		return nil, fmt.Errorf("could not list the staged products: %s", err)
+	}
+
+	var productGUID string
+	for _, product := range stagedProducts.Products {
+		if product.Type == productName {
+			productGUID = product.GUID
+			break
+		}
+	}
+	if productGUID == "" {
+		return []string{fmt.Sprintf("%s is not staged", productName)}, nil
+	}
+
+	var differences []string
+	for _, key := range sortedConfigKeys(want) {
+		// like the configure commands, empty keys are left alone
+		if want[key] == nil {
+			continue
+		}
+
+		var have interface{}
+
+		switch key {
+		case "product-name", "product-version", "validate-config-complete":
+			continue
+		case "product-properties":
+			have, err = c.stagedProductProperties(productGUID)
+			normalizeSelectedOptions(want[key])
+		case "network-properties":
+			have, err = c.service.GetStagedProductNetworksAndAZs(productGUID)
+		case "resource-config":
+			have, err = c.stagedResourceConfig(productGUID, true)
+		case "errand-config":
+			have, err = c.stagedErrands(productGUID)
+		case "syslog-properties":
+			have, err = c.service.GetStagedProductSyslogConfiguration(productGUID)
+		default:
+			differences = append(differences, key)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not get the staged %s of %s: %s", key, productName, err)
+		}
+
+		keyDifferences, err := configDifferences(key, want[key], have)
+		if err != nil {
+			return nil, err
+		}
+		differences = append(differences, keyDifferences...)
+	}
+
+	return differences, nil
+}
+
+func (c Converge) loadConfig(configFile string, varsFile string) (map[string]interface{}, error) {
+	contents, err := interpolate.Execute(interpolate.Options{
+		TemplateFile:     configFile,
+		VarsFiles:        c.configVarsFiles(varsFile),
+		VarsEnvs:         c.Options.VarsEnv,
+		Vars:             c.Options.Vars,
+		EnvironFunc:      c.environFunc,
+		VarsSourceConfig: c.varsSourceConfig,
+		ExpectAllKeys:    true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not load %s: %s", configFile, err)
+	}
+
+	contents, err = yamlConverter.YAMLToJSON(contents)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", configFile, err)
+	}
+
+	var config map[string]interface{}
+	err = json.Unmarshal(contents, &config)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %s", configFile, err)
+	}
+
+	return config, nil
+}
+
+func (c Converge) stagedProductProperties(productGUID string) (map[string]interface{}, error) {
+	properties, err := c.service.GetStagedProductProperties(productGUID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// the credentials are not redacted, so their values can be compared as they are
+	withCredentials := func(name configparser.PropertyName, property api.ResponseProperty) (map[string]interface{}, error) {
+		return map[string]interface{}{"value": property.Value}, nil
+	}
+
+	parser := configparser.NewConfigParser()
+	staged := map[string]interface{}{}
+	for name, property := range properties {
+		if property.Value == nil {
+			continue
+		}
+
+		output, err := parser.ParseProperties(configparser.NewPropertyName(name), property, withCredentials)
+		if err != nil {
+			return nil, err
+		}
+		if len(output) > 0 {
+			staged[name] = output
+		}
+	}
+
+	return staged, nil
+}
+
+func (c Converge) stagedResourceConfig(productGUID string, withMaxInFlight bool) (map[string]interface{}, error) {
+	jobs, err := c.service.ListStagedProductJobs(productGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobsToMaxInFlight map[string]interface{}
+	if withMaxInFlight {
+		jobsToMaxInFlight, err = c.service.GetStagedProductJobMaxInFlight(productGUID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	resourceConfig := map[string]interface{}{}
+	for name, jobGUID := range jobs {
+		jobProperties, err := c.service.GetStagedProductJobResourceConfig(productGUID, jobGUID)
+		if err != nil {
+			return nil, err
+		}
+
+		job := map[string]interface{}{}
+		for key, value := range jobProperties {
+			job[key] = value
+		}
+		if maxInFlight, ok := jobsToMaxInFlight[jobGUID]; ok {
+			job["max_in_flight"] = maxInFlight
+		}
+		resourceConfig[name] = job
+	}
+
+	return resourceConfig, nil
+}
+
+func (c Converge) stagedErrands(productGUID string) (map[string]interface{}, error) {
+	errands, err := c.service.ListStagedProductErrands(productGUID)
+	if err != nil {
+		return nil, err
+	}
+
+	staged := map[string]interface{}{}
+	for _, errand := range errands.Errands {
+		staged[errand.Name] = map[string]interface{}{
+			"post-deploy-state": errand.PostDeploy,
+			"pre-delete-state":  errand.PreDelete,
+		}
+	}
+
+	return staged, nil
+}
+
+func (c Converge) stagedVMTypes() (VMTypesConfiguration, error) {
+	vmTypes, err := c.service.ListVMTypes()
+	if err != nil {
+		return VMTypesConfiguration{}, err
+	}
+
+	config := VMTypesConfiguration{}
+	if len(vmTypes) > 0 && !vmTypes[0].BuiltIn {
+		config.CustomTypesOnly = true
+		for _, vmType := range vmTypes {
+			config.VMTypes = append(config.VMTypes, vmType.CreateVMType)
+		}
+	}
+
+	return config, nil
+}
+
+// normalizeSelectedOptions names the option of selectors selected_option, as the staged properties do.
+// configure-product accepts it as option_value too.
+func normalizeSelectedOptions(properties interface{}) {
+	propertiesByName, _ := properties.(map[string]interface{})
+	for _, property := range propertiesByName {
+		if property, ok := property.(map[string]interface{}); ok {
+			if optionValue, ok := property["option_value"]; ok && property["selected_option"] == nil {
+				property["selected_option"] = optionValue
+				delete(property, "option_value")
+			}
+		}
+	}
+}
+
+// configDifferences lists the paths at which the wanted config is not staged.
+// Only the keys of the wanted config are compared, as the staged config
+// also has the defaults and the generated values that configs leave out.
+func configDifferences(path string, want interface{}, staged interface{}) ([]string, error) {
+	// both sides are compared as JSON, like they are sent to and received from Ops Manager
+	stagedJSON, err := getJSONProperties(staged)
+	if err != nil {
+		return nil, fmt.Errorf("could not compare the staged %s: %s", path, err)
+	}
+
+	var have interface{}
+	err = json.Unmarshal([]byte(stagedJSON), &have)
+	if err != nil {
+		return nil, fmt.Errorf("could not compare the staged %s: %s", path, err)
+	}
+
+	return differences(path, want, have), nil
+}
+
+func differences(path string, want, have interface{}) []string {
+	switch want := want.(type) {
+	case map[string]interface{}:
+		have, ok := have.(map[string]interface{})
+		if !ok {
+			return []string{path}
+		}
+
+		var paths []string
+		for _, key := range sortedConfigKeys(want) {
+			// the names of product properties start with a dot already
+			keyPath := path + "." + strings.TrimPrefix(key, ".")
+
+			value, ok := have[key]
+			if !ok {
+				paths = append(paths, keyPath)
+				continue
+			}
+			paths = append(paths, differences(keyPath, want[key], value)...)
+		}
+		return paths
+
+	case []interface{}:
+		have, ok := have.([]interface{})
+		if !ok || len(have) != len(want) {
+			return []string{path}
+		}
+
+		var paths []string
+		for i := range want {
+			paths = append(paths, differences(path+"["+strconv.Itoa(i)+"]", want[i], have[i])...)
+		}
+		return paths
+
+	default:
+		if !reflect.DeepEqual(want, have) {
+			return []string{path}
+		}
+		return nil
+	}
+}
+
+func sortedConfigKeys(config map[string]interface{}) []string {
+	var keys []string
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func summarizeDifferences(differences []string) string {
+	if len(differences) <= maxListedDifferences {
+		return strings.Join(differences, ", ")
+	}
+
+	return fmt.Sprintf("%s and %d more", strings.Join(differences[:maxListedDifferences], ", "), len(differences)-maxListedDifferences)
+}
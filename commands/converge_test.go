@@ -0,0 +1,508 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+type recordingCommand struct {
+	name     string
+	executed *[]string
+	execute  func(args []string) error
+}
+
+func (r recordingCommand) Execute(args []string) error {
+	*r.executed = append(*r.executed, strings.TrimSpace(r.name+" "+strings.Join(args, " ")))
+	if r.execute != nil {
+		return r.execute(args)
+	}
+	return nil
+}
+
+func (r recordingCommand) Usage() jhanda.Usage {
+	return jhanda.Usage{}
+}
+
+var _ = Describe("Converge", func() {
+	var (
+		service     *fakes.ConvergeService
+		stdout      *gbytes.Buffer
+		commandSet  jhanda.CommandSet
+		executed    []string
+		dir         string
+		manifest    string
+		environFunc func() []string
+	)
+
+	writeManifest := func(contents string) {
+		Expect(ioutil.WriteFile(manifest, []byte(contents), 0600)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		service = &fakes.ConvergeService{}
+		stdout = gbytes.NewBuffer()
+		executed = nil
+		environFunc = func() []string { return nil }
+
+		commandSet = jhanda.CommandSet{}
+		for _, name := range []string{"configure-director", "download-product", "upload-product", "stage-product", "upload-stemcell", "assign-stemcell", "configure-product", "apply-changes"} {
+			commandSet[name] = recordingCommand{name: name, executed: &executed}
+		}
+
+		var err error
+		dir, err = ioutil.TempDir("", "converge")
+		Expect(err).ToNot(HaveOccurred())
+		manifest = filepath.Join(dir, "foundation.yml")
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "director.yml"), []byte(`
+properties-configuration:
+  director_configuration:
+    ntp_servers_string: ntp.example.com
+resource-configuration:
+  director:
+    instance_type:
+      id: m5.large
+`), 0600)).To(Succeed())
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "cf.yml"), []byte(`
+product-name: cf
+product-properties:
+  .properties.foo:
+    value: bar
+  .properties.selector:
+    option_value: internal
+  .properties.secret:
+    value:
+      secret: some-secret
+network-properties:
+  network:
+    name: deployment
+resource-config:
+  router:
+    instances: 2
+    max_in_flight: 1
+errand-config:
+  smoke_tests:
+    post-deploy-state: true
+`), 0600)).To(Succeed())
+
+		writeManifest(`
+director:
+  config: director.yml
+products:
+- name: cf
+  version: 2.10.3
+  file: cf-2.10.3.pivotal
+  config: cf.yml
+  stemcell:
+    version: "621.77"
+    file: /stemcells/stemcell-621.77.tgz
+`)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	newConverge := func() commands.Converge {
+		return commands.NewConverge(environFunc, interpolate.VarsSourceConfig{}, service, commandSet, log.New(stdout, "", 0))
+	}
+
+	// stageEverything stages what the foundation manifest describes, configs included
+	stageEverything := func() {
+		service.CheckProductAvailabilityReturns(true, nil)
+		service.GetDiagnosticReportReturns(api.DiagnosticReport{
+			StagedProducts: []api.DiagnosticProduct{{Name: "cf", Version: "2.10.3"}},
+		}, nil)
+		service.ListStemcellsReturns(api.ProductStemcells{
+			Products: []api.ProductStemcell{{ProductName: "cf", StagedStemcellVersion: "621.77"}},
+		}, nil)
+
+		service.GetStagedProductByNameReturns(api.StagedProductsFindOutput{Product: api.StagedProduct{GUID: "p-bosh-guid", Type: "p-bosh"}}, nil)
+		service.GetStagedDirectorPropertiesReturns(map[string]interface{}{
+			"director_configuration": map[string]interface{}{
+				"ntp_servers_string": "ntp.example.com",
+				"max_threads":        5,
+			},
+		}, nil)
+		service.ListStagedProductJobsStub = func(productGUID string) (map[string]string, error) {
+			if productGUID == "p-bosh-guid" {
+				return map[string]string{"director": "director-guid"}, nil
+			}
+			return map[string]string{"router": "router-guid"}, nil
+		}
+		service.GetStagedProductJobResourceConfigReturns(api.JobProperties{
+			"instances":     2,
+			"instance_type": map[string]interface{}{"id": "m5.large"},
+		}, nil)
+		service.GetStagedProductJobMaxInFlightReturns(map[string]interface{}{"router-guid": 1}, nil)
+
+		service.ListStagedProductsReturns(api.StagedProductsOutput{
+			Products: []api.StagedProduct{{GUID: "p-bosh-guid", Type: "p-bosh"}, {GUID: "cf-guid", Type: "cf"}},
+		}, nil)
+		service.GetStagedProductPropertiesReturns(map[string]api.ResponseProperty{
+			".properties.foo":      {Value: "bar", Configurable: true},
+			".properties.selector": {Value: "Internal", SelectedOption: "internal", Type: "selector", Configurable: true},
+			".properties.secret":   {Value: map[string]interface{}{"secret": "some-secret"}, Type: "secret", IsCredential: true, Configurable: true},
+			".properties.other":    {Value: "default", Configurable: true},
+		}, nil)
+		service.GetStagedProductNetworksAndAZsReturns(map[string]interface{}{
+			"network":                     map[string]interface{}{"name": "deployment"},
+			"singleton_availability_zone": map[string]interface{}{"name": "az1"},
+		}, nil)
+		service.ListStagedProductErrandsReturns(api.ErrandsListOutput{
+			Errands: []api.Errand{{Name: "smoke_tests", PostDeploy: true}},
+		}, nil)
+	}
+
+	It("takes every step for a product that is not on the Ops Manager", func() {
+		err := newConverge().Execute([]string{"--config", manifest})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(Equal([]string{
+			"configure-director --config " + filepath.Join(dir, "director.yml"),
+			"upload-product --product " + filepath.Join(dir, "cf-2.10.3.pivotal") + " --product-version 2.10.3",
+			"stage-product --product-name cf --product-version 2.10.3",
+			"upload-stemcell --stemcell /stemcells/stemcell-621.77.tgz --floating false",
+			"assign-stemcell --product cf --stemcell 621.77",
+			"configure-product --config " + filepath.Join(dir, "cf.yml"),
+		}))
+
+		productName, productVersion := service.CheckProductAvailabilityArgsForCall(0)
+		Expect(productName).To(Equal("cf"))
+		Expect(productVersion).To(Equal("2.10.3"))
+
+		Expect(stdout).To(gbytes.Say(`configure the director\.\.\.`))
+		Expect(stdout).To(gbytes.Say(`upload cf 2.10.3\.\.\.`))
+	})
+
+	It("skips the steps that are already in place", func() {
+		stageEverything()
+
+		err := newConverge().Execute([]string{"--config", manifest})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(BeEmpty())
+
+		Expect(stdout).To(gbytes.Say(`the director is already configured`))
+		Expect(stdout).To(gbytes.Say(`cf 2.10.3 is already uploaded`))
+		Expect(stdout).To(gbytes.Say(`cf 2.10.3 is already staged`))
+		Expect(stdout).To(gbytes.Say(`stemcell 621.77 is already assigned to cf`))
+		Expect(stdout).To(gbytes.Say(`cf is already configured`))
+
+		_, redact := service.GetStagedProductPropertiesArgsForCall(0)
+		Expect(redact).To(BeFalse())
+	})
+
+	It("only configures what differs from the staged configs", func() {
+		stageEverything()
+		service.GetStagedProductPropertiesReturns(map[string]api.ResponseProperty{
+			".properties.foo":      {Value: "baz", Configurable: true},
+			".properties.selector": {Value: "Internal", SelectedOption: "internal", Type: "selector", Configurable: true},
+		}, nil)
+
+		err := newConverge().Execute([]string{"--config", manifest})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(Equal([]string{
+			"configure-product --config " + filepath.Join(dir, "cf.yml"),
+		}))
+		Expect(stdout).To(gbytes.Say(`the director is already configured`))
+		Expect(stdout).To(gbytes.Say(`the config of cf differs from its staged config at product-properties.properties.foo.value, product-properties.properties.secret`))
+	})
+
+	It("configures the director when its config differs from the staged config", func() {
+		stageEverything()
+		service.GetStagedProductJobResourceConfigReturns(api.JobProperties{
+			"instances":     2,
+			"instance_type": map[string]interface{}{"id": "automatic"},
+		}, nil)
+
+		err := newConverge().Execute([]string{"--config", manifest})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(ContainElement("configure-director --config " + filepath.Join(dir, "director.yml")))
+		Expect(stdout).To(gbytes.Say(`the config of the director differs from its staged config at resource-configuration.director.instance_type.id`))
+	})
+
+	It("does not upload a stemcell that is already available", func() {
+		service.ListStemcellsReturns(api.ProductStemcells{
+			Products: []api.ProductStemcell{{ProductName: "cf", StagedStemcellVersion: "621.70", AvailableVersions: []string{"621.70", "621.77"}}},
+		}, nil)
+
+		err := newConverge().Execute([]string{"--config", manifest})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(ContainElement("assign-stemcell --product cf --stemcell 621.77"))
+		Expect(executed).ToNot(ContainElement(HavePrefix("upload-stemcell")))
+	})
+
+	It("passes the vars on to the commands that take a config", func() {
+		environFunc = func() []string { return []string{"OM_VAR_cf_version=2.10.3"} }
+		Expect(ioutil.WriteFile(filepath.Join(dir, "cf-vars.yml"), []byte(`{product-name: cf, product-properties: {.properties.foo: {value: ((foo))}}}`), 0600)).To(Succeed())
+		writeManifest(`
+products:
+- name: cf
+  version: ((cf_version))
+  file: /products/cf.pivotal
+  config: cf-vars.yml
+`)
+
+		err := newConverge().Execute([]string{"--config", manifest, "--vars-env", "OM_VAR", "--var", "foo=bar"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(Equal([]string{
+			"upload-product --product /products/cf.pivotal --product-version 2.10.3",
+			"stage-product --product-name cf --product-version 2.10.3",
+			"configure-product --config " + filepath.Join(dir, "cf-vars.yml") + " --vars-env OM_VAR --var foo=bar",
+		}))
+	})
+
+	It("interpolates each config with its own vars file, before the vars flags", func() {
+		stageEverything()
+		service.GetStagedProductJobResourceConfigReturns(api.JobProperties{
+			"instances":     2,
+			"instance_type": map[string]interface{}{"id": "automatic"},
+		}, nil)
+
+		Expect(ioutil.WriteFile(filepath.Join(dir, "cf.yml"), []byte(`{product-name: cf, product-properties: {.properties.secret: {value: {secret: ((properties_secret.secret))}}}}`), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "cf-vars.yml"), []byte(`{properties_secret: {secret: some-secret}}`), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "director-vars.yml"), []byte(`{properties_secret: {secret: some-director-secret}}`), 0600)).To(Succeed())
+		writeManifest(`
+director:
+  config: director.yml
+  vars-file: director-vars.yml
+products:
+- name: cf
+  version: 2.10.3
+  config: cf.yml
+  vars-file: cf-vars.yml
+  stemcell:
+    version: "621.77"
+`)
+
+		err := newConverge().Execute([]string{"--config", manifest, "--vars-file", filepath.Join(dir, "cf-vars.yml")})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(Equal([]string{
+			"configure-director --config " + filepath.Join(dir, "director.yml") + " --vars-file " + filepath.Join(dir, "director-vars.yml") + " --vars-file " + filepath.Join(dir, "cf-vars.yml"),
+		}))
+		Expect(stdout).To(gbytes.Say(`cf is already configured`))
+	})
+
+	It("downloads products that have a download instead of a file", func() {
+		downloadDir := filepath.Join(dir, "downloads")
+		Expect(os.Mkdir(downloadDir, 0700)).To(Succeed())
+
+		commandSet["download-product"] = recordingCommand{
+			name:     "download-product",
+			executed: &executed,
+			execute: func([]string) error {
+				return ioutil.WriteFile(filepath.Join(downloadDir, "download-file.json"), []byte(`{
+					"product_path": "/downloads/cf-2.10.3.pivotal",
+					"stemcell_path": "/downloads/stemcell-621.77.tgz",
+					"stemcell_version": "621.77"
+				}`), 0600)
+			},
+		}
+
+		writeManifest(`
+products:
+- name: cf
+  version: 2.10.3
+  download: download-cf.yml
+`)
+
+		err := newConverge().Execute([]string{"--config", manifest, "--download-directory", downloadDir})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(Equal([]string{
+			"download-product --config " + filepath.Join(dir, "download-cf.yml") + " --output-directory " + downloadDir,
+			"upload-product --product /downloads/cf-2.10.3.pivotal --product-version 2.10.3",
+			"stage-product --product-name cf --product-version 2.10.3",
+			"upload-stemcell --stemcell /downloads/stemcell-621.77.tgz --floating false",
+			"assign-stemcell --product cf --stemcell 621.77",
+		}))
+	})
+
+	Describe("--apply-changes", func() {
+		BeforeEach(func() {
+			service.CheckProductAvailabilityReturns(true, nil)
+			service.GetDiagnosticReportReturns(api.DiagnosticReport{
+				StagedProducts: []api.DiagnosticProduct{{Name: "cf", Version: "2.10.3"}, {Name: "mysql", Version: "2.9.0"}},
+			}, nil)
+			service.ListStemcellsReturns(api.ProductStemcells{
+				Products: []api.ProductStemcell{{ProductName: "cf", StagedStemcellVersion: "621.77"}},
+			}, nil)
+			service.ListStagedProductsReturns(api.StagedProductsOutput{
+				Products: []api.StagedProduct{
+					{GUID: "p-bosh-guid", Type: "p-bosh"},
+					{GUID: "cf-guid", Type: "cf"},
+					{GUID: "other-guid", Type: "other"},
+				},
+			}, nil)
+		})
+
+		It("applies changes to the products of the manifest that changed", func() {
+			service.ListStagedPendingChangesReturns(api.PendingChangesOutput{
+				ChangeList: []api.ProductChange{
+					{GUID: "p-bosh-guid", Action: "update"},
+					{GUID: "cf-guid", Action: "update"},
+					{GUID: "other-guid", Action: "install"},
+				},
+			}, nil)
+
+			err := newConverge().Execute([]string{"--config", manifest, "--apply-changes"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executed[len(executed)-1]).To(Equal("apply-changes --product-name cf"))
+			Expect(stdout).To(gbytes.Say("leaving the pending changes of other-guid alone, as it is not in the foundation manifest"))
+		})
+
+		It("only updates the director when no product changed", func() {
+			service.ListStagedPendingChangesReturns(api.PendingChangesOutput{
+				ChangeList: []api.ProductChange{
+					{GUID: "p-bosh-guid", Action: "update"},
+					{GUID: "cf-guid", Action: "unchanged"},
+				},
+			}, nil)
+
+			err := newConverge().Execute([]string{"--config", manifest, "--apply-changes"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executed[len(executed)-1]).To(Equal("apply-changes --skip-deploy-products"))
+		})
+
+		It("does not apply changes when nothing changed", func() {
+			service.ListStagedPendingChangesReturns(api.PendingChangesOutput{
+				ChangeList: []api.ProductChange{
+					{GUID: "p-bosh-guid", Action: "unchanged"},
+					{GUID: "cf-guid", Action: "unchanged"},
+				},
+			}, nil)
+
+			err := newConverge().Execute([]string{"--config", manifest, "--apply-changes"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(executed).ToNot(ContainElement(HavePrefix("apply-changes")))
+			Expect(stdout).To(gbytes.Say("there are no changes to apply"))
+		})
+	})
+
+	It("only prints the steps with --dry-run", func() {
+		err := newConverge().Execute([]string{"--config", manifest, "--dry-run", "--apply-changes"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(BeEmpty())
+		Expect(stdout).To(gbytes.Say(`would configure the director: om configure-director --config .*director.yml`))
+		Expect(stdout).To(gbytes.Say(`would upload cf 2.10.3: om upload-product --product .*cf-2.10.3.pivotal --product-version 2.10.3`))
+		Expect(stdout).To(gbytes.Say(`would stage cf 2.10.3: om stage-product --product-name cf --product-version 2.10.3`))
+		Expect(stdout).To(gbytes.Say(`would upload stemcell 621.77: om upload-stemcell --stemcell /stemcells/stemcell-621.77.tgz --floating false`))
+		Expect(stdout).To(gbytes.Say(`would assign stemcell 621.77 to cf: om assign-stemcell --product cf --stemcell 621.77`))
+		Expect(stdout).To(gbytes.Say(`would configure cf: om configure-product --config .*cf.yml`))
+		Expect(stdout).To(gbytes.Say(`would apply changes to the products with pending changes`))
+	})
+
+	It("does not print the configure steps of the configs that are already staged with --dry-run", func() {
+		stageEverything()
+
+		err := newConverge().Execute([]string{"--config", manifest, "--dry-run"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(executed).To(BeEmpty())
+		Expect(stdout).To(gbytes.Say(`the director is already configured`))
+		Expect(stdout).To(gbytes.Say(`cf is already configured`))
+		Expect(stdout).ToNot(gbytes.Say(`would configure`))
+	})
+
+	Context("failure cases", func() {
+		When("a step fails", func() {
+			It("stops and returns the error", func() {
+				commandSet["stage-product"] = recordingCommand{
+					name:     "stage-product",
+					executed: &executed,
+					execute:  func([]string) error { return errors.New("some error") },
+				}
+
+				err := newConverge().Execute([]string{"--config", manifest})
+				Expect(err).To(MatchError(`could not stage cf 2.10.3: could not execute "stage-product": some error`))
+				Expect(executed[len(executed)-1]).To(HavePrefix("stage-product"))
+			})
+		})
+
+		When("a product is not uploaded and has no source", func() {
+			It("returns an error", func() {
+				writeManifest(`{products: [{name: cf, version: 2.10.3}]}`)
+
+				err := newConverge().Execute([]string{"--config", manifest})
+				Expect(err).To(MatchError("cf 2.10.3 is not uploaded, and the foundation manifest has neither a file nor a download for it"))
+			})
+		})
+
+		When("a stemcell is not uploaded and has no file", func() {
+			It("returns an error", func() {
+				writeManifest(`{products: [{name: cf, version: 2.10.3, file: cf.pivotal, stemcell: {version: "621.77"}}]}`)
+
+				err := newConverge().Execute([]string{"--config", manifest})
+				Expect(err).To(MatchError("stemcell 621.77 for cf is not uploaded, and the foundation manifest has no file for it"))
+			})
+		})
+
+		When("the manifest is invalid", func() {
+			DescribeTable("returns an error",
+				func(contents, message string) {
+					writeManifest(contents)
+
+					err := newConverge().Execute([]string{"--config", manifest})
+					Expect(err).To(MatchError(ContainSubstring(message)))
+				},
+				Entry("without a version", `{products: [{name: cf}]}`, "every product in the foundation manifest requires a name and a version"),
+				Entry("with a duplicate product", `{products: [{name: cf, version: "1"}, {name: cf, version: "2"}]}`, `product "cf" is listed more than once`),
+				Entry("with a file and a download", `{products: [{name: cf, version: "1", file: a, download: b}]}`, `product "cf" can have either a file or a download, not both`),
+				Entry("with an unknown key", `{products: [{name: cf, version: "1", tile: a}]}`, "could not parse the foundation manifest"),
+				Entry("with a missing variable", `{products: [{name: cf, version: ((version))}]}`, "could not load the foundation manifest"),
+			)
+		})
+
+		When("the staged config cannot be compared", func() {
+			It("returns an error", func() {
+				service.GetStagedDirectorPropertiesReturns(nil, errors.New("some error"))
+
+				err := newConverge().Execute([]string{"--config", manifest})
+				Expect(err).To(MatchError("could not compare the config of the director with its staged config: could not get the staged properties-configuration of the director: some error"))
+				Expect(executed).To(BeEmpty())
+			})
+		})
+
+		When("checking the uploaded products fails", func() {
+			It("returns an error", func() {
+				service.CheckProductAvailabilityReturns(false, errors.New("some error"))
+
+				err := newConverge().Execute([]string{"--config", manifest})
+				Expect(err).To(MatchError("could not check whether cf 2.10.3 is uploaded: some error"))
+			})
+		})
+
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				err := newConverge().Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse converge flags: flag provided but not defined: -badflag"))
+			})
+		})
+	})
+})
@@ -14,7 +14,7 @@ type CreateCertificateAuthority struct {
 	Options   struct {
 		CertPem    string `long:"certificate-pem" required:"true" description:"certificate"`
 		PrivateKey string `long:"private-key-pem" required:"true" description:"private key"`
-		Format     string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format     string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -40,7 +40,10 @@ func (c CreateCertificateAuthority) Execute(args []string) error {
 		return err
 	}
 
-	c.presenter.SetFormat(c.Options.Format)
+	err = c.presenter.SetFormat(c.Options.Format)
+	if err != nil {
+		return err
+	}
 	c.presenter.PresentCertificateAuthority(ca)
 
 	return nil
@@ -17,10 +17,11 @@ type createVMExtensionService interface {
 }
 
 type CreateVMExtension struct {
-	environFunc func() []string
-	service     createVMExtensionService
-	logger      logger
-	Options     struct {
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	service          createVMExtensionService
+	logger           logger
+	Options          struct {
 		Name string `long:"name"               short:"n"   description:"VM extension name"`
 
 		interpolateConfigFileOptions
@@ -29,11 +30,12 @@ type CreateVMExtension struct {
 	}
 }
 
-func NewCreateVMExtension(environFunc func() []string, service createVMExtensionService, logger logger) CreateVMExtension {
+func NewCreateVMExtension(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, service createVMExtensionService, logger logger) CreateVMExtension {
 	return CreateVMExtension{
-		environFunc: environFunc,
-		service:     service,
-		logger:      logger,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		logger:           logger,
 	}
 }
 
@@ -49,13 +51,14 @@ func (c CreateVMExtension) Execute(args []string) error {
 	if c.Options.ConfigFile != "" {
 		var cfg config.VMExtensionConfig
 		configContents, err := interpolate.Execute(interpolate.Options{
-			TemplateFile:  c.Options.ConfigFile,
-			VarsFiles:     c.Options.VarsFile,
-			EnvironFunc:   c.environFunc,
-			VarsEnvs:      c.Options.VarsEnv,
-			Vars:          c.Options.Vars,
-			OpsFiles:      c.Options.OpsFile,
-			ExpectAllKeys: true,
+			TemplateFile:     c.Options.ConfigFile,
+			VarsFiles:        c.Options.VarsFile,
+			EnvironFunc:      c.environFunc,
+			VarsEnvs:         c.Options.VarsEnv,
+			Vars:             c.Options.Vars,
+			OpsFiles:         c.Options.OpsFile,
+			VarsSourceConfig: c.varsSourceConfig,
+			ExpectAllKeys:    true,
 		})
 		if err != nil {
 			return err
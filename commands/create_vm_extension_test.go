@@ -12,6 +12,7 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 )
 
 const ymlVMExtensionFile = `---
@@ -42,7 +43,7 @@ var _ = Describe("CreateVMExtension", func() {
 	BeforeEach(func() {
 		fakeService = &fakes.CreateVMExtensionService{}
 		fakeLogger = &fakes.Logger{}
-		command = commands.NewCreateVMExtension(func() []string { return nil }, fakeService, fakeLogger)
+		command = commands.NewCreateVMExtension(func() []string { return nil }, interpolate.VarsSourceConfig{}, fakeService, fakeLogger)
 	})
 
 	AfterEach(func() {
@@ -138,6 +139,7 @@ var _ = Describe("CreateVMExtension", func() {
 				It("makes a request to the OpsMan to create a VM extension", func() {
 					command = commands.NewCreateVMExtension(
 						func() []string { return []string{"OM_VAR_vm_extension_name=some-vm-extension"} },
+						interpolate.VarsSourceConfig{},
 						fakeService,
 						fakeLogger)
 					configFile, err = ioutil.TempFile("", "")
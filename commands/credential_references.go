@@ -15,7 +15,7 @@ type CredentialReferences struct {
 	logger    logger
 	Options   struct {
 		Product string `long:"product-name" short:"p" required:"true" description:"name of deployed product"`
-		Format  string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format  string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -61,7 +61,10 @@ func (cr CredentialReferences) Execute(args []string) error {
 		return nil
 	}
 
-	cr.presenter.SetFormat(cr.Options.Format)
+	err = cr.presenter.SetFormat(cr.Options.Format)
+	if err != nil {
+		return err
+	}
 	cr.presenter.PresentCredentialReferences(output.Credentials)
 
 	return nil
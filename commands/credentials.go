@@ -16,7 +16,7 @@ type Credentials struct {
 		Product             string `long:"product-name"         short:"p" required:"true" description:"name of deployed product"`
 		CredentialReference string `long:"credential-reference" short:"c" required:"true" description:"name of credential reference"`
 		CredentialField     string `long:"credential-field"     short:"f"                 description:"single credential field to output"`
-		Format              string `long:"format"               short:"t" default:"table" description:"Format to print as (options: table,json)"`
+		Format              string `long:"format"               short:"t" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -64,7 +64,10 @@ func (cs Credentials) Execute(args []string) error {
 	}
 
 	if cs.Options.CredentialField == "" {
-		cs.presenter.SetFormat(cs.Options.Format)
+		err = cs.presenter.SetFormat(cs.Options.Format)
+		if err != nil {
+			return err
+		}
 		cs.presenter.PresentCredentials(output.Credential.Value)
 	} else {
 		if value, ok := output.Credential.Value[cs.Options.CredentialField]; ok {
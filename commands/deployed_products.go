@@ -12,7 +12,7 @@ type DeployedProducts struct {
 	presenter presenters.FormattedPresenter
 	service   deployedProductsService
 	Options   struct {
-		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -40,7 +40,10 @@ func (dp DeployedProducts) Execute(args []string) error {
 
 	deployedProducts := diagnosticReport.DeployedProducts
 
-	dp.presenter.SetFormat(dp.Options.Format)
+	err = dp.presenter.SetFormat(dp.Options.Format)
+	if err != nil {
+		return err
+	}
 	dp.presenter.PresentDeployedProducts(deployedProducts)
 
 	return nil
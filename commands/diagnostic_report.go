@@ -36,7 +36,10 @@ func (dr DiagnosticReport) Execute(args []string) error {
 		return fmt.Errorf("failed to retrieve diagnostic-report %s", err)
 	}
 
-	dr.presenter.SetFormat("json")
+	err = dr.presenter.SetFormat("json")
+	if err != nil {
+		return err
+	}
 	dr.presenter.PresentDiagnosticReport(diagnosticReport)
 
 	return nil
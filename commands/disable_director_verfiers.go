@@ -5,15 +5,17 @@ import (
 	"fmt"
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/interpolate"
 	"github.com/pivotal-cf/om/presenters"
 	"os"
 )
 
 type DisableDirectorVerifiers struct {
-	service   disableDirectorVerifiersService
-	presenter presenters.FormattedPresenter
-	logger    logger
-	Options   struct {
+	varsSourceConfig interpolate.VarsSourceConfig
+	service          disableDirectorVerifiersService
+	presenter        presenters.FormattedPresenter
+	logger           logger
+	Options          struct {
 		interpolateConfigFileOptions
 
 		VerifierTypes []string `long:"type" short:"t"  description:"verifier types to disable" required:"true"`
@@ -26,16 +28,17 @@ type disableDirectorVerifiersService interface {
 	DisableDirectorVerifiers(verifierTypes []string) error
 }
 
-func NewDisableDirectorVerifiers(presenter presenters.FormattedPresenter, service disableDirectorVerifiersService, logger logger) DisableDirectorVerifiers {
+func NewDisableDirectorVerifiers(varsSourceConfig interpolate.VarsSourceConfig, presenter presenters.FormattedPresenter, service disableDirectorVerifiersService, logger logger) DisableDirectorVerifiers {
 	return DisableDirectorVerifiers{
-		service:   service,
-		presenter: presenter,
-		logger:    logger,
+		varsSourceConfig: varsSourceConfig,
+		service:          service,
+		presenter:        presenter,
+		logger:           logger,
 	}
 }
 
 func (dv DisableDirectorVerifiers) Execute(args []string) error {
-	err := loadConfigFile(args, &dv.Options, os.Environ)
+	err := loadConfigFile(args, &dv.Options, os.Environ, dv.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse disable-director-verifiers flags: %s", err)
 	}
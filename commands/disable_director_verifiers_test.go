@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/interpolate"
 	"io/ioutil"
 	"log"
 	"os"
@@ -30,7 +31,7 @@ var _ = Describe("DisableDirectorVerifiers", func() {
 		service = &fakes.DisableDirectorVerifiersService{}
 		stderr = gbytes.NewBuffer()
 		logger = log.New(stderr, "", 0)
-		command = commands.NewDisableDirectorVerifiers(presenter, service, logger)
+		command = commands.NewDisableDirectorVerifiers(interpolate.VarsSourceConfig{}, presenter, service, logger)
 	})
 
 	When("all provided verifiers exist", func() {
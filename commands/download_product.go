@@ -6,6 +6,7 @@ import (
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/download_clients"
 	"github.com/pivotal-cf/om/extractor"
+	"github.com/pivotal-cf/om/interpolate"
 	"github.com/pivotal-cf/om/validator"
 	"io"
 	"io/ioutil"
@@ -13,6 +14,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
 type PivnetOptions struct {
@@ -45,6 +47,21 @@ type AzureOptions struct {
 	AzureKey            string `long:"azure-storage-key"     description:"the access key for the storage account"`
 }
 
+type LocalOptions struct {
+	LocalDirectory string `long:"local-directory" description:"directory, such as an NFS share, where the product and stemcell artifacts are stored"`
+}
+
+type HTTPOptions struct {
+	HTTPURL        string `long:"http-url"         description:"url of the http mirror, serving directory listings, where the product and stemcell artifacts are stored"`
+	HTTPDisableSSL bool   `long:"http-disable-ssl" description:"whether to disable ssl validation when contacting the http mirror"`
+}
+
+type OCIOptions struct {
+	OCIRegistry     string `long:"oci-registry"      description:"host (and port) of the oci registry where the product and stemcell artifacts are stored. Prefix with http:// for a registry without TLS"`
+	OCIDisableSSL   bool   `long:"oci-disable-ssl"   description:"whether to disable ssl validation when contacting the oci registry"`
+	OCIDockerConfig string `long:"oci-docker-config" env:"DOCKER_CONFIG" description:"directory containing the docker config.json with the registry credentials (defaults to ~/.docker)"`
+}
+
 type StemcellOptions struct {
 	StemcellIaas    string `long:"stemcell-iaas"     description:"download the latest available stemcell for the product for the specified iaas. for example 'vsphere' or 'vcloud' or 'openstack' or 'google' or 'azure' or 'aws'. Can contain globbing patterns to match specific files in a stemcell release on Pivnet"`
 	StemcellVersion string `long:"stemcell-version" description:"the version number of the stemcell to download (ie 458.61)"`
@@ -52,32 +69,37 @@ type StemcellOptions struct {
 }
 
 type DownloadProductOptions struct {
-	Source            string `long:"source"                     short:"s" description:"enables download from external sources when set to [s3|gcs|azure|pivnet]" default:"pivnet"`
+	Source            string `long:"source"                     short:"s" description:"enables download from external sources when set to [s3|gcs|azure|pivnet|local|http|oci]" default:"pivnet"`
 	OutputDir         string `long:"output-directory"           short:"o" description:"directory path to which the file will be outputted. File Name will be preserved from Pivotal Network" required:"true"`
 	StemcellOutputDir string `long:"stemcell-output-directory" short:"d" description:"directory path to which the stemcell file will be outputted. If not provided, output-directory will be used."`
 
 	Bucket               string `long:"blobstore-bucket"        alias:"s3-bucket,gcs-bucket,azure-container"                   description:"bucket name where the product resides in the s3|gcs|azure compatible blobstore"`
-	ProductPath          string `long:"blobstore-product-path"  alias:"s3-product-path,gcs-product-path,azure-product-path"    description:"specify the lookup path where the s3|gcs|azure product artifacts are stored"`
-	StemcellPath         string `long:"blobstore-stemcell-path" alias:"s3-stemcell-path,gcs-stemcell-path,azure-stemcell-path" description:"specify the lookup path where the s3|gcs|azure stemcell artifacts are stored"`
+	ProductPath          string `long:"blobstore-product-path"  alias:"s3-product-path,gcs-product-path,azure-product-path"    description:"specify the lookup path where the s3|gcs|azure|local|http product artifacts, or the oci repository prefix are stored"`
+	StemcellPath         string `long:"blobstore-stemcell-path" alias:"s3-stemcell-path,gcs-stemcell-path,azure-stemcell-path" description:"specify the lookup path where the s3|gcs|azure|local|http stemcell artifacts, or the oci repository prefix are stored"`
 	CacheCleanup         string `long:"cache-cleanup" env:"CACHE_CLEANUP" description:"Delete everything except the latest artifact in output-dir and stemcell-output-dir, set to 'I acknowledge this will delete files in the output directories' to accept these terms"`
 	CheckAlreadyUploaded bool   `long:"check-already-uploaded" description:"Check if product is already uploaded on Ops Manager before downloading. This command is authenticated."`
+	DownloadThreads      int    `long:"download-threads" description:"number of chunks to download in parallel from sources supporting ranged requests (pivnet, s3, azure, local, http, oci). Interrupted downloads of these sources are resumed when run again" default:"10"`
 
 	AzureOptions
 	GCSOptions
+	HTTPOptions
 	interpolateConfigFileOptions
+	LocalOptions
+	OCIOptions
 	PivnetOptions
 	S3Options
 	StemcellOptions
 }
 
 type DownloadProduct struct {
-	environFunc    func() []string
-	progressWriter io.Writer
-	stderr         *log.Logger
-	stdout         *log.Logger
-	service        downloadProductService
-	downloadClient download_clients.ProductDownloader
-	Options        DownloadProductOptions
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	progressWriter   io.Writer
+	stderr           *log.Logger
+	stdout           *log.Logger
+	service          downloadProductService
+	downloadClient   download_clients.ProductDownloader
+	Options          DownloadProductOptions
 }
 
 //counterfeiter:generate -o ./fakes/download_product_service.go --fake-name DownloadProductService . downloadProductService
@@ -86,13 +108,14 @@ type downloadProductService interface {
 	CheckStemcellAvailability(string) (bool, error)
 }
 
-func NewDownloadProduct(environFunc func() []string, stdout *log.Logger, stderr *log.Logger, progressWriter io.Writer, downloadProductService downloadProductService, ) *DownloadProduct {
+func NewDownloadProduct(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, stdout *log.Logger, stderr *log.Logger, progressWriter io.Writer, downloadProductService downloadProductService) *DownloadProduct {
 	return &DownloadProduct{
-		environFunc:    environFunc,
-		stderr:         stderr,
-		stdout:         stdout,
-		progressWriter: progressWriter,
-		service:        downloadProductService,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		stderr:           stderr,
+		stdout:           stdout,
+		progressWriter:   progressWriter,
+		service:          downloadProductService,
 	}
 }
 
@@ -105,7 +128,7 @@ func (c DownloadProduct) Usage() jhanda.Usage {
 }
 
 func (c *DownloadProduct) Execute(args []string) error {
-	err := loadConfigFile(args, &c.Options, c.environFunc)
+	err := loadConfigFile(args, &c.Options, c.environFunc, c.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse download-product flags: %s", err)
 	}
@@ -228,7 +251,7 @@ func (c *DownloadProduct) determineProductVersion() (string, error) {
 func (c *DownloadProduct) createClient() error {
 	plugin, err := newDownloadClientFromSource(c.Options, c.progressWriter, c.stdout, c.stderr)
 	if err != nil {
-		return fmt.Errorf("could not find valid source for '%s'", c.Options.Source)
+		return fmt.Errorf("could not find valid source for '%s': %w", c.Options.Source, err)
 	}
 
 	c.downloadClient = plugin
@@ -420,14 +443,7 @@ func (c *DownloadProduct) downloadProductFile(slug, version, glob, prefixPath st
 	}
 
 	partialProductFilePath := productFilePath + ".partial"
-	// create a new file to download
-	productFile, err := os.Create(partialProductFilePath)
-	if err != nil {
-		return "", nil, fmt.Errorf("could not create file %s: %s", productFilePath, err)
-	}
-	defer productFile.Close()
-
-	err = c.downloadClient.DownloadProductToFile(fileArtifact, productFile)
+	err = c.downloadToFile(fileArtifact, productFilePath, partialProductFilePath)
 	if err != nil {
 		return productFilePath, fileArtifact, err
 	}
@@ -448,6 +464,29 @@ func (c *DownloadProduct) downloadProductFile(slug, version, glob, prefixPath st
 	return productFilePath, fileArtifact, nil
 }
 
+// downloadToFile downloads in ranges when the source supports it,
+// resuming from what a previous attempt left in the partial file.
+func (c *DownloadProduct) downloadToFile(fileArtifact download_clients.FileArtifacter, productFilePath, partialProductFilePath string) error {
+	if rangeDownloader, ok := c.downloadClient.(download_clients.RangeDownloader); ok {
+		err := download_clients.DownloadInRanges(rangeDownloader, fileArtifact, partialProductFilePath, download_clients.RangedDownloadOptions{
+			Threads:   c.Options.DownloadThreads,
+			ChunkSize: download_clients.DefaultChunkSize,
+		}, c.stderr)
+		if err != download_clients.ErrRangesNotSupported {
+			return err
+		}
+	}
+
+	// create a new file to download
+	productFile, err := os.Create(partialProductFilePath)
+	if err != nil {
+		return fmt.Errorf("could not create file %s: %s", productFilePath, err)
+	}
+	defer productFile.Close()
+
+	return c.downloadClient.DownloadProductToFile(fileArtifact, productFile)
+}
+
 func (c *DownloadProduct) cleanupCacheArtifacts(outputDir string, glob string, productFilePath string, slug string) error {
 	if c.Options.CacheCleanup == "I acknowledge this will delete files in the output directories" {
 
@@ -464,7 +503,8 @@ func (c *DownloadProduct) cleanupCacheArtifacts(outputDir string, glob string, p
 				dirFilePath := path.Join(outputDir, file.Name())
 				c.stderr.Printf("checking if %q needs to cleaned up", file.Name())
 				if matchGlob, _ := filepath.Match(fileGlob, file.Name()); matchGlob {
-					if dirFilePath != productFilePath {
+					// a partial download is kept so it can be resumed
+					if dirFilePath != productFilePath && !strings.HasPrefix(dirFilePath, productFilePath+".partial") {
 						c.stderr.Printf("cleaning up cached file: %s", dirFilePath)
 						_ = os.Remove(dirFilePath)
 					}
@@ -558,6 +598,36 @@ func newDownloadClientFromSource(c DownloadProductOptions,
 			},
 			stderr,
 		)
+	case "local":
+		return download_clients.NewLocalClient(
+			download_clients.LocalConfiguration{
+				Directory:    c.LocalDirectory,
+				ProductPath:  c.ProductPath,
+				StemcellPath: c.StemcellPath,
+			},
+			stderr,
+		)
+	case "http":
+		return download_clients.NewHTTPClient(
+			download_clients.HTTPConfiguration{
+				URL:          c.HTTPURL,
+				ProductPath:  c.ProductPath,
+				StemcellPath: c.StemcellPath,
+				DisableSSL:   c.HTTPDisableSSL,
+			},
+			stderr,
+		)
+	case "oci":
+		return download_clients.NewOCIClient(
+			download_clients.OCIConfiguration{
+				Registry:     c.OCIRegistry,
+				ProductPath:  c.ProductPath,
+				StemcellPath: c.StemcellPath,
+				DisableSSL:   c.OCIDisableSSL,
+				DockerConfig: c.OCIDockerConfig,
+			},
+			stderr,
+		)
 	case "pivnet", "":
 		return download_clients.NewPivnetClient(
 			stdout,
@@ -2,14 +2,17 @@ package commands_test
 
 import (
 	"archive/zip"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"github.com/pivotal-cf/om/extractor"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/pivotal-cf/om/download_clients"
 
@@ -19,6 +22,7 @@ import (
 	"github.com/pivotal-cf/om/commands"
 	cmdFakes "github.com/pivotal-cf/om/commands/fakes"
 	"github.com/pivotal-cf/om/download_clients/fakes"
+	"github.com/pivotal-cf/om/interpolate"
 )
 
 var _ = Describe("DownloadProduct", func() {
@@ -43,7 +47,7 @@ var _ = Describe("DownloadProduct", func() {
 			return fakeProductDownloader
 		}
 		buffer = gbytes.NewBuffer()
-		command = commands.NewDownloadProduct(environFunc, log.New(buffer, "", 0), log.New(buffer, "", 0), buffer, fakeDownloadProductService)
+		command = commands.NewDownloadProduct(environFunc, interpolate.VarsSourceConfig{}, log.New(buffer, "", 0), log.New(buffer, "", 0), buffer, fakeDownloadProductService)
 	})
 
 	When("the flags are set correctly", func() {
@@ -88,6 +92,80 @@ var _ = Describe("DownloadProduct", func() {
 			})
 		})
 
+		When("the source can download ranges", func() {
+			var fakeRangeDownloader *fakes.RangeDownloader
+
+			const contents = "some-product-contents"
+
+			BeforeEach(func() {
+				fa := &fakes.FileArtifacter{}
+				fa.NameReturns("/some-account/some-bucket/cf-2.0-build.1.pivotal")
+				fa.SHA256Returns(fmt.Sprintf("%x", sha256.Sum256([]byte(contents))))
+				fakeProductDownloader.GetLatestProductFileReturns(fa, nil)
+
+				fakeRangeDownloader = &fakes.RangeDownloader{}
+				fakeRangeDownloader.ProductFileSizeReturns(int64(len(contents)), nil)
+				fakeRangeDownloader.DownloadProductRangeStub = func(_ download_clients.FileArtifacter, start, end int64) (io.ReadCloser, error) {
+					return ioutil.NopCloser(strings.NewReader(contents[start : end+1])), nil
+				}
+			})
+
+			JustBeforeEach(func() {
+				download_clients.NewPivnetClient = func(stdout *log.Logger, stderr *log.Logger, factory download_clients.PivnetFactory, token string, skipSSL bool, pivnetHost string) download_clients.ProductDownloader {
+					return struct {
+						*fakes.ProductDownloader
+						*fakes.RangeDownloader
+					}{fakeProductDownloader, fakeRangeDownloader}
+				}
+			})
+
+			It("downloads the file in ranges and verifies its sha", func() {
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version", "2.0.0",
+					"--output-directory", tempDir,
+					"--download-threads", "2",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(0))
+				Expect(fakeRangeDownloader.DownloadProductRangeCallCount()).To(Equal(1))
+
+				written, err := ioutil.ReadFile(filepath.Join(tempDir, "cf-2.0-build.1.pivotal"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(string(written)).To(Equal(contents))
+				Expect(filepath.Join(tempDir, "cf-2.0-build.1.pivotal.partial.state")).ToNot(BeAnExistingFile())
+			})
+
+			It("falls back to a single download when the file cannot be downloaded in ranges", func() {
+				fakeRangeDownloader.ProductFileSizeReturns(0, download_clients.ErrRangesNotSupported)
+				fakeProductDownloader.DownloadProductToFileStub = func(_ download_clients.FileArtifacter, file *os.File) error {
+					_, err := file.WriteString(contents)
+					return err
+				}
+
+				tempDir, err := ioutil.TempDir("", "om-tests-")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Execute([]string{
+					"--pivnet-api-token", "token",
+					"--file-glob", "*.pivotal",
+					"--pivnet-product-slug", "elastic-runtime",
+					"--product-version", "2.0.0",
+					"--output-directory", tempDir,
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeProductDownloader.DownloadProductToFileCallCount()).To(Equal(1))
+				Expect(filepath.Join(tempDir, "cf-2.0-build.1.pivotal")).To(BeAnExistingFile())
+			})
+		})
+
 		When("a valid product-version-regex is provided", func() {
 			BeforeEach(func() {
 				fakeProductDownloader.GetAllProductVersionsReturns(
@@ -1217,6 +1295,73 @@ output-directory: %s
 		})
 	})
 
+	When("the source is a local directory", func() {
+		It("downloads the latest matching product from the directory", func() {
+			sourceDir, err := ioutil.TempDir("", "om-tests-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(sourceDir)
+
+			Expect(os.Mkdir(filepath.Join(sourceDir, "products"), 0755)).To(Succeed())
+			for _, version := range []string{"2.0.0", "2.1.0", "3.0.0"} {
+				name := filepath.Join(sourceDir, "products", fmt.Sprintf("[mayhem-crew,%s]my-great-product.pivotal", version))
+				Expect(ioutil.WriteFile(name, []byte("contents of "+version), 0644)).To(Succeed())
+			}
+
+			tempDir, err := ioutil.TempDir("", "om-tests-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tempDir)
+
+			err = command.Execute([]string{
+				"--source", "local",
+				"--local-directory", sourceDir,
+				"--blobstore-product-path", "products",
+				"--file-glob", "*.pivotal",
+				"--pivnet-product-slug", "mayhem-crew",
+				"--product-version-regex", `^2\..*$`,
+				"--output-directory", tempDir,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := ioutil.ReadFile(filepath.Join(tempDir, "[mayhem-crew,2.1.0]my-great-product.pivotal"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("contents of 2.1.0"))
+		})
+
+		It("errors when the directory is not provided", func() {
+			tempDir, err := ioutil.TempDir("", "om-tests-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tempDir)
+
+			err = command.Execute([]string{
+				"--source", "local",
+				"--file-glob", "*.pivotal",
+				"--pivnet-product-slug", "mayhem-crew",
+				"--product-version", "2.0.0",
+				"--output-directory", tempDir,
+			})
+			Expect(err).To(MatchError(ContainSubstring("could not find valid source for 'local'")))
+			Expect(err).To(MatchError(ContainSubstring("'Directory' failed on the 'required' tag")))
+		})
+	})
+
+	When("the source is an oci registry", func() {
+		It("errors when the registry is not provided", func() {
+			tempDir, err := ioutil.TempDir("", "om-tests-")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.RemoveAll(tempDir)
+
+			err = command.Execute([]string{
+				"--source", "oci",
+				"--file-glob", "*.pivotal",
+				"--pivnet-product-slug", "mayhem-crew",
+				"--product-version", "2.0.0",
+				"--output-directory", tempDir,
+			})
+			Expect(err).To(MatchError(ContainSubstring("could not find valid source for 'oci'")))
+			Expect(err).To(MatchError(ContainSubstring("'Registry' failed on the 'required' tag")))
+		})
+	})
+
 	When("--stemcell-version flag is provided, but --stemcell-iaas is missing", func() {
 		It("returns an error", func() {
 			tempDir, err := ioutil.TempDir("", "om-tests-")
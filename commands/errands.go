@@ -21,7 +21,7 @@ type Errands struct {
 	service   errandsService
 	Options   struct {
 		ProductName string `long:"product-name" short:"p" required:"true" description:"name of product"`
-		Format      string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format      string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -56,7 +56,10 @@ func (e Errands) Execute(args []string) error {
 		})
 	}
 
-	e.presenter.SetFormat(e.Options.Format)
+	err = e.presenter.SetFormat(e.Options.Format)
+	if err != nil {
+		return err
+	}
 	e.presenter.PresentErrands(errands)
 
 	return nil
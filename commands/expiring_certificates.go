@@ -1,14 +1,15 @@
 package commands
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/fatih/color"
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/metrics"
+	"github.com/pivotal-cf/om/presenters"
 	"regexp"
-	"strings"
-	"time"
 )
 
 //counterfeiter:generate -o ./fakes/expiring_certs_service.go --fake-name ExpiringCertsService . expiringCertsService
@@ -17,17 +18,20 @@ type expiringCertsService interface {
 }
 
 type ExpiringCerts struct {
-	logger  logger
-	api     expiringCertsService
-	Options struct {
+	logger    logger
+	api       expiringCertsService
+	presenter presenters.FormattedPresenter
+	Options   struct {
 		ExpiresWithin string `long:"expires-within"  short:"e"  description:"timeframe in which to check expiration. Default: \"3m\".\n\t\t\t\tdays(d), weeks(w), months(m) and years(y) supported."`
+		Format        string `long:"format"          short:"f"  alias:"output" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>,openmetrics)"`
 	}
 }
 
-func NewExpiringCertificates(service expiringCertsService, logger logger) *ExpiringCerts {
+func NewExpiringCertificates(service expiringCertsService, presenter presenters.FormattedPresenter, logger logger) *ExpiringCerts {
 	return &ExpiringCerts{
-		api:    service,
-		logger: logger,
+		api:       service,
+		presenter: presenter,
+		logger:    logger,
 	}
 }
 
@@ -48,84 +52,65 @@ func (e *ExpiringCerts) Execute(args []string) error {
 		e.Options.ExpiresWithin = "3m"
 	}
 
-	err := e.validateConfig()
+	err := validateExpiresWithin(e.Options.ExpiresWithin)
 	if err != nil {
 		return err
 	}
 
-	e.logger.Println("Getting expiring certificates...")
-	expiringCerts, err := e.api.ListExpiringCertificates(e.Options.ExpiresWithin)
-	if err != nil {
-		return fmt.Errorf("could not fetch expiring certificates: %s", err)
+	if e.Options.Format == "openmetrics" {
+		return e.writeMetrics()
 	}
 
-	if len(expiringCerts) == 0 {
-		e.logger.Printf(color.GreenString("[✓] No certificates are expiring in %s\n"), e.Options.ExpiresWithin)
-		return nil
+	err = e.presenter.SetFormat(e.Options.Format)
+	if err != nil {
+		return err
 	}
 
-	e.logger.Println(color.RedString("Found expiring certificates in the foundation:\n"))
+	// progress is only printed next to the table, so other formats can be parsed
+	table := e.Options.Format == "table"
 
-	expiringCertsWithVariablePath, expiringCertsWithProductGUID := e.groupByLocation(expiringCerts)
-	for location, certs := range expiringCertsWithVariablePath {
-		e.logger.Printf(color.RedString("[X] %s", location))
-
-		for _, cert := range certs {
-			e.printExpiringCertInfo(cert)
-		}
+	if table {
+		e.logger.Println("Getting expiring certificates...")
 	}
-
-	for location, productGUIDs := range expiringCertsWithProductGUID {
-		e.logger.Printf(color.RedString("[X] %s", location))
-		for guid, certs := range productGUIDs {
-			e.logger.Printf(color.RedString("    %s:", guid))
-			for _, cert := range certs {
-				e.printExpiringCertInfo(cert)
-			}
-		}
+	expiringCerts, err := e.api.ListExpiringCertificates(e.Options.ExpiresWithin)
+	if err != nil {
+		return fmt.Errorf("could not fetch expiring certificates: %s", err)
 	}
 
-	return errors.New("found expiring certificates in the foundation")
-}
-
-func (e *ExpiringCerts) groupByLocation(certs []api.ExpiringCertificate) (map[string][]api.ExpiringCertificate, map[string]map[string][]api.ExpiringCertificate) {
-	expiringCertsWithVariablePath := make(map[string][]api.ExpiringCertificate)
-	expiringCertsWithProductGUID := make(map[string]map[string][]api.ExpiringCertificate)
-	for _, cert := range certs {
-		location := strings.Title(strings.Replace(cert.Location, "_", " ", -1))
-		if cert.VariablePath != "" {
-			expiringCertsWithVariablePath[location] = append(expiringCertsWithVariablePath[location], cert)
-			continue
-		}
+	if len(expiringCerts) == 0 && table {
+		e.logger.Printf(color.GreenString("[✓] No certificates are expiring in %s\n"), e.Options.ExpiresWithin)
+		return nil
+	}
 
-		if expiringCertsWithProductGUID[location] == nil {
-			expiringCertsWithProductGUID[location] = make(map[string][]api.ExpiringCertificate)
-		}
+	e.presenter.PresentExpiringCertificates(expiringCerts)
 
-		expiringCertsWithProductGUID[location][cert.ProductGUID] = append(expiringCertsWithProductGUID[location][cert.ProductGUID], cert)
+	if len(expiringCerts) > 0 {
+		return errors.New("found expiring certificates in the foundation")
 	}
 
-	return expiringCertsWithVariablePath, expiringCertsWithProductGUID
+	return nil
 }
 
-func (e *ExpiringCerts) printExpiringCertInfo(cert api.ExpiringCertificate) {
-	expiringStr := "expiring"
-	if time.Now().After(cert.ValidUntil) {
-		expiringStr = "expired"
+// writeMetrics does not fail when certificates are expiring:
+// the metrics are meant to be alerted on by the monitoring system.
+func (e ExpiringCerts) writeMetrics() error {
+	expiringCerts, err := e.api.ListExpiringCertificates(e.Options.ExpiresWithin)
+	if err != nil {
+		return fmt.Errorf("could not fetch expiring certificates: %s", err)
 	}
 
-	validUntil := cert.ValidUntil.Format(time.RFC822)
-
-	if cert.VariablePath != "" {
-		e.logger.Printf(color.RedString("    %s: %s on %s"), cert.VariablePath, expiringStr, validUntil)
-		return
+	var output bytes.Buffer
+	err = metrics.Write(&output, metrics.CertificateExpiry(expiringCerts))
+	if err != nil {
+		return err
 	}
 
-	e.logger.Printf(color.RedString("        %s: %s on %s"), cert.PropertyReference, expiringStr, validUntil)
+	e.logger.Print(output.String())
+	return nil
 }
 
-func (e ExpiringCerts) validateConfig() error {
-	matched, err := regexp.MatchString("^[1-9]+\\d*[dwmy]$", e.Options.ExpiresWithin)
+func validateExpiresWithin(expiresWithin string) error {
+	matched, err := regexp.MatchString("^[1-9]+\\d*[dwmy]$", expiresWithin)
 	if err != nil {
 		return err
 	}
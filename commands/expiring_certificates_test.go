@@ -1,15 +1,18 @@
 package commands_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
 	"regexp"
 	"time"
 
+	"github.com/olekukonko/tablewriter"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/presenters"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -18,20 +21,22 @@ import (
 
 var _ = Describe("ExpiringCertificates", func() {
 	var (
-		service *fakes.ExpiringCertsService
-		stdout  *gbytes.Buffer
-		logger  *log.Logger
+		service   *fakes.ExpiringCertsService
+		stdout    *gbytes.Buffer
+		logger    *log.Logger
+		presenter *presenters.MultiPresenter
 	)
 
 	BeforeEach(func() {
 		service = &fakes.ExpiringCertsService{}
 		stdout = gbytes.NewBuffer()
 		logger = log.New(stdout, "", 0)
+		presenter = presenters.NewPresenter(presenters.NewTablePresenter(tablewriter.NewWriter(stdout), stdout), presenters.NewJSONPresenter(stdout))
 	})
 
 	When("there are no expiring certificates in the time range", func() {
 		It("displays a helpful message", func() {
-			command := commands.NewExpiringCertificates(service, logger)
+			command := commands.NewExpiringCertificates(service, presenter, logger)
 			err := command.Execute([]string{})
 			Expect(err).ToNot(HaveOccurred())
 
@@ -40,7 +45,7 @@ var _ = Describe("ExpiringCertificates", func() {
 		})
 
 		It("sets ExpiresWithin when passed", func() {
-			command := commands.NewExpiringCertificates(service, logger)
+			command := commands.NewExpiringCertificates(service, presenter, logger)
 			err := command.Execute([]string{
 				"--expires-within",
 				"5w",
@@ -136,7 +141,7 @@ var _ = Describe("ExpiringCertificates", func() {
 					},
 				}, nil
 			}
-			command := commands.NewExpiringCertificates(service, logger)
+			command := commands.NewExpiringCertificates(service, presenter, logger)
 			err = command.Execute([]string{})
 			Expect(err).To(HaveOccurred())
 
@@ -158,7 +163,7 @@ var _ = Describe("ExpiringCertificates", func() {
 		})
 
 		It("sets ExpiresWithin to 3m as default", func() {
-			command := commands.NewExpiringCertificates(service, logger)
+			command := commands.NewExpiringCertificates(service, presenter, logger)
 			err := command.Execute([]string{})
 			Expect(err).ToNot(HaveOccurred())
 
@@ -166,7 +171,7 @@ var _ = Describe("ExpiringCertificates", func() {
 		})
 
 		It("sets ExpiresWithin when passed", func() {
-			command := commands.NewExpiringCertificates(service, logger)
+			command := commands.NewExpiringCertificates(service, presenter, logger)
 			err := command.Execute([]string{
 				"--expires-within",
 				"5w",
@@ -177,14 +182,14 @@ var _ = Describe("ExpiringCertificates", func() {
 		})
 
 		It("validates the ExpiresWithin value as d,w,m,or y when passed", func() {
-			command := commands.NewExpiringCertificates(service, logger)
+			command := commands.NewExpiringCertificates(service, presenter, logger)
 			err := command.Execute([]string{
 				"--expires-within",
 				"1s",
 			})
 			Expect(err).To(MatchError(ContainSubstring("only d,w,m, or y are supported. Default is \"3m\"")))
 
-			command = commands.NewExpiringCertificates(service, logger)
+			command = commands.NewExpiringCertificates(service, presenter, logger)
 			err = command.Execute([]string{
 				"--expires-within",
 				"0d",
@@ -217,10 +222,68 @@ var _ = Describe("ExpiringCertificates", func() {
 		})
 	})
 
+	When("a format other than table is requested", func() {
+		It("prints only the certificates, in that format", func() {
+			validUntil := time.Date(2999, 1, 1, 1, 1, 1, 0, time.UTC)
+			service.ListExpiringCertificatesReturns([]api.ExpiringCertificate{{
+				ValidUntil:   validUntil,
+				Location:     "credhub",
+				VariablePath: "/opsmgr/bosh_dns/tls_ca",
+			}}, nil)
+
+			command := commands.NewExpiringCertificates(service, presenter, logger)
+			err := command.Execute([]string{"--format", "json"})
+			Expect(err).To(MatchError("found expiring certificates in the foundation"))
+
+			var certificates []api.ExpiringCertificate
+			Expect(json.Unmarshal(stdout.Contents(), &certificates)).To(Succeed())
+			Expect(certificates).To(HaveLen(1))
+			Expect(certificates[0].VariablePath).To(Equal("/opsmgr/bosh_dns/tls_ca"))
+		})
+
+		It("prints an empty list when no certificates are expiring", func() {
+			command := commands.NewExpiringCertificates(service, presenter, logger)
+			err := command.Execute([]string{"--format", "json"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(string(stdout.Contents())).To(MatchJSON(`null`))
+		})
+
+		It("errors on an unknown format before fetching the certificates", func() {
+			command := commands.NewExpiringCertificates(service, presenter, logger)
+			err := command.Execute([]string{"--format", "xml"})
+			Expect(err).To(MatchError(`unknown format "xml", the supported formats are: json, table`))
+			Expect(service.ListExpiringCertificatesCallCount()).To(Equal(0))
+		})
+	})
+
+	When("openmetrics output is requested", func() {
+		It("prints the expiry of every certificate without failing", func() {
+			service.ListExpiringCertificatesReturns([]api.ExpiringCertificate{{
+				Issuer:       "/services/tls_ca",
+				ValidUntil:   time.Unix(1800000000, 0),
+				Location:     "credhub",
+				VariablePath: "/opsmgr/bosh_dns/tls_ca",
+			}}, nil)
+
+			command := commands.NewExpiringCertificates(service, presenter, logger)
+			err := command.Execute([]string{"--output", "openmetrics", "--expires-within", "1y"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(service.ListExpiringCertificatesArgsForCall(0)).To(Equal("1y"))
+			Expect(string(stdout.Contents())).To(Equal(`# TYPE om_certificate_expiry_timestamp_seconds gauge
+# UNIT om_certificate_expiry_timestamp_seconds seconds
+# HELP om_certificate_expiry_timestamp_seconds Time the certificate expires at, in seconds since the Unix epoch.
+om_certificate_expiry_timestamp_seconds{product="",location="credhub",variable_path="/opsmgr/bosh_dns/tls_ca",property_reference="",issuer="/services/tls_ca"} 1800000000
+# EOF
+`))
+		})
+	})
+
 	When("certs cannot be fetched", func() {
 		It("returns an error", func() {
 			service.ListExpiringCertificatesReturns(nil, errors.New("an api error"))
-			command := commands.NewExpiringCertificates(service, logger)
+			command := commands.NewExpiringCertificates(service, presenter, logger)
 
 			err := command.Execute([]string{})
 			Expect(err).To(MatchError(ContainSubstring("could not fetch expiring certificates: an api error")))
@@ -0,0 +1,202 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"gopkg.in/yaml.v2"
+)
+
+type ExportFoundationConfig struct {
+	service exportFoundationConfigService
+	logger  logger
+	Options struct {
+		OutputDirectory string `long:"output-directory" short:"o" required:"true" description:"directory to write the configuration to (created if it does not exist)"`
+	}
+}
+
+//counterfeiter:generate -o ./fakes/export_foundation_config_service.go --fake-name ExportFoundationConfigService . exportFoundationConfigService
+type exportFoundationConfigService interface {
+	stagedConfigService
+	stagedDirectorConfigService
+
+	GetDiagnosticReport() (api.DiagnosticReport, error)
+	ListStagedProducts() (api.StagedProductsOutput, error)
+}
+
+var placeholderRegex = regexp.MustCompile(`\(\(([-\w.]+)\)\)`)
+
+func NewExportFoundationConfig(service exportFoundationConfigService, logger logger) ExportFoundationConfig {
+	return ExportFoundationConfig{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (efc ExportFoundationConfig) Execute(args []string) error {
+	if _, err := jhanda.Parse(&efc.Options, args); err != nil {
+		return fmt.Errorf("could not parse export-foundation-config flags: %s", err)
+	}
+
+	stagedProducts, err := efc.service.ListStagedProducts()
+	if err != nil {
+		return fmt.Errorf("could not list the staged products: %s", err)
+	}
+
+	report, err := efc.service.GetDiagnosticReport()
+	if err != nil {
+		return fmt.Errorf("could not determine the staged product versions: %s", err)
+	}
+
+	versions := map[string]string{}
+	for _, product := range report.StagedProducts {
+		versions[product.Name] = product.Version
+	}
+
+	err = os.MkdirAll(filepath.Join(efc.Options.OutputDirectory, "products"), 0755)
+	if err != nil {
+		return fmt.Errorf("could not create the output directory: %s", err)
+	}
+
+	directorConfig, err := efc.capture(func(stdout logger) error {
+		command := NewStagedDirectorConfig(efc.service, stdout, log.New(ioutil.Discard, "", 0))
+		return command.Execute([]string{"--include-placeholders"})
+	})
+	if err != nil {
+		return fmt.Errorf("could not export the director config: %s", err)
+	}
+
+	varsFile, err := efc.writeConfig("director.yml", directorConfig)
+	if err != nil {
+		return err
+	}
+
+	manifest := foundationManifest{
+		Director: &foundationDirector{Config: "director.yml", VarsFile: varsFile},
+	}
+
+	for _, product := range stagedProducts.Products {
+		if product.Type == "p-bosh" {
+			continue
+		}
+
+		productConfig, err := efc.capture(func(stdout logger) error {
+			command := NewStagedConfig(efc.service, stdout)
+			return command.Execute([]string{"--product-name", product.Type, "--include-placeholders"})
+		})
+		if err != nil {
+			return fmt.Errorf("could not export the config of %s: %s", product.Type, err)
+		}
+
+		configFile := filepath.Join("products", product.Type+".yml")
+		varsFile, err := efc.writeConfig(configFile, productConfig)
+		if err != nil {
+			return err
+		}
+
+		manifest.Products = append(manifest.Products, foundationProduct{
+			Name:     product.Type,
+			Version:  versions[product.Type],
+			Config:   configFile,
+			VarsFile: varsFile,
+		})
+	}
+
+	sort.Slice(manifest.Products, func(i, j int) bool {
+		return manifest.Products[i].Name < manifest.Products[j].Name
+	})
+
+	contents, err := yaml.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("could not marshal the foundation manifest: %s", err) // un-tested
+	}
+
+	return efc.write("foundation.yml", contents)
+}
+
+// capture runs a command that prints its config, and returns what it printed.
+func (efc ExportFoundationConfig) capture(run func(stdout logger) error) ([]byte, error) {
+	var output bytes.Buffer
+
+	err := run(log.New(&output, "", 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(bytes.TrimSpace(output.Bytes()), '\n'), nil
+}
+
+// writeConfig writes a config, and next to it a vars file template
+// for its placeholders, named after the config. Every config has its
+// own vars file, so that products with properties of the same name
+// do not share their credentials. It returns the name of the vars file,
+// or nothing when the config has no placeholders.
+func (efc ExportFoundationConfig) writeConfig(name string, contents []byte) (string, error) {
+	err := efc.write(name, contents)
+	if err != nil {
+		return "", err
+	}
+
+	vars := map[interface{}]interface{}{}
+	for _, match := range placeholderRegex.FindAllSubmatch(contents, -1) {
+		// placeholders like ((name.secret)) refer to a key of the name variable
+		keys := strings.Split(string(match[1]), ".")
+
+		parent := vars
+		for _, key := range keys[:len(keys)-1] {
+			child, ok := parent[key].(map[interface{}]interface{})
+			if !ok {
+				child = map[interface{}]interface{}{}
+				parent[key] = child
+			}
+			parent = child
+		}
+
+		if _, ok := parent[keys[len(keys)-1]]; !ok {
+			parent[keys[len(keys)-1]] = ""
+		}
+	}
+
+	if len(vars) == 0 {
+		return "", nil
+	}
+
+	varsContents, err := yaml.Marshal(vars)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal the vars file of %s: %s", name, err) // un-tested
+	}
+
+	varsFile := strings.TrimSuffix(name, filepath.Ext(name)) + "-vars.yml"
+	return varsFile, efc.write(varsFile, varsContents)
+}
+
+// write writes a file to the output directory.
+func (efc ExportFoundationConfig) write(name string, contents []byte) error {
+	path := filepath.Join(efc.Options.OutputDirectory, name)
+
+	err := ioutil.WriteFile(path, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write %s: %s", path, err)
+	}
+
+	efc.logger.Printf("exported %s", path)
+
+	return nil
+}
+
+func (efc ExportFoundationConfig) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command exports the config of the staged director and of every staged product to a directory, along with a vars file template for the credentials of each config and a foundation manifest. The configs contain placeholders instead of credentials, and can be passed in to om configure-director and om configure-product, or to om converge with the foundation manifest.",
+		ShortDescription: "exports the config of the director and every staged product",
+		Flags:            efc.Options,
+	}
+}
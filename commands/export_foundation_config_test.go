@@ -0,0 +1,181 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExportFoundationConfig", func() {
+	var (
+		service   *fakes.ExportFoundationConfigService
+		stdout    *gbytes.Buffer
+		outputDir string
+	)
+
+	readFile := func(name string) string {
+		contents, err := ioutil.ReadFile(filepath.Join(outputDir, name))
+		Expect(err).ToNot(HaveOccurred())
+		return string(contents)
+	}
+
+	BeforeEach(func() {
+		service = &fakes.ExportFoundationConfigService{}
+		stdout = gbytes.NewBuffer()
+
+		dir, err := ioutil.TempDir("", "export-foundation-config")
+		Expect(err).ToNot(HaveOccurred())
+		outputDir = filepath.Join(dir, "foundation")
+
+		service.ListStagedProductsReturns(api.StagedProductsOutput{
+			Products: []api.StagedProduct{
+				{GUID: "p-bosh-guid", Type: "p-bosh"},
+				{GUID: "mysql-guid", Type: "mysql"},
+				{GUID: "cf-guid", Type: "cf"},
+			},
+		}, nil)
+		service.GetDiagnosticReportReturns(api.DiagnosticReport{
+			StagedProducts: []api.DiagnosticProduct{
+				{Name: "p-bosh", Version: "2.10.0"},
+				{Name: "cf", Version: "2.10.3"},
+				{Name: "mysql", Version: "2.9.0"},
+			},
+		}, nil)
+
+		service.GetStagedProductByNameStub = func(name string) (api.StagedProductsFindOutput, error) {
+			return api.StagedProductsFindOutput{Product: api.StagedProduct{GUID: name + "-guid", Type: name}}, nil
+		}
+
+		service.GetStagedDirectorPropertiesReturns(map[string]interface{}{
+			"director_configuration": map[string]interface{}{"ntp_servers_string": "ntp.example.com"},
+		}, nil)
+		service.GetStagedDirectorIaasConfigurationsReturns(map[string][]map[string]interface{}{
+			"iaas_configurations": {{"guid": "some-guid", "name": "default"}},
+		}, nil)
+
+		service.GetStagedProductPropertiesStub = func(guid string, redact bool) (map[string]api.ResponseProperty, error) {
+			return map[string]api.ResponseProperty{
+				".properties.name": {Value: guid, Type: "string", Configurable: true},
+				".properties.secret": {
+					Value:        map[string]interface{}{"secret": "***"},
+					Type:         "secret",
+					Configurable: true,
+					IsCredential: true,
+				},
+			}, nil
+		}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(filepath.Dir(outputDir))).To(Succeed())
+	})
+
+	It("writes the director config, every product config with its own vars file template and a foundation manifest", func() {
+		command := commands.NewExportFoundationConfig(service, log.New(stdout, "", 0))
+		err := command.Execute([]string{"--output-directory", outputDir})
+		Expect(err).ToNot(HaveOccurred())
+
+		director := readFile("director.yml")
+		Expect(director).To(ContainSubstring("ntp_servers_string: ntp.example.com"))
+		Expect(director).To(ContainSubstring("name: ((iaas-configurations_0_name))"))
+
+		Expect(readFile("products/cf.yml")).To(MatchYAML(`
+product-name: cf
+product-properties:
+  .properties.name:
+    value: cf-guid
+  .properties.secret:
+    value:
+      secret: ((properties_secret.secret))
+`))
+		Expect(readFile("products/mysql.yml")).To(ContainSubstring("product-name: mysql"))
+
+		Expect(readFile("director-vars.yml")).To(MatchYAML(`
+iaas-configurations_0_name: ""
+`))
+		Expect(readFile("products/cf-vars.yml")).To(MatchYAML(`
+properties_secret:
+  secret: ""
+`))
+		Expect(readFile("products/mysql-vars.yml")).To(MatchYAML(`
+properties_secret:
+  secret: ""
+`))
+
+		Expect(readFile("foundation.yml")).To(MatchYAML(`
+director:
+  config: director.yml
+  vars-file: director-vars.yml
+products:
+- name: cf
+  version: 2.10.3
+  config: products/cf.yml
+  vars-file: products/cf-vars.yml
+- name: mysql
+  version: 2.9.0
+  config: products/mysql.yml
+  vars-file: products/mysql-vars.yml
+`))
+
+		_, redact := service.GetStagedProductPropertiesArgsForCall(0)
+		Expect(redact).To(BeTrue())
+
+		Expect(stdout).To(gbytes.Say(`exported .*director.yml`))
+		Expect(stdout).To(gbytes.Say(`exported .*director-vars.yml`))
+		Expect(stdout).To(gbytes.Say(`exported .*products/mysql.yml`))
+		Expect(stdout).To(gbytes.Say(`exported .*products/mysql-vars.yml`))
+		Expect(stdout).To(gbytes.Say(`exported .*products/cf.yml`))
+		Expect(stdout).To(gbytes.Say(`exported .*products/cf-vars.yml`))
+		Expect(stdout).To(gbytes.Say(`exported .*foundation.yml`))
+	})
+
+	Context("failure cases", func() {
+		When("the staged products cannot be listed", func() {
+			It("returns an error", func() {
+				service.ListStagedProductsReturns(api.StagedProductsOutput{}, errors.New("some error"))
+
+				command := commands.NewExportFoundationConfig(service, log.New(stdout, "", 0))
+				err := command.Execute([]string{"--output-directory", outputDir})
+				Expect(err).To(MatchError("could not list the staged products: some error"))
+			})
+		})
+
+		When("the config of a product cannot be exported", func() {
+			It("returns an error", func() {
+				service.ListStagedProductJobsReturnsOnCall(1, nil, errors.New("some error"))
+
+				command := commands.NewExportFoundationConfig(service, log.New(stdout, "", 0))
+				err := command.Execute([]string{"--output-directory", outputDir})
+				Expect(err).To(MatchError("could not export the config of mysql: some error"))
+			})
+		})
+
+		When("the output directory cannot be created", func() {
+			It("returns an error", func() {
+				Expect(ioutil.WriteFile(filepath.Join(filepath.Dir(outputDir), "file"), nil, 0600)).To(Succeed())
+
+				command := commands.NewExportFoundationConfig(service, log.New(stdout, "", 0))
+				err := command.Execute([]string{"--output-directory", filepath.Join(filepath.Dir(outputDir), "file")})
+				Expect(err).To(MatchError(ContainSubstring("could not create the output directory")))
+			})
+		})
+
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				command := commands.NewExportFoundationConfig(service, log.New(stdout, "", 0))
+				err := command.Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse export-foundation-config flags: flag provided but not defined: -badflag"))
+			})
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/metrics"
+)
+
+//counterfeiter:generate -o ./fakes/exporter_service.go --fake-name ExporterService . exporterService
+
+type exporterService interface {
+	ListExpiringCertificates(string) ([]api.ExpiringCertificate, error)
+	ListInstallations() ([]api.InstallationsServiceOutput, error)
+	ListStagedPendingChanges() (api.PendingChangesOutput, error)
+}
+
+type Exporter struct {
+	service        exporterService
+	logger         logger
+	listenAndServe func(addr string, handler http.Handler) error
+	Options        struct {
+		Address       string `long:"address"        short:"a" default:"127.0.0.1:9478" description:"address to serve the /metrics endpoint on"`
+		ExpiresWithin string `long:"expires-within" short:"e" default:"1y"             description:"export the certificates expiring within this timeframe.\n\t\t\t\tdays(d), weeks(w), months(m) and years(y) supported."`
+	}
+}
+
+func NewExporter(service exporterService, logger logger, listenAndServe func(addr string, handler http.Handler) error) *Exporter {
+	return &Exporter{
+		service:        service,
+		logger:         logger,
+		listenAndServe: listenAndServe,
+	}
+}
+
+func (e *Exporter) Execute(args []string) error {
+	if _, err := jhanda.Parse(&e.Options, args); err != nil {
+		return fmt.Errorf("could not parse exporter flags: %s", err)
+	}
+
+	err := validateExpiresWithin(e.Options.ExpiresWithin)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", e.serveMetrics)
+
+	e.logger.Printf("serving metrics on http://%s/metrics", e.Options.Address)
+
+	return e.listenAndServe(e.Options.Address, mux)
+}
+
+// serveMetrics collects the metrics from Ops Manager on every scrape.
+// Failures are logged and reported with om_up,
+// so the metrics that could be collected are still served.
+func (e *Exporter) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	var families []metrics.Family
+	up := true
+
+	certificates, err := e.service.ListExpiringCertificates(e.Options.ExpiresWithin)
+	if err != nil {
+		e.logger.Printf("could not fetch expiring certificates: %s", err)
+		up = false
+	} else {
+		families = append(families, metrics.CertificateExpiry(certificates))
+	}
+
+	pendingChanges, err := e.service.ListStagedPendingChanges()
+	if err != nil {
+		e.logger.Printf("could not fetch pending changes: %s", err)
+		up = false
+	} else {
+		families = append(families, metrics.PendingChanges(pendingChanges))
+	}
+
+	installations, err := e.service.ListInstallations()
+	if err != nil {
+		e.logger.Printf("could not fetch installations: %s", err)
+		up = false
+	} else {
+		families = append(families, metrics.LastInstallation(installations)...)
+	}
+
+	families = append(families, metrics.Up(up))
+
+	var body bytes.Buffer
+	err = metrics.Write(&body, families...)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	_, _ = w.Write(body.Bytes())
+}
+
+func (e *Exporter) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This long-running command serves the expiry of the certificates, the number of pending changes and the status of the last installation of the targeted Ops Manager on a /metrics endpoint, in the OpenMetrics format. Ops Manager is queried on every scrape.",
+		ShortDescription: "serves Ops Manager metrics for Prometheus",
+		Flags:            e.Options,
+	}
+}
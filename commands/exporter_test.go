@@ -0,0 +1,127 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("Exporter", func() {
+	var (
+		fakeService    *fakes.ExporterService
+		stdout         *gbytes.Buffer
+		command        *commands.Exporter
+		addr           string
+		handler        http.Handler
+		listenAndServe func(string, http.Handler) error
+	)
+
+	BeforeEach(func() {
+		fakeService = &fakes.ExporterService{}
+		stdout = gbytes.NewBuffer()
+
+		addr, handler = "", nil
+		listenAndServe = func(a string, h http.Handler) error {
+			addr, handler = a, h
+			return nil
+		}
+
+		command = commands.NewExporter(fakeService, log.New(stdout, "", 0), listenAndServe)
+	})
+
+	scrape := func() (*http.Response, string) {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/metrics")
+		Expect(err).ToNot(HaveOccurred())
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		Expect(err).ToNot(HaveOccurred())
+
+		return resp, string(body)
+	}
+
+	It("serves the metrics of Ops Manager on every scrape", func() {
+		fakeService.ListExpiringCertificatesReturns([]api.ExpiringCertificate{
+			{
+				Issuer:       "/services/tls_ca",
+				ValidUntil:   time.Unix(1800000000, 0),
+				Location:     "credhub",
+				VariablePath: "/opsmgr/bosh_dns/tls_ca",
+			},
+		}, nil)
+		fakeService.ListStagedPendingChangesReturns(api.PendingChangesOutput{
+			ChangeList: []api.ProductChange{{GUID: "cf-guid", Action: "install"}},
+		}, nil)
+		fakeService.ListInstallationsReturns([]api.InstallationsServiceOutput{{ID: 1, Status: "running"}}, nil)
+
+		err := command.Execute([]string{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(addr).To(Equal("127.0.0.1:9478"))
+		Expect(stdout).To(gbytes.Say(`serving metrics on http://127.0.0.1:9478/metrics`))
+		Expect(fakeService.ListExpiringCertificatesCallCount()).To(Equal(0))
+
+		resp, body := scrape()
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		Expect(resp.Header.Get("Content-Type")).To(Equal("application/openmetrics-text; version=1.0.0; charset=utf-8"))
+
+		Expect(body).To(ContainSubstring(`om_certificate_expiry_timestamp_seconds{product="",location="credhub",variable_path="/opsmgr/bosh_dns/tls_ca",property_reference="",issuer="/services/tls_ca"} 1800000000`))
+		Expect(body).To(ContainSubstring(`om_pending_changes{action="install"} 1`))
+		Expect(body).To(ContainSubstring(`om_last_installation_status{om_last_installation_status="running"} 1`))
+		Expect(body).To(HaveSuffix("om_up 1\n# EOF\n"))
+
+		Expect(fakeService.ListExpiringCertificatesArgsForCall(0)).To(Equal("1y"))
+
+		scrape()
+		Expect(fakeService.ListExpiringCertificatesCallCount()).To(Equal(2))
+	})
+
+	It("serves the metrics it could collect when Ops Manager fails", func() {
+		fakeService.ListStagedPendingChangesReturns(api.PendingChangesOutput{}, errors.New("boom"))
+
+		err := command.Execute([]string{"--address", "0.0.0.0:9999", "--expires-within", "2m"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addr).To(Equal("0.0.0.0:9999"))
+
+		_, body := scrape()
+		Expect(body).To(ContainSubstring("# TYPE om_certificate_expiry_timestamp_seconds gauge"))
+		Expect(body).ToNot(ContainSubstring("om_pending_changes"))
+		Expect(body).To(HaveSuffix("om_up 0\n# EOF\n"))
+		Expect(stdout).To(gbytes.Say("could not fetch pending changes: boom"))
+
+		Expect(fakeService.ListExpiringCertificatesArgsForCall(0)).To(Equal("2m"))
+	})
+
+	It("returns an error for an invalid timeframe", func() {
+		err := command.Execute([]string{"--expires-within", "3h"})
+		Expect(err).To(MatchError(`only d,w,m, or y are supported. Default is "3m"`))
+		Expect(addr).To(BeEmpty())
+	})
+
+	It("returns the error of the server", func() {
+		command = commands.NewExporter(fakeService, log.New(stdout, "", 0), func(string, http.Handler) error {
+			return errors.New("address already in use")
+		})
+
+		err := command.Execute([]string{})
+		Expect(err).To(MatchError("address already in use"))
+	})
+
+	It("returns an error for an unknown flag", func() {
+		err := command.Execute([]string{"--port", "9090"})
+		Expect(err).To(MatchError("could not parse exporter flags: flag provided but not defined: -port"))
+	})
+})
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/fakeopsman"
+)
+
+type FakeOpsman struct {
+	logger         logger
+	listenAndServe func(addr string, handler http.Handler) error
+	Options        struct {
+		Address string `long:"address" short:"a" default:"127.0.0.1:8080" description:"address for the fake Ops Manager to listen on"`
+		Config  string `long:"config"  short:"c"                          description:"path to a YAML file with the credentials, products and installation script to start with (defaults to a single example product)"`
+	}
+}
+
+func NewFakeOpsman(logger logger, listenAndServe func(addr string, handler http.Handler) error) FakeOpsman {
+	return FakeOpsman{
+		logger:         logger,
+		listenAndServe: listenAndServe,
+	}
+}
+
+func (f FakeOpsman) Execute(args []string) error {
+	if _, err := jhanda.Parse(&f.Options, args); err != nil {
+		return fmt.Errorf("could not parse fake-opsman flags: %s", err)
+	}
+
+	config := fakeopsman.DefaultConfig()
+	if f.Options.Config != "" {
+		var err error
+		config, err = fakeopsman.LoadConfig(f.Options.Config)
+		if err != nil {
+			return err
+		}
+	}
+
+	server, err := fakeopsman.New(config)
+	if err != nil {
+		return fmt.Errorf("could not start fake ops manager: %s", err)
+	}
+
+	f.logger.Printf("fake Ops Manager %s listening on http://%s", config.Version, f.Options.Address)
+	f.logger.Printf("authenticate with --username %q --password %q or --client-id %q --client-secret %q", config.Username, config.Password, config.ClientID, config.ClientSecret)
+
+	return f.listenAndServe(f.Options.Address, server)
+}
+
+// Usage has no ShortDescription, which keeps the command out of `om help`:
+// it is a testing aid rather than part of the interface to Ops Manager.
+func (f FakeOpsman) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description: "This command serves an in-memory, stateful fake of the Ops Manager API on localhost, for rehearsing pipelines (e.g. configure-product, apply-changes and staged-config) without a real foundation. Installations replay a scripted log and deploy the staged products when they succeed. All state is lost when the command exits.",
+		Flags:       f.Options,
+	}
+}
@@ -0,0 +1,124 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/commands"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FakeOpsman", func() {
+	var (
+		stdout         *gbytes.Buffer
+		logger         *log.Logger
+		addr           string
+		handler        http.Handler
+		listenAndServe func(string, http.Handler) error
+	)
+
+	BeforeEach(func() {
+		stdout = gbytes.NewBuffer()
+		logger = log.New(stdout, "", 0)
+
+		addr, handler = "", nil
+		listenAndServe = func(a string, h http.Handler) error {
+			addr, handler = a, h
+			return nil
+		}
+	})
+
+	It("serves the default fake Ops Manager", func() {
+		command := commands.NewFakeOpsman(logger, listenAndServe)
+
+		err := command.Execute([]string{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(addr).To(Equal("127.0.0.1:8080"))
+		Expect(stdout).To(gbytes.Say(`fake Ops Manager 2.10.0-build.1 listening on http://127.0.0.1:8080`))
+		Expect(stdout).To(gbytes.Say(`authenticate with --username "admin" --password "password"`))
+
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/api/v0/info")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(resp.StatusCode).To(Equal(http.StatusOK))
+	})
+
+	It("seeds the fake from a config file", func() {
+		configFile, err := ioutil.TempFile("", "fake-opsman-config")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(configFile.Name())
+
+		_, err = configFile.WriteString(`
+version: 2.9.0-build.2
+username: some-user
+products:
+- name: cf
+  version: 2.9.0
+`)
+		Expect(err).ToNot(HaveOccurred())
+
+		command := commands.NewFakeOpsman(logger, listenAndServe)
+
+		err = command.Execute([]string{"--config", configFile.Name(), "--address", "127.0.0.1:9999"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(addr).To(Equal("127.0.0.1:9999"))
+		Expect(stdout).To(gbytes.Say(`fake Ops Manager 2.9.0-build.2 listening on http://127.0.0.1:9999`))
+		Expect(stdout).To(gbytes.Say(`authenticate with --username "some-user" --password "password"`))
+	})
+
+	It("returns the error of the server", func() {
+		command := commands.NewFakeOpsman(logger, func(string, http.Handler) error {
+			return errors.New("address already in use")
+		})
+
+		err := command.Execute([]string{})
+		Expect(err).To(MatchError("address already in use"))
+	})
+
+	Context("failure cases", func() {
+		When("the config file does not exist", func() {
+			It("returns an error", func() {
+				command := commands.NewFakeOpsman(logger, listenAndServe)
+
+				err := command.Execute([]string{"--config", "/does/not/exist.yml"})
+				Expect(err).To(MatchError(ContainSubstring("could not read fake ops manager config")))
+			})
+		})
+
+		When("the config is invalid", func() {
+			It("returns an error", func() {
+				configFile, err := ioutil.TempFile("", "fake-opsman-config")
+				Expect(err).ToNot(HaveOccurred())
+				defer os.Remove(configFile.Name())
+
+				_, err = configFile.WriteString(`{installation: {status: exploded}}`)
+				Expect(err).ToNot(HaveOccurred())
+
+				command := commands.NewFakeOpsman(logger, listenAndServe)
+
+				err = command.Execute([]string{"--config", configFile.Name()})
+				Expect(err).To(MatchError(`could not start fake ops manager: installation status must be 'succeeded' or 'failed', got "exploded"`))
+			})
+		})
+
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				command := commands.NewFakeOpsman(logger, listenAndServe)
+
+				err := command.Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse fake-opsman flags: flag provided but not defined: -badflag"))
+			})
+		})
+	})
+})
@@ -0,0 +1,258 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/om/api"
+)
+
+type CancelInstallationService struct {
+	CancelInstallationStub        func(int) error
+	cancelInstallationMutex       sync.RWMutex
+	cancelInstallationArgsForCall []struct {
+		arg1 int
+	}
+	cancelInstallationReturns struct {
+		result1 error
+	}
+	cancelInstallationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetInstallationStub        func(int) (api.InstallationsServiceOutput, error)
+	getInstallationMutex       sync.RWMutex
+	getInstallationArgsForCall []struct {
+		arg1 int
+	}
+	getInstallationReturns struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	getInstallationReturnsOnCall map[int]struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	RunningInstallationStub        func() (api.InstallationsServiceOutput, error)
+	runningInstallationMutex       sync.RWMutex
+	runningInstallationArgsForCall []struct {
+	}
+	runningInstallationReturns struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	runningInstallationReturnsOnCall map[int]struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *CancelInstallationService) CancelInstallation(arg1 int) error {
+	fake.cancelInstallationMutex.Lock()
+	ret, specificReturn := fake.cancelInstallationReturnsOnCall[len(fake.cancelInstallationArgsForCall)]
+	fake.cancelInstallationArgsForCall = append(fake.cancelInstallationArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.CancelInstallationStub
+	fakeReturns := fake.cancelInstallationReturns
+	fake.recordInvocation("CancelInstallation", []interface{}{arg1})
+	fake.cancelInstallationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *CancelInstallationService) CancelInstallationCallCount() int {
+	fake.cancelInstallationMutex.RLock()
+	defer fake.cancelInstallationMutex.RUnlock()
+	return len(fake.cancelInstallationArgsForCall)
+}
+
+func (fake *CancelInstallationService) CancelInstallationCalls(stub func(int) error) {
+	fake.cancelInstallationMutex.Lock()
+	defer fake.cancelInstallationMutex.Unlock()
+	fake.CancelInstallationStub = stub
+}
+
+func (fake *CancelInstallationService) CancelInstallationArgsForCall(i int) int {
+	fake.cancelInstallationMutex.RLock()
+	defer fake.cancelInstallationMutex.RUnlock()
+	argsForCall := fake.cancelInstallationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *CancelInstallationService) CancelInstallationReturns(result1 error) {
+	fake.cancelInstallationMutex.Lock()
+	defer fake.cancelInstallationMutex.Unlock()
+	fake.CancelInstallationStub = nil
+	fake.cancelInstallationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *CancelInstallationService) CancelInstallationReturnsOnCall(i int, result1 error) {
+	fake.cancelInstallationMutex.Lock()
+	defer fake.cancelInstallationMutex.Unlock()
+	fake.CancelInstallationStub = nil
+	if fake.cancelInstallationReturnsOnCall == nil {
+		fake.cancelInstallationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.cancelInstallationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *CancelInstallationService) GetInstallation(arg1 int) (api.InstallationsServiceOutput, error) {
+	fake.getInstallationMutex.Lock()
+	ret, specificReturn := fake.getInstallationReturnsOnCall[len(fake.getInstallationArgsForCall)]
+	fake.getInstallationArgsForCall = append(fake.getInstallationArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.GetInstallationStub
+	fakeReturns := fake.getInstallationReturns
+	fake.recordInvocation("GetInstallation", []interface{}{arg1})
+	fake.getInstallationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CancelInstallationService) GetInstallationCallCount() int {
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	return len(fake.getInstallationArgsForCall)
+}
+
+func (fake *CancelInstallationService) GetInstallationCalls(stub func(int) (api.InstallationsServiceOutput, error)) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = stub
+}
+
+func (fake *CancelInstallationService) GetInstallationArgsForCall(i int) int {
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	argsForCall := fake.getInstallationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *CancelInstallationService) GetInstallationReturns(result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = nil
+	fake.getInstallationReturns = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CancelInstallationService) GetInstallationReturnsOnCall(i int, result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = nil
+	if fake.getInstallationReturnsOnCall == nil {
+		fake.getInstallationReturnsOnCall = make(map[int]struct {
+			result1 api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.getInstallationReturnsOnCall[i] = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CancelInstallationService) RunningInstallation() (api.InstallationsServiceOutput, error) {
+	fake.runningInstallationMutex.Lock()
+	ret, specificReturn := fake.runningInstallationReturnsOnCall[len(fake.runningInstallationArgsForCall)]
+	fake.runningInstallationArgsForCall = append(fake.runningInstallationArgsForCall, struct {
+	}{})
+	stub := fake.RunningInstallationStub
+	fakeReturns := fake.runningInstallationReturns
+	fake.recordInvocation("RunningInstallation", []interface{}{})
+	fake.runningInstallationMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *CancelInstallationService) RunningInstallationCallCount() int {
+	fake.runningInstallationMutex.RLock()
+	defer fake.runningInstallationMutex.RUnlock()
+	return len(fake.runningInstallationArgsForCall)
+}
+
+func (fake *CancelInstallationService) RunningInstallationCalls(stub func() (api.InstallationsServiceOutput, error)) {
+	fake.runningInstallationMutex.Lock()
+	defer fake.runningInstallationMutex.Unlock()
+	fake.RunningInstallationStub = stub
+}
+
+func (fake *CancelInstallationService) RunningInstallationReturns(result1 api.InstallationsServiceOutput, result2 error) {
+	fake.runningInstallationMutex.Lock()
+	defer fake.runningInstallationMutex.Unlock()
+	fake.RunningInstallationStub = nil
+	fake.runningInstallationReturns = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CancelInstallationService) RunningInstallationReturnsOnCall(i int, result1 api.InstallationsServiceOutput, result2 error) {
+	fake.runningInstallationMutex.Lock()
+	defer fake.runningInstallationMutex.Unlock()
+	fake.RunningInstallationStub = nil
+	if fake.runningInstallationReturnsOnCall == nil {
+		fake.runningInstallationReturnsOnCall = make(map[int]struct {
+			result1 api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.runningInstallationReturnsOnCall[i] = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *CancelInstallationService) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.cancelInstallationMutex.RLock()
+	defer fake.cancelInstallationMutex.RUnlock()
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	fake.runningInstallationMutex.RLock()
+	defer fake.runningInstallationMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *CancelInstallationService) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
@@ -0,0 +1,1470 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/om/api"
+)
+
+type ConvergeService struct {
+	CheckProductAvailabilityStub        func(string, string) (bool, error)
+	checkProductAvailabilityMutex       sync.RWMutex
+	checkProductAvailabilityArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	checkProductAvailabilityReturns struct {
+		result1 bool
+		result2 error
+	}
+	checkProductAvailabilityReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	GetDiagnosticReportStub        func() (api.DiagnosticReport, error)
+	getDiagnosticReportMutex       sync.RWMutex
+	getDiagnosticReportArgsForCall []struct {
+	}
+	getDiagnosticReportReturns struct {
+		result1 api.DiagnosticReport
+		result2 error
+	}
+	getDiagnosticReportReturnsOnCall map[int]struct {
+		result1 api.DiagnosticReport
+		result2 error
+	}
+	GetStagedDirectorAvailabilityZonesStub        func() (api.AvailabilityZonesOutput, error)
+	getStagedDirectorAvailabilityZonesMutex       sync.RWMutex
+	getStagedDirectorAvailabilityZonesArgsForCall []struct {
+	}
+	getStagedDirectorAvailabilityZonesReturns struct {
+		result1 api.AvailabilityZonesOutput
+		result2 error
+	}
+	getStagedDirectorAvailabilityZonesReturnsOnCall map[int]struct {
+		result1 api.AvailabilityZonesOutput
+		result2 error
+	}
+	GetStagedDirectorIaasConfigurationsStub        func(bool) (map[string][]map[string]interface{}, error)
+	getStagedDirectorIaasConfigurationsMutex       sync.RWMutex
+	getStagedDirectorIaasConfigurationsArgsForCall []struct {
+		arg1 bool
+	}
+	getStagedDirectorIaasConfigurationsReturns struct {
+		result1 map[string][]map[string]interface{}
+		result2 error
+	}
+	getStagedDirectorIaasConfigurationsReturnsOnCall map[int]struct {
+		result1 map[string][]map[string]interface{}
+		result2 error
+	}
+	GetStagedDirectorNetworksStub        func() (api.NetworksConfigurationOutput, error)
+	getStagedDirectorNetworksMutex       sync.RWMutex
+	getStagedDirectorNetworksArgsForCall []struct {
+	}
+	getStagedDirectorNetworksReturns struct {
+		result1 api.NetworksConfigurationOutput
+		result2 error
+	}
+	getStagedDirectorNetworksReturnsOnCall map[int]struct {
+		result1 api.NetworksConfigurationOutput
+		result2 error
+	}
+	GetStagedDirectorPropertiesStub        func(bool) (map[string]interface{}, error)
+	getStagedDirectorPropertiesMutex       sync.RWMutex
+	getStagedDirectorPropertiesArgsForCall []struct {
+		arg1 bool
+	}
+	getStagedDirectorPropertiesReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedDirectorPropertiesReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	GetStagedProductByNameStub        func(string) (api.StagedProductsFindOutput, error)
+	getStagedProductByNameMutex       sync.RWMutex
+	getStagedProductByNameArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductByNameReturns struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}
+	getStagedProductByNameReturnsOnCall map[int]struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}
+	GetStagedProductJobMaxInFlightStub        func(string) (map[string]interface{}, error)
+	getStagedProductJobMaxInFlightMutex       sync.RWMutex
+	getStagedProductJobMaxInFlightArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductJobMaxInFlightReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedProductJobMaxInFlightReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	GetStagedProductJobResourceConfigStub        func(string, string) (api.JobProperties, error)
+	getStagedProductJobResourceConfigMutex       sync.RWMutex
+	getStagedProductJobResourceConfigArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	getStagedProductJobResourceConfigReturns struct {
+		result1 api.JobProperties
+		result2 error
+	}
+	getStagedProductJobResourceConfigReturnsOnCall map[int]struct {
+		result1 api.JobProperties
+		result2 error
+	}
+	GetStagedProductNetworksAndAZsStub        func(string) (map[string]interface{}, error)
+	getStagedProductNetworksAndAZsMutex       sync.RWMutex
+	getStagedProductNetworksAndAZsArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductNetworksAndAZsReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedProductNetworksAndAZsReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	GetStagedProductPropertiesStub        func(string, bool) (map[string]api.ResponseProperty, error)
+	getStagedProductPropertiesMutex       sync.RWMutex
+	getStagedProductPropertiesArgsForCall []struct {
+		arg1 string
+		arg2 bool
+	}
+	getStagedProductPropertiesReturns struct {
+		result1 map[string]api.ResponseProperty
+		result2 error
+	}
+	getStagedProductPropertiesReturnsOnCall map[int]struct {
+		result1 map[string]api.ResponseProperty
+		result2 error
+	}
+	GetStagedProductSyslogConfigurationStub        func(string) (map[string]interface{}, error)
+	getStagedProductSyslogConfigurationMutex       sync.RWMutex
+	getStagedProductSyslogConfigurationArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductSyslogConfigurationReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getStagedProductSyslogConfigurationReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	ListStagedPendingChangesStub        func() (api.PendingChangesOutput, error)
+	listStagedPendingChangesMutex       sync.RWMutex
+	listStagedPendingChangesArgsForCall []struct {
+	}
+	listStagedPendingChangesReturns struct {
+		result1 api.PendingChangesOutput
+		result2 error
+	}
+	listStagedPendingChangesReturnsOnCall map[int]struct {
+		result1 api.PendingChangesOutput
+		result2 error
+	}
+	ListStagedProductErrandsStub        func(string) (api.ErrandsListOutput, error)
+	listStagedProductErrandsMutex       sync.RWMutex
+	listStagedProductErrandsArgsForCall []struct {
+		arg1 string
+	}
+	listStagedProductErrandsReturns struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}
+	listStagedProductErrandsReturnsOnCall map[int]struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}
+	ListStagedProductJobsStub        func(string) (map[string]string, error)
+	listStagedProductJobsMutex       sync.RWMutex
+	listStagedProductJobsArgsForCall []struct {
+		arg1 string
+	}
+	listStagedProductJobsReturns struct {
+		result1 map[string]string
+		result2 error
+	}
+	listStagedProductJobsReturnsOnCall map[int]struct {
+		result1 map[string]string
+		result2 error
+	}
+	ListStagedProductsStub        func() (api.StagedProductsOutput, error)
+	listStagedProductsMutex       sync.RWMutex
+	listStagedProductsArgsForCall []struct {
+	}
+	listStagedProductsReturns struct {
+		result1 api.StagedProductsOutput
+		result2 error
+	}
+	listStagedProductsReturnsOnCall map[int]struct {
+		result1 api.StagedProductsOutput
+		result2 error
+	}
+	ListStagedVMExtensionsStub        func() ([]api.VMExtension, error)
+	listStagedVMExtensionsMutex       sync.RWMutex
+	listStagedVMExtensionsArgsForCall []struct {
+	}
+	listStagedVMExtensionsReturns struct {
+		result1 []api.VMExtension
+		result2 error
+	}
+	listStagedVMExtensionsReturnsOnCall map[int]struct {
+		result1 []api.VMExtension
+		result2 error
+	}
+	ListStemcellsStub        func() (api.ProductStemcells, error)
+	listStemcellsMutex       sync.RWMutex
+	listStemcellsArgsForCall []struct {
+	}
+	listStemcellsReturns struct {
+		result1 api.ProductStemcells
+		result2 error
+	}
+	listStemcellsReturnsOnCall map[int]struct {
+		result1 api.ProductStemcells
+		result2 error
+	}
+	ListVMTypesStub        func() ([]api.VMType, error)
+	listVMTypesMutex       sync.RWMutex
+	listVMTypesArgsForCall []struct {
+	}
+	listVMTypesReturns struct {
+		result1 []api.VMType
+		result2 error
+	}
+	listVMTypesReturnsOnCall map[int]struct {
+		result1 []api.VMType
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *ConvergeService) CheckProductAvailability(arg1 string, arg2 string) (bool, error) {
+	fake.checkProductAvailabilityMutex.Lock()
+	ret, specificReturn := fake.checkProductAvailabilityReturnsOnCall[len(fake.checkProductAvailabilityArgsForCall)]
+	fake.checkProductAvailabilityArgsForCall = append(fake.checkProductAvailabilityArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.CheckProductAvailabilityStub
+	fakeReturns := fake.checkProductAvailabilityReturns
+	fake.recordInvocation("CheckProductAvailability", []interface{}{arg1, arg2})
+	fake.checkProductAvailabilityMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) CheckProductAvailabilityCallCount() int {
+	fake.checkProductAvailabilityMutex.RLock()
+	defer fake.checkProductAvailabilityMutex.RUnlock()
+	return len(fake.checkProductAvailabilityArgsForCall)
+}
+
+func (fake *ConvergeService) CheckProductAvailabilityCalls(stub func(string, string) (bool, error)) {
+	fake.checkProductAvailabilityMutex.Lock()
+	defer fake.checkProductAvailabilityMutex.Unlock()
+	fake.CheckProductAvailabilityStub = stub
+}
+
+func (fake *ConvergeService) CheckProductAvailabilityArgsForCall(i int) (string, string) {
+	fake.checkProductAvailabilityMutex.RLock()
+	defer fake.checkProductAvailabilityMutex.RUnlock()
+	argsForCall := fake.checkProductAvailabilityArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *ConvergeService) CheckProductAvailabilityReturns(result1 bool, result2 error) {
+	fake.checkProductAvailabilityMutex.Lock()
+	defer fake.checkProductAvailabilityMutex.Unlock()
+	fake.CheckProductAvailabilityStub = nil
+	fake.checkProductAvailabilityReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) CheckProductAvailabilityReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.checkProductAvailabilityMutex.Lock()
+	defer fake.checkProductAvailabilityMutex.Unlock()
+	fake.CheckProductAvailabilityStub = nil
+	if fake.checkProductAvailabilityReturnsOnCall == nil {
+		fake.checkProductAvailabilityReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.checkProductAvailabilityReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetDiagnosticReport() (api.DiagnosticReport, error) {
+	fake.getDiagnosticReportMutex.Lock()
+	ret, specificReturn := fake.getDiagnosticReportReturnsOnCall[len(fake.getDiagnosticReportArgsForCall)]
+	fake.getDiagnosticReportArgsForCall = append(fake.getDiagnosticReportArgsForCall, struct {
+	}{})
+	stub := fake.GetDiagnosticReportStub
+	fakeReturns := fake.getDiagnosticReportReturns
+	fake.recordInvocation("GetDiagnosticReport", []interface{}{})
+	fake.getDiagnosticReportMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetDiagnosticReportCallCount() int {
+	fake.getDiagnosticReportMutex.RLock()
+	defer fake.getDiagnosticReportMutex.RUnlock()
+	return len(fake.getDiagnosticReportArgsForCall)
+}
+
+func (fake *ConvergeService) GetDiagnosticReportCalls(stub func() (api.DiagnosticReport, error)) {
+	fake.getDiagnosticReportMutex.Lock()
+	defer fake.getDiagnosticReportMutex.Unlock()
+	fake.GetDiagnosticReportStub = stub
+}
+
+func (fake *ConvergeService) GetDiagnosticReportReturns(result1 api.DiagnosticReport, result2 error) {
+	fake.getDiagnosticReportMutex.Lock()
+	defer fake.getDiagnosticReportMutex.Unlock()
+	fake.GetDiagnosticReportStub = nil
+	fake.getDiagnosticReportReturns = struct {
+		result1 api.DiagnosticReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetDiagnosticReportReturnsOnCall(i int, result1 api.DiagnosticReport, result2 error) {
+	fake.getDiagnosticReportMutex.Lock()
+	defer fake.getDiagnosticReportMutex.Unlock()
+	fake.GetDiagnosticReportStub = nil
+	if fake.getDiagnosticReportReturnsOnCall == nil {
+		fake.getDiagnosticReportReturnsOnCall = make(map[int]struct {
+			result1 api.DiagnosticReport
+			result2 error
+		})
+	}
+	fake.getDiagnosticReportReturnsOnCall[i] = struct {
+		result1 api.DiagnosticReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedDirectorAvailabilityZones() (api.AvailabilityZonesOutput, error) {
+	fake.getStagedDirectorAvailabilityZonesMutex.Lock()
+	ret, specificReturn := fake.getStagedDirectorAvailabilityZonesReturnsOnCall[len(fake.getStagedDirectorAvailabilityZonesArgsForCall)]
+	fake.getStagedDirectorAvailabilityZonesArgsForCall = append(fake.getStagedDirectorAvailabilityZonesArgsForCall, struct {
+	}{})
+	stub := fake.GetStagedDirectorAvailabilityZonesStub
+	fakeReturns := fake.getStagedDirectorAvailabilityZonesReturns
+	fake.recordInvocation("GetStagedDirectorAvailabilityZones", []interface{}{})
+	fake.getStagedDirectorAvailabilityZonesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedDirectorAvailabilityZonesCallCount() int {
+	fake.getStagedDirectorAvailabilityZonesMutex.RLock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.RUnlock()
+	return len(fake.getStagedDirectorAvailabilityZonesArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedDirectorAvailabilityZonesCalls(stub func() (api.AvailabilityZonesOutput, error)) {
+	fake.getStagedDirectorAvailabilityZonesMutex.Lock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.Unlock()
+	fake.GetStagedDirectorAvailabilityZonesStub = stub
+}
+
+func (fake *ConvergeService) GetStagedDirectorAvailabilityZonesReturns(result1 api.AvailabilityZonesOutput, result2 error) {
+	fake.getStagedDirectorAvailabilityZonesMutex.Lock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.Unlock()
+	fake.GetStagedDirectorAvailabilityZonesStub = nil
+	fake.getStagedDirectorAvailabilityZonesReturns = struct {
+		result1 api.AvailabilityZonesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedDirectorAvailabilityZonesReturnsOnCall(i int, result1 api.AvailabilityZonesOutput, result2 error) {
+	fake.getStagedDirectorAvailabilityZonesMutex.Lock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.Unlock()
+	fake.GetStagedDirectorAvailabilityZonesStub = nil
+	if fake.getStagedDirectorAvailabilityZonesReturnsOnCall == nil {
+		fake.getStagedDirectorAvailabilityZonesReturnsOnCall = make(map[int]struct {
+			result1 api.AvailabilityZonesOutput
+			result2 error
+		})
+	}
+	fake.getStagedDirectorAvailabilityZonesReturnsOnCall[i] = struct {
+		result1 api.AvailabilityZonesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedDirectorIaasConfigurations(arg1 bool) (map[string][]map[string]interface{}, error) {
+	fake.getStagedDirectorIaasConfigurationsMutex.Lock()
+	ret, specificReturn := fake.getStagedDirectorIaasConfigurationsReturnsOnCall[len(fake.getStagedDirectorIaasConfigurationsArgsForCall)]
+	fake.getStagedDirectorIaasConfigurationsArgsForCall = append(fake.getStagedDirectorIaasConfigurationsArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	stub := fake.GetStagedDirectorIaasConfigurationsStub
+	fakeReturns := fake.getStagedDirectorIaasConfigurationsReturns
+	fake.recordInvocation("GetStagedDirectorIaasConfigurations", []interface{}{arg1})
+	fake.getStagedDirectorIaasConfigurationsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedDirectorIaasConfigurationsCallCount() int {
+	fake.getStagedDirectorIaasConfigurationsMutex.RLock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.RUnlock()
+	return len(fake.getStagedDirectorIaasConfigurationsArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedDirectorIaasConfigurationsCalls(stub func(bool) (map[string][]map[string]interface{}, error)) {
+	fake.getStagedDirectorIaasConfigurationsMutex.Lock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.Unlock()
+	fake.GetStagedDirectorIaasConfigurationsStub = stub
+}
+
+func (fake *ConvergeService) GetStagedDirectorIaasConfigurationsArgsForCall(i int) bool {
+	fake.getStagedDirectorIaasConfigurationsMutex.RLock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.RUnlock()
+	argsForCall := fake.getStagedDirectorIaasConfigurationsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConvergeService) GetStagedDirectorIaasConfigurationsReturns(result1 map[string][]map[string]interface{}, result2 error) {
+	fake.getStagedDirectorIaasConfigurationsMutex.Lock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.Unlock()
+	fake.GetStagedDirectorIaasConfigurationsStub = nil
+	fake.getStagedDirectorIaasConfigurationsReturns = struct {
+		result1 map[string][]map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedDirectorIaasConfigurationsReturnsOnCall(i int, result1 map[string][]map[string]interface{}, result2 error) {
+	fake.getStagedDirectorIaasConfigurationsMutex.Lock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.Unlock()
+	fake.GetStagedDirectorIaasConfigurationsStub = nil
+	if fake.getStagedDirectorIaasConfigurationsReturnsOnCall == nil {
+		fake.getStagedDirectorIaasConfigurationsReturnsOnCall = make(map[int]struct {
+			result1 map[string][]map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedDirectorIaasConfigurationsReturnsOnCall[i] = struct {
+		result1 map[string][]map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedDirectorNetworks() (api.NetworksConfigurationOutput, error) {
+	fake.getStagedDirectorNetworksMutex.Lock()
+	ret, specificReturn := fake.getStagedDirectorNetworksReturnsOnCall[len(fake.getStagedDirectorNetworksArgsForCall)]
+	fake.getStagedDirectorNetworksArgsForCall = append(fake.getStagedDirectorNetworksArgsForCall, struct {
+	}{})
+	stub := fake.GetStagedDirectorNetworksStub
+	fakeReturns := fake.getStagedDirectorNetworksReturns
+	fake.recordInvocation("GetStagedDirectorNetworks", []interface{}{})
+	fake.getStagedDirectorNetworksMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedDirectorNetworksCallCount() int {
+	fake.getStagedDirectorNetworksMutex.RLock()
+	defer fake.getStagedDirectorNetworksMutex.RUnlock()
+	return len(fake.getStagedDirectorNetworksArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedDirectorNetworksCalls(stub func() (api.NetworksConfigurationOutput, error)) {
+	fake.getStagedDirectorNetworksMutex.Lock()
+	defer fake.getStagedDirectorNetworksMutex.Unlock()
+	fake.GetStagedDirectorNetworksStub = stub
+}
+
+func (fake *ConvergeService) GetStagedDirectorNetworksReturns(result1 api.NetworksConfigurationOutput, result2 error) {
+	fake.getStagedDirectorNetworksMutex.Lock()
+	defer fake.getStagedDirectorNetworksMutex.Unlock()
+	fake.GetStagedDirectorNetworksStub = nil
+	fake.getStagedDirectorNetworksReturns = struct {
+		result1 api.NetworksConfigurationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedDirectorNetworksReturnsOnCall(i int, result1 api.NetworksConfigurationOutput, result2 error) {
+	fake.getStagedDirectorNetworksMutex.Lock()
+	defer fake.getStagedDirectorNetworksMutex.Unlock()
+	fake.GetStagedDirectorNetworksStub = nil
+	if fake.getStagedDirectorNetworksReturnsOnCall == nil {
+		fake.getStagedDirectorNetworksReturnsOnCall = make(map[int]struct {
+			result1 api.NetworksConfigurationOutput
+			result2 error
+		})
+	}
+	fake.getStagedDirectorNetworksReturnsOnCall[i] = struct {
+		result1 api.NetworksConfigurationOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedDirectorProperties(arg1 bool) (map[string]interface{}, error) {
+	fake.getStagedDirectorPropertiesMutex.Lock()
+	ret, specificReturn := fake.getStagedDirectorPropertiesReturnsOnCall[len(fake.getStagedDirectorPropertiesArgsForCall)]
+	fake.getStagedDirectorPropertiesArgsForCall = append(fake.getStagedDirectorPropertiesArgsForCall, struct {
+		arg1 bool
+	}{arg1})
+	stub := fake.GetStagedDirectorPropertiesStub
+	fakeReturns := fake.getStagedDirectorPropertiesReturns
+	fake.recordInvocation("GetStagedDirectorProperties", []interface{}{arg1})
+	fake.getStagedDirectorPropertiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedDirectorPropertiesCallCount() int {
+	fake.getStagedDirectorPropertiesMutex.RLock()
+	defer fake.getStagedDirectorPropertiesMutex.RUnlock()
+	return len(fake.getStagedDirectorPropertiesArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedDirectorPropertiesCalls(stub func(bool) (map[string]interface{}, error)) {
+	fake.getStagedDirectorPropertiesMutex.Lock()
+	defer fake.getStagedDirectorPropertiesMutex.Unlock()
+	fake.GetStagedDirectorPropertiesStub = stub
+}
+
+func (fake *ConvergeService) GetStagedDirectorPropertiesArgsForCall(i int) bool {
+	fake.getStagedDirectorPropertiesMutex.RLock()
+	defer fake.getStagedDirectorPropertiesMutex.RUnlock()
+	argsForCall := fake.getStagedDirectorPropertiesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConvergeService) GetStagedDirectorPropertiesReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedDirectorPropertiesMutex.Lock()
+	defer fake.getStagedDirectorPropertiesMutex.Unlock()
+	fake.GetStagedDirectorPropertiesStub = nil
+	fake.getStagedDirectorPropertiesReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedDirectorPropertiesReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedDirectorPropertiesMutex.Lock()
+	defer fake.getStagedDirectorPropertiesMutex.Unlock()
+	fake.GetStagedDirectorPropertiesStub = nil
+	if fake.getStagedDirectorPropertiesReturnsOnCall == nil {
+		fake.getStagedDirectorPropertiesReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedDirectorPropertiesReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductByName(arg1 string) (api.StagedProductsFindOutput, error) {
+	fake.getStagedProductByNameMutex.Lock()
+	ret, specificReturn := fake.getStagedProductByNameReturnsOnCall[len(fake.getStagedProductByNameArgsForCall)]
+	fake.getStagedProductByNameArgsForCall = append(fake.getStagedProductByNameArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductByNameStub
+	fakeReturns := fake.getStagedProductByNameReturns
+	fake.recordInvocation("GetStagedProductByName", []interface{}{arg1})
+	fake.getStagedProductByNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedProductByNameCallCount() int {
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	return len(fake.getStagedProductByNameArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedProductByNameCalls(stub func(string) (api.StagedProductsFindOutput, error)) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = stub
+}
+
+func (fake *ConvergeService) GetStagedProductByNameArgsForCall(i int) string {
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	argsForCall := fake.getStagedProductByNameArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConvergeService) GetStagedProductByNameReturns(result1 api.StagedProductsFindOutput, result2 error) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = nil
+	fake.getStagedProductByNameReturns = struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductByNameReturnsOnCall(i int, result1 api.StagedProductsFindOutput, result2 error) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = nil
+	if fake.getStagedProductByNameReturnsOnCall == nil {
+		fake.getStagedProductByNameReturnsOnCall = make(map[int]struct {
+			result1 api.StagedProductsFindOutput
+			result2 error
+		})
+	}
+	fake.getStagedProductByNameReturnsOnCall[i] = struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductJobMaxInFlight(arg1 string) (map[string]interface{}, error) {
+	fake.getStagedProductJobMaxInFlightMutex.Lock()
+	ret, specificReturn := fake.getStagedProductJobMaxInFlightReturnsOnCall[len(fake.getStagedProductJobMaxInFlightArgsForCall)]
+	fake.getStagedProductJobMaxInFlightArgsForCall = append(fake.getStagedProductJobMaxInFlightArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductJobMaxInFlightStub
+	fakeReturns := fake.getStagedProductJobMaxInFlightReturns
+	fake.recordInvocation("GetStagedProductJobMaxInFlight", []interface{}{arg1})
+	fake.getStagedProductJobMaxInFlightMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedProductJobMaxInFlightCallCount() int {
+	fake.getStagedProductJobMaxInFlightMutex.RLock()
+	defer fake.getStagedProductJobMaxInFlightMutex.RUnlock()
+	return len(fake.getStagedProductJobMaxInFlightArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedProductJobMaxInFlightCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.getStagedProductJobMaxInFlightMutex.Lock()
+	defer fake.getStagedProductJobMaxInFlightMutex.Unlock()
+	fake.GetStagedProductJobMaxInFlightStub = stub
+}
+
+func (fake *ConvergeService) GetStagedProductJobMaxInFlightArgsForCall(i int) string {
+	fake.getStagedProductJobMaxInFlightMutex.RLock()
+	defer fake.getStagedProductJobMaxInFlightMutex.RUnlock()
+	argsForCall := fake.getStagedProductJobMaxInFlightArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConvergeService) GetStagedProductJobMaxInFlightReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductJobMaxInFlightMutex.Lock()
+	defer fake.getStagedProductJobMaxInFlightMutex.Unlock()
+	fake.GetStagedProductJobMaxInFlightStub = nil
+	fake.getStagedProductJobMaxInFlightReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductJobMaxInFlightReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductJobMaxInFlightMutex.Lock()
+	defer fake.getStagedProductJobMaxInFlightMutex.Unlock()
+	fake.GetStagedProductJobMaxInFlightStub = nil
+	if fake.getStagedProductJobMaxInFlightReturnsOnCall == nil {
+		fake.getStagedProductJobMaxInFlightReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedProductJobMaxInFlightReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductJobResourceConfig(arg1 string, arg2 string) (api.JobProperties, error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	ret, specificReturn := fake.getStagedProductJobResourceConfigReturnsOnCall[len(fake.getStagedProductJobResourceConfigArgsForCall)]
+	fake.getStagedProductJobResourceConfigArgsForCall = append(fake.getStagedProductJobResourceConfigArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetStagedProductJobResourceConfigStub
+	fakeReturns := fake.getStagedProductJobResourceConfigReturns
+	fake.recordInvocation("GetStagedProductJobResourceConfig", []interface{}{arg1, arg2})
+	fake.getStagedProductJobResourceConfigMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedProductJobResourceConfigCallCount() int {
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
+	return len(fake.getStagedProductJobResourceConfigArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedProductJobResourceConfigCalls(stub func(string, string) (api.JobProperties, error)) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = stub
+}
+
+func (fake *ConvergeService) GetStagedProductJobResourceConfigArgsForCall(i int) (string, string) {
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
+	argsForCall := fake.getStagedProductJobResourceConfigArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *ConvergeService) GetStagedProductJobResourceConfigReturns(result1 api.JobProperties, result2 error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = nil
+	fake.getStagedProductJobResourceConfigReturns = struct {
+		result1 api.JobProperties
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductJobResourceConfigReturnsOnCall(i int, result1 api.JobProperties, result2 error) {
+	fake.getStagedProductJobResourceConfigMutex.Lock()
+	defer fake.getStagedProductJobResourceConfigMutex.Unlock()
+	fake.GetStagedProductJobResourceConfigStub = nil
+	if fake.getStagedProductJobResourceConfigReturnsOnCall == nil {
+		fake.getStagedProductJobResourceConfigReturnsOnCall = make(map[int]struct {
+			result1 api.JobProperties
+			result2 error
+		})
+	}
+	fake.getStagedProductJobResourceConfigReturnsOnCall[i] = struct {
+		result1 api.JobProperties
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductNetworksAndAZs(arg1 string) (map[string]interface{}, error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	ret, specificReturn := fake.getStagedProductNetworksAndAZsReturnsOnCall[len(fake.getStagedProductNetworksAndAZsArgsForCall)]
+	fake.getStagedProductNetworksAndAZsArgsForCall = append(fake.getStagedProductNetworksAndAZsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductNetworksAndAZsStub
+	fakeReturns := fake.getStagedProductNetworksAndAZsReturns
+	fake.recordInvocation("GetStagedProductNetworksAndAZs", []interface{}{arg1})
+	fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedProductNetworksAndAZsCallCount() int {
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
+	return len(fake.getStagedProductNetworksAndAZsArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedProductNetworksAndAZsCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = stub
+}
+
+func (fake *ConvergeService) GetStagedProductNetworksAndAZsArgsForCall(i int) string {
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
+	argsForCall := fake.getStagedProductNetworksAndAZsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConvergeService) GetStagedProductNetworksAndAZsReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = nil
+	fake.getStagedProductNetworksAndAZsReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductNetworksAndAZsReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductNetworksAndAZsMutex.Lock()
+	defer fake.getStagedProductNetworksAndAZsMutex.Unlock()
+	fake.GetStagedProductNetworksAndAZsStub = nil
+	if fake.getStagedProductNetworksAndAZsReturnsOnCall == nil {
+		fake.getStagedProductNetworksAndAZsReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedProductNetworksAndAZsReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductProperties(arg1 string, arg2 bool) (map[string]api.ResponseProperty, error) {
+	fake.getStagedProductPropertiesMutex.Lock()
+	ret, specificReturn := fake.getStagedProductPropertiesReturnsOnCall[len(fake.getStagedProductPropertiesArgsForCall)]
+	fake.getStagedProductPropertiesArgsForCall = append(fake.getStagedProductPropertiesArgsForCall, struct {
+		arg1 string
+		arg2 bool
+	}{arg1, arg2})
+	stub := fake.GetStagedProductPropertiesStub
+	fakeReturns := fake.getStagedProductPropertiesReturns
+	fake.recordInvocation("GetStagedProductProperties", []interface{}{arg1, arg2})
+	fake.getStagedProductPropertiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedProductPropertiesCallCount() int {
+	fake.getStagedProductPropertiesMutex.RLock()
+	defer fake.getStagedProductPropertiesMutex.RUnlock()
+	return len(fake.getStagedProductPropertiesArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedProductPropertiesCalls(stub func(string, bool) (map[string]api.ResponseProperty, error)) {
+	fake.getStagedProductPropertiesMutex.Lock()
+	defer fake.getStagedProductPropertiesMutex.Unlock()
+	fake.GetStagedProductPropertiesStub = stub
+}
+
+func (fake *ConvergeService) GetStagedProductPropertiesArgsForCall(i int) (string, bool) {
+	fake.getStagedProductPropertiesMutex.RLock()
+	defer fake.getStagedProductPropertiesMutex.RUnlock()
+	argsForCall := fake.getStagedProductPropertiesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *ConvergeService) GetStagedProductPropertiesReturns(result1 map[string]api.ResponseProperty, result2 error) {
+	fake.getStagedProductPropertiesMutex.Lock()
+	defer fake.getStagedProductPropertiesMutex.Unlock()
+	fake.GetStagedProductPropertiesStub = nil
+	fake.getStagedProductPropertiesReturns = struct {
+		result1 map[string]api.ResponseProperty
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductPropertiesReturnsOnCall(i int, result1 map[string]api.ResponseProperty, result2 error) {
+	fake.getStagedProductPropertiesMutex.Lock()
+	defer fake.getStagedProductPropertiesMutex.Unlock()
+	fake.GetStagedProductPropertiesStub = nil
+	if fake.getStagedProductPropertiesReturnsOnCall == nil {
+		fake.getStagedProductPropertiesReturnsOnCall = make(map[int]struct {
+			result1 map[string]api.ResponseProperty
+			result2 error
+		})
+	}
+	fake.getStagedProductPropertiesReturnsOnCall[i] = struct {
+		result1 map[string]api.ResponseProperty
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductSyslogConfiguration(arg1 string) (map[string]interface{}, error) {
+	fake.getStagedProductSyslogConfigurationMutex.Lock()
+	ret, specificReturn := fake.getStagedProductSyslogConfigurationReturnsOnCall[len(fake.getStagedProductSyslogConfigurationArgsForCall)]
+	fake.getStagedProductSyslogConfigurationArgsForCall = append(fake.getStagedProductSyslogConfigurationArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductSyslogConfigurationStub
+	fakeReturns := fake.getStagedProductSyslogConfigurationReturns
+	fake.recordInvocation("GetStagedProductSyslogConfiguration", []interface{}{arg1})
+	fake.getStagedProductSyslogConfigurationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) GetStagedProductSyslogConfigurationCallCount() int {
+	fake.getStagedProductSyslogConfigurationMutex.RLock()
+	defer fake.getStagedProductSyslogConfigurationMutex.RUnlock()
+	return len(fake.getStagedProductSyslogConfigurationArgsForCall)
+}
+
+func (fake *ConvergeService) GetStagedProductSyslogConfigurationCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.getStagedProductSyslogConfigurationMutex.Lock()
+	defer fake.getStagedProductSyslogConfigurationMutex.Unlock()
+	fake.GetStagedProductSyslogConfigurationStub = stub
+}
+
+func (fake *ConvergeService) GetStagedProductSyslogConfigurationArgsForCall(i int) string {
+	fake.getStagedProductSyslogConfigurationMutex.RLock()
+	defer fake.getStagedProductSyslogConfigurationMutex.RUnlock()
+	argsForCall := fake.getStagedProductSyslogConfigurationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConvergeService) GetStagedProductSyslogConfigurationReturns(result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductSyslogConfigurationMutex.Lock()
+	defer fake.getStagedProductSyslogConfigurationMutex.Unlock()
+	fake.GetStagedProductSyslogConfigurationStub = nil
+	fake.getStagedProductSyslogConfigurationReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) GetStagedProductSyslogConfigurationReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getStagedProductSyslogConfigurationMutex.Lock()
+	defer fake.getStagedProductSyslogConfigurationMutex.Unlock()
+	fake.GetStagedProductSyslogConfigurationStub = nil
+	if fake.getStagedProductSyslogConfigurationReturnsOnCall == nil {
+		fake.getStagedProductSyslogConfigurationReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getStagedProductSyslogConfigurationReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedPendingChanges() (api.PendingChangesOutput, error) {
+	fake.listStagedPendingChangesMutex.Lock()
+	ret, specificReturn := fake.listStagedPendingChangesReturnsOnCall[len(fake.listStagedPendingChangesArgsForCall)]
+	fake.listStagedPendingChangesArgsForCall = append(fake.listStagedPendingChangesArgsForCall, struct {
+	}{})
+	stub := fake.ListStagedPendingChangesStub
+	fakeReturns := fake.listStagedPendingChangesReturns
+	fake.recordInvocation("ListStagedPendingChanges", []interface{}{})
+	fake.listStagedPendingChangesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) ListStagedPendingChangesCallCount() int {
+	fake.listStagedPendingChangesMutex.RLock()
+	defer fake.listStagedPendingChangesMutex.RUnlock()
+	return len(fake.listStagedPendingChangesArgsForCall)
+}
+
+func (fake *ConvergeService) ListStagedPendingChangesCalls(stub func() (api.PendingChangesOutput, error)) {
+	fake.listStagedPendingChangesMutex.Lock()
+	defer fake.listStagedPendingChangesMutex.Unlock()
+	fake.ListStagedPendingChangesStub = stub
+}
+
+func (fake *ConvergeService) ListStagedPendingChangesReturns(result1 api.PendingChangesOutput, result2 error) {
+	fake.listStagedPendingChangesMutex.Lock()
+	defer fake.listStagedPendingChangesMutex.Unlock()
+	fake.ListStagedPendingChangesStub = nil
+	fake.listStagedPendingChangesReturns = struct {
+		result1 api.PendingChangesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedPendingChangesReturnsOnCall(i int, result1 api.PendingChangesOutput, result2 error) {
+	fake.listStagedPendingChangesMutex.Lock()
+	defer fake.listStagedPendingChangesMutex.Unlock()
+	fake.ListStagedPendingChangesStub = nil
+	if fake.listStagedPendingChangesReturnsOnCall == nil {
+		fake.listStagedPendingChangesReturnsOnCall = make(map[int]struct {
+			result1 api.PendingChangesOutput
+			result2 error
+		})
+	}
+	fake.listStagedPendingChangesReturnsOnCall[i] = struct {
+		result1 api.PendingChangesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedProductErrands(arg1 string) (api.ErrandsListOutput, error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	ret, specificReturn := fake.listStagedProductErrandsReturnsOnCall[len(fake.listStagedProductErrandsArgsForCall)]
+	fake.listStagedProductErrandsArgsForCall = append(fake.listStagedProductErrandsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ListStagedProductErrandsStub
+	fakeReturns := fake.listStagedProductErrandsReturns
+	fake.recordInvocation("ListStagedProductErrands", []interface{}{arg1})
+	fake.listStagedProductErrandsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) ListStagedProductErrandsCallCount() int {
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
+	return len(fake.listStagedProductErrandsArgsForCall)
+}
+
+func (fake *ConvergeService) ListStagedProductErrandsCalls(stub func(string) (api.ErrandsListOutput, error)) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = stub
+}
+
+func (fake *ConvergeService) ListStagedProductErrandsArgsForCall(i int) string {
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
+	argsForCall := fake.listStagedProductErrandsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConvergeService) ListStagedProductErrandsReturns(result1 api.ErrandsListOutput, result2 error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = nil
+	fake.listStagedProductErrandsReturns = struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedProductErrandsReturnsOnCall(i int, result1 api.ErrandsListOutput, result2 error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = nil
+	if fake.listStagedProductErrandsReturnsOnCall == nil {
+		fake.listStagedProductErrandsReturnsOnCall = make(map[int]struct {
+			result1 api.ErrandsListOutput
+			result2 error
+		})
+	}
+	fake.listStagedProductErrandsReturnsOnCall[i] = struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedProductJobs(arg1 string) (map[string]string, error) {
+	fake.listStagedProductJobsMutex.Lock()
+	ret, specificReturn := fake.listStagedProductJobsReturnsOnCall[len(fake.listStagedProductJobsArgsForCall)]
+	fake.listStagedProductJobsArgsForCall = append(fake.listStagedProductJobsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ListStagedProductJobsStub
+	fakeReturns := fake.listStagedProductJobsReturns
+	fake.recordInvocation("ListStagedProductJobs", []interface{}{arg1})
+	fake.listStagedProductJobsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) ListStagedProductJobsCallCount() int {
+	fake.listStagedProductJobsMutex.RLock()
+	defer fake.listStagedProductJobsMutex.RUnlock()
+	return len(fake.listStagedProductJobsArgsForCall)
+}
+
+func (fake *ConvergeService) ListStagedProductJobsCalls(stub func(string) (map[string]string, error)) {
+	fake.listStagedProductJobsMutex.Lock()
+	defer fake.listStagedProductJobsMutex.Unlock()
+	fake.ListStagedProductJobsStub = stub
+}
+
+func (fake *ConvergeService) ListStagedProductJobsArgsForCall(i int) string {
+	fake.listStagedProductJobsMutex.RLock()
+	defer fake.listStagedProductJobsMutex.RUnlock()
+	argsForCall := fake.listStagedProductJobsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ConvergeService) ListStagedProductJobsReturns(result1 map[string]string, result2 error) {
+	fake.listStagedProductJobsMutex.Lock()
+	defer fake.listStagedProductJobsMutex.Unlock()
+	fake.ListStagedProductJobsStub = nil
+	fake.listStagedProductJobsReturns = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedProductJobsReturnsOnCall(i int, result1 map[string]string, result2 error) {
+	fake.listStagedProductJobsMutex.Lock()
+	defer fake.listStagedProductJobsMutex.Unlock()
+	fake.ListStagedProductJobsStub = nil
+	if fake.listStagedProductJobsReturnsOnCall == nil {
+		fake.listStagedProductJobsReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+			result2 error
+		})
+	}
+	fake.listStagedProductJobsReturnsOnCall[i] = struct {
+		result1 map[string]string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedProducts() (api.StagedProductsOutput, error) {
+	fake.listStagedProductsMutex.Lock()
+	ret, specificReturn := fake.listStagedProductsReturnsOnCall[len(fake.listStagedProductsArgsForCall)]
+	fake.listStagedProductsArgsForCall = append(fake.listStagedProductsArgsForCall, struct {
+	}{})
+	stub := fake.ListStagedProductsStub
+	fakeReturns := fake.listStagedProductsReturns
+	fake.recordInvocation("ListStagedProducts", []interface{}{})
+	fake.listStagedProductsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) ListStagedProductsCallCount() int {
+	fake.listStagedProductsMutex.RLock()
+	defer fake.listStagedProductsMutex.RUnlock()
+	return len(fake.listStagedProductsArgsForCall)
+}
+
+func (fake *ConvergeService) ListStagedProductsCalls(stub func() (api.StagedProductsOutput, error)) {
+	fake.listStagedProductsMutex.Lock()
+	defer fake.listStagedProductsMutex.Unlock()
+	fake.ListStagedProductsStub = stub
+}
+
+func (fake *ConvergeService) ListStagedProductsReturns(result1 api.StagedProductsOutput, result2 error) {
+	fake.listStagedProductsMutex.Lock()
+	defer fake.listStagedProductsMutex.Unlock()
+	fake.ListStagedProductsStub = nil
+	fake.listStagedProductsReturns = struct {
+		result1 api.StagedProductsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedProductsReturnsOnCall(i int, result1 api.StagedProductsOutput, result2 error) {
+	fake.listStagedProductsMutex.Lock()
+	defer fake.listStagedProductsMutex.Unlock()
+	fake.ListStagedProductsStub = nil
+	if fake.listStagedProductsReturnsOnCall == nil {
+		fake.listStagedProductsReturnsOnCall = make(map[int]struct {
+			result1 api.StagedProductsOutput
+			result2 error
+		})
+	}
+	fake.listStagedProductsReturnsOnCall[i] = struct {
+		result1 api.StagedProductsOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedVMExtensions() ([]api.VMExtension, error) {
+	fake.listStagedVMExtensionsMutex.Lock()
+	ret, specificReturn := fake.listStagedVMExtensionsReturnsOnCall[len(fake.listStagedVMExtensionsArgsForCall)]
+	fake.listStagedVMExtensionsArgsForCall = append(fake.listStagedVMExtensionsArgsForCall, struct {
+	}{})
+	stub := fake.ListStagedVMExtensionsStub
+	fakeReturns := fake.listStagedVMExtensionsReturns
+	fake.recordInvocation("ListStagedVMExtensions", []interface{}{})
+	fake.listStagedVMExtensionsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) ListStagedVMExtensionsCallCount() int {
+	fake.listStagedVMExtensionsMutex.RLock()
+	defer fake.listStagedVMExtensionsMutex.RUnlock()
+	return len(fake.listStagedVMExtensionsArgsForCall)
+}
+
+func (fake *ConvergeService) ListStagedVMExtensionsCalls(stub func() ([]api.VMExtension, error)) {
+	fake.listStagedVMExtensionsMutex.Lock()
+	defer fake.listStagedVMExtensionsMutex.Unlock()
+	fake.ListStagedVMExtensionsStub = stub
+}
+
+func (fake *ConvergeService) ListStagedVMExtensionsReturns(result1 []api.VMExtension, result2 error) {
+	fake.listStagedVMExtensionsMutex.Lock()
+	defer fake.listStagedVMExtensionsMutex.Unlock()
+	fake.ListStagedVMExtensionsStub = nil
+	fake.listStagedVMExtensionsReturns = struct {
+		result1 []api.VMExtension
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStagedVMExtensionsReturnsOnCall(i int, result1 []api.VMExtension, result2 error) {
+	fake.listStagedVMExtensionsMutex.Lock()
+	defer fake.listStagedVMExtensionsMutex.Unlock()
+	fake.ListStagedVMExtensionsStub = nil
+	if fake.listStagedVMExtensionsReturnsOnCall == nil {
+		fake.listStagedVMExtensionsReturnsOnCall = make(map[int]struct {
+			result1 []api.VMExtension
+			result2 error
+		})
+	}
+	fake.listStagedVMExtensionsReturnsOnCall[i] = struct {
+		result1 []api.VMExtension
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStemcells() (api.ProductStemcells, error) {
+	fake.listStemcellsMutex.Lock()
+	ret, specificReturn := fake.listStemcellsReturnsOnCall[len(fake.listStemcellsArgsForCall)]
+	fake.listStemcellsArgsForCall = append(fake.listStemcellsArgsForCall, struct {
+	}{})
+	stub := fake.ListStemcellsStub
+	fakeReturns := fake.listStemcellsReturns
+	fake.recordInvocation("ListStemcells", []interface{}{})
+	fake.listStemcellsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) ListStemcellsCallCount() int {
+	fake.listStemcellsMutex.RLock()
+	defer fake.listStemcellsMutex.RUnlock()
+	return len(fake.listStemcellsArgsForCall)
+}
+
+func (fake *ConvergeService) ListStemcellsCalls(stub func() (api.ProductStemcells, error)) {
+	fake.listStemcellsMutex.Lock()
+	defer fake.listStemcellsMutex.Unlock()
+	fake.ListStemcellsStub = stub
+}
+
+func (fake *ConvergeService) ListStemcellsReturns(result1 api.ProductStemcells, result2 error) {
+	fake.listStemcellsMutex.Lock()
+	defer fake.listStemcellsMutex.Unlock()
+	fake.ListStemcellsStub = nil
+	fake.listStemcellsReturns = struct {
+		result1 api.ProductStemcells
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListStemcellsReturnsOnCall(i int, result1 api.ProductStemcells, result2 error) {
+	fake.listStemcellsMutex.Lock()
+	defer fake.listStemcellsMutex.Unlock()
+	fake.ListStemcellsStub = nil
+	if fake.listStemcellsReturnsOnCall == nil {
+		fake.listStemcellsReturnsOnCall = make(map[int]struct {
+			result1 api.ProductStemcells
+			result2 error
+		})
+	}
+	fake.listStemcellsReturnsOnCall[i] = struct {
+		result1 api.ProductStemcells
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListVMTypes() ([]api.VMType, error) {
+	fake.listVMTypesMutex.Lock()
+	ret, specificReturn := fake.listVMTypesReturnsOnCall[len(fake.listVMTypesArgsForCall)]
+	fake.listVMTypesArgsForCall = append(fake.listVMTypesArgsForCall, struct {
+	}{})
+	stub := fake.ListVMTypesStub
+	fakeReturns := fake.listVMTypesReturns
+	fake.recordInvocation("ListVMTypes", []interface{}{})
+	fake.listVMTypesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ConvergeService) ListVMTypesCallCount() int {
+	fake.listVMTypesMutex.RLock()
+	defer fake.listVMTypesMutex.RUnlock()
+	return len(fake.listVMTypesArgsForCall)
+}
+
+func (fake *ConvergeService) ListVMTypesCalls(stub func() ([]api.VMType, error)) {
+	fake.listVMTypesMutex.Lock()
+	defer fake.listVMTypesMutex.Unlock()
+	fake.ListVMTypesStub = stub
+}
+
+func (fake *ConvergeService) ListVMTypesReturns(result1 []api.VMType, result2 error) {
+	fake.listVMTypesMutex.Lock()
+	defer fake.listVMTypesMutex.Unlock()
+	fake.ListVMTypesStub = nil
+	fake.listVMTypesReturns = struct {
+		result1 []api.VMType
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) ListVMTypesReturnsOnCall(i int, result1 []api.VMType, result2 error) {
+	fake.listVMTypesMutex.Lock()
+	defer fake.listVMTypesMutex.Unlock()
+	fake.ListVMTypesStub = nil
+	if fake.listVMTypesReturnsOnCall == nil {
+		fake.listVMTypesReturnsOnCall = make(map[int]struct {
+			result1 []api.VMType
+			result2 error
+		})
+	}
+	fake.listVMTypesReturnsOnCall[i] = struct {
+		result1 []api.VMType
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ConvergeService) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.checkProductAvailabilityMutex.RLock()
+	defer fake.checkProductAvailabilityMutex.RUnlock()
+	fake.getDiagnosticReportMutex.RLock()
+	defer fake.getDiagnosticReportMutex.RUnlock()
+	fake.getStagedDirectorAvailabilityZonesMutex.RLock()
+	defer fake.getStagedDirectorAvailabilityZonesMutex.RUnlock()
+	fake.getStagedDirectorIaasConfigurationsMutex.RLock()
+	defer fake.getStagedDirectorIaasConfigurationsMutex.RUnlock()
+	fake.getStagedDirectorNetworksMutex.RLock()
+	defer fake.getStagedDirectorNetworksMutex.RUnlock()
+	fake.getStagedDirectorPropertiesMutex.RLock()
+	defer fake.getStagedDirectorPropertiesMutex.RUnlock()
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	fake.getStagedProductJobMaxInFlightMutex.RLock()
+	defer fake.getStagedProductJobMaxInFlightMutex.RUnlock()
+	fake.getStagedProductJobResourceConfigMutex.RLock()
+	defer fake.getStagedProductJobResourceConfigMutex.RUnlock()
+	fake.getStagedProductNetworksAndAZsMutex.RLock()
+	defer fake.getStagedProductNetworksAndAZsMutex.RUnlock()
+	fake.getStagedProductPropertiesMutex.RLock()
+	defer fake.getStagedProductPropertiesMutex.RUnlock()
+	fake.getStagedProductSyslogConfigurationMutex.RLock()
+	defer fake.getStagedProductSyslogConfigurationMutex.RUnlock()
+	fake.listStagedPendingChangesMutex.RLock()
+	defer fake.listStagedPendingChangesMutex.RUnlock()
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
+	fake.listStagedProductJobsMutex.RLock()
+	defer fake.listStagedProductJobsMutex.RUnlock()
+	fake.listStagedProductsMutex.RLock()
+	defer fake.listStagedProductsMutex.RUnlock()
+	fake.listStagedVMExtensionsMutex.RLock()
+	defer fake.listStagedVMExtensionsMutex.RUnlock()
+	fake.listStemcellsMutex.RLock()
+	defer fake.listStemcellsMutex.RUnlock()
+	fake.listVMTypesMutex.RLock()
+	defer fake.listVMTypesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *ConvergeService) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
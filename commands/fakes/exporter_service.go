@@ -0,0 +1,254 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/om/api"
+)
+
+type ExporterService struct {
+	ListExpiringCertificatesStub        func(string) ([]api.ExpiringCertificate, error)
+	listExpiringCertificatesMutex       sync.RWMutex
+	listExpiringCertificatesArgsForCall []struct {
+		arg1 string
+	}
+	listExpiringCertificatesReturns struct {
+		result1 []api.ExpiringCertificate
+		result2 error
+	}
+	listExpiringCertificatesReturnsOnCall map[int]struct {
+		result1 []api.ExpiringCertificate
+		result2 error
+	}
+	ListInstallationsStub        func() ([]api.InstallationsServiceOutput, error)
+	listInstallationsMutex       sync.RWMutex
+	listInstallationsArgsForCall []struct {
+	}
+	listInstallationsReturns struct {
+		result1 []api.InstallationsServiceOutput
+		result2 error
+	}
+	listInstallationsReturnsOnCall map[int]struct {
+		result1 []api.InstallationsServiceOutput
+		result2 error
+	}
+	ListStagedPendingChangesStub        func() (api.PendingChangesOutput, error)
+	listStagedPendingChangesMutex       sync.RWMutex
+	listStagedPendingChangesArgsForCall []struct {
+	}
+	listStagedPendingChangesReturns struct {
+		result1 api.PendingChangesOutput
+		result2 error
+	}
+	listStagedPendingChangesReturnsOnCall map[int]struct {
+		result1 api.PendingChangesOutput
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *ExporterService) ListExpiringCertificates(arg1 string) ([]api.ExpiringCertificate, error) {
+	fake.listExpiringCertificatesMutex.Lock()
+	ret, specificReturn := fake.listExpiringCertificatesReturnsOnCall[len(fake.listExpiringCertificatesArgsForCall)]
+	fake.listExpiringCertificatesArgsForCall = append(fake.listExpiringCertificatesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ListExpiringCertificatesStub
+	fakeReturns := fake.listExpiringCertificatesReturns
+	fake.recordInvocation("ListExpiringCertificates", []interface{}{arg1})
+	fake.listExpiringCertificatesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ExporterService) ListExpiringCertificatesCallCount() int {
+	fake.listExpiringCertificatesMutex.RLock()
+	defer fake.listExpiringCertificatesMutex.RUnlock()
+	return len(fake.listExpiringCertificatesArgsForCall)
+}
+
+func (fake *ExporterService) ListExpiringCertificatesCalls(stub func(string) ([]api.ExpiringCertificate, error)) {
+	fake.listExpiringCertificatesMutex.Lock()
+	defer fake.listExpiringCertificatesMutex.Unlock()
+	fake.ListExpiringCertificatesStub = stub
+}
+
+func (fake *ExporterService) ListExpiringCertificatesArgsForCall(i int) string {
+	fake.listExpiringCertificatesMutex.RLock()
+	defer fake.listExpiringCertificatesMutex.RUnlock()
+	argsForCall := fake.listExpiringCertificatesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ExporterService) ListExpiringCertificatesReturns(result1 []api.ExpiringCertificate, result2 error) {
+	fake.listExpiringCertificatesMutex.Lock()
+	defer fake.listExpiringCertificatesMutex.Unlock()
+	fake.ListExpiringCertificatesStub = nil
+	fake.listExpiringCertificatesReturns = struct {
+		result1 []api.ExpiringCertificate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ExporterService) ListExpiringCertificatesReturnsOnCall(i int, result1 []api.ExpiringCertificate, result2 error) {
+	fake.listExpiringCertificatesMutex.Lock()
+	defer fake.listExpiringCertificatesMutex.Unlock()
+	fake.ListExpiringCertificatesStub = nil
+	if fake.listExpiringCertificatesReturnsOnCall == nil {
+		fake.listExpiringCertificatesReturnsOnCall = make(map[int]struct {
+			result1 []api.ExpiringCertificate
+			result2 error
+		})
+	}
+	fake.listExpiringCertificatesReturnsOnCall[i] = struct {
+		result1 []api.ExpiringCertificate
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ExporterService) ListInstallations() ([]api.InstallationsServiceOutput, error) {
+	fake.listInstallationsMutex.Lock()
+	ret, specificReturn := fake.listInstallationsReturnsOnCall[len(fake.listInstallationsArgsForCall)]
+	fake.listInstallationsArgsForCall = append(fake.listInstallationsArgsForCall, struct {
+	}{})
+	stub := fake.ListInstallationsStub
+	fakeReturns := fake.listInstallationsReturns
+	fake.recordInvocation("ListInstallations", []interface{}{})
+	fake.listInstallationsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ExporterService) ListInstallationsCallCount() int {
+	fake.listInstallationsMutex.RLock()
+	defer fake.listInstallationsMutex.RUnlock()
+	return len(fake.listInstallationsArgsForCall)
+}
+
+func (fake *ExporterService) ListInstallationsCalls(stub func() ([]api.InstallationsServiceOutput, error)) {
+	fake.listInstallationsMutex.Lock()
+	defer fake.listInstallationsMutex.Unlock()
+	fake.ListInstallationsStub = stub
+}
+
+func (fake *ExporterService) ListInstallationsReturns(result1 []api.InstallationsServiceOutput, result2 error) {
+	fake.listInstallationsMutex.Lock()
+	defer fake.listInstallationsMutex.Unlock()
+	fake.ListInstallationsStub = nil
+	fake.listInstallationsReturns = struct {
+		result1 []api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ExporterService) ListInstallationsReturnsOnCall(i int, result1 []api.InstallationsServiceOutput, result2 error) {
+	fake.listInstallationsMutex.Lock()
+	defer fake.listInstallationsMutex.Unlock()
+	fake.ListInstallationsStub = nil
+	if fake.listInstallationsReturnsOnCall == nil {
+		fake.listInstallationsReturnsOnCall = make(map[int]struct {
+			result1 []api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.listInstallationsReturnsOnCall[i] = struct {
+		result1 []api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ExporterService) ListStagedPendingChanges() (api.PendingChangesOutput, error) {
+	fake.listStagedPendingChangesMutex.Lock()
+	ret, specificReturn := fake.listStagedPendingChangesReturnsOnCall[len(fake.listStagedPendingChangesArgsForCall)]
+	fake.listStagedPendingChangesArgsForCall = append(fake.listStagedPendingChangesArgsForCall, struct {
+	}{})
+	stub := fake.ListStagedPendingChangesStub
+	fakeReturns := fake.listStagedPendingChangesReturns
+	fake.recordInvocation("ListStagedPendingChanges", []interface{}{})
+	fake.listStagedPendingChangesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ExporterService) ListStagedPendingChangesCallCount() int {
+	fake.listStagedPendingChangesMutex.RLock()
+	defer fake.listStagedPendingChangesMutex.RUnlock()
+	return len(fake.listStagedPendingChangesArgsForCall)
+}
+
+func (fake *ExporterService) ListStagedPendingChangesCalls(stub func() (api.PendingChangesOutput, error)) {
+	fake.listStagedPendingChangesMutex.Lock()
+	defer fake.listStagedPendingChangesMutex.Unlock()
+	fake.ListStagedPendingChangesStub = stub
+}
+
+func (fake *ExporterService) ListStagedPendingChangesReturns(result1 api.PendingChangesOutput, result2 error) {
+	fake.listStagedPendingChangesMutex.Lock()
+	defer fake.listStagedPendingChangesMutex.Unlock()
+	fake.ListStagedPendingChangesStub = nil
+	fake.listStagedPendingChangesReturns = struct {
+		result1 api.PendingChangesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ExporterService) ListStagedPendingChangesReturnsOnCall(i int, result1 api.PendingChangesOutput, result2 error) {
+	fake.listStagedPendingChangesMutex.Lock()
+	defer fake.listStagedPendingChangesMutex.Unlock()
+	fake.ListStagedPendingChangesStub = nil
+	if fake.listStagedPendingChangesReturnsOnCall == nil {
+		fake.listStagedPendingChangesReturnsOnCall = make(map[int]struct {
+			result1 api.PendingChangesOutput
+			result2 error
+		})
+	}
+	fake.listStagedPendingChangesReturnsOnCall[i] = struct {
+		result1 api.PendingChangesOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ExporterService) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.listExpiringCertificatesMutex.RLock()
+	defer fake.listExpiringCertificatesMutex.RUnlock()
+	fake.listInstallationsMutex.RLock()
+	defer fake.listInstallationsMutex.RUnlock()
+	fake.listStagedPendingChangesMutex.RLock()
+	defer fake.listStagedPendingChangesMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *ExporterService) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
@@ -0,0 +1,263 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/om/api"
+)
+
+type InstallationSummaryService struct {
+	GetInstallationStub        func(int) (api.InstallationsServiceOutput, error)
+	getInstallationMutex       sync.RWMutex
+	getInstallationArgsForCall []struct {
+		arg1 int
+	}
+	getInstallationReturns struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	getInstallationReturnsOnCall map[int]struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	GetInstallationLogsStub        func(int) (api.InstallationsServiceOutput, error)
+	getInstallationLogsMutex       sync.RWMutex
+	getInstallationLogsArgsForCall []struct {
+		arg1 int
+	}
+	getInstallationLogsReturns struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	getInstallationLogsReturnsOnCall map[int]struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	ListInstallationsStub        func() ([]api.InstallationsServiceOutput, error)
+	listInstallationsMutex       sync.RWMutex
+	listInstallationsArgsForCall []struct {
+	}
+	listInstallationsReturns struct {
+		result1 []api.InstallationsServiceOutput
+		result2 error
+	}
+	listInstallationsReturnsOnCall map[int]struct {
+		result1 []api.InstallationsServiceOutput
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *InstallationSummaryService) GetInstallation(arg1 int) (api.InstallationsServiceOutput, error) {
+	fake.getInstallationMutex.Lock()
+	ret, specificReturn := fake.getInstallationReturnsOnCall[len(fake.getInstallationArgsForCall)]
+	fake.getInstallationArgsForCall = append(fake.getInstallationArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.GetInstallationStub
+	fakeReturns := fake.getInstallationReturns
+	fake.recordInvocation("GetInstallation", []interface{}{arg1})
+	fake.getInstallationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *InstallationSummaryService) GetInstallationCallCount() int {
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	return len(fake.getInstallationArgsForCall)
+}
+
+func (fake *InstallationSummaryService) GetInstallationCalls(stub func(int) (api.InstallationsServiceOutput, error)) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = stub
+}
+
+func (fake *InstallationSummaryService) GetInstallationArgsForCall(i int) int {
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	argsForCall := fake.getInstallationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *InstallationSummaryService) GetInstallationReturns(result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = nil
+	fake.getInstallationReturns = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *InstallationSummaryService) GetInstallationReturnsOnCall(i int, result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = nil
+	if fake.getInstallationReturnsOnCall == nil {
+		fake.getInstallationReturnsOnCall = make(map[int]struct {
+			result1 api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.getInstallationReturnsOnCall[i] = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *InstallationSummaryService) GetInstallationLogs(arg1 int) (api.InstallationsServiceOutput, error) {
+	fake.getInstallationLogsMutex.Lock()
+	ret, specificReturn := fake.getInstallationLogsReturnsOnCall[len(fake.getInstallationLogsArgsForCall)]
+	fake.getInstallationLogsArgsForCall = append(fake.getInstallationLogsArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.GetInstallationLogsStub
+	fakeReturns := fake.getInstallationLogsReturns
+	fake.recordInvocation("GetInstallationLogs", []interface{}{arg1})
+	fake.getInstallationLogsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *InstallationSummaryService) GetInstallationLogsCallCount() int {
+	fake.getInstallationLogsMutex.RLock()
+	defer fake.getInstallationLogsMutex.RUnlock()
+	return len(fake.getInstallationLogsArgsForCall)
+}
+
+func (fake *InstallationSummaryService) GetInstallationLogsCalls(stub func(int) (api.InstallationsServiceOutput, error)) {
+	fake.getInstallationLogsMutex.Lock()
+	defer fake.getInstallationLogsMutex.Unlock()
+	fake.GetInstallationLogsStub = stub
+}
+
+func (fake *InstallationSummaryService) GetInstallationLogsArgsForCall(i int) int {
+	fake.getInstallationLogsMutex.RLock()
+	defer fake.getInstallationLogsMutex.RUnlock()
+	argsForCall := fake.getInstallationLogsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *InstallationSummaryService) GetInstallationLogsReturns(result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationLogsMutex.Lock()
+	defer fake.getInstallationLogsMutex.Unlock()
+	fake.GetInstallationLogsStub = nil
+	fake.getInstallationLogsReturns = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *InstallationSummaryService) GetInstallationLogsReturnsOnCall(i int, result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationLogsMutex.Lock()
+	defer fake.getInstallationLogsMutex.Unlock()
+	fake.GetInstallationLogsStub = nil
+	if fake.getInstallationLogsReturnsOnCall == nil {
+		fake.getInstallationLogsReturnsOnCall = make(map[int]struct {
+			result1 api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.getInstallationLogsReturnsOnCall[i] = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *InstallationSummaryService) ListInstallations() ([]api.InstallationsServiceOutput, error) {
+	fake.listInstallationsMutex.Lock()
+	ret, specificReturn := fake.listInstallationsReturnsOnCall[len(fake.listInstallationsArgsForCall)]
+	fake.listInstallationsArgsForCall = append(fake.listInstallationsArgsForCall, struct {
+	}{})
+	stub := fake.ListInstallationsStub
+	fakeReturns := fake.listInstallationsReturns
+	fake.recordInvocation("ListInstallations", []interface{}{})
+	fake.listInstallationsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *InstallationSummaryService) ListInstallationsCallCount() int {
+	fake.listInstallationsMutex.RLock()
+	defer fake.listInstallationsMutex.RUnlock()
+	return len(fake.listInstallationsArgsForCall)
+}
+
+func (fake *InstallationSummaryService) ListInstallationsCalls(stub func() ([]api.InstallationsServiceOutput, error)) {
+	fake.listInstallationsMutex.Lock()
+	defer fake.listInstallationsMutex.Unlock()
+	fake.ListInstallationsStub = stub
+}
+
+func (fake *InstallationSummaryService) ListInstallationsReturns(result1 []api.InstallationsServiceOutput, result2 error) {
+	fake.listInstallationsMutex.Lock()
+	defer fake.listInstallationsMutex.Unlock()
+	fake.ListInstallationsStub = nil
+	fake.listInstallationsReturns = struct {
+		result1 []api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *InstallationSummaryService) ListInstallationsReturnsOnCall(i int, result1 []api.InstallationsServiceOutput, result2 error) {
+	fake.listInstallationsMutex.Lock()
+	defer fake.listInstallationsMutex.Unlock()
+	fake.ListInstallationsStub = nil
+	if fake.listInstallationsReturnsOnCall == nil {
+		fake.listInstallationsReturnsOnCall = make(map[int]struct {
+			result1 []api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.listInstallationsReturnsOnCall[i] = struct {
+		result1 []api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *InstallationSummaryService) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	fake.getInstallationLogsMutex.RLock()
+	defer fake.getInstallationLogsMutex.RUnlock()
+	fake.listInstallationsMutex.RLock()
+	defer fake.listInstallationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *InstallationSummaryService) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
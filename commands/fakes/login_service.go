@@ -0,0 +1,177 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+)
+
+type LoginService struct {
+	LoginStub        func(string) error
+	loginMutex       sync.RWMutex
+	loginArgsForCall []struct {
+		arg1 string
+	}
+	loginReturns struct {
+		result1 error
+	}
+	loginReturnsOnCall map[int]struct {
+		result1 error
+	}
+	PasscodeURLStub        func() (string, error)
+	passcodeURLMutex       sync.RWMutex
+	passcodeURLArgsForCall []struct {
+	}
+	passcodeURLReturns struct {
+		result1 string
+		result2 error
+	}
+	passcodeURLReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *LoginService) Login(arg1 string) error {
+	fake.loginMutex.Lock()
+	ret, specificReturn := fake.loginReturnsOnCall[len(fake.loginArgsForCall)]
+	fake.loginArgsForCall = append(fake.loginArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.LoginStub
+	fakeReturns := fake.loginReturns
+	fake.recordInvocation("Login", []interface{}{arg1})
+	fake.loginMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *LoginService) LoginCallCount() int {
+	fake.loginMutex.RLock()
+	defer fake.loginMutex.RUnlock()
+	return len(fake.loginArgsForCall)
+}
+
+func (fake *LoginService) LoginCalls(stub func(string) error) {
+	fake.loginMutex.Lock()
+	defer fake.loginMutex.Unlock()
+	fake.LoginStub = stub
+}
+
+func (fake *LoginService) LoginArgsForCall(i int) string {
+	fake.loginMutex.RLock()
+	defer fake.loginMutex.RUnlock()
+	argsForCall := fake.loginArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *LoginService) LoginReturns(result1 error) {
+	fake.loginMutex.Lock()
+	defer fake.loginMutex.Unlock()
+	fake.LoginStub = nil
+	fake.loginReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *LoginService) LoginReturnsOnCall(i int, result1 error) {
+	fake.loginMutex.Lock()
+	defer fake.loginMutex.Unlock()
+	fake.LoginStub = nil
+	if fake.loginReturnsOnCall == nil {
+		fake.loginReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.loginReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *LoginService) PasscodeURL() (string, error) {
+	fake.passcodeURLMutex.Lock()
+	ret, specificReturn := fake.passcodeURLReturnsOnCall[len(fake.passcodeURLArgsForCall)]
+	fake.passcodeURLArgsForCall = append(fake.passcodeURLArgsForCall, struct {
+	}{})
+	stub := fake.PasscodeURLStub
+	fakeReturns := fake.passcodeURLReturns
+	fake.recordInvocation("PasscodeURL", []interface{}{})
+	fake.passcodeURLMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *LoginService) PasscodeURLCallCount() int {
+	fake.passcodeURLMutex.RLock()
+	defer fake.passcodeURLMutex.RUnlock()
+	return len(fake.passcodeURLArgsForCall)
+}
+
+func (fake *LoginService) PasscodeURLCalls(stub func() (string, error)) {
+	fake.passcodeURLMutex.Lock()
+	defer fake.passcodeURLMutex.Unlock()
+	fake.PasscodeURLStub = stub
+}
+
+func (fake *LoginService) PasscodeURLReturns(result1 string, result2 error) {
+	fake.passcodeURLMutex.Lock()
+	defer fake.passcodeURLMutex.Unlock()
+	fake.PasscodeURLStub = nil
+	fake.passcodeURLReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *LoginService) PasscodeURLReturnsOnCall(i int, result1 string, result2 error) {
+	fake.passcodeURLMutex.Lock()
+	defer fake.passcodeURLMutex.Unlock()
+	fake.PasscodeURLStub = nil
+	if fake.passcodeURLReturnsOnCall == nil {
+		fake.passcodeURLReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.passcodeURLReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *LoginService) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.loginMutex.RLock()
+	defer fake.loginMutex.RUnlock()
+	fake.passcodeURLMutex.RLock()
+	defer fake.passcodeURLMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *LoginService) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
@@ -0,0 +1,244 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/om/profiles"
+)
+
+type ProfilesService struct {
+	LoadStub        func() (profiles.Config, error)
+	loadMutex       sync.RWMutex
+	loadArgsForCall []struct {
+	}
+	loadReturns struct {
+		result1 profiles.Config
+		result2 error
+	}
+	loadReturnsOnCall map[int]struct {
+		result1 profiles.Config
+		result2 error
+	}
+	PathStub        func() string
+	pathMutex       sync.RWMutex
+	pathArgsForCall []struct {
+	}
+	pathReturns struct {
+		result1 string
+	}
+	pathReturnsOnCall map[int]struct {
+		result1 string
+	}
+	SaveStub        func(profiles.Config) error
+	saveMutex       sync.RWMutex
+	saveArgsForCall []struct {
+		arg1 profiles.Config
+	}
+	saveReturns struct {
+		result1 error
+	}
+	saveReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *ProfilesService) Load() (profiles.Config, error) {
+	fake.loadMutex.Lock()
+	ret, specificReturn := fake.loadReturnsOnCall[len(fake.loadArgsForCall)]
+	fake.loadArgsForCall = append(fake.loadArgsForCall, struct {
+	}{})
+	stub := fake.LoadStub
+	fakeReturns := fake.loadReturns
+	fake.recordInvocation("Load", []interface{}{})
+	fake.loadMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *ProfilesService) LoadCallCount() int {
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	return len(fake.loadArgsForCall)
+}
+
+func (fake *ProfilesService) LoadCalls(stub func() (profiles.Config, error)) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = stub
+}
+
+func (fake *ProfilesService) LoadReturns(result1 profiles.Config, result2 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	fake.loadReturns = struct {
+		result1 profiles.Config
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ProfilesService) LoadReturnsOnCall(i int, result1 profiles.Config, result2 error) {
+	fake.loadMutex.Lock()
+	defer fake.loadMutex.Unlock()
+	fake.LoadStub = nil
+	if fake.loadReturnsOnCall == nil {
+		fake.loadReturnsOnCall = make(map[int]struct {
+			result1 profiles.Config
+			result2 error
+		})
+	}
+	fake.loadReturnsOnCall[i] = struct {
+		result1 profiles.Config
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *ProfilesService) Path() string {
+	fake.pathMutex.Lock()
+	ret, specificReturn := fake.pathReturnsOnCall[len(fake.pathArgsForCall)]
+	fake.pathArgsForCall = append(fake.pathArgsForCall, struct {
+	}{})
+	stub := fake.PathStub
+	fakeReturns := fake.pathReturns
+	fake.recordInvocation("Path", []interface{}{})
+	fake.pathMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *ProfilesService) PathCallCount() int {
+	fake.pathMutex.RLock()
+	defer fake.pathMutex.RUnlock()
+	return len(fake.pathArgsForCall)
+}
+
+func (fake *ProfilesService) PathCalls(stub func() string) {
+	fake.pathMutex.Lock()
+	defer fake.pathMutex.Unlock()
+	fake.PathStub = stub
+}
+
+func (fake *ProfilesService) PathReturns(result1 string) {
+	fake.pathMutex.Lock()
+	defer fake.pathMutex.Unlock()
+	fake.PathStub = nil
+	fake.pathReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *ProfilesService) PathReturnsOnCall(i int, result1 string) {
+	fake.pathMutex.Lock()
+	defer fake.pathMutex.Unlock()
+	fake.PathStub = nil
+	if fake.pathReturnsOnCall == nil {
+		fake.pathReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.pathReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *ProfilesService) Save(arg1 profiles.Config) error {
+	fake.saveMutex.Lock()
+	ret, specificReturn := fake.saveReturnsOnCall[len(fake.saveArgsForCall)]
+	fake.saveArgsForCall = append(fake.saveArgsForCall, struct {
+		arg1 profiles.Config
+	}{arg1})
+	stub := fake.SaveStub
+	fakeReturns := fake.saveReturns
+	fake.recordInvocation("Save", []interface{}{arg1})
+	fake.saveMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *ProfilesService) SaveCallCount() int {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	return len(fake.saveArgsForCall)
+}
+
+func (fake *ProfilesService) SaveCalls(stub func(profiles.Config) error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = stub
+}
+
+func (fake *ProfilesService) SaveArgsForCall(i int) profiles.Config {
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	argsForCall := fake.saveArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *ProfilesService) SaveReturns(result1 error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = nil
+	fake.saveReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *ProfilesService) SaveReturnsOnCall(i int, result1 error) {
+	fake.saveMutex.Lock()
+	defer fake.saveMutex.Unlock()
+	fake.SaveStub = nil
+	if fake.saveReturnsOnCall == nil {
+		fake.saveReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.saveReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *ProfilesService) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.loadMutex.RLock()
+	defer fake.loadMutex.RUnlock()
+	fake.pathMutex.RLock()
+	defer fake.pathMutex.RUnlock()
+	fake.saveMutex.RLock()
+	defer fake.saveMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *ProfilesService) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
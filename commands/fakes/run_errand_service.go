@@ -0,0 +1,591 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"sync"
+
+	"github.com/pivotal-cf/om/api"
+)
+
+type RunErrandService struct {
+	CreateInstallationStub        func(bool, bool, []string, api.ApplyErrandChanges) (api.InstallationsServiceOutput, error)
+	createInstallationMutex       sync.RWMutex
+	createInstallationArgsForCall []struct {
+		arg1 bool
+		arg2 bool
+		arg3 []string
+		arg4 api.ApplyErrandChanges
+	}
+	createInstallationReturns struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	createInstallationReturnsOnCall map[int]struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	GetInstallationStub        func(int) (api.InstallationsServiceOutput, error)
+	getInstallationMutex       sync.RWMutex
+	getInstallationArgsForCall []struct {
+		arg1 int
+	}
+	getInstallationReturns struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	getInstallationReturnsOnCall map[int]struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	GetInstallationLogsStub        func(int) (api.InstallationsServiceOutput, error)
+	getInstallationLogsMutex       sync.RWMutex
+	getInstallationLogsArgsForCall []struct {
+		arg1 int
+	}
+	getInstallationLogsReturns struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	getInstallationLogsReturnsOnCall map[int]struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}
+	GetStagedProductByNameStub        func(string) (api.StagedProductsFindOutput, error)
+	getStagedProductByNameMutex       sync.RWMutex
+	getStagedProductByNameArgsForCall []struct {
+		arg1 string
+	}
+	getStagedProductByNameReturns struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}
+	getStagedProductByNameReturnsOnCall map[int]struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}
+	ListDeployedProductsStub        func() ([]api.DeployedProductOutput, error)
+	listDeployedProductsMutex       sync.RWMutex
+	listDeployedProductsArgsForCall []struct {
+	}
+	listDeployedProductsReturns struct {
+		result1 []api.DeployedProductOutput
+		result2 error
+	}
+	listDeployedProductsReturnsOnCall map[int]struct {
+		result1 []api.DeployedProductOutput
+		result2 error
+	}
+	ListStagedProductErrandsStub        func(string) (api.ErrandsListOutput, error)
+	listStagedProductErrandsMutex       sync.RWMutex
+	listStagedProductErrandsArgsForCall []struct {
+		arg1 string
+	}
+	listStagedProductErrandsReturns struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}
+	listStagedProductErrandsReturnsOnCall map[int]struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}
+	UpdateStagedProductErrandsStub        func(string, string, interface{}, interface{}) error
+	updateStagedProductErrandsMutex       sync.RWMutex
+	updateStagedProductErrandsArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 interface{}
+		arg4 interface{}
+	}
+	updateStagedProductErrandsReturns struct {
+		result1 error
+	}
+	updateStagedProductErrandsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *RunErrandService) CreateInstallation(arg1 bool, arg2 bool, arg3 []string, arg4 api.ApplyErrandChanges) (api.InstallationsServiceOutput, error) {
+	var arg3Copy []string
+	if arg3 != nil {
+		arg3Copy = make([]string, len(arg3))
+		copy(arg3Copy, arg3)
+	}
+	fake.createInstallationMutex.Lock()
+	ret, specificReturn := fake.createInstallationReturnsOnCall[len(fake.createInstallationArgsForCall)]
+	fake.createInstallationArgsForCall = append(fake.createInstallationArgsForCall, struct {
+		arg1 bool
+		arg2 bool
+		arg3 []string
+		arg4 api.ApplyErrandChanges
+	}{arg1, arg2, arg3Copy, arg4})
+	stub := fake.CreateInstallationStub
+	fakeReturns := fake.createInstallationReturns
+	fake.recordInvocation("CreateInstallation", []interface{}{arg1, arg2, arg3Copy, arg4})
+	fake.createInstallationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *RunErrandService) CreateInstallationCallCount() int {
+	fake.createInstallationMutex.RLock()
+	defer fake.createInstallationMutex.RUnlock()
+	return len(fake.createInstallationArgsForCall)
+}
+
+func (fake *RunErrandService) CreateInstallationCalls(stub func(bool, bool, []string, api.ApplyErrandChanges) (api.InstallationsServiceOutput, error)) {
+	fake.createInstallationMutex.Lock()
+	defer fake.createInstallationMutex.Unlock()
+	fake.CreateInstallationStub = stub
+}
+
+func (fake *RunErrandService) CreateInstallationArgsForCall(i int) (bool, bool, []string, api.ApplyErrandChanges) {
+	fake.createInstallationMutex.RLock()
+	defer fake.createInstallationMutex.RUnlock()
+	argsForCall := fake.createInstallationArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *RunErrandService) CreateInstallationReturns(result1 api.InstallationsServiceOutput, result2 error) {
+	fake.createInstallationMutex.Lock()
+	defer fake.createInstallationMutex.Unlock()
+	fake.CreateInstallationStub = nil
+	fake.createInstallationReturns = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) CreateInstallationReturnsOnCall(i int, result1 api.InstallationsServiceOutput, result2 error) {
+	fake.createInstallationMutex.Lock()
+	defer fake.createInstallationMutex.Unlock()
+	fake.CreateInstallationStub = nil
+	if fake.createInstallationReturnsOnCall == nil {
+		fake.createInstallationReturnsOnCall = make(map[int]struct {
+			result1 api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.createInstallationReturnsOnCall[i] = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) GetInstallation(arg1 int) (api.InstallationsServiceOutput, error) {
+	fake.getInstallationMutex.Lock()
+	ret, specificReturn := fake.getInstallationReturnsOnCall[len(fake.getInstallationArgsForCall)]
+	fake.getInstallationArgsForCall = append(fake.getInstallationArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.GetInstallationStub
+	fakeReturns := fake.getInstallationReturns
+	fake.recordInvocation("GetInstallation", []interface{}{arg1})
+	fake.getInstallationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *RunErrandService) GetInstallationCallCount() int {
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	return len(fake.getInstallationArgsForCall)
+}
+
+func (fake *RunErrandService) GetInstallationCalls(stub func(int) (api.InstallationsServiceOutput, error)) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = stub
+}
+
+func (fake *RunErrandService) GetInstallationArgsForCall(i int) int {
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	argsForCall := fake.getInstallationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *RunErrandService) GetInstallationReturns(result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = nil
+	fake.getInstallationReturns = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) GetInstallationReturnsOnCall(i int, result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationMutex.Lock()
+	defer fake.getInstallationMutex.Unlock()
+	fake.GetInstallationStub = nil
+	if fake.getInstallationReturnsOnCall == nil {
+		fake.getInstallationReturnsOnCall = make(map[int]struct {
+			result1 api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.getInstallationReturnsOnCall[i] = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) GetInstallationLogs(arg1 int) (api.InstallationsServiceOutput, error) {
+	fake.getInstallationLogsMutex.Lock()
+	ret, specificReturn := fake.getInstallationLogsReturnsOnCall[len(fake.getInstallationLogsArgsForCall)]
+	fake.getInstallationLogsArgsForCall = append(fake.getInstallationLogsArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.GetInstallationLogsStub
+	fakeReturns := fake.getInstallationLogsReturns
+	fake.recordInvocation("GetInstallationLogs", []interface{}{arg1})
+	fake.getInstallationLogsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *RunErrandService) GetInstallationLogsCallCount() int {
+	fake.getInstallationLogsMutex.RLock()
+	defer fake.getInstallationLogsMutex.RUnlock()
+	return len(fake.getInstallationLogsArgsForCall)
+}
+
+func (fake *RunErrandService) GetInstallationLogsCalls(stub func(int) (api.InstallationsServiceOutput, error)) {
+	fake.getInstallationLogsMutex.Lock()
+	defer fake.getInstallationLogsMutex.Unlock()
+	fake.GetInstallationLogsStub = stub
+}
+
+func (fake *RunErrandService) GetInstallationLogsArgsForCall(i int) int {
+	fake.getInstallationLogsMutex.RLock()
+	defer fake.getInstallationLogsMutex.RUnlock()
+	argsForCall := fake.getInstallationLogsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *RunErrandService) GetInstallationLogsReturns(result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationLogsMutex.Lock()
+	defer fake.getInstallationLogsMutex.Unlock()
+	fake.GetInstallationLogsStub = nil
+	fake.getInstallationLogsReturns = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) GetInstallationLogsReturnsOnCall(i int, result1 api.InstallationsServiceOutput, result2 error) {
+	fake.getInstallationLogsMutex.Lock()
+	defer fake.getInstallationLogsMutex.Unlock()
+	fake.GetInstallationLogsStub = nil
+	if fake.getInstallationLogsReturnsOnCall == nil {
+		fake.getInstallationLogsReturnsOnCall = make(map[int]struct {
+			result1 api.InstallationsServiceOutput
+			result2 error
+		})
+	}
+	fake.getInstallationLogsReturnsOnCall[i] = struct {
+		result1 api.InstallationsServiceOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) GetStagedProductByName(arg1 string) (api.StagedProductsFindOutput, error) {
+	fake.getStagedProductByNameMutex.Lock()
+	ret, specificReturn := fake.getStagedProductByNameReturnsOnCall[len(fake.getStagedProductByNameArgsForCall)]
+	fake.getStagedProductByNameArgsForCall = append(fake.getStagedProductByNameArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetStagedProductByNameStub
+	fakeReturns := fake.getStagedProductByNameReturns
+	fake.recordInvocation("GetStagedProductByName", []interface{}{arg1})
+	fake.getStagedProductByNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *RunErrandService) GetStagedProductByNameCallCount() int {
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	return len(fake.getStagedProductByNameArgsForCall)
+}
+
+func (fake *RunErrandService) GetStagedProductByNameCalls(stub func(string) (api.StagedProductsFindOutput, error)) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = stub
+}
+
+func (fake *RunErrandService) GetStagedProductByNameArgsForCall(i int) string {
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	argsForCall := fake.getStagedProductByNameArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *RunErrandService) GetStagedProductByNameReturns(result1 api.StagedProductsFindOutput, result2 error) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = nil
+	fake.getStagedProductByNameReturns = struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) GetStagedProductByNameReturnsOnCall(i int, result1 api.StagedProductsFindOutput, result2 error) {
+	fake.getStagedProductByNameMutex.Lock()
+	defer fake.getStagedProductByNameMutex.Unlock()
+	fake.GetStagedProductByNameStub = nil
+	if fake.getStagedProductByNameReturnsOnCall == nil {
+		fake.getStagedProductByNameReturnsOnCall = make(map[int]struct {
+			result1 api.StagedProductsFindOutput
+			result2 error
+		})
+	}
+	fake.getStagedProductByNameReturnsOnCall[i] = struct {
+		result1 api.StagedProductsFindOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) ListDeployedProducts() ([]api.DeployedProductOutput, error) {
+	fake.listDeployedProductsMutex.Lock()
+	ret, specificReturn := fake.listDeployedProductsReturnsOnCall[len(fake.listDeployedProductsArgsForCall)]
+	fake.listDeployedProductsArgsForCall = append(fake.listDeployedProductsArgsForCall, struct {
+	}{})
+	stub := fake.ListDeployedProductsStub
+	fakeReturns := fake.listDeployedProductsReturns
+	fake.recordInvocation("ListDeployedProducts", []interface{}{})
+	fake.listDeployedProductsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *RunErrandService) ListDeployedProductsCallCount() int {
+	fake.listDeployedProductsMutex.RLock()
+	defer fake.listDeployedProductsMutex.RUnlock()
+	return len(fake.listDeployedProductsArgsForCall)
+}
+
+func (fake *RunErrandService) ListDeployedProductsCalls(stub func() ([]api.DeployedProductOutput, error)) {
+	fake.listDeployedProductsMutex.Lock()
+	defer fake.listDeployedProductsMutex.Unlock()
+	fake.ListDeployedProductsStub = stub
+}
+
+func (fake *RunErrandService) ListDeployedProductsReturns(result1 []api.DeployedProductOutput, result2 error) {
+	fake.listDeployedProductsMutex.Lock()
+	defer fake.listDeployedProductsMutex.Unlock()
+	fake.ListDeployedProductsStub = nil
+	fake.listDeployedProductsReturns = struct {
+		result1 []api.DeployedProductOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) ListDeployedProductsReturnsOnCall(i int, result1 []api.DeployedProductOutput, result2 error) {
+	fake.listDeployedProductsMutex.Lock()
+	defer fake.listDeployedProductsMutex.Unlock()
+	fake.ListDeployedProductsStub = nil
+	if fake.listDeployedProductsReturnsOnCall == nil {
+		fake.listDeployedProductsReturnsOnCall = make(map[int]struct {
+			result1 []api.DeployedProductOutput
+			result2 error
+		})
+	}
+	fake.listDeployedProductsReturnsOnCall[i] = struct {
+		result1 []api.DeployedProductOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) ListStagedProductErrands(arg1 string) (api.ErrandsListOutput, error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	ret, specificReturn := fake.listStagedProductErrandsReturnsOnCall[len(fake.listStagedProductErrandsArgsForCall)]
+	fake.listStagedProductErrandsArgsForCall = append(fake.listStagedProductErrandsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ListStagedProductErrandsStub
+	fakeReturns := fake.listStagedProductErrandsReturns
+	fake.recordInvocation("ListStagedProductErrands", []interface{}{arg1})
+	fake.listStagedProductErrandsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *RunErrandService) ListStagedProductErrandsCallCount() int {
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
+	return len(fake.listStagedProductErrandsArgsForCall)
+}
+
+func (fake *RunErrandService) ListStagedProductErrandsCalls(stub func(string) (api.ErrandsListOutput, error)) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = stub
+}
+
+func (fake *RunErrandService) ListStagedProductErrandsArgsForCall(i int) string {
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
+	argsForCall := fake.listStagedProductErrandsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *RunErrandService) ListStagedProductErrandsReturns(result1 api.ErrandsListOutput, result2 error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = nil
+	fake.listStagedProductErrandsReturns = struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) ListStagedProductErrandsReturnsOnCall(i int, result1 api.ErrandsListOutput, result2 error) {
+	fake.listStagedProductErrandsMutex.Lock()
+	defer fake.listStagedProductErrandsMutex.Unlock()
+	fake.ListStagedProductErrandsStub = nil
+	if fake.listStagedProductErrandsReturnsOnCall == nil {
+		fake.listStagedProductErrandsReturnsOnCall = make(map[int]struct {
+			result1 api.ErrandsListOutput
+			result2 error
+		})
+	}
+	fake.listStagedProductErrandsReturnsOnCall[i] = struct {
+		result1 api.ErrandsListOutput
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RunErrandService) UpdateStagedProductErrands(arg1 string, arg2 string, arg3 interface{}, arg4 interface{}) error {
+	fake.updateStagedProductErrandsMutex.Lock()
+	ret, specificReturn := fake.updateStagedProductErrandsReturnsOnCall[len(fake.updateStagedProductErrandsArgsForCall)]
+	fake.updateStagedProductErrandsArgsForCall = append(fake.updateStagedProductErrandsArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 interface{}
+		arg4 interface{}
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.UpdateStagedProductErrandsStub
+	fakeReturns := fake.updateStagedProductErrandsReturns
+	fake.recordInvocation("UpdateStagedProductErrands", []interface{}{arg1, arg2, arg3, arg4})
+	fake.updateStagedProductErrandsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *RunErrandService) UpdateStagedProductErrandsCallCount() int {
+	fake.updateStagedProductErrandsMutex.RLock()
+	defer fake.updateStagedProductErrandsMutex.RUnlock()
+	return len(fake.updateStagedProductErrandsArgsForCall)
+}
+
+func (fake *RunErrandService) UpdateStagedProductErrandsCalls(stub func(string, string, interface{}, interface{}) error) {
+	fake.updateStagedProductErrandsMutex.Lock()
+	defer fake.updateStagedProductErrandsMutex.Unlock()
+	fake.UpdateStagedProductErrandsStub = stub
+}
+
+func (fake *RunErrandService) UpdateStagedProductErrandsArgsForCall(i int) (string, string, interface{}, interface{}) {
+	fake.updateStagedProductErrandsMutex.RLock()
+	defer fake.updateStagedProductErrandsMutex.RUnlock()
+	argsForCall := fake.updateStagedProductErrandsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *RunErrandService) UpdateStagedProductErrandsReturns(result1 error) {
+	fake.updateStagedProductErrandsMutex.Lock()
+	defer fake.updateStagedProductErrandsMutex.Unlock()
+	fake.UpdateStagedProductErrandsStub = nil
+	fake.updateStagedProductErrandsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *RunErrandService) UpdateStagedProductErrandsReturnsOnCall(i int, result1 error) {
+	fake.updateStagedProductErrandsMutex.Lock()
+	defer fake.updateStagedProductErrandsMutex.Unlock()
+	fake.UpdateStagedProductErrandsStub = nil
+	if fake.updateStagedProductErrandsReturnsOnCall == nil {
+		fake.updateStagedProductErrandsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updateStagedProductErrandsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *RunErrandService) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.createInstallationMutex.RLock()
+	defer fake.createInstallationMutex.RUnlock()
+	fake.getInstallationMutex.RLock()
+	defer fake.getInstallationMutex.RUnlock()
+	fake.getInstallationLogsMutex.RLock()
+	defer fake.getInstallationLogsMutex.RUnlock()
+	fake.getStagedProductByNameMutex.RLock()
+	defer fake.getStagedProductByNameMutex.RUnlock()
+	fake.listDeployedProductsMutex.RLock()
+	defer fake.listDeployedProductsMutex.RUnlock()
+	fake.listStagedProductErrandsMutex.RLock()
+	defer fake.listStagedProductErrandsMutex.RUnlock()
+	fake.updateStagedProductErrandsMutex.RLock()
+	defer fake.updateStagedProductErrandsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *RunErrandService) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
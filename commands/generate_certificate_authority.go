@@ -12,7 +12,7 @@ type GenerateCertificateAuthority struct {
 	service   generateCertificateAuthorityService
 	presenter presenters.FormattedPresenter
 	Options   struct {
-		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -35,7 +35,10 @@ func (g GenerateCertificateAuthority) Execute(args []string) error {
 		return err
 	}
 
-	g.presenter.SetFormat(g.Options.Format)
+	err = g.presenter.SetFormat(g.Options.Format)
+	if err != nil {
+		return err
+	}
 	g.presenter.PresentCertificateAuthority(certificateAuthority)
 
 	return nil
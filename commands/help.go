@@ -86,7 +86,12 @@ func (h Help) buildGlobalContext() TemplateContext {
 		names  []string
 	)
 
-	for name := range h.commands {
+	for name, command := range h.commands {
+		// commands without a short description are hidden
+		if command.Usage().ShortDescription == "" {
+			continue
+		}
+
 		names = append(names, name)
 		if len(name) > length {
 			length = len(name)
@@ -91,6 +91,31 @@ var _ = Describe("Help", func() {
 
 				Expect(output.String()).To(ContainSubstring(GLOBAL_USAGE))
 			})
+
+			It("does not list commands without a short description", func() {
+				bake := &fakeCommand{
+					usage: jhanda.Usage{ShortDescription: "bakes you a cake"},
+				}
+
+				clean := &fakeCommand{
+					usage: jhanda.Usage{ShortDescription: "cleans up after baking"},
+				}
+
+				hidden := &fakeCommand{
+					usage: jhanda.Usage{Description: "This command is not for everyone."},
+				}
+
+				help := commands.NewHelp(output, strings.TrimSpace(flags), jhanda.CommandSet{
+					"bake":                  bake,
+					"clean":                 clean,
+					"a-very-hidden-command": hidden,
+				})
+				err := help.Execute([]string{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(output.String()).To(ContainSubstring(GLOBAL_USAGE))
+				Expect(output.String()).ToNot(ContainSubstring("a-very-hidden-command"))
+			})
 		})
 
 		When("a command name is given", func() {
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/interpolate"
 	"os"
 	"strings"
 	"time"
@@ -13,11 +14,12 @@ import (
 const maxRetries = 3
 
 type ImportInstallation struct {
-	multipart  multipart
-	logger     logger
-	service    importInstallationService
-	passphrase string
-	Options    struct {
+	varsSourceConfig interpolate.VarsSourceConfig
+	multipart        multipart
+	logger           logger
+	service          importInstallationService
+	passphrase       string
+	Options          struct {
 		interpolateConfigFileOptions
 
 		Installation    string `long:"installation"          short:"i"  required:"true" description:"path to installation."`
@@ -31,12 +33,13 @@ type importInstallationService interface {
 	EnsureAvailability(input api.EnsureAvailabilityInput) (api.EnsureAvailabilityOutput, error)
 }
 
-func NewImportInstallation(multipart multipart, service importInstallationService, passphrase string, logger logger) *ImportInstallation {
+func NewImportInstallation(varsSourceConfig interpolate.VarsSourceConfig, multipart multipart, service importInstallationService, passphrase string, logger logger) *ImportInstallation {
 	return &ImportInstallation{
-		multipart:  multipart,
-		logger:     logger,
-		service:    service,
-		passphrase: passphrase,
+		varsSourceConfig: varsSourceConfig,
+		multipart:        multipart,
+		logger:           logger,
+		service:          service,
+		passphrase:       passphrase,
 	}
 }
 
@@ -130,7 +133,7 @@ func (ii *ImportInstallation) validate(args []string) error {
 		return fmt.Errorf("the global decryption-passphrase argument is required for this command")
 	}
 
-	err := loadConfigFile(args, &ii.Options, os.Environ)
+	err := loadConfigFile(args, &ii.Options, os.Environ, ii.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse import-installation flags: %s", err)
 	}
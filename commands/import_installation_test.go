@@ -11,6 +11,7 @@ import (
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
 	"github.com/pivotal-cf/om/formcontent"
+	"github.com/pivotal-cf/om/interpolate"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -79,7 +80,7 @@ var _ = Describe("ImportInstallation", func() {
 			return eaOutputs[fakeService.EnsureAvailabilityCallCount()-1], nil
 		}
 
-		command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+		command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "some-passphrase", logger)
 
 		err := command.Execute([]string{"--polling-interval", "0",
 			"--installation", installationFile,
@@ -122,7 +123,7 @@ var _ = Describe("ImportInstallation", func() {
 				Status: api.EnsureAvailabilityStatusComplete,
 			}, nil)
 
-			command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+			command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "some-passphrase", logger)
 
 			err := command.Execute([]string{"--polling-interval", "0",
 				"--installation", installationFile,
@@ -168,7 +169,7 @@ var _ = Describe("ImportInstallation", func() {
 				return eaOutputs[fakeService.EnsureAvailabilityCallCount()-1], nil
 			}
 
-			command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+			command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "some-passphrase", logger)
 
 			err := command.Execute([]string{"--polling-interval", "0",
 				"--config", configFile.Name(),
@@ -205,7 +206,7 @@ var _ = Describe("ImportInstallation", func() {
 				return eaOutputs[fakeService.EnsureAvailabilityCallCount()-1], nil
 			}
 
-			command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+			command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "some-passphrase", logger)
 
 			err := command.Execute([]string{"--polling-interval", "0",
 				"--config", configFile.Name(),
@@ -235,7 +236,7 @@ var _ = Describe("ImportInstallation", func() {
 			}
 			multipart.FinalizeReturns(submission)
 
-			command = commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+			command = commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "some-passphrase", logger)
 		})
 
 		It("it retries on the specified polling interval to allow nginx time to boot up", func() {
@@ -298,7 +299,7 @@ var _ = Describe("ImportInstallation", func() {
 	Context("failure cases", func() {
 		When("the global decryption-passphrase is not provided", func() {
 			It("returns an error", func() {
-				command := commands.NewImportInstallation(multipart, fakeService, "", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "", logger)
 				err := command.Execute([]string{"--polling-interval", "0"})
 				Expect(err).To(MatchError("the global decryption-passphrase argument is required for this command"))
 			})
@@ -306,7 +307,7 @@ var _ = Describe("ImportInstallation", func() {
 
 		When("an unknown flag is provided", func() {
 			It("returns an error", func() {
-				command := commands.NewImportInstallation(multipart, fakeService, "passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "passphrase", logger)
 				err := command.Execute([]string{"--polling-interval", "0", "--badflag"})
 				Expect(err).To(MatchError("could not parse import-installation flags: flag provided but not defined: -badflag"))
 			})
@@ -314,7 +315,7 @@ var _ = Describe("ImportInstallation", func() {
 
 		When("config file cannot be opened", func() {
 			It("returns an error", func() {
-				command := commands.NewImportInstallation(multipart, fakeService, "passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "passphrase", logger)
 				err := command.Execute([]string{"--config", "something"})
 				Expect(err).To(MatchError("could not parse import-installation flags: could not load the config file: could not read file (something): open something: no such file or directory"))
 
@@ -323,7 +324,7 @@ var _ = Describe("ImportInstallation", func() {
 
 		When("the --installation flag is missing", func() {
 			It("returns an error", func() {
-				command := commands.NewImportInstallation(multipart, fakeService, "passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "passphrase", logger)
 				err := command.Execute([]string{"--polling-interval", "0"})
 				Expect(err).To(MatchError("could not parse import-installation flags: missing required flag \"--installation\""))
 			})
@@ -331,7 +332,7 @@ var _ = Describe("ImportInstallation", func() {
 
 		When("the --installation provided is a file that does not exist", func() {
 			It("returns an error", func() {
-				command := commands.NewImportInstallation(multipart, fakeService, "passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "passphrase", logger)
 				err := command.Execute([]string{"--installation", "does-not-exist.zip"})
 				Expect(err).To(MatchError("file: \"does-not-exist.zip\" does not exist. Please check the name and try again."))
 			})
@@ -350,7 +351,7 @@ var _ = Describe("ImportInstallation", func() {
 			})
 
 			It("returns an error", func() {
-				command := commands.NewImportInstallation(multipart, fakeService, "passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "passphrase", logger)
 				err := command.Execute([]string{"--installation", notZipFile})
 				Expect(err).To(MatchError(fmt.Sprintf("file: \"%s\" is not a valid zip file", notZipFile)))
 			})
@@ -367,7 +368,7 @@ var _ = Describe("ImportInstallation", func() {
 			})
 
 			It("returns an error", func() {
-				command := commands.NewImportInstallation(multipart, fakeService, "passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "passphrase", logger)
 				err := command.Execute([]string{"--installation", invalidInstallation})
 				expectedErrorTemplate := "file: \"%s\" is not a valid installation file. Validate that the provided installation file is correct, or run \"om export-installation\" and try again."
 				Expect(err).To(MatchError(fmt.Sprintf(expectedErrorTemplate, invalidInstallation)))
@@ -377,7 +378,7 @@ var _ = Describe("ImportInstallation", func() {
 		When("the ensure_availability endpoint returns an error", func() {
 			It("returns an error", func() {
 				fakeService.EnsureAvailabilityReturns(api.EnsureAvailabilityOutput{}, errors.New("some error"))
-				command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "some-passphrase", logger)
 				err := command.Execute([]string{"--polling-interval", "0", "--installation", installationFile})
 				Expect(err).To(MatchError("could not check Ops Manager status: some error"))
 			})
@@ -388,7 +389,7 @@ var _ = Describe("ImportInstallation", func() {
 				fakeService.EnsureAvailabilityReturns(api.EnsureAvailabilityOutput{
 					Status: api.EnsureAvailabilityStatusUnstarted,
 				}, nil)
-				command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "some-passphrase", logger)
 				multipart.AddFileReturns(errors.New("bad file"))
 
 				err := command.Execute([]string{"--polling-interval", "0", "--installation", installationFile})
@@ -401,7 +402,7 @@ var _ = Describe("ImportInstallation", func() {
 				fakeService.EnsureAvailabilityReturns(api.EnsureAvailabilityOutput{
 					Status: api.EnsureAvailabilityStatusUnstarted,
 				}, nil)
-				command := commands.NewImportInstallation(multipart, fakeService, "some-passphrase", logger)
+				command := commands.NewImportInstallation(interpolate.VarsSourceConfig{}, multipart, fakeService, "some-passphrase", logger)
 				fakeService.UploadInstallationAssetCollectionReturns(errors.New("some installation error"))
 
 				err := command.Execute([]string{"--polling-interval", "0", "--installation", installationFile})
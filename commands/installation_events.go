@@ -0,0 +1,194 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	InstallationEventStarted        = "installation_started"
+	InstallationEventStatusChanged  = "status_changed"
+	InstallationEventLog            = "log"
+	InstallationEventErrandStarted  = "errand_started"
+	InstallationEventErrandFinished = "errand_finished"
+	InstallationEventFinished       = "installation_finished"
+	InstallationEventDetached       = "installation_detached"
+	InstallationEventMessage        = "message"
+)
+
+// InstallationEvent is a single line of the newline-delimited JSON
+// emitted by `apply-changes --output json`.
+type InstallationEvent struct {
+	Type            string    `json:"type"`
+	Time            time.Time `json:"time"`
+	InstallationID  int       `json:"installation_id,omitempty"`
+	Reattached      bool      `json:"reattached,omitempty"`
+	Status          string    `json:"status,omitempty"`
+	PreviousStatus  string    `json:"previous_status,omitempty"`
+	Log             string    `json:"log,omitempty"`
+	Errand          string    `json:"errand,omitempty"`
+	Deployment      string    `json:"deployment,omitempty"`
+	ExitStatus      *int      `json:"exit_status,omitempty"`
+	DurationSeconds *float64  `json:"duration_seconds,omitempty"`
+	Message         string    `json:"message,omitempty"`
+}
+
+// Ops Manager wraps every bosh command it runs with lines like:
+//   ===== 2020-07-01 17:44:55 UTC Running "/usr/local/bin/bosh ... run-errand smoke_tests"
+//   ===== 2020-07-01 17:49:55 UTC Finished "/usr/local/bin/bosh ... run-errand smoke_tests"; Duration: 300s; Exit Status: 0
+var (
+	errandLogLineRegex  = regexp.MustCompile(`^===== .* (Running|Finished) "(.*\brun-errand\s+(\S+).*)"(?:; Duration: (\d+)s; Exit Status: (\d+))?`)
+	deploymentFlagRegex = regexp.MustCompile(`(?:--deployment[= ]|-d\s+)(\S+)`)
+)
+
+type installationEventStream struct {
+	logger         logger
+	now            func() time.Time
+	installationID int
+	startedAt      time.Time
+	status         string
+	logOffset      int
+	partialLine    string
+}
+
+func newInstallationEventStream(logger logger, installationID int, startedAt time.Time) *installationEventStream {
+	return &installationEventStream{
+		logger:         logger,
+		now:            time.Now,
+		installationID: installationID,
+		startedAt:      startedAt,
+	}
+}
+
+func (s *installationEventStream) started(reattached bool) error {
+	return s.emit(InstallationEvent{
+		Type:       InstallationEventStarted,
+		Reattached: reattached,
+	})
+}
+
+func (s *installationEventStream) statusChanged(status string) error {
+	if status == s.status {
+		return nil
+	}
+
+	previous := s.status
+	s.status = status
+
+	return s.emit(InstallationEvent{
+		Type:           InstallationEventStatusChanged,
+		Status:         status,
+		PreviousStatus: previous,
+	})
+}
+
+// logs accepts the full installation log as returned by Ops Manager
+// and emits only the part that has not been seen yet.
+func (s *installationEventStream) logs(logs string) error {
+	if len(logs) <= s.logOffset {
+		return nil
+	}
+
+	chunk := logs[s.logOffset:]
+	s.logOffset = len(logs)
+
+	err := s.emit(InstallationEvent{
+		Type: InstallationEventLog,
+		Log:  chunk,
+	})
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(s.partialLine+chunk, "\n")
+	s.partialLine = lines[len(lines)-1]
+
+	for _, line := range lines[:len(lines)-1] {
+		err = s.errandLine(line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *installationEventStream) finished(status string) error {
+	duration := s.now().Sub(s.startedAt).Seconds()
+
+	return s.emit(InstallationEvent{
+		Type:            InstallationEventFinished,
+		Status:          status,
+		DurationSeconds: &duration,
+	})
+}
+
+func (s *installationEventStream) detached(reason string) error {
+	duration := s.now().Sub(s.startedAt).Seconds()
+
+	return s.emit(InstallationEvent{
+		Type:            InstallationEventDetached,
+		Status:          s.status,
+		DurationSeconds: &duration,
+		Message:         reason,
+	})
+}
+
+func (s *installationEventStream) message(format string, v ...interface{}) error {
+	return s.emit(InstallationEvent{
+		Type:    InstallationEventMessage,
+		Message: fmt.Sprintf(format, v...),
+	})
+}
+
+func (s *installationEventStream) errandLine(line string) error {
+	matches := errandLogLineRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+	if matches == nil {
+		return nil
+	}
+
+	event := InstallationEvent{
+		Type:   InstallationEventErrandStarted,
+		Errand: matches[3],
+	}
+
+	if deployment := deploymentFlagRegex.FindStringSubmatch(matches[2]); deployment != nil {
+		event.Deployment = deployment[1]
+	}
+
+	if matches[1] == "Finished" {
+		event.Type = InstallationEventErrandFinished
+
+		if matches[4] != "" {
+			duration, _ := strconv.ParseFloat(matches[4], 64)
+			event.DurationSeconds = &duration
+		}
+
+		if matches[5] != "" {
+			exitStatus, _ := strconv.Atoi(matches[5])
+			event.ExitStatus = &exitStatus
+		}
+	}
+
+	return s.emit(event)
+}
+
+func (s *installationEventStream) emit(event InstallationEvent) error {
+	event.Time = s.now().UTC()
+	if event.InstallationID == 0 {
+		event.InstallationID = s.installationID
+	}
+
+	contents, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("could not marshal installation event: %s", err)
+	}
+
+	s.logger.Println(string(contents))
+
+	return nil
+}
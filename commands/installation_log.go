@@ -5,13 +5,16 @@ import (
 
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+	"github.com/pivotal-cf/om/presenters"
 )
 
 type InstallationLog struct {
-	service installationLogService
-	logger  logger
-	Options struct {
-		Id int `long:"id" required:"true" description:"id of the installation to retrieve logs for"`
+	service   installationLogService
+	presenter presenters.FormattedPresenter
+	Options   struct {
+		Id     int    `long:"id"     required:"true"       description:"id of the installation to retrieve logs for"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>); table prints the raw log"`
 	}
 }
 
@@ -20,10 +23,10 @@ type installationLogService interface {
 	GetInstallationLogs(id int) (api.InstallationsServiceOutput, error)
 }
 
-func NewInstallationLog(service installationLogService, logger logger) InstallationLog {
+func NewInstallationLog(service installationLogService, presenter presenters.FormattedPresenter) InstallationLog {
 	return InstallationLog{
-		service: service,
-		logger:  logger,
+		service:   service,
+		presenter: presenter,
 	}
 }
 
@@ -32,11 +35,17 @@ func (i InstallationLog) Execute(args []string) error {
 		return fmt.Errorf("could not parse installation-log flags: %s", err)
 	}
 
+	err := i.presenter.SetFormat(i.Options.Format)
+	if err != nil {
+		return err
+	}
+
 	output, err := i.service.GetInstallationLogs(i.Options.Id)
 	if err != nil {
 		return err
 	}
-	i.logger.Print(output.Logs)
+
+	i.presenter.PresentInstallationLog(models.InstallationLog{ID: i.Options.Id, Logs: output.Logs})
 	return nil
 }
 
@@ -8,19 +8,21 @@ import (
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/models"
+	presenterfakes "github.com/pivotal-cf/om/presenters/fakes"
 )
 
 var _ = Describe("InstallationLog", func() {
 	var (
-		command     commands.InstallationLog
-		fakeService *fakes.InstallationLogService
-		logger      *fakes.Logger
+		command       commands.InstallationLog
+		fakeService   *fakes.InstallationLogService
+		fakePresenter *presenterfakes.FormattedPresenter
 	)
 
 	BeforeEach(func() {
-		logger = &fakes.Logger{}
+		fakePresenter = &presenterfakes.FormattedPresenter{}
 		fakeService = &fakes.InstallationLogService{}
-		command = commands.NewInstallationLog(fakeService, logger)
+		command = commands.NewInstallationLog(fakeService, fakePresenter)
 	})
 
 	Describe("Execute", func() {
@@ -36,9 +38,16 @@ var _ = Describe("InstallationLog", func() {
 			requestedInstallationId := fakeService.GetInstallationLogsArgsForCall(0)
 			Expect(requestedInstallationId).To(Equal(999))
 
-			Expect(logger.PrintCallCount()).To(Equal(1))
-			outputLogs := logger.PrintArgsForCall(0)[0]
-			Expect(outputLogs).To(Equal("some log output"))
+			Expect(fakePresenter.SetFormatArgsForCall(0)).To(Equal("table"))
+			Expect(fakePresenter.PresentInstallationLogCallCount()).To(Equal(1))
+			Expect(fakePresenter.PresentInstallationLogArgsForCall(0)).To(Equal(models.InstallationLog{ID: 999, Logs: "some log output"}))
+		})
+
+		It("presents the logs in the requested format", func() {
+			err := command.Execute([]string{"--id", "999", "--format", "json"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakePresenter.SetFormatArgsForCall(0)).To(Equal("json"))
 		})
 
 		Context("Failure cases", func() {
@@ -54,6 +63,14 @@ var _ = Describe("InstallationLog", func() {
 					Expect(err).To(MatchError("could not parse installation-log flags: missing required flag \"--id\""))
 				})
 			})
+			When("the format is not supported", func() {
+				It("returns an error", func() {
+					fakePresenter.SetFormatReturns(errors.New("unknown format"))
+					err := command.Execute([]string{"--id", "999", "--format", "xml"})
+					Expect(err).To(MatchError("unknown format"))
+					Expect(fakeService.GetInstallationLogsCallCount()).To(Equal(0))
+				})
+			})
 			When("the api fails to retrieve the installation log", func() {
 				It("returns an error", func() {
 					fakeService.GetInstallationLogsReturns(
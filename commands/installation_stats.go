@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+	"github.com/pivotal-cf/om/presenters"
+)
+
+//counterfeiter:generate -o ./fakes/installation_stats_service.go --fake-name InstallationStatsService . installationStatsService
+type installationStatsService interface {
+	ListInstallations() ([]api.InstallationsServiceOutput, error)
+}
+
+type InstallationStats struct {
+	service   installationStatsService
+	presenter presenters.FormattedPresenter
+	now       func() time.Time
+	Options   struct {
+		Since   string `long:"since"   description:"start of the time window, as a date (2006-01-02) or an RFC3339 time (defaults to 90 days before --until)"`
+		Until   string `long:"until"   description:"end of the time window, as a date (2006-01-02) or an RFC3339 time (defaults to now)"`
+		Longest int    `long:"longest" default:"5" description:"number of longest installations to list"`
+		Format  string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
+	}
+}
+
+func NewInstallationStats(service installationStatsService, presenter presenters.FormattedPresenter, now func() time.Time) InstallationStats {
+	return InstallationStats{
+		service:   service,
+		presenter: presenter,
+		now:       now,
+	}
+}
+
+func (i InstallationStats) Execute(args []string) error {
+	if _, err := jhanda.Parse(&i.Options, args); err != nil {
+		return fmt.Errorf("could not parse installation-stats flags: %s", err)
+	}
+
+	until := i.now()
+	if i.Options.Until != "" {
+		var err error
+		until, err = parseTimeOrDate(i.Options.Until)
+		if err != nil {
+			return fmt.Errorf("could not parse --until: %s", err)
+		}
+	}
+
+	since := until.AddDate(0, 0, -90)
+	if i.Options.Since != "" {
+		var err error
+		since, err = parseTimeOrDate(i.Options.Since)
+		if err != nil {
+			return fmt.Errorf("could not parse --since: %s", err)
+		}
+	}
+
+	if i.Options.Longest < 0 {
+		return fmt.Errorf("--longest must not be negative, got %d", i.Options.Longest)
+	}
+
+	if !since.Before(until) {
+		return fmt.Errorf("--since (%s) must be before --until (%s)", since.Format(time.RFC3339), until.Format(time.RFC3339))
+	}
+
+	err := i.presenter.SetFormat(i.Options.Format)
+	if err != nil {
+		return err
+	}
+
+	installations, err := i.service.ListInstallations()
+	if err != nil {
+		return fmt.Errorf("failed to list installations: %s", err)
+	}
+
+	i.presenter.PresentInstallationStats(installationStats(installations, since, until, i.Options.Longest))
+
+	return nil
+}
+
+func parseTimeOrDate(value string) (time.Time, error) {
+	timestamp, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return timestamp, nil
+	}
+
+	timestamp, err = time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date (2006-01-02) nor an RFC3339 time", value)
+	}
+
+	return timestamp, nil
+}
+
+// installationStats considers the installations started within [since, until).
+func installationStats(installations []api.InstallationsServiceOutput, since, until time.Time, longest int) models.InstallationStats {
+	var (
+		all       []api.InstallationsServiceOutput
+		finished  []models.InstallationDuration
+		userNames []string
+	)
+	byUser := map[string][]api.InstallationsServiceOutput{}
+
+	for _, installation := range installations {
+		if installation.StartedAt == nil || installation.StartedAt.Before(since) || !installation.StartedAt.Before(until) {
+			continue
+		}
+
+		all = append(all, installation)
+
+		if _, ok := byUser[installation.UserName]; !ok {
+			userNames = append(userNames, installation.UserName)
+		}
+		byUser[installation.UserName] = append(byUser[installation.UserName], installation)
+
+		if duration, ok := installationDuration(installation); ok {
+			finished = append(finished, models.InstallationDuration{
+				Installation: models.Installation{
+					Id:         installation.ID,
+					User:       installation.UserName,
+					Status:     installation.Status,
+					StartedAt:  installation.StartedAt,
+					FinishedAt: installation.FinishedAt,
+				},
+				DurationSeconds: duration,
+			})
+		}
+	}
+
+	users := []models.UserInstallationStats{}
+	for _, name := range userNames {
+		users = append(users, models.UserInstallationStats{
+			User:               name,
+			InstallationCounts: installationCounts(byUser[name]),
+		})
+	}
+	sort.SliceStable(users, func(a, b int) bool {
+		if users[a].Installations != users[b].Installations {
+			return users[a].Installations > users[b].Installations
+		}
+		return users[a].User < users[b].User
+	})
+
+	sort.SliceStable(finished, func(a, b int) bool {
+		return finished[a].DurationSeconds > finished[b].DurationSeconds
+	})
+	if len(finished) > longest {
+		finished = finished[:longest]
+	}
+	if finished == nil {
+		finished = []models.InstallationDuration{}
+	}
+
+	return models.InstallationStats{
+		Since:              since,
+		Until:              until,
+		InstallationCounts: installationCounts(all),
+		Longest:            finished,
+		Users:              users,
+	}
+}
+
+func installationCounts(installations []api.InstallationsServiceOutput) models.InstallationCounts {
+	counts := models.InstallationCounts{Installations: len(installations)}
+
+	var durations []float64
+	for _, installation := range installations {
+		switch installation.Status {
+		case api.StatusSucceeded:
+			counts.Succeeded++
+		case api.StatusFailed:
+			counts.Failed++
+		}
+
+		if duration, ok := installationDuration(installation); ok {
+			durations = append(durations, duration)
+		}
+	}
+
+	if finished := counts.Succeeded + counts.Failed; finished > 0 {
+		counts.SuccessRate = float64(counts.Succeeded) / float64(finished)
+	}
+
+	if len(durations) > 0 {
+		sort.Float64s(durations)
+
+		var total float64
+		for _, duration := range durations {
+			total += duration
+		}
+		counts.MeanDurationSeconds = total / float64(len(durations))
+
+		// the nearest-rank percentile: the smallest duration
+		// at least 95% of the durations are lower than or equal to
+		rank := int(math.Ceil(0.95 * float64(len(durations))))
+		counts.P95DurationSeconds = durations[rank-1]
+	}
+
+	return counts
+}
+
+// installationDuration is only known for the installations
+// that have succeeded or failed.
+func installationDuration(installation api.InstallationsServiceOutput) (float64, bool) {
+	if installation.Status != api.StatusSucceeded && installation.Status != api.StatusFailed {
+		return 0, false
+	}
+	if installation.StartedAt == nil || installation.FinishedAt == nil {
+		return 0, false
+	}
+
+	return installation.FinishedAt.Sub(*installation.StartedAt).Seconds(), true
+}
+
+func (i InstallationStats) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command computes statistics over the installations started within a time window: the success rate, the mean and 95th percentile duration of the finished installations, the longest installations, and the counts per user.",
+		ShortDescription: "computes statistics over the installations",
+		Flags:            i.Options,
+	}
+}
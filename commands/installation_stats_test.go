@@ -0,0 +1,161 @@
+package commands_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/models"
+	presenterfakes "github.com/pivotal-cf/om/presenters/fakes"
+)
+
+var _ = Describe("InstallationStats", func() {
+	var (
+		command       commands.InstallationStats
+		fakeService   *fakes.InstallationStatsService
+		fakePresenter *presenterfakes.FormattedPresenter
+		now           time.Time
+	)
+
+	at := func(value string) *time.Time {
+		timestamp, err := time.Parse(time.RFC3339, value)
+		Expect(err).ToNot(HaveOccurred())
+		return &timestamp
+	}
+
+	installation := func(id int, user, status, startedAt string, minutes int) api.InstallationsServiceOutput {
+		started := at(startedAt)
+		output := api.InstallationsServiceOutput{ID: id, UserName: user, Status: status, StartedAt: started}
+		if minutes > 0 {
+			finished := started.Add(time.Duration(minutes) * time.Minute)
+			output.FinishedAt = &finished
+		}
+		return output
+	}
+
+	BeforeEach(func() {
+		fakeService = &fakes.InstallationStatsService{}
+		fakePresenter = &presenterfakes.FormattedPresenter{}
+		now = *at("2020-07-01T00:00:00Z")
+		command = commands.NewInstallationStats(fakeService, fakePresenter, func() time.Time { return now })
+
+		fakeService.ListInstallationsReturns([]api.InstallationsServiceOutput{
+			installation(7, "admin", "running", "2020-06-30T10:00:00Z", 0),
+			installation(6, "ci", "succeeded", "2020-06-20T10:00:00Z", 60),
+			installation(5, "ci", "failed", "2020-06-10T10:00:00Z", 20),
+			installation(4, "admin", "succeeded", "2020-05-01T10:00:00Z", 120),
+			installation(3, "ci", "succeeded", "2020-04-15T10:00:00Z", 40),
+			installation(2, "ci", "succeeded", "2020-03-01T10:00:00Z", 600),
+			{ID: 1, Status: "succeeded"},
+		}, nil)
+	})
+
+	It("computes the stats of the installations of the last 90 days by default", func() {
+		err := command.Execute([]string{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakePresenter.SetFormatArgsForCall(0)).To(Equal("table"))
+		Expect(fakePresenter.PresentInstallationStatsCallCount()).To(Equal(1))
+
+		stats := fakePresenter.PresentInstallationStatsArgsForCall(0)
+		Expect(stats.Since).To(Equal(*at("2020-04-02T00:00:00Z")))
+		Expect(stats.Until).To(Equal(now))
+		Expect(stats.InstallationCounts).To(Equal(models.InstallationCounts{
+			Installations:       5,
+			Succeeded:           3,
+			Failed:              1,
+			SuccessRate:         0.75,
+			MeanDurationSeconds: 60 * 60,
+			P95DurationSeconds:  120 * 60,
+		}))
+
+		Expect(stats.Users).To(Equal([]models.UserInstallationStats{
+			{User: "ci", InstallationCounts: models.InstallationCounts{
+				Installations:       3,
+				Succeeded:           2,
+				Failed:              1,
+				SuccessRate:         2.0 / 3.0,
+				MeanDurationSeconds: 40 * 60,
+				P95DurationSeconds:  60 * 60,
+			}},
+			{User: "admin", InstallationCounts: models.InstallationCounts{
+				Installations:       2,
+				Succeeded:           1,
+				SuccessRate:         1,
+				MeanDurationSeconds: 120 * 60,
+				P95DurationSeconds:  120 * 60,
+			}},
+		}))
+
+		Expect(stats.Longest).To(HaveLen(4))
+		Expect(stats.Longest[0].Id).To(Equal(4))
+		Expect(stats.Longest[0].User).To(Equal("admin"))
+		Expect(stats.Longest[0].DurationSeconds).To(Equal(120.0 * 60))
+		Expect(stats.Longest[3].Id).To(Equal(5))
+	})
+
+	It("considers the requested window and number of longest installations", func() {
+		err := command.Execute([]string{"--since", "2020-01-01", "--until", "2020-06-15T00:00:00Z", "--longest", "1", "--format", "json"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakePresenter.SetFormatArgsForCall(0)).To(Equal("json"))
+
+		stats := fakePresenter.PresentInstallationStatsArgsForCall(0)
+		Expect(stats.Since).To(Equal(*at("2020-01-01T00:00:00Z")))
+		Expect(stats.Installations).To(Equal(4))
+		Expect(stats.Longest).To(HaveLen(1))
+		Expect(stats.Longest[0].Id).To(Equal(2))
+	})
+
+	It("presents empty stats when no installation started within the window", func() {
+		err := command.Execute([]string{"--since", "2019-01-01", "--until", "2019-02-01"})
+		Expect(err).ToNot(HaveOccurred())
+
+		stats := fakePresenter.PresentInstallationStatsArgsForCall(0)
+		Expect(stats.InstallationCounts).To(Equal(models.InstallationCounts{}))
+		Expect(stats.Longest).To(BeEmpty())
+		Expect(stats.Users).To(BeEmpty())
+	})
+
+	Context("failure cases", func() {
+		It("returns an error for an unparseable time", func() {
+			err := command.Execute([]string{"--since", "last quarter"})
+			Expect(err).To(MatchError(`could not parse --since: "last quarter" is neither a date (2006-01-02) nor an RFC3339 time`))
+		})
+
+		It("returns an error when the window is empty", func() {
+			err := command.Execute([]string{"--since", "2020-02-01", "--until", "2020-01-01"})
+			Expect(err).To(MatchError("--since (2020-02-01T00:00:00Z) must be before --until (2020-01-01T00:00:00Z)"))
+		})
+
+		It("returns an error for a negative number of longest installations", func() {
+			err := command.Execute([]string{"--longest", "-1"})
+			Expect(err).To(MatchError("--longest must not be negative, got -1"))
+			Expect(fakeService.ListInstallationsCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when the installations cannot be listed", func() {
+			fakeService.ListInstallationsReturns(nil, errors.New("boom"))
+
+			err := command.Execute([]string{})
+			Expect(err).To(MatchError("failed to list installations: boom"))
+		})
+
+		It("returns an error for an unsupported format", func() {
+			fakePresenter.SetFormatReturns(errors.New("unknown format"))
+
+			err := command.Execute([]string{"--format", "xml"})
+			Expect(err).To(MatchError("unknown format"))
+			Expect(fakeService.ListInstallationsCallCount()).To(Equal(0))
+		})
+
+		It("returns an error for an unknown flag", func() {
+			err := command.Execute([]string{"--window", "90d"})
+			Expect(err).To(MatchError("could not parse installation-stats flags: flag provided but not defined: -window"))
+		})
+	})
+})
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/installationlog"
+	"github.com/pivotal-cf/om/models"
+	"github.com/pivotal-cf/om/presenters"
+)
+
+//counterfeiter:generate -o ./fakes/installation_summary_service.go --fake-name InstallationSummaryService . installationSummaryService
+type installationSummaryService interface {
+	GetInstallation(id int) (api.InstallationsServiceOutput, error)
+	GetInstallationLogs(id int) (api.InstallationsServiceOutput, error)
+	ListInstallations() ([]api.InstallationsServiceOutput, error)
+}
+
+type InstallationSummary struct {
+	service   installationSummaryService
+	presenter presenters.FormattedPresenter
+	Options   struct {
+		Id         int    `long:"id"          description:"id of the installation to summarize (defaults to the most recent installation)"`
+		ErrorLines int    `long:"error-lines" default:"10" description:"maximum number of error lines to show for every failed stage"`
+		Format     string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
+	}
+}
+
+func NewInstallationSummary(service installationSummaryService, presenter presenters.FormattedPresenter) InstallationSummary {
+	return InstallationSummary{
+		service:   service,
+		presenter: presenter,
+	}
+}
+
+func (i InstallationSummary) Execute(args []string) error {
+	if _, err := jhanda.Parse(&i.Options, args); err != nil {
+		return fmt.Errorf("could not parse installation-summary flags: %s", err)
+	}
+
+	err := i.presenter.SetFormat(i.Options.Format)
+	if err != nil {
+		return err
+	}
+
+	var installation api.InstallationsServiceOutput
+	if i.Options.Id == 0 {
+		installations, err := i.service.ListInstallations()
+		if err != nil {
+			return fmt.Errorf("failed to list installations: %s", err)
+		}
+		if len(installations) == 0 {
+			return errors.New("there are no installations to summarize")
+		}
+
+		installation = installations[0]
+	} else {
+		installation, err = i.service.GetInstallation(i.Options.Id)
+		if err != nil {
+			return fmt.Errorf("failed to get installation %d: %s", i.Options.Id, err)
+		}
+		installation.ID = i.Options.Id
+	}
+
+	output, err := i.service.GetInstallationLogs(installation.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get the log of installation %d: %s", installation.ID, err)
+	}
+
+	stages := installationlog.Parse(output.Logs, i.Options.ErrorLines)
+	if stages == nil {
+		stages = []models.InstallationStage{}
+	}
+
+	i.presenter.PresentInstallationSummary(models.InstallationSummary{
+		ID:     installation.ID,
+		Status: installation.Status,
+		Stages: stages,
+	})
+
+	return nil
+}
+
+func (i InstallationSummary) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command splits the log of an installation into its stages (the director deploy, the bosh deploy of every product and the update of its instance groups, errands and other bosh commands) and shows the duration and outcome of each, followed by the first error lines of the failed stages.",
+		ShortDescription: "summarizes the stages of an installation",
+		Flags:            i.Options,
+	}
+}
@@ -0,0 +1,112 @@
+package commands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/models"
+	presenterfakes "github.com/pivotal-cf/om/presenters/fakes"
+)
+
+var _ = Describe("InstallationSummary", func() {
+	var (
+		command       commands.InstallationSummary
+		fakeService   *fakes.InstallationSummaryService
+		fakePresenter *presenterfakes.FormattedPresenter
+	)
+
+	const log = `===== 2020-07-01 17:44:55 UTC Running "/usr/local/bin/bosh --deployment=cf-1234 run-errand smoke_tests"
+Error: smoke tests failed
+more context
+===== 2020-07-01 17:49:55 UTC Finished "/usr/local/bin/bosh --deployment=cf-1234 run-errand smoke_tests"; Duration: 300s; Exit Status: 1
+`
+
+	BeforeEach(func() {
+		fakeService = &fakes.InstallationSummaryService{}
+		fakePresenter = &presenterfakes.FormattedPresenter{}
+		command = commands.NewInstallationSummary(fakeService, fakePresenter)
+
+		fakeService.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: log}, nil)
+	})
+
+	It("summarizes the most recent installation by default", func() {
+		fakeService.ListInstallationsReturns([]api.InstallationsServiceOutput{
+			{ID: 12, Status: "failed"},
+			{ID: 11, Status: "succeeded"},
+		}, nil)
+
+		err := command.Execute([]string{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakePresenter.SetFormatArgsForCall(0)).To(Equal("table"))
+		Expect(fakeService.GetInstallationLogsArgsForCall(0)).To(Equal(12))
+
+		Expect(fakePresenter.PresentInstallationSummaryCallCount()).To(Equal(1))
+		summary := fakePresenter.PresentInstallationSummaryArgsForCall(0)
+		Expect(summary.ID).To(Equal(12))
+		Expect(summary.Status).To(Equal("failed"))
+		Expect(summary.Stages).To(HaveLen(1))
+		Expect(summary.Stages[0].Name).To(Equal("smoke_tests"))
+		Expect(summary.Stages[0].Outcome).To(Equal(models.InstallationStageFailed))
+		Expect(summary.Stages[0].ErrorLines).To(Equal([]string{"Error: smoke tests failed"}))
+	})
+
+	It("summarizes the requested installation", func() {
+		fakeService.GetInstallationReturns(api.InstallationsServiceOutput{Status: "succeeded"}, nil)
+
+		err := command.Execute([]string{"--id", "7", "--format", "json", "--error-lines", "0"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeService.ListInstallationsCallCount()).To(Equal(0))
+		Expect(fakeService.GetInstallationArgsForCall(0)).To(Equal(7))
+		Expect(fakeService.GetInstallationLogsArgsForCall(0)).To(Equal(7))
+		Expect(fakePresenter.SetFormatArgsForCall(0)).To(Equal("json"))
+
+		summary := fakePresenter.PresentInstallationSummaryArgsForCall(0)
+		Expect(summary.ID).To(Equal(7))
+		Expect(summary.Status).To(Equal("succeeded"))
+		Expect(summary.Stages[0].ErrorLines).To(BeEmpty())
+	})
+
+	It("presents no stages for a log without bosh commands", func() {
+		fakeService.ListInstallationsReturns([]api.InstallationsServiceOutput{{ID: 1}}, nil)
+		fakeService.GetInstallationLogsReturns(api.InstallationsServiceOutput{}, nil)
+
+		err := command.Execute([]string{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakePresenter.PresentInstallationSummaryArgsForCall(0).Stages).To(Equal([]models.InstallationStage{}))
+	})
+
+	Context("failure cases", func() {
+		It("returns an error when there are no installations", func() {
+			err := command.Execute([]string{})
+			Expect(err).To(MatchError("there are no installations to summarize"))
+		})
+
+		It("returns an error when the log cannot be fetched", func() {
+			fakeService.GetInstallationReturns(api.InstallationsServiceOutput{}, nil)
+			fakeService.GetInstallationLogsReturns(api.InstallationsServiceOutput{}, errors.New("not found"))
+
+			err := command.Execute([]string{"--id", "7"})
+			Expect(err).To(MatchError("failed to get the log of installation 7: not found"))
+		})
+
+		It("returns an error for an unsupported format before fetching anything", func() {
+			fakePresenter.SetFormatReturns(errors.New("unknown format"))
+
+			err := command.Execute([]string{"--format", "xml"})
+			Expect(err).To(MatchError("unknown format"))
+			Expect(fakeService.ListInstallationsCallCount()).To(Equal(0))
+		})
+
+		It("returns an error for an unknown flag", func() {
+			err := command.Execute([]string{"--since", "yesterday"})
+			Expect(err).To(MatchError("could not parse installation-summary flags: flag provided but not defined: -since"))
+		})
+	})
+})
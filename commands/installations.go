@@ -13,7 +13,7 @@ type Installations struct {
 	service   installationsService
 	presenter presenters.FormattedPresenter
 	Options   struct {
-		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -50,7 +50,10 @@ func (i Installations) Execute(args []string) error {
 		})
 	}
 
-	i.presenter.SetFormat(i.Options.Format)
+	err = i.presenter.SetFormat(i.Options.Format)
+	if err != nil {
+		return err
+	}
 	i.presenter.PresentInstallations(installations)
 
 	return nil
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/interpolate"
+	"gopkg.in/yaml.v2"
 	"io/ioutil"
 	"os"
 )
@@ -13,6 +14,7 @@ type interpolateOptions struct {
 	VarsEnv    []string `long:"vars-env" env:"OM_VARS_ENV" description:"load variables from environment variables matching the provided prefix (e.g.: 'MY' to load MY_var=value)"`
 	VarsFile   []string `long:"vars-file"    short:"l"     description:"load variables from a YAML file"`
 	Vars       []string `long:"var"          short:"v"     description:"load variable from the command line. Format: VAR=VAL"`
+	VarsSource []string `long:"vars-source"                description:"load the variables missing from the other options from a secret store (e.g.: 'vault://secret/foundation' for the secrets under that path of Vault, or 'credhub://concourse/main' for the credentials under that prefix of CredHub)"`
 }
 
 type interpolateConfigFileOptions struct {
@@ -23,22 +25,25 @@ type interpolateConfigFileOptions struct {
 }
 
 type Interpolate struct {
-	environFunc func() []string
-	logger      logger
-	input       *os.File
-	Options     struct {
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	logger           logger
+	input            *os.File
+	Options          struct {
 		interpolateOptions
 		Path              string   `long:"path"                       description:"extract specified value out of the interpolated file (e.g.: /private_key). The rest of the file will not be printed."`
 		OpsFile           []string `long:"ops-file"     short:"o"     description:"YAML operations files"`
 		SkipMissingParams bool     `long:"skip-missing" short:"s"     description:"allow skipping missing params"`
+		Report            bool     `long:"report"                     description:"instead of the interpolated file, print where each variable is resolved from, which are missing, and which supplied variables are unused"`
 	}
 }
 
-func NewInterpolate(environFunc func() []string, logger logger, input *os.File) Interpolate {
+func NewInterpolate(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, logger logger, input *os.File) Interpolate {
 	return Interpolate{
-		environFunc: environFunc,
-		logger:      logger,
-		input:       input,
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		logger:           logger,
+		input:            input,
 	}
 }
 
@@ -47,6 +52,8 @@ func (c Interpolate) Execute(args []string) error {
 		return fmt.Errorf("could not parse interpolate flags: %s", err)
 	}
 
+	configName := c.Options.ConfigFile
+
 	info, err := c.input.Stat()
 	if err != nil {
 		return fmt.Errorf("error in STDIN: %s", err)
@@ -73,6 +80,7 @@ func (c Interpolate) Execute(args []string) error {
 		}
 
 		c.Options.ConfigFile = tempFile.Name()
+		configName = "-"
 
 	} else if len(c.Options.ConfigFile) == 0 || c.Options.ConfigFile == "-" {
 		return fmt.Errorf("no file or STDIN input provided. Please provide a valid --config file or use a pipe to get STDIN")
@@ -83,16 +91,24 @@ func (c Interpolate) Execute(args []string) error {
 		expectAllKeys = false
 	}
 
-	bytes, err := interpolate.Execute(interpolate.Options{
-		TemplateFile:  c.Options.ConfigFile,
-		VarsFiles:     c.Options.VarsFile,
-		Vars:          c.Options.Vars,
-		EnvironFunc:   c.environFunc,
-		VarsEnvs:      c.Options.VarsEnv,
-		OpsFiles:      c.Options.OpsFile,
-		ExpectAllKeys: expectAllKeys,
-		Path:          c.Options.Path,
-	})
+	options := interpolate.Options{
+		TemplateFile:     c.Options.ConfigFile,
+		VarsFiles:        c.Options.VarsFile,
+		Vars:             c.Options.Vars,
+		EnvironFunc:      c.environFunc,
+		VarsEnvs:         c.Options.VarsEnv,
+		VarsSources:      c.Options.VarsSource,
+		VarsSourceConfig: c.varsSourceConfig,
+		OpsFiles:         c.Options.OpsFile,
+		ExpectAllKeys:    expectAllKeys,
+		Path:             c.Options.Path,
+	}
+
+	if c.Options.Report {
+		return c.report(options, configName)
+	}
+
+	bytes, err := interpolate.Execute(options)
 	if err != nil {
 		return err
 	}
@@ -102,6 +118,34 @@ func (c Interpolate) Execute(args []string) error {
 	return nil
 }
 
+// report prints the report of the variables as YAML,
+// naming the config by its flag rather than by the temp file of STDIN.
+func (c Interpolate) report(options interpolate.Options, configName string) error {
+	report, err := interpolate.ReportVariables(options)
+	if err != nil {
+		return err
+	}
+
+	for _, variables := range [][]interpolate.ReportedVariable{report.Resolved, report.Missing} {
+		for _, variable := range variables {
+			for i, path := range variable.FoundIn {
+				if path == options.TemplateFile {
+					variable.FoundIn[i] = configName
+				}
+			}
+		}
+	}
+
+	contents, err := yaml.Marshal(report)
+	if err != nil {
+		return fmt.Errorf("could not marshal the report of the variables: %s", err)
+	}
+
+	c.logger.Print(string(contents))
+
+	return nil
+}
+
 func (c Interpolate) Usage() jhanda.Usage {
 	return jhanda.Usage{
 		Description:      "interpolates variables into a manifest",
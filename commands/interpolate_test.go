@@ -1,11 +1,16 @@
 package commands_test
 
 import (
+	"fmt"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
+	"github.com/pivotal-cf/om/vault"
 	"io/ioutil"
+	"net/http"
 	"os"
 )
 
@@ -37,7 +42,7 @@ var _ = Describe("Interpolate", func() {
 		err = ioutil.WriteFile(stdin.Name(), []byte(templateNoParametersOverStdin), os.ModeCharDevice|0755) // mimic a character device so it'll be picked up in the conditional
 		Expect(err).ToNot(HaveOccurred())
 		logger = &fakes.Logger{}
-		command = commands.NewInterpolate(func() []string { return nil }, logger, stdin)
+		command = commands.NewInterpolate(func() []string { return nil }, interpolate.VarsSourceConfig{}, logger, stdin)
 	})
 
 	AfterEach(func() {
@@ -207,6 +212,47 @@ hello: world`))
 			})
 		})
 
+		Context("with vars source input", func() {
+			var server *ghttp.Server
+
+			BeforeEach(func() {
+				server = ghttp.NewServer()
+				server.RouteToHandler("GET", "/v1/sys/internal/ui/mounts/secret/foundation", ghttp.RespondWith(http.StatusOK, `{"data": {"path": "secret/", "options": {"version": "2"}}}`))
+				server.RouteToHandler("GET", "/v1/secret/data/foundation/hello", ghttp.RespondWith(http.StatusOK, `{"data": {"data": {"value": "vault"}}}`))
+			})
+
+			AfterEach(func() {
+				server.Close()
+			})
+
+			It("succeeds", func() {
+				command = commands.NewInterpolate(func() []string { return nil }, interpolate.VarsSourceConfig{
+					Vault: vault.Config{Address: server.URL(), Token: "some-token"},
+				}, logger, stdin)
+
+				err := ioutil.WriteFile(inputFile, []byte(templateWithParameters), 0755)
+				Expect(err).ToNot(HaveOccurred())
+				err = command.Execute([]string{
+					"--config", inputFile,
+					"--vars-source", "vault://secret/foundation",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				content := logger.PrintArgsForCall(0)
+				Expect(content[0].(string)).To(MatchYAML("hello: vault"))
+			})
+
+			It("fails without the address of Vault", func() {
+				err := ioutil.WriteFile(inputFile, []byte(templateWithParameters), 0755)
+				Expect(err).ToNot(HaveOccurred())
+				err = command.Execute([]string{
+					"--config", inputFile,
+					"--vars-source", "vault://secret/foundation",
+				})
+				Expect(err).To(MatchError(ContainSubstring("vars source vault://secret/foundation requires the address of Vault")))
+			})
+		})
+
 		When("path flag is set", func() {
 			It("returns a value from the interpolated file", func() {
 				err := ioutil.WriteFile(inputFile, []byte(`{"a": "((interpolated-value))", "c":"d" }`), 0755)
@@ -242,9 +288,54 @@ hello: world`))
 			})
 		})
 
+		When("the report flag is set", func() {
+			It("prints where the variables are resolved from instead of the interpolated file", func() {
+				err := ioutil.WriteFile(inputFile, []byte(templateWithMultipleParameters), 0755)
+				Expect(err).ToNot(HaveOccurred())
+				err = ioutil.WriteFile(varsFile, []byte("hello: world\nunused: value"), 0755)
+				Expect(err).ToNot(HaveOccurred())
+				err = command.Execute([]string{
+					"--config", inputFile,
+					"--vars-file", varsFile,
+					"--report",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				content := logger.PrintArgsForCall(0)
+				Expect(content[0].(string)).To(MatchYAML(fmt.Sprintf(`
+resolved:
+- name: hello
+  found_in: [%[1]s]
+  source: vars-file %[2]s
+missing:
+- name: world
+  found_in: [%[1]s]
+unused:
+- name: unused
+  source: vars-file %[2]s
+`, inputFile, varsFile)))
+			})
+
+			It("names the config passed via stdin -", func() {
+				err := ioutil.WriteFile(stdin.Name(), []byte(templateWithParameters), 0755)
+				Expect(err).ToNot(HaveOccurred())
+				err = command.Execute([]string{"--report"})
+				Expect(err).ToNot(HaveOccurred())
+
+				content := logger.PrintArgsForCall(0)
+				Expect(content[0].(string)).To(MatchYAML(`
+resolved: []
+missing:
+- name: hello
+  found_in: ["-"]
+unused: []
+`))
+			})
+		})
+
 		When("no flags are set and no stdin provided", func() {
 			It("errors", func() {
-				command = commands.NewInterpolate(func() []string { return nil }, logger, os.Stdin)
+				command = commands.NewInterpolate(func() []string { return nil }, interpolate.VarsSourceConfig{}, logger, os.Stdin)
 				err := command.Execute([]string{})
 				Expect(err).To(MatchError(ContainSubstring("no file or STDIN input provided.")))
 			})
@@ -252,7 +343,7 @@ hello: world`))
 
 		When("no stdin provided and --config -", func() {
 			It("errors", func() {
-				command = commands.NewInterpolate(func() []string { return nil }, logger, os.Stdin)
+				command = commands.NewInterpolate(func() []string { return nil }, interpolate.VarsSourceConfig{}, logger, os.Stdin)
 				err := command.Execute([]string{"--config", "-"})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("no file or STDIN input provided."))
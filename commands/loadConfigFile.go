@@ -14,7 +14,8 @@ import (
 // To use this function, `Config` field must be defined in the command struct being passed in.
 // To load vars, VarsFile and/or VarsEnv must exist in the command struct being passed in.
 // If VarsEnv is used, envFunc must be defined instead of nil
-func loadConfigFile(args []string, command interface{}, envFunc func() []string) error {
+// The variables missing from them are looked up in the vars sources of varsSourceConfig.
+func loadConfigFile(args []string, command interface{}, envFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig) error {
 	_, err := jhanda.Parse(command, args)
 	commandValue := reflect.ValueOf(command).Elem()
 	configFile := commandValue.FieldByName("ConfigFile").String()
@@ -54,13 +55,14 @@ func loadConfigFile(args []string, command interface{}, envFunc func() []string)
 	}
 
 	contents, err = interpolate.Execute(interpolate.Options{
-		TemplateFile:  configFile,
-		VarsEnvs:      varsEnv,
-		VarsFiles:     varsField,
-		Vars:          cmdVars,
-		EnvironFunc:   envFunc,
-		OpsFiles:      nil,
-		ExpectAllKeys: true,
+		TemplateFile:     configFile,
+		VarsEnvs:         varsEnv,
+		VarsFiles:        varsField,
+		Vars:             cmdVars,
+		EnvironFunc:      envFunc,
+		OpsFiles:         nil,
+		VarsSourceConfig: varsSourceConfig,
+		ExpectAllKeys:    true,
 	})
 	if err != nil {
 		return fmt.Errorf("could not load the config file: %s", err)
@@ -0,0 +1,75 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pivotal-cf/jhanda"
+)
+
+type Login struct {
+	service loginService
+	logger  logger
+	stdin   io.Reader
+	Options struct {
+		Passcode string `long:"passcode" description:"one-time passcode from the passcode page of the Ops Manager UAA (prompted for when not set)"`
+	}
+}
+
+//counterfeiter:generate -o ./fakes/login_service.go --fake-name LoginService . loginService
+type loginService interface {
+	PasscodeURL() (string, error)
+	Login(passcode string) error
+}
+
+func NewLogin(service loginService, logger logger, stdin io.Reader) Login {
+	return Login{
+		service: service,
+		logger:  logger,
+		stdin:   stdin,
+	}
+}
+
+func (l Login) Execute(args []string) error {
+	if _, err := jhanda.Parse(&l.Options, args); err != nil {
+		return fmt.Errorf("could not parse login flags: %s", err)
+	}
+
+	passcode := l.Options.Passcode
+	if passcode == "" {
+		passcodeURL, err := l.service.PasscodeURL()
+		if err != nil {
+			return err
+		}
+
+		l.logger.Printf("Get a one-time passcode from %s", passcodeURL)
+		l.logger.Printf("Passcode: ")
+
+		scanner := bufio.NewScanner(l.stdin)
+		scanner.Scan()
+		passcode = strings.TrimSpace(scanner.Text())
+		if passcode == "" {
+			return errors.New("a passcode is required to log in")
+		}
+	}
+
+	err := l.service.Login(passcode)
+	if err != nil {
+		return err
+	}
+
+	l.logger.Println("logged in")
+
+	return nil
+}
+
+func (l Login) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command logs in to the Ops Manager UAA with a one-time passcode, as the users of SAML have to, and stores the tokens for the subsequent commands run without a username or a client id.",
+		ShortDescription: "logs in with a one-time passcode",
+		Flags:            l.Options,
+	}
+}
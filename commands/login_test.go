@@ -0,0 +1,80 @@
+package commands_test
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("Login", func() {
+	var (
+		service *fakes.LoginService
+		logger  *fakes.Logger
+	)
+
+	BeforeEach(func() {
+		service = &fakes.LoginService{}
+		service.PasscodeURLReturns("https://opsman.example.com/uaa/passcode", nil)
+		logger = &fakes.Logger{}
+	})
+
+	It("prompts for a passcode and logs in with it", func() {
+		command := commands.NewLogin(service, logger, strings.NewReader("some-passcode\n"))
+
+		err := command.Execute([]string{})
+		Expect(err).ToNot(HaveOccurred())
+
+		format, content := logger.PrintfArgsForCall(0)
+		Expect(format).To(Equal("Get a one-time passcode from %s"))
+		Expect(content).To(Equal([]interface{}{"https://opsman.example.com/uaa/passcode"}))
+
+		Expect(service.LoginCallCount()).To(Equal(1))
+		Expect(service.LoginArgsForCall(0)).To(Equal("some-passcode"))
+		Expect(logger.PrintlnArgsForCall(0)).To(Equal([]interface{}{"logged in"}))
+	})
+
+	It("logs in with the passcode of the flag without prompting", func() {
+		command := commands.NewLogin(service, logger, strings.NewReader(""))
+
+		err := command.Execute([]string{"--passcode", "some-passcode"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(service.PasscodeURLCallCount()).To(Equal(0))
+		Expect(service.LoginArgsForCall(0)).To(Equal("some-passcode"))
+	})
+
+	It("errors without a passcode", func() {
+		command := commands.NewLogin(service, logger, strings.NewReader("\n"))
+
+		err := command.Execute([]string{})
+		Expect(err).To(MatchError("a passcode is required to log in"))
+		Expect(service.LoginCallCount()).To(Equal(0))
+	})
+
+	It("returns the errors of the passcode url", func() {
+		service.PasscodeURLReturns("", errors.New("target flag is required"))
+		command := commands.NewLogin(service, logger, strings.NewReader("some-passcode\n"))
+
+		err := command.Execute([]string{})
+		Expect(err).To(MatchError("target flag is required"))
+	})
+
+	It("returns the errors of the login", func() {
+		service.LoginReturns(errors.New("could not log in"))
+		command := commands.NewLogin(service, logger, strings.NewReader("some-passcode\n"))
+
+		err := command.Execute([]string{})
+		Expect(err).To(MatchError("could not log in"))
+	})
+
+	It("errors with unknown flags", func() {
+		command := commands.NewLogin(service, logger, strings.NewReader(""))
+
+		err := command.Execute([]string{"--unknown"})
+		Expect(err).To(MatchError(ContainSubstring("could not parse login flags")))
+	})
+})
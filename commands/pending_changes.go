@@ -14,7 +14,7 @@ type PendingChanges struct {
 	presenter presenters.FormattedPresenter
 	Options   struct {
 		Check  bool   `long:"check" description:"Exit 1 if there are any pending changes. Useful for validating that Ops Manager is in a clean state."`
-		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -40,7 +40,10 @@ func (pc PendingChanges) Execute(args []string) error {
 		return fmt.Errorf("failed to retrieve pending changes %s", err)
 	}
 
-	pc.presenter.SetFormat(pc.Options.Format)
+	err = pc.presenter.SetFormat(pc.Options.Format)
+	if err != nil {
+		return err
+	}
 	pc.presenter.PresentPendingChanges(output)
 
 	var errs []string
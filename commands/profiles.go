@@ -0,0 +1,226 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/profiles"
+)
+
+type Profiles struct {
+	service profilesService
+	logger  logger
+	Options struct {
+		Target                      string `long:"target"                        short:"t" description:"location of the Ops Manager VM (add)"`
+		CACert                      string `long:"ca-cert"                                 description:"OpsManager CA certificate path or value (add)"`
+		SkipSSLValidation           bool   `long:"skip-ssl-validation"           short:"k" description:"skip ssl certificate validation during http requests (add)"`
+		ClientCert                  string `long:"client-cert"                             description:"client certificate path or value, for mutual TLS (add)"`
+		ClientKey                   string `long:"client-key"                              description:"private key path or value of the client certificate (add)"`
+		Username                    string `long:"username"                      short:"u" description:"admin username for the Ops Manager VM (add)"`
+		PasswordEnv                 string `long:"password-env"                            description:"environment variable holding the password (add)"`
+		PasswordCommand             string `long:"password-command"                        description:"command printing the password (add)"`
+		ClientID                    string `long:"client-id"                     short:"c" description:"Client ID for the Ops Manager VM (add)"`
+		ClientSecretEnv             string `long:"client-secret-env"                       description:"environment variable holding the client secret (add)"`
+		ClientSecretCommand         string `long:"client-secret-command"                   description:"command printing the client secret (add)"`
+		DecryptionPassphraseEnv     string `long:"decryption-passphrase-env"               description:"environment variable holding the decryption passphrase (add)"`
+		DecryptionPassphraseCommand string `long:"decryption-passphrase-command"           description:"command printing the decryption passphrase (add)"`
+		ConnectTimeout              int    `long:"connect-timeout"               short:"o" description:"timeout in seconds to make TCP connections (add)"`
+		RequestTimeout              int    `long:"request-timeout"               short:"r" description:"timeout in seconds for HTTP requests to Ops Manager (add)"`
+	}
+}
+
+//counterfeiter:generate -o ./fakes/profiles_service.go --fake-name ProfilesService . profilesService
+type profilesService interface {
+	Load() (profiles.Config, error)
+	Save(profiles.Config) error
+	Path() string
+}
+
+func NewProfiles(service profilesService, logger logger) Profiles {
+	return Profiles{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (p Profiles) Execute(args []string) error {
+	if len(args) == 0 {
+		return errors.New("a subcommand is required: list, add, remove or use")
+	}
+
+	subcommand, args := args[0], args[1:]
+	switch subcommand {
+	case "list", "add", "remove", "use":
+	default:
+		return fmt.Errorf("unknown subcommand %q: expected list, add, remove or use", subcommand)
+	}
+
+	// the name of the profile comes before the flags, as parsing stops at the first argument
+	var name string
+	if subcommand != "list" {
+		if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+			return fmt.Errorf("profiles %s takes the name of a profile", subcommand)
+		}
+		name, args = args[0], args[1:]
+	}
+
+	rest, err := jhanda.Parse(&p.Options, args)
+	if err != nil {
+		return fmt.Errorf("could not parse profiles flags: %s", err)
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("unexpected arguments for profiles %s: %s", subcommand, strings.Join(rest, " "))
+	}
+
+	config, err := p.service.Load()
+	if err != nil {
+		return err
+	}
+
+	switch subcommand {
+	case "add":
+		return p.add(config, name)
+	case "remove":
+		return p.remove(config, name)
+	case "use":
+		return p.use(config, name)
+	default:
+		return p.list(config)
+	}
+}
+
+func (p Profiles) list(config profiles.Config) error {
+	if len(config.Profiles) == 0 {
+		p.logger.Printf("no profiles in %s", p.service.Path())
+		return nil
+	}
+
+	var output bytes.Buffer
+	writer := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "CURRENT\tNAME\tTARGET\tAUTH")
+	for _, name := range config.Names() {
+		current := ""
+		if name == config.Current {
+			current = "*"
+		}
+		profile := config.Profiles[name]
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", current, name, profile.Target, profile.AuthMethod())
+	}
+	_ = writer.Flush()
+
+	p.logger.Print(output.String())
+	return nil
+}
+
+func (p Profiles) add(config profiles.Config, name string) error {
+	profile := profiles.Profile{
+		Target:            p.Options.Target,
+		CACert:            p.Options.CACert,
+		SkipSSLValidation: p.Options.SkipSSLValidation,
+		ClientCert:        p.Options.ClientCert,
+		ClientKey:         p.Options.ClientKey,
+		Username:          p.Options.Username,
+		ClientID:          p.Options.ClientID,
+		ConnectTimeout:    p.Options.ConnectTimeout,
+		RequestTimeout:    p.Options.RequestTimeout,
+	}
+
+	var err error
+	profile.Password, err = secret("password", p.Options.PasswordEnv, p.Options.PasswordCommand)
+	if err != nil {
+		return err
+	}
+	profile.ClientSecret, err = secret("client-secret", p.Options.ClientSecretEnv, p.Options.ClientSecretCommand)
+	if err != nil {
+		return err
+	}
+	profile.DecryptionPassphrase, err = secret("decryption-passphrase", p.Options.DecryptionPassphraseEnv, p.Options.DecryptionPassphraseCommand)
+	if err != nil {
+		return err
+	}
+
+	err = profile.Validate()
+	if err != nil {
+		return fmt.Errorf("could not add profile %s: %s", name, err)
+	}
+
+	if config.Profiles == nil {
+		config.Profiles = map[string]profiles.Profile{}
+	}
+	_, replaced := config.Profiles[name]
+	config.Profiles[name] = profile
+
+	err = p.service.Save(config)
+	if err != nil {
+		return err
+	}
+
+	if replaced {
+		p.logger.Printf("updated profile %s", name)
+	} else {
+		p.logger.Printf("added profile %s", name)
+	}
+	return nil
+}
+
+func secret(flag, env, command string) (*profiles.Secret, error) {
+	if env != "" && command != "" {
+		return nil, fmt.Errorf("only one of --%s-env and --%s-command can be set", flag, flag)
+	}
+	if env == "" && command == "" {
+		return nil, nil
+	}
+
+	return &profiles.Secret{Env: env, Command: command}, nil
+}
+
+func (p Profiles) remove(config profiles.Config, name string) error {
+	if _, ok := config.Profiles[name]; !ok {
+		return fmt.Errorf("could not find profile %s in %s", name, p.service.Path())
+	}
+
+	delete(config.Profiles, name)
+	if config.Current == name {
+		config.Current = ""
+	}
+
+	err := p.service.Save(config)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Printf("removed profile %s", name)
+	return nil
+}
+
+func (p Profiles) use(config profiles.Config, name string) error {
+	if _, ok := config.Profiles[name]; !ok {
+		return fmt.Errorf("could not find profile %s in %s", name, p.service.Path())
+	}
+
+	config.Current = name
+
+	err := p.service.Save(config)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Printf("using profile %s", name)
+	return nil
+}
+
+func (p Profiles) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description: "This command manages the profiles of ~/.om/config.yml, the targets used with --profile, or by default:\n" +
+			"  profiles list                 lists the profiles, marking the current one\n" +
+			"  profiles add <name> [flags]   adds or replaces a profile, referring to its secrets by env var or command\n" +
+			"  profiles remove <name>        removes a profile\n" +
+			"  profiles use <name>           makes a profile the current one, used without --profile",
+		ShortDescription: "manages the target profiles of ~/.om/config.yml",
+		Flags:            p.Options,
+	}
+}
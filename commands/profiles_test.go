@@ -0,0 +1,187 @@
+package commands_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/profiles"
+)
+
+var _ = Describe("Profiles", func() {
+	var (
+		service *fakes.ProfilesService
+		logger  *fakes.Logger
+		command commands.Profiles
+	)
+
+	BeforeEach(func() {
+		service = &fakes.ProfilesService{}
+		service.PathReturns("/home/user/.om/config.yml")
+		service.LoadReturns(profiles.Config{
+			Current: "prod",
+			Profiles: map[string]profiles.Profile{
+				"prod":    {Target: "https://prod.example.com", Username: "admin", Password: &profiles.Secret{Env: "PROD_PASSWORD"}},
+				"staging": {Target: "https://staging.example.com", ClientID: "some-client", ClientSecret: &profiles.Secret{Command: "pass show staging"}},
+				"sso":     {Target: "https://sso.example.com"},
+			},
+		}, nil)
+		logger = &fakes.Logger{}
+		command = commands.NewProfiles(service, logger)
+	})
+
+	Describe("list", func() {
+		It("lists the profiles, marking the current one", func() {
+			err := command.Execute([]string{"list"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(logger.PrintArgsForCall(0)).To(Equal([]interface{}{
+				"CURRENT  NAME     TARGET                       AUTH\n" +
+					"*        prod     https://prod.example.com     password\n" +
+					"         sso      https://sso.example.com      login\n" +
+					"         staging  https://staging.example.com  client\n",
+			}))
+		})
+
+		It("tells when there are no profiles", func() {
+			service.LoadReturns(profiles.Config{}, nil)
+
+			err := command.Execute([]string{"list"})
+			Expect(err).ToNot(HaveOccurred())
+
+			format, content := logger.PrintfArgsForCall(0)
+			Expect(format).To(Equal("no profiles in %s"))
+			Expect(content).To(Equal([]interface{}{"/home/user/.om/config.yml"}))
+		})
+	})
+
+	Describe("add", func() {
+		It("adds a profile referring to its secrets", func() {
+			err := command.Execute([]string{
+				"add", "dev",
+				"--target", "https://dev.example.com",
+				"--skip-ssl-validation",
+				"--username", "admin",
+				"--password-env", "DEV_PASSWORD",
+				"--decryption-passphrase-command", "pass show dev/passphrase",
+				"--request-timeout", "3600",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(service.SaveCallCount()).To(Equal(1))
+			config := service.SaveArgsForCall(0)
+			Expect(config.Current).To(Equal("prod"))
+			Expect(config.Profiles).To(HaveLen(4))
+			Expect(config.Profiles["dev"]).To(Equal(profiles.Profile{
+				Target:               "https://dev.example.com",
+				SkipSSLValidation:    true,
+				Username:             "admin",
+				Password:             &profiles.Secret{Env: "DEV_PASSWORD"},
+				DecryptionPassphrase: &profiles.Secret{Command: "pass show dev/passphrase"},
+				RequestTimeout:       3600,
+			}))
+
+			format, content := logger.PrintfArgsForCall(0)
+			Expect(format).To(Equal("added profile %s"))
+			Expect(content).To(Equal([]interface{}{"dev"}))
+		})
+
+		It("replaces a profile of the same name", func() {
+			err := command.Execute([]string{"add", "sso", "--target", "https://new-sso.example.com"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(service.SaveArgsForCall(0).Profiles["sso"].Target).To(Equal("https://new-sso.example.com"))
+			format, _ := logger.PrintfArgsForCall(0)
+			Expect(format).To(Equal("updated profile %s"))
+		})
+
+		It("adds the first profile", func() {
+			service.LoadReturns(profiles.Config{}, nil)
+
+			err := command.Execute([]string{"add", "sso", "--target", "https://sso.example.com"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(service.SaveArgsForCall(0).Profiles).To(HaveKey("sso"))
+		})
+
+		It("errors with an invalid profile", func() {
+			err := command.Execute([]string{"add", "dev", "--target", "https://dev.example.com", "--username", "admin"})
+			Expect(err).To(MatchError("could not add profile dev: the password of username admin is required"))
+			Expect(service.SaveCallCount()).To(Equal(0))
+		})
+
+		It("errors with both an env var and a command for a secret", func() {
+			err := command.Execute([]string{"add", "dev", "--target", "https://dev.example.com", "--client-id", "some-client", "--client-secret-env", "SECRET", "--client-secret-command", "pass show secret"})
+			Expect(err).To(MatchError("only one of --client-secret-env and --client-secret-command can be set"))
+		})
+
+		It("errors without a name", func() {
+			err := command.Execute([]string{"add", "--target", "https://dev.example.com"})
+			Expect(err).To(MatchError("profiles add takes the name of a profile"))
+		})
+
+		It("errors with more than one name", func() {
+			err := command.Execute([]string{"add", "dev", "--target", "https://dev.example.com", "extra"})
+			Expect(err).To(MatchError("unexpected arguments for profiles add: extra"))
+		})
+	})
+
+	Describe("remove", func() {
+		It("removes a profile, and the current profile with it", func() {
+			err := command.Execute([]string{"remove", "prod"})
+			Expect(err).ToNot(HaveOccurred())
+
+			config := service.SaveArgsForCall(0)
+			Expect(config.Profiles).ToNot(HaveKey("prod"))
+			Expect(config.Current).To(BeEmpty())
+		})
+
+		It("errors with an unknown profile", func() {
+			err := command.Execute([]string{"remove", "unknown"})
+			Expect(err).To(MatchError("could not find profile unknown in /home/user/.om/config.yml"))
+		})
+	})
+
+	Describe("use", func() {
+		It("makes a profile the current one", func() {
+			err := command.Execute([]string{"use", "staging"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(service.SaveArgsForCall(0).Current).To(Equal("staging"))
+			format, content := logger.PrintfArgsForCall(0)
+			Expect(format).To(Equal("using profile %s"))
+			Expect(content).To(Equal([]interface{}{"staging"}))
+		})
+
+		It("errors with an unknown profile", func() {
+			err := command.Execute([]string{"use", "unknown"})
+			Expect(err).To(MatchError("could not find profile unknown in /home/user/.om/config.yml"))
+			Expect(service.SaveCallCount()).To(Equal(0))
+		})
+	})
+
+	It("errors without a subcommand", func() {
+		err := command.Execute([]string{})
+		Expect(err).To(MatchError("a subcommand is required: list, add, remove or use"))
+	})
+
+	It("errors with an unknown subcommand", func() {
+		err := command.Execute([]string{"rename"})
+		Expect(err).To(MatchError(`unknown subcommand "rename": expected list, add, remove or use`))
+	})
+
+	It("returns the errors of the config file", func() {
+		service.LoadReturns(profiles.Config{}, errors.New("could not parse the config file"))
+
+		err := command.Execute([]string{"list"})
+		Expect(err).To(MatchError("could not parse the config file"))
+	})
+
+	It("returns the errors of saving the config file", func() {
+		service.SaveReturns(errors.New("could not write the config file"))
+
+		err := command.Execute([]string{"use", "prod"})
+		Expect(err).To(MatchError("could not write the config file"))
+	})
+})
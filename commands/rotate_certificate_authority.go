@@ -0,0 +1,313 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	rotationStepGenerate         = "generate-certificate-authority"
+	rotationStepApplyNewCA       = "apply-changes-with-new-certificate-authority"
+	rotationStepActivate         = "activate-certificate-authority"
+	rotationStepRegenerate       = "regenerate-certificates"
+	rotationStepApplyRegenerated = "apply-changes-with-regenerated-certificates"
+	rotationStepDelete           = "delete-certificate-authority"
+)
+
+// rotationSteps are run in order. The steps at the start,
+// and after each apply changes, refuse to run while other changes are pending,
+// so the rotation does not deploy changes staged by someone else.
+var rotationSteps = []struct {
+	name                     string
+	requiresNoPendingChanges bool
+	description              string
+}{
+	{rotationStepGenerate, true, "generating a new certificate authority"},
+	{rotationStepApplyNewCA, false, "applying changes to trust the new certificate authority"},
+	{rotationStepActivate, true, "activating the new certificate authority"},
+	{rotationStepRegenerate, false, "regenerating the certificates signed by the old certificate authority"},
+	{rotationStepApplyRegenerated, false, "applying changes to deploy the regenerated certificates"},
+	{rotationStepDelete, true, "deleting the old certificate authority"},
+}
+
+type RotateCertificateAuthority struct {
+	service      rotateCertificateAuthorityService
+	logWriter    logWriter
+	logger       logger
+	waitDuration time.Duration
+	Options      struct {
+		StateFile      string `long:"state-file"      short:"s" default:"rotate-certificate-authority-state.yml" description:"path to the file recording the progress of the rotation, to resume it after a failed step"`
+		IgnoreWarnings bool   `long:"ignore-warnings" short:"i"                                                  description:"ignore the warnings of the verifiers when applying changes"`
+	}
+}
+
+//counterfeiter:generate -o ./fakes/rotate_certificate_authority_service.go --fake-name RotateCertificateAuthorityService . rotateCertificateAuthorityService
+type rotateCertificateAuthorityService interface {
+	ActivateCertificateAuthority(api.ActivateCertificateAuthorityInput) error
+	CreateInstallation(bool, bool, []string, api.ApplyErrandChanges) (api.InstallationsServiceOutput, error)
+	DeleteCertificateAuthority(api.DeleteCertificateAuthorityInput) error
+	GenerateCertificateAuthority() (api.CA, error)
+	GetInstallation(id int) (api.InstallationsServiceOutput, error)
+	GetInstallationLogs(id int) (api.InstallationsServiceOutput, error)
+	ListCertificateAuthorities() (api.CertificateAuthoritiesOutput, error)
+	ListStagedPendingChanges() (api.PendingChangesOutput, error)
+	RegenerateCertificates() error
+	RunningInstallation() (api.InstallationsServiceOutput, error)
+}
+
+// rotationState is persisted to the state file after every step.
+type rotationState struct {
+	Step            string   `yaml:"step"`
+	OldCAGUID       string   `yaml:"old_certificate_authority_guid,omitempty"`
+	NewCAGUID       string   `yaml:"new_certificate_authority_guid,omitempty"`
+	PreviousCAGUIDs []string `yaml:"previous_certificate_authority_guids,omitempty"`
+	InstallationID  int      `yaml:"installation_id,omitempty"`
+}
+
+func NewRotateCertificateAuthority(service rotateCertificateAuthorityService, logWriter logWriter, logger logger, waitDuration time.Duration) RotateCertificateAuthority {
+	return RotateCertificateAuthority{
+		service:      service,
+		logWriter:    logWriter,
+		logger:       logger,
+		waitDuration: waitDuration,
+	}
+}
+
+func (r RotateCertificateAuthority) Execute(args []string) error {
+	if _, err := jhanda.Parse(&r.Options, args); err != nil {
+		return fmt.Errorf("could not parse rotate-certificate-authority flags: %s", err)
+	}
+
+	state, err := r.loadState()
+	if err != nil {
+		return err
+	}
+
+	started := false
+	for i, step := range rotationSteps {
+		if !started && step.name != state.Step {
+			continue
+		}
+		started = true
+
+		r.logger.Printf("[%d/%d] %s", i+1, len(rotationSteps), step.description)
+
+		err = r.checkCanAdvance(step.requiresNoPendingChanges, state.InstallationID)
+		if err != nil {
+			return r.stopAt(step.name, err)
+		}
+
+		err = r.runStep(step.name, &state)
+		if err != nil {
+			return r.stopAt(step.name, err)
+		}
+
+		if i+1 < len(rotationSteps) {
+			state.Step = rotationSteps[i+1].name
+			state.InstallationID = 0
+
+			err = r.saveState(state)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if !started {
+		return fmt.Errorf("unknown step %q in state file %s", state.Step, r.Options.StateFile)
+	}
+
+	err = os.Remove(r.Options.StateFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not remove state file %s: %s", r.Options.StateFile, err)
+	}
+
+	r.logger.Printf("certificate authority rotated: %s is active and %s has been deleted", state.NewCAGUID, state.OldCAGUID)
+
+	return nil
+}
+
+func (r RotateCertificateAuthority) loadState() (rotationState, error) {
+	contents, err := ioutil.ReadFile(r.Options.StateFile)
+	if os.IsNotExist(err) {
+		return rotationState{Step: rotationStepGenerate}, nil
+	}
+	if err != nil {
+		return rotationState{}, fmt.Errorf("could not read state file %s: %s", r.Options.StateFile, err)
+	}
+
+	var state rotationState
+	err = yaml.UnmarshalStrict(contents, &state)
+	if err != nil {
+		return rotationState{}, fmt.Errorf("could not parse state file %s: %s", r.Options.StateFile, err)
+	}
+
+	r.logger.Printf("resuming the rotation recorded in %s at step %s", r.Options.StateFile, state.Step)
+
+	return state, nil
+}
+
+func (r RotateCertificateAuthority) saveState(state rotationState) error {
+	contents, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(r.Options.StateFile, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write state file %s: %s", r.Options.StateFile, err)
+	}
+
+	return nil
+}
+
+func (r RotateCertificateAuthority) stopAt(step string, err error) error {
+	return fmt.Errorf("rotation stopped at step %s: %w (run the command again with the same --state-file to resume)", step, err)
+}
+
+// checkCanAdvance refuses to run a step while an installation is running,
+// unless it is the installation the step itself started.
+func (r RotateCertificateAuthority) checkCanAdvance(requiresNoPendingChanges bool, installationID int) error {
+	running, err := r.service.RunningInstallation()
+	if err != nil {
+		return fmt.Errorf("could not check for a running installation: %s", err)
+	}
+
+	if running != (api.InstallationsServiceOutput{}) && running.ID != installationID {
+		return fmt.Errorf("installation %d is running; wait for it to finish", running.ID)
+	}
+
+	if !requiresNoPendingChanges {
+		return nil
+	}
+
+	pendingChanges, err := r.service.ListStagedPendingChanges()
+	if err != nil {
+		return fmt.Errorf("could not check for pending changes: %s", err)
+	}
+
+	for _, change := range pendingChanges.ChangeList {
+		if change.Action != "unchanged" {
+			return fmt.Errorf("product %s has pending changes; apply or revert them first", change.GUID)
+		}
+	}
+
+	return nil
+}
+
+func (r RotateCertificateAuthority) runStep(step string, state *rotationState) error {
+	switch step {
+	case rotationStepGenerate:
+		return r.generateCertificateAuthority(state)
+	case rotationStepApplyNewCA, rotationStepApplyRegenerated:
+		return r.applyChanges(step, state)
+	case rotationStepActivate:
+		return r.service.ActivateCertificateAuthority(api.ActivateCertificateAuthorityInput{GUID: state.NewCAGUID})
+	case rotationStepRegenerate:
+		return r.service.RegenerateCertificates()
+	case rotationStepDelete:
+		return r.service.DeleteCertificateAuthority(api.DeleteCertificateAuthorityInput{GUID: state.OldCAGUID})
+	}
+
+	return nil
+}
+
+// generateCertificateAuthority records the certificate authorities in the state file
+// before generating a new one. When the rotation stopped before recording the new one,
+// the resumed rotation finds it among those, instead of generating another one.
+func (r RotateCertificateAuthority) generateCertificateAuthority(state *rotationState) error {
+	cas, err := r.service.ListCertificateAuthorities()
+	if err != nil {
+		return err
+	}
+
+	for _, ca := range cas.CAs {
+		if ca.Active {
+			state.OldCAGUID = ca.GUID
+		}
+	}
+	if state.OldCAGUID == "" {
+		return errors.New("could not find the active certificate authority")
+	}
+
+	if len(state.PreviousCAGUIDs) > 0 {
+		previous := map[string]bool{}
+		for _, guid := range state.PreviousCAGUIDs {
+			previous[guid] = true
+		}
+
+		for _, ca := range cas.CAs {
+			if !ca.Active && !previous[ca.GUID] {
+				state.NewCAGUID = ca.GUID
+				state.PreviousCAGUIDs = nil
+				r.logger.Printf("using certificate authority %s, generated before the rotation stopped, to replace %s", state.NewCAGUID, state.OldCAGUID)
+				return nil
+			}
+		}
+	}
+
+	state.PreviousCAGUIDs = nil
+	for _, ca := range cas.CAs {
+		state.PreviousCAGUIDs = append(state.PreviousCAGUIDs, ca.GUID)
+	}
+
+	err = r.saveState(*state)
+	if err != nil {
+		return err
+	}
+
+	ca, err := r.service.GenerateCertificateAuthority()
+	if err != nil {
+		return err
+	}
+	state.NewCAGUID = ca.GUID
+	state.PreviousCAGUIDs = nil
+
+	r.logger.Printf("generated certificate authority %s to replace %s", state.NewCAGUID, state.OldCAGUID)
+
+	return nil
+}
+
+// applyChanges records the installation in the state file before waiting for it,
+// so a resumed rotation waits for the same installation instead of starting another one.
+func (r RotateCertificateAuthority) applyChanges(step string, state *rotationState) error {
+	if state.InstallationID != 0 {
+		installation, err := r.service.GetInstallation(state.InstallationID)
+		if err != nil {
+			return fmt.Errorf("could not get status of installation %d: %s", state.InstallationID, err)
+		}
+
+		if installation.Status != api.StatusFailed {
+			r.logger.Printf("waiting for installation %d", state.InstallationID)
+			return waitForInstallation(r.service, r.logWriter, state.InstallationID, r.waitDuration, nil)
+		}
+	}
+
+	installation, err := r.service.CreateInstallation(r.Options.IgnoreWarnings, true, nil, api.ApplyErrandChanges{})
+	if err != nil {
+		return fmt.Errorf("installation failed to trigger: %s", err)
+	}
+
+	state.InstallationID = installation.ID
+	err = r.saveState(*state)
+	if err != nil {
+		return err
+	}
+
+	return waitForInstallation(r.service, r.logWriter, installation.ID, r.waitDuration, nil)
+}
+
+func (r RotateCertificateAuthority) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command rotates the Ops Manager root certificate authority. It generates a new certificate authority, applies changes, activates it, regenerates the certificates, applies changes again and deletes the old certificate authority. The progress is recorded in a state file, so a rotation that stopped at a failed step resumes at that step when the command is run again.",
+		ShortDescription: "rotates the Ops Manager root certificate authority",
+		Flags:            r.Options,
+	}
+}
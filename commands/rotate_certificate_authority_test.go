@@ -0,0 +1,229 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("RotateCertificateAuthority", func() {
+	var (
+		service   *fakes.RotateCertificateAuthorityService
+		logWriter *fakes.LogWriter
+		stdout    *gbytes.Buffer
+		command   commands.RotateCertificateAuthority
+		stateDir  string
+		stateFile string
+	)
+
+	BeforeEach(func() {
+		service = &fakes.RotateCertificateAuthorityService{}
+		logWriter = &fakes.LogWriter{}
+		stdout = gbytes.NewBuffer()
+		command = commands.NewRotateCertificateAuthority(service, logWriter, log.New(stdout, "", 0), 0)
+
+		var err error
+		stateDir, err = ioutil.TempDir("", "rotate-ca")
+		Expect(err).ToNot(HaveOccurred())
+		stateFile = filepath.Join(stateDir, "state.yml")
+
+		service.ListCertificateAuthoritiesReturns(api.CertificateAuthoritiesOutput{
+			CAs: []api.CA{
+				{GUID: "old-ca-guid", Active: true},
+			},
+		}, nil)
+		service.GenerateCertificateAuthorityReturns(api.CA{GUID: "new-ca-guid"}, nil)
+		service.CreateInstallationReturnsOnCall(0, api.InstallationsServiceOutput{ID: 10}, nil)
+		service.CreateInstallationReturnsOnCall(1, api.InstallationsServiceOutput{ID: 11}, nil)
+		service.GetInstallationReturns(api.InstallationsServiceOutput{Status: api.StatusSucceeded}, nil)
+		service.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: "some logs"}, nil)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(stateDir)).To(Succeed())
+	})
+
+	It("drives the whole rotation and removes the state file", func() {
+		err := command.Execute([]string{"--state-file", stateFile})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(service.GenerateCertificateAuthorityCallCount()).To(Equal(1))
+		Expect(service.CreateInstallationCallCount()).To(Equal(2))
+		ignoreWarnings, deployProducts, productNames, _ := service.CreateInstallationArgsForCall(0)
+		Expect(ignoreWarnings).To(BeFalse())
+		Expect(deployProducts).To(BeTrue())
+		Expect(productNames).To(BeEmpty())
+		Expect(service.ActivateCertificateAuthorityArgsForCall(0)).To(Equal(api.ActivateCertificateAuthorityInput{GUID: "new-ca-guid"}))
+		Expect(service.RegenerateCertificatesCallCount()).To(Equal(1))
+		Expect(service.DeleteCertificateAuthorityArgsForCall(0)).To(Equal(api.DeleteCertificateAuthorityInput{GUID: "old-ca-guid"}))
+		Expect(service.GetInstallationArgsForCall(0)).To(Equal(10))
+		Expect(service.GetInstallationArgsForCall(1)).To(Equal(11))
+		Expect(logWriter.FlushArgsForCall(0)).To(Equal("some logs"))
+
+		Expect(stdout).To(gbytes.Say(`\[1/6\] generating a new certificate authority`))
+		Expect(stdout).To(gbytes.Say(`generated certificate authority new-ca-guid to replace old-ca-guid`))
+		Expect(stdout).To(gbytes.Say(`\[2/6\] applying changes to trust the new certificate authority`))
+		Expect(stdout).To(gbytes.Say(`\[3/6\] activating the new certificate authority`))
+		Expect(stdout).To(gbytes.Say(`\[4/6\] regenerating the certificates signed by the old certificate authority`))
+		Expect(stdout).To(gbytes.Say(`\[5/6\] applying changes to deploy the regenerated certificates`))
+		Expect(stdout).To(gbytes.Say(`\[6/6\] deleting the old certificate authority`))
+		Expect(stdout).To(gbytes.Say(`certificate authority rotated: new-ca-guid is active and old-ca-guid has been deleted`))
+
+		Expect(stateFile).ToNot(BeAnExistingFile())
+	})
+
+	It("records the failed step in the state file and resumes there", func() {
+		service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{Status: api.StatusFailed}, nil)
+
+		err := command.Execute([]string{"--state-file", stateFile})
+		Expect(err).To(MatchError("rotation stopped at step apply-changes-with-new-certificate-authority: installation 10 was unsuccessful (run the command again with the same --state-file to resume)"))
+
+		contents, err := ioutil.ReadFile(stateFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents).To(MatchYAML(`
+step: apply-changes-with-new-certificate-authority
+old_certificate_authority_guid: old-ca-guid
+new_certificate_authority_guid: new-ca-guid
+installation_id: 10
+`))
+
+		service.GetInstallationReturnsOnCall(1, api.InstallationsServiceOutput{Status: api.StatusFailed}, nil)
+
+		err = command.Execute([]string{"--state-file", stateFile})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stdout).To(gbytes.Say(`resuming the rotation recorded in .* at step apply-changes-with-new-certificate-authority`))
+		Expect(service.GenerateCertificateAuthorityCallCount()).To(Equal(1))
+		Expect(service.CreateInstallationCallCount()).To(Equal(3))
+		Expect(service.DeleteCertificateAuthorityArgsForCall(0)).To(Equal(api.DeleteCertificateAuthorityInput{GUID: "old-ca-guid"}))
+	})
+
+	It("waits for the installation of the step when resuming while it runs", func() {
+		Expect(ioutil.WriteFile(stateFile, []byte(`
+step: apply-changes-with-regenerated-certificates
+old_certificate_authority_guid: old-ca-guid
+new_certificate_authority_guid: new-ca-guid
+installation_id: 42
+`), 0600)).To(Succeed())
+
+		service.RunningInstallationReturns(api.InstallationsServiceOutput{ID: 42, Status: api.StatusRunning}, nil)
+		service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{ID: 42, Status: api.StatusRunning}, nil)
+		service.GetInstallationReturnsOnCall(1, api.InstallationsServiceOutput{ID: 42, Status: api.StatusRunning}, nil)
+		service.RunningInstallationReturnsOnCall(1, api.InstallationsServiceOutput{}, nil)
+
+		err := command.Execute([]string{"--state-file", stateFile})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stdout).To(gbytes.Say(`waiting for installation 42`))
+		Expect(service.CreateInstallationCallCount()).To(Equal(0))
+		Expect(service.GetInstallationArgsForCall(1)).To(Equal(42))
+		Expect(service.DeleteCertificateAuthorityArgsForCall(0)).To(Equal(api.DeleteCertificateAuthorityInput{GUID: "old-ca-guid"}))
+	})
+
+	It("uses the certificate authority generated before the rotation stopped when resuming", func() {
+		service.GenerateCertificateAuthorityReturns(api.CA{}, errors.New("connection reset"))
+
+		err := command.Execute([]string{"--state-file", stateFile})
+		Expect(err).To(MatchError(ContainSubstring("rotation stopped at step generate-certificate-authority: connection reset")))
+
+		contents, err := ioutil.ReadFile(stateFile)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents).To(MatchYAML(`
+step: generate-certificate-authority
+old_certificate_authority_guid: old-ca-guid
+previous_certificate_authority_guids: [old-ca-guid]
+`))
+
+		// the certificate authority was generated even though the response was lost
+		service.ListCertificateAuthoritiesReturns(api.CertificateAuthoritiesOutput{
+			CAs: []api.CA{
+				{GUID: "old-ca-guid", Active: true},
+				{GUID: "new-ca-guid", Active: false},
+			},
+		}, nil)
+
+		err = command.Execute([]string{"--state-file", stateFile})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(stdout).To(gbytes.Say(`using certificate authority new-ca-guid, generated before the rotation stopped, to replace old-ca-guid`))
+		Expect(service.GenerateCertificateAuthorityCallCount()).To(Equal(1))
+		Expect(service.ActivateCertificateAuthorityArgsForCall(0)).To(Equal(api.ActivateCertificateAuthorityInput{GUID: "new-ca-guid"}))
+		Expect(service.DeleteCertificateAuthorityArgsForCall(0)).To(Equal(api.DeleteCertificateAuthorityInput{GUID: "old-ca-guid"}))
+	})
+
+	When("there are pending changes", func() {
+		It("refuses to start the rotation", func() {
+			service.ListStagedPendingChangesReturns(api.PendingChangesOutput{
+				ChangeList: []api.ProductChange{
+					{GUID: "redis-guid", Action: "unchanged"},
+					{GUID: "cf-guid", Action: "update"},
+				},
+			}, nil)
+
+			err := command.Execute([]string{"--state-file", stateFile})
+			Expect(err).To(MatchError(ContainSubstring("rotation stopped at step generate-certificate-authority: product cf-guid has pending changes; apply or revert them first")))
+
+			Expect(service.GenerateCertificateAuthorityCallCount()).To(Equal(0))
+			Expect(stateFile).ToNot(BeAnExistingFile())
+		})
+	})
+
+	When("another installation is running", func() {
+		It("refuses to advance", func() {
+			service.RunningInstallationReturns(api.InstallationsServiceOutput{ID: 7, Status: api.StatusRunning}, nil)
+
+			err := command.Execute([]string{"--state-file", stateFile})
+			Expect(err).To(MatchError(ContainSubstring("installation 7 is running; wait for it to finish")))
+
+			Expect(service.GenerateCertificateAuthorityCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the state file names an unknown step", func() {
+		It("returns an error", func() {
+			Expect(ioutil.WriteFile(stateFile, []byte(`step: reticulate-splines`), 0600)).To(Succeed())
+
+			err := command.Execute([]string{"--state-file", stateFile})
+			Expect(err).To(MatchError(`unknown step "reticulate-splines" in state file ` + stateFile))
+		})
+	})
+
+	When("an unknown flag is provided", func() {
+		It("returns an error", func() {
+			err := command.Execute([]string{"--badflag"})
+			Expect(err).To(MatchError("could not parse rotate-certificate-authority flags: flag provided but not defined: -badflag"))
+		})
+	})
+
+	When("there is no active certificate authority", func() {
+		It("returns an error", func() {
+			service.ListCertificateAuthoritiesReturns(api.CertificateAuthoritiesOutput{}, nil)
+
+			err := command.Execute([]string{"--state-file", stateFile})
+			Expect(err).To(MatchError(ContainSubstring("could not find the active certificate authority")))
+		})
+	})
+
+	When("a step fails", func() {
+		It("keeps the state at that step", func() {
+			service.ActivateCertificateAuthorityReturns(errors.New("activation failed"))
+
+			err := command.Execute([]string{"--state-file", stateFile})
+			Expect(err).To(MatchError(ContainSubstring("rotation stopped at step activate-certificate-authority: activation failed")))
+
+			contents, err := ioutil.ReadFile(stateFile)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(ContainSubstring("step: activate-certificate-authority"))
+			Expect(string(contents)).ToNot(ContainSubstring("installation_id"))
+		})
+	})
+})
@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/api"
+)
+
+//counterfeiter:generate -o ./fakes/run_errand_service.go --fake-name RunErrandService . runErrandService
+type runErrandService interface {
+	CreateInstallation(bool, bool, []string, api.ApplyErrandChanges) (api.InstallationsServiceOutput, error)
+	GetInstallation(id int) (api.InstallationsServiceOutput, error)
+	GetInstallationLogs(id int) (api.InstallationsServiceOutput, error)
+	GetStagedProductByName(productName string) (api.StagedProductsFindOutput, error)
+	ListDeployedProducts() ([]api.DeployedProductOutput, error)
+	ListStagedProductErrands(productID string) (api.ErrandsListOutput, error)
+	UpdateStagedProductErrands(productID string, errandName string, postDeployState interface{}, preDeleteState interface{}) error
+}
+
+type RunErrand struct {
+	service       runErrandService
+	logWriter     logWriter
+	logger        logger
+	waitDuration  time.Duration
+	notifySignals func(chan<- os.Signal, ...os.Signal)
+	Options       struct {
+		ProductName    string `long:"product-name"    short:"p" required:"true" description:"name of the deployed product"`
+		ErrandName     string `long:"errand-name"     short:"e" required:"true" description:"name of the post-deploy errand to run"`
+		IgnoreWarnings bool   `long:"ignore-warnings" short:"i"                 description:"ignore the warnings of the verifiers when starting the installation"`
+	}
+}
+
+func NewRunErrand(service runErrandService, logWriter logWriter, logger logger, waitDuration time.Duration, notifySignals func(chan<- os.Signal, ...os.Signal)) RunErrand {
+	return RunErrand{
+		service:       service,
+		logWriter:     logWriter,
+		logger:        logger,
+		waitDuration:  waitDuration,
+		notifySignals: notifySignals,
+	}
+}
+
+func (r RunErrand) Execute(args []string) error {
+	if _, err := jhanda.Parse(&r.Options, args); err != nil {
+		return fmt.Errorf("could not parse run-errand flags: %s", err)
+	}
+
+	deployedProducts, err := r.service.ListDeployedProducts()
+	if err != nil {
+		return fmt.Errorf("failed to list deployed products: %s", err)
+	}
+
+	deployed := false
+	for _, product := range deployedProducts {
+		if product.Type == r.Options.ProductName {
+			deployed = true
+		}
+	}
+	if !deployed {
+		return fmt.Errorf("product %q has not been deployed, so its errands cannot be run", r.Options.ProductName)
+	}
+
+	findOutput, err := r.service.GetStagedProductByName(r.Options.ProductName)
+	if err != nil {
+		return fmt.Errorf("failed to find staged product %q: %s", r.Options.ProductName, err)
+	}
+	productGUID := findOutput.Product.GUID
+
+	errandsOutput, err := r.service.ListStagedProductErrands(productGUID)
+	if err != nil {
+		return fmt.Errorf("failed to list errands: %s", err)
+	}
+
+	// previousStates are the post-deploy states the installation overrides,
+	// keyed by errand name, to be restored once it has finished.
+	previousStates := map[string]interface{}{}
+	runPostDeploy := map[string]interface{}{}
+	var errandNames []string
+	found := false
+	for _, errand := range errandsOutput.Errands {
+		errandNames = append(errandNames, errand.Name)
+		found = found || errand.Name == r.Options.ErrandName
+
+		if errand.PostDeploy == nil {
+			continue
+		}
+
+		previousStates[errand.Name] = errand.PostDeploy
+		runPostDeploy[errand.Name] = errand.Name == r.Options.ErrandName
+	}
+
+	if !found {
+		return fmt.Errorf("errand %q not found in %s: errands available are: %s", r.Options.ErrandName, r.Options.ProductName, strings.Join(errandNames, ", "))
+	}
+	if _, ok := runPostDeploy[r.Options.ErrandName]; !ok {
+		return fmt.Errorf("errand %q of %s is not a post-deploy errand", r.Options.ErrandName, r.Options.ProductName)
+	}
+
+	// An interrupt stops the wait rather than the command,
+	// so the errand states are restored either way.
+	interrupts := make(chan os.Signal, 1)
+	r.notifySignals(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+
+	r.logger.Printf("running errand %s of %s", r.Options.ErrandName, r.Options.ProductName)
+
+	installation, err := r.service.CreateInstallation(r.Options.IgnoreWarnings, true, []string{r.Options.ProductName}, api.ApplyErrandChanges{
+		Errands: map[string]api.ProductErrand{
+			r.Options.ProductName: {RunPostDeploy: runPostDeploy},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("installation failed to trigger: %s", err)
+	}
+
+	err = waitForInstallation(r.service, r.logWriter, installation.ID, r.waitDuration, interrupts)
+
+	restoreErr := r.restore(productGUID, previousStates)
+	if err != nil {
+		if restoreErr != nil {
+			return fmt.Errorf("%s; %s", err, restoreErr)
+		}
+		return err
+	}
+
+	return restoreErr
+}
+
+// restore puts back the post-deploy state of every errand,
+// as Ops Manager keeps the errand states an installation was started with.
+// It tries every errand before returning the errors.
+func (r RunErrand) restore(productGUID string, previousStates map[string]interface{}) error {
+	var names []string
+	for name := range previousStates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var failures []string
+	for _, name := range names {
+		err := r.service.UpdateStagedProductErrands(productGUID, name, previousStates[name], nil)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s (was %v): %s", name, previousStates[name], err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("could not restore the post-deploy state of errands %s", strings.Join(failures, ", "))
+	}
+
+	r.logger.Printf("restored the post-deploy state of the errands of %s", r.Options.ProductName)
+	return nil
+}
+
+func (r RunErrand) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This authenticated command runs a post-deploy errand of a deployed product. It starts an installation deploying only that product, with only that errand enabled, streams its log, and restores the post-deploy state of the errands of the product afterwards, even when the errand fails or the command is interrupted.",
+		ShortDescription: "runs a single errand of a deployed product",
+		Flags:            r.Options,
+	}
+}
@@ -0,0 +1,165 @@
+package commands_test
+
+import (
+	"errors"
+	"log"
+	"os"
+	"syscall"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+)
+
+var _ = Describe("RunErrand", func() {
+	var (
+		service   *fakes.RunErrandService
+		logWriter *fakes.LogWriter
+		stdout    *gbytes.Buffer
+		signals   chan<- os.Signal
+		command   commands.RunErrand
+	)
+
+	BeforeEach(func() {
+		service = &fakes.RunErrandService{}
+		logWriter = &fakes.LogWriter{}
+		stdout = gbytes.NewBuffer()
+		signals = nil
+		command = commands.NewRunErrand(service, logWriter, log.New(stdout, "", 0), 0, func(c chan<- os.Signal, _ ...os.Signal) {
+			signals = c
+		})
+
+		service.ListDeployedProductsReturns([]api.DeployedProductOutput{{Type: "cf", GUID: "cf-guid"}}, nil)
+		service.GetStagedProductByNameReturns(api.StagedProductsFindOutput{Product: api.StagedProduct{GUID: "cf-guid"}}, nil)
+		service.ListStagedProductErrandsReturns(api.ErrandsListOutput{
+			Errands: []api.Errand{
+				{Name: "smoke_tests", PostDeploy: "when-changed"},
+				{Name: "push-apps-manager", PostDeploy: true},
+				{Name: "delete-apps", PreDelete: true},
+			},
+		}, nil)
+		service.CreateInstallationReturns(api.InstallationsServiceOutput{ID: 5}, nil)
+		service.GetInstallationReturnsOnCall(0, api.InstallationsServiceOutput{Status: api.StatusRunning}, nil)
+		service.GetInstallationReturnsOnCall(1, api.InstallationsServiceOutput{Status: api.StatusSucceeded}, nil)
+		service.GetInstallationLogsReturns(api.InstallationsServiceOutput{Logs: "some logs"}, nil)
+	})
+
+	It("runs only the errand, deploying only its product, and restores the errand states", func() {
+		err := command.Execute([]string{"--product-name", "cf", "--errand-name", "smoke_tests"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(service.ListStagedProductErrandsArgsForCall(0)).To(Equal("cf-guid"))
+
+		Expect(service.CreateInstallationCallCount()).To(Equal(1))
+		ignoreWarnings, deployProducts, productNames, errands := service.CreateInstallationArgsForCall(0)
+		Expect(ignoreWarnings).To(BeFalse())
+		Expect(deployProducts).To(BeTrue())
+		Expect(productNames).To(Equal([]string{"cf"}))
+		Expect(errands).To(Equal(api.ApplyErrandChanges{
+			Errands: map[string]api.ProductErrand{
+				"cf": {
+					RunPostDeploy: map[string]interface{}{
+						"smoke_tests":       true,
+						"push-apps-manager": false,
+					},
+				},
+			},
+		}))
+
+		Expect(service.GetInstallationArgsForCall(1)).To(Equal(5))
+		Expect(logWriter.FlushCallCount()).To(Equal(2))
+		Expect(logWriter.FlushArgsForCall(1)).To(Equal("some logs"))
+
+		Expect(service.UpdateStagedProductErrandsCallCount()).To(Equal(2))
+		guid, name, postDeploy, preDelete := service.UpdateStagedProductErrandsArgsForCall(0)
+		Expect([]interface{}{guid, name, postDeploy, preDelete}).To(Equal([]interface{}{"cf-guid", "push-apps-manager", true, nil}))
+		guid, name, postDeploy, preDelete = service.UpdateStagedProductErrandsArgsForCall(1)
+		Expect([]interface{}{guid, name, postDeploy, preDelete}).To(Equal([]interface{}{"cf-guid", "smoke_tests", "when-changed", nil}))
+
+		Expect(stdout).To(gbytes.Say("running errand smoke_tests of cf"))
+		Expect(stdout).To(gbytes.Say("restored the post-deploy state of the errands of cf"))
+	})
+
+	It("restores the errand states when the errand fails", func() {
+		service.GetInstallationReturnsOnCall(1, api.InstallationsServiceOutput{Status: api.StatusFailed}, nil)
+
+		err := command.Execute([]string{"--product-name", "cf", "--errand-name", "smoke_tests"})
+		Expect(err).To(MatchError("installation 5 was unsuccessful"))
+
+		Expect(service.UpdateStagedProductErrandsCallCount()).To(Equal(2))
+	})
+
+	It("restores every errand state it can and reports the others", func() {
+		service.GetInstallationReturnsOnCall(1, api.InstallationsServiceOutput{Status: api.StatusFailed}, nil)
+		service.UpdateStagedProductErrandsReturnsOnCall(0, errors.New("conflict"))
+
+		err := command.Execute([]string{"--product-name", "cf", "--errand-name", "smoke_tests"})
+		Expect(err).To(MatchError("installation 5 was unsuccessful; could not restore the post-deploy state of errands push-apps-manager (was true): conflict"))
+
+		Expect(service.UpdateStagedProductErrandsCallCount()).To(Equal(2))
+	})
+
+	When("interrupted while waiting", func() {
+		It("stops waiting and restores the errand states", func() {
+			command = commands.NewRunErrand(service, logWriter, log.New(stdout, "", 0), time.Minute, func(c chan<- os.Signal, _ ...os.Signal) {
+				signals = c
+			})
+			service.GetInstallationStub = func(int) (api.InstallationsServiceOutput, error) {
+				signals <- syscall.SIGTERM
+				return api.InstallationsServiceOutput{Status: api.StatusRunning}, nil
+			}
+
+			err := command.Execute([]string{"--product-name", "cf", "--errand-name", "smoke_tests"})
+			Expect(err).To(MatchError("stopped waiting for installation 5, which is still running on the Ops Manager: interrupted by terminated"))
+
+			Expect(service.GetInstallationCallCount()).To(Equal(1))
+			Expect(service.UpdateStagedProductErrandsCallCount()).To(Equal(2))
+			Expect(stdout).To(gbytes.Say("restored the post-deploy state of the errands of cf"))
+		})
+	})
+
+	When("the product has not been deployed", func() {
+		It("returns an error", func() {
+			err := command.Execute([]string{"--product-name", "p-mysql", "--errand-name", "smoke_tests"})
+			Expect(err).To(MatchError(`product "p-mysql" has not been deployed, so its errands cannot be run`))
+			Expect(service.CreateInstallationCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the errand does not exist", func() {
+		It("lists the errands of the product", func() {
+			err := command.Execute([]string{"--product-name", "cf", "--errand-name", "nope"})
+			Expect(err).To(MatchError(`errand "nope" not found in cf: errands available are: smoke_tests, push-apps-manager, delete-apps`))
+			Expect(service.CreateInstallationCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the errand is not a post-deploy errand", func() {
+		It("returns an error", func() {
+			err := command.Execute([]string{"--product-name", "cf", "--errand-name", "delete-apps"})
+			Expect(err).To(MatchError(`errand "delete-apps" of cf is not a post-deploy errand`))
+			Expect(service.CreateInstallationCallCount()).To(Equal(0))
+		})
+	})
+
+	When("the installation cannot be started", func() {
+		It("returns an error without changing the errand states", func() {
+			service.CreateInstallationReturns(api.InstallationsServiceOutput{}, errors.New("installation already running"))
+
+			err := command.Execute([]string{"--product-name", "cf", "--errand-name", "smoke_tests"})
+			Expect(err).To(MatchError("installation failed to trigger: installation already running"))
+			Expect(service.UpdateStagedProductErrandsCallCount()).To(Equal(0))
+		})
+	})
+
+	When("a required flag is missing", func() {
+		It("returns an error", func() {
+			err := command.Execute([]string{"--product-name", "cf"})
+			Expect(err).To(MatchError(`could not parse run-errand flags: missing required flag "--errand-name"`))
+		})
+	})
+})
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"github.com/pivotal-cf/om/interpolate"
 	"os"
 
 	"github.com/pivotal-cf/jhanda"
@@ -9,9 +10,10 @@ import (
 )
 
 type StageProduct struct {
-	logger  logger
-	service stageProductService
-	Options struct {
+	varsSourceConfig interpolate.VarsSourceConfig
+	logger           logger
+	service          stageProductService
+	Options          struct {
 		interpolateConfigFileOptions
 
 		Product string `long:"product-name"    short:"p" required:"true" description:"name of product"`
@@ -28,15 +30,16 @@ type stageProductService interface {
 	Stage(api.StageProductInput, string) error
 }
 
-func NewStageProduct(service stageProductService, logger logger) StageProduct {
+func NewStageProduct(varsSourceConfig interpolate.VarsSourceConfig, service stageProductService, logger logger) StageProduct {
 	return StageProduct{
-		logger:  logger,
-		service: service,
+		varsSourceConfig: varsSourceConfig,
+		logger:           logger,
+		service:          service,
 	}
 }
 
 func (sp StageProduct) Execute(args []string) error {
-	err := loadConfigFile(args, &sp.Options, os.Environ)
+	err := loadConfigFile(args, &sp.Options, os.Environ, sp.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse stage-product flags: %s", err)
 	}
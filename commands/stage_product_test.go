@@ -4,13 +4,17 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"net/http"
 
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
+	"github.com/pivotal-cf/om/vault"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
 )
 
 var _ = Describe("StageProduct", func() {
@@ -27,7 +31,7 @@ var _ = Describe("StageProduct", func() {
 	It("stages a product", func() {
 		fakeService.CheckProductAvailabilityReturns(true, nil)
 
-		command := commands.NewStageProduct(fakeService, logger)
+		command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 
 		fakeService.ListDeployedProductsReturns([]api.DeployedProductOutput{
 			api.DeployedProductOutput{
@@ -71,7 +75,7 @@ product-version: some-version`
 
 			fakeService.CheckProductAvailabilityReturns(true, nil)
 
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 
 			fakeService.ListDeployedProductsReturns([]api.DeployedProductOutput{
 				api.DeployedProductOutput{
@@ -104,13 +108,41 @@ product-version: some-version`
 			format, v = logger.PrintfArgsForCall(1)
 			Expect(fmt.Sprintf(format, v...)).To(Equal("finished staging"))
 		})
+		It("looks up the variables missing from the vars in the vars sources", func() {
+			server := ghttp.NewServer()
+			defer server.Close()
+			server.RouteToHandler("GET", "/v1/sys/internal/ui/mounts/secret/foundation", ghttp.RespondWith(http.StatusOK, `{"data": {"path": "secret/", "options": {"version": "2"}}}`))
+			server.RouteToHandler("GET", "/v1/secret/data/foundation/product_version", ghttp.RespondWith(http.StatusOK, `{"data": {"data": {"value": "some-version"}}}`))
+
+			configFile, err := ioutil.TempFile("", "config.yml")
+			Expect(err).ToNot(HaveOccurred())
+			defer configFile.Close()
+
+			_, err = configFile.WriteString(`{product-name: some-product, product-version: ((product_version))}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			fakeService.CheckProductAvailabilityReturns(true, nil)
+
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{
+				Sources: []string{"vault://secret/foundation"},
+				Vault:   vault.Config{Address: server.URL(), Token: "some-token"},
+			}, fakeService, logger)
+
+			err = command.Execute([]string{
+				"--config", configFile.Name(),
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			stageProductInput, _ := fakeService.StageArgsForCall(0)
+			Expect(stageProductInput.ProductVersion).To(Equal("some-version"))
+		})
 	})
 
 	When("a product has already been deployed", func() {
 		It("stages the product", func() {
 			fakeService.CheckProductAvailabilityReturns(true, nil)
 
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 
 			fakeService.ListDeployedProductsReturns([]api.DeployedProductOutput{
 				api.DeployedProductOutput{
@@ -159,7 +191,7 @@ product-version: some-version`
 				},
 			}, nil)
 
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 
 			err := command.Execute([]string{
 				"--product-name", "some-product",
@@ -188,7 +220,7 @@ product-version: some-version`
 			}, nil)
 		})
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 			err := command.Execute([]string{"--product-name", "cf", "--product-version", "some-version"})
 			Expect(err).To(MatchError("OpsManager does not allow configuration or staging changes while apply changes are running to prevent data loss for configuration and/or staging changes"))
 			Expect(fakeService.ListInstallationsCallCount()).To(Equal(1))
@@ -197,7 +229,7 @@ product-version: some-version`
 
 	When("an unknown flag is provided", func() {
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 			err := command.Execute([]string{"--badflag"})
 			Expect(err).To(MatchError("could not parse stage-product flags: flag provided but not defined: -badflag"))
 		})
@@ -205,7 +237,7 @@ product-version: some-version`
 
 	When("the product-name flag is not provided", func() {
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 			err := command.Execute([]string{"--product-version", "1.0"})
 			Expect(err).To(MatchError("could not parse stage-product flags: missing required flag \"--product-name\""))
 		})
@@ -213,7 +245,7 @@ product-version: some-version`
 
 	When("the product-version flag is not provided", func() {
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 			err := command.Execute([]string{"--product-name", "some-product"})
 			Expect(err).To(MatchError("could not parse stage-product flags: missing required flag \"--product-version\""))
 		})
@@ -225,7 +257,7 @@ product-version: some-version`
 		})
 
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 
 			err := command.Execute([]string{
 				"--product-name", "some-product",
@@ -241,7 +273,7 @@ product-version: some-version`
 		})
 
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 
 			err := command.Execute([]string{
 				"--product-name", "some-product",
@@ -253,7 +285,7 @@ product-version: some-version`
 
 	When("the product cannot be staged", func() {
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 			fakeService.CheckProductAvailabilityReturns(true, nil)
 			fakeService.StageReturns(errors.New("some product error"))
 
@@ -264,7 +296,7 @@ product-version: some-version`
 
 	When("the diagnostic report cannot be fetched", func() {
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 			fakeService.CheckProductAvailabilityReturns(true, nil)
 			fakeService.GetDiagnosticReportReturns(api.DiagnosticReport{}, errors.New("bad diagnostic report"))
 
@@ -281,7 +313,7 @@ product-version: some-version`
 		})
 
 		It("returns an error", func() {
-			command := commands.NewStageProduct(fakeService, logger)
+			command := commands.NewStageProduct(interpolate.VarsSourceConfig{}, fakeService, logger)
 
 			err := command.Execute([]string{
 				"--product-name", "some-product",
@@ -11,7 +11,7 @@ type StagedProducts struct {
 	presenter presenters.FormattedPresenter
 	service   diagnosticReportService
 	Options   struct {
-		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json)"`
+		Format string `long:"format" short:"f" default:"table" description:"Format to print as (options: table,json,yaml,csv,template=<go-template>)"`
 	}
 }
 
@@ -34,7 +34,10 @@ func (sp StagedProducts) Execute(args []string) error {
 
 	stagedProducts := diagnosticReport.StagedProducts
 
-	sp.presenter.SetFormat(sp.Options.Format)
+	err = sp.presenter.SetFormat(sp.Options.Format)
+	if err != nil {
+		return err
+	}
 	sp.presenter.PresentStagedProducts(stagedProducts)
 
 	return nil
@@ -2,23 +2,26 @@ package commands
 
 import (
 	"fmt"
+	"github.com/pivotal-cf/om/interpolate"
 	"os"
+	"time"
 
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/extractor"
+	"github.com/pivotal-cf/om/formcontent"
 	"github.com/pivotal-cf/om/network"
 	"github.com/pivotal-cf/om/validator"
 )
 
-const maxProductUploadRetries = 2
-
 type UploadProduct struct {
-	multipart multipart
-	logger    logger
-	service   uploadProductService
-	Options   struct {
+	varsSourceConfig interpolate.VarsSourceConfig
+	multipart        multipart
+	logger           logger
+	service          uploadProductService
+	Options          struct {
 		interpolateConfigFileOptions
+		uploadRetryOptions
 
 		Product         string `long:"product"          short:"p"   description:"path to product" required:"true"`
 		PollingInterval int    `long:"polling-interval" short:"pi"  description:"interval (in seconds) at which to print status" default:"1"`
@@ -40,8 +43,9 @@ type metadataExtractor interface {
 	ExtractFromFile(string) (*extractor.Metadata, error)
 }
 
-func NewUploadProduct(multipart multipart, metadataExtractor metadataExtractor, service uploadProductService, logger logger) UploadProduct {
+func NewUploadProduct(varsSourceConfig interpolate.VarsSourceConfig, multipart multipart, metadataExtractor metadataExtractor, service uploadProductService, logger logger) UploadProduct {
 	return UploadProduct{
+		varsSourceConfig:  varsSourceConfig,
 		multipart:         multipart,
 		metadataExtractor: metadataExtractor,
 		logger:            logger,
@@ -58,7 +62,7 @@ func (up UploadProduct) Usage() jhanda.Usage {
 }
 
 func (up UploadProduct) Execute(args []string) error {
-	err := loadConfigFile(args, &up.Options, os.Environ)
+	err := loadConfigFile(args, &up.Options, os.Environ, up.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse upload-product flags: %s", err)
 	}
@@ -103,7 +107,9 @@ func (up UploadProduct) Execute(args []string) error {
 		}
 	}
 
-	for i := 0; i <= maxProductUploadRetries; i++ {
+	var submission formcontent.ContentSubmission
+	stats := newUploadStats()
+	for i := 0; i <= up.Options.UploadRetries; i++ {
 		up.logger.Printf("processing product")
 
 		err = up.multipart.AddFile("product[file]", up.Options.Product)
@@ -111,7 +117,8 @@ func (up UploadProduct) Execute(args []string) error {
 			return fmt.Errorf("failed to load product: %s", err)
 		}
 
-		submission := up.multipart.Finalize()
+		submission = up.multipart.Finalize()
+		stats.attempt()
 
 		up.logger.Printf("beginning product upload to Ops Manager")
 
@@ -121,16 +128,23 @@ func (up UploadProduct) Execute(args []string) error {
 			ContentLength:   submission.ContentLength,
 			PollingInterval: up.Options.PollingInterval,
 		})
-		if network.CanRetry(err) && i < maxProductUploadRetries {
+		stats.attemptFinished()
+		if network.CanRetry(err) && i < up.Options.UploadRetries {
 			up.logger.Printf("retrying product upload after error: %s\n", err)
 			up.multipart.Reset()
 
+			backoff := up.Options.backoff(i)
+			up.logger.Printf("waiting %s before retrying", backoff)
+			time.Sleep(backoff)
+
+			// the upload may have completed even though the connection failed
 			prodAvailable, err = up.service.CheckProductAvailability(metadata.Name, metadata.Version)
 			if err != nil {
 				return fmt.Errorf("failed to check product availability: %s", err)
 			}
 			if prodAvailable {
 				up.logger.Printf("product %s %s has been successfully uploaded", metadata.Name, metadata.Version)
+				up.logger.Println(stats.summary(submission.ContentLength))
 				return nil
 			}
 		} else {
@@ -142,6 +156,7 @@ func (up UploadProduct) Execute(args []string) error {
 	}
 
 	up.logger.Printf("finished upload")
+	up.logger.Println(stats.summary(submission.ContentLength))
 
 	return nil
 }
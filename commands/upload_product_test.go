@@ -16,6 +16,7 @@ import (
 	"github.com/pivotal-cf/om/commands/fakes"
 	"github.com/pivotal-cf/om/extractor"
 	"github.com/pivotal-cf/om/formcontent"
+	"github.com/pivotal-cf/om/interpolate"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -45,7 +46,7 @@ var _ = Describe("UploadProduct", func() {
 		}
 		multipart.FinalizeReturns(submission)
 
-		command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+		command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 
 		err := command.Execute([]string{
 			"--product", "/path/to/some-product.tgz",
@@ -76,7 +77,7 @@ var _ = Describe("UploadProduct", func() {
 
 	When("the polling interval is provided", func() {
 		It("passes the value to the products service", func() {
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 			err := command.Execute([]string{
 				"--product", "/path/to/some-product.tgz",
 				"--polling-interval", "48",
@@ -88,7 +89,7 @@ var _ = Describe("UploadProduct", func() {
 
 	When("the same product is already present", func() {
 		It("does nothing and exits gracefully", func() {
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 			metadataExtractor.ExtractFromFileReturns(&extractor.Metadata{
 				Name:    "cf",
 				Version: "1.5.0",
@@ -113,7 +114,7 @@ var _ = Describe("UploadProduct", func() {
 
 		When("--force is specified", func() {
 			It("uploads the product", func() {
-				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+				command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 				metadataExtractor.ExtractFromFileReturns(&extractor.Metadata{
 					Name:    "cf",
 					Version: "1.5.0",
@@ -147,7 +148,7 @@ var _ = Describe("UploadProduct", func() {
 			err = file.Close()
 			Expect(err).ToNot(HaveOccurred())
 
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 			metadataExtractor.ExtractFromFileReturns(&extractor.Metadata{
 				Name:    "cf",
 				Version: "1.5.0",
@@ -181,7 +182,7 @@ var _ = Describe("UploadProduct", func() {
 			err = file.Close()
 			Expect(err).ToNot(HaveOccurred())
 
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 			err = command.Execute([]string{
 				"--product", file.Name(),
 				"--shasum", "not-the-correct-shasum",
@@ -191,7 +192,7 @@ var _ = Describe("UploadProduct", func() {
 		})
 
 		It("fails when the file can not calculate a shasum", func() {
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 			err := command.Execute([]string{
 				"--product", "/path/to/testing.tgz",
 				"--shasum", "not-the-correct-shasum",
@@ -213,7 +214,7 @@ var _ = Describe("UploadProduct", func() {
 				Name:    "cf",
 				Version: "1.5.0",
 			}, nil)
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 			fakeService.CheckProductAvailabilityStub = func(name, version string) (bool, error) {
 				if name == "cf" && version == "1.5.0" {
 					return true, nil
@@ -244,7 +245,7 @@ var _ = Describe("UploadProduct", func() {
 				Name:    "cf",
 				Version: "1.5.0",
 			}, nil)
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 			err = command.Execute([]string{
 				"--product", file.Name(),
 				"--product-version", "2.5.0",
@@ -260,7 +261,7 @@ var _ = Describe("UploadProduct", func() {
 				stdout := gbytes.NewBuffer()
 				logger := log.New(stdout, "", 0)
 
-				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+				command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 
 				fakeService.UploadAvailableProductReturnsOnCall(0, api.UploadAvailableProductOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
 				fakeService.UploadAvailableProductReturnsOnCall(1, api.UploadAvailableProductOutput{}, nil)
@@ -273,6 +274,7 @@ var _ = Describe("UploadProduct", func() {
 
 				err := command.Execute([]string{
 					"--product", "/path/to/some-product.tgz",
+					"--upload-retry-backoff", "0s",
 				})
 
 				Expect(err).ToNot(HaveOccurred())
@@ -285,12 +287,12 @@ var _ = Describe("UploadProduct", func() {
 		})
 
 		It("tries again", func() {
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 
 			fakeService.UploadAvailableProductReturnsOnCall(0, api.UploadAvailableProductOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
 			fakeService.UploadAvailableProductReturnsOnCall(1, api.UploadAvailableProductOutput{}, nil)
 
-			err := command.Execute([]string{"--product", "/some/path"})
+			err := command.Execute([]string{"--product", "/some/path", "--upload-retry-backoff", "0s"})
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(multipart.AddFileCallCount()).To(Equal(2))
@@ -303,12 +305,12 @@ var _ = Describe("UploadProduct", func() {
 
 	When("the product fails to upload three times", func() {
 		It("returns an error", func() {
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 
 			fakeService.CheckProductAvailabilityReturns(false, nil)
 			fakeService.UploadAvailableProductReturns(api.UploadAvailableProductOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
 
-			err := command.Execute([]string{"--product", "/some/path"})
+			err := command.Execute([]string{"--product", "/some/path", "--upload-retry-backoff", "0s"})
 
 			Expect(multipart.AddFileCallCount()).To(Equal(3))
 			Expect(multipart.FinalizeCallCount()).To(Equal(3))
@@ -320,6 +322,40 @@ var _ = Describe("UploadProduct", func() {
 		})
 	})
 
+	When("the product fails to upload with a retryable error more than once", func() {
+		It("waits twice as long before each retry and reports the throughput of the last attempt", func() {
+			stdout := gbytes.NewBuffer()
+			logger := log.New(stdout, "", 0)
+
+			multipart.FinalizeReturns(formcontent.ContentSubmission{ContentLength: 2048})
+			fakeService.UploadAvailableProductReturnsOnCall(0, api.UploadAvailableProductOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
+			fakeService.UploadAvailableProductReturnsOnCall(1, api.UploadAvailableProductOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
+			fakeService.UploadAvailableProductReturnsOnCall(2, api.UploadAvailableProductOutput{}, nil)
+
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
+			err := command.Execute([]string{"--product", "/some/path", "--upload-retry-backoff", "100ms"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeService.UploadAvailableProductCallCount()).To(Equal(3))
+			Expect(stdout).To(gbytes.Say("waiting 100ms before retrying"))
+			Expect(stdout).To(gbytes.Say("waiting 200ms before retrying"))
+			Expect(stdout).To(gbytes.Say("finished upload"))
+			// the waits between the attempts are left out of the throughput, but not of the total
+			Expect(stdout).To(gbytes.Say(`uploaded 2\.0 KiB in (0s|\d{1,2}ms) \(.+/s\) after 3 attempts, (\d{3}ms|\d+\.\d+s) in total`))
+		})
+
+		It("does not retry when retries are disabled", func() {
+			fakeService.UploadAvailableProductReturns(api.UploadAvailableProductOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
+
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
+			err := command.Execute([]string{"--product", "/some/path", "--upload-retries", "0"})
+			Expect(err).To(MatchError(ContainSubstring("EOF")))
+
+			Expect(fakeService.UploadAvailableProductCallCount()).To(Equal(1))
+			Expect(multipart.ResetCallCount()).To(Equal(0))
+		})
+	})
+
 	When("config file is provided", func() {
 		var configFile *os.File
 
@@ -347,7 +383,7 @@ product: will-be-overridden-by-command-line
 				Name:    "cf",
 				Version: "1.5.0",
 			}, nil)
-			command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+			command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 			fakeService.CheckProductAvailabilityStub = func(name, version string) (bool, error) {
 				if name == "cf" && version == "1.5.0" {
 					return true, nil
@@ -371,7 +407,7 @@ product: will-be-overridden-by-command-line
 	Context("failure cases", func() {
 		When("an unknown flag is provided", func() {
 			It("returns an error", func() {
-				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+				command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 				err := command.Execute([]string{"--badflag"})
 				Expect(err).To(MatchError("could not parse upload-product flags: flag provided but not defined: -badflag"))
 			})
@@ -379,7 +415,7 @@ product: will-be-overridden-by-command-line
 
 		When("the product flag is not provided", func() {
 			It("returns an error", func() {
-				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+				command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 				err := command.Execute([]string{})
 				Expect(err).To(MatchError("could not parse upload-product flags: missing required flag \"--product\""))
 			})
@@ -388,7 +424,7 @@ product: will-be-overridden-by-command-line
 		When("extracting the product metadata returns an error", func() {
 			It("returns an error", func() {
 				metadataExtractor.ExtractFromFileReturns(&extractor.Metadata{}, errors.New("some error"))
-				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+				command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 				err := command.Execute([]string{"--product", "/some/path"})
 				Expect(err).To(MatchError("failed to extract product metadata: some error"))
 			})
@@ -397,7 +433,7 @@ product: will-be-overridden-by-command-line
 		When("checking for product availability returns an error", func() {
 			It("returns an error", func() {
 				fakeService.CheckProductAvailabilityReturns(true, errors.New("some error"))
-				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+				command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 				err := command.Execute([]string{"--product", "/some/path"})
 				Expect(err).To(MatchError("failed to check product availability: some error"))
 			})
@@ -405,7 +441,7 @@ product: will-be-overridden-by-command-line
 
 		When("adding the file fails", func() {
 			It("returns an error", func() {
-				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+				command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 				multipart.AddFileReturns(errors.New("bad file"))
 
 				err := command.Execute([]string{"--product", "/some/path"})
@@ -415,7 +451,7 @@ product: will-be-overridden-by-command-line
 
 		When("the product cannot be uploaded", func() {
 			It("returns an error", func() {
-				command := commands.NewUploadProduct(multipart, metadataExtractor, fakeService, logger)
+				command := commands.NewUploadProduct(interpolate.VarsSourceConfig{}, multipart, metadataExtractor, fakeService, logger)
 				fakeService.UploadAvailableProductReturns(api.UploadAvailableProductOutput{}, errors.New("some product error"))
 
 				err := command.Execute([]string{"--product", "/some/path"})
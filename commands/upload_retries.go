@@ -0,0 +1,71 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+)
+
+// uploadRetryOptions are shared by the commands uploading large files to the Ops Manager.
+type uploadRetryOptions struct {
+	UploadRetries      int           `long:"upload-retries"       default:"2"   description:"how many times to retry an upload that failed because of a network error"`
+	UploadRetryBackoff time.Duration `long:"upload-retry-backoff" default:"10s" description:"how long to wait before retrying a failed upload, doubled for every further retry (e.g. 30s, 1m)"`
+}
+
+// backoff is how long to wait before the given retry, counting from zero.
+func (o uploadRetryOptions) backoff(retry int) time.Duration {
+	return o.UploadRetryBackoff << uint(retry)
+}
+
+// uploadStats times an upload, measuring its throughput over the last attempt only,
+// so that failed attempts and the waits between them do not lower it.
+type uploadStats struct {
+	started        time.Time
+	attemptStarted time.Time
+	attemptElapsed time.Duration
+	attempts       int
+}
+
+func newUploadStats() *uploadStats {
+	return &uploadStats{started: time.Now()}
+}
+
+func (s *uploadStats) attempt() {
+	s.attempts++
+	s.attemptStarted = time.Now()
+}
+
+func (s *uploadStats) attemptFinished() {
+	s.attemptElapsed = time.Since(s.attemptStarted)
+}
+
+// summary reports the throughput of an upload that sent the given number of bytes.
+func (s *uploadStats) summary(sent int64) string {
+	elapsed := s.attemptElapsed
+
+	var throughput int64
+	if elapsed > 0 {
+		throughput = int64(float64(sent) / elapsed.Seconds())
+	}
+
+	summary := fmt.Sprintf("uploaded %s in %s (%s/s)", formatBytes(sent), elapsed.Round(time.Millisecond), formatBytes(throughput))
+	if s.attempts > 1 {
+		summary += fmt.Sprintf(" after %d attempts, %s in total", s.attempts, time.Since(s.started).Round(time.Millisecond))
+	}
+
+	return summary
+}
+
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
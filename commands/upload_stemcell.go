@@ -6,21 +6,23 @@ import (
 	"github.com/pivotal-cf/jhanda"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/formcontent"
+	"github.com/pivotal-cf/om/interpolate"
 	"github.com/pivotal-cf/om/network"
 	"github.com/pivotal-cf/om/validator"
 	"os"
 	"path/filepath"
 	"regexp"
+	"time"
 )
 
-const maxStemcellUploadRetries = 2
-
 type UploadStemcell struct {
-	multipart multipart
-	logger    logger
-	service   uploadStemcellService
-	Options   struct {
+	varsSourceConfig interpolate.VarsSourceConfig
+	multipart        multipart
+	logger           logger
+	service          uploadStemcellService
+	Options          struct {
 		interpolateConfigFileOptions
+		uploadRetryOptions
 
 		Stemcell string `long:"stemcell" short:"s" required:"true" description:"path to stemcell (NOTE: use absolute path)"`
 		Force    bool   `long:"force"    short:"f"                 description:"upload stemcell even if it already exists on the target Ops Manager"`
@@ -45,11 +47,12 @@ type uploadStemcellService interface {
 	Info() (api.Info, error)
 }
 
-func NewUploadStemcell(multipart multipart, service uploadStemcellService, logger logger) UploadStemcell {
+func NewUploadStemcell(varsSourceConfig interpolate.VarsSourceConfig, multipart multipart, service uploadStemcellService, logger logger) UploadStemcell {
 	return UploadStemcell{
-		multipart: multipart,
-		logger:    logger,
-		service:   service,
+		varsSourceConfig: varsSourceConfig,
+		multipart:        multipart,
+		logger:           logger,
+		service:          service,
 	}
 }
 
@@ -62,7 +65,7 @@ func (us UploadStemcell) Usage() jhanda.Usage {
 }
 
 func (us UploadStemcell) Execute(args []string) error {
-	err := loadConfigFile(args, &us.Options, os.Environ)
+	err := loadConfigFile(args, &us.Options, os.Environ, us.varsSourceConfig)
 	if err != nil {
 		return fmt.Errorf("could not parse upload-stemcell flags: %s", err)
 	}
@@ -109,7 +112,10 @@ func (us UploadStemcell) Execute(args []string) error {
 }
 
 func (us UploadStemcell) uploadStemcell(stemcellFilename string) (err error) {
-	for i := 0; i <= maxStemcellUploadRetries; i++ {
+	var submission formcontent.ContentSubmission
+	stats := newUploadStats()
+
+	for i := 0; i <= us.Options.UploadRetries; i++ {
 		err = us.multipart.AddFile("stemcell[file]", stemcellFilename)
 		if err != nil {
 			return err
@@ -120,10 +126,11 @@ func (us UploadStemcell) uploadStemcell(stemcellFilename string) (err error) {
 			return err
 		}
 
-		submission := us.multipart.Finalize()
+		submission = us.multipart.Finalize()
 		if err != nil {
 			return fmt.Errorf("failed to create multipart form: %s", err)
 		}
+		stats.attempt()
 
 		us.logger.Printf("beginning stemcell upload to Ops Manager")
 
@@ -132,15 +139,35 @@ func (us UploadStemcell) uploadStemcell(stemcellFilename string) (err error) {
 			ContentType:   submission.ContentType,
 			ContentLength: submission.ContentLength,
 		})
-		if network.CanRetry(err) && i < maxStemcellUploadRetries {
+		stats.attemptFinished()
+		if network.CanRetry(err) && i < us.Options.UploadRetries {
 			us.logger.Printf("retrying stemcell upload after error: %s\n", err)
 			us.multipart.Reset()
+
+			backoff := us.Options.backoff(i)
+			us.logger.Printf("waiting %s before retrying", backoff)
+			time.Sleep(backoff)
+
+			// the upload may have completed even though the connection failed
+			found, err := us.service.CheckStemcellAvailability(us.Options.Stemcell)
+			if err != nil {
+				return err
+			}
+			if found {
+				us.logger.Printf("stemcell has been successfully uploaded")
+				us.logger.Println(stats.summary(submission.ContentLength))
+				return nil
+			}
 		} else {
 			break
 		}
 	}
+	if err != nil {
+		return err
+	}
 
-	return err
+	us.logger.Println(stats.summary(submission.ContentLength))
+	return nil
 }
 
 func (us UploadStemcell) validate() error {
@@ -5,13 +5,16 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log"
 	"os"
 	"strings"
 
+	"github.com/onsi/gomega/gbytes"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands"
 	"github.com/pivotal-cf/om/commands/fakes"
 	"github.com/pivotal-cf/om/formcontent"
+	"github.com/pivotal-cf/om/interpolate"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -42,7 +45,7 @@ var _ = Describe("UploadStemcell", func() {
 
 			fakeService.GetDiagnosticReportReturns(api.DiagnosticReport{Stemcells: []string{}}, nil)
 
-			command := commands.NewUploadStemcell(multipart, fakeService, logger)
+			command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 
 			err := command.Execute([]string{
 				"--stemcell", "/path/to/stemcell.tgz",
@@ -88,7 +91,7 @@ var _ = Describe("UploadStemcell", func() {
 
 				fakeService.GetDiagnosticReportReturns(api.DiagnosticReport{Stemcells: []string{}}, nil)
 
-				command = commands.NewUploadStemcell(multipart, fakeService, logger)
+				command = commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 			})
 
 			It("disables floating", func() {
@@ -157,13 +160,14 @@ var _ = Describe("UploadStemcell", func() {
 
 				fakeService.GetDiagnosticReportReturns(api.DiagnosticReport{Stemcells: []string{}}, nil)
 
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 
 				fakeService.UploadStemcellReturnsOnCall(0, api.StemcellUploadOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
 				fakeService.UploadStemcellReturnsOnCall(1, api.StemcellUploadOutput{}, nil)
 
 				err := command.Execute([]string{
 					"--stemcell", "/path/to/stemcell.tgz",
+					"--upload-retry-backoff", "0s",
 				})
 				Expect(err).ToNot(HaveOccurred())
 
@@ -175,6 +179,31 @@ var _ = Describe("UploadStemcell", func() {
 			})
 		})
 
+		When("the stemcell is present after failing to upload with a retryable error", func() {
+			It("does not upload it again", func() {
+				stdout := gbytes.NewBuffer()
+				logger := log.New(stdout, "", 0)
+
+				fakeService.InfoReturns(api.Info{Version: "2.2-build.1"}, nil)
+				multipart.FinalizeReturns(formcontent.ContentSubmission{ContentLength: 10})
+				fakeService.UploadStemcellReturns(api.StemcellUploadOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
+				fakeService.CheckStemcellAvailabilityReturnsOnCall(0, false, nil)
+				fakeService.CheckStemcellAvailabilityReturnsOnCall(1, true, nil)
+
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
+				err := command.Execute([]string{
+					"--stemcell", "/path/to/stemcell.tgz",
+					"--upload-retry-backoff", "0s",
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(fakeService.UploadStemcellCallCount()).To(Equal(1))
+				Expect(stdout).To(gbytes.Say("retrying stemcell upload after error: some upload error: EOF"))
+				Expect(stdout).To(gbytes.Say("stemcell has been successfully uploaded"))
+				Expect(stdout).To(gbytes.Say(`uploaded 10 B in .+ \(.+/s\)\n`))
+			})
+		})
+
 		When("the product fails to upload three times", func() {
 			It("returns an error", func() {
 				fakeService.InfoReturns(api.Info{Version: "2.2-build.1"}, nil)
@@ -187,12 +216,13 @@ var _ = Describe("UploadStemcell", func() {
 
 				fakeService.GetDiagnosticReportReturns(api.DiagnosticReport{Stemcells: []string{}}, nil)
 
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 
 				fakeService.UploadStemcellReturns(api.StemcellUploadOutput{}, fmt.Errorf("some upload error: %w", io.EOF))
 
 				err := command.Execute([]string{
 					"--stemcell", "/path/to/stemcell.tgz",
+					"--upload-retry-backoff", "0s",
 				})
 				Expect(err).To(MatchError(ContainSubstring("EOF")))
 
@@ -216,7 +246,7 @@ var _ = Describe("UploadStemcell", func() {
 				multipart.FinalizeReturns(submission)
 				fakeService.CheckStemcellAvailabilityReturns(true, nil)
 
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 
 				err := command.Execute([]string{
 					"--stemcell", "/path/to/stemcell.tgz",
@@ -239,7 +269,7 @@ var _ = Describe("UploadStemcell", func() {
 
 				fakeService.CheckStemcellAvailabilityReturns(true, nil)
 
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 
 				err := command.Execute([]string{
 					"--stemcell", "/path/to/stemcell.tgz",
@@ -288,7 +318,7 @@ var _ = Describe("UploadStemcell", func() {
 
 			fakeService.GetDiagnosticReportReturns(api.DiagnosticReport{Stemcells: []string{}}, nil)
 
-			command := commands.NewUploadStemcell(multipart, fakeService, logger)
+			command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 			err = command.Execute([]string{
 				"--stemcell", file.Name(),
 				"--shasum", "2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44",
@@ -307,7 +337,7 @@ var _ = Describe("UploadStemcell", func() {
 			err = file.Close()
 			Expect(err).ToNot(HaveOccurred())
 
-			command := commands.NewUploadStemcell(multipart, fakeService, logger)
+			command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 			err = command.Execute([]string{
 				"--stemcell", file.Name(),
 				"--shasum", "not-the-correct-shasum",
@@ -316,7 +346,7 @@ var _ = Describe("UploadStemcell", func() {
 			Expect(err).To(MatchError("expected shasum not-the-correct-shasum does not match file shasum 2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44"))
 		})
 		It("fails when the file can not calculate a shasum", func() {
-			command := commands.NewUploadStemcell(multipart, fakeService, logger)
+			command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 			err := command.Execute([]string{
 				"--stemcell", "/path/to/testing.tgz",
 				"--shasum", "2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44",
@@ -338,7 +368,7 @@ var _ = Describe("UploadStemcell", func() {
 
 			fakeService.GetDiagnosticReportReturns(api.DiagnosticReport{}, api.DiagnosticReportUnavailable{})
 
-			command := commands.NewUploadStemcell(multipart, fakeService, logger)
+			command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 
 			err := command.Execute([]string{
 				"--stemcell", "/path/to/stemcell.tgz",
@@ -412,7 +442,7 @@ shasum: 2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44
 
 		It("reads configuration from config file", func() {
 			fakeService.InfoReturns(api.Info{Version: "2.2-build.1"}, nil)
-			command := commands.NewUploadStemcell(multipart, fakeService, logger)
+			command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 			err := command.Execute([]string{
 				"--stemcell", file.Name(),
 				"--config", configFile.Name(),
@@ -426,7 +456,7 @@ shasum: 2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44
 	Context("failure cases", func() {
 		When("an unknown flag is provided", func() {
 			It("returns an error", func() {
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 				err := command.Execute([]string{"--badflag"})
 				Expect(err).To(MatchError("could not parse upload-stemcell flags: flag provided but not defined: -badflag"))
 			})
@@ -434,7 +464,7 @@ shasum: 2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44
 
 		When("the --stemcell flag is missing", func() {
 			It("returns an error", func() {
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 				err := command.Execute([]string{})
 				Expect(err).To(MatchError("could not parse upload-stemcell flags: missing required flag \"--stemcell\""))
 			})
@@ -443,7 +473,7 @@ shasum: 2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44
 		When("the file cannot be opened", func() {
 			It("returns an error", func() {
 				fakeService.InfoReturns(api.Info{Version: "2.2-build.1"}, nil)
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 				multipart.AddFileReturns(errors.New("bad file"))
 
 				err := command.Execute([]string{"--stemcell", "/some/path"})
@@ -454,7 +484,7 @@ shasum: 2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44
 		When("the stemcell cannot be uploaded", func() {
 			It("returns an error", func() {
 				fakeService.InfoReturns(api.Info{Version: "2.2-build.1"}, nil)
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 				fakeService.UploadStemcellReturns(api.StemcellUploadOutput{}, errors.New("some stemcell error"))
 
 				err := command.Execute([]string{"--stemcell", "/some/path"})
@@ -464,7 +494,7 @@ shasum: 2815ab9694a4a2cfd59424a734833010e143a0b2db20be3741507f177f289f44
 
 		When("the stemcell availability cannot be fetched", func() {
 			It("returns an error", func() {
-				command := commands.NewUploadStemcell(multipart, fakeService, logger)
+				command := commands.NewUploadStemcell(interpolate.VarsSourceConfig{}, multipart, fakeService, logger)
 				fakeService.CheckStemcellAvailabilityReturns(false, errors.New("some diagnostic error"))
 
 				err := command.Execute([]string{"--stemcell", "/some/path"})
@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/pivotal-cf/jhanda"
+	"github.com/pivotal-cf/om/configtemplate/validator"
+	"github.com/pivotal-cf/om/interpolate"
+)
+
+type ValidateProductConfig struct {
+	environFunc      func() []string
+	varsSourceConfig interpolate.VarsSourceConfig
+	metadataProvider func(productPath string) MetadataProvider
+	logger           logger
+	Options          struct {
+		ProductPath string   `long:"product-path" short:"p"         description:"path to the product file to validate the config against" required:"true"`
+		ConfigFile  string   `long:"config"       short:"c"         description:"path to the product config to validate, as passed to configure-product" required:"true"`
+		VarsFile    []string `long:"vars-file"    short:"l"         description:"load variables from a YAML file"`
+		Vars        []string `long:"var"          short:"v"         description:"load variable from the command line. Format: VAR=VAL"`
+		VarsEnv     []string `long:"vars-env"     env:"OM_VARS_ENV" description:"load variables from environment variables (e.g.: 'MY' to load MY_var=value)"`
+		OpsFile     []string `long:"ops-file"     short:"o"         description:"YAML operations file"`
+	}
+}
+
+func NewValidateProductConfig(environFunc func() []string, varsSourceConfig interpolate.VarsSourceConfig, metadataProvider func(productPath string) MetadataProvider, logger logger) ValidateProductConfig {
+	return ValidateProductConfig{
+		environFunc:      environFunc,
+		varsSourceConfig: varsSourceConfig,
+		metadataProvider: metadataProvider,
+		logger:           logger,
+	}
+}
+
+func (v ValidateProductConfig) Execute(args []string) error {
+	if _, err := jhanda.Parse(&v.Options, args); err != nil {
+		return fmt.Errorf("could not parse validate-product-config flags: %s", err)
+	}
+
+	// variables that are not provided stay placeholders,
+	// and are not type checked
+	config, err := interpolate.Execute(interpolate.Options{
+		TemplateFile:     v.Options.ConfigFile,
+		VarsFiles:        v.Options.VarsFile,
+		Vars:             v.Options.Vars,
+		EnvironFunc:      v.environFunc,
+		VarsEnvs:         v.Options.VarsEnv,
+		OpsFiles:         v.Options.OpsFile,
+		VarsSourceConfig: v.varsSourceConfig,
+		ExpectAllKeys:    false,
+	})
+	if err != nil {
+		return err
+	}
+
+	metadata, err := v.metadataProvider(v.Options.ProductPath).MetadataBytes()
+	if err != nil {
+		return err
+	}
+
+	problems, err := validator.Validate(metadata, config)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		v.logger.Printf("%s is a valid config for %s", v.Options.ConfigFile, v.Options.ProductPath)
+		return nil
+	}
+
+	for _, problem := range problems {
+		v.logger.Println(problem)
+	}
+
+	return fmt.Errorf("%s is not a valid config for %s: found %d problem(s)", v.Options.ConfigFile, v.Options.ProductPath, len(problems))
+}
+
+func (v ValidateProductConfig) Usage() jhanda.Usage {
+	return jhanda.Usage{
+		Description:      "This command validates a product config against the metadata of a product file, without an Ops Manager. It checks property names, types, selector options, required properties, collections, resource-config job names and errand names, and reports every problem it finds.",
+		ShortDescription: "validates a product config against a product file offline",
+		Flags:            v.Options,
+	}
+}
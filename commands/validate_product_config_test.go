@@ -0,0 +1,105 @@
+package commands_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/commands"
+	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/interpolate"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidateProductConfig", func() {
+	var (
+		provider     *fakes.MetadataProvider
+		stdout       *gbytes.Buffer
+		configFile   string
+		productPaths []string
+	)
+
+	newCommand := func() commands.ValidateProductConfig {
+		return commands.NewValidateProductConfig(func() []string { return nil }, interpolate.VarsSourceConfig{}, func(productPath string) commands.MetadataProvider {
+			productPaths = append(productPaths, productPath)
+			return provider
+		}, log.New(stdout, "", 0))
+	}
+
+	BeforeEach(func() {
+		provider = &fakes.MetadataProvider{}
+		provider.MetadataBytesReturns([]byte(`
+name: example-product
+property_blueprints:
+- name: port
+  type: port
+  configurable: true
+  default: 8080
+`), nil)
+		stdout = gbytes.NewBuffer()
+		productPaths = nil
+
+		file, err := ioutil.TempFile("", "config.yml")
+		Expect(err).ToNot(HaveOccurred())
+		configFile = file.Name()
+		Expect(file.Close()).To(Succeed())
+	})
+
+	AfterEach(func() {
+		Expect(os.Remove(configFile)).To(Succeed())
+	})
+
+	writeConfig := func(contents string) {
+		Expect(ioutil.WriteFile(configFile, []byte(contents), 0600)).To(Succeed())
+	}
+
+	It("succeeds when the config is valid", func() {
+		writeConfig(`{product-name: example-product, product-properties: {.properties.port: {value: ((port))}}}`)
+
+		err := newCommand().Execute([]string{"--product-path", "/path/to/product.pivotal", "--config", configFile, "--var", "port=443"})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(productPaths).To(Equal([]string{"/path/to/product.pivotal"}))
+		Expect(stdout).To(gbytes.Say(`is a valid config for /path/to/product.pivotal`))
+	})
+
+	It("prints every problem and returns an error when the config is invalid", func() {
+		writeConfig(`{product-name: example-product, product-properties: {.properties.port: {value: ((port))}, .properties.host: {value: foo}}}`)
+
+		err := newCommand().Execute([]string{"--product-path", "/path/to/product.pivotal", "--config", configFile, "--var", "port=not-a-port"})
+		Expect(err).To(MatchError(ContainSubstring("is not a valid config for /path/to/product.pivotal: found 2 problem(s)")))
+
+		Expect(stdout).To(gbytes.Say(`/product-properties/.properties.host: is not a property of example-product`))
+		Expect(stdout).To(gbytes.Say(`/product-properties/.properties.port/value: expected an integer, got "not-a-port"`))
+	})
+
+	Context("failure cases", func() {
+		When("the metadata cannot be read", func() {
+			It("returns an error", func() {
+				writeConfig(`{}`)
+				provider.MetadataBytesReturns(nil, errors.New("could not extract metadata"))
+
+				err := newCommand().Execute([]string{"--product-path", "/path/to/product.pivotal", "--config", configFile})
+				Expect(err).To(MatchError("could not extract metadata"))
+			})
+		})
+
+		When("the config file does not exist", func() {
+			It("returns an error", func() {
+				err := newCommand().Execute([]string{"--product-path", "/path/to/product.pivotal", "--config", "/does/not/exist.yml"})
+				Expect(err).To(MatchError(ContainSubstring("could not read file")))
+			})
+		})
+
+		When("an unknown flag is provided", func() {
+			It("returns an error", func() {
+				err := newCommand().Execute([]string{"--badflag"})
+				Expect(err).To(MatchError("could not parse validate-product-config flags: flag provided but not defined: -badflag"))
+			})
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pivotal-cf/om/api"
+)
+
+type installationPoller interface {
+	GetInstallation(id int) (api.InstallationsServiceOutput, error)
+	GetInstallationLogs(id int) (api.InstallationsServiceOutput, error)
+}
+
+// waitForInstallation streams the log of the installation to the logWriter
+// until the installation succeeds or fails,
+// or a signal arrives on interrupts, which may be nil.
+func waitForInstallation(service installationPoller, logWriter logWriter, id int, waitDuration time.Duration, interrupts <-chan os.Signal) error {
+	for {
+		current, err := service.GetInstallation(id)
+		if err != nil {
+			return fmt.Errorf("installation failed to get status: %s", err)
+		}
+
+		install, err := service.GetInstallationLogs(id)
+		if err != nil {
+			return fmt.Errorf("installation failed to get logs: %s", err)
+		}
+
+		err = logWriter.Flush(install.Logs)
+		if err != nil {
+			return fmt.Errorf("installation failed to flush logs: %s", err)
+		}
+
+		switch current.Status {
+		case api.StatusSucceeded:
+			return nil
+		case api.StatusFailed:
+			return fmt.Errorf("installation %d was unsuccessful", id)
+		}
+
+		select {
+		case <-time.After(waitDuration):
+		case sig := <-interrupts:
+			return fmt.Errorf("stopped waiting for installation %d, which is still running on the Ops Manager: interrupted by %s", id, sig)
+		}
+	}
+}
@@ -0,0 +1,516 @@
+package validator
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-cf/om/configtemplate/generator"
+	"gopkg.in/yaml.v2"
+)
+
+// Problem is something wrong with a product config,
+// found at the go-patch style Path (e.g. /product-properties/.properties.foo/value).
+type Problem struct {
+	Path    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+var placeholderRegex = regexp.MustCompile(`^\(\(.+\)\)$`)
+
+var credentialKeys = map[string][]string{
+	"secret":               {"secret"},
+	"simple_credentials":   {"identity", "password"},
+	"rsa_cert_credentials": {"cert_pem", "private_key_pem"},
+	"rsa_pkey_credentials": {"private_key_pem"},
+	"salted_credentials":   {"identity", "password", "salt"},
+}
+
+// Validate checks a product config, in the format of configure-product,
+// against the metadata of the product, without talking to an Ops Manager.
+// Values that are still placeholders are not type checked.
+// It returns every problem it finds, sorted by path.
+func Validate(metadataBytes []byte, configBytes []byte) ([]Problem, error) {
+	metadata, err := generator.NewMetadata(metadataBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse product metadata: %s", err)
+	}
+
+	var config map[string]interface{}
+	err = yaml.Unmarshal(configBytes, &config)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse product config: %s", err)
+	}
+
+	v := validator{metadata: metadata}
+
+	for key, value := range config {
+		path := "/" + key
+
+		switch key {
+		case "product-name":
+			if name, ok := value.(string); ok && name != metadata.Name && !isPlaceholder(name) {
+				v.problem(path, "expected %q, the name of the product, got %q", metadata.Name, name)
+			}
+		case "product-properties":
+			v.validateProductProperties(path, value)
+		case "resource-config":
+			v.validateResourceConfig(path, value)
+		case "errand-config":
+			v.validateErrandConfig(path, value)
+		case "network-properties", "syslog-properties", "validate-config-complete":
+			// these do not depend on the product
+		default:
+			v.problem(path, "is not a recognized top-level key")
+		}
+	}
+
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		return v.problems[i].Path < v.problems[j].Path
+	})
+
+	return v.problems, nil
+}
+
+type validator struct {
+	metadata *generator.Metadata
+	problems []Problem
+}
+
+func (v *validator) problem(path string, format string, args ...interface{}) {
+	v.problems = append(v.problems, Problem{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) validateProductProperties(path string, value interface{}) {
+	properties, ok := asMap(value)
+	if !ok {
+		v.problem(path, "expected a map of property names to properties")
+		return
+	}
+
+	for name, property := range properties {
+		propertyPath := path + "/" + name
+
+		blueprint, found := v.lookup(name)
+		if !found {
+			v.problem(propertyPath, "is not a property of %s", v.metadata.Name)
+			continue
+		}
+
+		if !blueprint.IsConfigurable() {
+			v.problem(propertyPath, "is not configurable")
+			continue
+		}
+
+		fields, ok := asMap(property)
+		if !ok {
+			v.problem(propertyPath, "expected a map with a value or selected_option")
+			continue
+		}
+
+		for field, fieldValue := range fields {
+			switch field {
+			case "value":
+				v.validateValue(propertyPath+"/value", blueprint, fieldValue)
+			case "selected_option":
+				if !blueprint.IsSelector() {
+					v.problem(propertyPath+"/selected_option", "is only valid for selector properties")
+				} else if option, ok := fieldValue.(string); ok && !isPlaceholder(option) && blueprint.OptionTemplate(option) == nil {
+					v.problem(propertyPath+"/selected_option", "expected one of %s, got %q", optionTemplateNames(blueprint), option)
+				}
+			default:
+				v.problem(propertyPath+"/"+field, "is not a recognized key, expected value or selected_option")
+			}
+		}
+	}
+
+	v.validateRequiredProperties(path, properties)
+}
+
+// lookup finds the blueprint of a property reference, which is one of
+// .properties.name, .properties.selector.option.name, .job.name
+// or .job.selector.option.name.
+func (v *validator) lookup(reference string) (*generator.PropertyBlueprint, bool) {
+	parts := strings.Split(strings.TrimPrefix(reference, "."), ".")
+
+	var blueprints []generator.PropertyBlueprint
+	if parts[0] == "properties" {
+		blueprints = v.metadata.PropertyBlueprints
+	} else {
+		job, err := v.metadata.GetJob(parts[0])
+		if err != nil {
+			return nil, false
+		}
+		blueprints = job.PropertyBlueprint
+	}
+
+	switch len(parts) {
+	case 2:
+		return findBlueprint(blueprints, parts[1])
+	case 4:
+		selector, found := findBlueprint(blueprints, parts[1])
+		if !found || !selector.IsSelector() {
+			return nil, false
+		}
+
+		option := selector.OptionTemplate(parts[2])
+		if option == nil {
+			return nil, false
+		}
+
+		return findBlueprint(option.PropertyBlueprints, parts[3])
+	default:
+		return nil, false
+	}
+}
+
+func (v *validator) validateRequiredProperties(path string, properties map[string]interface{}) {
+	for _, input := range v.metadata.PropertyInputs() {
+		blueprint, found := v.lookup(input.Reference)
+		if !found {
+			continue
+		}
+
+		property, configured := properties[input.Reference]
+		if isRequired(blueprint) && !configured {
+			v.problem(path+"/"+input.Reference, "is required")
+		}
+
+		if !blueprint.IsSelector() {
+			continue
+		}
+
+		// the required properties of the selected option are required too
+		var option *generator.OptionTemplate
+		fields, _ := asMap(property)
+		if selected, ok := fields["selected_option"].(string); ok {
+			option = blueprint.OptionTemplate(selected)
+		} else if selected, ok := fields["value"].(string); ok {
+			option = selectValueOptionTemplate(blueprint, selected)
+		} else if blueprint.HasDefault() {
+			option = selectValueOptionTemplate(blueprint, fmt.Sprintf("%v", blueprint.Default))
+		}
+
+		if option == nil {
+			continue
+		}
+
+		for _, sub := range option.PropertyBlueprints {
+			reference := fmt.Sprintf("%s.%s.%s", input.Reference, option.Name, sub.Name)
+			if _, configured := properties[reference]; isRequired(&sub) && !configured {
+				v.problem(path+"/"+reference, "is required when %s is %q", input.Reference, option.Name)
+			}
+		}
+	}
+}
+
+func (v *validator) validateValue(path string, blueprint *generator.PropertyBlueprint, value interface{}) {
+	if value == nil || isPlaceholder(value) {
+		return
+	}
+
+	if keys, ok := credentialKeys[blueprint.Type]; ok {
+		v.validateCredential(path, keys, value)
+		return
+	}
+
+	switch {
+	case blueprint.Type == "dropdown_select":
+		if !hasOption(blueprint, value) {
+			v.problem(path, "expected one of %s, got %v", optionNames(blueprint), value)
+		}
+	case blueprint.Type == "multi_select_options":
+		values, ok := value.([]interface{})
+		if !ok {
+			v.problem(path, "expected a list of options, got %s", describe(value))
+			return
+		}
+		for i, value := range values {
+			if !isPlaceholder(value) && !hasOption(blueprint, value) {
+				v.problem(fmt.Sprintf("%s/%d", path, i), "expected one of %s, got %v", optionNames(blueprint), value)
+			}
+		}
+	case blueprint.IsSelector():
+		selected, ok := value.(string)
+		if !ok {
+			v.problem(path, "expected a string, got %s", describe(value))
+		} else if selectValueOptionTemplate(blueprint, selected) == nil {
+			v.problem(path, "expected one of %s, got %q", selectValues(blueprint), selected)
+		}
+	case blueprint.IsCollection():
+		v.validateCollection(path, blueprint, value)
+	case blueprint.IsBool():
+		if _, ok := value.(bool); !ok {
+			v.problem(path, "expected a boolean, got %s", describe(value))
+		}
+	case blueprint.IsInt():
+		if _, ok := value.(int); !ok {
+			v.problem(path, "expected an integer, got %s", describe(value))
+		}
+	case blueprint.IsString():
+		if _, ok := value.(string); !ok {
+			v.problem(path, "expected a string, got %s", describe(value))
+		}
+	}
+}
+
+func (v *validator) validateCredential(path string, keys []string, value interface{}) {
+	fields, ok := asMap(value)
+	if !ok {
+		v.problem(path, "expected a map with %s", strings.Join(keys, ", "))
+		return
+	}
+
+	for _, key := range keys {
+		if _, ok := fields[key]; !ok {
+			v.problem(path+"/"+key, "is required")
+		}
+	}
+
+	for key := range fields {
+		if !contains(keys, key) {
+			v.problem(path+"/"+key, "is not a recognized key, expected %s", strings.Join(keys, ", "))
+		}
+	}
+}
+
+func (v *validator) validateCollection(path string, blueprint *generator.PropertyBlueprint, value interface{}) {
+	items, ok := value.([]interface{})
+	if !ok {
+		v.problem(path, "expected a list of collection items, got %s", describe(value))
+		return
+	}
+
+	for i, item := range items {
+		itemPath := fmt.Sprintf("%s/%d", path, i)
+
+		fields, ok := asMap(item)
+		if !ok {
+			v.problem(itemPath, "expected a map of collection item properties, got %s", describe(item))
+			continue
+		}
+
+		for name, fieldValue := range fields {
+			if name == "guid" {
+				continue
+			}
+
+			sub, found := findBlueprint(blueprint.PropertyBlueprints, name)
+			if !found {
+				if !inCollectionDefault(blueprint, name) {
+					v.problem(itemPath+"/"+name, "is not a property of the %s collection", blueprint.Name)
+				}
+				continue
+			}
+
+			v.validateValue(itemPath+"/"+name, sub, fieldValue)
+		}
+
+		for _, sub := range blueprint.PropertyBlueprints {
+			if _, configured := fields[sub.Name]; isRequired(&sub) && !configured {
+				v.problem(itemPath+"/"+sub.Name, "is required")
+			}
+		}
+	}
+}
+
+func (v *validator) validateResourceConfig(path string, value interface{}) {
+	jobs, ok := asMap(value)
+	if !ok {
+		v.problem(path, "expected a map of job names to resource config")
+		return
+	}
+
+	for name, resourceConfig := range jobs {
+		jobPath := path + "/" + name
+
+		if _, err := v.metadata.GetJob(name); err != nil {
+			v.problem(jobPath, "is not a job of %s", v.metadata.Name)
+			continue
+		}
+
+		fields, ok := asMap(resourceConfig)
+		if !ok {
+			v.problem(jobPath, "expected a map of resource config")
+			continue
+		}
+
+		if instances, ok := fields["instances"]; ok && !isPlaceholder(instances) {
+			if _, isInt := instances.(int); !isInt && instances != "automatic" {
+				v.problem(jobPath+"/instances", "expected an integer or \"automatic\", got %s", describe(instances))
+			}
+		}
+	}
+}
+
+func (v *validator) validateErrandConfig(path string, value interface{}) {
+	errands, ok := asMap(value)
+	if !ok {
+		v.problem(path, "expected a map of errand names to errand config")
+		return
+	}
+
+	postDeploy := map[string]bool{}
+	for _, errand := range v.metadata.PostDeployErrands {
+		postDeploy[errand.Name] = true
+	}
+
+	preDelete := map[string]bool{}
+	for _, errand := range v.metadata.PreDeleteErrands {
+		preDelete[errand.Name] = true
+	}
+
+	for name, errandConfig := range errands {
+		errandPath := path + "/" + name
+
+		if !postDeploy[name] && !preDelete[name] {
+			v.problem(errandPath, "is not an errand of %s", v.metadata.Name)
+			continue
+		}
+
+		fields, ok := asMap(errandConfig)
+		if !ok {
+			v.problem(errandPath, "expected a map with post-deploy-state or pre-delete-state")
+			continue
+		}
+
+		for field, state := range fields {
+			switch {
+			case field == "post-deploy-state" && !postDeploy[name]:
+				v.problem(errandPath+"/"+field, "%s is not a post-deploy errand", name)
+			case field == "pre-delete-state" && !preDelete[name]:
+				v.problem(errandPath+"/"+field, "%s is not a pre-delete errand", name)
+			case field != "post-deploy-state" && field != "pre-delete-state":
+				v.problem(errandPath+"/"+field, "is not a recognized key, expected post-deploy-state or pre-delete-state")
+			case !isErrandState(state):
+				v.problem(errandPath+"/"+field, "expected true, false, \"default\" or \"when-changed\", got %v", state)
+			}
+		}
+	}
+}
+
+func findBlueprint(blueprints []generator.PropertyBlueprint, name string) (*generator.PropertyBlueprint, bool) {
+	for _, blueprint := range blueprints {
+		if blueprint.Name == name {
+			return &blueprint, true
+		}
+	}
+	return nil, false
+}
+
+// inCollectionDefault is true when the default items of a collection
+// have a property, which is then accepted even without a blueprint.
+func inCollectionDefault(blueprint *generator.PropertyBlueprint, name string) bool {
+	items, _ := blueprint.Default.([]interface{})
+	for _, item := range items {
+		if fields, ok := asMap(item); ok {
+			if _, ok := fields[name]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func selectValueOptionTemplate(blueprint *generator.PropertyBlueprint, selectValue string) *generator.OptionTemplate {
+	for _, option := range blueprint.OptionTemplates {
+		if strings.EqualFold(option.SelectValue, selectValue) {
+			return &option
+		}
+	}
+	return nil
+}
+
+// isRequired matches what config-template considers required:
+// Ops Manager picks the first option of dropdowns and selectors
+// without a default, so those are never required.
+func isRequired(blueprint *generator.PropertyBlueprint) bool {
+	return blueprint.IsConfigurable() && blueprint.IsRequired() && !blueprint.HasDefault() &&
+		!blueprint.IsDropdown() && !blueprint.IsSelector()
+}
+
+func isPlaceholder(value interface{}) bool {
+	s, ok := value.(string)
+	return ok && placeholderRegex.MatchString(s)
+}
+
+func isErrandState(state interface{}) bool {
+	switch state {
+	case true, false, "default", "when-changed":
+		return true
+	}
+	return isPlaceholder(state)
+}
+
+func hasOption(blueprint *generator.PropertyBlueprint, value interface{}) bool {
+	for _, option := range blueprint.Options {
+		if fmt.Sprintf("%v", option.Name) == fmt.Sprintf("%v", value) {
+			return true
+		}
+	}
+	return false
+}
+
+func optionNames(blueprint *generator.PropertyBlueprint) string {
+	var names []string
+	for _, option := range blueprint.Options {
+		names = append(names, fmt.Sprintf("%v", option.Name))
+	}
+	return strings.Join(names, ", ")
+}
+
+func optionTemplateNames(blueprint *generator.PropertyBlueprint) string {
+	var names []string
+	for _, option := range blueprint.OptionTemplates {
+		names = append(names, option.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func selectValues(blueprint *generator.PropertyBlueprint) string {
+	var values []string
+	for _, option := range blueprint.OptionTemplates {
+		values = append(values, option.SelectValue)
+	}
+	return strings.Join(values, ", ")
+}
+
+func asMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		m := map[string]interface{}{}
+		for key, value := range v {
+			m[fmt.Sprintf("%v", key)] = value
+		}
+		return m, true
+	}
+	return nil, false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func describe(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return fmt.Sprintf("%q", value)
+	case []interface{}:
+		return "a list"
+	case map[interface{}]interface{}, map[string]interface{}:
+		return "a map"
+	}
+	return fmt.Sprintf("%v", value)
+}
@@ -0,0 +1,276 @@
+package validator_test
+
+import (
+	"github.com/pivotal-cf/om/configtemplate/validator"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const metadata = `---
+name: example-product
+product_version: 1.0.0
+form_types:
+- name: example-form
+  property_inputs:
+  - reference: .properties.domain
+  - reference: .properties.port
+  - reference: .properties.enabled
+  - reference: .properties.password
+  - reference: .properties.certificate
+  - reference: .properties.size
+  - reference: .properties.features
+  - reference: .properties.database
+    selector_property_inputs:
+    - reference: .properties.database.internal
+    - reference: .properties.database.external
+  - reference: .properties.users
+  - reference: .web.memory
+property_blueprints:
+- name: domain
+  type: wildcard_domain
+  configurable: true
+- name: port
+  type: port
+  configurable: true
+  default: 8080
+- name: enabled
+  type: boolean
+  configurable: true
+  default: false
+- name: password
+  type: secret
+  configurable: true
+  optional: true
+- name: certificate
+  type: rsa_cert_credentials
+  configurable: true
+  optional: true
+- name: size
+  type: dropdown_select
+  configurable: true
+  default: small
+  options:
+  - name: small
+  - name: large
+- name: features
+  type: multi_select_options
+  configurable: true
+  optional: true
+  options:
+  - name: metrics
+  - name: logs
+- name: database
+  type: selector
+  configurable: true
+  default: internal
+  option_templates:
+  - name: internal
+    select_value: internal
+  - name: external
+    select_value: external
+    property_blueprints:
+    - name: host
+      type: network_address
+      configurable: true
+    - name: port
+      type: port
+      configurable: true
+      default: 5432
+- name: users
+  type: collection
+  configurable: true
+  optional: true
+  property_blueprints:
+  - name: name
+    type: string
+    configurable: true
+  - name: admin
+    type: boolean
+    configurable: true
+    default: false
+- name: generated
+  type: secret
+  configurable: false
+job_types:
+- name: web
+  property_blueprints:
+  - name: memory
+    type: integer
+    configurable: true
+    default: 1024
+- name: worker
+post_deploy_errands:
+- name: smoke_tests
+pre_delete_errands:
+- name: cleanup
+`
+
+var _ = Describe("Validate", func() {
+	validate := func(config string) []string {
+		problems, err := validator.Validate([]byte(metadata), []byte(config))
+		Expect(err).ToNot(HaveOccurred())
+
+		var messages []string
+		for _, problem := range problems {
+			messages = append(messages, problem.String())
+		}
+		return messages
+	}
+
+	It("accepts a valid config", func() {
+		Expect(validate(`
+product-name: example-product
+product-properties:
+  .properties.domain:
+    value: "*.example.com"
+  .properties.port:
+    value: 443
+  .properties.enabled:
+    value: true
+  .properties.password:
+    value:
+      secret: hunter2
+  .properties.certificate:
+    value:
+      cert_pem: ((cert.certificate))
+      private_key_pem: ((cert.private_key))
+  .properties.size:
+    value: large
+  .properties.features:
+    value: [metrics, logs]
+  .properties.database:
+    selected_option: external
+    value: external
+  .properties.database.external.host:
+    value: db.example.com
+  .properties.users:
+    value:
+    - name: admin
+      admin: true
+    - name: ((other_user))
+  .web.memory:
+    value: 2048
+network-properties:
+  singleton_availability_zone:
+    name: az1
+resource-config:
+  web:
+    instances: 2
+  worker:
+    instances: automatic
+errand-config:
+  smoke_tests:
+    post-deploy-state: when-changed
+  cleanup:
+    pre-delete-state: false
+`)).To(BeEmpty())
+	})
+
+	It("does not type check placeholders", func() {
+		Expect(validate(`
+product-properties:
+  .properties.domain:
+    value: ((domain))
+  .properties.port:
+    value: ((port))
+  .properties.features:
+    value: ((features))
+resource-config:
+  web:
+    instances: ((web_instances))
+`)).To(BeEmpty())
+	})
+
+	It("reports every problem at once, with its path", func() {
+		Expect(validate(`
+product-name: other-product
+product-properties:
+  .properties.port:
+    value: not-a-port
+  .properties.enabled:
+    value: "true"
+  .properties.unknown:
+    value: foo
+  .properties.generated:
+    value:
+      secret: foo
+  .properties.password:
+    value: hunter2
+  .properties.certificate:
+    value:
+      cert_pem: foo
+      private_key: bar
+  .properties.size:
+    value: medium
+  .properties.features:
+    value: [metrics, traces]
+  .properties.database:
+    selected_option: remote
+    value: external
+  .properties.users:
+    value:
+    - admin: true
+      role: owner
+  .web.memory:
+    value: lots
+    selected_option: foo
+  .properties.domain: "*.example.com"
+resource-config:
+  web:
+    instances: many
+  router: {}
+errand-config:
+  smoke_tests:
+    pre-delete-state: true
+  cleanup:
+    pre-delete-state: sometimes
+  deploy_all: {}
+unknown-key: {}
+`)).To(Equal([]string{
+			`/errand-config/cleanup/pre-delete-state: expected true, false, "default" or "when-changed", got sometimes`,
+			`/errand-config/deploy_all: is not an errand of example-product`,
+			`/errand-config/smoke_tests/pre-delete-state: smoke_tests is not a pre-delete errand`,
+			`/product-name: expected "example-product", the name of the product, got "other-product"`,
+			`/product-properties/.properties.certificate/value/private_key: is not a recognized key, expected cert_pem, private_key_pem`,
+			`/product-properties/.properties.certificate/value/private_key_pem: is required`,
+			`/product-properties/.properties.database/selected_option: expected one of internal, external, got "remote"`,
+			`/product-properties/.properties.domain: expected a map with a value or selected_option`,
+			`/product-properties/.properties.enabled/value: expected a boolean, got "true"`,
+			`/product-properties/.properties.features/value/1: expected one of metrics, logs, got traces`,
+			`/product-properties/.properties.generated: is not configurable`,
+			`/product-properties/.properties.password/value: expected a map with secret`,
+			`/product-properties/.properties.port/value: expected an integer, got "not-a-port"`,
+			`/product-properties/.properties.size/value: expected one of small, large, got medium`,
+			`/product-properties/.properties.unknown: is not a property of example-product`,
+			`/product-properties/.properties.users/value/0/name: is required`,
+			`/product-properties/.properties.users/value/0/role: is not a property of the users collection`,
+			`/product-properties/.web.memory/selected_option: is only valid for selector properties`,
+			`/product-properties/.web.memory/value: expected an integer, got "lots"`,
+			`/resource-config/router: is not a job of example-product`,
+			`/resource-config/web/instances: expected an integer or "automatic", got "many"`,
+			`/unknown-key: is not a recognized top-level key`,
+		}))
+	})
+
+	It("reports required properties that are missing", func() {
+		Expect(validate(`
+product-properties:
+  .properties.database:
+    value: external
+`)).To(Equal([]string{
+			`/product-properties/.properties.database.external.host: is required when .properties.database is "external"`,
+			`/product-properties/.properties.domain: is required`,
+		}))
+	})
+
+	It("returns an error when the config cannot be parsed", func() {
+		_, err := validator.Validate([]byte(metadata), []byte("{"))
+		Expect(err).To(MatchError(ContainSubstring("could not parse product config")))
+	})
+
+	It("returns an error when the metadata cannot be parsed", func() {
+		_, err := validator.Validate([]byte("{"), []byte("{}"))
+		Expect(err).To(MatchError(ContainSubstring("could not parse product metadata")))
+	})
+})
@@ -0,0 +1,13 @@
+package credhub_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestCredHub(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "credhub")
+}
@@ -0,0 +1,200 @@
+// Package credhub looks up template variables in CredHub.
+package credhub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+	"github.com/pivotal-cf/om/network"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	connectTimeout = 10 * time.Second
+	requestTimeout = 30 * time.Second
+)
+
+// Config is how to reach and authenticate with CredHub:
+// with the credentials of a UAA client, or with a client certificate (mTLS).
+// Certificates and keys are paths or PEM values.
+type Config struct {
+	Server     string
+	Client     string
+	Secret     string
+	CACert     string
+	ClientCert string
+	ClientKey  string
+}
+
+// Variables resolves the variable ((name)) to the current value of the
+// credential <prefix>/name of the source credhub://<prefix>,
+// or /name of the source credhub://.
+// Values and passwords resolve to a string, other types to their fields,
+// so ((router_cert.private_key)) selects the key of a certificate.
+type Variables struct {
+	client *http.Client
+	config Config
+	prefix string
+
+	authenticated bool
+	values        map[string]interface{}
+}
+
+var _ template.Variables = &Variables{}
+
+func NewVariables(source string, config Config) (*Variables, error) {
+	sourceURL, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse vars source %q: %s", source, err)
+	}
+
+	if sourceURL.Scheme != "credhub" {
+		return nil, fmt.Errorf("vars source %q must be of the form credhub://prefix", source)
+	}
+
+	if config.Server == "" {
+		return nil, fmt.Errorf("vars source %s requires the address of CredHub (credhub-server in the env file, or CREDHUB_SERVER)", source)
+	}
+
+	mutualTLS := config.ClientCert != "" || config.ClientKey != ""
+	if !mutualTLS && (config.Client == "" || config.Secret == "") {
+		return nil, fmt.Errorf("vars source %s requires the credentials of a UAA client (credhub-client and credhub-secret, or CREDHUB_CLIENT and CREDHUB_SECRET) or a client certificate (credhub-client-cert and credhub-client-key, or CREDHUB_CLIENT_CERT and CREDHUB_CLIENT_KEY)", source)
+	}
+
+	client, err := network.NewHTTPClient(false, config.CACert, config.ClientCert, config.ClientKey, requestTimeout, connectTimeout, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the CredHub client: %s", err)
+	}
+
+	return &Variables{
+		client:        client,
+		config:        config,
+		prefix:        strings.Trim(sourceURL.Host+sourceURL.Path, "/"),
+		authenticated: mutualTLS,
+		values:        map[string]interface{}{},
+	}, nil
+}
+
+// Get reads the credential of a variable the first time it is asked for.
+// A missing credential is a missing variable, not an error.
+func (v *Variables) Get(definition template.VariableDefinition) (interface{}, bool, error) {
+	name := definition.Name
+	if !strings.HasPrefix(name, "/") {
+		name = strings.TrimSuffix("/"+v.prefix, "/") + "/" + name
+	}
+
+	if value, ok := v.values[name]; ok {
+		return value, value != nil, nil
+	}
+
+	if err := v.authenticate(); err != nil {
+		return nil, false, err
+	}
+
+	query := url.Values{"name": {name}, "current": {"true"}}
+	response, err := v.client.Get(strings.TrimRight(v.config.Server, "/") + "/api/v1/data?" + query.Encode())
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read credential %s from CredHub: %s", name, err)
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read credential %s from CredHub: %s", name, err)
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		v.values[name] = nil
+		return nil, false, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var credhubError struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(contents, &credhubError)
+		if credhubError.Error != "" {
+			return nil, false, fmt.Errorf("could not read credential %s from CredHub: unexpected response %d: %s", name, response.StatusCode, credhubError.Error)
+		}
+		return nil, false, fmt.Errorf("could not read credential %s from CredHub: unexpected response %d", name, response.StatusCode)
+	}
+
+	var credentials struct {
+		Data []struct {
+			Value json.RawMessage `json:"value"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(contents, &credentials); err != nil {
+		return nil, false, fmt.Errorf("could not decode credential %s: %s", name, err)
+	}
+	if len(credentials.Data) == 0 {
+		v.values[name] = nil
+		return nil, false, nil
+	}
+
+	// JSON is YAML: decoding it as such gives the maps the templates expect
+	var value interface{}
+	if err := yaml.Unmarshal(credentials.Data[0].Value, &value); err != nil {
+		return nil, false, fmt.Errorf("could not decode credential %s: %s", name, err)
+	}
+
+	v.values[name] = value
+	return value, true, nil
+}
+
+// List is not supported: the credentials are only read when asked for.
+func (v *Variables) List() ([]template.VariableDefinition, error) {
+	return nil, nil
+}
+
+// authenticate gets a token for the UAA client from the UAA of CredHub,
+// and has the client send it with every request.
+func (v *Variables) authenticate() error {
+	if v.authenticated {
+		return nil
+	}
+
+	response, err := v.client.Get(strings.TrimRight(v.config.Server, "/") + "/info")
+	if err != nil {
+		return fmt.Errorf("could not find the UAA of CredHub: %s", err)
+	}
+	defer response.Body.Close()
+
+	var info struct {
+		AuthServer struct {
+			URL string `json:"url"`
+		} `json:"auth-server"`
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not find the UAA of CredHub: unexpected response %d", response.StatusCode)
+	}
+	if err := json.NewDecoder(response.Body).Decode(&info); err != nil || info.AuthServer.URL == "" {
+		return fmt.Errorf("could not find the UAA of CredHub in its info")
+	}
+
+	clientCredentials := clientcredentials.Config{
+		ClientID:     v.config.Client,
+		ClientSecret: v.config.Secret,
+		TokenURL:     strings.TrimRight(info.AuthServer.URL, "/") + "/oauth/token",
+	}
+
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, v.client)
+	token, err := clientCredentials.TokenSource(ctx).Token()
+	if err != nil {
+		return fmt.Errorf("could not get a token for CredHub client %s: %s", v.config.Client, err)
+	}
+
+	v.client = oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
+	v.client.Timeout = requestTimeout
+	v.authenticated = true
+	return nil
+}
@@ -0,0 +1,200 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"io"
+	"sync"
+
+	"github.com/pivotal-cf/om/download_clients"
+)
+
+type RangeDownloader struct {
+	DownloadProductRangeStub        func(download_clients.FileArtifacter, int64, int64) (io.ReadCloser, error)
+	downloadProductRangeMutex       sync.RWMutex
+	downloadProductRangeArgsForCall []struct {
+		arg1 download_clients.FileArtifacter
+		arg2 int64
+		arg3 int64
+	}
+	downloadProductRangeReturns struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	downloadProductRangeReturnsOnCall map[int]struct {
+		result1 io.ReadCloser
+		result2 error
+	}
+	ProductFileSizeStub        func(download_clients.FileArtifacter) (int64, error)
+	productFileSizeMutex       sync.RWMutex
+	productFileSizeArgsForCall []struct {
+		arg1 download_clients.FileArtifacter
+	}
+	productFileSizeReturns struct {
+		result1 int64
+		result2 error
+	}
+	productFileSizeReturnsOnCall map[int]struct {
+		result1 int64
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *RangeDownloader) DownloadProductRange(arg1 download_clients.FileArtifacter, arg2 int64, arg3 int64) (io.ReadCloser, error) {
+	fake.downloadProductRangeMutex.Lock()
+	ret, specificReturn := fake.downloadProductRangeReturnsOnCall[len(fake.downloadProductRangeArgsForCall)]
+	fake.downloadProductRangeArgsForCall = append(fake.downloadProductRangeArgsForCall, struct {
+		arg1 download_clients.FileArtifacter
+		arg2 int64
+		arg3 int64
+	}{arg1, arg2, arg3})
+	stub := fake.DownloadProductRangeStub
+	fakeReturns := fake.downloadProductRangeReturns
+	fake.recordInvocation("DownloadProductRange", []interface{}{arg1, arg2, arg3})
+	fake.downloadProductRangeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *RangeDownloader) DownloadProductRangeCallCount() int {
+	fake.downloadProductRangeMutex.RLock()
+	defer fake.downloadProductRangeMutex.RUnlock()
+	return len(fake.downloadProductRangeArgsForCall)
+}
+
+func (fake *RangeDownloader) DownloadProductRangeCalls(stub func(download_clients.FileArtifacter, int64, int64) (io.ReadCloser, error)) {
+	fake.downloadProductRangeMutex.Lock()
+	defer fake.downloadProductRangeMutex.Unlock()
+	fake.DownloadProductRangeStub = stub
+}
+
+func (fake *RangeDownloader) DownloadProductRangeArgsForCall(i int) (download_clients.FileArtifacter, int64, int64) {
+	fake.downloadProductRangeMutex.RLock()
+	defer fake.downloadProductRangeMutex.RUnlock()
+	argsForCall := fake.downloadProductRangeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *RangeDownloader) DownloadProductRangeReturns(result1 io.ReadCloser, result2 error) {
+	fake.downloadProductRangeMutex.Lock()
+	defer fake.downloadProductRangeMutex.Unlock()
+	fake.DownloadProductRangeStub = nil
+	fake.downloadProductRangeReturns = struct {
+		result1 io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RangeDownloader) DownloadProductRangeReturnsOnCall(i int, result1 io.ReadCloser, result2 error) {
+	fake.downloadProductRangeMutex.Lock()
+	defer fake.downloadProductRangeMutex.Unlock()
+	fake.DownloadProductRangeStub = nil
+	if fake.downloadProductRangeReturnsOnCall == nil {
+		fake.downloadProductRangeReturnsOnCall = make(map[int]struct {
+			result1 io.ReadCloser
+			result2 error
+		})
+	}
+	fake.downloadProductRangeReturnsOnCall[i] = struct {
+		result1 io.ReadCloser
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RangeDownloader) ProductFileSize(arg1 download_clients.FileArtifacter) (int64, error) {
+	fake.productFileSizeMutex.Lock()
+	ret, specificReturn := fake.productFileSizeReturnsOnCall[len(fake.productFileSizeArgsForCall)]
+	fake.productFileSizeArgsForCall = append(fake.productFileSizeArgsForCall, struct {
+		arg1 download_clients.FileArtifacter
+	}{arg1})
+	stub := fake.ProductFileSizeStub
+	fakeReturns := fake.productFileSizeReturns
+	fake.recordInvocation("ProductFileSize", []interface{}{arg1})
+	fake.productFileSizeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *RangeDownloader) ProductFileSizeCallCount() int {
+	fake.productFileSizeMutex.RLock()
+	defer fake.productFileSizeMutex.RUnlock()
+	return len(fake.productFileSizeArgsForCall)
+}
+
+func (fake *RangeDownloader) ProductFileSizeCalls(stub func(download_clients.FileArtifacter) (int64, error)) {
+	fake.productFileSizeMutex.Lock()
+	defer fake.productFileSizeMutex.Unlock()
+	fake.ProductFileSizeStub = stub
+}
+
+func (fake *RangeDownloader) ProductFileSizeArgsForCall(i int) download_clients.FileArtifacter {
+	fake.productFileSizeMutex.RLock()
+	defer fake.productFileSizeMutex.RUnlock()
+	argsForCall := fake.productFileSizeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *RangeDownloader) ProductFileSizeReturns(result1 int64, result2 error) {
+	fake.productFileSizeMutex.Lock()
+	defer fake.productFileSizeMutex.Unlock()
+	fake.ProductFileSizeStub = nil
+	fake.productFileSizeReturns = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RangeDownloader) ProductFileSizeReturnsOnCall(i int, result1 int64, result2 error) {
+	fake.productFileSizeMutex.Lock()
+	defer fake.productFileSizeMutex.Unlock()
+	fake.ProductFileSizeStub = nil
+	if fake.productFileSizeReturnsOnCall == nil {
+		fake.productFileSizeReturnsOnCall = make(map[int]struct {
+			result1 int64
+			result2 error
+		})
+	}
+	fake.productFileSizeReturnsOnCall[i] = struct {
+		result1 int64
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *RangeDownloader) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.downloadProductRangeMutex.RLock()
+	defer fake.downloadProductRangeMutex.RUnlock()
+	fake.productFileSizeMutex.RLock()
+	defer fake.productFileSizeMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *RangeDownloader) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ download_clients.RangeDownloader = new(RangeDownloader)
@@ -0,0 +1,259 @@
+package download_clients
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pivotal-cf/om/extractor"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type HTTPConfiguration struct {
+	URL          string `validate:"required"`
+	ProductPath  string
+	StemcellPath string
+	DisableSSL   bool
+}
+
+// httpClient finds products and stemcells on an HTTP mirror
+// that serves directory listings, named the same way as in a blobstore.
+type httpClient struct {
+	config HTTPConfiguration
+	client *http.Client
+	stderr *log.Logger
+}
+
+func NewHTTPClient(config HTTPConfiguration, stderr *log.Logger) (httpClient, error) {
+	validate := validator.New()
+	err := validate.Struct(config)
+	if err != nil {
+		return httpClient{}, err
+	}
+
+	baseURL, err := url.Parse(config.URL)
+	if err != nil {
+		return httpClient{}, fmt.Errorf("could not parse http url: %w", err)
+	}
+	if baseURL.Scheme != "http" && baseURL.Scheme != "https" {
+		return httpClient{}, fmt.Errorf("http url %q must start with http:// or https://", config.URL)
+	}
+
+	return httpClient{
+		config: config,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: config.DisableSSL,
+				},
+			},
+		},
+		stderr: stderr,
+	}, nil
+}
+
+func (h httpClient) Name() string {
+	return "http"
+}
+
+func (h httpClient) GetAllProductVersions(slug string) ([]string, error) {
+	files, err := h.listFiles(h.config.ProductPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return versionsFromPrefixedFiles(files, h.config.ProductPath, slug)
+}
+
+func (h httpClient) GetLatestProductFile(slug, version, glob string) (FileArtifacter, error) {
+	files, err := h.listFiles(h.config.ProductPath, h.config.StemcellPath)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := findPrefixedFile(files, h.config.ProductPath, h.config.StemcellPath, slug, version, glob)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := &httpFileArtifact{
+		name:   h.fileURL(name, false),
+		url:    h.fileURL(name, true),
+		client: h.client,
+	}
+
+	// a checksum next to the file, as written by sha256sum, is verified after downloading
+	checksum, err := h.get(artifact.url + ".sha256")
+	if err == nil {
+		artifact.sha256 = firstField(string(checksum))
+	}
+
+	return artifact, nil
+}
+
+func (h httpClient) DownloadProductToFile(fa FileArtifacter, destinationFile *os.File) error {
+	artifact, ok := fa.(*httpFileArtifact)
+	if !ok {
+		return fmt.Errorf("could not download %s from http: unexpected artifact", fa.Name())
+	}
+
+	resp, err := h.client.Get(artifact.url)
+	if err != nil {
+		return fmt.Errorf("could not download %s: %w", artifact.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("could not download %s: unexpected status %s", artifact.name, resp.Status)
+	}
+
+	progressBar, wrappedReader := startProgressBar(h.stderr, resp.ContentLength, resp.Body)
+	defer progressBar.Finish()
+
+	return streamBufferToFile(destinationFile, wrappedReader)
+}
+
+// ProductFileSize only allows ranged downloads
+// when the mirror advertises support for them.
+func (h httpClient) ProductFileSize(fa FileArtifacter) (int64, error) {
+	artifact, ok := fa.(*httpFileArtifact)
+	if !ok {
+		return 0, fmt.Errorf("could not download %s from http: unexpected artifact", fa.Name())
+	}
+
+	resp, err := h.client.Head(artifact.url)
+	if err != nil {
+		return 0, fmt.Errorf("could not download %s: %w", artifact.name, err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("could not download %s: unexpected status %s", artifact.name, resp.Status)
+	}
+
+	if resp.Header.Get("Accept-Ranges") != "bytes" || resp.ContentLength < 0 {
+		return 0, ErrRangesNotSupported
+	}
+
+	return resp.ContentLength, nil
+}
+
+func (h httpClient) DownloadProductRange(fa FileArtifacter, start, end int64) (io.ReadCloser, error) {
+	artifact, ok := fa.(*httpFileArtifact)
+	if !ok {
+		return nil, fmt.Errorf("could not download %s from http: unexpected artifact", fa.Name())
+	}
+
+	return httpRange(h.client, artifact.url, start, end)
+}
+
+func (h httpClient) GetLatestStemcellForProduct(_ FileArtifacter, downloadedProductFileName string) (StemcellArtifacter, error) {
+	return latestStemcellForProduct(downloadedProductFileName, h.Name(), func(slug string) ([]string, error) {
+		files, err := h.listFiles(h.config.StemcellPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return versionsFromPrefixedFiles(files, h.config.StemcellPath, slug)
+	})
+}
+
+var hrefRegex = regexp.MustCompile(`(?i)href\s*=\s*"([^"]+)"`)
+
+// listFiles reads the directory listings of the given paths,
+// returning the files they link to relative to the url, leaving out checksums.
+func (h httpClient) listFiles(paths ...string) ([]string, error) {
+	var files []string
+	listed := map[string]bool{}
+
+	for _, p := range paths {
+		if listed[p] {
+			continue
+		}
+		listed[p] = true
+
+		listing, err := h.get(h.fileURL(p, true) + "/")
+		if err != nil {
+			return nil, fmt.Errorf("could not list files on http mirror: %w", err)
+		}
+
+		for _, match := range hrefRegex.FindAllStringSubmatch(string(listing), -1) {
+			link := match[1]
+			if strings.HasSuffix(link, "/") || strings.HasSuffix(link, ".sha256") || strings.ContainsAny(link, "?#") || strings.Contains(link, "://") {
+				continue
+			}
+
+			name, err := url.PathUnescape(path.Base(link))
+			if err != nil {
+				continue
+			}
+
+			files = append(files, path.Join(p, name))
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("http mirror '%s' lists no files", h.config.URL)
+	}
+
+	return files, nil
+}
+
+func (h httpClient) get(fileURL string) ([]byte, error) {
+	resp, err := h.client.Get(fileURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, fileURL)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// fileURL joins a path onto the url, escaping each segment
+// when the url is going to be requested.
+func (h httpClient) fileURL(name string, escape bool) string {
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(name, "/"), "/") {
+		if segment == "" {
+			continue
+		}
+		if escape {
+			segment = url.PathEscape(segment)
+		}
+		segments = append(segments, segment)
+	}
+
+	return strings.Join(append([]string{strings.TrimSuffix(h.config.URL, "/")}, segments...), "/")
+}
+
+type httpFileArtifact struct {
+	name   string
+	url    string
+	sha256 string
+	client *http.Client
+}
+
+func (f httpFileArtifact) ProductMetadata() (*extractor.Metadata, error) {
+	return extractor.NewMetadataExtractor(extractor.WithHTTPClient(f.client)).ExtractFromURL(f.url)
+}
+
+func (f httpFileArtifact) Name() string {
+	return f.name
+}
+
+func (f httpFileArtifact) SHA256() string {
+	return f.sha256
+}
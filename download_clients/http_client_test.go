@@ -0,0 +1,168 @@
+package download_clients_test
+
+import (
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/om/download_clients"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func serveContent(contents string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(contents))
+	}
+}
+
+var _ = Describe("httpClient", func() {
+	var (
+		server *ghttp.Server
+		stderr *log.Logger
+	)
+
+	const listing = `<html><body>
+<a href="../">../</a>
+<a href="nested/">nested/</a>
+<a href="%5Bexample-product%2C1.0.0%5Dexample.pivotal">[example-product,1.0.0]example.pivotal</a>
+<a href="%5Bexample-product%2C1.0.0%5Dexample.pivotal.sha256">[example-product,1.0.0]example.pivotal.sha256</a>
+<a href="%5Bexample-product%2C1.1.0%5Dexample.pivotal">[example-product,1.1.0]example.pivotal</a>
+<a href="?C=N;O=D">Name</a>
+<a href="https://example.com/[example-product,9.9.9]example.pivotal">elsewhere</a>
+</body></html>`
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		server.SetAllowUnhandledRequests(true)
+		server.SetUnhandledRequestStatusCode(http.StatusNotFound)
+		stderr = log.New(GinkgoWriter, "", 0)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	newClient := func(productPath string) download_clients.ProductDownloader {
+		client, err := download_clients.NewHTTPClient(download_clients.HTTPConfiguration{
+			URL:         server.URL(),
+			ProductPath: productPath,
+		}, stderr)
+		Expect(err).ToNot(HaveOccurred())
+		return client
+	}
+
+	Describe("NewHTTPClient", func() {
+		It("requires a url", func() {
+			_, err := download_clients.NewHTTPClient(download_clients.HTTPConfiguration{}, stderr)
+			Expect(err).To(MatchError(ContainSubstring("Field validation for 'URL' failed on the 'required' tag")))
+		})
+
+		It("requires an http or https url", func() {
+			_, err := download_clients.NewHTTPClient(download_clients.HTTPConfiguration{URL: "ftp://example.com"}, stderr)
+			Expect(err).To(MatchError(`http url "ftp://example.com" must start with http:// or https://`))
+		})
+	})
+
+	Describe("GetAllProductVersions", func() {
+		It("reports the versions linked from the directory listing", func() {
+			server.RouteToHandler("GET", "/products/", ghttp.RespondWith(http.StatusOK, listing))
+
+			versions, err := newClient("products").GetAllProductVersions("example-product")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(Equal([]string{"1.0.0", "1.1.0"}))
+		})
+
+		It("errors when the listing cannot be read", func() {
+			_, err := newClient("products").GetAllProductVersions("example-product")
+			Expect(err).To(MatchError(ContainSubstring("could not list files on http mirror: unexpected status 404")))
+		})
+	})
+
+	Describe("GetLatestProductFile and DownloadProductToFile", func() {
+		It("downloads the file matching the glob, with the checksum next to it", func() {
+			server.RouteToHandler("GET", "/", ghttp.RespondWith(http.StatusOK, listing))
+			server.RouteToHandler("GET", "/[example-product,1.0.0]example.pivotal.sha256", ghttp.RespondWith(http.StatusOK, "abc123  example.pivotal\n"))
+			server.RouteToHandler("GET", "/[example-product,1.0.0]example.pivotal", ghttp.RespondWith(http.StatusOK, "some-contents"))
+
+			client := newClient("")
+
+			artifact, err := client.GetLatestProductFile("example-product", "1.0.0", "*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifact.Name()).To(Equal(server.URL() + "/[example-product,1.0.0]example.pivotal"))
+			Expect(artifact.SHA256()).To(Equal("abc123"))
+
+			destination, err := ioutil.TempFile("", "destination")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(destination.Name())
+			defer destination.Close()
+
+			Expect(client.DownloadProductToFile(artifact, destination)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(destination.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+		})
+
+		It("downloads ranges when the mirror supports them", func() {
+			server.RouteToHandler("GET", "/", ghttp.RespondWith(http.StatusOK, listing))
+			server.RouteToHandler("HEAD", "/[example-product,1.0.0]example.pivotal", serveContent("some-contents"))
+			server.RouteToHandler("GET", "/[example-product,1.0.0]example.pivotal", serveContent("some-contents"))
+
+			client, err := download_clients.NewHTTPClient(download_clients.HTTPConfiguration{URL: server.URL()}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			artifact, err := client.GetLatestProductFile("example-product", "1.0.0", "*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+
+			size, err := client.ProductFileSize(artifact)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(size).To(Equal(int64(13)))
+
+			reader, err := client.DownloadProductRange(artifact, 5, 8)
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+
+			contents, err := ioutil.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("cont"))
+		})
+
+		It("does not download ranges when the mirror does not advertise them", func() {
+			server.RouteToHandler("GET", "/", ghttp.RespondWith(http.StatusOK, listing))
+			server.RouteToHandler("HEAD", "/[example-product,1.0.0]example.pivotal", ghttp.RespondWith(http.StatusOK, ""))
+
+			client, err := download_clients.NewHTTPClient(download_clients.HTTPConfiguration{URL: server.URL()}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			artifact, err := client.GetLatestProductFile("example-product", "1.0.0", "*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.ProductFileSize(artifact)
+			Expect(err).To(Equal(download_clients.ErrRangesNotSupported))
+		})
+
+		It("errors when the file cannot be downloaded", func() {
+			server.RouteToHandler("GET", "/", ghttp.RespondWith(http.StatusOK, listing))
+
+			client := newClient("")
+
+			artifact, err := client.GetLatestProductFile("example-product", "1.1.0", "*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifact.SHA256()).To(BeEmpty())
+
+			destination, err := ioutil.TempFile("", "destination")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(destination.Name())
+			defer destination.Close()
+
+			err = client.DownloadProductToFile(artifact, destination)
+			Expect(err).To(MatchError(ContainSubstring("unexpected status 404")))
+		})
+	})
+})
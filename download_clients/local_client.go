@@ -0,0 +1,191 @@
+package download_clients
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pivotal-cf/om/extractor"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+type LocalConfiguration struct {
+	Directory    string `validate:"required"`
+	ProductPath  string
+	StemcellPath string
+}
+
+// localClient finds products and stemcells in a directory tree,
+// such as an NFS share, named the same way as in a blobstore.
+type localClient struct {
+	config LocalConfiguration
+	stderr *log.Logger
+}
+
+func NewLocalClient(config LocalConfiguration, stderr *log.Logger) (localClient, error) {
+	validate := validator.New()
+	err := validate.Struct(config)
+	if err != nil {
+		return localClient{}, err
+	}
+
+	info, err := os.Stat(config.Directory)
+	if err != nil {
+		return localClient{}, fmt.Errorf("could not read local directory: %w", err)
+	}
+	if !info.IsDir() {
+		return localClient{}, fmt.Errorf("local directory %q is not a directory", config.Directory)
+	}
+
+	return localClient{
+		config: config,
+		stderr: stderr,
+	}, nil
+}
+
+func (l localClient) Name() string {
+	return "local"
+}
+
+func (l localClient) GetAllProductVersions(slug string) ([]string, error) {
+	files, err := l.listFiles(l.config.ProductPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return versionsFromPrefixedFiles(files, l.config.ProductPath, slug)
+}
+
+func (l localClient) GetLatestProductFile(slug, version, glob string) (FileArtifacter, error) {
+	files, err := l.listFiles(l.config.ProductPath, l.config.StemcellPath)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := findPrefixedFile(files, l.config.ProductPath, l.config.StemcellPath, slug, version, glob)
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := &localFileArtifact{path: filepath.Join(l.config.Directory, filepath.FromSlash(name))}
+
+	// a checksum next to the file, as written by sha256sum, is verified after downloading
+	checksum, err := ioutil.ReadFile(artifact.path + ".sha256")
+	if err == nil {
+		artifact.sha256 = firstField(string(checksum))
+	}
+
+	return artifact, nil
+}
+
+func (l localClient) DownloadProductToFile(fa FileArtifacter, destinationFile *os.File) error {
+	file, err := os.Open(fa.Name())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	progressBar, wrappedReader := startProgressBar(l.stderr, info.Size(), file)
+	defer progressBar.Finish()
+
+	return streamBufferToFile(destinationFile, wrappedReader)
+}
+
+func (l localClient) ProductFileSize(fa FileArtifacter) (int64, error) {
+	info, err := os.Stat(fa.Name())
+	if err != nil {
+		return 0, err
+	}
+
+	return info.Size(), nil
+}
+
+func (l localClient) DownloadProductRange(fa FileArtifacter, start, end int64) (io.ReadCloser, error) {
+	file, err := os.Open(fa.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{io.NewSectionReader(file, start, end-start+1), file}, nil
+}
+
+func (l localClient) GetLatestStemcellForProduct(_ FileArtifacter, downloadedProductFileName string) (StemcellArtifacter, error) {
+	return latestStemcellForProduct(downloadedProductFileName, l.Name(), func(slug string) ([]string, error) {
+		files, err := l.listFiles(l.config.StemcellPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return versionsFromPrefixedFiles(files, l.config.StemcellPath, slug)
+	})
+}
+
+// listFiles lists the files directly in the given paths of the directory,
+// relative to the directory, leaving out checksums.
+func (l localClient) listFiles(paths ...string) ([]string, error) {
+	var files []string
+	listed := map[string]bool{}
+
+	for _, p := range paths {
+		if listed[p] {
+			continue
+		}
+		listed[p] = true
+
+		entries, err := ioutil.ReadDir(filepath.Join(l.config.Directory, filepath.FromSlash(p)))
+		if err != nil {
+			return nil, fmt.Errorf("could not list files in local directory: %w", err)
+		}
+
+		for _, entry := range entries {
+			if entry.IsDir() || strings.HasSuffix(entry.Name(), ".sha256") {
+				continue
+			}
+			files = append(files, path.Join(p, entry.Name()))
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("local directory '%s' contains no files", l.config.Directory)
+	}
+
+	return files, nil
+}
+
+type localFileArtifact struct {
+	path   string
+	sha256 string
+}
+
+func (f localFileArtifact) ProductMetadata() (*extractor.Metadata, error) {
+	return extractor.NewMetadataExtractor().ExtractFromFile(f.path)
+}
+
+func (f localFileArtifact) Name() string {
+	return f.path
+}
+
+func (f localFileArtifact) SHA256() string {
+	return f.sha256
+}
+
+func firstField(contents string) string {
+	fields := strings.Fields(contents)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,176 @@
+package download_clients_test
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal-cf/om/download_clients"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("localClient", func() {
+	var (
+		directory string
+		stderr    *log.Logger
+	)
+
+	writeFile := func(name, contents string) {
+		path := filepath.Join(directory, name)
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		var err error
+		directory, err = ioutil.TempDir("", "local-client")
+		Expect(err).ToNot(HaveOccurred())
+
+		stderr = log.New(GinkgoWriter, "", 0)
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(directory)).To(Succeed())
+	})
+
+	Describe("NewLocalClient", func() {
+		It("requires a directory", func() {
+			_, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{}, stderr)
+			Expect(err).To(MatchError(ContainSubstring("Field validation for 'Directory' failed on the 'required' tag")))
+		})
+
+		It("errors when the directory does not exist", func() {
+			_, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{
+				Directory: filepath.Join(directory, "missing"),
+			}, stderr)
+			Expect(err).To(MatchError(ContainSubstring("could not read local directory")))
+		})
+	})
+
+	Describe("GetAllProductVersions", func() {
+		It("reports the versions of the product in the product path", func() {
+			writeFile("products/[example-product,1.0.0]example.pivotal", "")
+			writeFile("products/[example-product,1.1.0]example.pivotal", "")
+			writeFile("products/[other-product,2.0.0]other.pivotal", "")
+			writeFile("[example-product,3.0.0]example.pivotal", "")
+
+			client, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{
+				Directory:   directory,
+				ProductPath: "products",
+			}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			versions, err := client.GetAllProductVersions("example-product")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(ConsistOf("1.0.0", "1.1.0"))
+		})
+
+		It("errors when the product path has no matching files", func() {
+			writeFile("[other-product,2.0.0]other.pivotal", "")
+
+			client, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{Directory: directory}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetAllProductVersions("example-product")
+			Expect(err).To(MatchError("no files matching pivnet-product-slug example-product found"))
+		})
+	})
+
+	Describe("GetLatestProductFile", func() {
+		It("returns the file matching the glob, with the checksum next to it", func() {
+			writeFile("[example-product,1.0.0]example.pivotal", "")
+			writeFile("[example-product,1.0.0]example.pivotal.sha256", "abc123  example.pivotal\n")
+			writeFile("[example-product,1.0.0]example-light.pivotal", "")
+
+			client, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{Directory: directory}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			artifact, err := client.GetLatestProductFile("example-product", "1.0.0", "example.*")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(artifact.Name()).To(Equal(filepath.Join(directory, "[example-product,1.0.0]example.pivotal")))
+			Expect(artifact.SHA256()).To(Equal("abc123"))
+		})
+
+		It("errors when the glob matches multiple files", func() {
+			writeFile("[example-product,1.0.0]example.pivotal", "")
+			writeFile("[example-product,1.0.0]example-light.pivotal", "")
+
+			client, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{Directory: directory}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = client.GetLatestProductFile("example-product", "1.0.0", "*.pivotal")
+			Expect(err).To(MatchError(ContainSubstring("the glob '*.pivotal' matches multiple files")))
+		})
+	})
+
+	Describe("DownloadProductToFile", func() {
+		It("copies the file", func() {
+			writeFile("[example-product,1.0.0]example.pivotal", "some-contents")
+
+			client, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{Directory: directory}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			artifact, err := client.GetLatestProductFile("example-product", "1.0.0", "*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+
+			destination, err := ioutil.TempFile(directory, "destination")
+			Expect(err).ToNot(HaveOccurred())
+			defer destination.Close()
+
+			Expect(client.DownloadProductToFile(artifact, destination)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(destination.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("some-contents"))
+		})
+	})
+
+	Describe("ProductFileSize and DownloadProductRange", func() {
+		It("reads a range of the file", func() {
+			writeFile("[example-product,1.0.0]example.pivotal", "some-contents")
+
+			client, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{Directory: directory}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			artifact, err := client.GetLatestProductFile("example-product", "1.0.0", "*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+
+			size, err := client.ProductFileSize(artifact)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(size).To(Equal(int64(13)))
+
+			reader, err := client.DownloadProductRange(artifact, 5, 8)
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+
+			contents, err := ioutil.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal("cont"))
+		})
+	})
+
+	Describe("GetLatestStemcellForProduct", func() {
+		It("returns the latest stemcell in the stemcell path", func() {
+			exampleTileFileName := createPivotalFile("[example-product,1.0-build.0]example*pivotal", "ubuntu-xenial", "97.28")
+			defer os.Remove(exampleTileFileName)
+
+			writeFile("stemcells/[stemcells-ubuntu-xenial,97.28]stemcell.tgz", "")
+			writeFile("stemcells/[stemcells-ubuntu-xenial,97.101]stemcell.tgz", "")
+			writeFile("stemcells/[stemcells-ubuntu-xenial,98.1]stemcell.tgz", "")
+
+			client, err := download_clients.NewLocalClient(download_clients.LocalConfiguration{
+				Directory:    directory,
+				StemcellPath: "stemcells",
+			}, stderr)
+			Expect(err).ToNot(HaveOccurred())
+
+			stemcell, err := client.GetLatestStemcellForProduct(nil, exampleTileFileName)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stemcell.Slug()).To(Equal("stemcells-ubuntu-xenial"))
+			Expect(stemcell.Version()).To(Equal("97.101"))
+		})
+	})
+})
@@ -0,0 +1,580 @@
+package download_clients
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pivotal-cf/om/extractor"
+	"gopkg.in/go-playground/validator.v9"
+)
+
+const (
+	ociManifestMediaType    = "application/vnd.oci.image.manifest.v1+json"
+	dockerManifestMediaType = "application/vnd.docker.distribution.manifest.v2+json"
+	ociTitleAnnotation      = "org.opencontainers.image.title"
+)
+
+type OCIConfiguration struct {
+	Registry     string `validate:"required"`
+	ProductPath  string
+	StemcellPath string
+	DisableSSL   bool
+	DockerConfig string
+}
+
+// ociClient finds products and stemcells stored as OCI artifacts,
+// as pushed by `oras push <registry>/<path>/<slug>:<version> <file>`.
+// Each slug is a repository, each version a tag,
+// and each file a layer annotated with its title.
+type ociClient struct {
+	config   OCIConfiguration
+	registry *url.URL
+	client   *http.Client
+	auth     *ociAuthorizer
+	stderr   *log.Logger
+}
+
+func NewOCIClient(config OCIConfiguration, stderr *log.Logger) (ociClient, error) {
+	validate := validator.New()
+	err := validate.Struct(config)
+	if err != nil {
+		return ociClient{}, err
+	}
+
+	registry := config.Registry
+	if !strings.Contains(registry, "://") {
+		registry = "https://" + registry
+	}
+
+	registryURL, err := url.Parse(strings.TrimSuffix(registry, "/"))
+	if err != nil {
+		return ociClient{}, fmt.Errorf("could not parse oci registry: %w", err)
+	}
+	if registryURL.Scheme != "http" && registryURL.Scheme != "https" {
+		return ociClient{}, fmt.Errorf("oci registry %q must be a host, or start with http:// or https://", config.Registry)
+	}
+
+	credentials, err := dockerCredentials(config.DockerConfig, registryURL.Host)
+	if err != nil {
+		return ociClient{}, err
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: config.DisableSSL,
+			},
+		},
+	}
+
+	return ociClient{
+		config:   config,
+		registry: registryURL,
+		client:   client,
+		auth: &ociAuthorizer{
+			client:      client,
+			credentials: credentials,
+			headers:     map[string]string{},
+		},
+		stderr: stderr,
+	}, nil
+}
+
+func (o ociClient) Name() string {
+	return "oci"
+}
+
+func (o ociClient) GetAllProductVersions(slug string) ([]string, error) {
+	return o.tags(o.config.ProductPath, slug)
+}
+
+func (o ociClient) GetLatestProductFile(slug, version, glob string) (FileArtifacter, error) {
+	// stemcells are looked up by slug too, so they are found in either path
+	repository := o.repository(o.config.ProductPath, slug)
+	manifest, err := o.manifest(repository, version)
+	if err == errOCIManifestNotFound && o.config.StemcellPath != o.config.ProductPath {
+		repository = o.repository(o.config.StemcellPath, slug)
+		manifest, err = o.manifest(repository, version)
+	}
+	if err == errOCIManifestNotFound {
+		return nil, fmt.Errorf("no product files with expected tag %s:%s found", slug, version)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var titles []string
+	var matched []ociLayer
+	for _, layer := range manifest.Layers {
+		title := layer.Annotations[ociTitleAnnotation]
+		if title == "" {
+			continue
+		}
+		titles = append(titles, title)
+
+		if ok, _ := filepath.Match(glob, title); ok {
+			matched = append(matched, layer)
+		}
+	}
+
+	if len(matched) > 1 {
+		var names []string
+		for _, layer := range matched {
+			names = append(names, layer.Annotations[ociTitleAnnotation])
+		}
+		return nil, fmt.Errorf("the glob '%s' matches multiple files. Write your glob to match exactly one of the following:\n  %s", glob, strings.Join(names, "\n  "))
+	}
+
+	if len(matched) == 0 {
+		availableFiles := strings.Join(titles, ", ")
+		if availableFiles == "" {
+			availableFiles = "none"
+		}
+		return nil, fmt.Errorf("the glob '%s' matches no file\navailable files: %s", glob, availableFiles)
+	}
+
+	layer := matched[0]
+	algorithm, hash := splitDigest(layer.Digest)
+	if algorithm != "sha256" {
+		return nil, fmt.Errorf("the digest of %s uses %q, only sha256 is supported", layer.Annotations[ociTitleAnnotation], algorithm)
+	}
+
+	return &ociFileArtifact{
+		name:       fmt.Sprintf("%s/%s:%s/%s", o.registry.Host, repository, version, layer.Annotations[ociTitleAnnotation]),
+		url:        o.registryURL("/v2/" + repository + "/blobs/" + layer.Digest),
+		size:       layer.Size,
+		sha256:     hash,
+		repository: repository,
+		auth:       o.auth,
+	}, nil
+}
+
+func (o ociClient) DownloadProductToFile(fa FileArtifacter, destinationFile *os.File) error {
+	artifact, ok := fa.(*ociFileArtifact)
+	if !ok {
+		return fmt.Errorf("could not download %s from oci: unexpected artifact", fa.Name())
+	}
+
+	resp, err := o.get(artifact.repository, artifact.url, "")
+	if err != nil {
+		return fmt.Errorf("could not download %s: %w", artifact.name, err)
+	}
+	defer resp.Body.Close()
+
+	progressBar, wrappedReader := startProgressBar(o.stderr, artifact.size, resp.Body)
+	defer progressBar.Finish()
+
+	return streamBufferToFile(destinationFile, wrappedReader)
+}
+
+func (o ociClient) ProductFileSize(fa FileArtifacter) (int64, error) {
+	artifact, ok := fa.(*ociFileArtifact)
+	if !ok {
+		return 0, fmt.Errorf("could not download %s from oci: unexpected artifact", fa.Name())
+	}
+
+	return artifact.size, nil
+}
+
+func (o ociClient) DownloadProductRange(fa FileArtifacter, start, end int64) (io.ReadCloser, error) {
+	artifact, ok := fa.(*ociFileArtifact)
+	if !ok {
+		return nil, fmt.Errorf("could not download %s from oci: unexpected artifact", fa.Name())
+	}
+
+	return httpRange(ociRequester{auth: o.auth, repository: artifact.repository}, artifact.url, start, end)
+}
+
+func (o ociClient) GetLatestStemcellForProduct(_ FileArtifacter, downloadedProductFileName string) (StemcellArtifacter, error) {
+	return latestStemcellForProduct(downloadedProductFileName, o.Name(), func(slug string) ([]string, error) {
+		return o.tags(o.config.StemcellPath, slug)
+	})
+}
+
+func (o ociClient) tags(repositoryPath, slug string) ([]string, error) {
+	repository := o.repository(repositoryPath, slug)
+
+	var versions []string
+	next := o.registryURL("/v2/" + repository + "/tags/list")
+	for next != "" {
+		resp, err := o.get(repository, next, "application/json")
+		if err != nil {
+			return nil, fmt.Errorf("could not list tags of %s: %w", repository, err)
+		}
+
+		var tags struct {
+			Tags []string `json:"tags"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&tags)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("could not parse tags of %s: %w", repository, err)
+		}
+
+		versions = append(versions, tags.Tags...)
+		next = o.nextPage(resp)
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no files matching pivnet-product-slug %s found", slug)
+	}
+
+	return versions, nil
+}
+
+type ociManifest struct {
+	MediaType string     `json:"mediaType"`
+	Layers    []ociLayer `json:"layers"`
+}
+
+type ociLayer struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+var errOCIManifestNotFound = fmt.Errorf("manifest not found")
+
+// manifest fetches the manifest of a tag,
+// verifying it against the digest the registry reports.
+func (o ociClient) manifest(repository, tag string) (ociManifest, error) {
+	resp, err := o.get(repository, o.registryURL("/v2/"+repository+"/manifests/"+tag), ociManifestMediaType+", "+dockerManifestMediaType)
+	if err, ok := err.(ociStatusError); ok && err.StatusCode == http.StatusNotFound {
+		return ociManifest{}, errOCIManifestNotFound
+	}
+	if err != nil {
+		return ociManifest{}, fmt.Errorf("could not fetch manifest of %s:%s: %w", repository, tag, err)
+	}
+	defer resp.Body.Close()
+
+	contents, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return ociManifest{}, fmt.Errorf("could not read manifest of %s:%s: %w", repository, tag, err)
+	}
+
+	if digest := resp.Header.Get("Docker-Content-Digest"); digest != "" {
+		algorithm, expected := splitDigest(digest)
+		if algorithm == "sha256" {
+			sum := sha256.Sum256(contents)
+			if actual := hex.EncodeToString(sum[:]); actual != expected {
+				return ociManifest{}, fmt.Errorf("the manifest of %s:%s does not match its digest %s", repository, tag, digest)
+			}
+		}
+	}
+
+	var manifest ociManifest
+	err = json.Unmarshal(contents, &manifest)
+	if err != nil {
+		return ociManifest{}, fmt.Errorf("could not parse manifest of %s:%s: %w", repository, tag, err)
+	}
+
+	return manifest, nil
+}
+
+func (o ociClient) get(repository, requestURL, accept string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	resp, err := o.auth.do(repository, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		return nil, ociStatusError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	return resp, nil
+}
+
+var linkNextRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func (o ociClient) nextPage(resp *http.Response) string {
+	match := linkNextRegex.FindStringSubmatch(resp.Header.Get("Link"))
+	if match == nil {
+		return ""
+	}
+
+	next, err := resp.Request.URL.Parse(match[1])
+	if err != nil {
+		return ""
+	}
+	return next.String()
+}
+
+func (o ociClient) repository(repositoryPath, slug string) string {
+	return strings.Trim(path.Join(repositoryPath, slug), "/")
+}
+
+func (o ociClient) registryURL(p string) string {
+	return o.registry.String() + p
+}
+
+type ociStatusError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e ociStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %s", e.Status)
+}
+
+func splitDigest(digest string) (string, string) {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 {
+		return "", digest
+	}
+	return parts[0], parts[1]
+}
+
+type ociFileArtifact struct {
+	name       string
+	url        string
+	size       int64
+	sha256     string
+	repository string
+	auth       *ociAuthorizer
+}
+
+func (f ociFileArtifact) ProductMetadata() (*extractor.Metadata, error) {
+	return extractor.NewMetadataExtractor(extractor.WithHTTPClient(ociRequester{
+		auth:       f.auth,
+		repository: f.repository,
+	})).ExtractFromURL(f.url)
+}
+
+func (f ociFileArtifact) Name() string {
+	return f.name
+}
+
+func (f ociFileArtifact) SHA256() string {
+	return f.sha256
+}
+
+// ociRequester authorizes the ranged requests
+// used to read the metadata of a product without downloading it.
+type ociRequester struct {
+	auth       *ociAuthorizer
+	repository string
+}
+
+func (r ociRequester) Do(req *http.Request) (*http.Response, error) {
+	return r.auth.do(r.repository, req)
+}
+
+type dockerCredential struct {
+	Username string
+	Password string
+}
+
+// dockerCredentials reads the credentials for the registry
+// from the config.json in the docker config directory, as written by `docker login`.
+func dockerCredentials(configDir, host string) (*dockerCredential, error) {
+	if configDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, nil
+		}
+		configDir = filepath.Join(home, ".docker")
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(configDir, "config.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read docker config: %w", err)
+	}
+
+	var config struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	err = json.Unmarshal(contents, &config)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse docker config: %w", err)
+	}
+
+	for key, auth := range config.Auths {
+		if dockerConfigHost(key) != host {
+			continue
+		}
+
+		if auth.Auth == "" {
+			return &dockerCredential{Username: auth.Username, Password: auth.Password}, nil
+		}
+
+		decoded, err := base64.StdEncoding.DecodeString(auth.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode docker config auth for %s: %w", key, err)
+		}
+
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("could not decode docker config auth for %s: expected username:password", key)
+		}
+
+		return &dockerCredential{Username: parts[0], Password: parts[1]}, nil
+	}
+
+	return nil, nil
+}
+
+// dockerConfigHost returns the host of an auths key,
+// which may be a host or a url such as https://index.docker.io/v1/.
+func dockerConfigHost(key string) string {
+	if i := strings.Index(key, "://"); i >= 0 {
+		key = key[i+3:]
+	}
+	host := strings.SplitN(key, "/", 2)[0]
+
+	if host == "index.docker.io" {
+		return "registry-1.docker.io"
+	}
+	return host
+}
+
+// ociAuthorizer answers the registry's challenges
+// with basic auth or a bearer token, remembering the answer per repository.
+type ociAuthorizer struct {
+	client      *http.Client
+	credentials *dockerCredential
+
+	mutex   sync.Mutex
+	headers map[string]string
+}
+
+var challengeParamRegex = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+func (a *ociAuthorizer) do(repository string, req *http.Request) (*http.Response, error) {
+	a.mutex.Lock()
+	header := a.headers[repository]
+	a.mutex.Unlock()
+
+	if header != "" {
+		req.Header.Set("Authorization", header)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// a remembered token may have expired, so every 401 is answered once
+	if resp.StatusCode != http.StatusUnauthorized {
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+
+	header, err = a.authorize(resp.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		return nil, err
+	}
+
+	a.mutex.Lock()
+	a.headers[repository] = header
+	a.mutex.Unlock()
+
+	retry := req.Clone(req.Context())
+	retry.Header.Set("Authorization", header)
+	return a.client.Do(retry)
+}
+
+func (a *ociAuthorizer) authorize(challenge string) (string, error) {
+	scheme := strings.ToLower(strings.SplitN(challenge, " ", 2)[0])
+	params := map[string]string{}
+	for _, match := range challengeParamRegex.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(match[1])] = match[2]
+	}
+
+	switch scheme {
+	case "basic":
+		if a.credentials == nil {
+			return "", fmt.Errorf("the oci registry requires credentials, but none were found in the docker config")
+		}
+		return "Basic " + basicAuth(a.credentials), nil
+	case "bearer":
+		return a.token(params)
+	}
+
+	return "", fmt.Errorf("the oci registry requested unsupported authentication %q", challenge)
+}
+
+func (a *ociAuthorizer) token(params map[string]string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("the oci registry returned an invalid token realm %q", params["realm"])
+	}
+
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if a.credentials != nil {
+		req.Header.Set("Authorization", "Basic "+basicAuth(a.credentials))
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("could not fetch oci registry token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("could not fetch oci registry token: unexpected status %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&token)
+	if err != nil {
+		return "", fmt.Errorf("could not parse oci registry token: %w", err)
+	}
+
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	return "Bearer " + token.Token, nil
+}
+
+func basicAuth(credentials *dockerCredential) string {
+	return base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password))
+}
@@ -0,0 +1,323 @@
+package download_clients_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf/om/download_clients"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// fakeRegistry serves OCI artifacts the way registry:2 does,
+// behind token authentication.
+type fakeRegistry struct {
+	manifests map[string][]byte
+	blobs     map[string][]byte
+	tags      map[string][]string
+	username  string
+	password  string
+
+	tamperManifests bool
+	server          *httptest.Server
+}
+
+func newFakeRegistry() *fakeRegistry {
+	registry := &fakeRegistry{
+		manifests: map[string][]byte{},
+		blobs:     map[string][]byte{},
+		tags:      map[string][]string{},
+		username:  "some-user",
+		password:  "some-password",
+	}
+	registry.server = httptest.NewServer(registry)
+	return registry
+}
+
+func (r *fakeRegistry) push(repository, tag string, files map[string][]byte) {
+	var layers []map[string]interface{}
+	for name, contents := range files {
+		digest := r.digest(contents)
+		r.blobs[digest] = contents
+		layers = append(layers, map[string]interface{}{
+			"mediaType":   "application/vnd.oci.image.layer.v1.tar",
+			"digest":      digest,
+			"size":        len(contents),
+			"annotations": map[string]string{"org.opencontainers.image.title": name},
+		})
+	}
+
+	manifest, err := json.Marshal(map[string]interface{}{
+		"schemaVersion": 2,
+		"mediaType":     "application/vnd.oci.image.manifest.v1+json",
+		"layers":        layers,
+	})
+	Expect(err).ToNot(HaveOccurred())
+
+	r.manifests[repository+":"+tag] = manifest
+	r.tags[repository] = append(r.tags[repository], tag)
+}
+
+func (r *fakeRegistry) digest(contents []byte) string {
+	sum := sha256.Sum256(contents)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func (r *fakeRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		username, password, ok := req.BasicAuth()
+		if !ok || username != r.username || password != r.password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"token": "token-for-" + req.URL.Query().Get("scope")})
+		return
+	}
+
+	path := strings.TrimPrefix(req.URL.Path, "/v2/")
+	var repository string
+	for _, kind := range []string{"/tags/list", "/manifests/", "/blobs/"} {
+		if i := strings.Index(path, kind); i >= 0 {
+			repository = path[:i]
+		}
+	}
+
+	if req.Header.Get("Authorization") != "Bearer token-for-repository:"+repository+":pull" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake-registry",scope="repository:%s:pull"`, r.server.URL, repository))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case strings.HasSuffix(path, "/tags/list"):
+		tags, ok := r.tags[repository]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if req.URL.Query().Get("last") == "" && len(tags) > 1 {
+			w.Header().Set("Link", fmt.Sprintf(`</v2/%s/tags/list?n=1&last=%s>; rel="next"`, repository, tags[0]))
+			tags = tags[:1]
+		} else if req.URL.Query().Get("last") != "" {
+			tags = tags[1:]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"name": repository, "tags": tags})
+	case strings.Contains(path, "/manifests/"):
+		manifest, ok := r.manifests[repository+":"+path[strings.LastIndex(path, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", r.digest(manifest))
+		if r.tamperManifests {
+			manifest = bytes.Replace(manifest, []byte("schemaVersion"), []byte("schemaversion"), 1)
+		}
+		_, _ = w.Write(manifest)
+	case strings.Contains(path, "/blobs/"):
+		digest := path[strings.LastIndex(path, "/")+1:]
+		blob, ok := r.blobs[digest]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Etag", `"`+digest+`"`)
+		http.ServeContent(w, req, "", time.Time{}, bytes.NewReader(blob))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+var _ = Describe("ociClient", func() {
+	var (
+		registry     *fakeRegistry
+		dockerConfig string
+		stderr       *log.Logger
+	)
+
+	writeDockerConfig := func(host, username, password string) {
+		auth := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		contents := fmt.Sprintf(`{"auths": {"%s": {"auth": "%s"}}}`, host, auth)
+		Expect(ioutil.WriteFile(filepath.Join(dockerConfig, "config.json"), []byte(contents), 0600)).To(Succeed())
+	}
+
+	newClient := func(productPath, stemcellPath string) download_clients.ProductDownloader {
+		client, err := download_clients.NewOCIClient(download_clients.OCIConfiguration{
+			Registry:     registry.server.URL,
+			ProductPath:  productPath,
+			StemcellPath: stemcellPath,
+			DockerConfig: dockerConfig,
+		}, stderr)
+		Expect(err).ToNot(HaveOccurred())
+		return client
+	}
+
+	BeforeEach(func() {
+		registry = newFakeRegistry()
+		stderr = log.New(GinkgoWriter, "", 0)
+
+		var err error
+		dockerConfig, err = ioutil.TempDir("", "docker-config")
+		Expect(err).ToNot(HaveOccurred())
+
+		writeDockerConfig(strings.TrimPrefix(registry.server.URL, "http://"), registry.username, registry.password)
+	})
+
+	AfterEach(func() {
+		registry.server.Close()
+		Expect(os.RemoveAll(dockerConfig)).To(Succeed())
+	})
+
+	Describe("NewOCIClient", func() {
+		It("requires a registry", func() {
+			_, err := download_clients.NewOCIClient(download_clients.OCIConfiguration{}, stderr)
+			Expect(err).To(MatchError(ContainSubstring("Field validation for 'Registry' failed on the 'required' tag")))
+		})
+
+		It("errors when the docker config cannot be parsed", func() {
+			Expect(ioutil.WriteFile(filepath.Join(dockerConfig, "config.json"), []byte("{"), 0600)).To(Succeed())
+
+			_, err := download_clients.NewOCIClient(download_clients.OCIConfiguration{
+				Registry:     "registry.example.com",
+				DockerConfig: dockerConfig,
+			}, stderr)
+			Expect(err).To(MatchError(ContainSubstring("could not parse docker config")))
+		})
+	})
+
+	Describe("GetAllProductVersions", func() {
+		It("returns every tag of the repository, authenticating with the docker config", func() {
+			registry.push("products/example-product", "1.0.0", map[string][]byte{"example.pivotal": []byte("1")})
+			registry.push("products/example-product", "1.1.0", map[string][]byte{"example.pivotal": []byte("2")})
+
+			versions, err := newClient("products", "").GetAllProductVersions("example-product")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(Equal([]string{"1.0.0", "1.1.0"}))
+		})
+
+		It("errors when the credentials are rejected", func() {
+			registry.push("example-product", "1.0.0", map[string][]byte{"example.pivotal": []byte("1")})
+			writeDockerConfig(strings.TrimPrefix(registry.server.URL, "http://"), "some-user", "wrong-password")
+
+			_, err := newClient("", "").GetAllProductVersions("example-product")
+			Expect(err).To(MatchError(ContainSubstring("could not fetch oci registry token: unexpected status 401")))
+		})
+
+		It("errors when the repository does not exist", func() {
+			_, err := newClient("", "").GetAllProductVersions("example-product")
+			Expect(err).To(MatchError(ContainSubstring("could not list tags of example-product: unexpected status 404")))
+		})
+	})
+
+	Describe("GetLatestProductFile", func() {
+		BeforeEach(func() {
+			registry.push("products/example-product", "1.0.0", map[string][]byte{
+				"example.pivotal":       []byte("some-tile"),
+				"example-light.pivotal": []byte("some-light-tile"),
+			})
+			registry.push("stemcells/stemcells-ubuntu-xenial", "97.28", map[string][]byte{
+				"light-bosh-stemcell-97.28-aws-xen-hvm-ubuntu-xenial-go_agent.tgz": []byte("some-stemcell"),
+			})
+		})
+
+		It("returns the layer matching the glob, with its digest", func() {
+			artifact, err := newClient("products", "stemcells").GetLatestProductFile("example-product", "1.0.0", "example.*")
+			Expect(err).ToNot(HaveOccurred())
+
+			host := strings.TrimPrefix(registry.server.URL, "http://")
+			Expect(artifact.Name()).To(Equal(host + "/products/example-product:1.0.0/example.pivotal"))
+			Expect("sha256:" + artifact.SHA256()).To(Equal(registry.digest([]byte("some-tile"))))
+		})
+
+		It("finds stemcells in the stemcell path", func() {
+			artifact, err := newClient("products", "stemcells").GetLatestProductFile("stemcells-ubuntu-xenial", "97.28", "*aws*")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filepath.Base(artifact.Name())).To(Equal("light-bosh-stemcell-97.28-aws-xen-hvm-ubuntu-xenial-go_agent.tgz"))
+		})
+
+		It("errors when the glob matches multiple layers", func() {
+			_, err := newClient("products", "stemcells").GetLatestProductFile("example-product", "1.0.0", "*.pivotal")
+			Expect(err).To(MatchError(ContainSubstring("the glob '*.pivotal' matches multiple files")))
+		})
+
+		It("errors when the glob matches no layer", func() {
+			_, err := newClient("products", "stemcells").GetLatestProductFile("example-product", "1.0.0", "*.tgz")
+			Expect(err).To(MatchError(ContainSubstring("the glob '*.tgz' matches no file")))
+		})
+
+		It("errors when the tag does not exist", func() {
+			_, err := newClient("products", "stemcells").GetLatestProductFile("example-product", "2.0.0", "*.pivotal")
+			Expect(err).To(MatchError("no product files with expected tag example-product:2.0.0 found"))
+		})
+
+		It("errors when the manifest does not match its digest", func() {
+			registry.tamperManifests = true
+
+			_, err := newClient("products", "stemcells").GetLatestProductFile("example-product", "1.0.0", "example.*")
+			Expect(err).To(MatchError(ContainSubstring("the manifest of products/example-product:1.0.0 does not match its digest")))
+		})
+	})
+
+	Describe("DownloadProductToFile and ProductMetadata", func() {
+		It("downloads the layer and reads the product metadata from it", func() {
+			tileFileName := createPivotalFile("[example-product,1.0.0]example*pivotal", "ubuntu-xenial", "97.28")
+			defer os.Remove(tileFileName)
+
+			tile, err := ioutil.ReadFile(tileFileName)
+			Expect(err).ToNot(HaveOccurred())
+			registry.push("example-product", "1.0.0", map[string][]byte{"example.pivotal": tile})
+			registry.push("stemcells-ubuntu-xenial", "97.28", map[string][]byte{"stemcell.tgz": []byte("1")})
+			registry.push("stemcells-ubuntu-xenial", "97.101", map[string][]byte{"stemcell.tgz": []byte("2")})
+
+			client := newClient("", "")
+
+			artifact, err := client.GetLatestProductFile("example-product", "1.0.0", "*.pivotal")
+			Expect(err).ToNot(HaveOccurred())
+
+			metadata, err := artifact.ProductMetadata()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(metadata.Name).To(Equal("example-product"))
+
+			destination, err := ioutil.TempFile("", "destination")
+			Expect(err).ToNot(HaveOccurred())
+			defer os.Remove(destination.Name())
+			defer destination.Close()
+
+			Expect(client.DownloadProductToFile(artifact, destination)).To(Succeed())
+
+			contents, err := ioutil.ReadFile(destination.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal(tile))
+
+			rangedClient := client.(download_clients.RangeDownloader)
+			size, err := rangedClient.ProductFileSize(artifact)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(size).To(Equal(int64(len(tile))))
+
+			reader, err := rangedClient.DownloadProductRange(artifact, 10, 19)
+			Expect(err).ToNot(HaveOccurred())
+			defer reader.Close()
+
+			chunk, err := ioutil.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(chunk).To(Equal(tile[10:20]))
+
+			stemcell, err := client.GetLatestStemcellForProduct(artifact, destination.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stemcell.Slug()).To(Equal("stemcells-ubuntu-xenial"))
+			Expect(stemcell.Version()).To(Equal("97.101"))
+		})
+	})
+})
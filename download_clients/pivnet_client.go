@@ -6,10 +6,12 @@ import (
 	"github.com/pivotal-cf/pivnet-cli/filter"
 	"io"
 	"log"
+	"net/http"
 	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/pivotal-cf/go-pivnet/v5"
 	"github.com/pivotal-cf/go-pivnet/v5/download"
@@ -69,6 +71,9 @@ type pivnetClient struct {
 	filter     *filter.Filter
 	stderr     *log.Logger
 	client     pivnet.Client
+
+	linksMutex sync.Mutex
+	links      map[int]string
 }
 
 func (p *pivnetClient) GetAllProductVersions(slug string) ([]string, error) {
@@ -131,6 +136,67 @@ func (p *pivnetClient) DownloadProductToFile(fa FileArtifacter, file *os.File) e
 	return nil
 }
 
+func (p *pivnetClient) ProductFileSize(fa FileArtifacter) (int64, error) {
+	fileArtifact := fa.(*PivnetFileArtifact)
+	if fileArtifact.productFile.Size <= 0 {
+		return 0, ErrRangesNotSupported
+	}
+
+	return int64(fileArtifact.productFile.Size), nil
+}
+
+func (p *pivnetClient) DownloadProductRange(fa FileArtifacter, start, end int64) (io.ReadCloser, error) {
+	fileArtifact := fa.(*PivnetFileArtifact)
+
+	// the link fetcher changes the redirect policy of the pivnet client,
+	// so the ranges are requested with a client of their own
+	rangeClient := &http.Client{Transport: p.client.HTTP.Transport}
+
+	link, err := p.signedDownloadLink(fileArtifact, false)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := httpRange(rangeClient, link, start, end)
+	if err == nil {
+		return reader, nil
+	}
+
+	// the signed link expires, so it is fetched again once
+	link, err = p.signedDownloadLink(fileArtifact, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return httpRange(rangeClient, link, start, end)
+}
+
+func (p *pivnetClient) signedDownloadLink(fileArtifact *PivnetFileArtifact, refresh bool) (string, error) {
+	p.linksMutex.Lock()
+	defer p.linksMutex.Unlock()
+
+	if p.links == nil {
+		p.links = map[int]string{}
+	}
+
+	if link, ok := p.links[fileArtifact.productFile.ID]; ok && !refresh {
+		return link, nil
+	}
+
+	downloadLink, err := fileArtifact.productFile.DownloadLink()
+	if err != nil {
+		return "", fmt.Errorf("cannot retrieve download link: %w", err)
+	}
+
+	link, err := pivnet.NewProductFileLinkFetcher(downloadLink, p.client).NewDownloadLink()
+	if err != nil {
+		return "", fmt.Errorf("could not download product file %s: %s", fileArtifact.slug, err)
+	}
+
+	p.links[fileArtifact.productFile.ID] = link
+	return link, nil
+}
+
 func (p *pivnetClient) GetLatestStemcellForProduct(fa FileArtifacter, _ string) (StemcellArtifacter, error) {
 	fileArtifact := fa.(*PivnetFileArtifact)
 	dependencies, err := p.downloader.ReleaseDependencies(fileArtifact.slug, fileArtifact.releaseID)
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -112,6 +113,85 @@ var _ = Describe("Grabbing Metadata", func() {
 	})
 })
 
+var _ = Describe("Downloading ranges", func() {
+	It("downloads ranges from the signed download link", func() {
+		stdout := log.New(GinkgoWriter, "", 0)
+		stderr := log.New(GinkgoWriter, "", 0)
+		contents := "some-product-contents"
+
+		server := ghttp.NewTLSServer()
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v2/products/pivnet-product/releases"),
+				ghttp.RespondWith(http.StatusOK, `{"releases": [{"id": 24, "version": "1.0.0"}]}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v2/products/pivnet-product/releases/24"),
+				ghttp.RespondWith(http.StatusOK, `{"id":24}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v2/products/pivnet-product/releases/24/product_files"),
+				ghttp.RespondWith(http.StatusOK, fmt.Sprintf(`{
+  "product_files": [
+  {
+    "id": 21,
+    "aws_object_key": "product.pivotal",
+    "size": %d,
+    "_links": {
+      "download": {
+        "href": "%s/api/v2/products/pivnet-product/releases/24/product_files/21/download"
+      }
+    }
+  }
+]
+}`, len(contents), server.URL())),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/api/v2/products/pivnet-product/releases/24/file_groups"),
+				ghttp.RespondWith(http.StatusOK, `{}`),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/api/v2/products/pivnet-product/releases/24/product_files/21/download"),
+				ghttp.RespondWith(http.StatusFound, `{}`, http.Header{"Location": {server.URL() + "/signed/product.pivotal"}}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/signed/product.pivotal"),
+				ghttp.VerifyHeaderKV("Range", "bytes=0-3"),
+				func(w http.ResponseWriter, r *http.Request) {
+					http.ServeContent(w, r, "product.pivotal", time.Now(), strings.NewReader(contents))
+				},
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/signed/product.pivotal"),
+				ghttp.VerifyHeaderKV("Range", "bytes=5-11"),
+				func(w http.ResponseWriter, r *http.Request) {
+					http.ServeContent(w, r, "product.pivotal", time.Now(), strings.NewReader(contents))
+				},
+			),
+		)
+		defer server.Close()
+
+		client := download_clients.NewPivnetClient(stdout, stderr, download_clients.DefaultPivnetFactory, "", true, server.URL())
+		file, err := client.GetLatestProductFile("pivnet-product", "1.0.0", "*.pivotal")
+		Expect(err).NotTo(HaveOccurred())
+
+		rangedClient := client.(download_clients.RangeDownloader)
+		size, err := rangedClient.ProductFileSize(file)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(int64(len(contents))))
+
+		for _, r := range [][2]int64{{0, 3}, {5, 11}} {
+			reader, err := rangedClient.DownloadProductRange(file, r[0], r[1])
+			Expect(err).NotTo(HaveOccurred())
+
+			chunk, err := ioutil.ReadAll(reader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reader.Close()).To(Succeed())
+			Expect(string(chunk)).To(Equal(contents[r[0] : r[1]+1]))
+		}
+	})
+})
+
 var _ = Describe("PivnetClient", func() {
 	var (
 		stdout *log.Logger
@@ -0,0 +1,129 @@
+package download_clients
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// The blobstore, local and http sources all store files
+// the way download-product persists them from Pivotal Network:
+// prefixed with [slug,version], optionally under a product or stemcell path.
+
+func versionsFromPrefixedFiles(files []string, path, slug string) ([]string, error) {
+	productFileCompiledRegex := regexp.MustCompile(
+		fmt.Sprintf(`^/?%s/?\[%s,(.*?)\]`,
+			regexp.QuoteMeta(strings.Trim(path, "/")),
+			slug,
+		),
+	)
+
+	var versions []string
+	versionFound := make(map[string]bool)
+	for _, fileName := range files {
+		match := productFileCompiledRegex.FindStringSubmatch(fileName)
+		if match != nil {
+			version := match[1]
+			if !versionFound[version] {
+				versions = append(versions, version)
+				versionFound[version] = true
+			}
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no files matching pivnet-product-slug %s found", slug)
+	}
+
+	return versions, nil
+}
+
+func findPrefixedFile(files []string, productPath, stemcellPath, slug, version, glob string) (string, error) {
+	validFile := regexp.MustCompile(
+		fmt.Sprintf(`^/?(%s|%s)/?\[%s,%s\]`,
+			regexp.QuoteMeta(strings.Trim(productPath, "/")),
+			regexp.QuoteMeta(strings.Trim(stemcellPath, "/")),
+			slug,
+			regexp.QuoteMeta(version),
+		),
+	)
+	var prefixedFilepaths []string
+	var globMatchedFilepaths []string
+
+	for _, f := range files {
+		if validFile.MatchString(f) {
+			prefixedFilepaths = append(prefixedFilepaths, f)
+		}
+	}
+
+	if len(prefixedFilepaths) == 0 {
+		return "", fmt.Errorf("no product files with expected prefix [%s,%s] found. Please ensure the file you're trying to download was initially persisted from Pivotal Network net using an appropriately configured download-product command", slug, version)
+	}
+
+	for _, f := range prefixedFilepaths {
+		removePrefixRegex := regexp.MustCompile(`^\[.*\]`)
+		baseFilename := removePrefixRegex.ReplaceAllString(filepath.Base(f), "")
+
+		matched, _ := filepath.Match(glob, baseFilename)
+		if matched {
+			globMatchedFilepaths = append(globMatchedFilepaths, f)
+		}
+	}
+
+	if len(globMatchedFilepaths) > 1 {
+		return "", fmt.Errorf("the glob '%s' matches multiple files. Write your glob to match exactly one of the following:\n  %s", glob, strings.Join(globMatchedFilepaths, "\n  "))
+	}
+
+	if len(globMatchedFilepaths) == 0 {
+		availableFiles := strings.Join(prefixedFilepaths, ", ")
+		if availableFiles == "" {
+			availableFiles = "none"
+		}
+		return "", fmt.Errorf("the glob '%s' matches no file\navailable files: %s", glob, availableFiles)
+	}
+
+	return globMatchedFilepaths[0], nil
+}
+
+// latestStemcellForProduct finds the latest patch of the stemcell the
+// downloaded product requires, amongst the versions the source has.
+func latestStemcellForProduct(downloadedProductFileName string, source string, allVersions func(slug string) ([]string, error)) (StemcellArtifacter, error) {
+	definedStemcell, err := stemcellFromProduct(downloadedProductFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	definedMajor, definedPatch, err := stemcellVersionPartsFromString(definedStemcell.Version())
+	if err != nil {
+		return nil, err
+	}
+
+	allStemcellVersions, err := allVersions(definedStemcell.Slug())
+	if err != nil {
+		return nil, fmt.Errorf("could not find stemcells on %s: %s", source, err)
+	}
+
+	var filteredVersions []string
+	for _, version := range allStemcellVersions {
+		major, patch, _ := stemcellVersionPartsFromString(version)
+
+		if major == definedMajor && patch >= definedPatch {
+			filteredVersions = append(filteredVersions, version)
+		}
+	}
+
+	if len(filteredVersions) == 0 {
+		return nil, fmt.Errorf("no versions could be found equal to or greater than %s", definedStemcell.Version())
+	}
+
+	latestVersion, err := getLatestStemcellVersion(filteredVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stemcell{
+		version: latestVersion,
+		slug:    definedStemcell.Slug(),
+	}, nil
+}
@@ -0,0 +1,290 @@
+package download_clients
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/cheggaaa/pb/v3"
+)
+
+//counterfeiter:generate -o ./fakes/range_downloader.go --fake-name RangeDownloader . RangeDownloader
+
+// RangeDownloader is implemented by the clients that can download part of a file,
+// so an interrupted download can be resumed, and large files fetched in parallel chunks.
+type RangeDownloader interface {
+	// ProductFileSize returns ErrRangesNotSupported
+	// when the file cannot be downloaded in ranges.
+	ProductFileSize(fa FileArtifacter) (int64, error)
+	// DownloadProductRange reads the bytes from start to end, inclusive.
+	DownloadProductRange(fa FileArtifacter, start, end int64) (io.ReadCloser, error)
+}
+
+var ErrRangesNotSupported = errors.New("the source does not support ranged downloads")
+
+const DefaultChunkSize int64 = 64 * 1024 * 1024
+
+type RangedDownloadOptions struct {
+	Threads   int
+	ChunkSize int64
+}
+
+// rangedDownloadState is kept next to the partial file,
+// recording the chunks that have been completely written to it.
+type rangedDownloadState struct {
+	Name      string  `json:"name"`
+	Size      int64   `json:"size"`
+	SHA256    string  `json:"sha256,omitempty"`
+	ChunkSize int64   `json:"chunk_size"`
+	Completed []int64 `json:"completed"`
+}
+
+// DownloadInRanges downloads the file to path in chunks,
+// resuming from the chunks a previous attempt completed.
+// The state of the download is kept in path.state until it completes.
+func DownloadInRanges(client RangeDownloader, fa FileArtifacter, path string, options RangedDownloadOptions, stderr *log.Logger) error {
+	size, err := client.ProductFileSize(fa)
+	if err != nil {
+		return err
+	}
+
+	if options.Threads < 1 {
+		options.Threads = 1
+	}
+	if options.ChunkSize < 1 {
+		options.ChunkSize = DefaultChunkSize
+	}
+
+	statePath := path + ".state"
+	state := rangedDownloadState{
+		Name:      fa.Name(),
+		Size:      size,
+		SHA256:    fa.SHA256(),
+		ChunkSize: options.ChunkSize,
+	}
+
+	completed := map[int64]bool{}
+	if previous, ok := readRangedDownloadState(statePath, path); ok &&
+		previous.Name == state.Name &&
+		previous.Size == state.Size &&
+		previous.SHA256 == state.SHA256 &&
+		previous.ChunkSize == state.ChunkSize {
+		for _, chunk := range previous.Completed {
+			completed[chunk] = true
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("could not open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if len(completed) == 0 {
+		err = file.Truncate(0)
+		if err != nil {
+			return fmt.Errorf("could not truncate %s: %w", path, err)
+		}
+	}
+
+	err = file.Truncate(size)
+	if err != nil {
+		return fmt.Errorf("could not allocate %s: %w", path, err)
+	}
+
+	chunks := (size + options.ChunkSize - 1) / options.ChunkSize
+	var pending []int64
+	var downloaded int64
+	for chunk := int64(0); chunk < chunks; chunk++ {
+		if completed[chunk] {
+			downloaded += chunkLength(chunk, size, options.ChunkSize)
+			state.Completed = append(state.Completed, chunk)
+		} else {
+			pending = append(pending, chunk)
+		}
+	}
+
+	if downloaded > 0 {
+		stderr.Printf("resuming download of %s: %d of %d bytes already downloaded", fa.Name(), downloaded, size)
+	}
+
+	progressBar := pb.Default.New(0)
+	progressBar.SetWriter(stderr.Writer())
+	progressBar.Set(pb.Bytes, true)
+	progressBar.SetTotal(size)
+	progressBar.SetCurrent(downloaded)
+	progressBar.SetMaxWidth(80)
+	progressBar.Start()
+	defer progressBar.Finish()
+
+	var (
+		mutex    sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+
+	chunkQueue := make(chan int64)
+	for i := 0; i < options.Threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for chunk := range chunkQueue {
+				mutex.Lock()
+				failed := firstErr != nil
+				mutex.Unlock()
+				if failed {
+					continue
+				}
+
+				err := downloadChunk(client, fa, file, chunk, size, options.ChunkSize, progressBar)
+
+				mutex.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					state.Completed = append(state.Completed, chunk)
+					err = writeRangedDownloadState(statePath, state)
+					if err != nil && firstErr == nil {
+						firstErr = err
+					}
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, chunk := range pending {
+		mutex.Lock()
+		failed := firstErr != nil
+		mutex.Unlock()
+		if failed {
+			break
+		}
+
+		chunkQueue <- chunk
+	}
+	close(chunkQueue)
+	wg.Wait()
+
+	if firstErr != nil {
+		var done int64
+		for _, chunk := range state.Completed {
+			done += chunkLength(chunk, size, options.ChunkSize)
+		}
+		return fmt.Errorf("download of %s interrupted after %d of %d bytes, run the command again to resume: %w", fa.Name(), done, size, firstErr)
+	}
+
+	_ = os.Remove(statePath)
+	return nil
+}
+
+func downloadChunk(client RangeDownloader, fa FileArtifacter, file *os.File, chunk, size, chunkSize int64, progressBar *pb.ProgressBar) error {
+	start := chunk * chunkSize
+	length := chunkLength(chunk, size, chunkSize)
+
+	reader, err := client.DownloadProductRange(fa, start, start+length-1)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+
+	written, err := io.Copy(&offsetWriter{file: file, offset: start}, progressBar.NewProxyReader(io.LimitReader(reader, length)))
+	if err != nil {
+		return err
+	}
+
+	if written != length {
+		return fmt.Errorf("expected %d bytes from offset %d, got %d", length, start, written)
+	}
+
+	return nil
+}
+
+func chunkLength(chunk, size, chunkSize int64) int64 {
+	start := chunk * chunkSize
+	if start+chunkSize > size {
+		return size - start
+	}
+	return chunkSize
+}
+
+func readRangedDownloadState(statePath, path string) (rangedDownloadState, bool) {
+	if _, err := os.Stat(path); err != nil {
+		return rangedDownloadState{}, false
+	}
+
+	contents, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		return rangedDownloadState{}, false
+	}
+
+	var state rangedDownloadState
+	if json.Unmarshal(contents, &state) != nil {
+		return rangedDownloadState{}, false
+	}
+
+	return state, true
+}
+
+func writeRangedDownloadState(statePath string, state rangedDownloadState) error {
+	sort.Slice(state.Completed, func(i, j int) bool { return state.Completed[i] < state.Completed[j] })
+
+	contents, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	// written aside and renamed, so an interruption never leaves a truncated state
+	err = ioutil.WriteFile(statePath+".tmp", contents, 0644)
+	if err != nil {
+		return fmt.Errorf("could not write download state: %w", err)
+	}
+
+	return os.Rename(statePath+".tmp", statePath)
+}
+
+type offsetWriter struct {
+	file   *os.File
+	offset int64
+}
+
+func (w *offsetWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset)
+	w.offset += int64(n)
+	return n, err
+}
+
+type httpDoer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+// httpRange requests the bytes from start to end, inclusive,
+// failing when the server does not honour the range.
+func httpRange(client httpDoer, url string, start, end int64) (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusPartialContent {
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("could not download bytes %d-%d: unexpected status %s", start, end, resp.Status)
+	}
+
+	return resp.Body, nil
+}
@@ -0,0 +1,139 @@
+package download_clients_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pivotal-cf/om/download_clients"
+	"github.com/pivotal-cf/om/download_clients/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DownloadInRanges", func() {
+	const contents = "0123456789abcdefghij-"
+
+	var (
+		client    *fakes.RangeDownloader
+		artifact  *fakes.FileArtifacter
+		directory string
+		path      string
+		stderr    *log.Logger
+		options   download_clients.RangedDownloadOptions
+	)
+
+	BeforeEach(func() {
+		client = &fakes.RangeDownloader{}
+		client.ProductFileSizeReturns(int64(len(contents)), nil)
+		client.DownloadProductRangeStub = func(_ download_clients.FileArtifacter, start, end int64) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(contents[start : end+1])), nil
+		}
+
+		artifact = &fakes.FileArtifacter{}
+		artifact.NameReturns("product.pivotal")
+		artifact.SHA256Returns("some-sha")
+
+		var err error
+		directory, err = ioutil.TempDir("", "ranged-download")
+		Expect(err).ToNot(HaveOccurred())
+		path = filepath.Join(directory, "product.pivotal.partial")
+
+		stderr = log.New(GinkgoWriter, "", 0)
+		options = download_clients.RangedDownloadOptions{Threads: 3, ChunkSize: 4}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(directory)).To(Succeed())
+	})
+
+	requestedStarts := func() []int64 {
+		var starts []int64
+		for i := 0; i < client.DownloadProductRangeCallCount(); i++ {
+			_, start, _ := client.DownloadProductRangeArgsForCall(i)
+			starts = append(starts, start)
+		}
+		return starts
+	}
+
+	It("downloads every chunk in parallel and removes the state", func() {
+		err := download_clients.DownloadInRanges(client, artifact, path, options, stderr)
+		Expect(err).ToNot(HaveOccurred())
+
+		written, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(written)).To(Equal(contents))
+
+		var ranges [][2]int64
+		for i := 0; i < client.DownloadProductRangeCallCount(); i++ {
+			_, start, end := client.DownloadProductRangeArgsForCall(i)
+			ranges = append(ranges, [2]int64{start, end})
+		}
+		Expect(ranges).To(ConsistOf([2]int64{0, 3}, [2]int64{4, 7}, [2]int64{8, 11}, [2]int64{12, 15}, [2]int64{16, 19}, [2]int64{20, 20}))
+
+		Expect(path + ".state").ToNot(BeAnExistingFile())
+	})
+
+	It("resumes from the chunks a previous attempt completed", func() {
+		options.Threads = 1
+		client.DownloadProductRangeStub = func(_ download_clients.FileArtifacter, start, end int64) (io.ReadCloser, error) {
+			if start == 12 {
+				return nil, errors.New("connection reset")
+			}
+			return ioutil.NopCloser(strings.NewReader(contents[start : end+1])), nil
+		}
+
+		err := download_clients.DownloadInRanges(client, artifact, path, options, stderr)
+		Expect(err).To(MatchError("download of product.pivotal interrupted after 12 of 21 bytes, run the command again to resume: connection reset"))
+		Expect(path + ".state").To(BeAnExistingFile())
+
+		client = &fakes.RangeDownloader{}
+		client.ProductFileSizeReturns(int64(len(contents)), nil)
+		client.DownloadProductRangeStub = func(_ download_clients.FileArtifacter, start, end int64) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(contents[start : end+1])), nil
+		}
+
+		err = download_clients.DownloadInRanges(client, artifact, path, options, stderr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(requestedStarts()).To(Equal([]int64{12, 16, 20}))
+
+		written, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(written)).To(Equal(contents))
+	})
+
+	It("starts over when the partial file belongs to another version of the file", func() {
+		Expect(ioutil.WriteFile(path, []byte("stale contents of another file"), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(path+".state", []byte(`{"name":"product.pivotal","size":21,"sha256":"other-sha","chunk_size":4,"completed":[0,1,2]}`), 0644)).To(Succeed())
+
+		err := download_clients.DownloadInRanges(client, artifact, path, options, stderr)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.DownloadProductRangeCallCount()).To(Equal(6))
+
+		written, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(written)).To(Equal(contents))
+	})
+
+	It("errors when a chunk is shorter than requested", func() {
+		client.DownloadProductRangeStub = func(_ download_clients.FileArtifacter, start, end int64) (io.ReadCloser, error) {
+			return ioutil.NopCloser(strings.NewReader(contents[start:end])), nil
+		}
+
+		err := download_clients.DownloadInRanges(client, artifact, path, options, stderr)
+		Expect(err).To(MatchError(ContainSubstring("expected 4 bytes from offset")))
+	})
+
+	It("returns ErrRangesNotSupported when the source cannot download ranges", func() {
+		client.ProductFileSizeReturns(0, download_clients.ErrRangesNotSupported)
+
+		err := download_clients.DownloadInRanges(client, artifact, path, options, stderr)
+		Expect(err).To(Equal(download_clients.ErrRangesNotSupported))
+		Expect(path).ToNot(BeAnExistingFile())
+	})
+})
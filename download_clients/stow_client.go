@@ -8,9 +8,6 @@ import (
 	"io"
 	"log"
 	"os"
-	"path/filepath"
-	"regexp"
-	"strings"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -71,31 +68,7 @@ func (s stowClient) getAllProductVersionsFromPath(slug, path string) ([]string,
 		return nil, err
 	}
 
-	productFileCompiledRegex := regexp.MustCompile(
-		fmt.Sprintf(`^/?%s/?\[%s,(.*?)\]`,
-			regexp.QuoteMeta(strings.Trim(path, "/")),
-			slug,
-		),
-	)
-
-	var versions []string
-	versionFound := make(map[string]bool)
-	for _, fileName := range files {
-		match := productFileCompiledRegex.FindStringSubmatch(fileName)
-		if match != nil {
-			version := match[1]
-			if !versionFound[version] {
-				versions = append(versions, version)
-				versionFound[version] = true
-			}
-		}
-	}
-
-	if len(versions) == 0 {
-		return nil, fmt.Errorf("no files matching pivnet-product-slug %s found", slug)
-	}
-
-	return versions, nil
+	return versionsFromPrefixedFiles(files, path, slug)
 }
 
 func (s *stowClient) listFiles() ([]string, error) {
@@ -155,66 +128,66 @@ func (s stowClient) GetLatestProductFile(slug, version, glob string) (FileArtifa
 		return nil, err
 	}
 
-	validFile := regexp.MustCompile(
-		fmt.Sprintf(`^/?(%s|%s)/?\[%s,%s\]`,
-			regexp.QuoteMeta(strings.Trim(s.productPath, "/")),
-			regexp.QuoteMeta(strings.Trim(s.stemcellPath, "/")),
-			slug,
-			regexp.QuoteMeta(version),
-		),
-	)
-	var prefixedFilepaths []string
-	var globMatchedFilepaths []string
-
-	for _, f := range files {
-		if validFile.MatchString(f) {
-			prefixedFilepaths = append(prefixedFilepaths, f)
-		}
+	name, err := findPrefixedFile(files, s.productPath, s.stemcellPath, slug, version, glob)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(prefixedFilepaths) == 0 {
-		return nil, fmt.Errorf("no product files with expected prefix [%s,%s] found. Please ensure the file you're trying to download was initially persisted from Pivotal Network net using an appropriately configured download-product command", slug, version)
+	return &stowFileArtifact{name: name, source: s.kind}, nil
+}
+
+func (s stowClient) DownloadProductToFile(fa FileArtifacter, destinationFile *os.File) error {
+	blobReader, size, err := s.initializeBlobReader(fa.Name())
+	if err != nil {
+		return err
 	}
 
-	for _, f := range prefixedFilepaths {
-		removePrefixRegex := regexp.MustCompile(`^\[.*\]`)
-		baseFilename := removePrefixRegex.ReplaceAllString(filepath.Base(f), "")
+	progressBar, wrappedBlobReader := startProgressBar(s.stderr, size, blobReader)
+	defer progressBar.Finish()
 
-		matched, _ := filepath.Match(glob, baseFilename)
-		if matched {
-			globMatchedFilepaths = append(globMatchedFilepaths, f)
-		}
+	if err = streamBufferToFile(destinationFile, wrappedBlobReader); err != nil {
+		return err
 	}
 
-	if len(globMatchedFilepaths) > 1 {
-		return nil, fmt.Errorf("the glob '%s' matches multiple files. Write your glob to match exactly one of the following:\n  %s", glob, strings.Join(globMatchedFilepaths, "\n  "))
+	return nil
+}
+
+func (s stowClient) ProductFileSize(fa FileArtifacter) (int64, error) {
+	item, err := s.rangedItem(fa.Name())
+	if err != nil {
+		return 0, err
 	}
 
-	if len(globMatchedFilepaths) == 0 {
-		availableFiles := strings.Join(prefixedFilepaths, ", ")
-		if availableFiles == "" {
-			availableFiles = "none"
-		}
-		return nil, fmt.Errorf("the glob '%s' matches no file\navailable files: %s", glob, availableFiles)
+	return item.Size()
+}
+
+func (s stowClient) DownloadProductRange(fa FileArtifacter, start, end int64) (io.ReadCloser, error) {
+	item, err := s.rangedItem(fa.Name())
+	if err != nil {
+		return nil, err
 	}
 
-	return &stowFileArtifact{name: globMatchedFilepaths[0], source: s.kind}, nil
+	return item.(stow.ItemRanger).OpenRange(uint64(start), uint64(end))
 }
 
-func (s stowClient) DownloadProductToFile(fa FileArtifacter, destinationFile *os.File) error {
-	blobReader, size, err := s.initializeBlobReader(fa.Name())
+// rangedItem returns the item when its blobstore supports ranged downloads,
+// which gcs does not.
+func (s *stowClient) rangedItem(filename string) (stow.Item, error) {
+	container, err := s.getContainer()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	progressBar, wrappedBlobReader := s.startProgressBar(size, blobReader)
-	defer progressBar.Finish()
+	item, err := container.Item(filename)
+	if err != nil {
+		return nil, err
+	}
 
-	if err = s.streamBufferToFile(destinationFile, wrappedBlobReader); err != nil {
-		return err
+	if _, ok := item.(stow.ItemRanger); !ok {
+		return nil, ErrRangesNotSupported
 	}
 
-	return nil
+	return item, nil
 }
 
 func (s *stowClient) initializeBlobReader(filename string) (blobToRead io.ReadCloser, fileSize int64, err error) {
@@ -236,9 +209,9 @@ func (s *stowClient) initializeBlobReader(filename string) (blobToRead io.ReadCl
 	return blobToRead, fileSize, err
 }
 
-func (s stowClient) startProgressBar(size int64, item io.Reader) (*pb.ProgressBar, io.Reader) {
+func startProgressBar(stderr *log.Logger, size int64, item io.Reader) (*pb.ProgressBar, io.Reader) {
 	progressBar := pb.Default.New(0)
-	progressBar.SetWriter(s.stderr.Writer())
+	progressBar.SetWriter(stderr.Writer())
 	progressBar.Set(pb.Bytes, true)
 	progressBar.SetTotal(size)
 	progressBar.SetMaxWidth(80)
@@ -247,49 +220,15 @@ func (s stowClient) startProgressBar(size int64, item io.Reader) (*pb.ProgressBa
 	return progressBar, reader
 }
 
-func (s stowClient) streamBufferToFile(destinationFile *os.File, wrappedBlobReader io.Reader) error {
+func streamBufferToFile(destinationFile *os.File, wrappedBlobReader io.Reader) error {
 	_, err := io.Copy(destinationFile, wrappedBlobReader)
 	return err
 }
 
 func (s stowClient) GetLatestStemcellForProduct(_ FileArtifacter, downloadedProductFileName string) (StemcellArtifacter, error) {
-	definedStemcell, err := stemcellFromProduct(downloadedProductFileName)
-	if err != nil {
-		return nil, err
-	}
-
-	definedMajor, definedPatch, err := stemcellVersionPartsFromString(definedStemcell.Version())
-	if err != nil {
-		return nil, err
-	}
-
-	allStemcellVersions, err := s.getAllProductVersionsFromPath(definedStemcell.Slug(), s.stemcellPath)
-	if err != nil {
-		return nil, fmt.Errorf("could not find stemcells on %s: %s", s.kind, err)
-	}
-
-	var filteredVersions []string
-	for _, version := range allStemcellVersions {
-		major, patch, _ := stemcellVersionPartsFromString(version)
-
-		if major == definedMajor && patch >= definedPatch {
-			filteredVersions = append(filteredVersions, version)
-		}
-	}
-
-	if len(filteredVersions) == 0 {
-		return nil, fmt.Errorf("no versions could be found equal to or greater than %s", definedStemcell.Version())
-	}
-
-	latestVersion, err := getLatestStemcellVersion(filteredVersions)
-	if err != nil {
-		return nil, err
-	}
-
-	return &stemcell{
-		version: latestVersion,
-		slug:    definedStemcell.Slug(),
-	}, nil
+	return latestStemcellForProduct(downloadedProductFileName, s.kind, func(slug string) ([]string, error) {
+		return s.getAllProductVersionsFromPath(slug, s.stemcellPath)
+	})
 }
 
 func stemcellFromProduct(filename string) (*stemcell, error) {
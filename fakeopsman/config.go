@@ -0,0 +1,147 @@
+package fakeopsman
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+)
+
+// Config seeds the state of a fake Ops Manager.
+type Config struct {
+	Version      string             `json:"version"`
+	Username     string             `json:"username"`
+	Password     string             `json:"password"`
+	ClientID     string             `json:"client-id"`
+	ClientSecret string             `json:"client-secret"`
+	Products     []ProductConfig    `json:"products"`
+	Installation InstallationConfig `json:"installation"`
+}
+
+type ProductConfig struct {
+	Name                string                    `json:"name"`
+	Version             string                    `json:"version"`
+	GUID                string                    `json:"guid"`
+	Deployed            bool                      `json:"deployed"`
+	Properties          map[string]PropertyConfig `json:"properties"`
+	NetworksAndAZs      map[string]interface{}    `json:"networks-and-azs"`
+	SyslogConfiguration map[string]interface{}    `json:"syslog-configuration"`
+	Jobs                []JobConfig               `json:"jobs"`
+	Errands             []ErrandConfig            `json:"errands"`
+}
+
+type PropertyConfig struct {
+	Type           string      `json:"type"`
+	Value          interface{} `json:"value"`
+	SelectedOption string      `json:"selected-option"`
+	Configurable   bool        `json:"configurable"`
+	Credential     bool        `json:"credential"`
+	Optional       bool        `json:"optional"`
+}
+
+type JobConfig struct {
+	Name           string                 `json:"name"`
+	GUID           string                 `json:"guid"`
+	ResourceConfig map[string]interface{} `json:"resource-config"`
+	MaxInFlight    interface{}            `json:"max-in-flight"`
+}
+
+type ErrandConfig struct {
+	Name       string      `json:"name"`
+	PostDeploy interface{} `json:"post-deploy"`
+	PreDelete  interface{} `json:"pre-delete"`
+}
+
+// InstallationConfig scripts what every installation does.
+// When Logs is empty, the log is generated from the products being deployed.
+// Each entry of Logs is appended to the installation log every time the
+// installation status is polled.
+type InstallationConfig struct {
+	Status string   `json:"status"`
+	Logs   []string `json:"logs"`
+}
+
+// DefaultConfig is used when no config file is given:
+// a single staged, never deployed product with a couple of properties,
+// jobs and errands.
+func DefaultConfig() Config {
+	return Config{
+		Version:      "2.10.0-build.1",
+		Username:     "admin",
+		Password:     "password",
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Products: []ProductConfig{
+			{
+				Name:    "example-product",
+				Version: "1.0.0",
+				GUID:    "example-product-0123456789abcdef",
+				Properties: map[string]PropertyConfig{
+					".properties.example_string": {
+						Type:         "string",
+						Value:        "some-value",
+						Configurable: true,
+					},
+					".properties.example_secret": {
+						Type:         "secret",
+						Configurable: true,
+						Credential:   true,
+						Optional:     true,
+					},
+					".properties.example_selector": {
+						Type:           "selector",
+						Value:          "Option one",
+						SelectedOption: "option_one",
+						Configurable:   true,
+					},
+				},
+				NetworksAndAZs: map[string]interface{}{
+					"network":                     map[string]interface{}{"name": "example-network"},
+					"singleton_availability_zone": map[string]interface{}{"name": "az1"},
+					"other_availability_zones": []interface{}{
+						map[string]interface{}{"name": "az1"},
+					},
+				},
+				SyslogConfiguration: map[string]interface{}{
+					"enabled": false,
+				},
+				Jobs: []JobConfig{
+					{
+						Name: "web",
+						ResourceConfig: map[string]interface{}{
+							"instances":     1,
+							"instance_type": map[string]interface{}{"id": "automatic"},
+						},
+						MaxInFlight: 1,
+					},
+				},
+				Errands: []ErrandConfig{
+					{Name: "smoke_tests", PostDeploy: true},
+				},
+			},
+		},
+		Installation: InstallationConfig{
+			Status: "succeeded",
+		},
+	}
+}
+
+// LoadConfig reads a YAML (or JSON) config file.
+// Values not set in the file are taken from DefaultConfig,
+// except for the products.
+func LoadConfig(path string) (Config, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not read fake ops manager config: %w", err)
+	}
+
+	config := DefaultConfig()
+	config.Products = nil
+
+	err = yaml.Unmarshal(contents, &config)
+	if err != nil {
+		return Config{}, fmt.Errorf("could not parse fake ops manager config: %w", err)
+	}
+
+	return config, nil
+}
@@ -0,0 +1,51 @@
+package fakeopsman
+
+import "strings"
+
+// lineDiff renders the difference between two documents in the
+// style of `bosh diff`, or returns an empty string if they are the same.
+func lineDiff(from, to string) string {
+	if from == to {
+		return ""
+	}
+
+	a := strings.Split(strings.TrimSuffix(from, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(to, "\n"), "\n")
+
+	// lengths of the longest common subsequences of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var diff strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			diff.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			diff.WriteString("- " + a[i] + "\n")
+			i++
+		default:
+			diff.WriteString("+ " + b[j] + "\n")
+			j++
+		}
+	}
+
+	return diff.String()
+}
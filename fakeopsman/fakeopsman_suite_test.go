@@ -0,0 +1,13 @@
+package fakeopsman_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestFakeopsman(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Fakeopsman Suite")
+}
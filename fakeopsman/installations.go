@@ -0,0 +1,220 @@
+package fakeopsman
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const boshCommandPrefix = "/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5"
+
+type installation struct {
+	ID         int        `json:"id"`
+	Status     string     `json:"status"`
+	UserName   string     `json:"user_name"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+
+	logs     string
+	pending  []string
+	products []*product
+}
+
+type productErrands struct {
+	RunPostDeploy map[string]interface{} `json:"run_post_deploy"`
+	RunPreDelete  map[string]interface{} `json:"run_pre_delete"`
+}
+
+func (s *Server) installationsEndpoint(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		// like Ops Manager, the most recent installation comes first
+		installations := []*installation{}
+		for i := len(s.installations) - 1; i >= 0; i-- {
+			installations = append(installations, s.installations[i])
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"installations": installations})
+	case http.MethodPost:
+		s.createInstallation(w, r)
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) createInstallation(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		DeployProducts interface{}               `json:"deploy_products"`
+		Errands        map[string]productErrands `json:"errands"`
+	}
+	if !decodeBody(w, r, &body) {
+		return
+	}
+
+	if len(s.installations) > 0 && s.installations[len(s.installations)-1].Status == "running" {
+		writeErrors(w, http.StatusConflict, "an installation is already running")
+		return
+	}
+
+	var products []*product
+	switch deployProducts := body.DeployProducts.(type) {
+	case nil, string:
+		if deployProducts != "none" {
+			for _, p := range s.products {
+				if p.action() != "unchanged" {
+					products = append(products, p)
+				}
+			}
+		}
+	case []interface{}:
+		for _, guid := range deployProducts {
+			p := s.findProductByGUID(fmt.Sprintf("%v", guid))
+			if p == nil {
+				writeErrors(w, http.StatusUnprocessableEntity, fmt.Sprintf("%v is not a staged product", guid))
+				return
+			}
+
+			products = append(products, p)
+		}
+	}
+
+	for _, p := range products {
+		if !p.configurationComplete() {
+			writeErrors(w, http.StatusUnprocessableEntity, fmt.Sprintf("%s configuration is incomplete", p.name))
+			return
+		}
+	}
+
+	startedAt := s.now().UTC()
+	install := &installation{
+		ID:        len(s.installations) + 1,
+		Status:    "running",
+		UserName:  s.config.Username,
+		StartedAt: &startedAt,
+		pending:   s.config.Installation.Logs,
+		products:  products,
+	}
+
+	if len(install.pending) == 0 {
+		install.pending = s.generateLogs(products, body.Errands)
+	}
+
+	s.installations = append(s.installations, install)
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"install": map[string]int{"id": install.ID},
+	})
+}
+
+func (s *Server) installation(w http.ResponseWriter, r *http.Request, id string, path []string) {
+	install := s.findInstallation(id)
+	if install == nil {
+		notFound(w, r)
+		return
+	}
+
+	switch {
+	case len(path) == 0 && r.Method == http.MethodGet:
+		// every poll of the status moves the installation along its script
+		s.advance(install)
+		writeJSON(w, http.StatusOK, install)
+	case match(path, "logs") && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]string{"logs": install.logs})
+	case match(path, "cancel") && r.Method == http.MethodPost:
+		if install.Status != "running" {
+			writeErrors(w, http.StatusUnprocessableEntity, fmt.Sprintf("installation %d is not running", install.ID))
+			return
+		}
+
+		install.logs += s.logLine("Installation cancelled by %s", s.config.Username)
+		s.finish(install, "failed")
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) findInstallation(id string) *installation {
+	n, err := strconv.Atoi(id)
+	if err != nil || n < 1 || n > len(s.installations) {
+		return nil
+	}
+
+	return s.installations[n-1]
+}
+
+func (s *Server) advance(install *installation) {
+	if install.Status != "running" {
+		return
+	}
+
+	if len(install.pending) > 0 {
+		install.logs += install.pending[0]
+		install.pending = install.pending[1:]
+		return
+	}
+
+	s.finish(install, s.config.Installation.Status)
+}
+
+func (s *Server) finish(install *installation, status string) {
+	finishedAt := s.now().UTC()
+	install.FinishedAt = &finishedAt
+	install.Status = status
+	install.pending = nil
+
+	if status != "succeeded" {
+		return
+	}
+
+	for _, p := range install.products {
+		p.deploy()
+	}
+}
+
+// generateLogs scripts an installation log that looks like the one of
+// Ops Manager: a bosh deploy for every product, followed by the errands
+// that are enabled. When the installation is configured to fail,
+// the last command fails.
+func (s *Server) generateLogs(products []*product, errands map[string]productErrands) []string {
+	var commands []string
+	for _, p := range products {
+		commands = append(commands, fmt.Sprintf("%s --deployment=%s deploy /var/tempest/workspaces/default/deployments/%s.yml", boshCommandPrefix, p.guid, p.guid))
+	}
+
+	for _, p := range products {
+		for _, e := range p.errands {
+			enabled := e.PostDeploy
+			if override, ok := errands[p.guid].RunPostDeploy[e.Name]; ok {
+				enabled = override
+			}
+
+			if enabled == nil || enabled == false || enabled == "false" {
+				continue
+			}
+
+			commands = append(commands, fmt.Sprintf("%s --deployment=%s run-errand %s", boshCommandPrefix, p.guid, e.Name))
+		}
+	}
+
+	var chunks []string
+	for i, command := range commands {
+		exitStatus := 0
+		if i == len(commands)-1 && s.config.Installation.Status == "failed" {
+			exitStatus = 1
+		}
+
+		chunks = append(chunks,
+			s.logLine("Running %q", command)+"Task completed\n",
+			s.logLine("Finished %q; Duration: 1s; Exit Status: %d", command, exitStatus),
+		)
+	}
+
+	return chunks
+}
+
+func (s *Server) logLine(format string, v ...interface{}) string {
+	return fmt.Sprintf("===== %s %s\n", s.now().UTC().Format("2006-01-02 15:04:05 MST"), fmt.Sprintf(format, v...))
+}
@@ -0,0 +1,711 @@
+package fakeopsman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+type product struct {
+	name    string
+	version string
+	guid    string
+
+	properties          map[string]*property
+	networksAndAZs      map[string]interface{}
+	syslogConfiguration map[string]interface{}
+	jobs                []*job
+	errands             []*errand
+
+	// deployedManifest is a snapshot of the manifest taken by the last
+	// successful installation. It is nil if the product was never deployed.
+	deployedManifest map[string]interface{}
+}
+
+type property struct {
+	Type           string      `json:"type"`
+	Value          interface{} `json:"value"`
+	SelectedOption string      `json:"selected_option,omitempty"`
+	Configurable   bool        `json:"configurable"`
+	Credential     bool        `json:"credential"`
+	Optional       bool        `json:"optional"`
+}
+
+type job struct {
+	name           string
+	guid           string
+	resourceConfig map[string]interface{}
+	maxInFlight    interface{}
+}
+
+type errand struct {
+	Name       string      `json:"name"`
+	PostDeploy interface{} `json:"post_deploy,omitempty"`
+	PreDelete  interface{} `json:"pre_delete,omitempty"`
+}
+
+func newProduct(config ProductConfig) *product {
+	p := &product{
+		name:                config.Name,
+		version:             config.Version,
+		guid:                config.GUID,
+		properties:          map[string]*property{},
+		networksAndAZs:      config.NetworksAndAZs,
+		syslogConfiguration: config.SyslogConfiguration,
+	}
+
+	if p.guid == "" {
+		p.guid = fmt.Sprintf("%s-%s", p.name, randomHex(10))
+	}
+
+	for name, propertyConfig := range config.Properties {
+		p.properties[name] = &property{
+			Type:           propertyConfig.Type,
+			Value:          propertyConfig.Value,
+			SelectedOption: propertyConfig.SelectedOption,
+			Configurable:   propertyConfig.Configurable,
+			Credential:     propertyConfig.Credential,
+			Optional:       propertyConfig.Optional,
+		}
+	}
+
+	for _, jobConfig := range config.Jobs {
+		j := &job{
+			name:           jobConfig.Name,
+			guid:           jobConfig.GUID,
+			resourceConfig: jobConfig.ResourceConfig,
+			maxInFlight:    jobConfig.MaxInFlight,
+		}
+
+		if j.guid == "" {
+			j.guid = fmt.Sprintf("%s-%s", j.name, randomHex(10))
+		}
+
+		if j.resourceConfig == nil {
+			j.resourceConfig = map[string]interface{}{}
+		}
+
+		if j.maxInFlight == nil {
+			j.maxInFlight = "default"
+		}
+
+		p.jobs = append(p.jobs, j)
+	}
+
+	for _, errandConfig := range config.Errands {
+		p.errands = append(p.errands, &errand{
+			Name:       errandConfig.Name,
+			PostDeploy: errandConfig.PostDeploy,
+			PreDelete:  errandConfig.PreDelete,
+		})
+	}
+
+	if config.Deployed {
+		p.deploy()
+	}
+
+	return p
+}
+
+// manifest is a stand-in for the bosh manifest Ops Manager would generate:
+// it contains everything that has been configured, so that any change
+// shows up in the pending changes and the diff.
+func (p *product) manifest() map[string]interface{} {
+	properties := map[string]interface{}{}
+	for name, property := range p.properties {
+		properties[name] = property.Value
+	}
+
+	var instanceGroups []interface{}
+	for _, j := range p.jobs {
+		instanceGroups = append(instanceGroups, map[string]interface{}{
+			"name":            j.name,
+			"resource_config": j.resourceConfig,
+			"max_in_flight":   j.maxInFlight,
+		})
+	}
+
+	manifest := map[string]interface{}{
+		"name":             p.guid,
+		"product_version":  p.version,
+		"properties":       properties,
+		"instance_groups":  instanceGroups,
+		"networks_and_azs": p.networksAndAZs,
+	}
+
+	if p.syslogConfiguration != nil {
+		manifest["syslog_configuration"] = p.syslogConfiguration
+	}
+
+	// round trip through JSON, so that the result shares no maps
+	// with the staged configuration and can be kept as a snapshot
+	contents, _ := json.Marshal(manifest)
+
+	var snapshot map[string]interface{}
+	_ = json.Unmarshal(contents, &snapshot)
+
+	return snapshot
+}
+
+func (p *product) deploy() {
+	p.deployedManifest = p.manifest()
+}
+
+func (p *product) deployed() bool {
+	return p.deployedManifest != nil
+}
+
+func (p *product) action() string {
+	switch {
+	case !p.deployed():
+		return "install"
+	case renderManifest(p.manifest()) != renderManifest(p.deployedManifest):
+		return "update"
+	default:
+		return "unchanged"
+	}
+}
+
+func (p *product) configurationComplete() bool {
+	for _, property := range p.properties {
+		if property.Configurable && !property.Optional && (property.Value == nil || property.Value == "") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *product) findJob(guid string) *job {
+	for _, j := range p.jobs {
+		if j.guid == guid {
+			return j
+		}
+	}
+
+	return nil
+}
+
+func (p *product) findErrand(name string) *errand {
+	for _, e := range p.errands {
+		if e.Name == name {
+			return e
+		}
+	}
+
+	return nil
+}
+
+func renderManifest(manifest map[string]interface{}) string {
+	contents, _ := yaml.Marshal(manifest)
+	return string(contents)
+}
+
+func (s *Server) findProductByName(name string) *product {
+	for _, p := range s.products {
+		if p.name == name {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) findProductByGUID(guid string) *product {
+	for _, p := range s.products {
+		if p.guid == guid {
+			return p
+		}
+	}
+
+	return nil
+}
+
+func productSummary(p *product) map[string]string {
+	return map[string]string{
+		"installation_name": p.guid,
+		"guid":              p.guid,
+		"type":              p.name,
+		"product_version":   p.version,
+	}
+}
+
+func (s *Server) listStagedProducts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	products := []map[string]string{}
+	for _, p := range s.products {
+		products = append(products, productSummary(p))
+	}
+
+	writeJSON(w, http.StatusOK, products)
+}
+
+func (s *Server) listDeployedProducts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	products := []map[string]string{}
+	for _, p := range s.products {
+		if p.deployed() {
+			products = append(products, productSummary(p))
+		}
+	}
+
+	writeJSON(w, http.StatusOK, products)
+}
+
+func (s *Server) diagnosticReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	staged := []map[string]interface{}{}
+	deployed := []map[string]interface{}{}
+	for _, p := range s.products {
+		staged = append(staged, map[string]interface{}{"name": p.name, "version": p.version})
+		if p.deployed() {
+			deployed = append(deployed, map[string]interface{}{"name": p.name, "version": p.deployedManifest["product_version"]})
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"infrastructure_type": "fake",
+		"added_products": map[string]interface{}{
+			"staged":   staged,
+			"deployed": deployed,
+		},
+	})
+}
+
+func (s *Server) pendingChanges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	changes := []interface{}{}
+	for _, p := range s.products {
+		errands := p.errands
+		if errands == nil {
+			errands = []*errand{}
+		}
+
+		changes = append(changes, map[string]interface{}{
+			"guid":    p.guid,
+			"action":  p.action(),
+			"errands": errands,
+			"completeness_checks": map[string]bool{
+				"configuration_complete":        p.configurationComplete(),
+				"stemcell_present":              true,
+				"configurable_properties_valid": true,
+			},
+		})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"product_changes": changes})
+}
+
+func (s *Server) productDiff(w http.ResponseWriter, r *http.Request, guid string) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	p := s.findProductByGUID(guid)
+	if p == nil {
+		notFound(w, r)
+		return
+	}
+
+	manifest := map[string]string{"status": "to_be_installed", "diff": ""}
+	if p.deployed() {
+		diff := lineDiff(renderManifest(p.deployedManifest), renderManifest(p.manifest()))
+
+		manifest["status"] = "same"
+		if diff != "" {
+			manifest["status"] = "different"
+			manifest["diff"] = diff
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"manifest":        manifest,
+		"runtime_configs": []interface{}{},
+	})
+}
+
+func (s *Server) stagedProduct(w http.ResponseWriter, r *http.Request, guid string, path []string) {
+	p := s.findProductByGUID(guid)
+	if p == nil {
+		notFound(w, r)
+		return
+	}
+
+	switch {
+	case match(path, "properties"):
+		s.properties(w, r, p)
+	case match(path, "networks_and_azs"):
+		getOrPut(w, r, "networks_and_azs", &p.networksAndAZs)
+	case match(path, "syslog_configuration"):
+		getOrPut(w, r, "syslog_configuration", &p.syslogConfiguration)
+	case match(path, "jobs"):
+		s.jobs(w, r, p)
+	case match(path, "jobs", "*", "resource_config"):
+		s.resourceConfig(w, r, p, path[1])
+	case match(path, "max_in_flight"):
+		s.maxInFlight(w, r, p)
+	case match(path, "errands"):
+		s.errands(w, r, p)
+	case match(path, "manifest"):
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"manifest": p.manifest()})
+	default:
+		notFound(w, r)
+	}
+}
+
+func (s *Server) deployedProduct(w http.ResponseWriter, r *http.Request, guid string, path []string) {
+	p := s.findProductByGUID(guid)
+	if p == nil || !p.deployed() {
+		notFound(w, r)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	switch {
+	case match(path, "manifest"):
+		writeJSON(w, http.StatusOK, p.deployedManifest)
+	case match(path, "credentials", "*") && len(path) == 2:
+		property, ok := p.properties[path[1]]
+		if !ok || !property.Credential {
+			notFound(w, r)
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"credential": map[string]interface{}{
+				"type":  property.Type,
+				"value": credentialValue(property.Value),
+			},
+		})
+	default:
+		notFound(w, r)
+	}
+}
+
+// getOrPut serves a resource that is read and replaced as a whole,
+// wrapped in a single top level key.
+func getOrPut(w http.ResponseWriter, r *http.Request, key string, value *map[string]interface{}) {
+	switch r.Method {
+	case http.MethodGet:
+		if *value == nil {
+			writeErrors(w, http.StatusNotFound, fmt.Sprintf("the product does not have %s", key))
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{key: *value})
+	case http.MethodPut:
+		var body map[string]map[string]interface{}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+
+		*value = body[key]
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) properties(w http.ResponseWriter, r *http.Request, p *product) {
+	switch r.Method {
+	case http.MethodGet:
+		redact := r.URL.Query().Get("redact") != "false"
+
+		properties := map[string]property{}
+		for name, prop := range p.properties {
+			properties[name] = *prop
+			if redact && prop.Credential && prop.Value != nil {
+				properties[name] = redacted(*prop)
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"properties": properties})
+	case http.MethodPut:
+		var body struct {
+			Properties map[string]struct {
+				Value          interface{} `json:"value"`
+				SelectedOption string      `json:"selected_option"`
+			} `json:"properties"`
+		}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+
+		var errors []string
+		for name := range body.Properties {
+			prop, ok := p.properties[name]
+			switch {
+			case !ok:
+				errors = append(errors, fmt.Sprintf("%s is not a property of %s", name, p.name))
+			case !prop.Configurable:
+				errors = append(errors, fmt.Sprintf("%s is not configurable", name))
+			}
+		}
+
+		if len(errors) > 0 {
+			sort.Strings(errors)
+			writeErrors(w, http.StatusUnprocessableEntity, errors...)
+			return
+		}
+
+		for name, update := range body.Properties {
+			prop := p.properties[name]
+			prop.Value = update.Value
+
+			switch prop.Type {
+			case "selector":
+				prop.SelectedOption = update.SelectedOption
+				if prop.SelectedOption == "" {
+					prop.SelectedOption = fmt.Sprintf("%v", update.Value)
+				}
+			case "collection":
+				prop.Value = collectionValue(update.Value)
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) jobs(w http.ResponseWriter, r *http.Request, p *product) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	jobs := []map[string]string{}
+	for _, j := range p.jobs {
+		jobs = append(jobs, map[string]string{"guid": j.guid, "name": j.name})
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": jobs})
+}
+
+func (s *Server) resourceConfig(w http.ResponseWriter, r *http.Request, p *product, jobGUID string) {
+	j := p.findJob(jobGUID)
+	if j == nil {
+		notFound(w, r)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, j.resourceConfig)
+	case http.MethodPut:
+		var body map[string]interface{}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+
+		// om sends max_in_flight along with the resource config,
+		// but Ops Manager keeps it separately
+		delete(body, "max_in_flight")
+
+		j.resourceConfig = body
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) maxInFlight(w http.ResponseWriter, r *http.Request, p *product) {
+	switch r.Method {
+	case http.MethodGet:
+		maxInFlight := map[string]interface{}{}
+		for _, j := range p.jobs {
+			maxInFlight[j.guid] = j.maxInFlight
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"max_in_flight": maxInFlight})
+	case http.MethodPut:
+		var body struct {
+			MaxInFlight map[string]interface{} `json:"max_in_flight"`
+		}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+
+		for guid := range body.MaxInFlight {
+			if p.findJob(guid) == nil {
+				writeErrors(w, http.StatusUnprocessableEntity, fmt.Sprintf("%s is not a job of %s", guid, p.name))
+				return
+			}
+		}
+
+		for guid, value := range body.MaxInFlight {
+			p.findJob(guid).maxInFlight = value
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func (s *Server) errands(w http.ResponseWriter, r *http.Request, p *product) {
+	switch r.Method {
+	case http.MethodGet:
+		errands := p.errands
+		if errands == nil {
+			errands = []*errand{}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{"errands": errands})
+	case http.MethodPut:
+		var body struct {
+			Errands []errand `json:"errands"`
+		}
+		if !decodeBody(w, r, &body) {
+			return
+		}
+
+		for _, update := range body.Errands {
+			if p.findErrand(update.Name) == nil {
+				writeErrors(w, http.StatusUnprocessableEntity, fmt.Sprintf("%s is not an errand of %s", update.Name, p.name))
+				return
+			}
+		}
+
+		for _, update := range body.Errands {
+			e := p.findErrand(update.Name)
+			if update.PostDeploy != nil {
+				e.PostDeploy = update.PostDeploy
+			}
+			if update.PreDelete != nil {
+				e.PreDelete = update.PreDelete
+			}
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{})
+	default:
+		methodNotAllowed(w, r)
+	}
+}
+
+func redacted(prop property) property {
+	if fields, ok := prop.Value.(map[string]interface{}); ok {
+		masked := map[string]interface{}{}
+		for key := range fields {
+			masked[key] = "***"
+		}
+		prop.Value = masked
+		return prop
+	}
+
+	prop.Value = "***"
+	return prop
+}
+
+func credentialValue(value interface{}) map[string]string {
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return map[string]string{"value": fmt.Sprintf("%v", value)}
+	}
+
+	credential := map[string]string{}
+	for key, field := range fields {
+		credential[key] = fmt.Sprintf("%v", field)
+	}
+
+	return credential
+}
+
+// collectionValue turns the plain values om sends for each field of
+// a collection item into the typed form Ops Manager returns,
+// assigning a guid to new items.
+func collectionValue(value interface{}) interface{} {
+	items, ok := value.([]interface{})
+	if !ok {
+		return value
+	}
+
+	var collection []interface{}
+	for _, item := range items {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			collection = append(collection, item)
+			continue
+		}
+
+		if _, ok := fields["guid"]; !ok {
+			fields["guid"] = randomHex(10)
+		}
+
+		typed := map[string]interface{}{}
+		for name, field := range fields {
+			if existing, ok := field.(map[string]interface{}); ok {
+				if _, ok := existing["value"]; ok {
+					typed[name] = existing
+					continue
+				}
+			}
+
+			typed[name] = map[string]interface{}{
+				"type":         fieldType(name, field),
+				"value":        field,
+				"configurable": name != "guid",
+				"credential":   false,
+			}
+		}
+
+		collection = append(collection, typed)
+	}
+
+	return collection
+}
+
+func fieldType(name string, value interface{}) string {
+	if name == "guid" {
+		return "uuid"
+	}
+
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case float64:
+		return "integer"
+	default:
+		return "string"
+	}
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, body interface{}) bool {
+	err := json.NewDecoder(r.Body).Decode(body)
+	if err != nil {
+		writeErrors(w, http.StatusBadRequest, fmt.Sprintf("could not parse request body: %s", err))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,255 @@
+// Package fakeopsman implements an in-memory, stateful stand-in for the
+// parts of the Ops Manager API that om uses to configure and deploy products.
+//
+// It is meant for rehearsing pipelines (configure-product, apply-changes,
+// staged-config, ...) against localhost, not for testing Ops Manager itself:
+// nothing is validated beyond what om relies on.
+package fakeopsman
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const tokenLifetime = time.Hour
+
+type Server struct {
+	mu sync.Mutex
+
+	config        Config
+	products      []*product
+	installations []*installation
+	accessTokens  map[string]time.Time
+	refreshTokens map[string]bool
+
+	now func() time.Time
+}
+
+func New(config Config) (*Server, error) {
+	s := &Server{
+		config:        config,
+		accessTokens:  map[string]time.Time{},
+		refreshTokens: map[string]bool{},
+		now:           time.Now,
+	}
+
+	if s.config.Installation.Status == "" {
+		s.config.Installation.Status = "succeeded"
+	}
+
+	if s.config.Installation.Status != "succeeded" && s.config.Installation.Status != "failed" {
+		return nil, fmt.Errorf("installation status must be 'succeeded' or 'failed', got %q", s.config.Installation.Status)
+	}
+
+	for _, productConfig := range config.Products {
+		if productConfig.Name == "" {
+			return nil, fmt.Errorf("every product requires a name")
+		}
+
+		if s.findProductByName(productConfig.Name) != nil {
+			return nil, fmt.Errorf("product %q is configured more than once", productConfig.Name)
+		}
+
+		s.products = append(s.products, newProduct(productConfig))
+	}
+
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/uaa/oauth/token":
+		s.token(w, r)
+	case r.URL.Path == "/api/v0/info":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"info": map[string]string{"version": s.config.Version},
+		})
+	case strings.HasPrefix(r.URL.Path, "/api/v0/"):
+		if !s.authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error":             "invalid_token",
+				"error_description": "missing, invalid or expired access token",
+			})
+			return
+		}
+
+		s.api(w, r, strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v0/"), "/"), "/"))
+	default:
+		notFound(w, r)
+	}
+}
+
+func (s *Server) api(w http.ResponseWriter, r *http.Request, path []string) {
+	switch {
+	case match(path, "staged", "products"):
+		s.listStagedProducts(w, r)
+	case match(path, "staged", "pending_changes"):
+		s.pendingChanges(w, r)
+	case match(path, "staged", "director", "properties"):
+		s.directorProperties(w, r)
+	case match(path, "staged", "products", "*") && len(path) > 3:
+		s.stagedProduct(w, r, path[2], path[3:])
+	case match(path, "deployed", "products"):
+		s.listDeployedProducts(w, r)
+	case match(path, "deployed", "products", "*") && len(path) > 3:
+		s.deployedProduct(w, r, path[2], path[3:])
+	case match(path, "diagnostic_report"):
+		s.diagnosticReport(w, r)
+	case match(path, "director", "diff"):
+		s.directorDiff(w, r)
+	case match(path, "products", "*", "diff"):
+		s.productDiff(w, r, path[1])
+	case match(path, "installations"):
+		s.installationsEndpoint(w, r)
+	case match(path, "installations", "*") && len(path) <= 3:
+		s.installation(w, r, path[1], path[2:])
+	default:
+		notFound(w, r)
+	}
+}
+
+// match reports whether the leading segments of path are pattern,
+// where "*" matches any single segment.
+// Unless the pattern ends in "*", the lengths have to be equal.
+func match(path []string, pattern ...string) bool {
+	if len(path) < len(pattern) {
+		return false
+	}
+
+	if pattern[len(pattern)-1] != "*" && len(path) != len(pattern) {
+		return false
+	}
+
+	for i, segment := range pattern {
+		if segment != "*" && segment != path[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	err := r.ParseForm()
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID = r.PostForm.Get("client_id")
+		clientSecret = r.PostForm.Get("client_secret")
+	}
+
+	var authenticated bool
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		authenticated = clientID == "opsman" &&
+			r.PostForm.Get("username") == s.config.Username &&
+			r.PostForm.Get("password") == s.config.Password
+	case "client_credentials":
+		authenticated = clientID == s.config.ClientID && clientSecret == s.config.ClientSecret
+	case "refresh_token":
+		authenticated = s.refreshTokens[r.PostForm.Get("refresh_token")]
+	}
+
+	if !authenticated {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{
+			"error":             "unauthorized",
+			"error_description": "Bad credentials",
+		})
+		return
+	}
+
+	accessToken := randomHex(16)
+	refreshToken := randomHex(16)
+	s.accessTokens[accessToken] = s.now().Add(tokenLifetime)
+	s.refreshTokens[refreshToken] = true
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token":  accessToken,
+		"refresh_token": refreshToken,
+		"token_type":    "bearer",
+		"expires_in":    int(tokenLifetime.Seconds()),
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	expiry, ok := s.accessTokens[token]
+	return ok && s.now().Before(expiry)
+}
+
+// RevokeTokens invalidates every access and refresh token issued so far,
+// as happens when Ops Manager is restarted.
+func (s *Server) RevokeTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accessTokens = map[string]time.Time{}
+	s.refreshTokens = map[string]bool{}
+}
+
+func (s *Server) directorProperties(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	// the fake director has no configuration, so there is nothing to update
+	writeJSON(w, http.StatusOK, map[string]interface{}{})
+}
+
+func (s *Server) directorDiff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"manifest":        map[string]string{"status": "same", "diff": ""},
+		"cloud_config":    map[string]string{"status": "same", "diff": ""},
+		"runtime_configs": []interface{}{},
+		"cpi_configs":     []interface{}{},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeErrors(w http.ResponseWriter, status int, errors ...string) {
+	writeJSON(w, status, map[string][]string{"errors": errors})
+}
+
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeErrors(w, http.StatusNotFound, fmt.Sprintf("%s %s is not supported by the fake ops manager", r.Method, r.URL.Path))
+}
+
+func methodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeErrors(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed for %s", r.Method, r.URL.Path))
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,320 @@
+package fakeopsman_test
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/fakeopsman"
+	"github.com/pivotal-cf/om/network"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Server", func() {
+	var (
+		config  fakeopsman.Config
+		fake    *fakeopsman.Server
+		server  *httptest.Server
+		service api.Api
+	)
+
+	newService := func(username, password, clientID, clientSecret string) api.Api {
+		authedClient, err := network.NewOAuthClient(server.URL, username, password, clientID, clientSecret, false, "", "", "", time.Second, 5*time.Second, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		unauthedClient, err := network.NewUnauthenticatedClient(server.URL, false, "", "", "", time.Second, 5*time.Second, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		return api.New(api.ApiInput{
+			Client:         authedClient,
+			UnauthedClient: unauthedClient,
+			Logger:         log.New(gbytes.NewBuffer(), "", 0),
+		})
+	}
+
+	waitForInstallation := func(id int) api.InstallationsServiceOutput {
+		for i := 0; i < 100; i++ {
+			installation, err := service.GetInstallation(id)
+			Expect(err).ToNot(HaveOccurred())
+
+			if installation.Status != api.StatusRunning {
+				return installation
+			}
+		}
+
+		Fail("installation never finished")
+		return api.InstallationsServiceOutput{}
+	}
+
+	BeforeEach(func() {
+		config = fakeopsman.DefaultConfig()
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		fake, err = fakeopsman.New(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		server = httptest.NewServer(fake)
+		service = newService("admin", "password", "", "")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("serves the info endpoint", func() {
+		info, err := service.Info()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Version).To(Equal("2.10.0-build.1"))
+	})
+
+	Describe("authentication", func() {
+		It("accepts client credentials", func() {
+			service = newService("", "", "client", "secret")
+
+			_, err := service.ListStagedProducts()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("rejects bad credentials", func() {
+			service = newService("admin", "wrong", "", "")
+
+			_, err := service.ListStagedProducts()
+			Expect(err).To(MatchError(ContainSubstring("Bad credentials")))
+		})
+
+		It("rejects requests without a valid token", func() {
+			resp, err := http.Get(server.URL + "/api/v0/staged/products")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusUnauthorized))
+		})
+
+		It("rejects tokens that have been revoked", func() {
+			resp, err := http.PostForm(server.URL+"/uaa/oauth/token", url.Values{
+				"grant_type":    {"client_credentials"},
+				"client_id":     {"client"},
+				"client_secret": {"secret"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			var token struct {
+				AccessToken string `json:"access_token"`
+			}
+			Expect(json.NewDecoder(resp.Body).Decode(&token)).To(Succeed())
+
+			get := func() int {
+				req, err := http.NewRequest("GET", server.URL+"/api/v0/staged/products", nil)
+				Expect(err).ToNot(HaveOccurred())
+				req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+				resp, err := http.DefaultClient.Do(req)
+				Expect(err).ToNot(HaveOccurred())
+				return resp.StatusCode
+			}
+
+			Expect(get()).To(Equal(http.StatusOK))
+
+			fake.RevokeTokens()
+
+			Expect(get()).To(Equal(http.StatusUnauthorized))
+		})
+	})
+
+	Describe("staged products", func() {
+		It("configures and reads back a product", func() {
+			product, err := service.GetStagedProductByName("example-product")
+			Expect(err).ToNot(HaveOccurred())
+			guid := product.Product.GUID
+
+			err = service.UpdateStagedProductProperties(api.UpdateStagedProductPropertiesInput{
+				GUID:       guid,
+				Properties: `{".properties.example_string": {"value": "new-value"}, ".properties.example_secret": {"value": {"secret": "s3cr3t"}}}`,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			properties, err := service.GetStagedProductProperties(guid, true)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(properties[".properties.example_string"].Value).To(Equal("new-value"))
+			Expect(properties[".properties.example_secret"].Value).To(Equal(map[interface{}]interface{}{"secret": "***"}))
+
+			properties, err = service.GetStagedProductProperties(guid, false)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(properties[".properties.example_secret"].Value).To(Equal(map[interface{}]interface{}{"secret": "s3cr3t"}))
+
+			err = service.ConfigureJobResourceConfig(guid, map[string]interface{}{
+				"web": map[string]interface{}{"instances": 3},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			jobs, err := service.ListStagedProductJobs(guid)
+			Expect(err).ToNot(HaveOccurred())
+
+			resourceConfig, err := service.GetStagedProductJobResourceConfig(guid, jobs["web"])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(resourceConfig["instances"]).To(BeNumerically("==", 3))
+			Expect(resourceConfig["instance_type"]).To(Equal(map[string]interface{}{"id": "automatic"}))
+
+			err = service.UpdateStagedProductJobMaxInFlight(guid, map[string]interface{}{jobs["web"]: "20%"})
+			Expect(err).ToNot(HaveOccurred())
+
+			maxInFlight, err := service.GetStagedProductJobMaxInFlight(guid)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(maxInFlight).To(Equal(map[string]interface{}{jobs["web"]: "20%"}))
+
+			err = service.UpdateStagedProductErrands(guid, "smoke_tests", false, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			errands, err := service.ListStagedProductErrands(guid)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(errands.Errands).To(Equal([]api.Errand{{Name: "smoke_tests", PostDeploy: false}}))
+		})
+
+		It("rejects unknown properties", func() {
+			err := service.UpdateStagedProductProperties(api.UpdateStagedProductPropertiesInput{
+				GUID:       "example-product-0123456789abcdef",
+				Properties: `{".properties.does_not_exist": {"value": "new-value"}}`,
+			})
+			Expect(err).To(MatchError(ContainSubstring(".properties.does_not_exist is not a property of example-product")))
+		})
+	})
+
+	Describe("installations", func() {
+		It("deploys the staged products", func() {
+			changes, err := service.ListStagedPendingChanges()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes.ChangeList).To(HaveLen(1))
+			Expect(changes.ChangeList[0].Action).To(Equal("install"))
+			Expect(changes.ChangeList[0].CompletenessChecks.ConfigurationComplete).To(BeTrue())
+
+			diff, err := service.ProductDiff("example-product")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.Manifest.Status).To(Equal("to_be_installed"))
+
+			installation, err := service.CreateInstallation(false, true, nil, api.ApplyErrandChanges{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(installation.ID).To(Equal(1))
+
+			running, err := service.RunningInstallation()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(running.ID).To(Equal(1))
+
+			Expect(waitForInstallation(1).Status).To(Equal(api.StatusSucceeded))
+
+			logs, err := service.GetInstallationLogs(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logs.Logs).To(MatchRegexp(`===== .* Running ".*--deployment=example-product-0123456789abcdef deploy .*"`))
+			Expect(logs.Logs).To(MatchRegexp(`===== .* Finished ".*run-errand smoke_tests"; Duration: 1s; Exit Status: 0`))
+
+			deployed, err := service.ListDeployedProducts()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deployed).To(Equal([]api.DeployedProductOutput{{Type: "example-product", GUID: "example-product-0123456789abcdef"}}))
+
+			changes, err = service.ListStagedPendingChanges()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes.ChangeList[0].Action).To(Equal("unchanged"))
+
+			err = service.UpdateStagedProductProperties(api.UpdateStagedProductPropertiesInput{
+				GUID:       "example-product-0123456789abcdef",
+				Properties: `{".properties.example_string": {"value": "new-value"}}`,
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			changes, err = service.ListStagedPendingChanges()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changes.ChangeList[0].Action).To(Equal("update"))
+
+			diff, err = service.ProductDiff("example-product")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.Manifest.Status).To(Equal("different"))
+			Expect(diff.Manifest.Diff).To(ContainSubstring("-   .properties.example_string: some-value\n+   .properties.example_string: new-value\n"))
+		})
+
+		It("skips errands that are disabled for the installation", func() {
+			installation, err := service.CreateInstallation(false, true, nil, api.ApplyErrandChanges{
+				Errands: map[string]api.ProductErrand{
+					"example-product": {RunPostDeploy: map[string]interface{}{"smoke_tests": false}},
+				},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			waitForInstallation(installation.ID)
+
+			logs, err := service.GetInstallationLogs(installation.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logs.Logs).ToNot(ContainSubstring("run-errand"))
+		})
+
+		It("can be cancelled", func() {
+			installation, err := service.CreateInstallation(false, true, nil, api.ApplyErrandChanges{})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = service.CancelInstallation(installation.ID)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(waitForInstallation(installation.ID).Status).To(Equal(api.StatusFailed))
+
+			deployed, err := service.ListDeployedProducts()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deployed).To(BeEmpty())
+		})
+
+		It("does not start a second installation while one is running", func() {
+			_, err := service.CreateInstallation(false, true, nil, api.ApplyErrandChanges{})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = service.CreateInstallation(false, true, nil, api.ApplyErrandChanges{})
+			Expect(err).To(MatchError(ContainSubstring("an installation is already running")))
+		})
+
+		When("the installation is scripted to fail", func() {
+			BeforeEach(func() {
+				config.Installation = fakeopsman.InstallationConfig{
+					Status: "failed",
+					Logs:   []string{"first chunk\n", "second chunk\n"},
+				}
+			})
+
+			It("replays the scripted logs and fails", func() {
+				installation, err := service.CreateInstallation(false, true, nil, api.ApplyErrandChanges{})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(waitForInstallation(installation.ID).Status).To(Equal(api.StatusFailed))
+
+				logs, err := service.GetInstallationLogs(installation.ID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logs.Logs).To(Equal("first chunk\nsecond chunk\n"))
+
+				deployed, err := service.ListDeployedProducts()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deployed).To(BeEmpty())
+			})
+		})
+
+		When("a product is not completely configured", func() {
+			BeforeEach(func() {
+				config.Products[0].Properties[".properties.required"] = fakeopsman.PropertyConfig{
+					Type:         "string",
+					Configurable: true,
+				}
+			})
+
+			It("refuses to deploy it", func() {
+				changes, err := service.ListStagedPendingChanges()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(changes.ChangeList[0].CompletenessChecks.ConfigurationComplete).To(BeFalse())
+
+				_, err = service.CreateInstallation(false, true, nil, api.ApplyErrandChanges{})
+				Expect(err).To(MatchError(ContainSubstring("example-product configuration is incomplete")))
+			})
+		})
+	})
+})
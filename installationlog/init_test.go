@@ -0,0 +1,13 @@
+package installationlog_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestInstallationLog(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "installationlog")
+}
@@ -0,0 +1,296 @@
+// Package installationlog splits the log of an Ops Manager installation
+// into the stages the installation went through.
+package installationlog
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pivotal-cf/om/models"
+)
+
+// Ops Manager wraps every bosh command it runs with lines like:
+//
+//	===== 2020-07-01 17:44:55 UTC Running "/usr/local/bin/bosh ... deploy /var/tempest/workspaces/default/deployments/cf-1234.yml"
+//	===== 2020-07-01 17:49:55 UTC Finished "/usr/local/bin/bosh ... deploy /var/tempest/workspaces/default/deployments/cf-1234.yml"; Duration: 300s; Exit Status: 0
+//
+// and bosh deploy reports the update of every instance with a single task event,
+// stamped with the time the update started and ending with how long it took:
+//
+//	Task 42 | 17:45:10 | Updating instance router: router/8f2c9a14-5b7e-4c2d-9f3a-1e6b8d0c7a25 (0) (canary) (00:02:10)
+var (
+	commandLineRegex  = regexp.MustCompile(`^===== (\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2} \S+) (Running|Finished) "(.*)"(?:; Duration: (\d+)s; Exit Status: (\d+))?`)
+	instanceLineRegex = regexp.MustCompile(`^Task \d+ \| (\d{2}:\d{2}:\d{2}) \| Updating instance ([^:]+): .*?(?: \((\d+):(\d{2}):(\d{2})\))?\s*$`)
+	errorLineRegex    = regexp.MustCompile(`(?i)\berror\b`)
+)
+
+const timestampLayout = "2006-01-02 15:04:05 MST"
+
+// boshValueFlags are the global bosh flags whose value may be the next argument.
+var boshValueFlags = map[string]bool{
+	"-e": true, "--environment": true,
+	"-d": true, "--deployment": true,
+	"--client": true, "--ca-cert": true,
+}
+
+// Parse returns the stages of the log in the order they started.
+// The instance groups updated by a bosh deploy follow the deployment stage.
+// Stages keep at most maxErrorLines error lines; a failed stage without any
+// keeps its last lines instead.
+func Parse(log string, maxErrorLines int) []models.InstallationStage {
+	p := &parser{
+		maxErrorLines: maxErrorLines,
+		command:       -1,
+	}
+
+	for _, line := range strings.Split(log, "\n") {
+		p.line(strings.TrimRight(line, "\r"))
+	}
+	p.closeCommand(nil, nil, nil)
+
+	return p.stages
+}
+
+type parser struct {
+	maxErrorLines int
+	stages        []models.InstallationStage
+
+	// command is the index of the stage of the running bosh command,
+	// groups those of the instance groups it has updated so far.
+	command      int
+	groups       map[string]int
+	failedGroups map[int]bool
+	lastGroup    int
+	lastTime     time.Time
+	tail         []string
+}
+
+func (p *parser) line(line string) {
+	if matches := commandLineRegex.FindStringSubmatch(line); matches != nil {
+		timestamp, err := time.Parse(timestampLayout, matches[1])
+		if err != nil {
+			return
+		}
+
+		if matches[2] == "Running" {
+			p.closeCommand(nil, nil, nil)
+			p.startCommand(matches[3], timestamp)
+			return
+		}
+
+		var duration *float64
+		if matches[4] != "" {
+			seconds, _ := strconv.ParseFloat(matches[4], 64)
+			duration = &seconds
+		}
+
+		var exitStatus *int
+		if matches[5] != "" {
+			status, _ := strconv.Atoi(matches[5])
+			exitStatus = &status
+		}
+
+		p.closeCommand(&timestamp, duration, exitStatus)
+		return
+	}
+
+	if p.command < 0 || strings.TrimSpace(line) == "" {
+		return
+	}
+
+	p.tail = append(p.tail, line)
+	if len(p.tail) > p.maxErrorLines {
+		p.tail = p.tail[1:]
+	}
+
+	if matches := instanceLineRegex.FindStringSubmatch(line); matches != nil && p.stages[p.command].Type == models.InstallationStageDeployment {
+		p.instanceUpdated(matches[2], matches[1], matches[3:])
+		return
+	}
+
+	if errorLineRegex.MatchString(line) {
+		p.addErrorLine(p.command, line)
+
+		if p.lastGroup >= 0 {
+			p.addErrorLine(p.lastGroup, line)
+			p.failedGroups[p.lastGroup] = true
+		}
+	}
+}
+
+func (p *parser) startCommand(command string, startedAt time.Time) {
+	stage := classify(command)
+	stage.StartedAt = startedAt
+	stage.Outcome = models.InstallationStageIncomplete
+
+	p.stages = append(p.stages, stage)
+	p.command = len(p.stages) - 1
+	p.groups = map[string]int{}
+	p.failedGroups = map[int]bool{}
+	p.lastGroup = -1
+	p.lastTime = startedAt
+	p.tail = nil
+}
+
+// closeCommand ends the stage of the running command, and of its instance groups.
+// Without a finishedAt, the log ended before the command did.
+func (p *parser) closeCommand(finishedAt *time.Time, duration *float64, exitStatus *int) {
+	if p.command < 0 {
+		return
+	}
+
+	stage := &p.stages[p.command]
+	stage.ExitStatus = exitStatus
+
+	end := p.lastTime
+	if finishedAt != nil {
+		end = *finishedAt
+		stage.FinishedAt = finishedAt
+		stage.Outcome = models.InstallationStageSucceeded
+		if exitStatus != nil && *exitStatus != 0 {
+			stage.Outcome = models.InstallationStageFailed
+		}
+	}
+
+	stage.DurationSeconds = end.Sub(stage.StartedAt).Seconds()
+	if duration != nil {
+		stage.DurationSeconds = *duration
+	}
+
+	if stage.Outcome == models.InstallationStageFailed && len(stage.ErrorLines) == 0 {
+		stage.ErrorLines = p.tail
+	}
+
+	for _, index := range p.groups {
+		group := &p.stages[index]
+		group.DurationSeconds = group.FinishedAt.Sub(group.StartedAt).Seconds()
+
+		switch {
+		case p.failedGroups[index]:
+			group.Outcome = models.InstallationStageFailed
+		case finishedAt != nil:
+			group.Outcome = models.InstallationStageSucceeded
+		default:
+			group.FinishedAt = nil
+		}
+	}
+
+	p.command = -1
+}
+
+// instanceUpdated adds the update of an instance to its instance group,
+// which spans from the first update started to the last one finished.
+// Without a duration, the log ended before the update did.
+func (p *parser) instanceUpdated(name string, clock string, duration []string) {
+	startedAt, err := p.taskTime(clock)
+	if err != nil {
+		return
+	}
+
+	finishedAt := startedAt
+	if duration[0] != "" {
+		hours, _ := strconv.Atoi(duration[0])
+		minutes, _ := strconv.Atoi(duration[1])
+		seconds, _ := strconv.Atoi(duration[2])
+		finishedAt = startedAt.Add(time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second)
+	}
+
+	index, ok := p.groups[name]
+	if !ok {
+		p.stages = append(p.stages, models.InstallationStage{
+			Name:       name,
+			Type:       models.InstallationStageInstanceGroup,
+			Deployment: p.stages[p.command].Deployment,
+			StartedAt:  startedAt,
+			FinishedAt: &finishedAt,
+			Outcome:    models.InstallationStageIncomplete,
+		})
+		index = len(p.stages) - 1
+		p.groups[name] = index
+	}
+
+	group := &p.stages[index]
+	if startedAt.Before(group.StartedAt) {
+		group.StartedAt = startedAt
+	}
+	if finishedAt.After(*group.FinishedAt) {
+		group.FinishedAt = &finishedAt
+	}
+	p.lastGroup = index
+}
+
+// taskTime dates the time of day of a bosh task event,
+// assuming events are never more than half a day apart.
+func (p *parser) taskTime(clock string) (time.Time, error) {
+	timeOfDay, err := time.Parse("15:04:05", clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	year, month, day := p.lastTime.Date()
+	timestamp := time.Date(year, month, day, timeOfDay.Hour(), timeOfDay.Minute(), timeOfDay.Second(), 0, p.lastTime.Location())
+	if p.lastTime.Sub(timestamp) > 12*time.Hour {
+		timestamp = timestamp.AddDate(0, 0, 1)
+	}
+
+	p.lastTime = timestamp
+	return timestamp, nil
+}
+
+func (p *parser) addErrorLine(index int, line string) {
+	if len(p.stages[index].ErrorLines) < p.maxErrorLines {
+		p.stages[index].ErrorLines = append(p.stages[index].ErrorLines, strings.TrimSpace(line))
+	}
+}
+
+// classify names the stage of a bosh command after what it does:
+// create-env deploys the director, deploy a product, run-errand an errand.
+func classify(command string) models.InstallationStage {
+	var (
+		deployment string
+		arguments  []string
+	)
+
+	fields := strings.Fields(command)
+	for i := 1; i < len(fields); i++ {
+		field := fields[i]
+		if !strings.HasPrefix(field, "-") {
+			arguments = append(arguments, field)
+			continue
+		}
+
+		flag, value, hasValue := field, "", false
+		if equals := strings.Index(field, "="); equals >= 0 {
+			flag, value, hasValue = field[:equals], field[equals+1:], true
+		}
+		if !hasValue && boshValueFlags[flag] && i+1 < len(fields) {
+			i++
+			value = fields[i]
+		}
+
+		if flag == "-d" || flag == "--deployment" {
+			deployment = value
+		}
+	}
+
+	if len(arguments) == 0 {
+		return models.InstallationStage{Name: command, Type: models.InstallationStageCommand}
+	}
+
+	switch arguments[0] {
+	case "create-env":
+		return models.InstallationStage{Name: "director", Type: models.InstallationStageDirector}
+	case "deploy":
+		return models.InstallationStage{Name: deployment, Type: models.InstallationStageDeployment, Deployment: deployment}
+	case "run-errand":
+		name := arguments[0]
+		if len(arguments) > 1 {
+			name = arguments[1]
+		}
+		return models.InstallationStage{Name: name, Type: models.InstallationStageErrand, Deployment: deployment}
+	default:
+		return models.InstallationStage{Name: arguments[0], Type: models.InstallationStageCommand, Deployment: deployment}
+	}
+}
@@ -0,0 +1,160 @@
+package installationlog_test
+
+import (
+	"io/ioutil"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/installationlog"
+	"github.com/pivotal-cf/om/models"
+)
+
+var _ = Describe("Parse", func() {
+	at := func(value string) time.Time {
+		timestamp, err := time.Parse(time.RFC3339, value)
+		Expect(err).ToNot(HaveOccurred())
+		return timestamp
+	}
+
+	pointerTo := func(value string) *time.Time {
+		timestamp := at(value)
+		return &timestamp
+	}
+
+	exitStatus := func(status int) *int {
+		return &status
+	}
+
+	diegoCellErrorLines := []string{
+		"L Error: Action Failed get_task: Task 6b1e2f3a-4c5d-4e6f-7a8b-9c0d1e2f3a4b result: 1 of 4 pre-start scripts failed. Failed Jobs: rep. Successful Jobs: bosh-dns, loggregator_agent, cflinuxfs3-rootfs-setup.",
+		"Task 42 | 00:11:01 | Error: Action Failed get_task: Task 6b1e2f3a-4c5d-4e6f-7a8b-9c0d1e2f3a4b result: 1 of 4 pre-start scripts failed. Failed Jobs: rep. Successful Jobs: bosh-dns, loggregator_agent, cflinuxfs3-rootfs-setup.",
+	}
+
+	It("splits the log into the director, product, instance group and command stages", func() {
+		contents, err := ioutil.ReadFile("fixtures/failed-installation.log")
+		Expect(err).ToNot(HaveOccurred())
+
+		stages := installationlog.Parse(string(contents), 2)
+
+		Expect(stages).To(Equal([]models.InstallationStage{
+			{
+				Name:            "director",
+				Type:            models.InstallationStageDirector,
+				StartedAt:       at("2020-07-01T23:30:00Z"),
+				FinishedAt:      pointerTo("2020-07-01T23:40:00Z"),
+				DurationSeconds: 600,
+				Outcome:         models.InstallationStageSucceeded,
+				ExitStatus:      exitStatus(0),
+			},
+			{
+				Name:            "update-runtime-config",
+				Type:            models.InstallationStageCommand,
+				StartedAt:       at("2020-07-01T23:40:05Z"),
+				FinishedAt:      pointerTo("2020-07-01T23:40:10Z"),
+				DurationSeconds: 5,
+				Outcome:         models.InstallationStageSucceeded,
+				ExitStatus:      exitStatus(0),
+			},
+			{
+				Name:            "cf-1234",
+				Type:            models.InstallationStageDeployment,
+				Deployment:      "cf-1234",
+				StartedAt:       at("2020-07-01T23:40:20Z"),
+				FinishedAt:      pointerTo("2020-07-02T00:11:05Z"),
+				DurationSeconds: 1845,
+				Outcome:         models.InstallationStageFailed,
+				ExitStatus:      exitStatus(1),
+				ErrorLines:      diegoCellErrorLines,
+			},
+			{
+				Name:            "nats",
+				Type:            models.InstallationStageInstanceGroup,
+				Deployment:      "cf-1234",
+				StartedAt:       at("2020-07-01T23:41:18Z"),
+				FinishedAt:      pointerTo("2020-07-01T23:42:20Z"),
+				DurationSeconds: 62,
+				Outcome:         models.InstallationStageSucceeded,
+			},
+			{
+				Name:            "router",
+				Type:            models.InstallationStageInstanceGroup,
+				Deployment:      "cf-1234",
+				StartedAt:       at("2020-07-01T23:42:20Z"),
+				FinishedAt:      pointerTo("2020-07-02T00:00:30Z"),
+				DurationSeconds: 1090,
+				Outcome:         models.InstallationStageSucceeded,
+			},
+			{
+				Name:            "diego_cell",
+				Type:            models.InstallationStageInstanceGroup,
+				Deployment:      "cf-1234",
+				StartedAt:       at("2020-07-02T00:00:30Z"),
+				FinishedAt:      pointerTo("2020-07-02T00:11:01Z"),
+				DurationSeconds: 631,
+				Outcome:         models.InstallationStageFailed,
+				ErrorLines:      diegoCellErrorLines,
+			},
+		}))
+	})
+
+	It("times an instance group of a single instance by the duration of its update", func() {
+		stages := installationlog.Parse(`===== 2020-07-01 17:00:00 UTC Running "/usr/local/bin/bosh --deployment=p-redis-5678 deploy p-redis-5678.yml"
+Task 43 | 17:00:10 | Updating instance redis-broker: redis-broker/5e6f7a8b-9c0d-4e1f-a2b3-c4d5e6f7a8b9 (0) (canary) (00:02:05)
+===== 2020-07-01 17:02:20 UTC Finished "/usr/local/bin/bosh --deployment=p-redis-5678 deploy p-redis-5678.yml"; Duration: 140s; Exit Status: 0
+`, 10)
+
+		Expect(stages).To(HaveLen(2))
+		Expect(stages[1].Name).To(Equal("redis-broker"))
+		Expect(stages[1].StartedAt).To(Equal(at("2020-07-01T17:00:10Z")))
+		Expect(stages[1].FinishedAt).To(Equal(pointerTo("2020-07-01T17:02:15Z")))
+		Expect(stages[1].DurationSeconds).To(Equal(125.0))
+		Expect(stages[1].Outcome).To(Equal(models.InstallationStageSucceeded))
+	})
+
+	It("recognizes errands", func() {
+		stages := installationlog.Parse(`===== 2020-07-01 17:44:55 UTC Running "/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-1234 run-errand smoke_tests"
+Errand 'smoke_tests' completed successfully (exit code 0)
+===== 2020-07-01 17:49:55 UTC Finished "/usr/local/bin/bosh --no-color --non-interactive --tty --environment=10.0.0.5 --deployment=cf-1234 run-errand smoke_tests"; Duration: 300s; Exit Status: 0
+`, 10)
+
+		Expect(stages).To(HaveLen(1))
+		Expect(stages[0].Name).To(Equal("smoke_tests"))
+		Expect(stages[0].Type).To(Equal(models.InstallationStageErrand))
+		Expect(stages[0].Deployment).To(Equal("cf-1234"))
+		Expect(stages[0].Outcome).To(Equal(models.InstallationStageSucceeded))
+	})
+
+	It("keeps the last lines of a failed stage without error lines", func() {
+		stages := installationlog.Parse(`===== 2020-07-01 17:44:55 UTC Running "/usr/local/bin/bosh -d p-redis-5678 run-errand broker-registrar"
+registering broker
+connection refused
+
+Exit code 1
+===== 2020-07-01 17:45:55 UTC Finished "/usr/local/bin/bosh -d p-redis-5678 run-errand broker-registrar"; Duration: 60s; Exit Status: 1
+`, 2)
+
+		Expect(stages).To(HaveLen(1))
+		Expect(stages[0].Deployment).To(Equal("p-redis-5678"))
+		Expect(stages[0].Outcome).To(Equal(models.InstallationStageFailed))
+		Expect(stages[0].ErrorLines).To(Equal([]string{"connection refused", "Exit code 1"}))
+	})
+
+	It("leaves the stage still running at the end of the log incomplete", func() {
+		stages := installationlog.Parse(`===== 2020-07-01 17:00:00 UTC Running "/usr/local/bin/bosh --deployment=cf-1234 deploy cf-1234.yml"
+Task 42 | 17:10:00 | Updating instance router: router/8f2c (0) (canary)
+`, 10)
+
+		Expect(stages).To(HaveLen(2))
+		Expect(stages[0].Outcome).To(Equal(models.InstallationStageIncomplete))
+		Expect(stages[0].FinishedAt).To(BeNil())
+		Expect(stages[0].DurationSeconds).To(Equal(600.0))
+		Expect(stages[1].Name).To(Equal("router"))
+		Expect(stages[1].Outcome).To(Equal(models.InstallationStageIncomplete))
+		Expect(stages[1].FinishedAt).To(BeNil())
+	})
+
+	It("has no stages for a log without bosh commands", func() {
+		Expect(installationlog.Parse("", 10)).To(BeEmpty())
+	})
+})
@@ -3,13 +3,24 @@ package interpolate
 import (
 	"fmt"
 	"io/ioutil"
+	"sort"
 	"strings"
 
 	"github.com/cloudfoundry/bosh-cli/director/template"
 	"github.com/cppforlife/go-patch/patch"
+	"github.com/pivotal-cf/om/credhub"
+	"github.com/pivotal-cf/om/vault"
 	"gopkg.in/yaml.v2"
 )
 
+// VarsSourceConfig is how to connect to the secret stores of the vars sources.
+// Its Sources, as those of the env file, are looked up after those of the Options.
+type VarsSourceConfig struct {
+	Sources []string
+	Vault   vault.Config
+	CredHub credhub.Config
+}
+
 type Options struct {
 	TemplateFile  string
 	VarsEnvs      []string
@@ -19,21 +30,111 @@ type Options struct {
 	EnvironFunc   func() []string
 	ExpectAllKeys bool
 	Path          string
+
+	// VarsSources are looked up, in order, for the variables
+	// missing from the vars envs, vars files and vars.
+	VarsSources      []string
+	VarsSourceConfig VarsSourceConfig
 }
 
 func Execute(o Options) ([]byte, error) {
+	tpl, vars, ops, err := load(o)
+	if err != nil {
+		return nil, err
+	}
+
+	evalOpts := template.EvaluateOpts{
+		UnescapedMultiline: true,
+		ExpectAllKeys:      o.ExpectAllKeys,
+	}
+
+	path, err := patch.NewPointerFromString(o.Path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse path: %s", err)
+	}
+
+	firstPassBytes, err := tpl.Evaluate(vars, ops, evalOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	if path.IsSet() {
+		evalOpts.PostVarSubstitutionOp = patch.FindOp{Path: path}
+	}
+
+	secondPassTemplate := template.NewTemplate(firstPassBytes)
+	secondPassBytes, err := secondPassTemplate.Evaluate(vars, nil, evalOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	return secondPassBytes, nil
+}
+
+// load reads the template and the ops files, and gathers the variables of the options.
+func load(o Options) (template.Template, *variables, patch.Ops, error) {
 	contents, err := ioutil.ReadFile(o.TemplateFile)
 	if err != nil {
-		return nil, fmt.Errorf("could not read file (%s): %s", o.TemplateFile, err.Error())
+		return template.Template{}, nil, nil, fmt.Errorf("could not read file (%s): %s", o.TemplateFile, err.Error())
 	}
 
 	tpl := template.NewTemplate(contents)
 
+	vars, err := loadVariables(o)
+	if err != nil {
+		return template.Template{}, nil, nil, err
+	}
+
+	ops := patch.Ops{}
+	for _, path := range o.OpsFiles {
+		var opDefs []patch.OpDefinition
+		err := readYAMLFile(path, &opDefs)
+		if err != nil {
+			return template.Template{}, nil, nil, err
+		}
+		op, err := patch.NewOpsFromDefinitions(opDefs)
+		if err != nil {
+			return template.Template{}, nil, nil, fmt.Errorf("Building ops (%s)", err.Error())
+		}
+		ops = append(ops, op)
+	}
+
+	return tpl, vars, ops, nil
+}
+
+// variables looks up a variable in the vars envs, vars files and vars,
+// then in the vars sources, and remembers where each one was found.
+type variables struct {
+	static   template.StaticVariables
+	origins  map[string]string
+	supplied []suppliedVariable
+	sources  []variablesSource
+
+	lookups map[string]string
+}
+
+type suppliedVariable struct {
+	name   string
+	origin string
+}
+
+type variablesSource struct {
+	origin    string
+	variables template.Variables
+}
+
+var _ template.Variables = &variables{}
+
+func loadVariables(o Options) (*variables, error) {
+	vars := &variables{
+		static:  template.StaticVariables{},
+		origins: map[string]string{},
+		lookups: map[string]string{},
+	}
+
 	// the following was taken from bosh cli
 	// https://github.com/cloudfoundry/bosh-cli/blob/9c1c210c83673a780e3787a91f444541755e6585/cmd/opts/var_flags.go
 	// we cannot use it directly because of the use of `jhanda`
-	staticVars := template.StaticVariables{}
-
 	for _, prefix := range o.VarsEnvs {
 		varsEnvArg := &template.VarsEnvArg{EnvironFunc: o.EnvironFunc}
 		err := varsEnvArg.UnmarshalFlag(prefix)
@@ -41,12 +142,12 @@ func Execute(o Options) ([]byte, error) {
 			return nil, err
 		}
 
-		for k, v := range varsEnvArg.Vars {
-			staticVars[k] = maintainMultilineStringForEnvVar(
+		for _, k := range sortedKeys(varsEnvArg.Vars) {
+			vars.supply(k, "vars-env "+prefix, maintainMultilineStringForEnvVar(
 				o.EnvironFunc,
 				fmt.Sprintf("%s_%s", prefix, k),
-				v,
-			)
+				varsEnvArg.Vars[k],
+			))
 		}
 	}
 
@@ -57,8 +158,8 @@ func Execute(o Options) ([]byte, error) {
 			return nil, err
 		}
 
-		for k, v := range varFilesArg.Vars {
-			staticVars[k] = v
+		for _, k := range sortedKeys(varFilesArg.Vars) {
+			vars.supply(k, "vars-file "+v, varFilesArg.Vars[k])
 		}
 	}
 
@@ -69,49 +170,77 @@ func Execute(o Options) ([]byte, error) {
 			return nil, err
 		}
 
-		staticVars[varArg.Name] = maintainMultilineString(v, varArg.Value)
+		vars.supply(varArg.Name, "var", maintainMultilineString(v, varArg.Value))
 	}
 
-	ops := patch.Ops{}
-	for _, path := range o.OpsFiles {
-		var opDefs []patch.OpDefinition
-		err := readYAMLFile(path, &opDefs)
+	for _, source := range append(append([]string{}, o.VarsSources...), o.VarsSourceConfig.Sources...) {
+		sourceVars, err := newVarsSource(source, o.VarsSourceConfig)
 		if err != nil {
 			return nil, err
 		}
-		op, err := patch.NewOpsFromDefinitions(opDefs)
-		if err != nil {
-			return nil, fmt.Errorf("Building ops (%s)", err.Error())
-		}
-		ops = append(ops, op)
+		vars.sources = append(vars.sources, variablesSource{origin: source, variables: sourceVars})
 	}
 
-	evalOpts := template.EvaluateOpts{
-		UnescapedMultiline: true,
-		ExpectAllKeys:      o.ExpectAllKeys,
+	return vars, nil
+}
+
+// supply sets a variable, overriding the value of an earlier option.
+func (v *variables) supply(name, origin string, value interface{}) {
+	v.static[name] = value
+	v.origins[rootName(name)] = origin
+	v.supplied = append(v.supplied, suppliedVariable{name: name, origin: origin})
+}
+
+// Get records where the variable was found, or that it is missing.
+func (v *variables) Get(definition template.VariableDefinition) (interface{}, bool, error) {
+	if value, found, _ := v.static.Get(definition); found {
+		v.lookups[definition.Name] = v.origins[definition.Name]
+		return value, true, nil
 	}
 
-	path, err := patch.NewPointerFromString(o.Path)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse path: %s", err)
+	for _, source := range v.sources {
+		value, found, err := source.variables.Get(definition)
+		if err != nil {
+			return nil, false, err
+		}
+		if found {
+			v.lookups[definition.Name] = source.origin
+			return value, true, nil
+		}
 	}
 
-	firstPassBytes, err := tpl.Evaluate(staticVars, ops, evalOpts)
-	if err != nil {
-		return nil, err
+	v.lookups[definition.Name] = ""
+	return nil, false, nil
+}
+
+func (v *variables) List() ([]template.VariableDefinition, error) {
+	return v.static.List()
+}
+
+// rootName is the name a dotted variable, as ((cert.private_key)), is looked up by.
+func rootName(name string) string {
+	return strings.SplitN(name, ".", 2)[0]
+}
+
+func sortedKeys(vars template.StaticVariables) []string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
+	return keys
+}
 
-	if path.IsSet() {
-		evalOpts.PostVarSubstitutionOp = patch.FindOp{Path: path}
+func newVarsSource(source string, config VarsSourceConfig) (template.Variables, error) {
+	if strings.HasPrefix(source, "vault://") {
+		return vault.NewVariables(source, config.Vault)
 	}
 
-	secondPassTemplate := template.NewTemplate(firstPassBytes)
-	secondPassBytes, err := secondPassTemplate.Evaluate(staticVars, nil, evalOpts)
-	if err != nil {
-		return nil, err
+	if strings.HasPrefix(source, "credhub://") {
+		return credhub.NewVariables(source, config.CredHub)
 	}
 
-	return secondPassBytes, nil
+	return nil, fmt.Errorf("unsupported vars source %q: expected vault://mount/path or credhub://prefix", source)
 }
 
 func maintainMultilineStringForEnvVar(environFunc func() []string, key string, v interface{}) interface{} {
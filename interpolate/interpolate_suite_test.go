@@ -1,10 +1,15 @@
 package interpolate_test
 
 import (
+	"encoding/pem"
 	"io/ioutil"
+	"net/http"
 	"testing"
 
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/om/credhub"
 	"github.com/pivotal-cf/om/interpolate"
+	"github.com/pivotal-cf/om/vault"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -212,6 +217,146 @@ var _ = Describe("Execute", func() {
 		})
 	})
 
+	When("vars sources are specified", func() {
+		var server *ghttp.Server
+
+		BeforeEach(func() {
+			server = ghttp.NewServer()
+			server.RouteToHandler("GET", "/v1/sys/internal/ui/mounts/secret/foundation", ghttp.RespondWith(http.StatusOK, `{"data": {"path": "secret/", "options": {"version": "2"}}}`))
+			server.RouteToHandler("GET", "/v1/secret/data/foundation/password", ghttp.RespondWith(http.StatusOK, `{"data": {"data": {"value": "from-vault"}}}`))
+			server.RouteToHandler("GET", "/v1/secret/data/foundation/cert", ghttp.RespondWith(http.StatusOK, `{"data": {"data": {"certificate": "some-cert", "private_key": "some-key"}}}`))
+			server.RouteToHandler("GET", "/v1/secret/data/foundation/username", ghttp.RespondWith(http.StatusOK, `{"data": {"data": {"value": "from-vault"}}}`))
+			server.RouteToHandler("GET", "/v1/secret/data/foundation/missing", ghttp.RespondWith(http.StatusNotFound, `{"errors": []}`))
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("looks up the variables missing from the other options", func() {
+			contents, err := interpolate.Execute(interpolate.Options{
+				TemplateFile: writeFile(`{username: ((username)), password: ((password)), key: ((cert.private_key))}`),
+				Vars:         []string{"username=Bob"},
+				VarsSources:  []string{"vault://secret/foundation"},
+				VarsSourceConfig: interpolate.VarsSourceConfig{
+					Vault: vault.Config{Address: server.URL(), Token: "some-token"},
+				},
+				ExpectAllKeys: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(MatchYAML(`{username: Bob, password: from-vault, key: some-key}`))
+		})
+
+		It("looks up the sources of the config after those of the options", func() {
+			contents, err := interpolate.Execute(interpolate.Options{
+				TemplateFile: writeFile(`{password: ((password))}`),
+				VarsSourceConfig: interpolate.VarsSourceConfig{
+					Sources: []string{"vault://secret/foundation"},
+					Vault:   vault.Config{Address: server.URL(), Token: "some-token"},
+				},
+				ExpectAllKeys: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(MatchYAML(`{password: from-vault}`))
+		})
+
+		It("fails when a variable is in none of them", func() {
+			_, err := interpolate.Execute(interpolate.Options{
+				TemplateFile: writeFile(`{name: ((missing))}`),
+				VarsSources:  []string{"vault://secret/foundation"},
+				VarsSourceConfig: interpolate.VarsSourceConfig{
+					Vault: vault.Config{Address: server.URL(), Token: "some-token"},
+				},
+				ExpectAllKeys: true,
+			})
+			Expect(err).To(MatchError(ContainSubstring("Expected to find variables: missing")))
+		})
+
+		It("errors with an unsupported source", func() {
+			_, err := interpolate.Execute(interpolate.Options{
+				TemplateFile: writeFile(`{name: ((username))}`),
+				VarsSources:  []string{"s3://bucket/vars.yml"},
+			})
+			Expect(err).To(MatchError(`unsupported vars source "s3://bucket/vars.yml": expected vault://mount/path or credhub://prefix`))
+		})
+	})
+
+	When("CredHub is a vars source", func() {
+		var (
+			server *ghttp.Server
+			config credhub.Config
+		)
+
+		BeforeEach(func() {
+			server = ghttp.NewTLSServer()
+			server.RouteToHandler("GET", "/info", ghttp.RespondWith(http.StatusOK, `{"auth-server": {"url": "`+server.URL()+`"}}`))
+			server.RouteToHandler("POST", "/oauth/token", ghttp.RespondWith(http.StatusOK, `{"access_token": "some-token", "token_type": "bearer"}`, http.Header{"Content-Type": {"application/json"}}))
+			server.RouteToHandler("GET", "/api/v1/data", func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Query().Get("name") {
+				case "/foundation/password":
+					_, _ = w.Write([]byte(`{"data": [{"type": "password", "value": "from-credhub"}]}`))
+				case "/foundation/cert":
+					_, _ = w.Write([]byte(`{"data": [{"type": "certificate", "value": {"certificate": "some-cert", "private_key": "some-key"}}]}`))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			})
+
+			caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.HTTPTestServer.Certificate().Raw}))
+			config = credhub.Config{
+				Server: server.URL(),
+				Client: "some-client",
+				Secret: "some-secret",
+				CACert: caCert,
+			}
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("looks up the variables missing from the other options under the prefix of the source", func() {
+			contents, err := interpolate.Execute(interpolate.Options{
+				TemplateFile: writeFile(`{username: ((username)), password: ((password)), key: ((cert.private_key))}`),
+				Vars:         []string{"username=Bob"},
+				VarsSources:  []string{"credhub://foundation"},
+				VarsSourceConfig: interpolate.VarsSourceConfig{
+					CredHub: config,
+				},
+				ExpectAllKeys: true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(MatchYAML(`{username: Bob, password: from-credhub, key: some-key}`))
+		})
+
+		It("fails when a variable is not in CredHub either", func() {
+			_, err := interpolate.Execute(interpolate.Options{
+				TemplateFile: writeFile(`{name: ((missing))}`),
+				VarsSourceConfig: interpolate.VarsSourceConfig{
+					Sources: []string{"credhub://foundation"},
+					CredHub: config,
+				},
+				ExpectAllKeys: true,
+			})
+			Expect(err).To(MatchError(ContainSubstring("Expected to find variables: missing")))
+		})
+
+		It("does not look up CredHub without a credhub:// source, even when it is configured", func() {
+			_, err := interpolate.Execute(interpolate.Options{
+				TemplateFile: writeFile(`{name: ((password))}`),
+				EnvironFunc: func() []string {
+					return []string{"CREDHUB_SERVER=" + server.URL(), "CREDHUB_CLIENT=some-client", "CREDHUB_SECRET=some-secret"}
+				},
+				VarsSourceConfig: interpolate.VarsSourceConfig{
+					CredHub: config,
+				},
+				ExpectAllKeys: true,
+			})
+			Expect(err).To(MatchError(ContainSubstring("Expected to find variables: password")))
+			Expect(server.ReceivedRequests()).To(BeEmpty())
+		})
+	})
+
 	It("fails when no variables are provided", func() {
 		_, err := interpolate.Execute(interpolate.Options{
 			TemplateFile:  writeFile(`{name: ((username))}`),
@@ -270,6 +415,79 @@ other-template-keys:
 	})
 })
 
+var _ = Describe("ReportVariables", func() {
+	It("reports where each variable is resolved from, which are missing, and which are unused", func() {
+		templateFile := writeFile(`{name: ((name)), password: ((password)), cert: ((cert.private_key))}`)
+		opsFile := writeFile(`[{type: replace, path: "/port?", value: ((port))}]`)
+		varsFile := writeFile(`{name: Bob, password: old, stale: value, cert: {private_key: key}}`)
+
+		report, err := interpolate.ReportVariables(interpolate.Options{
+			TemplateFile: templateFile,
+			OpsFiles:     []string{opsFile},
+			VarsFiles:    []string{varsFile},
+			Vars:         []string{"password=new", "unused=value"},
+			VarsEnvs:     []string{"PREFIX"},
+			EnvironFunc: func() []string {
+				return []string{"PREFIX_name=Alice"}
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(report.Resolved).To(Equal([]interpolate.ReportedVariable{
+			{Name: "cert", FoundIn: []string{templateFile}, Source: "vars-file " + varsFile},
+			{Name: "name", FoundIn: []string{templateFile}, Source: "vars-file " + varsFile},
+			{Name: "password", FoundIn: []string{templateFile}, Source: "var"},
+		}))
+		Expect(report.Missing).To(Equal([]interpolate.ReportedVariable{
+			{Name: "port", FoundIn: []string{opsFile}},
+		}))
+		Expect(report.Unused).To(Equal([]interpolate.ReportedVariable{
+			{Name: "name", Source: "vars-env PREFIX", OverriddenBy: "vars-file " + varsFile},
+			{Name: "password", Source: "vars-file " + varsFile, OverriddenBy: "var"},
+			{Name: "stale", Source: "vars-file " + varsFile},
+			{Name: "unused", Source: "var"},
+		}))
+	})
+
+	It("reports the variables mapped by other variables without files", func() {
+		report, err := interpolate.ReportVariables(interpolate.Options{
+			TemplateFile: writeFile(`{password: ((password))}`),
+			VarsFiles:    []string{writeFile(`{password: ((secret_password))}`)},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Missing).To(Equal([]interpolate.ReportedVariable{
+			{Name: "secret_password"},
+		}))
+	})
+
+	It("reports the variables found in vars sources", func() {
+		server := ghttp.NewServer()
+		defer server.Close()
+		server.RouteToHandler("GET", "/v1/sys/internal/ui/mounts/secret/foundation", ghttp.RespondWith(http.StatusOK, `{"data": {"path": "secret/", "options": {"version": "2"}}}`))
+		server.RouteToHandler("GET", "/v1/secret/data/foundation/password", ghttp.RespondWith(http.StatusOK, `{"data": {"data": {"value": "vault"}}}`))
+
+		templateFile := writeFile(`{password: ((password))}`)
+		report, err := interpolate.ReportVariables(interpolate.Options{
+			TemplateFile: templateFile,
+			VarsSources:  []string{"vault://secret/foundation"},
+			VarsSourceConfig: interpolate.VarsSourceConfig{
+				Vault: vault.Config{Address: server.URL(), Token: "some-token"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(report.Resolved).To(Equal([]interpolate.ReportedVariable{
+			{Name: "password", FoundIn: []string{templateFile}, Source: "vault://secret/foundation"},
+		}))
+	})
+
+	It("errors when the template file does not exist", func() {
+		_, err := interpolate.ReportVariables(interpolate.Options{
+			TemplateFile: "unknown.txt",
+		})
+		Expect(err).To(MatchError("could not read file (unknown.txt): open unknown.txt: no such file or directory"))
+	})
+})
+
 func writeFile(contents string) string {
 	file, err := ioutil.TempFile("", "")
 	Expect(err).ToNot(HaveOccurred())
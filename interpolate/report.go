@@ -0,0 +1,106 @@
+package interpolate
+
+import (
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+)
+
+// the same placeholders as those the templates interpolate
+var placeholderRegexp = regexp.MustCompile(`\(\((!?[-/\.\w\pL]+)\)\)`)
+
+// Report tells, for the variables of a template, where each was resolved from,
+// which are missing, and which of the vars envs, vars files and vars are never used.
+type Report struct {
+	Resolved []ReportedVariable `yaml:"resolved"`
+	Missing  []ReportedVariable `yaml:"missing"`
+	Unused   []ReportedVariable `yaml:"unused"`
+}
+
+// ReportedVariable is a variable, the template and ops files it is found in,
+// and where its value comes from: a vars env, vars file, var or vars source.
+// A variable whose value is overridden by a later option is unused.
+type ReportedVariable struct {
+	Name         string   `yaml:"name"`
+	FoundIn      []string `yaml:"found_in,omitempty"`
+	Source       string   `yaml:"source,omitempty"`
+	OverriddenBy string   `yaml:"overridden_by,omitempty"`
+}
+
+// ReportVariables interpolates the template without expecting all the keys,
+// and reports the variables it looks up, and the supplied ones it does not.
+// Variables only found in the values of other variables are reported without files,
+// and variables of the template removed by the ops files are neither resolved nor missing.
+func ReportVariables(o Options) (Report, error) {
+	tpl, vars, ops, err := load(o)
+	if err != nil {
+		return Report{}, err
+	}
+
+	evalOpts := template.EvaluateOpts{
+		UnescapedMultiline: true,
+	}
+
+	firstPassBytes, err := tpl.Evaluate(vars, ops, evalOpts)
+	if err != nil {
+		return Report{}, err
+	}
+
+	_, err = template.NewTemplate(firstPassBytes).Evaluate(vars, nil, evalOpts)
+	if err != nil {
+		return Report{}, err
+	}
+
+	foundIn := map[string][]string{}
+	for _, path := range append([]string{o.TemplateFile}, o.OpsFiles...) {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return Report{}, err
+		}
+
+		seen := map[string]bool{}
+		for _, match := range placeholderRegexp.FindAllStringSubmatch(string(contents), -1) {
+			name := rootName(strings.TrimPrefix(match[1], "!"))
+			if !seen[name] {
+				seen[name] = true
+				foundIn[name] = append(foundIn[name], path)
+			}
+		}
+	}
+
+	report := Report{
+		Resolved: []ReportedVariable{},
+		Missing:  []ReportedVariable{},
+		Unused:   []ReportedVariable{},
+	}
+
+	names := make([]string, 0, len(vars.lookups))
+	for name := range vars.lookups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		source := vars.lookups[name]
+		variable := ReportedVariable{Name: name, FoundIn: foundIn[name], Source: source}
+		if source == "" {
+			report.Missing = append(report.Missing, variable)
+		} else {
+			report.Resolved = append(report.Resolved, variable)
+		}
+	}
+
+	for _, supplied := range vars.supplied {
+		source, lookedUp := vars.lookups[rootName(supplied.name)]
+		if !lookedUp {
+			report.Unused = append(report.Unused, ReportedVariable{Name: supplied.name, Source: supplied.origin})
+		} else if source != supplied.origin {
+			report.Unused = append(report.Unused, ReportedVariable{Name: supplied.name, Source: supplied.origin, OverriddenBy: source})
+		}
+	}
+
+	return report, nil
+}
@@ -21,6 +21,14 @@ func main() {
 			log.Print(err)
 			os.Exit(2)
 		}
+		if errors.Is(err, commands.ErrApplyChangesTimedOut) {
+			log.Print(err)
+			os.Exit(3)
+		}
+		if errors.Is(err, commands.ErrApplyChangesInterrupted) {
+			log.Print(err)
+			os.Exit(130)
+		}
 		log.Fatal(err)
 	}
 }
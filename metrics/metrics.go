@@ -0,0 +1,199 @@
+// Package metrics renders the state of an Ops Manager
+// in the OpenMetrics text format, for Prometheus and compatible scrapers.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pivotal-cf/om/api"
+)
+
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+type Family struct {
+	Name    string
+	Type    string
+	Unit    string
+	Help    string
+	Samples []Sample
+}
+
+type Sample struct {
+	Labels []Label
+	Value  float64
+}
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Write writes the families, followed by the # EOF marker
+// which ends every OpenMetrics exposition.
+func Write(w io.Writer, families ...Family) error {
+	var b strings.Builder
+
+	for _, family := range families {
+		fmt.Fprintf(&b, "# TYPE %s %s\n", family.Name, family.Type)
+		if family.Unit != "" {
+			fmt.Fprintf(&b, "# UNIT %s %s\n", family.Name, family.Unit)
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.Name, escape(family.Help, false))
+
+		for _, sample := range family.Samples {
+			b.WriteString(family.Name)
+
+			if len(sample.Labels) > 0 {
+				labels := make([]string, 0, len(sample.Labels))
+				for _, label := range sample.Labels {
+					labels = append(labels, fmt.Sprintf(`%s="%s"`, label.Name, escape(label.Value, true)))
+				}
+				fmt.Fprintf(&b, "{%s}", strings.Join(labels, ","))
+			}
+
+			fmt.Fprintf(&b, " %s\n", strconv.FormatFloat(sample.Value, 'f', -1, 64))
+		}
+	}
+
+	b.WriteString("# EOF\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// CertificateExpiry has a sample per certificate,
+// with the Unix time the certificate expires at.
+func CertificateExpiry(certificates []api.ExpiringCertificate) Family {
+	family := Family{
+		Name: "om_certificate_expiry_timestamp_seconds",
+		Type: "gauge",
+		Unit: "seconds",
+		Help: "Time the certificate expires at, in seconds since the Unix epoch.",
+	}
+
+	for _, certificate := range certificates {
+		family.Samples = append(family.Samples, Sample{
+			Labels: []Label{
+				{Name: "product", Value: certificate.ProductGUID},
+				{Name: "location", Value: certificate.Location},
+				{Name: "variable_path", Value: certificate.VariablePath},
+				{Name: "property_reference", Value: certificate.PropertyReference},
+				{Name: "issuer", Value: certificate.Issuer},
+			},
+			Value: float64(certificate.ValidUntil.Unix()),
+		})
+	}
+
+	return family
+}
+
+// PendingChanges counts the staged products by their pending action.
+// The install, update and delete actions are always present,
+// so alerts do not have to deal with missing series.
+func PendingChanges(output api.PendingChangesOutput) Family {
+	counts := map[string]int{
+		"install": 0,
+		"update":  0,
+		"delete":  0,
+	}
+	for _, change := range output.ChangeList {
+		if change.Action == "unchanged" {
+			continue
+		}
+		counts[change.Action]++
+	}
+
+	var actions []string
+	for action := range counts {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	family := Family{
+		Name: "om_pending_changes",
+		Type: "gauge",
+		Help: "Number of staged products with pending changes, by action.",
+	}
+	for _, action := range actions {
+		family.Samples = append(family.Samples, Sample{
+			Labels: []Label{{Name: "action", Value: action}},
+			Value:  float64(counts[action]),
+		})
+	}
+
+	return family
+}
+
+// LastInstallation describes the installation with the highest ID.
+// There are no families when nothing has been installed yet.
+func LastInstallation(installations []api.InstallationsServiceOutput) []Family {
+	if len(installations) == 0 {
+		return nil
+	}
+
+	last := installations[0]
+	for _, installation := range installations {
+		if installation.ID > last.ID {
+			last = installation
+		}
+	}
+
+	status := Family{
+		Name: "om_last_installation_status",
+		Type: "stateset",
+		Help: "Status of the last installation.",
+	}
+	for _, state := range []string{api.StatusRunning, api.StatusSucceeded, api.StatusFailed} {
+		value := 0.0
+		if last.Status == state {
+			value = 1
+		}
+		status.Samples = append(status.Samples, Sample{
+			Labels: []Label{{Name: status.Name, Value: state}},
+			Value:  value,
+		})
+	}
+
+	families := []Family{status}
+
+	if last.FinishedAt != nil {
+		families = append(families, Family{
+			Name:    "om_last_installation_finished_timestamp_seconds",
+			Type:    "gauge",
+			Unit:    "seconds",
+			Help:    "Time the last installation finished at, in seconds since the Unix epoch.",
+			Samples: []Sample{{Value: float64(last.FinishedAt.Unix())}},
+		})
+	}
+
+	return families
+}
+
+// Up is 1 when every metric could be collected from Ops Manager.
+func Up(up bool) Family {
+	value := 0.0
+	if up {
+		value = 1
+	}
+
+	return Family{
+		Name:    "om_up",
+		Type:    "gauge",
+		Help:    "Whether the last collection from Ops Manager succeeded.",
+		Samples: []Sample{{Value: value}},
+	}
+}
+
+func escape(value string, quoted bool) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	if quoted {
+		value = strings.ReplaceAll(value, `"`, `\"`)
+	}
+
+	return value
+}
@@ -0,0 +1,91 @@
+package metrics_test
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/metrics"
+)
+
+var _ = Describe("metrics", func() {
+	var output *bytes.Buffer
+
+	BeforeEach(func() {
+		output = &bytes.Buffer{}
+	})
+
+	It("writes the certificate expiry of every certificate", func() {
+		err := metrics.Write(output, metrics.CertificateExpiry([]api.ExpiringCertificate{
+			{
+				Issuer:            `CN="opsmgr"`,
+				ValidUntil:        time.Unix(1700000000, 0),
+				PropertyReference: ".properties.networking_poe_ssl_certs",
+				ProductGUID:       "cf-guid",
+				Location:          "ops_manager",
+			},
+			{
+				Issuer:       "/services/tls_ca",
+				ValidUntil:   time.Unix(1800000000, 0),
+				Location:     "credhub",
+				VariablePath: "/opsmgr/bosh_dns/tls_ca",
+			},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(Equal(`# TYPE om_certificate_expiry_timestamp_seconds gauge
+# UNIT om_certificate_expiry_timestamp_seconds seconds
+# HELP om_certificate_expiry_timestamp_seconds Time the certificate expires at, in seconds since the Unix epoch.
+om_certificate_expiry_timestamp_seconds{product="cf-guid",location="ops_manager",variable_path="",property_reference=".properties.networking_poe_ssl_certs",issuer="CN=\"opsmgr\""} 1700000000
+om_certificate_expiry_timestamp_seconds{product="",location="credhub",variable_path="/opsmgr/bosh_dns/tls_ca",property_reference="",issuer="/services/tls_ca"} 1800000000
+# EOF
+`))
+	})
+
+	It("counts the pending changes by action", func() {
+		err := metrics.Write(output, metrics.PendingChanges(api.PendingChangesOutput{
+			ChangeList: []api.ProductChange{
+				{GUID: "cf-guid", Action: "update"},
+				{GUID: "mysql-guid", Action: "update"},
+				{GUID: "redis-guid", Action: "unchanged"},
+			},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(Equal(`# TYPE om_pending_changes gauge
+# HELP om_pending_changes Number of staged products with pending changes, by action.
+om_pending_changes{action="delete"} 0
+om_pending_changes{action="install"} 0
+om_pending_changes{action="update"} 2
+# EOF
+`))
+	})
+
+	It("describes the installation with the highest id", func() {
+		finishedAt := time.Unix(1700000000, 0)
+
+		err := metrics.Write(output, metrics.LastInstallation([]api.InstallationsServiceOutput{
+			{ID: 2, Status: "failed", FinishedAt: &finishedAt},
+			{ID: 1, Status: "succeeded"},
+		})...)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(output.String()).To(Equal(`# TYPE om_last_installation_status stateset
+# HELP om_last_installation_status Status of the last installation.
+om_last_installation_status{om_last_installation_status="running"} 0
+om_last_installation_status{om_last_installation_status="succeeded"} 0
+om_last_installation_status{om_last_installation_status="failed"} 1
+# TYPE om_last_installation_finished_timestamp_seconds gauge
+# UNIT om_last_installation_finished_timestamp_seconds seconds
+# HELP om_last_installation_finished_timestamp_seconds Time the last installation finished at, in seconds since the Unix epoch.
+om_last_installation_finished_timestamp_seconds 1700000000
+# EOF
+`))
+	})
+
+	It("has no installation metrics before the first installation", func() {
+		Expect(metrics.LastInstallation(nil)).To(BeEmpty())
+	})
+})
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"github.com/pivotal-cf/om/api"
+)
 
 type Installation struct {
 	FinishedAt *time.Time `json:"finished_at,omitempty"`
@@ -20,3 +24,79 @@ type Errand struct {
 	PostDeployEnabled string `json:"post_deploy_enabled,omitempty"`
 	PreDeleteEnabled  string `json:"pre_delete_enabled,omitempty"`
 }
+
+type BoshDiff struct {
+	Director *api.DirectorDiff `json:"director,omitempty"`
+	Products []ProductDiff     `json:"products"`
+}
+
+type ProductDiff struct {
+	Name string `json:"name"`
+	api.ProductDiff
+}
+
+type InstallationLog struct {
+	ID   int    `json:"id"`
+	Logs string `json:"logs"`
+}
+
+const (
+	InstallationStageDirector      = "director"
+	InstallationStageDeployment    = "deployment"
+	InstallationStageInstanceGroup = "instance_group"
+	InstallationStageErrand        = "errand"
+	InstallationStageCommand       = "command"
+
+	InstallationStageSucceeded  = "succeeded"
+	InstallationStageFailed     = "failed"
+	InstallationStageIncomplete = "incomplete"
+)
+
+type InstallationSummary struct {
+	ID     int                 `json:"id"`
+	Status string              `json:"status"`
+	Stages []InstallationStage `json:"stages"`
+}
+
+// InstallationStage is a bosh command run by an installation,
+// or the update of an instance group by a bosh deploy.
+type InstallationStage struct {
+	Name            string     `json:"name"`
+	Type            string     `json:"type"`
+	Deployment      string     `json:"deployment,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	Outcome         string     `json:"outcome"`
+	ExitStatus      *int       `json:"exit_status,omitempty"`
+	ErrorLines      []string   `json:"error_lines,omitempty"`
+}
+
+type InstallationStats struct {
+	Since time.Time `json:"since"`
+	Until time.Time `json:"until"`
+	InstallationCounts
+	Longest []InstallationDuration  `json:"longest"`
+	Users   []UserInstallationStats `json:"users"`
+}
+
+// InstallationCounts describes a set of installations.
+// The success rate and durations only consider the finished installations.
+type InstallationCounts struct {
+	Installations       int     `json:"installations"`
+	Succeeded           int     `json:"succeeded"`
+	Failed              int     `json:"failed"`
+	SuccessRate         float64 `json:"success_rate"`
+	MeanDurationSeconds float64 `json:"mean_duration_seconds"`
+	P95DurationSeconds  float64 `json:"p95_duration_seconds"`
+}
+
+type InstallationDuration struct {
+	Installation
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+type UserInstallationStats struct {
+	User string `json:"user"`
+	InstallationCounts
+}
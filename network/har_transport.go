@@ -0,0 +1,507 @@
+package network
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const redacted = "[REDACTED]"
+
+// HARRecorder collects the requests and responses of HARTransports,
+// to write them as an HTTP Archive viewable in the network panel of browser devtools.
+type HARRecorder struct {
+	version string
+
+	mutex   sync.Mutex
+	records []*harRecord
+}
+
+func NewHARRecorder(version string) *HARRecorder {
+	return &HARRecorder{version: version}
+}
+
+// HARTransport records the requests and responses of the transport it wraps,
+// as they are sent: the requests for tokens and every retry included.
+// Bodies are captured as they are read, up to maxBodySize.
+type HARTransport struct {
+	transport http.RoundTripper
+	recorder  *HARRecorder
+}
+
+func NewHARTransport(transport http.RoundTripper, recorder *HARRecorder) *HARTransport {
+	return &HARTransport{
+		transport: transport,
+		recorder:  recorder,
+	}
+}
+
+type harRecord struct {
+	started time.Time
+	wait    time.Duration
+	receive time.Duration
+
+	method         string
+	url            *url.URL
+	proto          string
+	requestHeader  http.Header
+	requestBody    *harBody
+	status         int
+	statusText     string
+	responseProto  string
+	responseHeader http.Header
+	responseBody   *harBody
+	err            error
+}
+
+func (t *HARTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	record := &harRecord{
+		started:       time.Now(),
+		method:        request.Method,
+		url:           request.URL,
+		proto:         request.Proto,
+		requestHeader: request.Header.Clone(),
+	}
+	t.recorder.add(record)
+
+	if request.Body != nil && request.Body != http.NoBody {
+		// a round tripper must not modify the request, so the body is read from a copy
+		record.requestBody = &harBody{}
+		request = request.Clone(request.Context())
+		request.Body = &harBodyReader{ReadCloser: request.Body, body: record.requestBody}
+	}
+
+	response, err := t.transport.RoundTrip(request)
+	record.wait = time.Since(record.started)
+
+	if err != nil {
+		record.err = err
+		return nil, err
+	}
+
+	record.status = response.StatusCode
+	record.statusText = strings.TrimPrefix(response.Status, strconv.Itoa(response.StatusCode)+" ")
+	record.responseProto = response.Proto
+	record.responseHeader = response.Header.Clone()
+
+	if response.Body != nil {
+		record.responseBody = &harBody{}
+		received := time.Now()
+		response.Body = &harBodyReader{
+			ReadCloser: response.Body,
+			body:       record.responseBody,
+			done: func() {
+				record.receive = time.Since(received)
+			},
+		}
+	}
+
+	return response, nil
+}
+
+func (r *HARRecorder) add(record *harRecord) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.records = append(r.records, record)
+}
+
+// Write writes the HTTP Archive of the requests and responses recorded so far.
+// The Authorization and cookie headers, and the secrets of the queries
+// and of the JSON, YAML and form bodies, are redacted.
+// Other bodies are not recorded, as their secrets could not be redacted.
+func (r *HARRecorder) Write(writer io.Writer) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	archive := har{
+		Log: harLog{
+			Version: "1.2",
+			Creator: harCreator{Name: "om", Version: r.version},
+			Entries: []harEntry{},
+		},
+	}
+	for _, record := range r.records {
+		archive.Log.Entries = append(archive.Log.Entries, record.entry())
+	}
+
+	contents, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not marshal the HTTP Archive: %s", err)
+	}
+
+	_, err = writer.Write(append(contents, '\n'))
+	return err
+}
+
+func (record *harRecord) entry() harEntry {
+	entry := harEntry{
+		StartedDateTime: record.started.Format(time.RFC3339Nano),
+		Time:            milliseconds(record.wait + record.receive),
+		Request: harRequest{
+			Method:      record.method,
+			HTTPVersion: record.proto,
+			Cookies:     []harNameValue{},
+			Headers:     harHeaders(record.requestHeader),
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+		},
+		Response: harResponse{
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			HeadersSize: -1,
+		},
+		Cache: struct{}{},
+		Timings: harTimings{
+			Send:    0,
+			Wait:    milliseconds(record.wait),
+			Receive: milliseconds(record.receive),
+		},
+	}
+
+	if record.url != nil {
+		query := record.url.Query()
+		for _, name := range sortedKeys(query) {
+			for _, value := range query[name] {
+				if isSecretName(name) {
+					value = redacted
+				}
+				entry.Request.QueryString = append(entry.Request.QueryString, harNameValue{Name: name, Value: value})
+			}
+		}
+
+		recordedURL := *record.url
+		recordedURL.User = nil
+		recordedURL.RawQuery = redactValues(query).Encode()
+		entry.Request.URL = recordedURL.String()
+	}
+
+	if record.requestBody != nil {
+		mimeType := record.requestHeader.Get("Content-Type")
+		text, comment := record.requestBody.content(mimeType)
+		entry.Request.BodySize = record.requestBody.size
+		entry.Request.PostData = &harPostData{
+			MimeType: mimeType,
+			Params:   []harNameValue{},
+			Text:     text,
+			Comment:  comment,
+		}
+	}
+
+	if record.err != nil {
+		entry.Comment = record.err.Error()
+		return entry
+	}
+
+	entry.Response.Status = record.status
+	entry.Response.StatusText = record.statusText
+	entry.Response.HTTPVersion = record.responseProto
+	entry.Response.Headers = harHeaders(record.responseHeader)
+	entry.Response.RedirectURL = record.responseHeader.Get("Location")
+	entry.Response.Content.MimeType = record.responseHeader.Get("Content-Type")
+
+	if record.responseBody != nil {
+		text, comment := record.responseBody.content(entry.Response.Content.MimeType)
+		entry.Response.BodySize = record.responseBody.size
+		entry.Response.Content.Size = record.responseBody.size
+		entry.Response.Content.Text = text
+		entry.Response.Content.Comment = comment
+	}
+
+	return entry
+}
+
+func milliseconds(duration time.Duration) float64 {
+	return float64(duration) / float64(time.Millisecond)
+}
+
+func harHeaders(header http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			if isSecretHeader(name) {
+				value = redacted
+			}
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+
+	return headers
+}
+
+func sortedKeys(values map[string][]string) []string {
+	var keys []string
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isSecretHeader(name string) bool {
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie":
+		return true
+	}
+
+	return isSecretName(name)
+}
+
+// isSecretName tells whether a header, a query or form parameter, or a key of a body names a secret,
+// like the passphrase of /api/v0/unlock, the password of a credential or the encryption keys of a manifest.
+func isSecretName(name string) bool {
+	name = strings.ToLower(name)
+	for _, word := range []string{"passphrase", "password", "passcode", "secret", "token", "key", "credential"} {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func redactValues(values url.Values) url.Values {
+	redactedValues := url.Values{}
+	for name, value := range values {
+		if isSecretName(name) {
+			value = []string{redacted}
+		}
+		redactedValues[name] = value
+	}
+
+	return redactedValues
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range value {
+			if isSecretName(key) && nested != nil {
+				value[key] = redacted
+			} else {
+				value[key] = redactJSON(nested)
+			}
+		}
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = redactJSON(nested)
+		}
+	}
+
+	return value
+}
+
+func redactYAML(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[interface{}]interface{}:
+		for key, nested := range value {
+			if isSecretName(fmt.Sprint(key)) && nested != nil {
+				value[key] = redacted
+			} else {
+				value[key] = redactYAML(nested)
+			}
+		}
+	case []interface{}:
+		for i, nested := range value {
+			value[i] = redactYAML(nested)
+		}
+	}
+
+	return value
+}
+
+type harBody struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+	size   int
+}
+
+func (b *harBody) write(p []byte) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.size += len(p)
+	if remaining := maxBodySize - b.buffer.Len(); remaining > 0 {
+		if len(p) > remaining {
+			p = p[:remaining]
+		}
+		b.buffer.Write(p)
+	}
+}
+
+// content is the text of the body, redacted for JSON, YAML and forms.
+// Other bodies, and those over the limit, are not recorded,
+// as their secrets could not be redacted.
+func (b *harBody) content(contentType string) (text, comment string) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.size == 0 {
+		return "", ""
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !isJSON(mediaType) && !isYAML(mediaType) && mediaType != "application/x-www-form-urlencoded" {
+		if mediaType == "" {
+			mediaType = "untyped"
+		}
+		return "", fmt.Sprintf("%s body of %d bytes not recorded, as its secrets could not be redacted", mediaType, b.size)
+	}
+
+	contents := b.buffer.Bytes()
+	if b.size > len(contents) {
+		return "", fmt.Sprintf("body of %d bytes over the limit of %d bytes not recorded, as its secrets could not be redacted", b.size, maxBodySize)
+	}
+
+	switch {
+	case isJSON(mediaType):
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		decoder.UseNumber()
+		var value interface{}
+		err := decoder.Decode(&value)
+		if err != nil {
+			return "", "invalid JSON body not recorded, as its secrets could not be redacted"
+		}
+		redactedContents, _ := json.Marshal(redactJSON(value))
+		return string(redactedContents), ""
+
+	case isYAML(mediaType):
+		var value interface{}
+		err := yaml.Unmarshal(contents, &value)
+		if err != nil {
+			return "", "invalid YAML body not recorded, as its secrets could not be redacted"
+		}
+		redactedContents, _ := yaml.Marshal(redactYAML(value))
+		return string(redactedContents), ""
+
+	default:
+		values, err := url.ParseQuery(string(contents))
+		if err != nil {
+			return "", "invalid form body not recorded, as its secrets could not be redacted"
+		}
+		return redactValues(values).Encode(), ""
+	}
+}
+
+func isJSON(mediaType string) bool {
+	return strings.HasSuffix(mediaType, "json")
+}
+
+func isYAML(mediaType string) bool {
+	return strings.HasSuffix(mediaType, "yaml") || strings.HasSuffix(mediaType, "yml")
+}
+
+type harBodyReader struct {
+	io.ReadCloser
+	body *harBody
+	done func()
+	once sync.Once
+}
+
+func (r *harBodyReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.body.write(p[:n])
+	if err == io.EOF {
+		r.finish()
+	}
+
+	return n, err
+}
+
+func (r *harBodyReader) Close() error {
+	r.finish()
+	return r.ReadCloser.Close()
+}
+
+func (r *harBodyReader) finish() {
+	if r.done != nil {
+		r.once.Do(r.done)
+	}
+}
+
+// The HTTP Archive format, see http://www.softwareishard.com/blog/har-12-spec/
+
+type har struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harPostData struct {
+	MimeType string         `json:"mimeType"`
+	Params   []harNameValue `json:"params"`
+	Text     string         `json:"text"`
+	Comment  string         `json:"comment,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
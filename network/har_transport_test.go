@@ -0,0 +1,294 @@
+package network_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/om/network"
+	"github.com/pivotal-cf/om/network/fakes"
+)
+
+// roundTripper makes a fake client the transport of a HARTransport.
+type roundTripper func(*http.Request) (*http.Response, error)
+
+func (f roundTripper) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+var _ = Describe("HAR Transport", func() {
+	var (
+		fakeClient   *fakes.HttpClient
+		recorder     *network.HARRecorder
+		harTransport *network.HARTransport
+	)
+
+	BeforeEach(func() {
+		fakeClient = &fakes.HttpClient{}
+		fakeClient.DoStub = func(request *http.Request) (*http.Response, error) {
+			if request.Body != nil {
+				_, err := ioutil.ReadAll(request.Body)
+				Expect(err).ToNot(HaveOccurred())
+			}
+			return &http.Response{
+				Status:     "200 OK",
+				StatusCode: http.StatusOK,
+				Proto:      "HTTP/1.1",
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       ioutil.NopCloser(strings.NewReader(`{"products":[{"guid":"cf-guid"}]}`)),
+			}, nil
+		}
+
+		recorder = network.NewHARRecorder("1.2.3")
+		harTransport = network.NewHARTransport(roundTripper(fakeClient.Do), recorder)
+	})
+
+	do := func(request *http.Request) {
+		response, err := harTransport.RoundTrip(request)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = ioutil.ReadAll(response.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body.Close()).To(Succeed())
+	}
+
+	archive := func() map[string]interface{} {
+		var output bytes.Buffer
+		Expect(recorder.Write(&output)).To(Succeed())
+
+		var archive map[string]interface{}
+		Expect(json.Unmarshal(output.Bytes(), &archive)).To(Succeed())
+		return archive["log"].(map[string]interface{})
+	}
+
+	entry := func(index int) map[string]interface{} {
+		return archive()["entries"].([]interface{})[index].(map[string]interface{})
+	}
+
+	It("records the requests and responses in an HTTP Archive", func() {
+		request, err := http.NewRequest("POST", "https://opsman.example.com/api/v0/staged/products?verbose=true", strings.NewReader(`{"name":"cf"}`))
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Set("Content-Type", "application/json")
+		do(request)
+
+		log := archive()
+		Expect(log["version"]).To(Equal("1.2"))
+		Expect(log["creator"]).To(Equal(map[string]interface{}{"name": "om", "version": "1.2.3"}))
+		Expect(log["entries"]).To(HaveLen(1))
+
+		entry := log["entries"].([]interface{})[0].(map[string]interface{})
+		Expect(entry["startedDateTime"]).ToNot(BeEmpty())
+		Expect(entry["time"]).To(BeNumerically(">=", 0))
+		Expect(entry["timings"]).To(HaveKey("wait"))
+		Expect(entry["timings"]).To(HaveKey("receive"))
+
+		recorded := entry["request"].(map[string]interface{})
+		Expect(recorded).To(HaveKeyWithValue("method", "POST"))
+		Expect(recorded).To(HaveKeyWithValue("url", "https://opsman.example.com/api/v0/staged/products?verbose=true"))
+		Expect(recorded).To(HaveKeyWithValue("httpVersion", "HTTP/1.1"))
+		Expect(recorded["headers"]).To(ConsistOf(map[string]interface{}{"name": "Content-Type", "value": "application/json"}))
+		Expect(recorded["queryString"]).To(ConsistOf(map[string]interface{}{"name": "verbose", "value": "true"}))
+		Expect(recorded).To(HaveKeyWithValue("bodySize", BeNumerically("==", 13)))
+		Expect(recorded).To(HaveKeyWithValue("postData", map[string]interface{}{
+			"mimeType": "application/json",
+			"params":   []interface{}{},
+			"text":     `{"name":"cf"}`,
+		}))
+
+		response := entry["response"].(map[string]interface{})
+		Expect(response).To(HaveKeyWithValue("status", BeNumerically("==", 200)))
+		Expect(response).To(HaveKeyWithValue("statusText", "OK"))
+		Expect(response["headers"]).To(ConsistOf(map[string]interface{}{"name": "Content-Type", "value": "application/json"}))
+		Expect(response).To(HaveKeyWithValue("bodySize", BeNumerically("==", 33)))
+		Expect(response).To(HaveKeyWithValue("content", map[string]interface{}{
+			"size":     float64(33),
+			"mimeType": "application/json",
+			"text":     `{"products":[{"guid":"cf-guid"}]}`,
+		}))
+	})
+
+	It("redacts the secrets of the headers, queries and bodies", func() {
+		request, err := http.NewRequest("PUT", "https://opsman.example.com/api/v0/unlock?client_secret=some-secret", strings.NewReader(`{"passphrase":"some-passphrase","nested":{"password":{"secret":"some-password"},"identity":"admin"}}`))
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Set("Authorization", "Bearer some-token")
+		request.Header.Set("Content-Type", "application/json; charset=utf-8")
+		do(request)
+
+		request, err = http.NewRequest("POST", "https://opsman.example.com/uaa/oauth/token", strings.NewReader("grant_type=password&password=some-password&username=admin"))
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		do(request)
+
+		recorded := entry(0)["request"].(map[string]interface{})
+		Expect(recorded["url"]).To(Equal("https://opsman.example.com/api/v0/unlock?client_secret=%5BREDACTED%5D"))
+		Expect(recorded["queryString"]).To(ConsistOf(map[string]interface{}{"name": "client_secret", "value": "[REDACTED]"}))
+		Expect(recorded["headers"]).To(ContainElement(map[string]interface{}{"name": "Authorization", "value": "[REDACTED]"}))
+		Expect(recorded["postData"]).To(HaveKeyWithValue("text", `{"nested":{"identity":"admin","password":"[REDACTED]"},"passphrase":"[REDACTED]"}`))
+
+		recorded = entry(1)["request"].(map[string]interface{})
+		Expect(recorded["postData"]).To(HaveKeyWithValue("text", "grant_type=password&password=%5BREDACTED%5D&username=admin"))
+	})
+
+	It("redacts the keys and credentials of manifests", func() {
+		fakeClient.DoReturns(&http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       ioutil.NopCloser(strings.NewReader(`{"manifest":{"name":"cf-guid","properties":{"uaa":{"jwt":{"policy":{"keys":{"key-1":{"signingKey":"some-signing-key"}}}}},"cc":{"db_encryption_key":"some-encryption-key","credentials":["some-credential"],"instances":2}}}}`)),
+		}, nil)
+
+		request, err := http.NewRequest("GET", "https://opsman.example.com/api/v0/staged/products/cf-guid/manifest", nil)
+		Expect(err).ToNot(HaveOccurred())
+		do(request)
+
+		fakeClient.DoReturns(&http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/x-yaml"}},
+			Body:       ioutil.NopCloser(strings.NewReader("name: cf-guid\nproperties:\n  cc:\n    db_encryption_key: some-encryption-key\n    instances: 2\n")),
+		}, nil)
+
+		request, err = http.NewRequest("GET", "https://opsman.example.com/api/v0/deployed/products/cf-guid/manifest", nil)
+		Expect(err).ToNot(HaveOccurred())
+		do(request)
+
+		content := entry(0)["response"].(map[string]interface{})["content"].(map[string]interface{})
+		Expect(content["text"]).To(MatchJSON(`{"manifest":{"name":"cf-guid","properties":{"uaa":{"jwt":{"policy":{"keys":"[REDACTED]"}}},"cc":{"db_encryption_key":"[REDACTED]","credentials":"[REDACTED]","instances":2}}}}`))
+
+		content = entry(1)["response"].(map[string]interface{})["content"].(map[string]interface{})
+		Expect(content["text"]).To(MatchYAML("name: cf-guid\nproperties:\n  cc:\n    db_encryption_key: '[REDACTED]'\n    instances: 2\n"))
+
+		var output bytes.Buffer
+		Expect(recorder.Write(&output)).To(Succeed())
+		Expect(output.String()).ToNot(ContainSubstring("some-signing-key"))
+		Expect(output.String()).ToNot(ContainSubstring("some-encryption-key"))
+		Expect(output.String()).ToNot(ContainSubstring("some-credential"))
+	})
+
+	It("does not record the bodies whose secrets cannot be redacted", func() {
+		largeBody := strings.Repeat("a", 1024*1024+1)
+
+		fakeClient.DoStub = func(request *http.Request) (*http.Response, error) {
+			_, err := ioutil.ReadAll(request.Body)
+			Expect(err).ToNot(HaveOccurred())
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{"Content-Type": {"text/plain"}},
+				Body:       ioutil.NopCloser(strings.NewReader("password: some-password")),
+			}, nil
+		}
+
+		request, err := http.NewRequest("POST", "https://opsman.example.com/api/v0/large", strings.NewReader(`{"large":"`+largeBody+`"}`))
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Set("Content-Type", "application/json")
+		do(request)
+
+		request, err = http.NewRequest("POST", "https://opsman.example.com/api/v0/installation_asset_collection", bytes.NewReader([]byte{0xff, 0xfe, 0x00}))
+		Expect(err).ToNot(HaveOccurred())
+		request.Header.Set("Content-Type", "application/octet-stream")
+		do(request)
+
+		postData := entry(0)["request"].(map[string]interface{})["postData"].(map[string]interface{})
+		Expect(postData["text"]).To(BeEmpty())
+		Expect(postData["comment"]).To(Equal("body of 1048589 bytes over the limit of 1048576 bytes not recorded, as its secrets could not be redacted"))
+
+		content := entry(0)["response"].(map[string]interface{})["content"].(map[string]interface{})
+		Expect(content["size"]).To(BeNumerically("==", 23))
+		Expect(content).ToNot(HaveKey("text"))
+		Expect(content["comment"]).To(Equal("text/plain body of 23 bytes not recorded, as its secrets could not be redacted"))
+
+		postData = entry(1)["request"].(map[string]interface{})["postData"].(map[string]interface{})
+		Expect(postData["text"]).To(BeEmpty())
+		Expect(postData["comment"]).To(Equal("application/octet-stream body of 3 bytes not recorded, as its secrets could not be redacted"))
+	})
+
+	It("records the failed requests", func() {
+		fakeClient.DoReturns(nil, errors.New("connection refused"))
+
+		request, err := http.NewRequest("GET", "https://opsman.example.com/api/v0/info", nil)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = harTransport.RoundTrip(request)
+		Expect(err).To(MatchError("connection refused"))
+
+		entry := entry(0)
+		Expect(entry["comment"]).To(Equal("connection refused"))
+		Expect(entry["response"]).To(HaveKeyWithValue("status", BeNumerically("==", 0)))
+	})
+
+	It("writes an empty archive without requests", func() {
+		Expect(archive()["entries"]).To(BeEmpty())
+	})
+
+	When("it is the transport of the clients of om", func() {
+		var server *ghttp.Server
+
+		BeforeEach(func() {
+			server = ghttp.NewTLSServer()
+			server.RouteToHandler("PUT", "/api/v0/unlock", ghttp.RespondWith(http.StatusOK, `{}`))
+			server.RouteToHandler("GET", "/login/ensure_availability", ghttp.RespondWith(http.StatusFound, "", http.Header{"Location": {"/auth/cloudfoundry"}}))
+			server.RouteToHandler("POST", "/uaa/oauth/token", ghttp.RespondWith(http.StatusOK, `{"access_token": "some-token", "token_type": "bearer", "expires_in": 3600}`, http.Header{"Content-Type": {"application/json"}}))
+			server.AppendHandlers(
+				ghttp.RespondWith(http.StatusServiceUnavailable, ""),
+				ghttp.RespondWith(http.StatusOK, `{"products": []}`, http.Header{"Content-Type": {"application/json"}}),
+			)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("records the requests for tokens, to unlock and the retries, with their secrets redacted", func() {
+			unauthenticatedClient, err := network.NewUnauthenticatedClient(server.URL(), true, "", "", "", 5*time.Second, 30*time.Second, recorder)
+			Expect(err).ToNot(HaveOccurred())
+			oauthClient, err := network.NewOAuthClient(server.URL(), "admin", "some-password", "", "", true, "", "", "", 5*time.Second, 30*time.Second, nil, recorder)
+			Expect(err).ToNot(HaveOccurred())
+
+			retryClient := network.NewRetryClient(oauthClient, 1, time.Millisecond, ioutil.Discard)
+			client := network.NewDecryptClient(retryClient, unauthenticatedClient, "some-passphrase", ioutil.Discard)
+
+			request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+			Expect(err).ToNot(HaveOccurred())
+			response, err := client.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(response.Body.Close()).To(Succeed())
+
+			var requests []string
+			for _, entry := range archive()["entries"].([]interface{}) {
+				entry := entry.(map[string]interface{})
+				recorded := entry["request"].(map[string]interface{})
+				status := entry["response"].(map[string]interface{})["status"]
+				requests = append(requests, fmt.Sprintf("%s %s %v", recorded["method"], strings.TrimPrefix(recorded["url"].(string), server.URL()), status))
+			}
+			Expect(requests).To(Equal([]string{
+				"PUT /api/v0/unlock 200",
+				"GET /login/ensure_availability 302",
+				"POST /uaa/oauth/token 200",
+				"GET /api/v0/staged/products 503",
+				"POST /uaa/oauth/token 200",
+				"GET /api/v0/staged/products 200",
+			}))
+
+			unlock := entry(0)["request"].(map[string]interface{})
+			Expect(unlock["postData"]).To(HaveKeyWithValue("text", `{"passphrase":"[REDACTED]"}`))
+
+			token := entry(2)["request"].(map[string]interface{})
+			Expect(token["headers"]).To(ContainElement(map[string]interface{}{"name": "Authorization", "value": "[REDACTED]"}))
+			Expect(token["postData"]).To(HaveKeyWithValue("text", ContainSubstring("password=%5BREDACTED%5D")))
+
+			products := entry(3)["request"].(map[string]interface{})
+			Expect(products["headers"]).To(ContainElement(map[string]interface{}{"name": "Authorization", "value": "[REDACTED]"}))
+
+			var output bytes.Buffer
+			Expect(recorder.Write(&output)).To(Succeed())
+			Expect(output.String()).ToNot(ContainSubstring("some-password"))
+			Expect(output.String()).ToNot(ContainSubstring("some-passphrase"))
+			Expect(output.String()).ToNot(ContainSubstring("some-token"))
+		})
+	})
+})
@@ -11,7 +11,11 @@ import (
 	"time"
 )
 
-func newHTTPClient(insecureSkipVerify bool, caCert string, requestTimeout time.Duration, connectTimeout time.Duration) (*http.Client, error) {
+// NewHTTPClient returns a client that trusts the given CA certificate,
+// presents the given client certificate, and does not follow redirects.
+// Certificates and keys are paths or PEM values.
+// With a recorder, it records its requests and responses to an HTTP Archive.
+func NewHTTPClient(insecureSkipVerify bool, caCert string, clientCert string, clientKey string, requestTimeout time.Duration, connectTimeout time.Duration, recorder *HARRecorder) (*http.Client, error) {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: insecureSkipVerify,
 		MinVersion:         tls.VersionTLS12,
@@ -20,19 +24,29 @@ func newHTTPClient(insecureSkipVerify bool, caCert string, requestTimeout time.D
 	if err != nil {
 		return nil, err
 	}
+	err = setClientCert(clientCert, clientKey, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var transport http.RoundTripper = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: tlsConfig,
+		Dial: (&net.Dialer{
+			Timeout:   connectTimeout,
+			KeepAlive: 30 * time.Second,
+		}).Dial,
+	}
+	if recorder != nil {
+		transport = NewHARTransport(transport, recorder)
+	}
+
 	return &http.Client{
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
-		Transport: &http.Transport{
-			Proxy:           http.ProxyFromEnvironment,
-			TLSClientConfig: tlsConfig,
-			Dial: (&net.Dialer{
-				Timeout:   connectTimeout,
-				KeepAlive: 30 * time.Second,
-			}).Dial,
-		},
-		Timeout: requestTimeout,
+		Transport: transport,
+		Timeout:   requestTimeout,
 	}, nil
 }
 
@@ -59,3 +73,43 @@ func setCACert(caCert string, tlsConfig *tls.Config) error {
 	tlsConfig.RootCAs = caCertPool
 	return nil
 }
+
+func setClientCert(clientCert string, clientKey string, tlsConfig *tls.Config) error {
+	if clientCert == "" && clientKey == "" {
+		return nil
+	}
+	if clientCert == "" || clientKey == "" {
+		return fmt.Errorf("both a client cert and a client key are required")
+	}
+
+	certPEM, err := readPEM(clientCert)
+	if err != nil {
+		return fmt.Errorf("could not load client cert from file: %s", err)
+	}
+	keyPEM, err := readPEM(clientKey)
+	if err != nil {
+		return fmt.Errorf("could not load client key from file: %s", err)
+	}
+
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	if err != nil {
+		return fmt.Errorf("could not use client cert: %s", err)
+	}
+
+	tlsConfig.Certificates = []tls.Certificate{certificate}
+	return nil
+}
+
+// readPEM returns a PEM value as is, or reads it from the path.
+func readPEM(value string) (string, error) {
+	if strings.Contains(value, "BEGIN") {
+		return value, nil
+	}
+
+	contents, err := ioutil.ReadFile(value)
+	if err != nil {
+		return "", err
+	}
+
+	return string(contents), nil
+}
@@ -0,0 +1,99 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// PasscodeURL is where the users of an Ops Manager with SAML get
+// the one-time passcode to log in with.
+func (oc OAuthClient) PasscodeURL() (string, error) {
+	targetURL, err := oc.targetURL()
+	if err != nil {
+		return "", err
+	}
+
+	targetURL.Path = "/uaa/passcode"
+	return targetURL.String(), nil
+}
+
+// Login exchanges a one-time passcode for tokens with the passcode grant of UAA,
+// and caches them for the requests made without a username or a client id,
+// which refresh them once they expire.
+func (oc OAuthClient) Login(passcode string) error {
+	if oc.username != "" || oc.oauthConfigCC.ClientID != "" {
+		return errors.New("a username or a client id is already set: logging in is only for the users without credentials")
+	}
+
+	if oc.tokenCache == nil {
+		return errors.New("could not find a directory to store the login in: set the token-cache flag")
+	}
+
+	targetURL, err := oc.targetURL()
+	if err != nil {
+		return err
+	}
+	targetURL.Path = "/uaa/oauth/token"
+
+	form := url.Values{
+		"grant_type": {"password"},
+		"passcode":   {passcode},
+	}
+	request, err := http.NewRequest("POST", targetURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(oc.oauthConfig.ClientID, oc.oauthConfig.ClientSecret)
+
+	response, err := oc.httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("could not log in to %s: %s", oc.target, err)
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return fmt.Errorf("could not log in to %s: %s", oc.target, err)
+	}
+
+	var tokenResponse struct {
+		AccessToken      string `json:"access_token"`
+		RefreshToken     string `json:"refresh_token"`
+		TokenType        string `json:"token_type"`
+		ExpiresIn        int    `json:"expires_in"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(contents, &tokenResponse)
+
+	if response.StatusCode != http.StatusOK {
+		if tokenResponse.ErrorDescription != "" {
+			return fmt.Errorf("could not log in to %s with the passcode: unexpected response %d: %s", oc.target, response.StatusCode, tokenResponse.ErrorDescription)
+		}
+		return fmt.Errorf("could not log in to %s with the passcode: unexpected response %d", oc.target, response.StatusCode)
+	}
+
+	if tokenResponse.AccessToken == "" {
+		return fmt.Errorf("could not log in to %s with the passcode: the response has no access token", oc.target)
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  tokenResponse.AccessToken,
+		RefreshToken: tokenResponse.RefreshToken,
+		TokenType:    tokenResponse.TokenType,
+	}
+	if tokenResponse.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tokenResponse.ExpiresIn) * time.Second)
+	}
+
+	return oc.tokenCache.Store(oc.tokenCacheKey(), token)
+}
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"syscall"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -21,12 +22,14 @@ const TOKEN_ATTEMPT_COUNT = 3
 type OAuthClient struct {
 	oauthConfig   *oauth2.Config
 	oauthConfigCC *clientcredentials.Config
+	httpClient    *http.Client
 	jar           *cookiejar.Jar
 	context       context.Context
 	username      string
 	password      string
 	target        string
 	timeout       time.Duration
+	tokenCache    *TokenCache
 }
 
 func NewOAuthClient(
@@ -34,8 +37,11 @@ func NewOAuthClient(
 	clientID, clientSecret string,
 	insecureSkipVerify bool,
 	caCert string,
+	clientCert, clientKey string,
 	connectTimeout time.Duration,
 	requestTimeout time.Duration,
+	tokenCache *TokenCache,
+	recorder *HARRecorder,
 ) (OAuthClient, error) {
 	conf := &oauth2.Config{
 		ClientID:     "opsman",
@@ -48,7 +54,7 @@ func NewOAuthClient(
 		ClientSecret: clientSecret,
 	}
 
-	httpclient, err := newHTTPClient(insecureSkipVerify, caCert, requestTimeout, connectTimeout)
+	httpclient, err := NewHTTPClient(insecureSkipVerify, caCert, clientCert, clientKey, requestTimeout, connectTimeout, recorder)
 	if err != nil {
 		return OAuthClient{}, err
 	}
@@ -59,17 +65,60 @@ func NewOAuthClient(
 	return OAuthClient{
 		oauthConfig:   conf,
 		oauthConfigCC: confCC,
+		httpClient:    httpclient,
 		context:       insecureContext,
 		username:      username,
 		password:      password,
 		target:        target,
 		timeout:       requestTimeout,
+		tokenCache:    tokenCache,
 	}, nil
 }
 
 func (oc OAuthClient) Do(request *http.Request) (*http.Response, error) {
-	var client *http.Client
+	targetURL, err := oc.targetURL()
+	if err != nil {
+		return nil, err
+	}
+
+	targetURL.Path = "/uaa/oauth/token"
+	oc.oauthConfigCC.TokenURL = targetURL.String()
+	oc.oauthConfig.Endpoint.TokenURL = targetURL.String()
+
+	client, err := oc.authenticatedClient()
+	if err != nil {
+		return nil, err
+	}
+
+	request.URL.Scheme = targetURL.Scheme
+	request.URL.Host = targetURL.Host
+
+	// only a request without a body can be sent again
+	if request.Method == "GET" {
+		response, err := client.Do(request)
+		if err != nil || oc.tokenCache == nil || response.StatusCode != http.StatusUnauthorized {
+			return response, err
+		}
 
+		// the cached token has been revoked, so forget it and try again with a new one
+		_ = response.Body.Close()
+		err = oc.tokenCache.Delete(oc.tokenCacheKey())
+		if err != nil {
+			return nil, err
+		}
+
+		client, err = oc.authenticatedClient()
+		if err != nil {
+			return nil, err
+		}
+
+		return client.Do(request)
+	}
+
+	return client.Do(request)
+}
+
+func (oc OAuthClient) targetURL() (*url.URL, error) {
 	if oc.target == "" {
 		return nil, fmt.Errorf("target flag is required. Run `om help` for more info.")
 	}
@@ -90,11 +139,20 @@ func (oc OAuthClient) Do(request *http.Request) (*http.Response, error) {
 		return nil, fmt.Errorf("could not parse target url: %s", err)
 	}
 
-	targetURL.Path = "/uaa/oauth/token"
-	oc.oauthConfigCC.TokenURL = targetURL.String()
-	oc.oauthConfig.Endpoint.TokenURL = targetURL.String()
+	return targetURL, nil
+}
 
-	if oc.oauthConfigCC.ClientID != "" {
+func (oc OAuthClient) authenticatedClient() (*http.Client, error) {
+	var client *http.Client
+
+	if oc.tokenCache != nil {
+		token, err := oc.cachedToken()
+		if err != nil {
+			return nil, err
+		}
+
+		client = oauth2.NewClient(oc.context, oauth2.StaticTokenSource(token))
+	} else if oc.oauthConfigCC.ClientID != "" {
 		client = oc.oauthConfigCC.Client(oc.context)
 	} else {
 		token, err := retrieveTokenWithRetry(oc.oauthConfig, oc.context, oc.username, oc.password)
@@ -111,15 +169,46 @@ func (oc OAuthClient) Do(request *http.Request) (*http.Response, error) {
 		client.Jar = oc.jar
 	}
 
-	request.URL.Scheme = targetURL.Scheme
-	request.URL.Host = targetURL.Host
+	return client, nil
+}
 
-	// we only want to retry non-modifying actions
-	if request.Method == "GET" {
-		return httpResponseWithRetry(client, request)
+func (oc OAuthClient) tokenCacheKey() string {
+	if oc.oauthConfigCC.ClientID != "" {
+		return TokenCacheKey(oc.target, oc.oauthConfigCC.ClientID, oc.oauthConfigCC.ClientSecret)
 	}
 
-	return client.Do(request)
+	return TokenCacheKey(oc.target, oc.username, oc.password)
+}
+
+func (oc OAuthClient) cachedToken() (*oauth2.Token, error) {
+	return oc.tokenCache.Token(oc.tokenCacheKey(), func(cached *oauth2.Token) (*oauth2.Token, error) {
+		if oc.oauthConfigCC.ClientID != "" {
+			token, err := oc.oauthConfigCC.Token(oc.context)
+			if err != nil {
+				return nil, fmt.Errorf("token could not be retrieved from target url: %s", err)
+			}
+
+			return token, nil
+		}
+
+		if cached != nil && cached.RefreshToken != "" {
+			token, err := oc.oauthConfig.TokenSource(oc.context, &oauth2.Token{RefreshToken: cached.RefreshToken}).Token()
+			if err == nil {
+				return token, nil
+			}
+		}
+
+		// without credentials, the token is that of `om login`,
+		// and it cannot be renewed once it cannot be refreshed
+		if oc.username == "" {
+			if cached == nil {
+				return nil, fmt.Errorf("not logged in to %s: set a username and password, or a client id and secret, or run `om login`", oc.target)
+			}
+			return nil, fmt.Errorf("the login to %s has expired: run `om login` again", oc.target)
+		}
+
+		return retrieveTokenWithRetry(oc.oauthConfig, oc.context, oc.username, oc.password)
+	})
 }
 
 func retrieveTokenWithRetry(config *oauth2.Config, ctx context.Context, username, password string) (*oauth2.Token, error) {
@@ -148,24 +237,13 @@ func retrieveTokenWithRetry(config *oauth2.Config, ctx context.Context, username
 	return token, nil
 }
 
-func httpResponseWithRetry(client *http.Client, request *http.Request) (*http.Response, error) {
-retry:
-	resp, err := client.Do(request)
-	if client.Timeout == 0 {
-		if CanRetry(err) {
-			goto retry
-		}
-	}
-
-	if err != nil {
-		return nil, err
-	}
-
-	return resp, nil
-}
-
 func CanRetry(err error) bool {
 	if err != nil {
+		// the connection was dropped while sending a request body, such as an upload
+		if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return true
+		}
+
 		for errors.Unwrap(err) != nil {
 			err = errors.Unwrap(err)
 		}
@@ -6,12 +6,19 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/http/httputil"
 	"net/url"
+	"os"
 	"strings"
+	"syscall"
 
 	"github.com/pivotal-cf/om/network"
 
@@ -63,7 +70,7 @@ var _ = Describe("OAuthClient", func() {
 
 	Describe("Do", func() {
 		It("makes a request with authentication", func() {
-			client, err := network.NewOAuthClient(server.URL, "opsman-username", "opsman-password", "", "", true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+			client, err := network.NewOAuthClient(server.URL, "opsman-username", "opsman-password", "", "", true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(callCount).To(Equal(0))
@@ -98,7 +105,7 @@ var _ = Describe("OAuthClient", func() {
 		})
 
 		It("makes a request with client credentials", func() {
-			client, err := network.NewOAuthClient(server.URL, "", "", "client_id", "client_secret", true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+			client, err := network.NewOAuthClient(server.URL, "", "", "client_id", "client_secret", true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(callCount).To(Equal(0))
@@ -136,7 +143,7 @@ var _ = Describe("OAuthClient", func() {
 			nonTLS12Server.Config.ErrorLog = log.New(GinkgoWriter, "", 0)
 			defer nonTLS12Server.Close()
 
-			client, err := network.NewOAuthClient(nonTLS12Server.URL, "", "", "client_id", "client_secret", true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+			client, err := network.NewOAuthClient(nonTLS12Server.URL, "", "", "client_id", "client_secret", true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			req, err := http.NewRequest("GET", "/some/path", strings.NewReader("request-body"))
@@ -154,7 +161,7 @@ var _ = Describe("OAuthClient", func() {
 				noScheme.Scheme = ""
 				finalURL := noScheme.String()
 
-				client, err := network.NewOAuthClient(finalURL, "opsman-username", "opsman-password", "", "", true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+				client, err := network.NewOAuthClient(finalURL, "opsman-username", "opsman-password", "", "", true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				req, err := http.NewRequest("GET", "/some/path", strings.NewReader("request-body"))
@@ -170,7 +177,7 @@ var _ = Describe("OAuthClient", func() {
 		When("insecureSkipVerify is configured", func() {
 			When("it is set to false", func() {
 				It("throws an error for invalid certificates", func() {
-					client, err := network.NewOAuthClient(server.URL, "opsman-username", "opsman-password", "", "", false, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+					client, err := network.NewOAuthClient(server.URL, "opsman-username", "opsman-password", "", "", false, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
 					Expect(err).ToNot(HaveOccurred())
 
 					req, err := http.NewRequest("GET", "/some/path", strings.NewReader("request-body"))
@@ -183,7 +190,7 @@ var _ = Describe("OAuthClient", func() {
 
 			When("it is set to true", func() {
 				It("does not verify certificates", func() {
-					client, err := network.NewOAuthClient(server.URL, "opsman-username", "opsman-password", "", "", true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+					client, err := network.NewOAuthClient(server.URL, "opsman-username", "opsman-password", "", "", true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
 					Expect(err).ToNot(HaveOccurred())
 
 					req, err := http.NewRequest("GET", "/some/path", strings.NewReader("request-body"))
@@ -207,7 +214,10 @@ var _ = Describe("OAuthClient", func() {
 					"", "",
 					false,
 					pemCert,
+					"", "",
 					time.Duration(5)*time.Second, time.Duration(30)*time.Second,
+					nil,
+					nil,
 				)
 
 				Expect(err).ToNot(HaveOccurred())
@@ -230,7 +240,10 @@ var _ = Describe("OAuthClient", func() {
 					"", "",
 					false,
 					pemCert,
+					"", "",
 					time.Duration(5)*time.Second, time.Duration(30)*time.Second,
+					nil,
+					nil,
 				)
 
 				Expect(err).ToNot(HaveOccurred())
@@ -243,6 +256,165 @@ var _ = Describe("OAuthClient", func() {
 			})
 		})
 
+		When("supporting a client cert", func() {
+			BeforeEach(func() {
+				server.TLS.ClientAuth = tls.RequireAnyClientCert
+			})
+
+			It("presents it to UAA and Ops Manager", func() {
+				cert, err := x509.ParseCertificate(server.TLS.Certificates[0].Certificate[0])
+				Expect(err).ToNot(HaveOccurred())
+				pemCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+				key, err := x509.MarshalPKCS8PrivateKey(server.TLS.Certificates[0].PrivateKey)
+				Expect(err).ToNot(HaveOccurred())
+				pemKey := writeFile(string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})))
+
+				client, err := network.NewOAuthClient(
+					server.URL,
+					"opsman-username", "opsman-password",
+					"", "",
+					false,
+					pemCert,
+					pemCert, pemKey,
+					time.Duration(5)*time.Second, time.Duration(30)*time.Second,
+					nil,
+					nil,
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				req, err := http.NewRequest("GET", "/some/path", nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				resp, err := client.Do(req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+				Expect(callCount).To(Equal(1))
+			})
+
+			It("fails to get a token without it", func() {
+				client, err := network.NewOAuthClient(server.URL, "opsman-username", "opsman-password", "", "", true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				req, err := http.NewRequest("GET", "/some/path", nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = client.Do(req)
+				Expect(err).To(MatchError(ContainSubstring("token could not be retrieved from target url")))
+				Expect(callCount).To(Equal(0))
+			})
+
+			It("errors with a client cert without a key", func() {
+				_, err := network.NewOAuthClient(server.URL, "opsman-username", "opsman-password", "", "", true, "", "some-cert", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
+				Expect(err).To(MatchError("both a client cert and a client key are required"))
+			})
+		})
+
+		When("a token cache is provided", func() {
+			var (
+				cacheDir     string
+				grantTypes   []string
+				expiresIn    int
+				rejectTokens map[string]bool
+				cacheServer  *httptest.Server
+			)
+
+			BeforeEach(func() {
+				var err error
+				cacheDir, err = ioutil.TempDir("", "token-cache")
+				Expect(err).ToNot(HaveOccurred())
+
+				grantTypes = nil
+				expiresIn = 3600
+				rejectTokens = map[string]bool{}
+
+				cacheServer = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+					switch req.URL.Path {
+					case "/uaa/oauth/token":
+						Expect(req.ParseForm()).To(Succeed())
+						grantTypes = append(grantTypes, req.Form.Get("grant_type"))
+
+						w.Header().Set("Content-Type", "application/json")
+						_, err := fmt.Fprintf(w, `{
+							"access_token": "token-%d",
+							"refresh_token": "refresh-token",
+							"token_type": "bearer",
+							"expires_in": %d
+						}`, len(grantTypes), expiresIn)
+						Expect(err).ToNot(HaveOccurred())
+					case "/some/path":
+						authHeader = req.Header.Get("Authorization")
+						if rejectTokens[authHeader] {
+							w.WriteHeader(http.StatusUnauthorized)
+							return
+						}
+
+						w.WriteHeader(http.StatusNoContent)
+					}
+				}))
+				cacheServer.Config.ErrorLog = log.New(GinkgoWriter, "", 0)
+			})
+
+			AfterEach(func() {
+				cacheServer.Close()
+				Expect(os.RemoveAll(cacheDir)).To(Succeed())
+			})
+
+			makeRequest := func(username, password, clientID, clientSecret string) {
+				client, err := network.NewOAuthClient(cacheServer.URL, username, password, clientID, clientSecret, true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, network.NewTokenCache(cacheDir), nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				req, err := http.NewRequest("GET", "/some/path", nil)
+				Expect(err).ToNot(HaveOccurred())
+
+				resp, err := client.Do(req)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusNoContent))
+			}
+
+			It("reuses the token across clients with the same credentials", func() {
+				makeRequest("opsman-username", "opsman-password", "", "")
+				makeRequest("opsman-username", "opsman-password", "", "")
+
+				Expect(grantTypes).To(Equal([]string{"password"}))
+				Expect(authHeader).To(Equal("Bearer token-1"))
+			})
+
+			It("reuses client credentials tokens", func() {
+				makeRequest("", "", "client_id", "client_secret")
+				makeRequest("", "", "client_id", "client_secret")
+
+				Expect(grantTypes).To(Equal([]string{"client_credentials"}))
+				Expect(authHeader).To(Equal("Bearer token-1"))
+			})
+
+			It("does not share tokens between different credentials", func() {
+				makeRequest("opsman-username", "opsman-password", "", "")
+				makeRequest("other-username", "other-password", "", "")
+
+				Expect(grantTypes).To(Equal([]string{"password", "password"}))
+				Expect(authHeader).To(Equal("Bearer token-2"))
+			})
+
+			It("uses the refresh token once the cached token has expired", func() {
+				expiresIn = 1
+
+				makeRequest("opsman-username", "opsman-password", "", "")
+				makeRequest("opsman-username", "opsman-password", "", "")
+
+				Expect(grantTypes).To(Equal([]string{"password", "refresh_token"}))
+				Expect(authHeader).To(Equal("Bearer token-2"))
+			})
+
+			It("fetches a new token when the cached one has been revoked", func() {
+				makeRequest("opsman-username", "opsman-password", "", "")
+				rejectTokens["Bearer token-1"] = true
+				makeRequest("opsman-username", "opsman-password", "", "")
+
+				Expect(grantTypes).To(Equal([]string{"password", "password"}))
+				Expect(authHeader).To(Equal("Bearer token-2"))
+			})
+		})
+
 		When("an error occurs", func() {
 			When("the initial token cannot be retrieved", func() {
 				var badServer *httptest.Server
@@ -255,7 +427,7 @@ var _ = Describe("OAuthClient", func() {
 				})
 
 				It("returns an error", func() {
-					client, err := network.NewOAuthClient(badServer.URL, "username", "password", "", "", true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+					client, err := network.NewOAuthClient(badServer.URL, "username", "password", "", "", true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
 					Expect(err).ToNot(HaveOccurred())
 
 					req, err := http.NewRequest("GET", "/some/path", strings.NewReader("request-body"))
@@ -268,7 +440,7 @@ var _ = Describe("OAuthClient", func() {
 
 			When("the target url is empty", func() {
 				It("returns an error", func() {
-					client, err := network.NewOAuthClient("", "username", "password", "", "", false, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+					client, err := network.NewOAuthClient("", "username", "password", "", "", false, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil, nil)
 					Expect(err).ToNot(HaveOccurred())
 
 					req, err := http.NewRequest("GET", "/some/path", strings.NewReader("request-body"))
@@ -281,3 +453,18 @@ var _ = Describe("OAuthClient", func() {
 		})
 	})
 })
+
+var _ = Describe("CanRetry", func() {
+	It("retries errors from a dropped connection", func() {
+		Expect(network.CanRetry(fmt.Errorf("upload failed: %w", io.EOF))).To(BeTrue())
+		Expect(network.CanRetry(fmt.Errorf("upload failed: %w", io.ErrUnexpectedEOF))).To(BeTrue())
+		Expect(network.CanRetry(&url.Error{Op: "Post", URL: "https://example.com", Err: &net.OpError{Op: "write", Err: os.NewSyscallError("write", syscall.EPIPE)}})).To(BeTrue())
+		Expect(network.CanRetry(&url.Error{Op: "Post", URL: "https://example.com", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}})).To(BeTrue())
+	})
+
+	It("does not retry other errors", func() {
+		Expect(network.CanRetry(nil)).To(BeFalse())
+		Expect(network.CanRetry(errors.New("request failed: unexpected response"))).To(BeFalse())
+		Expect(network.CanRetry(&url.Error{Op: "Post", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}})).To(BeFalse())
+	})
+})
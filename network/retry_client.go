@@ -0,0 +1,155 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const initialBackoff = time.Second
+
+// RetryClient retries the requests of idempotent methods
+// when the connection fails, or Ops Manager answers 502, 503 or 504,
+// as it does while restarting.
+// It waits between attempts for an exponential backoff with jitter,
+// up to the max backoff, or for the Retry-After of the response.
+type RetryClient struct {
+	client     httpClient
+	attempts   int
+	maxBackoff time.Duration
+	stderr     io.Writer
+}
+
+func NewRetryClient(client httpClient, attempts int, maxBackoff time.Duration, stderr io.Writer) RetryClient {
+	return RetryClient{
+		client:     client,
+		attempts:   attempts,
+		maxBackoff: maxBackoff,
+		stderr:     stderr,
+	}
+}
+
+func (rc RetryClient) Do(request *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		response, err := rc.client.Do(request)
+		if retry >= rc.attempts || !rc.canRetry(request, response, err) {
+			return response, err
+		}
+
+		backoff := rc.backoff(retry)
+		reason := ""
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = response.Status
+			if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+				backoff = retryAfter
+				if backoff > rc.maxBackoff {
+					backoff = rc.maxBackoff
+				}
+			}
+
+			_, _ = io.Copy(ioutil.Discard, response.Body)
+			_ = response.Body.Close()
+		}
+
+		fmt.Fprintf(rc.stderr, "%s %s failed (%s), retrying in %s (retry %d of %d)\n", request.Method, request.URL.Path, reason, backoff.Round(time.Millisecond), retry+1, rc.attempts)
+		time.Sleep(backoff)
+
+		if request.GetBody != nil {
+			request.Body, err = request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+// canRetry only retries requests that can be sent again:
+// those of idempotent methods, without a body or with one that can be read again.
+func (rc RetryClient) canRetry(request *http.Request, response *http.Response, err error) bool {
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return false
+	}
+
+	if err != nil {
+		return isConnectionError(err)
+	}
+
+	switch response.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// backoff doubles with each retry, up to the max backoff,
+// and is between half of it and all of it so concurrent clients spread out.
+func (rc RetryClient) backoff(retry int) time.Duration {
+	backoff := initialBackoff
+	for i := 0; i < retry && backoff < rc.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > rc.maxBackoff {
+		backoff = rc.maxBackoff
+	}
+
+	half := backoff / 2
+	if half <= 0 {
+		return backoff
+	}
+
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// isConnectionError is true when the connection could not be made,
+// as when Ops Manager is restarting, or was dropped.
+// A request that timed out is not sent again.
+func isConnectionError(err error) bool {
+	var opError *net.OpError
+	if errors.As(err, &opError) && opError.Op == "dial" {
+		return true
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) && netError.Timeout() {
+		return false
+	}
+
+	return CanRetry(err) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// parseRetryAfter reads the seconds, or the date, of a Retry-After header.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+
+	return 0, false
+}
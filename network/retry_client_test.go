@@ -0,0 +1,233 @@
+package network_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/network"
+	"github.com/pivotal-cf/om/network/fakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryClient", func() {
+	var (
+		client      *fakes.HttpClient
+		stderr      *gbytes.Buffer
+		retryClient network.RetryClient
+	)
+
+	respond := func(statusCode int, header http.Header) *http.Response {
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			StatusCode: statusCode,
+			Status:     http.StatusText(statusCode),
+			Header:     header,
+			Body:       ioutil.NopCloser(strings.NewReader("some-body")),
+		}
+	}
+
+	connectionRefused := &url.Error{Op: "Get", URL: "https://example.com", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}
+
+	BeforeEach(func() {
+		client = &fakes.HttpClient{}
+		stderr = gbytes.NewBuffer()
+		retryClient = network.NewRetryClient(client, 3, time.Millisecond, stderr)
+	})
+
+	It("does not retry a successful request", func() {
+		client.DoReturns(respond(http.StatusOK, nil), nil)
+
+		request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err := retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(client.DoCallCount()).To(Equal(1))
+	})
+
+	It("retries idempotent requests answered 502, 503 or 504, and logs each retry", func() {
+		client.DoReturnsOnCall(0, respond(http.StatusBadGateway, nil), nil)
+		client.DoReturnsOnCall(1, respond(http.StatusServiceUnavailable, nil), nil)
+		client.DoReturnsOnCall(2, respond(http.StatusGatewayTimeout, nil), nil)
+		client.DoReturnsOnCall(3, respond(http.StatusOK, nil), nil)
+
+		request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err := retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(client.DoCallCount()).To(Equal(4))
+
+		Expect(stderr).To(gbytes.Say(`GET /api/v0/staged/products failed \(Bad Gateway\), retrying in .* \(retry 1 of 3\)`))
+		Expect(stderr).To(gbytes.Say(`GET /api/v0/staged/products failed \(Service Unavailable\), retrying in .* \(retry 2 of 3\)`))
+		Expect(stderr).To(gbytes.Say(`GET /api/v0/staged/products failed \(Gateway Timeout\), retrying in .* \(retry 3 of 3\)`))
+	})
+
+	It("retries connection errors", func() {
+		client.DoReturnsOnCall(0, nil, connectionRefused)
+		client.DoReturnsOnCall(1, respond(http.StatusOK, nil), nil)
+
+		request, err := http.NewRequest("DELETE", "/api/v0/staged/products/some-guid", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err := retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(client.DoCallCount()).To(Equal(2))
+		Expect(stderr).To(gbytes.Say(`DELETE /api/v0/staged/products/some-guid failed \(.*connection refused\)`))
+	})
+
+	It("does not retry requests that timed out", func() {
+		client.DoReturns(nil, &url.Error{Op: "Get", URL: "https://example.com", Err: timeoutError{}})
+
+		request, err := http.NewRequest("GET", "/api/v0/installation_asset_collection", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = retryClient.Do(request)
+		Expect(err).To(HaveOccurred())
+		Expect(client.DoCallCount()).To(Equal(1))
+	})
+
+	It("returns the last response once out of attempts", func() {
+		client.DoReturns(respond(http.StatusServiceUnavailable, nil), nil)
+
+		request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err := retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(client.DoCallCount()).To(Equal(4))
+	})
+
+	It("returns the last error once out of attempts", func() {
+		client.DoReturns(nil, connectionRefused)
+
+		request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = retryClient.Do(request)
+		Expect(err).To(MatchError(connectionRefused))
+		Expect(client.DoCallCount()).To(Equal(4))
+	})
+
+	It("does not retry when there are no attempts", func() {
+		retryClient = network.NewRetryClient(client, 0, time.Millisecond, stderr)
+		client.DoReturns(respond(http.StatusServiceUnavailable, nil), nil)
+
+		request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.DoCallCount()).To(Equal(1))
+	})
+
+	It("does not retry non-idempotent methods", func() {
+		client.DoReturns(respond(http.StatusServiceUnavailable, nil), nil)
+
+		request, err := http.NewRequest("POST", "/api/v0/installations", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err := retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(client.DoCallCount()).To(Equal(1))
+	})
+
+	It("does not retry other responses or errors", func() {
+		client.DoReturnsOnCall(0, respond(http.StatusInternalServerError, nil), nil)
+		client.DoReturnsOnCall(1, nil, errors.New("some error"))
+
+		request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		response, err := retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusInternalServerError))
+
+		_, err = retryClient.Do(request)
+		Expect(err).To(MatchError("some error"))
+		Expect(client.DoCallCount()).To(Equal(2))
+	})
+
+	It("sends the body again", func() {
+		var bodies []string
+		client.DoStub = func(request *http.Request) (*http.Response, error) {
+			body, err := ioutil.ReadAll(request.Body)
+			Expect(err).ToNot(HaveOccurred())
+			bodies = append(bodies, string(body))
+
+			if len(bodies) == 1 {
+				return respond(http.StatusBadGateway, nil), nil
+			}
+			return respond(http.StatusOK, nil), nil
+		}
+
+		request, err := http.NewRequest("PUT", "/api/v0/staged/director/properties", strings.NewReader(`{"some":"body"}`))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(bodies).To(Equal([]string{`{"some":"body"}`, `{"some":"body"}`}))
+	})
+
+	It("does not retry a body that cannot be read again", func() {
+		client.DoReturns(respond(http.StatusServiceUnavailable, nil), nil)
+
+		request, err := http.NewRequest("PUT", "/api/v0/stemcells", ioutil.NopCloser(strings.NewReader("some-stemcell")))
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(client.DoCallCount()).To(Equal(1))
+	})
+
+	It("waits for the Retry-After of the response, up to the max backoff", func() {
+		retryClient = network.NewRetryClient(client, 1, 100*time.Millisecond, stderr)
+		client.DoReturnsOnCall(0, respond(http.StatusServiceUnavailable, http.Header{"Retry-After": []string{"120"}}), nil)
+		client.DoReturnsOnCall(1, respond(http.StatusOK, nil), nil)
+
+		request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		start := time.Now()
+		_, err = retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Since(start)).To(BeNumerically(">=", 100*time.Millisecond))
+		Expect(stderr).To(gbytes.Say(`retrying in 100ms`))
+	})
+
+	It("waits for a Retry-After shorter than the backoff", func() {
+		retryClient = network.NewRetryClient(client, 1, time.Minute, stderr)
+		client.DoReturnsOnCall(0, respond(http.StatusServiceUnavailable, http.Header{"Retry-After": []string{"0"}}), nil)
+		client.DoReturnsOnCall(1, respond(http.StatusOK, nil), nil)
+
+		request, err := http.NewRequest("GET", "/api/v0/staged/products", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = retryClient.Do(request)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(stderr).To(gbytes.Say(`retrying in 0s`))
+	})
+})
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "Client.Timeout exceeded while awaiting headers" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
@@ -0,0 +1,189 @@
+package network
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// A lock is only held while a token is fetched, so one older than
+// tokenCacheStaleLockAge was left behind by an om process that died.
+// It is shorter than tokenCacheLockTimeout, so that the processes
+// waiting for such a lock remove it instead of timing out.
+const (
+	tokenCacheLockRetryInterval = 100 * time.Millisecond
+	tokenCacheLockTimeout       = 30 * time.Second
+	tokenCacheStaleLockAge      = 20 * time.Second
+)
+
+// TokenCache persists UAA tokens on disk so that separate om invocations
+// against the same target and credentials can reuse them.
+type TokenCache struct {
+	dir string
+}
+
+func NewTokenCache(dir string) *TokenCache {
+	return &TokenCache{
+		dir: dir,
+	}
+}
+
+// TokenCacheKey identifies a cached token by target, principal (username or client id)
+// and the secret used to obtain it, so that changing credentials never reuses an old token.
+func TokenCacheKey(target, principal, secret string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{target, principal, secret}, "\x00")))
+	return fmt.Sprintf("%x", sum)
+}
+
+// Token returns the cached token for the key when it is still valid.
+// Otherwise, it calls fetch with the expired cached token (or nil if there is none),
+// stores the result and returns it.
+// The cache entry is locked for the duration so concurrent processes do not
+// request or refresh the same token at the same time.
+func (tc *TokenCache) Token(key string, fetch func(cached *oauth2.Token) (*oauth2.Token, error)) (*oauth2.Token, error) {
+	err := os.MkdirAll(tc.dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create token cache directory: %s", err)
+	}
+
+	unlock, err := tc.lock(key)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	cached := tc.read(key)
+	if cached != nil && cached.Valid() {
+		return cached, nil
+	}
+
+	token, err := fetch(cached)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tc.write(key, token)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// Store caches the token for the key, replacing any cached one.
+func (tc *TokenCache) Store(key string, token *oauth2.Token) error {
+	err := os.MkdirAll(tc.dir, 0700)
+	if err != nil {
+		return fmt.Errorf("could not create token cache directory: %s", err)
+	}
+
+	unlock, err := tc.lock(key)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return tc.write(key, token)
+}
+
+// Delete removes the cached token for the key, if any.
+func (tc *TokenCache) Delete(key string) error {
+	err := os.Remove(tc.path(key))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("could not remove cached token: %s", err)
+	}
+
+	return nil
+}
+
+func (tc *TokenCache) path(key string) string {
+	return filepath.Join(tc.dir, key+".json")
+}
+
+func (tc *TokenCache) read(key string) *oauth2.Token {
+	contents, err := ioutil.ReadFile(tc.path(key))
+	if err != nil {
+		return nil
+	}
+
+	var token oauth2.Token
+	err = json.Unmarshal(contents, &token)
+	if err != nil {
+		return nil
+	}
+
+	return &token
+}
+
+func (tc *TokenCache) write(key string, token *oauth2.Token) error {
+	contents, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("could not marshal token for cache: %s", err)
+	}
+
+	tempFile, err := ioutil.TempFile(tc.dir, key+".tmp")
+	if err != nil {
+		return fmt.Errorf("could not write token cache: %s", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	_, err = tempFile.Write(contents)
+	if err != nil {
+		_ = tempFile.Close()
+		return fmt.Errorf("could not write token cache: %s", err)
+	}
+
+	err = tempFile.Close()
+	if err != nil {
+		return fmt.Errorf("could not write token cache: %s", err)
+	}
+
+	err = os.Chmod(tempFile.Name(), 0600)
+	if err != nil {
+		return fmt.Errorf("could not write token cache: %s", err)
+	}
+
+	err = os.Rename(tempFile.Name(), tc.path(key))
+	if err != nil {
+		return fmt.Errorf("could not write token cache: %s", err)
+	}
+
+	return nil
+}
+
+func (tc *TokenCache) lock(key string) (func(), error) {
+	lockPath := filepath.Join(tc.dir, key+".lock")
+	deadline := time.Now().Add(tokenCacheLockTimeout)
+
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			_ = lockFile.Close()
+			return func() { _ = os.Remove(lockPath) }, nil
+		}
+
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("could not lock token cache: %s", err)
+		}
+
+		info, statErr := os.Stat(lockPath)
+		if statErr == nil && time.Since(info.ModTime()) > tokenCacheStaleLockAge {
+			_ = os.Remove(lockPath)
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("could not lock token cache: timed out waiting for another om process to release it")
+		}
+
+		time.Sleep(tokenCacheLockRetryInterval)
+	}
+}
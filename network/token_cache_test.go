@@ -0,0 +1,151 @@
+package network_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/pivotal-cf/om/network"
+	"golang.org/x/oauth2"
+)
+
+var _ = Describe("TokenCache", func() {
+	var (
+		dir   string
+		cache *network.TokenCache
+		key   string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "token-cache")
+		Expect(err).ToNot(HaveOccurred())
+
+		cache = network.NewTokenCache(filepath.Join(dir, "tokens"))
+		key = network.TokenCacheKey("https://example.com", "some-user", "some-password")
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("fetches and stores a token when nothing has been cached", func() {
+		var fetchedWith *oauth2.Token
+		token, err := cache.Token(key, func(cached *oauth2.Token) (*oauth2.Token, error) {
+			fetchedWith = cached
+			return &oauth2.Token{AccessToken: "some-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.AccessToken).To(Equal("some-token"))
+		Expect(fetchedWith).To(BeNil())
+
+		info, err := os.Stat(filepath.Join(dir, "tokens", key+".json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+	})
+
+	It("reuses a cached token that has not expired", func() {
+		_, err := cache.Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "some-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		token, err := network.NewTokenCache(filepath.Join(dir, "tokens")).Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			Fail("should not have fetched a new token")
+			return nil, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.AccessToken).To(Equal("some-token"))
+	})
+
+	It("passes an expired token to fetch so it can be refreshed", func() {
+		_, err := cache.Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "old-token", RefreshToken: "refresh-token", Expiry: time.Now().Add(-time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		var fetchedWith *oauth2.Token
+		token, err := cache.Token(key, func(cached *oauth2.Token) (*oauth2.Token, error) {
+			fetchedWith = cached
+			return &oauth2.Token{AccessToken: "new-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.AccessToken).To(Equal("new-token"))
+		Expect(fetchedWith.RefreshToken).To(Equal("refresh-token"))
+	})
+
+	It("keeps separate tokens for separate keys", func() {
+		otherKey := network.TokenCacheKey("https://example.com", "some-user", "other-password")
+		Expect(otherKey).ToNot(Equal(key))
+
+		_, err := cache.Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "some-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		token, err := cache.Token(otherKey, func(*oauth2.Token) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "other-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.AccessToken).To(Equal("other-token"))
+	})
+
+	It("forgets a deleted token", func() {
+		_, err := cache.Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "some-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cache.Delete(key)).To(Succeed())
+
+		token, err := cache.Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "new-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.AccessToken).To(Equal("new-token"))
+	})
+
+	It("releases the lock when fetching fails", func() {
+		_, err := cache.Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			return nil, errors.New("some-error")
+		})
+		Expect(err).To(MatchError("some-error"))
+
+		_, err = os.Stat(filepath.Join(dir, "tokens", key+".lock"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("ignores a stale lock left behind by another process", func() {
+		lockPath := filepath.Join(dir, "tokens", key+".lock")
+		Expect(os.MkdirAll(filepath.Dir(lockPath), 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(lockPath, nil, 0600)).To(Succeed())
+		staleTime := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(lockPath, staleTime, staleTime)).To(Succeed())
+
+		token, err := cache.Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "some-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.AccessToken).To(Equal("some-token"))
+	})
+
+	It("removes the lock left behind by another process before timing out waiting for it", func() {
+		lockPath := filepath.Join(dir, "tokens", key+".lock")
+		Expect(os.MkdirAll(filepath.Dir(lockPath), 0700)).To(Succeed())
+		Expect(ioutil.WriteFile(lockPath, nil, 0600)).To(Succeed())
+		lockTime := time.Now().Add(-25 * time.Second)
+		Expect(os.Chtimes(lockPath, lockTime, lockTime)).To(Succeed())
+
+		started := time.Now()
+		token, err := cache.Token(key, func(*oauth2.Token) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "some-token", Expiry: time.Now().Add(time.Hour)}, nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(token.AccessToken).To(Equal("some-token"))
+		Expect(time.Since(started)).To(BeNumerically("<", 10*time.Second))
+	})
+})
@@ -13,8 +13,8 @@ type UnauthenticatedClient struct {
 	client *http.Client
 }
 
-func NewUnauthenticatedClient(target string, insecureSkipVerify bool, caCert string, connectTimeout time.Duration, requestTimeout time.Duration) (UnauthenticatedClient, error) {
-	client, err := newHTTPClient(insecureSkipVerify, caCert, requestTimeout, connectTimeout)
+func NewUnauthenticatedClient(target string, insecureSkipVerify bool, caCert string, clientCert string, clientKey string, connectTimeout time.Duration, requestTimeout time.Duration, recorder *HARRecorder) (UnauthenticatedClient, error) {
+	client, err := NewHTTPClient(insecureSkipVerify, caCert, clientCert, clientKey, requestTimeout, connectTimeout, recorder)
 	if err != nil {
 		return UnauthenticatedClient{}, err
 	}
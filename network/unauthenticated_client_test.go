@@ -37,7 +37,7 @@ var _ = Describe("UnauthenticatedClient", func() {
 			}))
 			server.Config.ErrorLog = log.New(GinkgoWriter, "", 0)
 
-			client, _ := network.NewUnauthenticatedClient(server.URL, true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+			client, _ := network.NewUnauthenticatedClient(server.URL, true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil)
 
 			request, err := http.NewRequest("GET", "/path?query", strings.NewReader("request"))
 			Expect(err).ToNot(HaveOccurred())
@@ -78,7 +78,7 @@ var _ = Describe("UnauthenticatedClient", func() {
 				noScheme.Scheme = ""
 				finalURL := strings.Replace(noScheme.String(), "//", "", 1)
 
-				client, _ := network.NewUnauthenticatedClient(finalURL, true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+				client, _ := network.NewUnauthenticatedClient(finalURL, true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				request, err := http.NewRequest("GET", "/some/path", strings.NewReader("request-body"))
@@ -106,7 +106,7 @@ var _ = Describe("UnauthenticatedClient", func() {
 				Expect(err).ToNot(HaveOccurred())
 				pemCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
 
-				client, err := network.NewUnauthenticatedClient(server.URL, false, pemCert, time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+				client, err := network.NewUnauthenticatedClient(server.URL, false, pemCert, "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				request, err := http.NewRequest("GET", "/path?query", strings.NewReader("request"))
@@ -121,7 +121,7 @@ var _ = Describe("UnauthenticatedClient", func() {
 				Expect(err).ToNot(HaveOccurred())
 				pemCert := writeFile(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})))
 
-				client, err := network.NewUnauthenticatedClient(server.URL, false, pemCert, time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+				client, err := network.NewUnauthenticatedClient(server.URL, false, pemCert, "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil)
 				Expect(err).ToNot(HaveOccurred())
 
 				request, err := http.NewRequest("GET", "/path?query", strings.NewReader("request"))
@@ -132,13 +132,41 @@ var _ = Describe("UnauthenticatedClient", func() {
 			})
 		})
 
+		It("presents a client cert", func() {
+			server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				Expect(req.TLS.PeerCertificates).To(HaveLen(1))
+				w.WriteHeader(http.StatusTeapot)
+			}))
+			server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+			server.StartTLS()
+			server.Config.ErrorLog = log.New(GinkgoWriter, "", 0)
+			defer server.Close()
+
+			cert, err := x509.ParseCertificate(server.TLS.Certificates[0].Certificate[0])
+			Expect(err).ToNot(HaveOccurred())
+			pemCert := writeFile(string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})))
+			key, err := x509.MarshalPKCS8PrivateKey(server.TLS.Certificates[0].PrivateKey)
+			Expect(err).ToNot(HaveOccurred())
+			pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}))
+
+			client, err := network.NewUnauthenticatedClient(server.URL, true, "", pemCert, pemKey, time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			request, err := http.NewRequest("GET", "/path", nil)
+			Expect(err).ToNot(HaveOccurred())
+
+			response, err := client.Do(request)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusTeapot))
+		})
+
 		It("enforces minimum TLS version 1.2", func() {
 			nonTLS12Server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
 			nonTLS12Server.TLS.MaxVersion = tls.VersionTLS11
 			nonTLS12Server.Config.ErrorLog = log.New(GinkgoWriter, "", 0)
 			defer nonTLS12Server.Close()
 
-			client, _ := network.NewUnauthenticatedClient(nonTLS12Server.URL, true, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+			client, _ := network.NewUnauthenticatedClient(nonTLS12Server.URL, true, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil)
 
 			req, err := http.NewRequest("GET", "/some/path", strings.NewReader("request-body"))
 			Expect(err).ToNot(HaveOccurred())
@@ -150,7 +178,7 @@ var _ = Describe("UnauthenticatedClient", func() {
 		Context("failure cases", func() {
 			When("the target url cannot be parsed", func() {
 				It("returns an error", func() {
-					client, _ := network.NewUnauthenticatedClient("%%%", false, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+					client, _ := network.NewUnauthenticatedClient("%%%", false, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil)
 					_, err := client.Do(&http.Request{})
 					Expect(err).To(MatchError("could not parse target url: parse \"//%%%\": invalid URL escape \"%%%\""))
 				})
@@ -158,7 +186,7 @@ var _ = Describe("UnauthenticatedClient", func() {
 
 			When("the target url is empty", func() {
 				It("returns an error", func() {
-					client, _ := network.NewUnauthenticatedClient("", false, "", time.Duration(5)*time.Second, time.Duration(30)*time.Second)
+					client, _ := network.NewUnauthenticatedClient("", false, "", "", "", time.Duration(5)*time.Second, time.Duration(30)*time.Second, nil)
 					_, err := client.Do(&http.Request{})
 					Expect(err).To(MatchError("target flag is required. Run `om help` for more info."))
 				})
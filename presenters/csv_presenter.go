@@ -0,0 +1,182 @@
+package presenters
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+)
+
+// CSVPresenter writes the tables of the TablePresenter as CSV,
+// and gives diffs, logs and expiring certificates a row per item.
+type CSVPresenter struct {
+	TablePresenter
+	writer *csv.Writer
+}
+
+func NewCSVPresenter(stdout io.Writer) CSVPresenter {
+	writer := csv.NewWriter(stdout)
+
+	return CSVPresenter{
+		TablePresenter: NewTablePresenter(&csvTableWriter{writer: writer}, stdout),
+		writer:         writer,
+	}
+}
+
+func (c CSVPresenter) PresentBoshDiff(diff models.BoshDiff) {
+	rows := [][]string{{"product", "config", "name", "status", "diff"}}
+
+	if diff.Director != nil {
+		rows = append(rows,
+			[]string{"director", "manifest", "", diff.Director.Manifest.Status, diff.Director.Manifest.Diff},
+			[]string{"director", "cloud_config", "", diff.Director.CloudConfig.Status, diff.Director.CloudConfig.Diff},
+		)
+		for _, config := range diff.Director.RuntimeConfigs {
+			rows = append(rows, []string{"director", "runtime_config", config.Name, config.Status, config.Diff})
+		}
+		for _, config := range diff.Director.CPIConfigs {
+			rows = append(rows, []string{"director", "cpi_config", config.IAASConfigurationName, config.Status, config.Diff})
+		}
+	}
+
+	for _, product := range diff.Products {
+		rows = append(rows, []string{product.Name, "manifest", "", product.Manifest.Status, product.Manifest.Diff})
+		for _, config := range product.RuntimeConfigs {
+			rows = append(rows, []string{product.Name, "runtime_config", config.Name, config.Status, config.Diff})
+		}
+	}
+
+	c.writeAll(rows)
+}
+
+func (c CSVPresenter) PresentExpiringCertificates(certificates []api.ExpiringCertificate) {
+	rows := [][]string{{"location", "product_guid", "variable_path", "property_reference", "issuer", "valid_until", "configurable"}}
+
+	for _, certificate := range certificates {
+		rows = append(rows, []string{
+			certificate.Location,
+			certificate.ProductGUID,
+			certificate.VariablePath,
+			certificate.PropertyReference,
+			certificate.Issuer,
+			certificate.ValidUntil.Format(time.RFC3339),
+			strconv.FormatBool(certificate.Configurable),
+		})
+	}
+
+	c.writeAll(rows)
+}
+
+func (c CSVPresenter) PresentInstallationLog(log models.InstallationLog) {
+	c.writeAll([][]string{
+		{"id", "logs"},
+		{strconv.Itoa(log.ID), log.Logs},
+	})
+}
+
+func (c CSVPresenter) PresentInstallationSummary(summary models.InstallationSummary) {
+	rows := [][]string{{"stage", "type", "deployment", "started_at", "finished_at", "duration_seconds", "outcome", "exit_status", "first_error"}}
+
+	for _, stage := range summary.Stages {
+		var finishedAt, exitStatus, firstError string
+		if stage.FinishedAt != nil {
+			finishedAt = stage.FinishedAt.Format(time.RFC3339)
+		}
+		if stage.ExitStatus != nil {
+			exitStatus = strconv.Itoa(*stage.ExitStatus)
+		}
+		if len(stage.ErrorLines) > 0 {
+			firstError = stage.ErrorLines[0]
+		}
+
+		rows = append(rows, []string{
+			stage.Name,
+			stage.Type,
+			stage.Deployment,
+			stage.StartedAt.Format(time.RFC3339),
+			finishedAt,
+			strconv.FormatFloat(stage.DurationSeconds, 'f', -1, 64),
+			stage.Outcome,
+			exitStatus,
+			firstError,
+		})
+	}
+
+	c.writeAll(rows)
+}
+
+// PresentInstallationStats writes a row for all users, then a row per user.
+func (c CSVPresenter) PresentInstallationStats(stats models.InstallationStats) {
+	rows := [][]string{{"user", "installations", "succeeded", "failed", "success_rate", "mean_duration_seconds", "p95_duration_seconds"}}
+
+	rows = append(rows, csvInstallationCountsRow("", stats.InstallationCounts))
+	for _, user := range stats.Users {
+		rows = append(rows, csvInstallationCountsRow(user.User, user.InstallationCounts))
+	}
+
+	c.writeAll(rows)
+}
+
+func csvInstallationCountsRow(user string, counts models.InstallationCounts) []string {
+	return []string{
+		user,
+		strconv.Itoa(counts.Installations),
+		strconv.Itoa(counts.Succeeded),
+		strconv.Itoa(counts.Failed),
+		strconv.FormatFloat(counts.SuccessRate, 'f', -1, 64),
+		strconv.FormatFloat(counts.MeanDurationSeconds, 'f', -1, 64),
+		strconv.FormatFloat(counts.P95DurationSeconds, 'f', -1, 64),
+	}
+}
+
+func (c CSVPresenter) PresentPendingChanges(output api.PendingChangesOutput) {
+	rows := [][]string{{"product", "action", "errand"}}
+
+	for _, change := range output.ChangeList {
+		if len(change.Errands) == 0 {
+			rows = append(rows, []string{change.GUID, change.Action, ""})
+		}
+		for _, errand := range change.Errands {
+			rows = append(rows, []string{change.GUID, change.Action, errand.Name})
+		}
+	}
+
+	c.writeAll(rows)
+}
+
+func (c CSVPresenter) writeAll(rows [][]string) {
+	_ = c.writer.WriteAll(rows)
+}
+
+// csvTableWriter is a tableWriter writing each rendered table as CSV.
+type csvTableWriter struct {
+	writer *csv.Writer
+	header []string
+	rows   [][]string
+}
+
+func (w *csvTableWriter) SetHeader(header []string) {
+	w.header = header
+}
+
+func (w *csvTableWriter) Append(row []string) {
+	w.rows = append(w.rows, row)
+}
+
+func (w *csvTableWriter) Render() {
+	if len(w.header) > 0 {
+		_ = w.writer.Write(w.header)
+	}
+	_ = w.writer.WriteAll(w.rows)
+
+	w.header = nil
+	w.rows = nil
+}
+
+func (w *csvTableWriter) ClearRows()                {}
+func (w *csvTableWriter) SetAlignment(int)          {}
+func (w *csvTableWriter) SetAutoFormatHeaders(bool) {}
+func (w *csvTableWriter) SetAutoWrapText(bool)      {}
@@ -0,0 +1,108 @@
+package presenters_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+	"github.com/pivotal-cf/om/presenters"
+)
+
+var _ = Describe("CSVPresenter", func() {
+	var (
+		csvPresenter presenters.CSVPresenter
+		stdout       *gbytes.Buffer
+	)
+
+	BeforeEach(func() {
+		stdout = gbytes.NewBuffer()
+		csvPresenter = presenters.NewCSVPresenter(stdout)
+	})
+
+	Describe("PresentErrands", func() {
+		It("writes the table of the errands", func() {
+			csvPresenter.PresentErrands([]models.Errand{
+				{Name: "smoke-tests", PostDeployEnabled: "true"},
+				{Name: "drain", PreDeleteEnabled: "false"},
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`Name,Post Deploy Enabled,Pre Delete Enabled
+smoke-tests,true,
+drain,,false
+`))
+		})
+	})
+
+	Describe("PresentBoshDiff", func() {
+		It("writes a row per config", func() {
+			csvPresenter.PresentBoshDiff(models.BoshDiff{
+				Products: []models.ProductDiff{{
+					Name: "cf",
+					ProductDiff: api.ProductDiff{
+						Manifest: api.ManifestDiff{Status: "different", Diff: "- a\n+ b"},
+						RuntimeConfigs: []api.RuntimeConfigsDiff{
+							{Name: "dns", Status: "same"},
+						},
+					},
+				}},
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`product,config,name,status,diff
+cf,manifest,,different,"- a
++ b"
+cf,runtime_config,dns,same,
+`))
+		})
+	})
+
+	Describe("PresentExpiringCertificates", func() {
+		It("writes a row per certificate", func() {
+			csvPresenter.PresentExpiringCertificates([]api.ExpiringCertificate{{
+				Location:     "credhub",
+				VariablePath: "/opsmgr/bosh_dns/tls_ca",
+				Issuer:       "/C=US/O=Pivotal",
+				ValidUntil:   time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+			}})
+
+			Expect(string(stdout.Contents())).To(Equal(`location,product_guid,variable_path,property_reference,issuer,valid_until,configurable
+credhub,,/opsmgr/bosh_dns/tls_ca,,/C=US/O=Pivotal,2021-01-02T03:04:05Z,false
+`))
+		})
+	})
+
+	Describe("PresentInstallationStats", func() {
+		It("writes a row for all users, then a row per user", func() {
+			csvPresenter.PresentInstallationStats(models.InstallationStats{
+				InstallationCounts: models.InstallationCounts{Installations: 3, Succeeded: 2, Failed: 1, SuccessRate: 0.5, MeanDurationSeconds: 90, P95DurationSeconds: 120.5},
+				Users: []models.UserInstallationStats{
+					{User: "admin", InstallationCounts: models.InstallationCounts{Installations: 3, Succeeded: 2, Failed: 1, SuccessRate: 0.5, MeanDurationSeconds: 90, P95DurationSeconds: 120.5}},
+				},
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`user,installations,succeeded,failed,success_rate,mean_duration_seconds,p95_duration_seconds
+,3,2,1,0.5,90,120.5
+admin,3,2,1,0.5,90,120.5
+`))
+		})
+	})
+
+	Describe("PresentPendingChanges", func() {
+		It("writes a row per errand, or per product without errands", func() {
+			csvPresenter.PresentPendingChanges(api.PendingChangesOutput{
+				ChangeList: []api.ProductChange{
+					{GUID: "cf-guid", Action: "update", Errands: []api.Errand{{Name: "smoke_tests"}, {Name: "push-apps-manager"}}},
+					{GUID: "p-bosh-guid", Action: "unchanged"},
+				},
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`product,action,errand
+cf-guid,update,smoke_tests
+cf-guid,update,push-apps-manager
+p-bosh-guid,unchanged,
+`))
+		})
+	})
+})
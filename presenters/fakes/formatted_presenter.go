@@ -15,6 +15,11 @@ type FormattedPresenter struct {
 	presentAvailableProductsArgsForCall []struct {
 		arg1 []models.Product
 	}
+	PresentBoshDiffStub        func(models.BoshDiff)
+	presentBoshDiffMutex       sync.RWMutex
+	presentBoshDiffArgsForCall []struct {
+		arg1 models.BoshDiff
+	}
 	PresentCertificateAuthoritiesStub        func([]api.CA)
 	presentCertificateAuthoritiesMutex       sync.RWMutex
 	presentCertificateAuthoritiesArgsForCall []struct {
@@ -50,6 +55,26 @@ type FormattedPresenter struct {
 	presentErrandsArgsForCall []struct {
 		arg1 []models.Errand
 	}
+	PresentExpiringCertificatesStub        func([]api.ExpiringCertificate)
+	presentExpiringCertificatesMutex       sync.RWMutex
+	presentExpiringCertificatesArgsForCall []struct {
+		arg1 []api.ExpiringCertificate
+	}
+	PresentInstallationLogStub        func(models.InstallationLog)
+	presentInstallationLogMutex       sync.RWMutex
+	presentInstallationLogArgsForCall []struct {
+		arg1 models.InstallationLog
+	}
+	PresentInstallationStatsStub        func(models.InstallationStats)
+	presentInstallationStatsMutex       sync.RWMutex
+	presentInstallationStatsArgsForCall []struct {
+		arg1 models.InstallationStats
+	}
+	PresentInstallationSummaryStub        func(models.InstallationSummary)
+	presentInstallationSummaryMutex       sync.RWMutex
+	presentInstallationSummaryArgsForCall []struct {
+		arg1 models.InstallationSummary
+	}
 	PresentInstallationsStub        func([]models.Installation)
 	presentInstallationsMutex       sync.RWMutex
 	presentInstallationsArgsForCall []struct {
@@ -70,11 +95,17 @@ type FormattedPresenter struct {
 	presentStagedProductsArgsForCall []struct {
 		arg1 []api.DiagnosticProduct
 	}
-	SetFormatStub        func(string)
+	SetFormatStub        func(string) error
 	setFormatMutex       sync.RWMutex
 	setFormatArgsForCall []struct {
 		arg1 string
 	}
+	setFormatReturns struct {
+		result1 error
+	}
+	setFormatReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -89,9 +120,10 @@ func (fake *FormattedPresenter) PresentAvailableProducts(arg1 []models.Product)
 	fake.presentAvailableProductsArgsForCall = append(fake.presentAvailableProductsArgsForCall, struct {
 		arg1 []models.Product
 	}{arg1Copy})
+	stub := fake.PresentAvailableProductsStub
 	fake.recordInvocation("PresentAvailableProducts", []interface{}{arg1Copy})
 	fake.presentAvailableProductsMutex.Unlock()
-	if fake.PresentAvailableProductsStub != nil {
+	if stub != nil {
 		fake.PresentAvailableProductsStub(arg1)
 	}
 }
@@ -115,6 +147,38 @@ func (fake *FormattedPresenter) PresentAvailableProductsArgsForCall(i int) []mod
 	return argsForCall.arg1
 }
 
+func (fake *FormattedPresenter) PresentBoshDiff(arg1 models.BoshDiff) {
+	fake.presentBoshDiffMutex.Lock()
+	fake.presentBoshDiffArgsForCall = append(fake.presentBoshDiffArgsForCall, struct {
+		arg1 models.BoshDiff
+	}{arg1})
+	stub := fake.PresentBoshDiffStub
+	fake.recordInvocation("PresentBoshDiff", []interface{}{arg1})
+	fake.presentBoshDiffMutex.Unlock()
+	if stub != nil {
+		fake.PresentBoshDiffStub(arg1)
+	}
+}
+
+func (fake *FormattedPresenter) PresentBoshDiffCallCount() int {
+	fake.presentBoshDiffMutex.RLock()
+	defer fake.presentBoshDiffMutex.RUnlock()
+	return len(fake.presentBoshDiffArgsForCall)
+}
+
+func (fake *FormattedPresenter) PresentBoshDiffCalls(stub func(models.BoshDiff)) {
+	fake.presentBoshDiffMutex.Lock()
+	defer fake.presentBoshDiffMutex.Unlock()
+	fake.PresentBoshDiffStub = stub
+}
+
+func (fake *FormattedPresenter) PresentBoshDiffArgsForCall(i int) models.BoshDiff {
+	fake.presentBoshDiffMutex.RLock()
+	defer fake.presentBoshDiffMutex.RUnlock()
+	argsForCall := fake.presentBoshDiffArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FormattedPresenter) PresentCertificateAuthorities(arg1 []api.CA) {
 	var arg1Copy []api.CA
 	if arg1 != nil {
@@ -125,9 +189,10 @@ func (fake *FormattedPresenter) PresentCertificateAuthorities(arg1 []api.CA) {
 	fake.presentCertificateAuthoritiesArgsForCall = append(fake.presentCertificateAuthoritiesArgsForCall, struct {
 		arg1 []api.CA
 	}{arg1Copy})
+	stub := fake.PresentCertificateAuthoritiesStub
 	fake.recordInvocation("PresentCertificateAuthorities", []interface{}{arg1Copy})
 	fake.presentCertificateAuthoritiesMutex.Unlock()
-	if fake.PresentCertificateAuthoritiesStub != nil {
+	if stub != nil {
 		fake.PresentCertificateAuthoritiesStub(arg1)
 	}
 }
@@ -156,9 +221,10 @@ func (fake *FormattedPresenter) PresentCertificateAuthority(arg1 api.CA) {
 	fake.presentCertificateAuthorityArgsForCall = append(fake.presentCertificateAuthorityArgsForCall, struct {
 		arg1 api.CA
 	}{arg1})
+	stub := fake.PresentCertificateAuthorityStub
 	fake.recordInvocation("PresentCertificateAuthority", []interface{}{arg1})
 	fake.presentCertificateAuthorityMutex.Unlock()
-	if fake.PresentCertificateAuthorityStub != nil {
+	if stub != nil {
 		fake.PresentCertificateAuthorityStub(arg1)
 	}
 }
@@ -192,9 +258,10 @@ func (fake *FormattedPresenter) PresentCredentialReferences(arg1 []string) {
 	fake.presentCredentialReferencesArgsForCall = append(fake.presentCredentialReferencesArgsForCall, struct {
 		arg1 []string
 	}{arg1Copy})
+	stub := fake.PresentCredentialReferencesStub
 	fake.recordInvocation("PresentCredentialReferences", []interface{}{arg1Copy})
 	fake.presentCredentialReferencesMutex.Unlock()
-	if fake.PresentCredentialReferencesStub != nil {
+	if stub != nil {
 		fake.PresentCredentialReferencesStub(arg1)
 	}
 }
@@ -223,9 +290,10 @@ func (fake *FormattedPresenter) PresentCredentials(arg1 map[string]string) {
 	fake.presentCredentialsArgsForCall = append(fake.presentCredentialsArgsForCall, struct {
 		arg1 map[string]string
 	}{arg1})
+	stub := fake.PresentCredentialsStub
 	fake.recordInvocation("PresentCredentials", []interface{}{arg1})
 	fake.presentCredentialsMutex.Unlock()
-	if fake.PresentCredentialsStub != nil {
+	if stub != nil {
 		fake.PresentCredentialsStub(arg1)
 	}
 }
@@ -259,9 +327,10 @@ func (fake *FormattedPresenter) PresentDeployedProducts(arg1 []api.DiagnosticPro
 	fake.presentDeployedProductsArgsForCall = append(fake.presentDeployedProductsArgsForCall, struct {
 		arg1 []api.DiagnosticProduct
 	}{arg1Copy})
+	stub := fake.PresentDeployedProductsStub
 	fake.recordInvocation("PresentDeployedProducts", []interface{}{arg1Copy})
 	fake.presentDeployedProductsMutex.Unlock()
-	if fake.PresentDeployedProductsStub != nil {
+	if stub != nil {
 		fake.PresentDeployedProductsStub(arg1)
 	}
 }
@@ -290,9 +359,10 @@ func (fake *FormattedPresenter) PresentDiagnosticReport(arg1 api.DiagnosticRepor
 	fake.presentDiagnosticReportArgsForCall = append(fake.presentDiagnosticReportArgsForCall, struct {
 		arg1 api.DiagnosticReport
 	}{arg1})
+	stub := fake.PresentDiagnosticReportStub
 	fake.recordInvocation("PresentDiagnosticReport", []interface{}{arg1})
 	fake.presentDiagnosticReportMutex.Unlock()
-	if fake.PresentDiagnosticReportStub != nil {
+	if stub != nil {
 		fake.PresentDiagnosticReportStub(arg1)
 	}
 }
@@ -326,9 +396,10 @@ func (fake *FormattedPresenter) PresentErrands(arg1 []models.Errand) {
 	fake.presentErrandsArgsForCall = append(fake.presentErrandsArgsForCall, struct {
 		arg1 []models.Errand
 	}{arg1Copy})
+	stub := fake.PresentErrandsStub
 	fake.recordInvocation("PresentErrands", []interface{}{arg1Copy})
 	fake.presentErrandsMutex.Unlock()
-	if fake.PresentErrandsStub != nil {
+	if stub != nil {
 		fake.PresentErrandsStub(arg1)
 	}
 }
@@ -352,6 +423,139 @@ func (fake *FormattedPresenter) PresentErrandsArgsForCall(i int) []models.Errand
 	return argsForCall.arg1
 }
 
+func (fake *FormattedPresenter) PresentExpiringCertificates(arg1 []api.ExpiringCertificate) {
+	var arg1Copy []api.ExpiringCertificate
+	if arg1 != nil {
+		arg1Copy = make([]api.ExpiringCertificate, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.presentExpiringCertificatesMutex.Lock()
+	fake.presentExpiringCertificatesArgsForCall = append(fake.presentExpiringCertificatesArgsForCall, struct {
+		arg1 []api.ExpiringCertificate
+	}{arg1Copy})
+	stub := fake.PresentExpiringCertificatesStub
+	fake.recordInvocation("PresentExpiringCertificates", []interface{}{arg1Copy})
+	fake.presentExpiringCertificatesMutex.Unlock()
+	if stub != nil {
+		fake.PresentExpiringCertificatesStub(arg1)
+	}
+}
+
+func (fake *FormattedPresenter) PresentExpiringCertificatesCallCount() int {
+	fake.presentExpiringCertificatesMutex.RLock()
+	defer fake.presentExpiringCertificatesMutex.RUnlock()
+	return len(fake.presentExpiringCertificatesArgsForCall)
+}
+
+func (fake *FormattedPresenter) PresentExpiringCertificatesCalls(stub func([]api.ExpiringCertificate)) {
+	fake.presentExpiringCertificatesMutex.Lock()
+	defer fake.presentExpiringCertificatesMutex.Unlock()
+	fake.PresentExpiringCertificatesStub = stub
+}
+
+func (fake *FormattedPresenter) PresentExpiringCertificatesArgsForCall(i int) []api.ExpiringCertificate {
+	fake.presentExpiringCertificatesMutex.RLock()
+	defer fake.presentExpiringCertificatesMutex.RUnlock()
+	argsForCall := fake.presentExpiringCertificatesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FormattedPresenter) PresentInstallationLog(arg1 models.InstallationLog) {
+	fake.presentInstallationLogMutex.Lock()
+	fake.presentInstallationLogArgsForCall = append(fake.presentInstallationLogArgsForCall, struct {
+		arg1 models.InstallationLog
+	}{arg1})
+	stub := fake.PresentInstallationLogStub
+	fake.recordInvocation("PresentInstallationLog", []interface{}{arg1})
+	fake.presentInstallationLogMutex.Unlock()
+	if stub != nil {
+		fake.PresentInstallationLogStub(arg1)
+	}
+}
+
+func (fake *FormattedPresenter) PresentInstallationLogCallCount() int {
+	fake.presentInstallationLogMutex.RLock()
+	defer fake.presentInstallationLogMutex.RUnlock()
+	return len(fake.presentInstallationLogArgsForCall)
+}
+
+func (fake *FormattedPresenter) PresentInstallationLogCalls(stub func(models.InstallationLog)) {
+	fake.presentInstallationLogMutex.Lock()
+	defer fake.presentInstallationLogMutex.Unlock()
+	fake.PresentInstallationLogStub = stub
+}
+
+func (fake *FormattedPresenter) PresentInstallationLogArgsForCall(i int) models.InstallationLog {
+	fake.presentInstallationLogMutex.RLock()
+	defer fake.presentInstallationLogMutex.RUnlock()
+	argsForCall := fake.presentInstallationLogArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FormattedPresenter) PresentInstallationStats(arg1 models.InstallationStats) {
+	fake.presentInstallationStatsMutex.Lock()
+	fake.presentInstallationStatsArgsForCall = append(fake.presentInstallationStatsArgsForCall, struct {
+		arg1 models.InstallationStats
+	}{arg1})
+	stub := fake.PresentInstallationStatsStub
+	fake.recordInvocation("PresentInstallationStats", []interface{}{arg1})
+	fake.presentInstallationStatsMutex.Unlock()
+	if stub != nil {
+		fake.PresentInstallationStatsStub(arg1)
+	}
+}
+
+func (fake *FormattedPresenter) PresentInstallationStatsCallCount() int {
+	fake.presentInstallationStatsMutex.RLock()
+	defer fake.presentInstallationStatsMutex.RUnlock()
+	return len(fake.presentInstallationStatsArgsForCall)
+}
+
+func (fake *FormattedPresenter) PresentInstallationStatsCalls(stub func(models.InstallationStats)) {
+	fake.presentInstallationStatsMutex.Lock()
+	defer fake.presentInstallationStatsMutex.Unlock()
+	fake.PresentInstallationStatsStub = stub
+}
+
+func (fake *FormattedPresenter) PresentInstallationStatsArgsForCall(i int) models.InstallationStats {
+	fake.presentInstallationStatsMutex.RLock()
+	defer fake.presentInstallationStatsMutex.RUnlock()
+	argsForCall := fake.presentInstallationStatsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FormattedPresenter) PresentInstallationSummary(arg1 models.InstallationSummary) {
+	fake.presentInstallationSummaryMutex.Lock()
+	fake.presentInstallationSummaryArgsForCall = append(fake.presentInstallationSummaryArgsForCall, struct {
+		arg1 models.InstallationSummary
+	}{arg1})
+	stub := fake.PresentInstallationSummaryStub
+	fake.recordInvocation("PresentInstallationSummary", []interface{}{arg1})
+	fake.presentInstallationSummaryMutex.Unlock()
+	if stub != nil {
+		fake.PresentInstallationSummaryStub(arg1)
+	}
+}
+
+func (fake *FormattedPresenter) PresentInstallationSummaryCallCount() int {
+	fake.presentInstallationSummaryMutex.RLock()
+	defer fake.presentInstallationSummaryMutex.RUnlock()
+	return len(fake.presentInstallationSummaryArgsForCall)
+}
+
+func (fake *FormattedPresenter) PresentInstallationSummaryCalls(stub func(models.InstallationSummary)) {
+	fake.presentInstallationSummaryMutex.Lock()
+	defer fake.presentInstallationSummaryMutex.Unlock()
+	fake.PresentInstallationSummaryStub = stub
+}
+
+func (fake *FormattedPresenter) PresentInstallationSummaryArgsForCall(i int) models.InstallationSummary {
+	fake.presentInstallationSummaryMutex.RLock()
+	defer fake.presentInstallationSummaryMutex.RUnlock()
+	argsForCall := fake.presentInstallationSummaryArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *FormattedPresenter) PresentInstallations(arg1 []models.Installation) {
 	var arg1Copy []models.Installation
 	if arg1 != nil {
@@ -362,9 +566,10 @@ func (fake *FormattedPresenter) PresentInstallations(arg1 []models.Installation)
 	fake.presentInstallationsArgsForCall = append(fake.presentInstallationsArgsForCall, struct {
 		arg1 []models.Installation
 	}{arg1Copy})
+	stub := fake.PresentInstallationsStub
 	fake.recordInvocation("PresentInstallations", []interface{}{arg1Copy})
 	fake.presentInstallationsMutex.Unlock()
-	if fake.PresentInstallationsStub != nil {
+	if stub != nil {
 		fake.PresentInstallationsStub(arg1)
 	}
 }
@@ -393,9 +598,10 @@ func (fake *FormattedPresenter) PresentPendingChanges(arg1 api.PendingChangesOut
 	fake.presentPendingChangesArgsForCall = append(fake.presentPendingChangesArgsForCall, struct {
 		arg1 api.PendingChangesOutput
 	}{arg1})
+	stub := fake.PresentPendingChangesStub
 	fake.recordInvocation("PresentPendingChanges", []interface{}{arg1})
 	fake.presentPendingChangesMutex.Unlock()
-	if fake.PresentPendingChangesStub != nil {
+	if stub != nil {
 		fake.PresentPendingChangesStub(arg1)
 	}
 }
@@ -424,9 +630,10 @@ func (fake *FormattedPresenter) PresentSSLCertificate(arg1 api.SSLCertificate) {
 	fake.presentSSLCertificateArgsForCall = append(fake.presentSSLCertificateArgsForCall, struct {
 		arg1 api.SSLCertificate
 	}{arg1})
+	stub := fake.PresentSSLCertificateStub
 	fake.recordInvocation("PresentSSLCertificate", []interface{}{arg1})
 	fake.presentSSLCertificateMutex.Unlock()
-	if fake.PresentSSLCertificateStub != nil {
+	if stub != nil {
 		fake.PresentSSLCertificateStub(arg1)
 	}
 }
@@ -460,9 +667,10 @@ func (fake *FormattedPresenter) PresentStagedProducts(arg1 []api.DiagnosticProdu
 	fake.presentStagedProductsArgsForCall = append(fake.presentStagedProductsArgsForCall, struct {
 		arg1 []api.DiagnosticProduct
 	}{arg1Copy})
+	stub := fake.PresentStagedProductsStub
 	fake.recordInvocation("PresentStagedProducts", []interface{}{arg1Copy})
 	fake.presentStagedProductsMutex.Unlock()
-	if fake.PresentStagedProductsStub != nil {
+	if stub != nil {
 		fake.PresentStagedProductsStub(arg1)
 	}
 }
@@ -486,16 +694,23 @@ func (fake *FormattedPresenter) PresentStagedProductsArgsForCall(i int) []api.Di
 	return argsForCall.arg1
 }
 
-func (fake *FormattedPresenter) SetFormat(arg1 string) {
+func (fake *FormattedPresenter) SetFormat(arg1 string) error {
 	fake.setFormatMutex.Lock()
+	ret, specificReturn := fake.setFormatReturnsOnCall[len(fake.setFormatArgsForCall)]
 	fake.setFormatArgsForCall = append(fake.setFormatArgsForCall, struct {
 		arg1 string
 	}{arg1})
+	stub := fake.SetFormatStub
+	fakeReturns := fake.setFormatReturns
 	fake.recordInvocation("SetFormat", []interface{}{arg1})
 	fake.setFormatMutex.Unlock()
-	if fake.SetFormatStub != nil {
-		fake.SetFormatStub(arg1)
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
 	}
+	return fakeReturns.result1
 }
 
 func (fake *FormattedPresenter) SetFormatCallCount() int {
@@ -504,7 +719,7 @@ func (fake *FormattedPresenter) SetFormatCallCount() int {
 	return len(fake.setFormatArgsForCall)
 }
 
-func (fake *FormattedPresenter) SetFormatCalls(stub func(string)) {
+func (fake *FormattedPresenter) SetFormatCalls(stub func(string) error) {
 	fake.setFormatMutex.Lock()
 	defer fake.setFormatMutex.Unlock()
 	fake.SetFormatStub = stub
@@ -517,11 +732,36 @@ func (fake *FormattedPresenter) SetFormatArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
+func (fake *FormattedPresenter) SetFormatReturns(result1 error) {
+	fake.setFormatMutex.Lock()
+	defer fake.setFormatMutex.Unlock()
+	fake.SetFormatStub = nil
+	fake.setFormatReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FormattedPresenter) SetFormatReturnsOnCall(i int, result1 error) {
+	fake.setFormatMutex.Lock()
+	defer fake.setFormatMutex.Unlock()
+	fake.SetFormatStub = nil
+	if fake.setFormatReturnsOnCall == nil {
+		fake.setFormatReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setFormatReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FormattedPresenter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.presentAvailableProductsMutex.RLock()
 	defer fake.presentAvailableProductsMutex.RUnlock()
+	fake.presentBoshDiffMutex.RLock()
+	defer fake.presentBoshDiffMutex.RUnlock()
 	fake.presentCertificateAuthoritiesMutex.RLock()
 	defer fake.presentCertificateAuthoritiesMutex.RUnlock()
 	fake.presentCertificateAuthorityMutex.RLock()
@@ -536,6 +776,14 @@ func (fake *FormattedPresenter) Invocations() map[string][][]interface{} {
 	defer fake.presentDiagnosticReportMutex.RUnlock()
 	fake.presentErrandsMutex.RLock()
 	defer fake.presentErrandsMutex.RUnlock()
+	fake.presentExpiringCertificatesMutex.RLock()
+	defer fake.presentExpiringCertificatesMutex.RUnlock()
+	fake.presentInstallationLogMutex.RLock()
+	defer fake.presentInstallationLogMutex.RUnlock()
+	fake.presentInstallationStatsMutex.RLock()
+	defer fake.presentInstallationStatsMutex.RUnlock()
+	fake.presentInstallationSummaryMutex.RLock()
+	defer fake.presentInstallationSummaryMutex.RUnlock()
 	fake.presentInstallationsMutex.RLock()
 	defer fake.presentInstallationsMutex.RUnlock()
 	fake.presentPendingChangesMutex.RLock()
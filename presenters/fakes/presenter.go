@@ -15,6 +15,11 @@ type Presenter struct {
 	presentAvailableProductsArgsForCall []struct {
 		arg1 []models.Product
 	}
+	PresentBoshDiffStub        func(models.BoshDiff)
+	presentBoshDiffMutex       sync.RWMutex
+	presentBoshDiffArgsForCall []struct {
+		arg1 models.BoshDiff
+	}
 	PresentCertificateAuthoritiesStub        func([]api.CA)
 	presentCertificateAuthoritiesMutex       sync.RWMutex
 	presentCertificateAuthoritiesArgsForCall []struct {
@@ -50,6 +55,26 @@ type Presenter struct {
 	presentErrandsArgsForCall []struct {
 		arg1 []models.Errand
 	}
+	PresentExpiringCertificatesStub        func([]api.ExpiringCertificate)
+	presentExpiringCertificatesMutex       sync.RWMutex
+	presentExpiringCertificatesArgsForCall []struct {
+		arg1 []api.ExpiringCertificate
+	}
+	PresentInstallationLogStub        func(models.InstallationLog)
+	presentInstallationLogMutex       sync.RWMutex
+	presentInstallationLogArgsForCall []struct {
+		arg1 models.InstallationLog
+	}
+	PresentInstallationStatsStub        func(models.InstallationStats)
+	presentInstallationStatsMutex       sync.RWMutex
+	presentInstallationStatsArgsForCall []struct {
+		arg1 models.InstallationStats
+	}
+	PresentInstallationSummaryStub        func(models.InstallationSummary)
+	presentInstallationSummaryMutex       sync.RWMutex
+	presentInstallationSummaryArgsForCall []struct {
+		arg1 models.InstallationSummary
+	}
 	PresentInstallationsStub        func([]models.Installation)
 	presentInstallationsMutex       sync.RWMutex
 	presentInstallationsArgsForCall []struct {
@@ -84,9 +109,10 @@ func (fake *Presenter) PresentAvailableProducts(arg1 []models.Product) {
 	fake.presentAvailableProductsArgsForCall = append(fake.presentAvailableProductsArgsForCall, struct {
 		arg1 []models.Product
 	}{arg1Copy})
+	stub := fake.PresentAvailableProductsStub
 	fake.recordInvocation("PresentAvailableProducts", []interface{}{arg1Copy})
 	fake.presentAvailableProductsMutex.Unlock()
-	if fake.PresentAvailableProductsStub != nil {
+	if stub != nil {
 		fake.PresentAvailableProductsStub(arg1)
 	}
 }
@@ -110,6 +136,38 @@ func (fake *Presenter) PresentAvailableProductsArgsForCall(i int) []models.Produ
 	return argsForCall.arg1
 }
 
+func (fake *Presenter) PresentBoshDiff(arg1 models.BoshDiff) {
+	fake.presentBoshDiffMutex.Lock()
+	fake.presentBoshDiffArgsForCall = append(fake.presentBoshDiffArgsForCall, struct {
+		arg1 models.BoshDiff
+	}{arg1})
+	stub := fake.PresentBoshDiffStub
+	fake.recordInvocation("PresentBoshDiff", []interface{}{arg1})
+	fake.presentBoshDiffMutex.Unlock()
+	if stub != nil {
+		fake.PresentBoshDiffStub(arg1)
+	}
+}
+
+func (fake *Presenter) PresentBoshDiffCallCount() int {
+	fake.presentBoshDiffMutex.RLock()
+	defer fake.presentBoshDiffMutex.RUnlock()
+	return len(fake.presentBoshDiffArgsForCall)
+}
+
+func (fake *Presenter) PresentBoshDiffCalls(stub func(models.BoshDiff)) {
+	fake.presentBoshDiffMutex.Lock()
+	defer fake.presentBoshDiffMutex.Unlock()
+	fake.PresentBoshDiffStub = stub
+}
+
+func (fake *Presenter) PresentBoshDiffArgsForCall(i int) models.BoshDiff {
+	fake.presentBoshDiffMutex.RLock()
+	defer fake.presentBoshDiffMutex.RUnlock()
+	argsForCall := fake.presentBoshDiffArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *Presenter) PresentCertificateAuthorities(arg1 []api.CA) {
 	var arg1Copy []api.CA
 	if arg1 != nil {
@@ -120,9 +178,10 @@ func (fake *Presenter) PresentCertificateAuthorities(arg1 []api.CA) {
 	fake.presentCertificateAuthoritiesArgsForCall = append(fake.presentCertificateAuthoritiesArgsForCall, struct {
 		arg1 []api.CA
 	}{arg1Copy})
+	stub := fake.PresentCertificateAuthoritiesStub
 	fake.recordInvocation("PresentCertificateAuthorities", []interface{}{arg1Copy})
 	fake.presentCertificateAuthoritiesMutex.Unlock()
-	if fake.PresentCertificateAuthoritiesStub != nil {
+	if stub != nil {
 		fake.PresentCertificateAuthoritiesStub(arg1)
 	}
 }
@@ -151,9 +210,10 @@ func (fake *Presenter) PresentCertificateAuthority(arg1 api.CA) {
 	fake.presentCertificateAuthorityArgsForCall = append(fake.presentCertificateAuthorityArgsForCall, struct {
 		arg1 api.CA
 	}{arg1})
+	stub := fake.PresentCertificateAuthorityStub
 	fake.recordInvocation("PresentCertificateAuthority", []interface{}{arg1})
 	fake.presentCertificateAuthorityMutex.Unlock()
-	if fake.PresentCertificateAuthorityStub != nil {
+	if stub != nil {
 		fake.PresentCertificateAuthorityStub(arg1)
 	}
 }
@@ -187,9 +247,10 @@ func (fake *Presenter) PresentCredentialReferences(arg1 []string) {
 	fake.presentCredentialReferencesArgsForCall = append(fake.presentCredentialReferencesArgsForCall, struct {
 		arg1 []string
 	}{arg1Copy})
+	stub := fake.PresentCredentialReferencesStub
 	fake.recordInvocation("PresentCredentialReferences", []interface{}{arg1Copy})
 	fake.presentCredentialReferencesMutex.Unlock()
-	if fake.PresentCredentialReferencesStub != nil {
+	if stub != nil {
 		fake.PresentCredentialReferencesStub(arg1)
 	}
 }
@@ -218,9 +279,10 @@ func (fake *Presenter) PresentCredentials(arg1 map[string]string) {
 	fake.presentCredentialsArgsForCall = append(fake.presentCredentialsArgsForCall, struct {
 		arg1 map[string]string
 	}{arg1})
+	stub := fake.PresentCredentialsStub
 	fake.recordInvocation("PresentCredentials", []interface{}{arg1})
 	fake.presentCredentialsMutex.Unlock()
-	if fake.PresentCredentialsStub != nil {
+	if stub != nil {
 		fake.PresentCredentialsStub(arg1)
 	}
 }
@@ -254,9 +316,10 @@ func (fake *Presenter) PresentDeployedProducts(arg1 []api.DiagnosticProduct) {
 	fake.presentDeployedProductsArgsForCall = append(fake.presentDeployedProductsArgsForCall, struct {
 		arg1 []api.DiagnosticProduct
 	}{arg1Copy})
+	stub := fake.PresentDeployedProductsStub
 	fake.recordInvocation("PresentDeployedProducts", []interface{}{arg1Copy})
 	fake.presentDeployedProductsMutex.Unlock()
-	if fake.PresentDeployedProductsStub != nil {
+	if stub != nil {
 		fake.PresentDeployedProductsStub(arg1)
 	}
 }
@@ -285,9 +348,10 @@ func (fake *Presenter) PresentDiagnosticReport(arg1 api.DiagnosticReport) {
 	fake.presentDiagnosticReportArgsForCall = append(fake.presentDiagnosticReportArgsForCall, struct {
 		arg1 api.DiagnosticReport
 	}{arg1})
+	stub := fake.PresentDiagnosticReportStub
 	fake.recordInvocation("PresentDiagnosticReport", []interface{}{arg1})
 	fake.presentDiagnosticReportMutex.Unlock()
-	if fake.PresentDiagnosticReportStub != nil {
+	if stub != nil {
 		fake.PresentDiagnosticReportStub(arg1)
 	}
 }
@@ -321,9 +385,10 @@ func (fake *Presenter) PresentErrands(arg1 []models.Errand) {
 	fake.presentErrandsArgsForCall = append(fake.presentErrandsArgsForCall, struct {
 		arg1 []models.Errand
 	}{arg1Copy})
+	stub := fake.PresentErrandsStub
 	fake.recordInvocation("PresentErrands", []interface{}{arg1Copy})
 	fake.presentErrandsMutex.Unlock()
-	if fake.PresentErrandsStub != nil {
+	if stub != nil {
 		fake.PresentErrandsStub(arg1)
 	}
 }
@@ -347,6 +412,139 @@ func (fake *Presenter) PresentErrandsArgsForCall(i int) []models.Errand {
 	return argsForCall.arg1
 }
 
+func (fake *Presenter) PresentExpiringCertificates(arg1 []api.ExpiringCertificate) {
+	var arg1Copy []api.ExpiringCertificate
+	if arg1 != nil {
+		arg1Copy = make([]api.ExpiringCertificate, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.presentExpiringCertificatesMutex.Lock()
+	fake.presentExpiringCertificatesArgsForCall = append(fake.presentExpiringCertificatesArgsForCall, struct {
+		arg1 []api.ExpiringCertificate
+	}{arg1Copy})
+	stub := fake.PresentExpiringCertificatesStub
+	fake.recordInvocation("PresentExpiringCertificates", []interface{}{arg1Copy})
+	fake.presentExpiringCertificatesMutex.Unlock()
+	if stub != nil {
+		fake.PresentExpiringCertificatesStub(arg1)
+	}
+}
+
+func (fake *Presenter) PresentExpiringCertificatesCallCount() int {
+	fake.presentExpiringCertificatesMutex.RLock()
+	defer fake.presentExpiringCertificatesMutex.RUnlock()
+	return len(fake.presentExpiringCertificatesArgsForCall)
+}
+
+func (fake *Presenter) PresentExpiringCertificatesCalls(stub func([]api.ExpiringCertificate)) {
+	fake.presentExpiringCertificatesMutex.Lock()
+	defer fake.presentExpiringCertificatesMutex.Unlock()
+	fake.PresentExpiringCertificatesStub = stub
+}
+
+func (fake *Presenter) PresentExpiringCertificatesArgsForCall(i int) []api.ExpiringCertificate {
+	fake.presentExpiringCertificatesMutex.RLock()
+	defer fake.presentExpiringCertificatesMutex.RUnlock()
+	argsForCall := fake.presentExpiringCertificatesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Presenter) PresentInstallationLog(arg1 models.InstallationLog) {
+	fake.presentInstallationLogMutex.Lock()
+	fake.presentInstallationLogArgsForCall = append(fake.presentInstallationLogArgsForCall, struct {
+		arg1 models.InstallationLog
+	}{arg1})
+	stub := fake.PresentInstallationLogStub
+	fake.recordInvocation("PresentInstallationLog", []interface{}{arg1})
+	fake.presentInstallationLogMutex.Unlock()
+	if stub != nil {
+		fake.PresentInstallationLogStub(arg1)
+	}
+}
+
+func (fake *Presenter) PresentInstallationLogCallCount() int {
+	fake.presentInstallationLogMutex.RLock()
+	defer fake.presentInstallationLogMutex.RUnlock()
+	return len(fake.presentInstallationLogArgsForCall)
+}
+
+func (fake *Presenter) PresentInstallationLogCalls(stub func(models.InstallationLog)) {
+	fake.presentInstallationLogMutex.Lock()
+	defer fake.presentInstallationLogMutex.Unlock()
+	fake.PresentInstallationLogStub = stub
+}
+
+func (fake *Presenter) PresentInstallationLogArgsForCall(i int) models.InstallationLog {
+	fake.presentInstallationLogMutex.RLock()
+	defer fake.presentInstallationLogMutex.RUnlock()
+	argsForCall := fake.presentInstallationLogArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Presenter) PresentInstallationStats(arg1 models.InstallationStats) {
+	fake.presentInstallationStatsMutex.Lock()
+	fake.presentInstallationStatsArgsForCall = append(fake.presentInstallationStatsArgsForCall, struct {
+		arg1 models.InstallationStats
+	}{arg1})
+	stub := fake.PresentInstallationStatsStub
+	fake.recordInvocation("PresentInstallationStats", []interface{}{arg1})
+	fake.presentInstallationStatsMutex.Unlock()
+	if stub != nil {
+		fake.PresentInstallationStatsStub(arg1)
+	}
+}
+
+func (fake *Presenter) PresentInstallationStatsCallCount() int {
+	fake.presentInstallationStatsMutex.RLock()
+	defer fake.presentInstallationStatsMutex.RUnlock()
+	return len(fake.presentInstallationStatsArgsForCall)
+}
+
+func (fake *Presenter) PresentInstallationStatsCalls(stub func(models.InstallationStats)) {
+	fake.presentInstallationStatsMutex.Lock()
+	defer fake.presentInstallationStatsMutex.Unlock()
+	fake.PresentInstallationStatsStub = stub
+}
+
+func (fake *Presenter) PresentInstallationStatsArgsForCall(i int) models.InstallationStats {
+	fake.presentInstallationStatsMutex.RLock()
+	defer fake.presentInstallationStatsMutex.RUnlock()
+	argsForCall := fake.presentInstallationStatsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Presenter) PresentInstallationSummary(arg1 models.InstallationSummary) {
+	fake.presentInstallationSummaryMutex.Lock()
+	fake.presentInstallationSummaryArgsForCall = append(fake.presentInstallationSummaryArgsForCall, struct {
+		arg1 models.InstallationSummary
+	}{arg1})
+	stub := fake.PresentInstallationSummaryStub
+	fake.recordInvocation("PresentInstallationSummary", []interface{}{arg1})
+	fake.presentInstallationSummaryMutex.Unlock()
+	if stub != nil {
+		fake.PresentInstallationSummaryStub(arg1)
+	}
+}
+
+func (fake *Presenter) PresentInstallationSummaryCallCount() int {
+	fake.presentInstallationSummaryMutex.RLock()
+	defer fake.presentInstallationSummaryMutex.RUnlock()
+	return len(fake.presentInstallationSummaryArgsForCall)
+}
+
+func (fake *Presenter) PresentInstallationSummaryCalls(stub func(models.InstallationSummary)) {
+	fake.presentInstallationSummaryMutex.Lock()
+	defer fake.presentInstallationSummaryMutex.Unlock()
+	fake.PresentInstallationSummaryStub = stub
+}
+
+func (fake *Presenter) PresentInstallationSummaryArgsForCall(i int) models.InstallationSummary {
+	fake.presentInstallationSummaryMutex.RLock()
+	defer fake.presentInstallationSummaryMutex.RUnlock()
+	argsForCall := fake.presentInstallationSummaryArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *Presenter) PresentInstallations(arg1 []models.Installation) {
 	var arg1Copy []models.Installation
 	if arg1 != nil {
@@ -357,9 +555,10 @@ func (fake *Presenter) PresentInstallations(arg1 []models.Installation) {
 	fake.presentInstallationsArgsForCall = append(fake.presentInstallationsArgsForCall, struct {
 		arg1 []models.Installation
 	}{arg1Copy})
+	stub := fake.PresentInstallationsStub
 	fake.recordInvocation("PresentInstallations", []interface{}{arg1Copy})
 	fake.presentInstallationsMutex.Unlock()
-	if fake.PresentInstallationsStub != nil {
+	if stub != nil {
 		fake.PresentInstallationsStub(arg1)
 	}
 }
@@ -388,9 +587,10 @@ func (fake *Presenter) PresentPendingChanges(arg1 api.PendingChangesOutput) {
 	fake.presentPendingChangesArgsForCall = append(fake.presentPendingChangesArgsForCall, struct {
 		arg1 api.PendingChangesOutput
 	}{arg1})
+	stub := fake.PresentPendingChangesStub
 	fake.recordInvocation("PresentPendingChanges", []interface{}{arg1})
 	fake.presentPendingChangesMutex.Unlock()
-	if fake.PresentPendingChangesStub != nil {
+	if stub != nil {
 		fake.PresentPendingChangesStub(arg1)
 	}
 }
@@ -419,9 +619,10 @@ func (fake *Presenter) PresentSSLCertificate(arg1 api.SSLCertificate) {
 	fake.presentSSLCertificateArgsForCall = append(fake.presentSSLCertificateArgsForCall, struct {
 		arg1 api.SSLCertificate
 	}{arg1})
+	stub := fake.PresentSSLCertificateStub
 	fake.recordInvocation("PresentSSLCertificate", []interface{}{arg1})
 	fake.presentSSLCertificateMutex.Unlock()
-	if fake.PresentSSLCertificateStub != nil {
+	if stub != nil {
 		fake.PresentSSLCertificateStub(arg1)
 	}
 }
@@ -455,9 +656,10 @@ func (fake *Presenter) PresentStagedProducts(arg1 []api.DiagnosticProduct) {
 	fake.presentStagedProductsArgsForCall = append(fake.presentStagedProductsArgsForCall, struct {
 		arg1 []api.DiagnosticProduct
 	}{arg1Copy})
+	stub := fake.PresentStagedProductsStub
 	fake.recordInvocation("PresentStagedProducts", []interface{}{arg1Copy})
 	fake.presentStagedProductsMutex.Unlock()
-	if fake.PresentStagedProductsStub != nil {
+	if stub != nil {
 		fake.PresentStagedProductsStub(arg1)
 	}
 }
@@ -486,6 +688,8 @@ func (fake *Presenter) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.presentAvailableProductsMutex.RLock()
 	defer fake.presentAvailableProductsMutex.RUnlock()
+	fake.presentBoshDiffMutex.RLock()
+	defer fake.presentBoshDiffMutex.RUnlock()
 	fake.presentCertificateAuthoritiesMutex.RLock()
 	defer fake.presentCertificateAuthoritiesMutex.RUnlock()
 	fake.presentCertificateAuthorityMutex.RLock()
@@ -500,6 +704,14 @@ func (fake *Presenter) Invocations() map[string][][]interface{} {
 	defer fake.presentDiagnosticReportMutex.RUnlock()
 	fake.presentErrandsMutex.RLock()
 	defer fake.presentErrandsMutex.RUnlock()
+	fake.presentExpiringCertificatesMutex.RLock()
+	defer fake.presentExpiringCertificatesMutex.RUnlock()
+	fake.presentInstallationLogMutex.RLock()
+	defer fake.presentInstallationLogMutex.RUnlock()
+	fake.presentInstallationStatsMutex.RLock()
+	defer fake.presentInstallationStatsMutex.RUnlock()
+	fake.presentInstallationSummaryMutex.RLock()
+	defer fake.presentInstallationSummaryMutex.RUnlock()
 	fake.presentInstallationsMutex.RLock()
 	defer fake.presentInstallationsMutex.RUnlock()
 	fake.presentPendingChangesMutex.RLock()
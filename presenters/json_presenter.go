@@ -22,6 +22,10 @@ func (j JSONPresenter) PresentAvailableProducts(products []models.Product) {
 	j.encodeJSON(products)
 }
 
+func (j JSONPresenter) PresentBoshDiff(diff models.BoshDiff) {
+	j.encodeJSON(diff)
+}
+
 func (j JSONPresenter) PresentCertificateAuthorities(certificateAuthorities []api.CA) {
 	j.encodeJSON(certificateAuthorities)
 }
@@ -42,6 +46,22 @@ func (j JSONPresenter) PresentErrands(errands []models.Errand) {
 	j.encodeJSON(errands)
 }
 
+func (j JSONPresenter) PresentExpiringCertificates(certificates []api.ExpiringCertificate) {
+	j.encodeJSON(certificates)
+}
+
+func (j JSONPresenter) PresentInstallationLog(log models.InstallationLog) {
+	j.encodeJSON(log)
+}
+
+func (j JSONPresenter) PresentInstallationSummary(summary models.InstallationSummary) {
+	j.encodeJSON(summary)
+}
+
+func (j JSONPresenter) PresentInstallationStats(stats models.InstallationStats) {
+	j.encodeJSON(stats)
+}
+
 func (j JSONPresenter) PresentCertificateAuthority(certificateAuthority api.CA) {
 	j.encodeJSON(certificateAuthority)
 }
@@ -1,6 +1,10 @@
 package presenters
 
 import (
+	"fmt"
+	"sort"
+	"strings"
+
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/models"
 )
@@ -9,6 +13,7 @@ import (
 
 type Presenter interface {
 	PresentAvailableProducts([]models.Product)
+	PresentBoshDiff(models.BoshDiff)
 	PresentCertificateAuthorities([]api.CA)
 	PresentCertificateAuthority(api.CA)
 	PresentSSLCertificate(api.SSLCertificate)
@@ -16,6 +21,10 @@ type Presenter interface {
 	PresentCredentials(map[string]string)
 	PresentDeployedProducts([]api.DiagnosticProduct)
 	PresentErrands([]models.Errand)
+	PresentExpiringCertificates([]api.ExpiringCertificate)
+	PresentInstallationLog(models.InstallationLog)
+	PresentInstallationSummary(models.InstallationSummary)
+	PresentInstallationStats(models.InstallationStats)
 	PresentInstallations([]models.Installation)
 	PresentPendingChanges(api.PendingChangesOutput)
 	PresentStagedProducts([]api.DiagnosticProduct)
@@ -27,130 +36,160 @@ type Presenter interface {
 
 type FormattedPresenter interface {
 	Presenter
-	SetFormat(string)
+	SetFormat(string) error
+}
+
+// ConfigurablePresenter is a Presenter whose format takes a value,
+// as in template=<go-template>.
+type ConfigurablePresenter interface {
+	Presenter
+	Configure(string) error
 }
 
 type MultiPresenter struct {
-	tablePresenter Presenter
-	jsonPresenter  Presenter
-	format         string
+	presenters map[string]Presenter
+	format     string
 }
 
 func NewPresenter(tablePresenter Presenter, jsonPresenter Presenter) *MultiPresenter {
-	return &MultiPresenter{
-		tablePresenter: tablePresenter,
-		jsonPresenter:  jsonPresenter,
-		format:         "table",
+	p := &MultiPresenter{
+		presenters: map[string]Presenter{},
+		format:     "table",
 	}
+
+	p.Register("table", tablePresenter)
+	p.Register("json", jsonPresenter)
+
+	return p
 }
 
-func (p *MultiPresenter) SetFormat(format string) {
-	p.format = format
+// Register makes the presenter available to SetFormat under the given format name.
+func (p *MultiPresenter) Register(format string, presenter Presenter) {
+	p.presenters[format] = presenter
 }
 
-func (p *MultiPresenter) PresentAvailableProducts(products []models.Product) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentAvailableProducts(products)
-	default:
-		p.tablePresenter.PresentAvailableProducts(products)
+// SetFormat selects the presenter registered for the format.
+// The value of a format, as in template=<go-template>,
+// is passed to its ConfigurablePresenter.
+func (p *MultiPresenter) SetFormat(format string) error {
+	name, value, hasValue := format, "", false
+	if i := strings.Index(format, "="); i >= 0 {
+		name, value, hasValue = format[:i], format[i+1:], true
+	}
+
+	presenter, ok := p.presenters[name]
+	if !ok {
+		return fmt.Errorf("unknown format %q, the supported formats are: %s", name, strings.Join(p.formats(), ", "))
 	}
+
+	configurable, isConfigurable := presenter.(ConfigurablePresenter)
+	switch {
+	case isConfigurable && !hasValue:
+		return fmt.Errorf("the %s format requires a value, as in %s=<value>", name, name)
+	case isConfigurable:
+		err := configurable.Configure(value)
+		if err != nil {
+			return err
+		}
+	case hasValue:
+		return fmt.Errorf("the %s format does not take a value", name)
+	}
+
+	p.format = name
+	return nil
 }
 
-func (p *MultiPresenter) PresentCertificateAuthorities(cas []api.CA) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentCertificateAuthorities(cas)
-	default:
-		p.tablePresenter.PresentCertificateAuthorities(cas)
+func (p *MultiPresenter) formats() []string {
+	var formats []string
+	for name, presenter := range p.presenters {
+		if _, ok := presenter.(ConfigurablePresenter); ok {
+			name += "=<value>"
+		}
+		formats = append(formats, name)
 	}
+	sort.Strings(formats)
+
+	return formats
 }
 
-func (p *MultiPresenter) PresentCertificateAuthority(ca api.CA) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentCertificateAuthority(ca)
-	default:
-		p.tablePresenter.PresentCertificateAuthority(ca)
+// Err returns the error the selected presenter failed to present with,
+// for the presenters that can fail, like the TemplatePresenter.
+func (p *MultiPresenter) Err() error {
+	if presenter, ok := p.presenter().(interface{ Err() error }); ok {
+		return presenter.Err()
 	}
+
+	return nil
+}
+
+func (p *MultiPresenter) presenter() Presenter {
+	return p.presenters[p.format]
+}
+
+func (p *MultiPresenter) PresentAvailableProducts(products []models.Product) {
+	p.presenter().PresentAvailableProducts(products)
+}
+
+func (p *MultiPresenter) PresentBoshDiff(diff models.BoshDiff) {
+	p.presenter().PresentBoshDiff(diff)
+}
+
+func (p *MultiPresenter) PresentCertificateAuthorities(cas []api.CA) {
+	p.presenter().PresentCertificateAuthorities(cas)
+}
+
+func (p *MultiPresenter) PresentCertificateAuthority(ca api.CA) {
+	p.presenter().PresentCertificateAuthority(ca)
 }
 
 func (p *MultiPresenter) PresentSSLCertificate(cert api.SSLCertificate) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentSSLCertificate(cert)
-	default:
-		p.tablePresenter.PresentSSLCertificate(cert)
-	}
+	p.presenter().PresentSSLCertificate(cert)
 }
 
 func (p *MultiPresenter) PresentCredentialReferences(ref []string) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentCredentialReferences(ref)
-	default:
-		p.tablePresenter.PresentCredentialReferences(ref)
-	}
+	p.presenter().PresentCredentialReferences(ref)
 }
 
 func (p *MultiPresenter) PresentCredentials(creds map[string]string) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentCredentials(creds)
-	default:
-		p.tablePresenter.PresentCredentials(creds)
-	}
+	p.presenter().PresentCredentials(creds)
 }
 
 func (p *MultiPresenter) PresentDeployedProducts(products []api.DiagnosticProduct) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentDeployedProducts(products)
-	default:
-		p.tablePresenter.PresentDeployedProducts(products)
-	}
+	p.presenter().PresentDeployedProducts(products)
 }
 
 func (p *MultiPresenter) PresentErrands(errands []models.Errand) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentErrands(errands)
-	default:
-		p.tablePresenter.PresentErrands(errands)
-	}
+	p.presenter().PresentErrands(errands)
+}
+
+func (p *MultiPresenter) PresentExpiringCertificates(certs []api.ExpiringCertificate) {
+	p.presenter().PresentExpiringCertificates(certs)
+}
+
+func (p *MultiPresenter) PresentInstallationLog(log models.InstallationLog) {
+	p.presenter().PresentInstallationLog(log)
+}
+
+func (p *MultiPresenter) PresentInstallationSummary(summary models.InstallationSummary) {
+	p.presenter().PresentInstallationSummary(summary)
+}
+
+func (p *MultiPresenter) PresentInstallationStats(stats models.InstallationStats) {
+	p.presenter().PresentInstallationStats(stats)
 }
 
 func (p *MultiPresenter) PresentInstallations(i []models.Installation) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentInstallations(i)
-	default:
-		p.tablePresenter.PresentInstallations(i)
-	}
+	p.presenter().PresentInstallations(i)
 }
+
 func (p *MultiPresenter) PresentPendingChanges(c api.PendingChangesOutput) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentPendingChanges(c)
-	default:
-		p.tablePresenter.PresentPendingChanges(c)
-	}
+	p.presenter().PresentPendingChanges(c)
 }
 
 func (p *MultiPresenter) PresentStagedProducts(products []api.DiagnosticProduct) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentStagedProducts(products)
-	default:
-		p.tablePresenter.PresentStagedProducts(products)
-	}
+	p.presenter().PresentStagedProducts(products)
 }
 
 func (p *MultiPresenter) PresentDiagnosticReport(report api.DiagnosticReport) {
-	switch p.format {
-	case "json":
-		p.jsonPresenter.PresentDiagnosticReport(report)
-	default:
-		p.tablePresenter.PresentDiagnosticReport(report)
-	}
+	p.presenter().PresentDiagnosticReport(report)
 }
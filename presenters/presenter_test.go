@@ -0,0 +1,97 @@
+package presenters_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/commands/fakes"
+	"github.com/pivotal-cf/om/models"
+	"github.com/pivotal-cf/om/presenters"
+)
+
+var _ = Describe("MultiPresenter", func() {
+	var (
+		presenter *presenters.MultiPresenter
+		stdout    *gbytes.Buffer
+	)
+
+	BeforeEach(func() {
+		stdout = gbytes.NewBuffer()
+
+		presenter = presenters.NewPresenter(
+			presenters.NewTablePresenter(&fakes.TableWriter{}, stdout),
+			presenters.NewJSONPresenter(stdout),
+		)
+		presenter.Register("yaml", presenters.NewYAMLPresenter(stdout))
+		presenter.Register("csv", presenters.NewCSVPresenter(stdout))
+		presenter.Register("template", presenters.NewTemplatePresenter(stdout))
+	})
+
+	errands := []models.Errand{
+		{Name: "smoke-tests", PostDeployEnabled: "true"},
+		{Name: "drain", PreDeleteEnabled: "false"},
+	}
+
+	It("presents as yaml", func() {
+		Expect(presenter.SetFormat("yaml")).To(Succeed())
+		presenter.PresentErrands(errands)
+
+		Expect(string(stdout.Contents())).To(Equal(`- name: smoke-tests
+  post_deploy_enabled: "true"
+- name: drain
+  pre_delete_enabled: "false"
+`))
+	})
+
+	It("presents as csv", func() {
+		Expect(presenter.SetFormat("csv")).To(Succeed())
+		presenter.PresentInstallationLog(models.InstallationLog{ID: 3, Logs: "line one\nline two"})
+
+		Expect(string(stdout.Contents())).To(Equal("id,logs\n3,\"line one\nline two\"\n"))
+	})
+
+	It("presents through a template", func() {
+		Expect(presenter.SetFormat(`template={{range .}}{{.name}}{{"\n"}}{{end}}`)).To(Succeed())
+		presenter.PresentErrands(errands)
+
+		Expect(string(stdout.Contents())).To(Equal("smoke-tests\ndrain\n"))
+	})
+
+	It("returns the error of a template failing to execute", func() {
+		Expect(presenter.SetFormat(`template={{.missing}}`)).To(Succeed())
+		presenter.PresentInstallationLog(models.InstallationLog{ID: 3})
+
+		Expect(stdout.Contents()).To(BeEmpty())
+		Expect(presenter.Err()).To(MatchError(ContainSubstring(`could not execute format template: `)))
+		Expect(presenter.Err()).To(MatchError(ContainSubstring(`map has no entry for key "missing"`)))
+	})
+
+	It("returns no error for the presenters that cannot fail", func() {
+		Expect(presenter.SetFormat("json")).To(Succeed())
+		presenter.PresentErrands(errands)
+
+		Expect(presenter.Err()).ToNot(HaveOccurred())
+	})
+
+	Describe("SetFormat", func() {
+		It("errors on an unknown format", func() {
+			err := presenter.SetFormat("xml")
+			Expect(err).To(MatchError(`unknown format "xml", the supported formats are: csv, json, table, template=<value>, yaml`))
+		})
+
+		It("errors when a format requiring a value has none", func() {
+			err := presenter.SetFormat("template")
+			Expect(err).To(MatchError("the template format requires a value, as in template=<value>"))
+		})
+
+		It("errors when a format not taking a value is given one", func() {
+			err := presenter.SetFormat("json=pretty")
+			Expect(err).To(MatchError("the json format does not take a value"))
+		})
+
+		It("errors when the template does not parse", func() {
+			err := presenter.SetFormat("template={{.Name")
+			Expect(err).To(MatchError(ContainSubstring("could not parse format template:")))
+		})
+	})
+})
@@ -1,10 +1,14 @@
 package presenters
 
 import (
+	"fmt"
+	"io"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/models"
@@ -19,15 +23,20 @@ type tableWriter interface {
 	Render()
 	SetAutoFormatHeaders(bool)
 	SetAutoWrapText(bool)
+	ClearRows()
 }
 
+// TablePresenter renders tables, except for diffs, logs and expiring certificates,
+// which are written to stdout as text.
 type TablePresenter struct {
 	tableWriter tableWriter
+	stdout      io.Writer
 }
 
-func NewTablePresenter(tableWriter tableWriter) TablePresenter {
+func NewTablePresenter(tableWriter tableWriter, stdout io.Writer) TablePresenter {
 	return TablePresenter{
 		tableWriter: tableWriter,
+		stdout:      stdout,
 	}
 }
 
@@ -42,6 +51,100 @@ func (t TablePresenter) PresentAvailableProducts(products []models.Product) {
 	t.tableWriter.Render()
 }
 
+func (t TablePresenter) PresentBoshDiff(diff models.BoshDiff) {
+	if diff.Director != nil {
+		t.printf("## Director Manifest\n\n")
+		notInstalled := t.printManifestDiff(diff.Director.Manifest)
+		if !notInstalled {
+			t.printf("## Director Cloud Config\n\n")
+			t.printManifestDiff(diff.Director.CloudConfig)
+			t.printf("## Director Runtime Configs\n\n")
+			t.printRuntimeConfigs(diff.Director.RuntimeConfigs)
+			t.printf("## Director CPI Configs\n\n")
+			t.printCPIConfigs(diff.Director.CPIConfigs)
+		}
+	}
+
+	for _, product := range diff.Products {
+		t.printf("## Product Manifest for %s\n\n", product.Name)
+
+		notInstalled := t.printManifestDiff(product.Manifest)
+		if notInstalled {
+			continue
+		}
+		t.printf("## Runtime Configs for %s\n\n", product.Name)
+		t.printRuntimeConfigs(product.RuntimeConfigs)
+	}
+}
+
+func (t TablePresenter) printManifestDiff(diff api.ManifestDiff) bool {
+	switch diff.Status {
+	case "same":
+		t.printf("no changes\n\n")
+	case "does_not_exist":
+		t.printf("no manifest for this product\n\n")
+	case "different":
+		t.printf("%s\n\n", colorizeDiff(diff.Diff))
+	case "to_be_installed":
+		t.printf("This product is not yet deployed, so the product and runtime diffs are not available.\n")
+		return true
+	default:
+		t.printf("unrecognized product status: %s\n\n%s\n\n", diff.Status, diff.Diff)
+	}
+	return false
+}
+
+func (t TablePresenter) printRuntimeConfigs(configs []api.RuntimeConfigsDiff) {
+	noneChanged := true
+
+	for _, config := range configs {
+		if config.Status == "same" {
+			continue
+		}
+
+		noneChanged = false
+
+		t.printf("### %s\n\n", config.Name)
+		t.printf("%s\n\n", colorizeDiff(config.Diff))
+	}
+
+	if noneChanged {
+		t.printf("no changes\n\n")
+	}
+}
+
+func (t TablePresenter) printCPIConfigs(configs []api.CPIConfigsDiff) {
+	noneChanged := true
+
+	for _, config := range configs {
+		if config.Status == "same" {
+			continue
+		}
+
+		noneChanged = false
+
+		t.printf("### %s\n\n", config.IAASConfigurationName)
+		t.printf("%s\n\n", colorizeDiff(config.Diff))
+	}
+
+	if noneChanged {
+		t.printf("no changes\n\n")
+	}
+}
+
+func colorizeDiff(diff string) string {
+	lines := strings.Split(diff, "\n")
+	for index, line := range lines {
+		if strings.HasPrefix(line, "-") {
+			lines[index] = color.RedString(line)
+		}
+		if strings.HasPrefix(line, "+") {
+			lines[index] = color.GreenString(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 func (t TablePresenter) PresentCertificateAuthorities(certificateAuthorities []api.CA) {
 	t.tableWriter.SetAutoWrapText(false)
 	t.tableWriter.SetHeader([]string{"id", "issuer", "active", "created on", "expires on", "certicate pem"})
@@ -96,6 +199,194 @@ func (t TablePresenter) PresentErrands(errands []models.Errand) {
 	t.tableWriter.Render()
 }
 
+func (t TablePresenter) PresentExpiringCertificates(certificates []api.ExpiringCertificate) {
+	if len(certificates) == 0 {
+		return
+	}
+
+	t.printf("%s\n", color.RedString("Found expiring certificates in the foundation:\n"))
+
+	withVariablePath, withProductGUID := groupByLocation(certificates)
+
+	var variablePathLocations []string
+	for location := range withVariablePath {
+		variablePathLocations = append(variablePathLocations, location)
+	}
+	sort.Strings(variablePathLocations)
+
+	for _, location := range variablePathLocations {
+		t.printf("%s\n", color.RedString("[X] %s", location))
+
+		for _, certificate := range withVariablePath[location] {
+			t.printExpiringCertificate(certificate)
+		}
+	}
+
+	var productGUIDLocations []string
+	for location := range withProductGUID {
+		productGUIDLocations = append(productGUIDLocations, location)
+	}
+	sort.Strings(productGUIDLocations)
+
+	for _, location := range productGUIDLocations {
+		t.printf("%s\n", color.RedString("[X] %s", location))
+
+		var guids []string
+		for guid := range withProductGUID[location] {
+			guids = append(guids, guid)
+		}
+		sort.Strings(guids)
+
+		for _, guid := range guids {
+			t.printf("%s\n", color.RedString("    %s:", guid))
+			for _, certificate := range withProductGUID[location][guid] {
+				t.printExpiringCertificate(certificate)
+			}
+		}
+	}
+}
+
+func (t TablePresenter) printExpiringCertificate(certificate api.ExpiringCertificate) {
+	expiring := "expiring"
+	if time.Now().After(certificate.ValidUntil) {
+		expiring = "expired"
+	}
+
+	validUntil := certificate.ValidUntil.Format(time.RFC822)
+
+	if certificate.VariablePath != "" {
+		t.printf("%s\n", color.RedString("    %s: %s on %s", certificate.VariablePath, expiring, validUntil))
+		return
+	}
+
+	t.printf("%s\n", color.RedString("        %s: %s on %s", certificate.PropertyReference, expiring, validUntil))
+}
+
+// groupByLocation groups certificates stored in CredHub by their location,
+// and the others by their location and product.
+func groupByLocation(certificates []api.ExpiringCertificate) (map[string][]api.ExpiringCertificate, map[string]map[string][]api.ExpiringCertificate) {
+	withVariablePath := make(map[string][]api.ExpiringCertificate)
+	withProductGUID := make(map[string]map[string][]api.ExpiringCertificate)
+	for _, certificate := range certificates {
+		location := strings.Title(strings.Replace(certificate.Location, "_", " ", -1))
+		if certificate.VariablePath != "" {
+			withVariablePath[location] = append(withVariablePath[location], certificate)
+			continue
+		}
+
+		if withProductGUID[location] == nil {
+			withProductGUID[location] = make(map[string][]api.ExpiringCertificate)
+		}
+
+		withProductGUID[location][certificate.ProductGUID] = append(withProductGUID[location][certificate.ProductGUID], certificate)
+	}
+
+	return withVariablePath, withProductGUID
+}
+
+func (t TablePresenter) PresentInstallationLog(log models.InstallationLog) {
+	t.printf("%s", log.Logs)
+	if !strings.HasSuffix(log.Logs, "\n") {
+		t.printf("\n")
+	}
+}
+
+func (t TablePresenter) PresentInstallationSummary(summary models.InstallationSummary) {
+	t.tableWriter.SetAlignment(tablewriter.ALIGN_LEFT)
+	t.tableWriter.SetHeader([]string{"Stage", "Type", "Started At", "Duration", "Outcome"})
+
+	for _, stage := range summary.Stages {
+		t.tableWriter.Append([]string{
+			stageName(stage),
+			stage.Type,
+			stage.StartedAt.Format(time.RFC3339),
+			formatSeconds(stage.DurationSeconds),
+			stage.Outcome,
+		})
+	}
+
+	t.tableWriter.Render()
+
+	for _, stage := range summary.Stages {
+		if stage.Outcome != models.InstallationStageFailed || len(stage.ErrorLines) == 0 {
+			continue
+		}
+
+		t.printf("\nErrors in %s %s:\n", strings.Replace(stage.Type, "_", " ", 1), stageName(stage))
+		for _, line := range stage.ErrorLines {
+			t.printf("  %s\n", line)
+		}
+	}
+}
+
+// PresentInstallationStats renders a table of the counts of all users and of each user,
+// followed by a table of the longest installations.
+func (t TablePresenter) PresentInstallationStats(stats models.InstallationStats) {
+	t.printf("Installations started from %s to %s\n", stats.Since.Format(time.RFC3339), stats.Until.Format(time.RFC3339))
+
+	t.tableWriter.SetAlignment(tablewriter.ALIGN_LEFT)
+	t.tableWriter.SetHeader([]string{"User", "Installations", "Succeeded", "Failed", "Success Rate", "Mean Duration", "P95 Duration"})
+	t.tableWriter.Append(installationCountsRow("all users", stats.InstallationCounts))
+	for _, user := range stats.Users {
+		t.tableWriter.Append(installationCountsRow(user.User, user.InstallationCounts))
+	}
+	t.tableWriter.Render()
+	t.tableWriter.ClearRows()
+
+	if len(stats.Longest) == 0 {
+		return
+	}
+
+	t.printf("Longest installations\n")
+
+	t.tableWriter.SetHeader([]string{"ID", "User", "Status", "Started At", "Duration"})
+	for _, installation := range stats.Longest {
+		var startedAt string
+		if installation.StartedAt != nil {
+			startedAt = installation.StartedAt.Format(time.RFC3339)
+		}
+
+		t.tableWriter.Append([]string{
+			strconv.Itoa(installation.Id),
+			installation.User,
+			installation.Status,
+			startedAt,
+			formatSeconds(installation.DurationSeconds),
+		})
+	}
+	t.tableWriter.Render()
+	t.tableWriter.ClearRows()
+}
+
+func installationCountsRow(user string, counts models.InstallationCounts) []string {
+	return []string{
+		user,
+		strconv.Itoa(counts.Installations),
+		strconv.Itoa(counts.Succeeded),
+		strconv.Itoa(counts.Failed),
+		fmt.Sprintf("%.1f%%", counts.SuccessRate*100),
+		formatSeconds(counts.MeanDurationSeconds),
+		formatSeconds(counts.P95DurationSeconds),
+	}
+}
+
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second).String()
+}
+
+// stageName qualifies instance groups with their deployment.
+func stageName(stage models.InstallationStage) string {
+	if stage.Type == models.InstallationStageInstanceGroup {
+		return stage.Deployment + "/" + stage.Name
+	}
+
+	return stage.Name
+}
+
+func (t TablePresenter) printf(format string, v ...interface{}) {
+	_, _ = fmt.Fprintf(t.stdout, format, v...)
+}
+
 func (t TablePresenter) PresentCertificateAuthority(certificateAuthority api.CA) {
 	t.tableWriter.SetAutoWrapText(false)
 	t.tableWriter.SetHeader([]string{"id", "issuer", "active", "created on", "expires on", "certicate pem"})
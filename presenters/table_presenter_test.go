@@ -4,9 +4,11 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 	"github.com/pivotal-cf/om/api"
 	"github.com/pivotal-cf/om/commands/fakes"
 	"github.com/pivotal-cf/om/models"
@@ -17,11 +19,13 @@ var _ = Describe("TablePresenter", func() {
 	var (
 		tablePresenter  presenters.TablePresenter
 		fakeTableWriter *fakes.TableWriter
+		stdout          *gbytes.Buffer
 	)
 
 	BeforeEach(func() {
 		fakeTableWriter = &fakes.TableWriter{}
-		tablePresenter = presenters.NewTablePresenter(fakeTableWriter)
+		stdout = gbytes.NewBuffer()
+		tablePresenter = presenters.NewTablePresenter(fakeTableWriter, stdout)
 	})
 
 	Describe("PresentAvailableProducts", func() {
@@ -362,4 +366,134 @@ var _ = Describe("TablePresenter", func() {
 			Expect(fakeTableWriter.RenderCallCount()).To(Equal(1))
 		})
 	})
+
+	Describe("PresentExpiringCertificates", func() {
+		BeforeEach(func() {
+			color.NoColor = true
+		})
+
+		AfterEach(func() {
+			color.NoColor = false
+		})
+
+		It("lists the certificates grouped by location, then product", func() {
+			validUntil := time.Date(2999, 1, 1, 1, 1, 1, 0, time.UTC)
+			tablePresenter.PresentExpiringCertificates([]api.ExpiringCertificate{
+				{ValidUntil: validUntil, Location: "ops_manager", ProductGUID: "product-guid-2", PropertyReference: ".properties.b"},
+				{ValidUntil: validUntil, Location: "credhub", VariablePath: "/opsmgr/tls_ca"},
+				{ValidUntil: validUntil, Location: "ops_manager", ProductGUID: "product-guid-1", PropertyReference: ".properties.a"},
+			})
+
+			expiring := validUntil.Format(time.RFC822)
+			Expect(string(stdout.Contents())).To(Equal(`Found expiring certificates in the foundation:
+
+[X] Credhub
+    /opsmgr/tls_ca: expiring on ` + expiring + `
+[X] Ops Manager
+    product-guid-1:
+        .properties.a: expiring on ` + expiring + `
+    product-guid-2:
+        .properties.b: expiring on ` + expiring + `
+`))
+		})
+
+		It("prints nothing when no certificates are expiring", func() {
+			tablePresenter.PresentExpiringCertificates(nil)
+			Expect(stdout.Contents()).To(BeEmpty())
+		})
+	})
+
+	Describe("PresentBoshDiff", func() {
+		It("stops at the manifest of a product that is not deployed yet", func() {
+			tablePresenter.PresentBoshDiff(models.BoshDiff{
+				Director: &api.DirectorDiff{
+					Manifest:    api.ManifestDiff{Status: "same"},
+					CloudConfig: api.ManifestDiff{Status: "same"},
+				},
+				Products: []models.ProductDiff{
+					{Name: "new-product", ProductDiff: api.ProductDiff{Manifest: api.ManifestDiff{Status: "to_be_installed"}}},
+				},
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`## Director Manifest
+
+no changes
+
+## Director Cloud Config
+
+no changes
+
+## Director Runtime Configs
+
+no changes
+
+## Director CPI Configs
+
+no changes
+
+## Product Manifest for new-product
+
+This product is not yet deployed, so the product and runtime diffs are not available.
+`))
+		})
+	})
+
+	Describe("PresentInstallationLog", func() {
+		It("prints the raw log", func() {
+			tablePresenter.PresentInstallationLog(models.InstallationLog{ID: 12, Logs: "some log output"})
+			Expect(string(stdout.Contents())).To(Equal("some log output\n"))
+		})
+	})
+
+	Describe("PresentInstallationSummary", func() {
+		It("tables the stages and lists the errors of the failed ones", func() {
+			startedAt := time.Date(2020, 7, 1, 23, 10, 20, 0, time.UTC)
+
+			tablePresenter.PresentInstallationSummary(models.InstallationSummary{
+				ID:     12,
+				Status: "failed",
+				Stages: []models.InstallationStage{
+					{Name: "cf-1234", Type: "deployment", Deployment: "cf-1234", StartedAt: startedAt, DurationSeconds: 3645, Outcome: "failed", ErrorLines: []string{"Error: boom"}},
+					{Name: "router", Type: "instance_group", Deployment: "cf-1234", StartedAt: startedAt, DurationSeconds: 90.4, Outcome: "succeeded"},
+				},
+			})
+
+			Expect(fakeTableWriter.SetHeaderArgsForCall(0)).To(Equal([]string{"Stage", "Type", "Started At", "Duration", "Outcome"}))
+			Expect(fakeTableWriter.AppendCallCount()).To(Equal(2))
+			Expect(fakeTableWriter.AppendArgsForCall(0)).To(Equal([]string{"cf-1234", "deployment", "2020-07-01T23:10:20Z", "1h0m45s", "failed"}))
+			Expect(fakeTableWriter.AppendArgsForCall(1)).To(Equal([]string{"cf-1234/router", "instance_group", "2020-07-01T23:10:20Z", "1m30s", "succeeded"}))
+			Expect(fakeTableWriter.RenderCallCount()).To(Equal(1))
+
+			Expect(string(stdout.Contents())).To(Equal("\nErrors in deployment cf-1234:\n  Error: boom\n"))
+		})
+	})
+
+	Describe("PresentInstallationStats", func() {
+		It("tables the counts per user and the longest installations", func() {
+			startedAt := time.Date(2020, 6, 20, 10, 0, 0, 0, time.UTC)
+			counts := models.InstallationCounts{Installations: 3, Succeeded: 2, Failed: 1, SuccessRate: 2.0 / 3.0, MeanDurationSeconds: 2400, P95DurationSeconds: 3600}
+
+			tablePresenter.PresentInstallationStats(models.InstallationStats{
+				Since:              time.Date(2020, 4, 1, 0, 0, 0, 0, time.UTC),
+				Until:              time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC),
+				InstallationCounts: counts,
+				Users:              []models.UserInstallationStats{{User: "ci", InstallationCounts: counts}},
+				Longest: []models.InstallationDuration{
+					{Installation: models.Installation{Id: 6, User: "ci", Status: "succeeded", StartedAt: &startedAt}, DurationSeconds: 3600},
+				},
+			})
+
+			Expect(string(stdout.Contents())).To(Equal("Installations started from 2020-04-01T00:00:00Z to 2020-07-01T00:00:00Z\nLongest installations\n"))
+
+			Expect(fakeTableWriter.SetHeaderArgsForCall(0)).To(Equal([]string{"User", "Installations", "Succeeded", "Failed", "Success Rate", "Mean Duration", "P95 Duration"}))
+			Expect(fakeTableWriter.AppendArgsForCall(0)).To(Equal([]string{"all users", "3", "2", "1", "66.7%", "40m0s", "1h0m0s"}))
+			Expect(fakeTableWriter.AppendArgsForCall(1)).To(Equal([]string{"ci", "3", "2", "1", "66.7%", "40m0s", "1h0m0s"}))
+
+			Expect(fakeTableWriter.SetHeaderArgsForCall(1)).To(Equal([]string{"ID", "User", "Status", "Started At", "Duration"}))
+			Expect(fakeTableWriter.AppendArgsForCall(2)).To(Equal([]string{"6", "ci", "succeeded", "2020-06-20T10:00:00Z", "1h0m0s"}))
+
+			Expect(fakeTableWriter.RenderCallCount()).To(Equal(2))
+			Expect(fakeTableWriter.ClearRowsCallCount()).To(Equal(2))
+		})
+	})
 })
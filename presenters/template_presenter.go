@@ -0,0 +1,138 @@
+package presenters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/template"
+
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+)
+
+// TemplatePresenter executes a Go template against the document
+// the JSONPresenter would write, so fields are accessed by their JSON keys.
+// A template can only fail against the document it is executed with,
+// so the first failure is kept for Err.
+type TemplatePresenter struct {
+	stdout   io.Writer
+	template *template.Template
+	err      error
+}
+
+func NewTemplatePresenter(stdout io.Writer) *TemplatePresenter {
+	return &TemplatePresenter{
+		stdout: stdout,
+	}
+}
+
+func (t *TemplatePresenter) Configure(text string) error {
+	tmpl, err := template.New("format").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return fmt.Errorf("could not parse format template: %w", err)
+	}
+
+	t.template = tmpl
+	return nil
+}
+
+func (t *TemplatePresenter) PresentAvailableProducts(products []models.Product) {
+	t.execute(products)
+}
+
+func (t *TemplatePresenter) PresentBoshDiff(diff models.BoshDiff) {
+	t.execute(diff)
+}
+
+func (t *TemplatePresenter) PresentCertificateAuthorities(certificateAuthorities []api.CA) {
+	t.execute(certificateAuthorities)
+}
+
+func (t *TemplatePresenter) PresentCredentialReferences(credentialReferences []string) {
+	t.execute(credentialReferences)
+}
+
+func (t *TemplatePresenter) PresentCredentials(credentials map[string]string) {
+	t.execute(credentials)
+}
+
+func (t *TemplatePresenter) PresentDeployedProducts(deployedProducts []api.DiagnosticProduct) {
+	t.execute(deployedProducts)
+}
+
+func (t *TemplatePresenter) PresentErrands(errands []models.Errand) {
+	t.execute(errands)
+}
+
+func (t *TemplatePresenter) PresentExpiringCertificates(certificates []api.ExpiringCertificate) {
+	t.execute(certificates)
+}
+
+func (t *TemplatePresenter) PresentInstallationLog(log models.InstallationLog) {
+	t.execute(log)
+}
+
+func (t *TemplatePresenter) PresentInstallationSummary(summary models.InstallationSummary) {
+	t.execute(summary)
+}
+
+func (t *TemplatePresenter) PresentInstallationStats(stats models.InstallationStats) {
+	t.execute(stats)
+}
+
+func (t *TemplatePresenter) PresentCertificateAuthority(certificateAuthority api.CA) {
+	t.execute(certificateAuthority)
+}
+
+func (t *TemplatePresenter) PresentSSLCertificate(certificate api.SSLCertificate) {
+	t.execute(certificate)
+}
+
+func (t *TemplatePresenter) PresentInstallations(installations []models.Installation) {
+	t.execute(installations)
+}
+
+func (t *TemplatePresenter) PresentStagedProducts(stagedProducts []api.DiagnosticProduct) {
+	t.execute(stagedProducts)
+}
+
+func (t *TemplatePresenter) PresentPendingChanges(pendingChangesOutput api.PendingChangesOutput) {
+	t.executeJSON([]byte(pendingChangesOutput.FullReport))
+}
+
+func (t *TemplatePresenter) PresentDiagnosticReport(report api.DiagnosticReport) {
+	t.executeJSON([]byte(report.FullReport))
+}
+
+// Err returns the first error executing the template.
+func (t *TemplatePresenter) Err() error {
+	return t.err
+}
+
+func (t *TemplatePresenter) execute(v interface{}) {
+	document, err := json.Marshal(v)
+	if err != nil {
+		t.fail(err)
+		return
+	}
+
+	t.executeJSON(document)
+}
+
+func (t *TemplatePresenter) executeJSON(document []byte) {
+	var data interface{}
+	err := json.Unmarshal(document, &data)
+	if err == nil {
+		err = t.template.Execute(t.stdout, data)
+	}
+
+	if err != nil {
+		t.fail(err)
+	}
+}
+
+func (t *TemplatePresenter) fail(err error) {
+	if t.err == nil {
+		t.err = fmt.Errorf("could not execute format template: %w", err)
+	}
+}
@@ -0,0 +1,72 @@
+package presenters_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+	"github.com/pivotal-cf/om/presenters"
+)
+
+var _ = Describe("TemplatePresenter", func() {
+	var (
+		templatePresenter *presenters.TemplatePresenter
+		stdout            *gbytes.Buffer
+	)
+
+	BeforeEach(func() {
+		stdout = gbytes.NewBuffer()
+		templatePresenter = presenters.NewTemplatePresenter(stdout)
+	})
+
+	Describe("Configure", func() {
+		It("errors when the template does not parse", func() {
+			err := templatePresenter.Configure("{{.name")
+			Expect(err).To(MatchError(ContainSubstring("could not parse format template:")))
+		})
+	})
+
+	It("executes the template against the json document, by its json keys", func() {
+		Expect(templatePresenter.Configure(`{{range .}}{{.name}}={{.version}}{{"\n"}}{{end}}`)).To(Succeed())
+
+		templatePresenter.PresentAvailableProducts([]models.Product{
+			{Name: "cf", Version: "2.10.0"},
+			{Name: "p-mysql", Version: "2.9.0"},
+		})
+
+		Expect(string(stdout.Contents())).To(Equal("cf=2.10.0\np-mysql=2.9.0\n"))
+		Expect(templatePresenter.Err()).ToNot(HaveOccurred())
+	})
+
+	It("executes the template against the full report", func() {
+		Expect(templatePresenter.Configure(`{{.versions.release_version}}`)).To(Succeed())
+
+		templatePresenter.PresentDiagnosticReport(api.DiagnosticReport{
+			FullReport: `{"versions": {"release_version": "2.10.0"}}`,
+		})
+
+		Expect(string(stdout.Contents())).To(Equal("2.10.0"))
+	})
+
+	When("the template does not fit the document", func() {
+		It("returns the error from Err", func() {
+			Expect(templatePresenter.Configure(`{{.missing}}`)).To(Succeed())
+
+			templatePresenter.PresentInstallationLog(models.InstallationLog{ID: 3})
+
+			Expect(stdout.Contents()).To(BeEmpty())
+			Expect(templatePresenter.Err()).To(MatchError(ContainSubstring(`could not execute format template: `)))
+			Expect(templatePresenter.Err()).To(MatchError(ContainSubstring(`map has no entry for key "missing"`)))
+		})
+
+		It("keeps the first error", func() {
+			Expect(templatePresenter.Configure(`{{.id}}`)).To(Succeed())
+
+			templatePresenter.PresentErrands([]models.Errand{{Name: "smoke-tests"}})
+			templatePresenter.PresentInstallationLog(models.InstallationLog{ID: 3})
+
+			Expect(templatePresenter.Err()).To(MatchError(ContainSubstring("can't evaluate field id")))
+		})
+	})
+})
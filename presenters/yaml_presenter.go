@@ -0,0 +1,100 @@
+package presenters
+
+import (
+	"io"
+
+	"github.com/ghodss/yaml"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+)
+
+// YAMLPresenter writes the same documents as the JSONPresenter, as YAML.
+type YAMLPresenter struct {
+	stdout io.Writer
+}
+
+func NewYAMLPresenter(stdout io.Writer) YAMLPresenter {
+	return YAMLPresenter{
+		stdout: stdout,
+	}
+}
+
+func (y YAMLPresenter) PresentAvailableProducts(products []models.Product) {
+	y.encodeYAML(products)
+}
+
+func (y YAMLPresenter) PresentBoshDiff(diff models.BoshDiff) {
+	y.encodeYAML(diff)
+}
+
+func (y YAMLPresenter) PresentCertificateAuthorities(certificateAuthorities []api.CA) {
+	y.encodeYAML(certificateAuthorities)
+}
+
+func (y YAMLPresenter) PresentCredentialReferences(credentialReferences []string) {
+	y.encodeYAML(credentialReferences)
+}
+
+func (y YAMLPresenter) PresentCredentials(credentials map[string]string) {
+	y.encodeYAML(credentials)
+}
+
+func (y YAMLPresenter) PresentDeployedProducts(deployedProducts []api.DiagnosticProduct) {
+	y.encodeYAML(deployedProducts)
+}
+
+func (y YAMLPresenter) PresentErrands(errands []models.Errand) {
+	y.encodeYAML(errands)
+}
+
+func (y YAMLPresenter) PresentExpiringCertificates(certificates []api.ExpiringCertificate) {
+	y.encodeYAML(certificates)
+}
+
+func (y YAMLPresenter) PresentInstallationLog(log models.InstallationLog) {
+	y.encodeYAML(log)
+}
+
+func (y YAMLPresenter) PresentInstallationSummary(summary models.InstallationSummary) {
+	y.encodeYAML(summary)
+}
+
+func (y YAMLPresenter) PresentInstallationStats(stats models.InstallationStats) {
+	y.encodeYAML(stats)
+}
+
+func (y YAMLPresenter) PresentCertificateAuthority(certificateAuthority api.CA) {
+	y.encodeYAML(certificateAuthority)
+}
+
+func (y YAMLPresenter) PresentSSLCertificate(certificate api.SSLCertificate) {
+	y.encodeYAML(certificate)
+}
+
+func (y YAMLPresenter) PresentInstallations(installations []models.Installation) {
+	y.encodeYAML(installations)
+}
+
+func (y YAMLPresenter) PresentStagedProducts(stagedProducts []api.DiagnosticProduct) {
+	y.encodeYAML(stagedProducts)
+}
+
+func (y YAMLPresenter) PresentPendingChanges(pendingChangesOutput api.PendingChangesOutput) {
+	y.convertJSON(pendingChangesOutput.FullReport)
+}
+
+func (y YAMLPresenter) PresentDiagnosticReport(report api.DiagnosticReport) {
+	y.convertJSON(report.FullReport)
+}
+
+func (y YAMLPresenter) encodeYAML(v interface{}) {
+	b, _ := yaml.Marshal(v)
+
+	_, _ = y.stdout.Write(b)
+}
+
+func (y YAMLPresenter) convertJSON(document string) {
+	b, _ := yaml.JSONToYAML([]byte(document))
+
+	_, _ = y.stdout.Write(b)
+}
@@ -0,0 +1,74 @@
+package presenters_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/pivotal-cf/om/api"
+	"github.com/pivotal-cf/om/models"
+	"github.com/pivotal-cf/om/presenters"
+)
+
+var _ = Describe("YAMLPresenter", func() {
+	var (
+		yamlPresenter presenters.YAMLPresenter
+		stdout        *gbytes.Buffer
+	)
+
+	BeforeEach(func() {
+		stdout = gbytes.NewBuffer()
+		yamlPresenter = presenters.NewYAMLPresenter(stdout)
+	})
+
+	Describe("PresentCredentials", func() {
+		It("writes the document the json presenter would, as yaml", func() {
+			yamlPresenter.PresentCredentials(map[string]string{
+				"identity": "admin",
+				"password": "secret",
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`identity: admin
+password: secret
+`))
+		})
+	})
+
+	Describe("PresentInstallations", func() {
+		It("writes the document the json presenter would, as yaml", func() {
+			yamlPresenter.PresentInstallations([]models.Installation{
+				{Id: 1, Status: "succeeded", User: "admin"},
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`- id: 1
+  started_at: null
+  status: succeeded
+  user: admin
+`))
+		})
+	})
+
+	Describe("PresentPendingChanges", func() {
+		It("converts the full report", func() {
+			yamlPresenter.PresentPendingChanges(api.PendingChangesOutput{
+				FullReport: `{"product_changes": [{"guid": "cf-guid", "action": "update"}]}`,
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`product_changes:
+- action: update
+  guid: cf-guid
+`))
+		})
+	})
+
+	Describe("PresentDiagnosticReport", func() {
+		It("converts the full report", func() {
+			yamlPresenter.PresentDiagnosticReport(api.DiagnosticReport{
+				FullReport: `{"versions": {"release_version": "2.10.0"}}`,
+			})
+
+			Expect(string(stdout.Contents())).To(Equal(`versions:
+  release_version: 2.10.0
+`))
+		})
+	})
+})
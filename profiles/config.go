@@ -0,0 +1,195 @@
+// Package profiles stores the named Ops Manager targets of a user in ~/.om/config.yml.
+package profiles
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the config file: the profiles by name,
+// and the one used when no profile is given.
+type Config struct {
+	Current  string             `yaml:"current,omitempty"`
+	Profiles map[string]Profile `yaml:"profiles,omitempty"`
+}
+
+// Names are the names of the profiles, sorted.
+func (c Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Profile is how to reach an Ops Manager, and to authenticate with it:
+// with a username and password, with a client, or with the tokens of `om login`.
+type Profile struct {
+	Target               string  `yaml:"target"`
+	CACert               string  `yaml:"ca-cert,omitempty"`
+	SkipSSLValidation    bool    `yaml:"skip-ssl-validation,omitempty"`
+	ClientCert           string  `yaml:"client-cert,omitempty"`
+	ClientKey            string  `yaml:"client-key,omitempty"`
+	Username             string  `yaml:"username,omitempty"`
+	Password             *Secret `yaml:"password,omitempty"`
+	ClientID             string  `yaml:"client-id,omitempty"`
+	ClientSecret         *Secret `yaml:"client-secret,omitempty"`
+	DecryptionPassphrase *Secret `yaml:"decryption-passphrase,omitempty"`
+	ConnectTimeout       int     `yaml:"connect-timeout,omitempty"`
+	RequestTimeout       int     `yaml:"request-timeout,omitempty"`
+}
+
+// AuthMethod tells how the profile authenticates with Ops Manager.
+func (p Profile) AuthMethod() string {
+	switch {
+	case p.ClientID != "":
+		return "client"
+	case p.Username != "":
+		return "password"
+	default:
+		return "login"
+	}
+}
+
+// Validate checks the profile has a target, and the secrets of its credentials.
+func (p Profile) Validate() error {
+	if p.Target == "" {
+		return errors.New("a target is required")
+	}
+	if p.Username != "" && p.Password == nil {
+		return fmt.Errorf("the password of username %s is required", p.Username)
+	}
+	if p.ClientID != "" && p.ClientSecret == nil {
+		return fmt.Errorf("the client secret of client %s is required", p.ClientID)
+	}
+
+	return nil
+}
+
+// Secret refers to a secret, rather than storing it in plain text:
+// it is the value of an environment variable, or the output of a command.
+type Secret struct {
+	Env     string `yaml:"env,omitempty"`
+	Command string `yaml:"command,omitempty"`
+}
+
+func (s *Secret) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		return errors.New("secrets are referred to by an environment variable or a command (e.g.: {env: OM_PASSWORD}), not stored in plain text")
+	}
+
+	type plain Secret
+	return unmarshal((*plain)(s))
+}
+
+// Resolve reads the environment variable of the secret, or runs its command with sh.
+// The trailing newline of the output of the command is trimmed.
+func (s *Secret) Resolve() (string, error) {
+	if s == nil {
+		return "", nil
+	}
+
+	if s.Env != "" {
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("the environment variable %s of the secret is not set", s.Env)
+		}
+		return value, nil
+	}
+
+	if s.Command != "" {
+		command := exec.Command("sh", "-c", s.Command)
+		command.Stderr = os.Stderr
+		output, err := command.Output()
+		if err != nil {
+			return "", fmt.Errorf("could not run the command of the secret (%s): %s", s.Command, err)
+		}
+		return strings.TrimRight(string(output), "\r\n"), nil
+	}
+
+	return "", errors.New("a secret needs an environment variable or a command")
+}
+
+func (s *Secret) String() string {
+	switch {
+	case s == nil:
+		return ""
+	case s.Env != "":
+		return "env " + s.Env
+	default:
+		return "command " + s.Command
+	}
+}
+
+// Store reads and writes the config file.
+type Store struct {
+	path string
+}
+
+func NewStore(path string) Store {
+	return Store{path: path}
+}
+
+// DefaultPath is ~/.om/config.yml.
+func DefaultPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find the home directory for the config file: %s", err)
+	}
+
+	return filepath.Join(home, ".om", "config.yml"), nil
+}
+
+func (s Store) Path() string {
+	return s.path
+}
+
+// Load reads the config file, which is empty when it does not exist.
+func (s Store) Load() (Config, error) {
+	var config Config
+
+	contents, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, fmt.Errorf("could not read the config file: %s", err)
+	}
+
+	err = yaml.UnmarshalStrict(contents, &config)
+	if err != nil {
+		return config, fmt.Errorf("could not parse the config file %s: %s", s.path, err)
+	}
+
+	return config, nil
+}
+
+// Save writes the config file, only readable by the user.
+func (s Store) Save(config Config) error {
+	contents, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("could not marshal the config file: %s", err)
+	}
+
+	err = os.MkdirAll(filepath.Dir(s.path), 0700)
+	if err != nil {
+		return fmt.Errorf("could not create the directory of the config file: %s", err)
+	}
+
+	err = ioutil.WriteFile(s.path, contents, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write the config file: %s", err)
+	}
+
+	return nil
+}
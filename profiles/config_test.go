@@ -0,0 +1,143 @@
+package profiles_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pivotal-cf/om/profiles"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Store", func() {
+	var (
+		dir   string
+		store profiles.Store
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "profiles")
+		Expect(err).ToNot(HaveOccurred())
+
+		store = profiles.NewStore(filepath.Join(dir, ".om", "config.yml"))
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("loads an empty config when there is no config file", func() {
+		config, err := store.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(config).To(Equal(profiles.Config{}))
+	})
+
+	It("saves the config file only readable by the user, and loads it back", func() {
+		config := profiles.Config{
+			Current: "prod",
+			Profiles: map[string]profiles.Profile{
+				"prod": {
+					Target:               "https://opsman.example.com",
+					Username:             "admin",
+					Password:             &profiles.Secret{Env: "PROD_PASSWORD"},
+					DecryptionPassphrase: &profiles.Secret{Command: "pass show prod/passphrase"},
+					RequestTimeout:       3600,
+				},
+			},
+		}
+
+		err := store.Save(config)
+		Expect(err).ToNot(HaveOccurred())
+
+		info, err := os.Stat(store.Path())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0600)))
+
+		contents, err := ioutil.ReadFile(store.Path())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(contents).To(MatchYAML(`
+current: prod
+profiles:
+  prod:
+    target: https://opsman.example.com
+    username: admin
+    password: {env: PROD_PASSWORD}
+    decryption-passphrase: {command: pass show prod/passphrase}
+    request-timeout: 3600
+`))
+
+		loaded, err := store.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(loaded).To(Equal(config))
+	})
+
+	It("rejects secrets stored in plain text", func() {
+		Expect(os.MkdirAll(filepath.Dir(store.Path()), 0700)).To(Succeed())
+		err := ioutil.WriteFile(store.Path(), []byte(`{profiles: {prod: {target: opsman, username: admin, password: plain}}}`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = store.Load()
+		Expect(err).To(MatchError(ContainSubstring("not stored in plain text")))
+	})
+
+	It("rejects unknown keys", func() {
+		Expect(os.MkdirAll(filepath.Dir(store.Path()), 0700)).To(Succeed())
+		err := ioutil.WriteFile(store.Path(), []byte(`{profiles: {prod: {target: opsman, unknown: value}}}`), 0600)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = store.Load()
+		Expect(err).To(MatchError(ContainSubstring("could not parse the config file")))
+	})
+})
+
+var _ = Describe("Profile", func() {
+	It("tells the auth method", func() {
+		Expect(profiles.Profile{ClientID: "some-client"}.AuthMethod()).To(Equal("client"))
+		Expect(profiles.Profile{Username: "admin"}.AuthMethod()).To(Equal("password"))
+		Expect(profiles.Profile{}.AuthMethod()).To(Equal("login"))
+	})
+
+	It("requires a target and the secrets of the credentials", func() {
+		Expect(profiles.Profile{}.Validate()).To(MatchError("a target is required"))
+		Expect(profiles.Profile{Target: "opsman", Username: "admin"}.Validate()).To(MatchError("the password of username admin is required"))
+		Expect(profiles.Profile{Target: "opsman", ClientID: "some-client"}.Validate()).To(MatchError("the client secret of client some-client is required"))
+		Expect(profiles.Profile{Target: "opsman", ClientID: "some-client", ClientSecret: &profiles.Secret{Env: "SECRET"}}.Validate()).To(Succeed())
+	})
+})
+
+var _ = Describe("Secret", func() {
+	It("resolves to the value of its environment variable", func() {
+		Expect(os.Setenv("OM_PROFILES_TEST_SECRET", "some-secret")).To(Succeed())
+		defer os.Unsetenv("OM_PROFILES_TEST_SECRET")
+
+		value, err := (&profiles.Secret{Env: "OM_PROFILES_TEST_SECRET"}).Resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal("some-secret"))
+	})
+
+	It("errors when its environment variable is not set", func() {
+		_, err := (&profiles.Secret{Env: "OM_PROFILES_TEST_UNSET"}).Resolve()
+		Expect(err).To(MatchError("the environment variable OM_PROFILES_TEST_UNSET of the secret is not set"))
+	})
+
+	It("resolves to the output of its command", func() {
+		value, err := (&profiles.Secret{Command: "echo some-secret"}).Resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal("some-secret"))
+	})
+
+	It("errors when its command fails", func() {
+		_, err := (&profiles.Secret{Command: "exit 1"}).Resolve()
+		Expect(err).To(MatchError(ContainSubstring("could not run the command of the secret (exit 1)")))
+	})
+
+	It("resolves to nothing without a secret", func() {
+		var secret *profiles.Secret
+		value, err := secret.Resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(BeEmpty())
+	})
+})
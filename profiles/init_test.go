@@ -0,0 +1,13 @@
+package profiles_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "profiles")
+}
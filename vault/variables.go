@@ -0,0 +1,255 @@
+// Package vault looks up template variables in the KV secrets engine of a Vault server.
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+	"github.com/pivotal-cf/om/network"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	connectTimeout = 10 * time.Second
+	requestTimeout = 30 * time.Second
+)
+
+// Config is how to reach and authenticate with Vault:
+// with a token, or with the role and secret IDs of an AppRole.
+type Config struct {
+	Address           string
+	Token             string
+	RoleID            string
+	SecretID          string
+	Namespace         string
+	CACert            string
+	SkipSSLValidation bool
+}
+
+// Variables resolves the variable ((name)) to the secret <path>/name
+// of the source vault://<path>, where path starts with the mount of a KV
+// secrets engine, version 1 or 2.
+// A secret only holding a "value" key resolves to that value,
+// any other secret to all its keys, so ((name.key)) selects one of them.
+type Variables struct {
+	client *http.Client
+	config Config
+	path   string
+
+	token   string
+	mount   string
+	version string
+	secrets map[string]interface{}
+}
+
+var _ template.Variables = &Variables{}
+
+func NewVariables(source string, config Config) (*Variables, error) {
+	sourceURL, err := url.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse vars source %q: %s", source, err)
+	}
+
+	path := strings.Trim(sourceURL.Host+sourceURL.Path, "/")
+	if sourceURL.Scheme != "vault" || path == "" {
+		return nil, fmt.Errorf("vars source %q must be of the form vault://mount/path", source)
+	}
+
+	if config.Address == "" {
+		return nil, fmt.Errorf("vars source %s requires the address of Vault (vault-addr in the env file, or VAULT_ADDR)", source)
+	}
+
+	if config.Token == "" && (config.RoleID == "" || config.SecretID == "") {
+		return nil, fmt.Errorf("vars source %s requires a Vault token (vault-token, or VAULT_TOKEN) or the role and secret IDs of an AppRole (vault-role-id and vault-secret-id, or VAULT_ROLE_ID and VAULT_SECRET_ID)", source)
+	}
+
+	client, err := network.NewHTTPClient(config.SkipSSLValidation, config.CACert, "", "", requestTimeout, connectTimeout, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the Vault client: %s", err)
+	}
+
+	return &Variables{
+		client:  client,
+		config:  config,
+		path:    path,
+		secrets: map[string]interface{}{},
+	}, nil
+}
+
+// Get reads the secret of a variable the first time it is asked for.
+// A missing secret is a missing variable, not an error.
+func (v *Variables) Get(definition template.VariableDefinition) (interface{}, bool, error) {
+	name := strings.Trim(definition.Name, "/")
+
+	if value, ok := v.secrets[name]; ok {
+		return value, value != nil, nil
+	}
+
+	if err := v.connect(); err != nil {
+		return nil, false, err
+	}
+
+	secretPath := strings.TrimPrefix(v.path+"/"+name, v.mount)
+	readPath := v.mount + secretPath
+	if v.version == "2" {
+		readPath = v.mount + "data/" + secretPath
+	}
+
+	var response struct {
+		Data json.RawMessage `json:"data"`
+	}
+	found, err := v.request("GET", readPath, nil, &response)
+	if err != nil {
+		return nil, false, fmt.Errorf("could not read secret %s/%s from Vault: %s", v.path, name, err)
+	}
+	if !found {
+		v.secrets[name] = nil
+		return nil, false, nil
+	}
+
+	data := response.Data
+	if v.version == "2" {
+		var versioned struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(response.Data, &versioned); err != nil {
+			return nil, false, fmt.Errorf("could not decode secret %s/%s: %s", v.path, name, err)
+		}
+		data = versioned.Data
+	}
+
+	// JSON is YAML: decoding it as such gives the maps the templates expect
+	var fields map[interface{}]interface{}
+	if err := yaml.Unmarshal(data, &fields); err != nil {
+		return nil, false, fmt.Errorf("could not decode secret %s/%s: %s", v.path, name, err)
+	}
+
+	var value interface{} = fields
+	if single, ok := fields["value"]; ok && len(fields) == 1 {
+		value = single
+	}
+
+	v.secrets[name] = value
+	return value, true, nil
+}
+
+// List is not supported: the secrets are only read when asked for.
+func (v *Variables) List() ([]template.VariableDefinition, error) {
+	return nil, nil
+}
+
+// connect logs in with the AppRole, if no token was given,
+// and finds the mount of the path and the version of its engine.
+func (v *Variables) connect() error {
+	if v.mount != "" {
+		return nil
+	}
+
+	v.token = v.config.Token
+	if v.token == "" {
+		var login struct {
+			Auth struct {
+				ClientToken string `json:"client_token"`
+			} `json:"auth"`
+		}
+		found, err := v.request("POST", "auth/approle/login", map[string]string{
+			"role_id":   v.config.RoleID,
+			"secret_id": v.config.SecretID,
+		}, &login)
+		if err == nil && !found {
+			err = errors.New("the approle auth method is not enabled")
+		}
+		if err != nil {
+			return fmt.Errorf("could not log in to Vault with the AppRole: %s", err)
+		}
+		v.token = login.Auth.ClientToken
+	}
+
+	// the vault CLI does the same to tell the KV versions apart,
+	// and falls back to version 1 when it may not
+	var mount struct {
+		Data struct {
+			Path    string `json:"path"`
+			Options struct {
+				Version string `json:"version"`
+			} `json:"options"`
+		} `json:"data"`
+	}
+	found, err := v.request("GET", "sys/internal/ui/mounts/"+v.path, nil, &mount)
+	if err != nil || !found || mount.Data.Path == "" {
+		v.mount = strings.SplitN(v.path, "/", 2)[0] + "/"
+		v.version = "1"
+		return nil
+	}
+
+	v.mount = mount.Data.Path
+	v.version = mount.Data.Options.Version
+	return nil
+}
+
+// request returns false when Vault answers 404.
+func (v *Variables) request(method, path string, body interface{}, output interface{}) (bool, error) {
+	var requestBody io.Reader
+	if body != nil {
+		contents, err := json.Marshal(body)
+		if err != nil {
+			return false, err
+		}
+		requestBody = bytes.NewReader(contents)
+	}
+
+	request, err := http.NewRequest(method, strings.TrimRight(v.config.Address, "/")+"/v1/"+path, requestBody)
+	if err != nil {
+		return false, err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if v.token != "" {
+		request.Header.Set("X-Vault-Token", v.token)
+	}
+	if v.config.Namespace != "" {
+		request.Header.Set("X-Vault-Namespace", v.config.Namespace)
+	}
+
+	response, err := v.client.Do(request)
+	if err != nil {
+		return false, err
+	}
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return false, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if response.StatusCode != http.StatusOK {
+		var vaultErrors struct {
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(contents, &vaultErrors)
+		if len(vaultErrors.Errors) > 0 {
+			return false, fmt.Errorf("unexpected response %d: %s", response.StatusCode, strings.Join(vaultErrors.Errors, "; "))
+		}
+		return false, fmt.Errorf("unexpected response %d", response.StatusCode)
+	}
+
+	if err := json.Unmarshal(contents, output); err != nil {
+		return false, fmt.Errorf("could not decode the response: %s", err)
+	}
+
+	return true, nil
+}
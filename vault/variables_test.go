@@ -0,0 +1,188 @@
+package vault_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry/bosh-cli/director/template"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+	"github.com/pivotal-cf/om/vault"
+)
+
+var _ = Describe("Variables", func() {
+	var server *ghttp.Server
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	mounts := func(path, version string) http.HandlerFunc {
+		return ghttp.CombineHandlers(
+			ghttp.VerifyRequest("GET", "/v1/sys/internal/ui/mounts/"+path),
+			ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+				"data": map[string]interface{}{
+					"path":    "secret/",
+					"type":    "kv",
+					"options": map[string]string{"version": version},
+				},
+			}),
+		)
+	}
+
+	It("reads the secrets of a KV version 2 engine with a token", func() {
+		server.AppendHandlers(
+			mounts("secret/foundation", "2"),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/secret/data/foundation/admin_password"),
+				ghttp.VerifyHeaderKV("X-Vault-Token", "some-token"),
+				ghttp.VerifyHeaderKV("X-Vault-Namespace", "some-namespace"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+					"data": map[string]interface{}{
+						"data":     map[string]string{"value": "some-password"},
+						"metadata": map[string]interface{}{"version": 3},
+					},
+				}),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/secret/data/foundation/router_cert"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+					"data": map[string]interface{}{
+						"data": map[string]string{"certificate": "some-certificate", "private_key": "some-key"},
+					},
+				}),
+			),
+		)
+
+		variables, err := vault.NewVariables("vault://secret/foundation", vault.Config{
+			Address:   server.URL(),
+			Token:     "some-token",
+			Namespace: "some-namespace",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		value, found, err := variables.Get(template.VariableDefinition{Name: "admin_password"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal("some-password"))
+
+		value, found, err = variables.Get(template.VariableDefinition{Name: "router_cert"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal(map[interface{}]interface{}{
+			"certificate": "some-certificate",
+			"private_key": "some-key",
+		}))
+
+		By("reading every secret once")
+		_, _, err = variables.Get(template.VariableDefinition{Name: "admin_password"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("reads the secrets of a KV version 1 engine after logging in with an AppRole", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("POST", "/v1/auth/approle/login"),
+				ghttp.VerifyJSON(`{"role_id": "some-role-id", "secret_id": "some-secret-id"}`),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+					"auth": map[string]string{"client_token": "approle-token"},
+				}),
+			),
+			mounts("secret", "1"),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/secret/admin_password"),
+				ghttp.VerifyHeaderKV("X-Vault-Token", "approle-token"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+					"data": map[string]string{"value": "some-password"},
+				}),
+			),
+		)
+
+		variables, err := vault.NewVariables("vault://secret", vault.Config{
+			Address:  server.URL(),
+			RoleID:   "some-role-id",
+			SecretID: "some-secret-id",
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		value, found, err := variables.Get(template.VariableDefinition{Name: "admin_password"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(value).To(Equal("some-password"))
+	})
+
+	It("assumes a KV version 1 engine mounted on the first segment when the mount cannot be found", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusForbidden, `{"errors": ["permission denied"]}`),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/v1/kv/foundation/admin_password"),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, map[string]interface{}{
+					"data": map[string]string{"value": "some-password"},
+				}),
+			),
+		)
+
+		variables, err := vault.NewVariables("vault://kv/foundation", vault.Config{Address: server.URL(), Token: "some-token"})
+		Expect(err).ToNot(HaveOccurred())
+
+		value, _, err := variables.Get(template.VariableDefinition{Name: "admin_password"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal("some-password"))
+	})
+
+	It("does not find the variables without a secret", func() {
+		server.AppendHandlers(
+			mounts("secret/foundation", "2"),
+			ghttp.RespondWith(http.StatusNotFound, `{"errors": []}`),
+		)
+
+		variables, err := vault.NewVariables("vault://secret/foundation", vault.Config{Address: server.URL(), Token: "some-token"})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, found, err := variables.Get(template.VariableDefinition{Name: "missing"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("returns the errors of Vault", func() {
+		server.AppendHandlers(
+			mounts("secret/foundation", "2"),
+			ghttp.RespondWith(http.StatusForbidden, `{"errors": ["permission denied"]}`),
+		)
+
+		variables, err := vault.NewVariables("vault://secret/foundation", vault.Config{Address: server.URL(), Token: "some-token"})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, err = variables.Get(template.VariableDefinition{Name: "admin_password"})
+		Expect(err).To(MatchError("could not read secret secret/foundation/admin_password from Vault: unexpected response 403: permission denied"))
+	})
+
+	It("returns an error when the AppRole cannot log in", func() {
+		server.AppendHandlers(
+			ghttp.RespondWith(http.StatusBadRequest, `{"errors": ["invalid secret id"]}`),
+		)
+
+		variables, err := vault.NewVariables("vault://secret", vault.Config{Address: server.URL(), RoleID: "some-role-id", SecretID: "wrong"})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, _, err = variables.Get(template.VariableDefinition{Name: "admin_password"})
+		Expect(err).To(MatchError("could not log in to Vault with the AppRole: unexpected response 400: invalid secret id"))
+	})
+
+	DescribeTable("invalid configurations",
+		func(source string, config vault.Config, message string) {
+			_, err := vault.NewVariables(source, config)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("another scheme", "credhub://secret", vault.Config{Address: "https://vault", Token: "token"}, `vars source "credhub://secret" must be of the form vault://mount/path`),
+		Entry("no path", "vault://", vault.Config{Address: "https://vault", Token: "token"}, `vars source "vault://" must be of the form vault://mount/path`),
+		Entry("no address", "vault://secret", vault.Config{Token: "token"}, "vars source vault://secret requires the address of Vault"),
+		Entry("no credentials", "vault://secret", vault.Config{Address: "https://vault", RoleID: "role"}, "vars source vault://secret requires a Vault token"),
+	)
+})